@@ -0,0 +1,127 @@
+// Command migrate manages the database schema without starting the API server
+//
+// Usage:
+//
+//	migrate up             apply all pending migrations
+//	migrate down [-to N]   roll back the last migration, or every migration after version N
+//	migrate status         list all migrations and when they were applied
+//	migrate redo           roll back the last migration and apply it again
+//
+// It only needs DATABASE_URL. Deployments that run it before starting the new server
+// set AUTO_MIGRATE=false on the server.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/migrations"
+	_ "github.com/lib/pq"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up             apply all pending migrations
+  down [-to N]   roll back the last migration, or every migration after version N
+  status         list all migrations and when they were applied
+  redo           roll back the last migration and apply it again
+`
+
+func main() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	os.Exit(run(os.Args[1], os.Args[2:]))
+}
+
+func run(command string, args []string) int {
+	switch command {
+	case "up", "down", "status", "redo":
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	to := -1
+	if command == "down" {
+		fs.IntVar(&to, "to", -1, "roll back every migration after this version (0 = all)")
+	}
+	fs.Parse(args)
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		slog.Error("DATABASE_URL is required")
+		return 1
+	}
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		slog.Error("Failed to open database", "error", err)
+		return 1
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		return 1
+	}
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		slog.Error("Failed to initialize migrator", "error", err)
+		return 1
+	}
+
+	switch command {
+	case "up":
+		err = migrator.MigrateUp()
+	case "down":
+		if to >= 0 {
+			err = migrator.MigrateDownTo(to)
+		} else {
+			err = migrator.MigrateDown()
+		}
+	case "redo":
+		err = migrator.Redo()
+	case "status":
+		err = printStatus(migrator)
+	}
+	if err != nil {
+		slog.Error("Migration failed", "command", command, "error", err)
+		return 1
+	}
+	return 0
+}
+
+// printStatus writes one line per migration to stdout
+func printStatus(migrator *migrations.Migrator) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	pending := 0
+	for _, s := range status {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		} else {
+			pending++
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d of %d migrations applied, %d pending\n", len(status)-pending, len(status), pending)
+	return nil
+}
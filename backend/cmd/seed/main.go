@@ -0,0 +1,377 @@
+// Command seed fills a development database with realistic sample data: players, a
+// confirmed match history spread over the past weeks, comments and reactions
+//
+// Usage:
+//
+//	seed [-users 40] [-matches 600] [-days 120] [-seed 1]
+//
+// Every player has a hidden skill that decides who wins, so ratings drift towards it
+// the way they would with real players. Matches go through the CSV import, which
+// replays all ratings in chronological order. Seeded players get IDs from 9000000 up
+// and are upserted, so running the command again adds matches without new players.
+// It reads the same environment as the server and refuses to run in production or staging.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/app"
+	"github.com/42heilbronn/elo-leaderboard/internal/config"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	_ "github.com/lib/pq"
+)
+
+// seedIDBase keeps seeded players clear of real 42 intra IDs
+const seedIDBase = 9000000
+
+var (
+	firstNames = []string{
+		"Anna", "Ben", "Clara", "David", "Elif", "Felix", "Greta", "Hannes", "Ida", "Jonas",
+		"Kira", "Lukas", "Mia", "Noah", "Olga", "Paul", "Quentin", "Rosa", "Samir", "Tara",
+		"Umut", "Vera", "Wim", "Xenia", "Yusuf", "Zoe", "Emil", "Lea", "Malte", "Nora",
+	}
+	lastNames = []string{
+		"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker",
+		"Schulz", "Hoffmann", "Koch", "Richter", "Klein", "Wolf", "Yilmaz", "Kaya",
+		"Neumann", "Schwarz", "Braun", "Zimmermann",
+	}
+	comments = []string{
+		"Good game!", "Rematch tomorrow?", "That last rally was unreal", "GG, well played",
+		"I'll get you next time", "Close one!", "My backhand needs work", "Never again 😅",
+		"What a comeback", "Lucky shot at the end", "Let's play doubles next", "The table was wobbly, I swear",
+	}
+	reactions = []string{"👍", "👏", "🔥", "😮", "😂", "🏆", "💪", "😢"}
+)
+
+// player is a seeded user with the hidden skill and activity the simulation uses
+type player struct {
+	id       int
+	skill    float64 // How far above or below the sport's default rating the player would settle
+	activity float64 // Relative share of matches the player takes part in
+	sports   map[string]bool
+}
+
+func main() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	users := flag.Int("users", 40, "number of players to create")
+	matches := flag.Int("matches", 600, "number of matches to generate")
+	days := flag.Int("days", 120, "spread the matches over this many past days")
+	seed := flag.Int64("seed", 1, "random seed; the same seed generates the same players and results")
+	flag.Parse()
+
+	if *users < 2 || *matches < 1 || *days < 1 {
+		slog.Error("Need at least 2 users, 1 match and 1 day")
+		os.Exit(2)
+	}
+	if *matches > services.MatchImportMaxRows {
+		slog.Error("Too many matches", "max", services.MatchImportMaxRows)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Failed to load config", "error", err)
+		os.Exit(1)
+	}
+	if cfg.IsProduction() {
+		slog.Error("Refusing to seed a production or staging database", "environment", cfg.Environment)
+		os.Exit(1)
+	}
+
+	application, err := app.NewCLI(cfg)
+	if err != nil {
+		slog.Error("Failed to initialize application", "error", err)
+		os.Exit(1)
+	}
+	defer application.Close()
+
+	// Seeding is not a request, so its queries run without the request query timeout
+	ctx := repositories.WithoutQueryTimeout(context.Background())
+	if err := run(ctx, application, rand.New(rand.NewSource(*seed)), *users, *matches, *days); err != nil {
+		slog.Error("Seeding failed", "error", err)
+		application.Close()
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, a *app.App, rng *rand.Rand, userCount, matchCount, days int) error {
+	sports, err := a.Services.Sport.GetAllActiveSports()
+	if err != nil {
+		return err
+	}
+	if len(sports) == 0 {
+		return fmt.Errorf("no active sports")
+	}
+
+	players, err := createPlayers(ctx, a, rng, userCount, sports)
+	if err != nil {
+		return err
+	}
+	slog.Info("Players created", "count", len(players))
+	if _, err := a.Services.Anonymization.AssignMissingNames(ctx); err != nil {
+		return err
+	}
+
+	history, err := matchHistory(rng, players, sports, matchCount, days)
+	if err != nil {
+		return err
+	}
+	report, err := a.Services.MatchImport.Import(ctx, bytes.NewReader(history), false)
+	if err != nil {
+		return fmt.Errorf("failed to import matches: %w", err)
+	}
+	for _, e := range report.Errors {
+		slog.Warn("Match skipped", "line", e.Line, "error", e.Error)
+	}
+	slog.Info("Matches imported", "imported", report.Imported, "skipped", len(report.Errors))
+
+	commentCount, reactionCount, err := addInteractions(ctx, a, rng, players)
+	if err != nil {
+		return err
+	}
+	slog.Info("Comments and reactions added", "comments", commentCount, "reactions", reactionCount)
+
+	fmt.Printf("Seeded %d players, %d matches, %d comments and %d reactions\n",
+		len(players), report.Imported, commentCount, reactionCount)
+	return nil
+}
+
+// createPlayers upserts the seeded users; each plays one or more of the sports
+func createPlayers(ctx context.Context, a *app.App, rng *rand.Rand, count int, sports []*services.Sport) ([]*player, error) {
+	players := make([]*player, 0, count)
+	logins := make(map[string]bool, count)
+
+	for i := 0; i < count; i++ {
+		first := firstNames[rng.Intn(len(firstNames))]
+		last := lastNames[rng.Intn(len(lastNames))]
+
+		// 42 style logins: first letter of the first name and up to seven of the last
+		base := strings.ToLower(first[:1] + strings.NewReplacer("ü", "ue", "ö", "oe", "ä", "ae").Replace(last))
+		if len(base) > 8 {
+			base = base[:8]
+		}
+		login := base
+		for n := 2; logins[login]; n++ {
+			login = base + strconv.Itoa(n)
+		}
+		logins[login] = true
+
+		user := &models.User{
+			IntraID:     seedIDBase + i,
+			Login:       login,
+			DisplayName: first + " " + last,
+			Campus:      "Heilbronn",
+		}
+		if _, err := a.Repos.User.CreateOrUpdate(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to create user %s: %w", login, err)
+		}
+
+		p := &player{
+			id:       user.ID,
+			skill:    rng.NormFloat64() * 180,
+			activity: 0.2 + rng.ExpFloat64(),
+			sports:   make(map[string]bool),
+		}
+		// Everyone plays the first sport most of the time; others are picked up by some
+		for j, sport := range sports {
+			if j == 0 && rng.Float64() < 0.85 || j > 0 && rng.Float64() < 0.5 {
+				p.sports[sport.ID] = true
+			}
+		}
+		if len(p.sports) == 0 {
+			p.sports[sports[rng.Intn(len(sports))].ID] = true
+		}
+		players = append(players, p)
+	}
+	return players, nil
+}
+
+// matchHistory generates the matches as a CSV file in the import's column layout
+func matchHistory(rng *rand.Rand, players []*player, sports []*services.Sport, count, days int) ([]byte, error) {
+	now := time.Now().UTC()
+	start := now.AddDate(0, 0, -days)
+
+	// Matches are played between 9:00 and 20:00, mostly on weekdays, in chronological order
+	times := make([]time.Time, 0, count)
+	for len(times) < count {
+		t := start.Add(time.Duration(rng.Int63n(int64(now.Sub(start)))))
+		t = time.Date(t.Year(), t.Month(), t.Day(), 9+rng.Intn(11), rng.Intn(60), rng.Intn(60), 0, time.UTC)
+		weekend := t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+		if t.After(now) || weekend && rng.Float64() < 0.8 {
+			continue
+		}
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"Sport", "Player1ID", "Player2ID", "Player1Score", "Player2Score", "CreatedAt", "Status"})
+	for _, t := range times {
+		sport := sports[rng.Intn(len(sports))]
+		var pool []*player
+		for _, p := range players {
+			if p.sports[sport.ID] {
+				pool = append(pool, p)
+			}
+		}
+		if len(pool) < 2 {
+			continue
+		}
+		player1 := pick(rng, pool, nil)
+		player2 := pick(rng, pool, player1)
+
+		// The hidden skills decide the winner like ratings would: 400 points apart is 10:1
+		player1WinChance := 1 / (1 + math.Pow(10, (player2.skill-player1.skill)/400))
+		player1Won := rng.Float64() < player1WinChance
+		winnerChance := player1WinChance
+		if !player1Won {
+			winnerChance = 1 - player1WinChance
+		}
+		winnerScore, loserScore := scores(rng, sport, winnerChance)
+		player1Score, player2Score := winnerScore, loserScore
+		if !player1Won {
+			player1Score, player2Score = loserScore, winnerScore
+		}
+
+		// A few results are denied by the opponent and do not count
+		status := models.StatusConfirmed
+		if rng.Float64() < 0.04 {
+			status = models.StatusDenied
+		}
+
+		w.Write([]string{
+			sport.ID,
+			strconv.Itoa(player1.id),
+			strconv.Itoa(player2.id),
+			strconv.Itoa(player1Score),
+			strconv.Itoa(player2Score),
+			t.Format(time.RFC3339),
+			status,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// pick chooses a player weighted by activity, other than exclude
+func pick(rng *rand.Rand, pool []*player, exclude *player) *player {
+	total := 0.0
+	for _, p := range pool {
+		if p != exclude {
+			total += p.activity
+		}
+	}
+	r := rng.Float64() * total
+	for _, p := range pool {
+		if p == exclude {
+			continue
+		}
+		if r -= p.activity; r <= 0 {
+			return p
+		}
+	}
+	for _, p := range pool {
+		if p != exclude {
+			return p
+		}
+	}
+	return nil
+}
+
+// scores returns a plausible result: table football is played to 10, everything else
+// to 11, within the sport's score range; clear favourites tend to win by more
+func scores(rng *rand.Rand, sport *services.Sport, winnerChance float64) (int, int) {
+	target := 11
+	if sport.ID == "table_football" {
+		target = 10
+	}
+	target = min(max(target, sport.MinScore+1), sport.MaxScore)
+
+	// Even matches often go to deuce in table tennis
+	if target == 11 && winnerChance < 0.6 && rng.Float64() < 0.25 && sport.MaxScore >= 13 {
+		loser := 10 + rng.Intn(3)
+		return loser + 2, loser
+	}
+	// The loser's share of the points shrinks the more the winner was favoured
+	closeness := 1 - winnerChance + 0.5*rng.Float64()
+	loser := int(math.Round(float64(target-1) * min(closeness, 1) * rng.Float64() * 1.6))
+	return target, min(max(loser, sport.MinScore), target-1)
+}
+
+// addInteractions lets players comment on and react to some of the seeded matches
+func addInteractions(ctx context.Context, a *app.App, rng *rand.Rand, players []*player) (int, int, error) {
+	rows, err := a.DB.QueryContext(ctx, `
+		SELECT id, player1_id, player2_id FROM matches
+		WHERE player1_id >= $1 AND player2_id >= $1 AND status = $2 AND deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.match_id = matches.id)
+		  AND NOT EXISTS (SELECT 1 FROM reactions r WHERE r.match_id = matches.id)
+	`, seedIDBase, models.StatusConfirmed)
+	if err != nil {
+		return 0, 0, err
+	}
+	type match struct{ id, player1, player2 int }
+	var matches []match
+	for rows.Next() {
+		var m match
+		if err := rows.Scan(&m.id, &m.player1, &m.player2); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		matches = append(matches, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	commentCount, reactionCount := 0, 0
+	for _, m := range matches {
+		if rng.Float64() < 0.25 {
+			authors := []int{m.player1, m.player2}
+			for i := 0; i < 1+rng.Intn(3); i++ {
+				comment := &models.Comment{
+					MatchID: m.id,
+					UserID:  authors[i%2],
+					Content: comments[rng.Intn(len(comments))],
+				}
+				if err := a.Repos.Comment.Add(ctx, comment); err != nil {
+					return commentCount, reactionCount, fmt.Errorf("failed to add comment to match %d: %w", m.id, err)
+				}
+				commentCount++
+			}
+		}
+		if rng.Float64() < 0.4 {
+			for i := 0; i < 1+rng.Intn(4); i++ {
+				reaction := &models.Reaction{
+					MatchID: m.id,
+					UserID:  players[rng.Intn(len(players))].id,
+					Emoji:   reactions[rng.Intn(len(reactions))],
+				}
+				added, err := a.Repos.Reaction.Add(ctx, reaction)
+				if err != nil {
+					return commentCount, reactionCount, fmt.Errorf("failed to add reaction to match %d: %w", m.id, err)
+				}
+				if added {
+					reactionCount++
+				}
+			}
+		}
+	}
+	return commentCount, reactionCount, nil
+}
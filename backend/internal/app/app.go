@@ -0,0 +1,715 @@
+// Package app builds and owns all application components.
+//
+// New constructs the database, repositories, services, background jobs, realtime
+// hub and handlers in dependency order and registers each component's cleanup
+// with the shutdown manager as soon as it exists. Cleanups run in reverse order,
+// so components are torn down before the things they depend on.
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/cache"
+	"github.com/42heilbronn/elo-leaderboard/internal/config"
+	"github.com/42heilbronn/elo-leaderboard/internal/handlers"
+	"github.com/42heilbronn/elo-leaderboard/internal/integrations"
+	"github.com/42heilbronn/elo-leaderboard/internal/intra"
+	"github.com/42heilbronn/elo-leaderboard/internal/jobs"
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/migrations"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+	"github.com/42heilbronn/elo-leaderboard/internal/realtime"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/revocation"
+	"github.com/42heilbronn/elo-leaderboard/internal/server"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/telemetry"
+	"github.com/XSAM/otelsql"
+	"github.com/gin-gonic/gin"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// shutdownTimeout bounds the whole graceful shutdown, HTTP drain included
+const shutdownTimeout = 30 * time.Second
+
+// deliveryLogRetention is how long notification delivery attempts are kept
+const deliveryLogRetention = 30 * 24 * time.Hour
+
+// userNotificationRetention is how long entries stay in user notification inboxes
+const userNotificationRetention = 90 * 24 * time.Hour
+
+// rankNotificationDebounce is how long rank changes are collected before a player is notified
+const rankNotificationDebounce = 5 * time.Minute
+
+// Repositories groups all data access components
+type Repositories struct {
+	User          *repositories.UserRepository
+	Match         *repositories.MatchRepository
+	Comment       *repositories.CommentRepository
+	Admin         *repositories.AdminRepository
+	UserSports    *repositories.UserSportsRepository
+	Reaction      *repositories.ReactionRepository
+	Snapshot      *repositories.SnapshotRepository
+	Season        *repositories.SeasonRepository
+	Anonymization *repositories.AnonymizationRepository
+	ELOHistory    *repositories.ELOHistoryRepository
+	Delivery      *repositories.DeliveryRepository
+	Usage         *repositories.UsageRepository
+	Legal         *repositories.LegalRepository
+	Webhook       *repositories.WebhookRepository
+	Notification  *repositories.UserNotificationRepository
+	Slack         *repositories.SlackRepository
+	Incident      *repositories.IncidentRepository
+	ELOReplay     *repositories.ELOReplayRepository
+	TrustedClient *repositories.TrustedClientRepository
+	DataExport    *repositories.DataExportRepository
+	Challenge     *repositories.ChallengeRepository
+	Deletion      *repositories.DeletionRequestRepository
+	ProfileSync   *repositories.ProfileSyncRepository
+	Report        *repositories.ReportRepository
+	MatchFlag     *repositories.MatchFlagRepository
+	Digest        *repositories.DigestRepository
+	Ranking       *repositories.LeaderboardRankingRepository
+}
+
+// Services groups all business logic components
+type Services struct {
+	ELO           *services.ELOService
+	Sport         *services.SportService
+	Match         *services.MatchService
+	Season        *services.SeasonService
+	Anonymization *services.AnonymizationService
+	Stats         *services.StatsService
+	Recompute     *services.RecomputeService
+	MatchImport   *services.MatchImportService
+	Activity      *services.ActivityMonitor
+	DataExport    *services.DataExportService
+	ConfirmTokens *services.ConfirmationTokenService
+	Calendar      *services.CalendarService
+	Challenge     *services.ChallengeService
+	Deletion      *services.AccountDeletionService
+	ProfileSync   *services.ProfileSyncService
+	Abuse         *services.AbuseDetectionService
+	Digest        *services.DigestService
+}
+
+// Handlers groups all HTTP handlers
+type Handlers struct {
+	Auth          *handlers.AuthHandler
+	Match         *handlers.MatchHandler
+	Admin         *handlers.AdminHandler
+	Health        *handlers.HealthHandler
+	GDPR          *handlers.GDPRHandler
+	Sport         *handlers.SportHandler
+	Anonymization *handlers.AnonymizationHandler
+	Season        *handlers.SeasonHandler
+	Realtime      *handlers.RealtimeHandler
+	ELOHistory    *handlers.ELOHistoryHandler
+	UserStats     *handlers.UserStatsHandler
+	Notification  *handlers.NotificationHandler
+	Usage         *handlers.UsageHandler
+	Legal         *handlers.LegalHandler
+	Compare       *handlers.CompareHandler
+	Webhook       *handlers.WebhookHandler
+	Inbox         *handlers.UserNotificationHandler
+	Status        *handlers.StatusHandler
+	TrustedClient *handlers.TrustedClientHandler
+	Challenge     *handlers.ChallengeHandler
+	Report        *handlers.ReportHandler
+	Digest        *handlers.DigestHandler
+	Stats         *handlers.StatsHandler
+	Calendar      *handlers.CalendarHandler
+	Cache         *handlers.CacheHandler
+	RateLimit     *handlers.RateLimitHandler
+	Chaos         *handlers.ChaosHandler // nil unless CHAOS_ENABLED
+	Docs          *handlers.DocsHandler  // nil unless API_DOCS_ENABLED
+	Slack         *handlers.SlackHandler // nil unless SLACK_SIGNING_SECRET is set
+	Kiosk         *handlers.KioskHandler // nil unless KIOSK_API_KEYS is set
+}
+
+// App is the application container
+type App struct {
+	Config    *config.Config
+	DB        *sql.DB
+	ReplicaDB *sql.DB // nil unless DATABASE_URL_RO is set
+	Repos     Repositories
+	Services  Services
+	Handlers  Handlers
+	Hub       *realtime.Hub
+	Notifier  *notifications.Notifier
+	Webhooks  *notifications.WebhookDispatcher
+	Ranks     *notifications.RankWatcher
+	Inbox     *notifications.Inbox
+	Templates *notifications.Templates
+	Discord   *integrations.DiscordAnnouncer
+	Digests   *integrations.DigestPoster
+	Usage     *middleware.UsageTracker
+	Chaos     *middleware.ChaosInjector // nil unless CHAOS_ENABLED
+	Tiers     *middleware.ClientTiers
+	Limiters  *middleware.RateLimitRegistry
+	DenyList  *revocation.DenyList
+	Cache     *cache.Instrumented
+	Scheduler *jobs.Scheduler
+
+	shutdown *server.ShutdownManager
+	server   *server.Server
+	limits   rateLimits
+}
+
+// rateLimiter builds a rate limit middleware from a key function
+type rateLimiter func(keyFunc func(*gin.Context) string) gin.HandlerFunc
+
+// rateLimits holds the rate limiters of each endpoint class and of routes with a limit of their own
+type rateLimits struct {
+	strict   rateLimiter            // 10 req/min for match submission
+	moderate rateLimiter            // 30 req/min for comments
+	loose    rateLimiter            // 100 req/min for reads
+	routes   map[string]rateLimiter // RATE_LIMIT_<ROUTE> overrides by route name
+}
+
+// route returns the limiter of a named route, or its class's limiter unless the route is overridden
+func (l rateLimits) route(name string, class rateLimiter) rateLimiter {
+	if limiter, ok := l.routes[name]; ok {
+		return limiter
+	}
+	return class
+}
+
+// initStep is one stage of building the application
+type initStep struct {
+	name string
+	init func() error
+}
+
+// New constructs the application; on error everything built so far is cleaned up
+func New(cfg *config.Config) (*App, error) {
+	a := &App{
+		Config:   cfg,
+		shutdown: server.NewShutdownManager(shutdownTimeout),
+	}
+
+	err := a.build([]initStep{
+		{"telemetry", a.initTelemetry},
+		{"database", a.initDatabase},
+		{"repositories", a.initRepositories},
+		{"cache", a.initCache},
+		{"services", a.initServices},
+		{"revocation", a.initRevocation},
+		{"realtime", a.initRealtime},
+		{"notifications", a.initNotifications},
+		{"jobs", a.initJobs},
+		{"rate_limits", a.initRateLimits},
+		{"handlers", a.initHandlers},
+		{"server", a.initServer},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewCLI constructs only the database, repositories, cache and services for one-off commands
+// Call Close when done
+func NewCLI(cfg *config.Config) (*App, error) {
+	a := &App{
+		Config:   cfg,
+		shutdown: server.NewShutdownManager(shutdownTimeout),
+	}
+
+	err := a.build([]initStep{
+		{"database", a.initDatabase},
+		{"repositories", a.initRepositories},
+		{"cache", a.initCache},
+		{"services", a.initServices},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *App) build(steps []initStep) error {
+	for _, step := range steps {
+		if err := step.init(); err != nil {
+			a.shutdown.Shutdown(nil)
+			return fmt.Errorf("failed to initialize %s: %w", step.name, err)
+		}
+	}
+	return nil
+}
+
+// Close releases everything an App built by NewCLI holds
+func (a *App) Close() {
+	a.shutdown.Shutdown(nil)
+}
+
+// Run starts background jobs and serves HTTP until a shutdown signal arrives
+func (a *App) Run() error {
+	a.Scheduler.Start()
+
+	slog.Info("Server starting", "port", a.Config.Port)
+	return a.server.Start()
+}
+
+// onShutdown registers a cleanup; later registrations are cleaned up first
+func (a *App) onShutdown(name string, cleanup func(ctx context.Context) error) {
+	a.shutdown.Register(name, cleanup)
+}
+
+// initTelemetry starts exporting traces when OTEL_EXPORTER_OTLP_ENDPOINT is set
+// Registered first, so spans of the other components' shutdown are still flushed
+func (a *App) initTelemetry() error {
+	shutdown, err := telemetry.Setup(context.Background(), telemetry.Config{
+		Endpoint:    a.Config.OTelEndpoint,
+		ServiceName: a.Config.OTelServiceName,
+		SampleRate:  a.Config.TracingSampleRate,
+	})
+	if err != nil {
+		return err
+	}
+	a.onShutdown("telemetry", shutdown)
+	if a.Config.OTelEndpoint != "" {
+		slog.Info("Tracing enabled", "endpoint", a.Config.OTelEndpoint, "sample_rate", a.Config.TracingSampleRate)
+	}
+	return nil
+}
+
+// openDatabase opens a connection pool and makes sure the database is reachable
+// Queries are traced as children of the span of the request or job that runs them
+func openDatabase(url string) (*sql.DB, error) {
+	db, err := otelsql.Open("postgres", url,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Configure connection pool for better performance under load
+	db.SetMaxOpenConns(25)                 // Maximum number of open connections
+	db.SetMaxIdleConns(10)                 // Maximum number of idle connections
+	db.SetConnMaxLifetime(5 * time.Minute) // Maximum connection lifetime
+	db.SetConnMaxIdleTime(1 * time.Minute) // Maximum idle time before closing
+
+	// Test database connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return db, nil
+}
+
+func (a *App) initDatabase() error {
+	db, err := openDatabase(a.Config.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	a.shutdown.RegisterDatabase(db)
+	slog.Info("Connected to database successfully")
+	repositories.SetQueryTimeout(time.Duration(a.Config.DBQueryTimeoutSeconds) * time.Second)
+
+	// Leaderboard, feed and statistics reads go to the replica when one is configured
+	if a.Config.DatabaseURLRO != "" {
+		replica, err := openDatabase(a.Config.DatabaseURLRO)
+		if err != nil {
+			return fmt.Errorf("read replica: %w", err)
+		}
+		a.onShutdown("database replica", func(ctx context.Context) error {
+			return replica.Close()
+		})
+		a.ReplicaDB = replica
+		slog.Info("Connected to read replica successfully")
+	}
+
+	// Run database migrations, or make sure they were run
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		return fmt.Errorf("failed to initialize migrator: %w", err)
+	}
+	if a.Config.AutoMigrate {
+		if err := migrator.MigrateUp(); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+		slog.Info("Database migrations applied successfully")
+	} else {
+		pending, err := migrator.Pending()
+		if err != nil {
+			return fmt.Errorf("failed to check migrations: %w", err)
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d database migrations are pending, starting with %03d_%s; apply them with the migrate command",
+				len(pending), pending[0].Version, pending[0].Name)
+		}
+		slog.Info("Database schema is up to date")
+	}
+
+	a.DB = db
+	return nil
+}
+
+func (a *App) initRepositories() error {
+	a.Repos = Repositories{
+		User:          repositories.NewUserRepository(a.DB),
+		Match:         repositories.NewMatchRepository(a.DB, a.ReplicaDB),
+		Comment:       repositories.NewCommentRepository(a.DB),
+		Admin:         repositories.NewAdminRepository(a.DB),
+		UserSports:    repositories.NewUserSportsRepository(a.DB),
+		Reaction:      repositories.NewReactionRepository(a.DB),
+		Snapshot:      repositories.NewSnapshotRepository(a.DB),
+		Season:        repositories.NewSeasonRepository(a.DB),
+		Anonymization: repositories.NewAnonymizationRepository(a.DB),
+		ELOHistory:    repositories.NewELOHistoryRepository(a.DB),
+		Delivery:      repositories.NewDeliveryRepository(a.DB),
+		Usage:         repositories.NewUsageRepository(a.DB),
+		Legal:         repositories.NewLegalRepository(a.DB),
+		Webhook:       repositories.NewWebhookRepository(a.DB),
+		Notification:  repositories.NewUserNotificationRepository(a.DB),
+		Slack:         repositories.NewSlackRepository(a.DB),
+		Incident:      repositories.NewIncidentRepository(a.DB),
+		ELOReplay:     repositories.NewELOReplayRepository(a.DB),
+		TrustedClient: repositories.NewTrustedClientRepository(a.DB),
+		DataExport:    repositories.NewDataExportRepository(a.DB),
+		Challenge:     repositories.NewChallengeRepository(a.DB),
+		Deletion:      repositories.NewDeletionRequestRepository(a.DB),
+		ProfileSync:   repositories.NewProfileSyncRepository(a.DB),
+		Report:        repositories.NewReportRepository(a.DB),
+		MatchFlag:     repositories.NewMatchFlagRepository(a.DB),
+		Digest:        repositories.NewDigestRepository(a.DB),
+		Ranking:       repositories.NewLeaderboardRankingRepository(a.DB),
+	}
+	return nil
+}
+
+// initCache sets up the cache for leaderboards, sports and the status page
+// Redis shares it between instances and keeps it across restarts
+func (a *App) initCache() error {
+	var backend cache.Cache
+	switch a.Config.CacheBackend {
+	case cache.BackendRedis:
+		redisCache, err := cache.NewRedis(a.Config.RedisURL)
+		if err != nil {
+			return err
+		}
+		backend = redisCache
+	default:
+		backend = cache.NewInMemory()
+	}
+	a.Cache = cache.NewInstrumented(backend, a.Config.CacheBackend)
+	a.onShutdown("cache", func(ctx context.Context) error {
+		return a.Cache.Close()
+	})
+
+	slog.Info("Cache initialized", "backend", a.Config.CacheBackend)
+	return nil
+}
+
+func (a *App) initServices() error {
+	r := &a.Repos
+	s := &a.Services
+
+	s.Sport = services.NewSportService(a.DB, r.Ranking, a.Cache)
+	s.ELO = services.NewELOService(a.Config.ELOKFactor, a.Config.ForfeitELOFactor, s.Sport)
+	s.Activity = services.NewActivityMonitor(a.Config.ActiveHoursFrom, a.Config.ActiveHoursUntil)
+	s.Match = services.NewMatchService(a.DB, r.Match, r.User, r.UserSports, r.ELOHistory, r.Snapshot, r.Ranking, s.Sport, s.ELO, a.Cache, s.Activity)
+	s.Season = services.NewSeasonService(a.DB, r.Season, s.Match, s.Sport)
+	s.Anonymization = services.NewAnonymizationService(r.Anonymization, a.Config.AnonAdjectives, a.Config.AnonAnimals)
+	s.Stats = services.NewStatsService(r.Match, r.User, r.UserSports, s.Sport)
+	s.Recompute = services.NewRecomputeService(a.DB, r.ELOReplay, r.UserSports, r.ELOHistory, s.ELO, s.Match)
+	s.MatchImport = services.NewMatchImportService(a.DB, r.Match, r.User, r.UserSports, s.Sport, s.Match, s.Recompute)
+	s.ConfirmTokens = services.NewConfirmationTokenService(r.Match, a.Config.JWTSecret, time.Duration(a.Config.ConfirmTokenTTLMinutes)*time.Minute, a.Config.FrontendURL)
+	s.Calendar = services.NewCalendarService(r.Match, r.Challenge, r.User, s.Sport, a.Config.JWTSecret, a.Config.PublicAPIURL)
+	s.DataExport = services.NewDataExportService(a.DB, r.User, r.DataExport, a.Config.JWTSecret, a.Config.PublicAPIURL)
+	s.Challenge = services.NewChallengeService(a.DB, r.Challenge, r.User, s.Sport, s.Match)
+	s.Deletion = services.NewAccountDeletionService(a.DB, r.User, r.Deletion, s.Match)
+	s.ProfileSync = services.NewProfileSyncService(
+		intra.NewClient(a.Config.FTClientUID, a.Config.FTClientSecret),
+		r.ProfileSync, r.User, s.Match,
+		time.Duration(a.Config.ProfileSyncIntervalHours)*time.Hour,
+	)
+	s.Abuse = services.NewAbuseDetectionService(r.MatchFlag)
+	s.Digest = services.NewDigestService(r.Digest, r.Snapshot, s.Match, s.Sport)
+	return nil
+}
+
+// initRevocation sets up the token deny-list for logout/ban revocation
+// Redis keeps it consistent across instances
+func (a *App) initRevocation() error {
+	var store revocation.Store
+	if a.Config.RedisURL != "" {
+		redisStore, err := revocation.NewRedisStore(a.Config.RedisURL)
+		if err != nil {
+			return fmt.Errorf("failed to initialize token revocation store: %w", err)
+		}
+		store = redisStore
+	} else {
+		slog.Warn("REDIS_URL not set, token revocation is local to this instance")
+		store = revocation.NewMemoryStore()
+	}
+	a.onShutdown("token_revocation_store", func(ctx context.Context) error {
+		return store.Close()
+	})
+
+	a.DenyList = revocation.NewDenyList(store)
+	a.shutdown.RegisterSimple("token_deny_list", a.DenyList.Close)
+	return nil
+}
+
+// initRealtime sets up the hub for live match pages and the global /api/ws feed
+func (a *App) initRealtime() error {
+	a.Hub = realtime.NewHub(a.Config.AllowedOrigins)
+	return nil
+}
+
+// initNotifications registers the configured outbound notification channels, the
+// webhook dispatcher, the rank watcher and the Discord announcements, then routes
+// match events to all of them
+func (a *App) initNotifications() error {
+	r := &a.Repos
+	s := &a.Services
+
+	templates, err := notifications.LoadTemplates(a.Config.NotificationTemplatesDir)
+	if err != nil {
+		return err
+	}
+	a.Templates = templates
+
+	a.Notifier = notifications.NewNotifier(r.Delivery)
+	discordCfg := integrations.DiscordConfig{
+		SportWebhooks: a.Config.DiscordSportWebhooks,
+		ShowLogins:    a.Config.DiscordShowLogins,
+		FrontendURL:   a.Config.FrontendURL,
+		Language:      a.Config.NotificationLanguage,
+	}
+	if a.Config.DiscordWebhookURL != "" {
+		a.Notifier.Register(notifications.NewDiscordChannel("discord", a.Config.DiscordWebhookURL))
+		discordCfg.DefaultChannel = "discord"
+	} else {
+		slog.Info("DISCORD_WEBHOOK_URL not set, Discord notifications disabled")
+	}
+	a.Discord = integrations.NewDiscordAnnouncer(discordCfg, a.Notifier, a.Templates, r.Match, r.User, r.Snapshot, r.Delivery, s.Match, s.Sport, s.Anonymization)
+
+	slackChannel := ""
+	if a.Config.SlackWebhookURL != "" {
+		slackChannel = "slack"
+		a.Notifier.Register(notifications.NewSlackChannel(slackChannel, a.Config.SlackWebhookURL))
+	}
+	a.Digests = integrations.NewDigestPoster(a.Discord, slackChannel, a.Config.DigestPush)
+
+	a.Webhooks = notifications.NewWebhookDispatcher(r.Webhook, r.Delivery, func(sport string) ([]models.LeaderboardEntry, error) {
+		entries, _, err := s.Match.GetLeaderboardPage(context.Background(), sport, 3, 0)
+		return entries, err
+	})
+	a.onShutdown("webhooks", a.Webhooks.Close)
+
+	a.Inbox = notifications.NewInbox(r.Notification, r.User, a.Templates, a.Hub)
+	a.Ranks = notifications.NewRankWatcher(func(sport string) ([]models.LeaderboardEntry, error) {
+		return s.Match.GetLeaderboard(context.Background(), sport)
+	}, a.Inbox, rankNotificationDebounce)
+	a.onShutdown("rank_watcher", a.Ranks.Close)
+
+	if sports, err := s.Sport.GetAllActiveSports(); err == nil {
+		for _, sport := range sports {
+			a.Webhooks.PrimePodiums(sport.ID)
+			a.Ranks.Prime(sport.ID)
+		}
+	} else {
+		slog.Warn("Failed to load sports, rank change detection starts without a baseline", "error", err)
+	}
+
+	publishers := services.Publishers{a.Hub, a.Webhooks, a.Ranks, s.Activity}
+	if a.Discord.Enabled() {
+		publishers = append(publishers, a.Discord)
+	}
+	s.Match.SetEventPublisher(publishers)
+	return nil
+}
+
+// initJobs registers the background jobs (expiry, snapshots, retention, archival, consistency, data exports, account deletions, ban expiry, abuse scan, digests, profile sync, rating decay, usage)
+func (a *App) initJobs() error {
+	cfg := a.Config
+	r := &a.Repos
+	s := &a.Services
+
+	a.Scheduler = jobs.NewScheduler()
+	a.Scheduler.Register(jobs.PendingMatchExpiry(r.Match, time.Duration(cfg.PendingMatchExpiryHours)*time.Hour))
+	a.Scheduler.Register(jobs.LeaderboardSnapshots(s.Sport, s.Match, r.Snapshot))
+	a.Scheduler.Register(jobs.FingerprintRetention(r.Match, time.Duration(cfg.FingerprintRetentionDays)*24*time.Hour))
+	a.Scheduler.Register(jobs.MatchArchival(r.Match, r.Season, time.Duration(cfg.MatchArchiveAfterDays)*24*time.Hour))
+	a.Scheduler.Register(jobs.StatsConsistency(r.UserSports, s.Match))
+	a.Scheduler.Register(jobs.DeliveryLogRetention(r.Delivery, deliveryLogRetention))
+	a.Scheduler.Register(jobs.UserNotificationRetention(r.Notification, userNotificationRetention))
+	a.Scheduler.Register(jobs.DataExports(s.DataExport, a.Inbox))
+	a.Scheduler.Register(jobs.AccountDeletions(s.Deletion))
+	a.Scheduler.Register(jobs.AnonymousNames(s.Anonymization))
+	a.Scheduler.Register(jobs.BanExpiry(r.Admin, s.Match, a.Inbox))
+	a.Scheduler.Register(jobs.AbuseScan(s.Abuse))
+	a.Scheduler.Register(jobs.Digests(s.Digest, a.Digests))
+	if cfg.ProfileSyncIntervalHours > 0 {
+		a.Scheduler.Register(jobs.ProfileSync(s.ProfileSync))
+	}
+	if a.Discord.Enabled() {
+		a.Scheduler.Register(jobs.DiscordWeeklySummary(a.Discord))
+	}
+	if policy := a.decayPolicy(); policy.Enabled {
+		a.Scheduler.Register(jobs.RatingDecay(r.UserSports, s.Match, policy))
+	}
+
+	// Per-user API usage is counted in memory and flushed by a job; the last
+	// counts are flushed on shutdown once the HTTP server has drained
+	a.Usage = middleware.NewUsageTracker(cfg.UsageSampleRate)
+	usageFlush := jobs.UsageFlush(a.Usage, r.Usage, time.Duration(cfg.UsageRetentionDays)*24*time.Hour)
+	a.Scheduler.Register(usageFlush)
+	a.onShutdown("usage_flush", usageFlush.Run)
+
+	a.onShutdown("background_jobs", a.Scheduler.Stop)
+	return nil
+}
+
+func (a *App) initHandlers() error {
+	cfg := a.Config
+	r := &a.Repos
+	s := &a.Services
+
+	// Rate limit tiers of kiosks, display screens and tooling, managed by admins at runtime
+	a.Tiers = middleware.NewClientTiers(r.TrustedClient)
+
+	a.Handlers = Handlers{
+		Auth:          handlers.NewAuthHandler(cfg, r.User, s.Match, a.DenyList, s.Deletion, r.Legal, s.Anonymization),
+		Match:         handlers.NewMatchHandler(s.Match, r.Match, r.Comment, s.Anonymization, r.Reaction, a.Hub, s.Season, a.Cache, s.Activity, s.ConfirmTokens, r.User, a.Inbox, s.Sport),
+		Admin:         handlers.NewAdminHandler(r.Admin, r.User, r.Match, a.DenyList, a.Webhooks, s.Match, s.Recompute, r.Comment, r.Reaction, a.Hub, r.MatchFlag, s.MatchImport, a.Inbox),
+		Health:        handlers.NewHealthHandler(a.DB, a.ReplicaDB, a.Scheduler),
+		GDPR:          handlers.NewGDPRHandler(a.DB, r.User, r.Match, r.Comment, s.Match, s.DataExport, s.Anonymization, s.Deletion),
+		Sport:         handlers.NewSportHandler(s.Sport, r.Admin),
+		Anonymization: handlers.NewAnonymizationHandler(r.Anonymization, s.Anonymization, r.Admin),
+		Season:        handlers.NewSeasonHandler(s.Season, r.Admin),
+		Realtime:      handlers.NewRealtimeHandler(a.Hub),
+		ELOHistory:    handlers.NewELOHistoryHandler(r.ELOHistory, r.Snapshot, r.User, s.Sport, a.decayPolicy()),
+		UserStats:     handlers.NewUserStatsHandler(r.User, r.UserSports, s.Stats, s.Sport),
+		Notification:  handlers.NewNotificationHandler(a.Notifier, a.Templates, cfg.NotificationLanguage, r.Delivery, r.Admin),
+		Usage:         handlers.NewUsageHandler(r.Usage, cfg.UsageSampleRate, cfg.UsageRetentionDays),
+		Legal:         handlers.NewLegalHandler(r.Legal, r.Admin, r.User),
+		Compare:       handlers.NewCompareHandler(r.User, r.UserSports, r.Match, r.ELOHistory, s.Match, s.Sport),
+		Webhook:       handlers.NewWebhookHandler(r.Webhook, r.Delivery, r.Admin, cfg.IsProduction()),
+		Inbox:         handlers.NewUserNotificationHandler(r.Notification, r.User, a.Hub),
+		TrustedClient: handlers.NewTrustedClientHandler(r.TrustedClient, r.Admin, a.Tiers),
+		Challenge:     handlers.NewChallengeHandler(s.Challenge, s.Sport, s.ConfirmTokens, r.User, a.Inbox),
+		Report:        handlers.NewReportHandler(r.Report, r.Match, r.Comment, r.User, r.Admin, a.Inbox),
+		Digest:        handlers.NewDigestHandler(r.Digest, r.User, s.Sport, s.Anonymization),
+		Stats:         handlers.NewStatsHandler(r.Match, s.Match, s.Sport, s.Anonymization),
+		Calendar:      handlers.NewCalendarHandler(s.Calendar),
+		Cache:         handlers.NewCacheHandler(a.Cache),
+		RateLimit:     handlers.NewRateLimitHandler(a.Limiters, r.Admin),
+	}
+	a.Handlers.Status = handlers.NewStatusHandler(a.Handlers.Health, r.Incident, r.Admin, a.Cache)
+
+	if cfg.ChaosEnabled {
+		slog.Warn("CHAOS_ENABLED is set, fault injection endpoints are exposed", "environment", cfg.Environment)
+		a.Chaos = middleware.NewChaosInjector()
+		a.Handlers.Chaos = handlers.NewChaosHandler(a.Chaos, r.Admin)
+	}
+
+	if cfg.SlackSigningSecret != "" {
+		a.Handlers.Slack = handlers.NewSlackHandler(r.Slack, r.User, r.Match, s.Match, cfg.SlackSigningSecret)
+	}
+
+	if len(cfg.KioskAPIKeys) > 0 {
+		a.Handlers.Kiosk = handlers.NewKioskHandler(r.User, a.DenyList, cfg)
+	}
+
+	if cfg.APIDocsEnabled {
+		a.Handlers.Docs = handlers.NewDocsHandler()
+	}
+	return nil
+}
+
+// initRateLimits sets up the rate limiters; Redis shares the counters between instances
+// RATE_LIMIT_<NAME> overrides the limit of an endpoint class, or gives a route limiter of its own
+func (a *App) initRateLimits() error {
+	multiplier := a.Config.TrustedRateMultiplier
+	a.Limiters = middleware.NewRateLimitRegistry()
+
+	newLimiter := func(name string, limit config.RateLimit) rateLimiter {
+		rl := middleware.NewRateLimiter(limit.Requests, limit.Window).WithTrustedTier(multiplier)
+		a.shutdown.RegisterSimple(name+"_rate_limiter", rl.Stop)
+		a.Limiters.Register(name, rl)
+		return func(keyFunc func(*gin.Context) string) gin.HandlerFunc {
+			return middleware.RateLimitMiddleware(rl, keyFunc)
+		}
+	}
+
+	if a.Config.RateLimitBackend == "redis" {
+		client, err := middleware.NewGoRedisClient(a.Config.RedisURL)
+		if err != nil {
+			return fmt.Errorf("failed to initialize rate limit store: %w", err)
+		}
+		a.onShutdown("rate_limit_store", func(ctx context.Context) error {
+			return client.Close()
+		})
+
+		store := middleware.NewRedisRateLimitStore(client)
+		newLimiter = func(name string, limit config.RateLimit) rateLimiter {
+			rl := middleware.NewDistributedRateLimiter(store, limit.Requests, limit.Window, "ratelimit:"+name).WithTrustedTier(multiplier)
+			a.Limiters.Register(name, rl)
+			return func(keyFunc func(*gin.Context) string) gin.HandlerFunc {
+				return middleware.DistributedRateLimitMiddleware(rl, keyFunc)
+			}
+		}
+		slog.Info("Rate limits shared through Redis")
+	}
+
+	class := func(name string, requestsPerMinute int) rateLimiter {
+		limit, ok := a.Config.RateLimits[name]
+		if !ok {
+			limit = config.RateLimit{Requests: requestsPerMinute, Window: time.Minute}
+		}
+		return newLimiter(name, limit)
+	}
+	a.limits = rateLimits{
+		strict:   class("strict", 10),
+		moderate: class("moderate", 30),
+		loose:    class("loose", 100),
+		routes:   make(map[string]rateLimiter),
+	}
+
+	for name, limit := range a.Config.RateLimits {
+		switch name {
+		case "strict", "moderate", "loose":
+			continue
+		}
+		a.limits.routes[name] = newLimiter(name, limit)
+		slog.Info("Route rate limit overridden", "route", name, "requests", limit.Requests, "window", limit.Window.String())
+	}
+	return nil
+}
+
+// initServer builds the router and the HTTP server with graceful shutdown
+func (a *App) initServer() error {
+	a.server = server.NewServer(server.ServerConfig{
+		Addr:            ":" + a.Config.Port,
+		Handler:         a.routes(),
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: shutdownTimeout,
+		ShutdownManager: a.shutdown,
+	})
+	return nil
+}
+
+// decayPolicy is the rating decay of inactive players as configured
+func (a *App) decayPolicy() models.ELODecayPolicy {
+	return models.ELODecayPolicy{
+		Enabled:    a.Config.ELODecayPoints > 0,
+		Points:     a.Config.ELODecayPoints,
+		AfterWeeks: a.Config.ELODecayAfterWeeks,
+	}
+}
@@ -0,0 +1,390 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// routes builds the Gin router with all middleware and routes
+func (a *App) routes() *gin.Engine {
+	cfg := a.Config
+	h := &a.Handlers
+
+	// Setup Gin router
+	router := gin.New()
+
+	// Request ID first so recovery, access log and error responses can include it
+	router.Use(middleware.RequestIDMiddleware())
+
+	// One span per request, parent of the service and SQL spans; health probes are not traced
+	if cfg.OTelEndpoint != "" {
+		router.Use(otelgin.Middleware(cfg.OTelServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+			return !strings.HasPrefix(r.URL.Path, "/health")
+		})))
+	}
+
+	// Add recovery middleware with proper error boundaries
+	router.Use(middleware.RecoveryMiddleware())
+	router.Use(gin.LoggerWithFormatter(middleware.AccessLogFormatter))
+
+	// Security headers middleware (HSTS, XSS protection, etc.) - GDPR/security compliance
+	router.Use(middleware.SecurityHeaders(cfg.CookieSecure))
+
+	// HTTPS redirect in production
+	router.Use(middleware.HTTPSRedirect(cfg.CookieSecure))
+
+	// Per-user API usage (authenticated requests only, route templates only)
+	router.Use(a.Usage.Middleware())
+
+	// Fault injection for staging (CHAOS_ENABLED), applied before auth and handlers
+	if a.Chaos != nil {
+		router.Use(a.Chaos.Middleware())
+	}
+
+	// Brotli or gzip compression of text responses above a size threshold (COMPRESSION_*)
+	if cfg.CompressionLevel > 0 {
+		router.Use(middleware.CompressionMiddleware(cfg.CompressionLevel, cfg.CompressionMinSize, cfg.CompressionTypes))
+	}
+
+	// CORS middleware
+	router.Use(cors.New(cors.Config{
+		AllowOrigins:     cfg.AllowedOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-None-Match", middleware.ClientKeyHeader, middleware.KioskKeyHeader, middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", "X-Next-Cursor", "X-Total-Count", "Deprecation", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", middleware.RequestIDHeader},
+		AllowCredentials: true,
+	}))
+
+	// Trusted clients (X-Client-Key) get higher limits or none; see /api/admin/trusted-clients
+	router.Use(a.Tiers.Middleware())
+
+	// Rate limiters (local or shared through Redis, see initRateLimits); route() applies RATE_LIMIT_<ROUTE> overrides
+	strict, moderate, loose, route := a.limits.strict, a.limits.moderate, a.limits.loose, a.limits.route
+
+	// Hashed client fingerprints for anti-abuse review of submissions/confirmations
+	fingerprint := middleware.ClientFingerprintMiddleware(cfg.JWTSecret)
+
+	// 304 Not Modified for polled feeds whose response did not change
+	etag := middleware.ETagMiddleware()
+
+	// Public routes
+	api := router.Group("/api")
+	{
+		// Auth routes
+		auth := api.Group("/auth")
+		{
+			auth.GET("/login", h.Auth.Login)
+			auth.GET("/callback", h.Auth.Callback)
+			auth.POST("/logout", h.Auth.Logout) // Logout endpoint to clear httpOnly cookie
+		}
+
+		// Sports configuration - public endpoint for dynamic sport list
+		sports := api.Group("/sports")
+		{
+			sports.GET("", h.Sport.GetAllSports)
+			sports.GET("/:id", h.Sport.GetSport)
+		}
+
+		// Public leaderboard - with optional auth to show real data to logged-in users
+		api.GET("/leaderboard/:sport", middleware.OptionalAuthMiddleware(cfg.JWTSecret, a.DenyList), etag, h.Match.GetLeaderboard)
+
+		// Podium for the intra dashboard widget - tiny cached response for frequent polling
+		api.GET("/leaderboard/:sport/top", middleware.OptionalAuthMiddleware(cfg.JWTSecret, a.DenyList), etag, h.Match.GetLeaderboardTop)
+
+		// Players still in their placement matches, for finding new opponents
+		api.GET("/leaderboard/:sport/unranked", loose(middleware.IPKeyFunc), middleware.OptionalAuthMiddleware(cfg.JWTSecret, a.DenyList), h.Match.GetUnrankedPlayers)
+
+		// Daily and weekly digests - upsets, most active players and rank movers
+		api.GET("/digest/:sport/latest", loose(middleware.IPKeyFunc), middleware.OptionalAuthMiddleware(cfg.JWTSecret, a.DenyList), h.Digest.GetLatestDigest)
+
+		// Global figures for the landing page
+		api.GET("/stats", loose(middleware.IPKeyFunc), middleware.OptionalAuthMiddleware(cfg.JWTSecret, a.DenyList), h.Stats.GetGlobalStats)
+
+		// Rating distribution for "better than X% of campus" widgets
+		api.GET("/stats/:sport/distribution", loose(middleware.IPKeyFunc), middleware.OptionalAuthMiddleware(cfg.JWTSecret, a.DenyList), h.Stats.GetDistribution)
+
+		// Matches by weekday and hour for "when is the table free" heatmaps
+		api.GET("/stats/:sport/activity", loose(middleware.IPKeyFunc), h.Stats.GetActivity)
+
+		// Seasons - public list; archived standings via /leaderboard/:sport?season=<id>
+		api.GET("/seasons", h.Season.ListSeasons)
+
+		// How ratings of inactive players decay, for profile hints
+		api.GET("/elo/decay-policy", loose(middleware.IPKeyFunc), h.ELOHistory.GetDecayPolicy)
+
+		// Legal documents (impressum, datenschutz, nutzungsbedingungen) per language and version
+		legal := api.Group("/legal", loose(middleware.IPKeyFunc))
+		{
+			legal.GET("/:doc", h.Legal.GetDocument)
+			legal.GET("/:doc/versions", h.Legal.ListVersions)
+		}
+
+		// Public status page - coarse component states and admin incident notes, cached
+		api.GET("/status", loose(middleware.IPKeyFunc), h.Status.GetStatus)
+
+		// Data export archives, authorized by the signed link from the ready notification
+		api.GET("/data-exports/:id/download", route("data_export", strict)(middleware.IPKeyFunc), h.GDPR.DownloadDataExport)
+
+		// Calendar subscription feed, authorized by the signed token from /users/me/calendar
+		api.GET("/users/me/matches.ics", loose(middleware.IPKeyFunc), middleware.OptionalAuthMiddleware(cfg.JWTSecret, a.DenyList), h.Calendar.GetFeed)
+
+		// Slack slash command (/elo), authenticated by Slack's request signature
+		if h.Slack != nil {
+			api.POST("/integrations/slack", loose(middleware.IPKeyFunc), h.Slack.HandleCommand)
+		}
+
+		// Table-side kiosks (KIOSK_API_KEYS) submit matches for the student who typed their login
+		// Kiosk sessions are rejected everywhere else; the opponent confirms from their own account
+		if h.Kiosk != nil {
+			kiosk := api.Group("/kiosk", middleware.KioskKeyMiddleware(cfg.KioskAPIKeys))
+			{
+				kiosk.POST("/session", strict(middleware.IPKeyFunc), h.Kiosk.CreateSession)
+				kiosk.DELETE("/session", h.Kiosk.EndSession)
+				kiosk.POST("/matches", middleware.KioskSessionMiddleware(cfg.JWTSecret, a.DenyList), middleware.BannedUserMiddleware(a.Repos.User), route("match_submit", strict)(middleware.CombinedKeyFunc), fingerprint, h.Match.SubmitMatch)
+			}
+		}
+
+		// OpenAPI spec and Swagger UI (API_DOCS_ENABLED)
+		if h.Docs != nil {
+			api.GET("/docs", loose(middleware.IPKeyFunc), h.Docs.UI)
+			api.GET("/docs/openapi.yaml", loose(middleware.IPKeyFunc), h.Docs.Spec)
+		}
+	}
+
+	// Protected routes
+	protected := api.Group("")
+	protected.Use(middleware.AuthMiddleware(cfg.JWTSecret, a.DenyList))
+	protected.Use(middleware.BannedUserMiddleware(a.Repos.User))
+	{
+		// Auth
+		protected.GET("/auth/me", h.Auth.Me)
+		protected.GET("/users", h.Auth.GetUsers)
+		protected.GET("/users/:id/elo-history", loose(middleware.IPKeyFunc), h.ELOHistory.GetELOHistory)
+		protected.GET("/users/:id/rank-history", loose(middleware.IPKeyFunc), h.ELOHistory.GetRankHistory)
+		protected.GET("/users/:id/stats", loose(middleware.IPKeyFunc), h.UserStats.GetUserStats)
+		protected.GET("/users/:id/profile", loose(middleware.IPKeyFunc), h.UserStats.GetUserProfile)
+		protected.GET("/compare", loose(middleware.IPKeyFunc), h.Compare.ComparePlayers)
+
+		// GDPR endpoints (Art. 15 & 17)
+		protected.GET("/users/me/data-export", route("data_export", strict)(middleware.CombinedKeyFunc), h.GDPR.ExportUserData)
+		protected.POST("/users/me/data-exports", route("data_export", strict)(middleware.CombinedKeyFunc), h.GDPR.RequestDataExport)
+		protected.GET("/users/me/data-exports/latest", loose(middleware.IPKeyFunc), h.GDPR.GetDataExport)
+		protected.DELETE("/users/me/delete", h.GDPR.DeleteAccount)
+		protected.POST("/users/me/delete/cancel", h.GDPR.CancelDeletion)
+		protected.POST("/users/me/erase", route("erase", strict)(middleware.CombinedKeyFunc), h.GDPR.EraseData) // partial erasure, account stays
+
+		// Deactivation - hidden from leaderboards until the next login, nothing is erased
+		protected.POST("/users/me/deactivate", strict(middleware.CombinedKeyFunc), h.Auth.DeactivateAccount)
+
+		// Acceptance of the terms and privacy notice, asked for at login when a new version is out
+		protected.GET("/users/me/legal", loose(middleware.IPKeyFunc), h.Legal.GetMyLegalStatus)
+		protected.POST("/legal/:doc/accept", moderate(middleware.CombinedKeyFunc), h.Legal.AcceptDocument)
+
+		// API usage insights for the current user
+		protected.GET("/users/me/usage", loose(middleware.IPKeyFunc), h.Usage.GetMyUsage)
+
+		// Notification inbox (rank changes), also pushed live over the WebSocket
+		protected.GET("/users/me/notifications", loose(middleware.IPKeyFunc), h.Inbox.ListNotifications)
+		protected.POST("/users/me/notifications/read", moderate(middleware.CombinedKeyFunc), h.Inbox.MarkRead)
+		protected.GET("/users/me/notifications/ws", loose(middleware.IPKeyFunc), h.Inbox.SubscribeNotifications)
+		protected.GET("/users/me/notifications/language", loose(middleware.IPKeyFunc), h.Inbox.GetLanguage)
+		protected.PUT("/users/me/notifications/language", moderate(middleware.CombinedKeyFunc), h.Inbox.SetLanguage)
+
+		// Calendar subscription link (iCal feed of matches and challenges)
+		protected.GET("/users/me/calendar", loose(middleware.IPKeyFunc), h.Calendar.GetFeedLink)
+
+		// Account linking after 42 account migrations (new intra ID, same login)
+		protected.POST("/users/me/relink", h.Auth.RelinkPreviousAccount)
+
+		// Slack account link for the /elo slash command
+		if h.Slack != nil {
+			protected.GET("/users/me/slack", h.Slack.GetLink)
+			protected.POST("/users/me/slack/link-code", moderate(middleware.CombinedKeyFunc), h.Slack.CreateLinkCode)
+			protected.DELETE("/users/me/slack", h.Slack.Unlink)
+		}
+
+		// Matches - apply strict rate limiting to mutation endpoints
+		protected.POST("/matches", route("match_submit", strict)(middleware.CombinedKeyFunc), fingerprint, h.Match.SubmitMatch)
+		protected.POST("/matches/forfeit", route("match_submit", strict)(middleware.CombinedKeyFunc), fingerprint, h.Match.SubmitForfeit)
+		protected.GET("/matches", loose(middleware.IPKeyFunc), etag, h.Match.GetMatches)
+		protected.GET("/matches/:id", loose(middleware.IPKeyFunc), h.Match.GetMatch)
+		protected.GET("/matches/:id/elo-breakdown", loose(middleware.IPKeyFunc), h.Match.GetELOBreakdown)
+		protected.POST("/matches/:id/confirm", route("match_confirm", strict)(middleware.CombinedKeyFunc), fingerprint, h.Match.ConfirmMatch)
+		// QR code / deep link: GET shows the match, only the POST with the token confirms it
+		protected.GET("/matches/confirm", loose(middleware.IPKeyFunc), h.Match.GetMatchByConfirmToken)
+		protected.POST("/matches/confirm", route("match_confirm", strict)(middleware.CombinedKeyFunc), fingerprint, h.Match.ConfirmMatchByToken)
+		protected.POST("/matches/:id/deny", strict(middleware.CombinedKeyFunc), h.Match.DenyMatch)
+		protected.POST("/matches/:id/cancel", strict(middleware.CombinedKeyFunc), h.Match.CancelMatch)
+
+		// Counter-proposals - corrected score attached when denying, answered by the submitter
+		protected.GET("/matches/:id/counter", loose(middleware.IPKeyFunc), h.Match.GetCounterProposal)
+		protected.POST("/matches/:id/counter/accept", route("match_confirm", strict)(middleware.CombinedKeyFunc), fingerprint, h.Match.AcceptCounterProposal)
+		protected.POST("/matches/:id/counter/reject", strict(middleware.CombinedKeyFunc), h.Match.RejectCounterProposal)
+
+		// Challenges - scheduled matches; the result becomes a pending match
+		protected.POST("/challenges", route("challenges", moderate)(middleware.CombinedKeyFunc), h.Challenge.CreateChallenge)
+		protected.GET("/challenges", loose(middleware.IPKeyFunc), h.Challenge.ListChallenges)
+		protected.GET("/challenges/:id", loose(middleware.IPKeyFunc), h.Challenge.GetChallenge)
+		protected.POST("/challenges/:id/accept", route("challenges", moderate)(middleware.CombinedKeyFunc), h.Challenge.AcceptChallenge)
+		protected.POST("/challenges/:id/decline", route("challenges", moderate)(middleware.CombinedKeyFunc), h.Challenge.DeclineChallenge)
+		protected.POST("/challenges/:id/cancel", route("challenges", moderate)(middleware.CombinedKeyFunc), h.Challenge.CancelChallenge)
+		protected.POST("/challenges/:id/result", route("match_submit", strict)(middleware.CombinedKeyFunc), fingerprint, h.Challenge.SubmitResult)
+
+		// Comments - moderate rate limiting
+		protected.POST("/matches/:id/comments", route("comments", moderate)(middleware.CombinedKeyFunc), h.Match.AddComment)
+		protected.GET("/matches/:id/comments", loose(middleware.IPKeyFunc), h.Match.GetComments)
+		protected.DELETE("/matches/:id/comments/:commentId", route("comments", moderate)(middleware.CombinedKeyFunc), h.Match.DeleteComment)
+
+		// Reactions
+		protected.GET("/matches/:id/reactions", loose(middleware.IPKeyFunc), h.Match.GetReactions)
+		protected.POST("/matches/:id/reactions", route("reactions", moderate)(middleware.CombinedKeyFunc), h.Match.AddReaction)
+		protected.DELETE("/matches/:id/reactions", route("reactions", moderate)(middleware.CombinedKeyFunc), h.Match.RemoveReaction)
+
+		// Abuse reports on matches, comments and players
+		protected.POST("/reports", route("reports", strict)(middleware.CombinedKeyFunc), h.Report.CreateReport)
+
+		// Live match channel (new comments and reactions over WebSocket)
+		protected.GET("/matches/:id/ws", loose(middleware.IPKeyFunc), h.Match.SubscribeMatch)
+
+		// Global realtime feed (leaderboard, matches, comments, reactions)
+		protected.GET("/ws", loose(middleware.IPKeyFunc), h.Realtime.Subscribe)
+	}
+
+	// Admin routes - require authentication + a staff role; each route needs a permission
+	// of that role (see middleware.HasPermission), except the read-only dashboard and audit log
+	admin := api.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(cfg.JWTSecret, a.DenyList))
+	admin.Use(middleware.AdminMiddleware(a.Repos.User))
+	can := middleware.RequirePermission
+	{
+		// System health dashboard
+		admin.GET("/health", h.Admin.GetSystemHealth)
+		admin.GET("/analytics", h.Admin.GetAnalytics)
+
+		// User management
+		admin.GET("/users/banned", can(models.PermissionBanUsers), h.Admin.GetBannedUsers)
+		admin.POST("/users/ban", can(models.PermissionBanUsers), h.Admin.BanUser)
+		admin.POST("/users/:id/unban", can(models.PermissionBanUsers), h.Admin.UnbanUser)
+		admin.POST("/users/:id/link-intra", can(models.PermissionManageUsers), h.Admin.LinkIntraID)
+		admin.POST("/users/merge", can(models.PermissionManageUsers), h.Admin.MergeUsers)
+		admin.POST("/users/:id/role", can(models.PermissionManageRoles), h.Admin.SetUserRole)
+
+		// ELO management
+		admin.POST("/elo/adjust", can(models.PermissionAdjustELO), h.Admin.AdjustELO)
+		admin.POST("/elo/penalty", can(models.PermissionAdjustELO), h.Admin.PenalizeELO)
+		admin.GET("/elo/adjustments", can(models.PermissionAdjustELO), h.Admin.GetELOAdjustments)
+		admin.POST("/elo/recompute", can(models.PermissionAdjustELO), h.Admin.RecomputeELO)
+		admin.POST("/leaderboard/rebuild", can(models.PermissionAdjustELO), h.Admin.RebuildLeaderboard)
+
+		// Match management
+		admin.GET("/matches/disputed", can(models.PermissionResolveDisputes), h.Admin.GetDisputedMatches)
+		admin.GET("/matches/confirmed", can(models.PermissionManageMatches), h.Admin.GetConfirmedMatches)
+		admin.GET("/matches/anomalies", can(models.PermissionManageMatches), h.Admin.GetMatchAnomalies)
+		admin.GET("/matches/flagged", can(models.PermissionManageMatches), h.Admin.GetFlaggedMatches)
+		admin.POST("/matches/flags/:id/review", can(models.PermissionManageMatches), h.Admin.ReviewMatchFlag)
+		admin.GET("/matches/inconsistent", can(models.PermissionManageMatches), h.Admin.GetInconsistentMatches)
+		admin.GET("/matches/deleted", can(models.PermissionManageMatches), h.Admin.GetDeletedMatches)
+		admin.POST("/matches/repair-winners", can(models.PermissionManageMatches), h.Admin.RepairMatchWinners)
+		admin.POST("/matches/forfeit", can(models.PermissionManageMatches), h.Admin.RecordForfeit)
+		admin.POST("/matches/import", can(models.PermissionManageSystem), h.Admin.ImportMatchesCSV)
+		admin.PUT("/matches/:id", can(models.PermissionManageMatches), h.Admin.EditMatch)
+		admin.PUT("/matches/:id/status", can(models.PermissionResolveDisputes), h.Admin.UpdateMatchStatus)
+		admin.POST("/matches/:id/revert", can(models.PermissionManageMatches), h.Admin.RevertMatch)
+		admin.POST("/matches/:id/restore", can(models.PermissionManageMatches), h.Admin.RestoreMatch)
+		admin.DELETE("/matches/:id", can(models.PermissionManageMatches), h.Admin.DeleteMatch)
+
+		// Comment and reaction moderation
+		admin.DELETE("/comments/:id", can(models.PermissionModerateComments), h.Admin.DeleteComment)
+		admin.DELETE("/users/:id/comments", can(models.PermissionModerateComments), h.Admin.DeleteUserComments)
+		admin.DELETE("/matches/:id/reactions", can(models.PermissionModerateComments), h.Admin.RemoveReactions)
+		admin.GET("/users/muted", can(models.PermissionModerateComments), h.Admin.GetMutedUsers)
+		admin.POST("/users/:id/mute", can(models.PermissionModerateComments), h.Admin.MuteUser)
+		admin.DELETE("/users/:id/mute", can(models.PermissionModerateComments), h.Admin.UnmuteUser)
+
+		// Abuse report queue
+		admin.GET("/reports", can(models.PermissionHandleReports), h.Report.GetReports)
+		admin.POST("/reports/:id/resolve", can(models.PermissionHandleReports), h.Report.ResolveReport)
+		admin.POST("/reports/:id/dismiss", can(models.PermissionHandleReports), h.Report.DismissReport)
+
+		// Audit log
+		admin.GET("/audit-log", h.Admin.GetAuditLog)
+
+		// Legal documents - each publish creates a new immutable version
+		admin.POST("/legal/:doc", can(models.PermissionManageSystem), h.Legal.PublishDocument)
+
+		// Seasons
+		admin.POST("/seasons", can(models.PermissionManageSystem), h.Season.OpenSeason)
+		admin.POST("/seasons/:id/close", can(models.PermissionManageSystem), h.Season.CloseSeason)
+
+		// Anonymization vocabulary (global and per-campus)
+		admin.GET("/anonymization/words", can(models.PermissionManageSystem), h.Anonymization.ListWords)
+		admin.POST("/anonymization/words", can(models.PermissionManageSystem), h.Anonymization.AddWord)
+		admin.DELETE("/anonymization/words/:id", can(models.PermissionManageSystem), h.Anonymization.DeleteWord)
+
+		// CSV exports
+		admin.GET("/export/matches", can(models.PermissionManageSystem), h.Admin.ExportMatchesCSV)
+		admin.GET("/export/users", can(models.PermissionManageUsers), h.Admin.ExportUsersCSV)
+
+		// Hit rates of the leaderboard, sport and status caches
+		admin.GET("/cache", can(models.PermissionManageSystem), h.Cache.GetStats)
+
+		// Rate limits of a user or IP, e.g. to unblock players after a burst on tournament day
+		admin.GET("/rate-limits", can(models.PermissionManageSystem), h.RateLimit.GetRateLimits)
+		admin.POST("/rate-limits/reset", can(models.PermissionManageSystem), h.RateLimit.ResetRateLimits)
+
+		// API usage across users, to spot misbehaving clients
+		admin.GET("/usage", can(models.PermissionManageSystem), h.Usage.GetUsageOverview)
+
+		// Notification delivery log and test sends
+		admin.GET("/deliveries", can(models.PermissionManageSystem), h.Notification.ListDeliveries)
+		admin.POST("/deliveries/test/:channel", can(models.PermissionManageSystem), strict(middleware.UserOrIPKeyFunc), h.Notification.SendTest)
+
+		// Outbound webhooks
+		admin.GET("/webhooks", can(models.PermissionManageSystem), h.Webhook.ListWebhooks)
+		admin.POST("/webhooks", can(models.PermissionManageSystem), h.Webhook.CreateWebhook)
+		admin.PUT("/webhooks/:id", can(models.PermissionManageSystem), h.Webhook.UpdateWebhook)
+		admin.DELETE("/webhooks/:id", can(models.PermissionManageSystem), h.Webhook.DeleteWebhook)
+		admin.GET("/webhooks/:id/deliveries", can(models.PermissionManageSystem), h.Webhook.ListWebhookDeliveries)
+
+		// Sport management and configuration transfer between campuses
+		admin.GET("/sports/export", can(models.PermissionManageSystem), h.Sport.ExportSports)
+		admin.POST("/sports/import", can(models.PermissionManageSystem), h.Sport.ImportSports)
+		admin.POST("/sports", can(models.PermissionManageSystem), h.Sport.CreateSport)
+		admin.POST("/sports/reorder", can(models.PermissionManageSystem), h.Sport.ReorderSports)
+		admin.PUT("/sports/:id", can(models.PermissionManageSystem), h.Sport.UpdateSport)
+		admin.DELETE("/sports/:id", can(models.PermissionManageSystem), h.Sport.DeactivateSport)
+
+		// Status page incidents
+		admin.GET("/incidents", can(models.PermissionManageSystem), h.Status.ListIncidents)
+		admin.POST("/incidents", can(models.PermissionManageSystem), h.Status.CreateIncident)
+		admin.PUT("/incidents/:id", can(models.PermissionManageSystem), h.Status.UpdateIncident)
+		admin.DELETE("/incidents/:id", can(models.PermissionManageSystem), h.Status.DeleteIncident)
+
+		// API keys of kiosks, display screens and tooling with relaxed rate limits
+		admin.GET("/trusted-clients", can(models.PermissionManageSystem), h.TrustedClient.ListTrustedClients)
+		admin.POST("/trusted-clients", can(models.PermissionManageSystem), h.TrustedClient.CreateTrustedClient)
+		admin.PUT("/trusted-clients/:id", can(models.PermissionManageSystem), h.TrustedClient.UpdateTrustedClient)
+		admin.DELETE("/trusted-clients/:id", can(models.PermissionManageSystem), h.TrustedClient.DeleteTrustedClient)
+
+		// Fault injection (latency, DB errors, panics) - only with CHAOS_ENABLED outside production
+		if h.Chaos != nil {
+			admin.GET("/chaos/rules", can(models.PermissionManageSystem), h.Chaos.ListRules)
+			admin.POST("/chaos/rules", can(models.PermissionManageSystem), h.Chaos.CreateRule)
+			admin.DELETE("/chaos/rules", can(models.PermissionManageSystem), h.Chaos.ClearRules)
+			admin.DELETE("/chaos/rules/:id", can(models.PermissionManageSystem), h.Chaos.DeleteRule)
+		}
+	}
+
+	// Health check endpoints
+	router.GET("/health", h.Health.Health)
+	router.GET("/health/live", h.Health.Liveness)
+	router.GET("/health/ready", h.Health.Readiness)
+
+	return router
+}
@@ -1,248 +1,84 @@
 package cache
 
 import (
-	"sync"
-	"sync/atomic"
+	"encoding/json"
 	"time"
 )
 
-// CacheEntry represents a cached item with expiration
-type CacheEntry struct {
-	Value      interface{}
-	Expiration time.Time
-}
-
-// Cache provides thread-safe in-memory caching
-type Cache struct {
-	mu       sync.RWMutex
-	items    map[string]CacheEntry
-	ttl      time.Duration
-	cleanup  *time.Ticker
-	done     chan struct{} // Use struct{} for signal-only channels (zero memory)
-	stopped  atomic.Bool   // Track if cache is stopped to prevent double-stop
-	maxItems int           // Maximum number of items to prevent unbounded growth
-}
-
-// CacheConfig holds configuration options for the cache
-type CacheConfig struct {
-	TTL             time.Duration
-	CleanupInterval time.Duration
-	MaxItems        int // 0 means no limit
-}
-
-// DefaultCacheConfig returns sensible default configuration
-func DefaultCacheConfig() CacheConfig {
-	return CacheConfig{
-		TTL:             5 * time.Minute,
-		CleanupInterval: 1 * time.Minute,
-		MaxItems:        10000, // Prevent unbounded growth
-	}
-}
-
-// NewCache creates a new cache with the specified TTL and cleanup interval
-func NewCache(ttl time.Duration, cleanupInterval time.Duration) *Cache {
-	return NewCacheWithConfig(CacheConfig{
-		TTL:             ttl,
-		CleanupInterval: cleanupInterval,
-		MaxItems:        10000, // Default max items
-	})
-}
-
-// NewCacheWithConfig creates a new cache with full configuration
-func NewCacheWithConfig(cfg CacheConfig) *Cache {
-	c := &Cache{
-		items:    make(map[string]CacheEntry),
-		ttl:      cfg.TTL,
-		cleanup:  time.NewTicker(cfg.CleanupInterval),
-		done:     make(chan struct{}),
-		maxItems: cfg.MaxItems,
-	}
-
-	// Start cleanup goroutine
-	go c.cleanupLoop()
-
-	return c
-}
-
-// cleanupLoop periodically removes expired entries
-func (c *Cache) cleanupLoop() {
-	for {
-		select {
-		case <-c.cleanup.C:
-			c.deleteExpired()
-		case <-c.done:
-			c.cleanup.Stop()
-			return
-		}
-	}
-}
-
-// deleteExpired removes all expired entries
-func (c *Cache) deleteExpired() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// Backends selectable with CACHE_BACKEND
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
 
-	now := time.Now()
-	for key, entry := range c.items {
-		if now.After(entry.Expiration) {
-			delete(c.items, key)
-		}
+// Cache stores serialized values with a TTL
+// The in-memory backend is local to one instance; the Redis backend is shared between
+// instances and survives restarts. Cache failures are treated as misses, so callers
+// always fall back to the database.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+	DeleteByPrefix(prefix string)
+	Close() error
+}
+
+// GetJSON decodes a cached JSON value into dest; entries that do not decode are misses
+func GetJSON(c Cache, key string, dest interface{}) bool {
+	data, ok := c.Get(key)
+	if !ok {
+		return false
 	}
+	return json.Unmarshal(data, dest) == nil
 }
 
-// evictOldest removes the oldest entries if cache exceeds max size
-// Must be called with lock held
-func (c *Cache) evictOldest() {
-	if c.maxItems <= 0 || len(c.items) < c.maxItems {
+// SetJSON caches value encoded as JSON; values that cannot be encoded are not cached
+func SetJSON(c Cache, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
 		return
 	}
-
-	// Find and remove oldest entries (up to 10% of max to avoid frequent evictions)
-	toRemove := len(c.items) - c.maxItems + (c.maxItems / 10)
-	if toRemove <= 0 {
-		toRemove = 1
-	}
-
-	// Simple eviction: remove expired first, then oldest
-	now := time.Now()
-	removed := 0
-
-	// First pass: remove expired
-	for key, entry := range c.items {
-		if removed >= toRemove {
-			break
-		}
-		if now.After(entry.Expiration) {
-			delete(c.items, key)
-			removed++
-		}
-	}
-
-	// Second pass: remove oldest by expiration time if still need to evict
-	if removed < toRemove {
-		type keyExp struct {
-			key string
-			exp time.Time
-		}
-		var oldest []keyExp
-		for key, entry := range c.items {
-			oldest = append(oldest, keyExp{key, entry.Expiration})
-		}
-		// Sort by expiration (oldest first) - simple bubble for small evictions
-		for i := 0; i < len(oldest)-1 && removed < toRemove; i++ {
-			minIdx := i
-			for j := i + 1; j < len(oldest); j++ {
-				if oldest[j].exp.Before(oldest[minIdx].exp) {
-					minIdx = j
-				}
-			}
-			if minIdx != i {
-				oldest[i], oldest[minIdx] = oldest[minIdx], oldest[i]
-			}
-			delete(c.items, oldest[i].key)
-			removed++
-		}
-	}
+	c.Set(key, data, ttl)
 }
 
-// Set adds an item to the cache
-func (c *Cache) Set(key string, value interface{}) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// Evict if necessary before adding
-	c.evictOldest()
-
-	c.items[key] = CacheEntry{
-		Value:      value,
-		Expiration: time.Now().Add(c.ttl),
-	}
+// memoryBackend adapts MemoryCache to the Cache interface
+type memoryBackend struct {
+	items *MemoryCache
 }
 
-// SetWithTTL adds an item to the cache with a custom TTL
-func (c *Cache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// Evict if necessary before adding
-	c.evictOldest()
-
-	c.items[key] = CacheEntry{
-		Value:      value,
-		Expiration: time.Now().Add(ttl),
-	}
+// NewInMemory creates a Cache local to this instance
+func NewInMemory() Cache {
+	return &memoryBackend{items: NewMemoryCacheWithConfig(DefaultCacheConfig())}
 }
 
-// Get retrieves an item from the cache
-func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	entry, exists := c.items[key]
-	if !exists {
+func (m *memoryBackend) Get(key string) ([]byte, bool) {
+	value, ok := m.items.Get(key)
+	if !ok {
 		return nil, false
 	}
-
-	if time.Now().After(entry.Expiration) {
-		return nil, false
-	}
-
-	return entry.Value, true
+	data, ok := value.([]byte)
+	return data, ok
 }
 
-// Delete removes an item from the cache
-func (c *Cache) Delete(key string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	delete(c.items, key)
+func (m *memoryBackend) Set(key string, value []byte, ttl time.Duration) {
+	m.items.SetWithTTL(key, value, ttl)
 }
 
-// DeleteByPrefix removes all items with keys starting with the prefix
-func (c *Cache) DeleteByPrefix(prefix string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	for key := range c.items {
-		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
-			delete(c.items, key)
-		}
-	}
+func (m *memoryBackend) Delete(key string) {
+	m.items.Delete(key)
 }
 
-// Clear removes all items from the cache
-func (c *Cache) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	c.items = make(map[string]CacheEntry)
+func (m *memoryBackend) DeleteByPrefix(prefix string) {
+	m.items.DeleteByPrefix(prefix)
 }
 
-// Stop stops the cleanup goroutine safely
-// It's safe to call Stop multiple times
-func (c *Cache) Stop() {
-	// Use atomic to prevent double-close panic
-	if c.stopped.CompareAndSwap(false, true) {
-		close(c.done)
-	}
-}
-
-// IsStopped returns whether the cache has been stopped
-func (c *Cache) IsStopped() bool {
-	return c.stopped.Load()
+// Len returns the number of entries that have not expired yet
+func (m *memoryBackend) Len() int {
+	count, expired := m.items.Stats()
+	return count - expired
 }
 
-// Stats returns cache statistics
-func (c *Cache) Stats() (count int, expired int) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	now := time.Now()
-	for _, entry := range c.items {
-		count++
-		if now.After(entry.Expiration) {
-			expired++
-		}
-	}
-	return
+func (m *memoryBackend) Close() error {
+	m.items.Stop()
+	return nil
 }
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats are the lookups and writes of a cache on this instance since it started
+// Namespaces break them down by the key up to the first colon, e.g. "leaderboard"
+type Stats struct {
+	Backend    string                     `json:"backend"`
+	Since      time.Time                  `json:"since"`
+	Entries    *int                       `json:"entries,omitempty"` // Live entries, only known for the in-memory backend
+	Total      NamespaceStats             `json:"total"`
+	Namespaces map[string]*NamespaceStats `json:"namespaces"`
+}
+
+// NamespaceStats counts the operations on the keys of one namespace
+type NamespaceStats struct {
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	HitRate       float64 `json:"hit_rate"` // Share of lookups that were hits, 0 without lookups
+	Writes        uint64  `json:"writes"`
+	Invalidations uint64  `json:"invalidations"` // Delete and DeleteByPrefix calls
+}
+
+// Instrumented is a Cache that counts hits, misses, writes and invalidations
+// The counters are per instance, also when the backend is shared through Redis
+type Instrumented struct {
+	Cache
+	backend string
+	since   time.Time
+
+	mu         sync.Mutex
+	namespaces map[string]*NamespaceStats
+}
+
+// NewInstrumented wraps c; backend names it in the stats
+func NewInstrumented(c Cache, backend string) *Instrumented {
+	return &Instrumented{
+		Cache:      c,
+		backend:    backend,
+		since:      time.Now().UTC(),
+		namespaces: make(map[string]*NamespaceStats),
+	}
+}
+
+func (i *Instrumented) Get(key string) ([]byte, bool) {
+	data, ok := i.Cache.Get(key)
+	i.count(key, func(s *NamespaceStats) {
+		if ok {
+			s.Hits++
+		} else {
+			s.Misses++
+		}
+	})
+	return data, ok
+}
+
+func (i *Instrumented) Set(key string, value []byte, ttl time.Duration) {
+	i.Cache.Set(key, value, ttl)
+	i.count(key, func(s *NamespaceStats) { s.Writes++ })
+}
+
+func (i *Instrumented) Delete(key string) {
+	i.Cache.Delete(key)
+	i.count(key, func(s *NamespaceStats) { s.Invalidations++ })
+}
+
+func (i *Instrumented) DeleteByPrefix(prefix string) {
+	i.Cache.DeleteByPrefix(prefix)
+	i.count(prefix, func(s *NamespaceStats) { s.Invalidations++ })
+}
+
+func (i *Instrumented) count(key string, update func(s *NamespaceStats)) {
+	namespace, _, _ := strings.Cut(key, ":")
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	s, ok := i.namespaces[namespace]
+	if !ok {
+		s = &NamespaceStats{}
+		i.namespaces[namespace] = s
+	}
+	update(s)
+}
+
+// Stats returns a snapshot of the counters
+func (i *Instrumented) Stats() Stats {
+	stats := Stats{
+		Backend:    i.backend,
+		Since:      i.since,
+		Namespaces: make(map[string]*NamespaceStats),
+	}
+	if sized, ok := i.Cache.(interface{ Len() int }); ok {
+		entries := sized.Len()
+		stats.Entries = &entries
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for namespace, s := range i.namespaces {
+		snapshot := *s
+		snapshot.HitRate = hitRate(snapshot.Hits, snapshot.Misses)
+		stats.Namespaces[namespace] = &snapshot
+
+		stats.Total.Hits += s.Hits
+		stats.Total.Misses += s.Misses
+		stats.Total.Writes += s.Writes
+		stats.Total.Invalidations += s.Invalidations
+	}
+	stats.Total.HitRate = hitRate(stats.Total.Hits, stats.Total.Misses)
+	return stats
+}
+
+func hitRate(hits, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
@@ -1,29 +1,123 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// Environment is the deployment environment the server runs in
+type Environment string
+
+const (
+	EnvDevelopment Environment = "development"
+	EnvStaging     Environment = "staging"
+	EnvProduction  Environment = "production"
+)
+
+// CookieConfig controls how the auth cookie is issued
+// Embedded in Config so existing cfg.CookieSecure style references keep working
+type CookieConfig struct {
+	UseHTTPOnlyCookie bool          // Use httpOnly cookies instead of localStorage for JWT
+	CookieDomain      string        // Domain for the cookie (e.g., ".example.com")
+	CookieSecure      bool          // Whether to require HTTPS for cookies
+	CookieSameSite    http.SameSite // SameSite policy of the auth cookie
+}
+
+// RateLimit allows Requests per Window
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// RateLimitRoutes are the names RATE_LIMIT_<NAME> overrides: the endpoint classes
+// (strict 10/m, moderate 30/m, loose 100/m) and routes that otherwise share their class's limit
+var RateLimitRoutes = []string{
+	"strict", "moderate", "loose",
+	"match_submit",  // POST /matches, /matches/forfeit and /challenges/:id/result
+	"match_confirm", // Confirming a match by id, token or counter-proposal
+	"challenges",    // Creating, accepting, declining and cancelling challenges
+	"comments",      // Adding and deleting match comments
+	"reactions",     // Adding and removing match reactions
+	"reports",       // POST /reports
+	"data_export",   // Requesting and downloading data exports
+	"erase",         // POST /users/me/erase
+}
+
 type Config struct {
-	DatabaseURL     string
-	FTClientUID     string
-	FTClientSecret  string
-	FTRedirectURI   string
-	JWTSecret       string
-	Port            string
-	AllowedOrigins  []string
-	FrontendURL     string
-	DefaultELO      int
-	ELOKFactor      int
-	UseHTTPOnlyCookie bool   // Use httpOnly cookies instead of localStorage for JWT
-	CookieDomain      string // Domain for the cookie (e.g., ".example.com")
-	CookieSecure      bool   // Whether to require HTTPS for cookies
+	Environment    Environment
+	DatabaseURL    string
+	DatabaseURLRO  string // Read replica for leaderboard, match feed and statistics reads (empty = primary only)
+	FTClientUID    string
+	FTClientSecret string
+	FTRedirectURI  string
+	JWTSecret      string
+	Port           string
+	AllowedOrigins []string
+	FrontendURL    string // Base URL of the SPA, used for OAuth redirects
+	PublicAPIURL   string // Externally reachable base URL of this API, used for links we hand out
+	DefaultELO     int
+	ELOKFactor     int
+	CookieConfig
+	AnonAdjectives           []string             // Fallback anonymization adjectives when the database has none
+	AnonAnimals              []string             // Fallback anonymization animals when the database has none
+	RedisURL                 string               // Redis for shared state across instances (empty = in-memory)
+	CacheBackend             string               // Leaderboard, sport and status caches: "memory" or "redis" (needs REDIS_URL)
+	ActiveHoursFrom          int                  // Start of the usual playing hours (UTC hour); caches stay longer outside them
+	ActiveHoursUntil         int                  // End of the usual playing hours (UTC hour, exclusive)
+	PendingMatchExpiryHours  int                  // Pending matches older than this are cancelled by the expiry job
+	FingerprintRetentionDays int                  // Client fingerprints older than this are purged by the retention job
+	MatchArchiveAfterDays    int                  // Without closed seasons, finished matches older than this are archived
+	DiscordWebhookURL        string               // Discord webhook for notifications (empty = disabled)
+	DiscordSportWebhooks     map[string]string    // Per-sport Discord webhooks for match announcements, default is DiscordWebhookURL
+	DiscordShowLogins        bool                 // Announce players by login instead of their anonymous name
+	UsageSampleRate          float64              // Fraction of successful requests counted for per-user usage insights
+	UsageRetentionDays       int                  // Per-user API usage counters older than this are purged
+	ChaosEnabled             bool                 // Expose fault injection endpoints (never allowed in production)
+	ForfeitELOFactor         float64              // Share of a played match's ELO change applied to forfeits
+	ELODecayPoints           int                  // Rating points inactive players lose per week, never below the sport default (0 = no decay)
+	ELODecayAfterWeeks       int                  // Weeks without a confirmed match in a sport before its rating starts to decay
+	SlackSigningSecret       string               // Verifies Slack slash command requests (empty = Slack integration disabled)
+	SlackWebhookURL          string               // Slack incoming webhook for channel-wide posts such as digests (empty = disabled)
+	DigestPush               string               // Post the "daily" or "weekly" digests to Discord and Slack (empty = API only)
+	TrustedRateMultiplier    int                  // Rate limits of trusted clients (kiosks, display screens) are multiplied by this
+	RateLimitBackend         string               // Rate limit counters: "memory" per instance or "redis" shared (needs REDIS_URL)
+	RateLimits               map[string]RateLimit // Overridden limits by RateLimitRoutes name, e.g. RATE_LIMIT_MATCH_SUBMIT=20/m
+	KioskAPIKeys             map[string]string    // SHA-256 hex of each kiosk's API key by kiosk name (empty = kiosk routes disabled)
+	ConfirmTokenTTLMinutes   int                  // Validity of the QR/deep-link confirmation token handed out on submission
+	NotificationLanguage     string               // Language of channel-wide notifications such as Discord: "en" or "de"
+	NotificationTemplatesDir string               // Directory with <lang>.json files overriding the built-in notification templates
+	APIDocsEnabled           bool                 // Serve the OpenAPI spec and Swagger UI at /api/docs
+	ProfileSyncIntervalHours int                  // Avatars and display names of active users are refreshed from 42 this often (0 = only on login)
+	CompressionLevel         int                  // gzip level and brotli quality of responses, 1 (fastest) to 9 (smallest); 0 disables compression
+	CompressionMinSize       int                  // Responses smaller than this many bytes are sent uncompressed
+	CompressionTypes         []string             // Media types that are compressed; images and archives are already compressed
+	AutoMigrate              bool                 // Apply pending database migrations on startup; without it startup fails while any are pending
+	DBQueryTimeoutSeconds    int                  // Database calls serving a request are cancelled after this long (0 = only when the client goes away); jobs are exempt
+	OTelEndpoint             string               // OTLP/HTTP collector that receives traces, e.g. http://otel-collector:4318 (empty = tracing disabled)
+	OTelServiceName          string               // Service name the traces are reported under
+	TracingSampleRate        float64              // Fraction of new traces that are recorded; requests with a sampled parent follow the caller
+}
+
+// IsProduction reports whether the server runs with production hardening
+// Staging is treated like production so it exercises the same settings
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvProduction || c.Environment == EnvStaging
 }
 
 func Load() (*Config, error) {
+	env := Environment(strings.ToLower(getEnv("APP_ENV", "")))
+	if env == "" {
+		env = EnvDevelopment
+	}
+	secureByDefault := env == EnvProduction || env == EnvStaging
+
 	defaultELO, err := strconv.Atoi(getEnv("DEFAULT_ELO", "1000"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid DEFAULT_ELO: %w", err)
@@ -34,28 +128,220 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid ELO_K_FACTOR: %w", err)
 	}
 
-	allowedOrigins := getEnvAsSlice("ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://localhost:5173"}, ",")
-	frontendURL := getEnv("FRONTEND_URL", "http://localhost:3000")
+	pendingExpiryHours, err := strconv.Atoi(getEnv("PENDING_MATCH_EXPIRY_HOURS", "168"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PENDING_MATCH_EXPIRY_HOURS: %w", err)
+	}
+
+	confirmTokenTTLMinutes, err := strconv.Atoi(getEnv("CONFIRM_TOKEN_TTL_MINUTES", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONFIRM_TOKEN_TTL_MINUTES: %w", err)
+	}
+
+	fingerprintRetentionDays, err := strconv.Atoi(getEnv("FINGERPRINT_RETENTION_DAYS", "90"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FINGERPRINT_RETENTION_DAYS: %w", err)
+	}
+
+	matchArchiveAfterDays, err := strconv.Atoi(getEnv("MATCH_ARCHIVE_AFTER_DAYS", "365"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MATCH_ARCHIVE_AFTER_DAYS: %w", err)
+	}
+
+	usageSampleRate, err := strconv.ParseFloat(getEnv("USAGE_SAMPLE_RATE", "0.25"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid USAGE_SAMPLE_RATE: %w", err)
+	}
+
+	usageRetentionDays, err := strconv.Atoi(getEnv("USAGE_RETENTION_DAYS", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USAGE_RETENTION_DAYS: %w", err)
+	}
+
+	profileSyncIntervalHours, err := strconv.Atoi(getEnv("PROFILE_SYNC_INTERVAL_HOURS", "24"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROFILE_SYNC_INTERVAL_HOURS: %w", err)
+	}
+
+	forfeitELOFactor, err := strconv.ParseFloat(getEnv("FORFEIT_ELO_FACTOR", "0.5"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FORFEIT_ELO_FACTOR: %w", err)
+	}
+
+	eloDecayPoints, err := strconv.Atoi(getEnv("ELO_DECAY_POINTS", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ELO_DECAY_POINTS: %w", err)
+	}
+
+	eloDecayAfterWeeks, err := strconv.Atoi(getEnv("ELO_DECAY_AFTER_WEEKS", "8"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ELO_DECAY_AFTER_WEEKS: %w", err)
+	}
+
+	activeFrom, activeUntil, err := getEnvAsHourRange("ACTIVE_HOURS", 6, 21)
+	if err != nil {
+		return nil, err
+	}
+
+	trustedRateMultiplier, err := strconv.Atoi(getEnv("RATE_LIMIT_TRUSTED_MULTIPLIER", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_TRUSTED_MULTIPLIER: %w", err)
+	}
+
+	rateLimits, err := getRateLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	compressionLevel, err := strconv.Atoi(getEnv("COMPRESSION_LEVEL", "6"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid COMPRESSION_LEVEL: %w", err)
+	}
+
+	compressionMinSize, err := strconv.Atoi(getEnv("COMPRESSION_MIN_SIZE", "1024"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid COMPRESSION_MIN_SIZE: %w", err)
+	}
+
+	dbQueryTimeoutSeconds, err := strconv.Atoi(getEnv("DB_QUERY_TIMEOUT_SECONDS", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_QUERY_TIMEOUT_SECONDS: %w", err)
+	}
+
+	tracingSampleRate, err := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATE", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATE: %w", err)
+	}
+
+	compressionTypes := getEnvAsSlice("COMPRESSION_TYPES", []string{
+		"application/json", "text/csv", "text/calendar", "text/html", "text/plain", "application/yaml",
+	}, ",")
+
+	port := getEnv("PORT", "8080")
+
+	// Base URLs - development falls back to localhost, production must set them explicitly
+	frontendDefault, apiDefault := "http://localhost:3000", "http://localhost:"+port
+	if secureByDefault {
+		frontendDefault, apiDefault = "", ""
+	}
+	frontendURL := strings.TrimRight(getEnv("FRONTEND_URL", frontendDefault), "/")
+	publicAPIURL := strings.TrimRight(getEnv("PUBLIC_API_URL", apiDefault), "/")
+
+	originsDefault := []string{"http://localhost:3000", "http://localhost:5173"}
+	if secureByDefault {
+		originsDefault = []string{frontendURL}
+	}
+	allowedOrigins := getEnvAsSlice("ALLOWED_ORIGINS", originsDefault, ",")
+
+	// Cookie settings - more secure than localStorage for JWT, on by default outside development
+	useHTTPOnlyCookie, err := getEnvAsBool("USE_HTTPONLY_COOKIE", secureByDefault)
+	if err != nil {
+		return nil, err
+	}
+	cookieSecure, err := getEnvAsBool("COOKIE_SECURE", secureByDefault)
+	if err != nil {
+		return nil, err
+	}
+	cookieSameSite, err := parseSameSite(getEnv("COOKIE_SAMESITE", "strict"))
+	if err != nil {
+		return nil, err
+	}
+
+	chaosEnabled, err := getEnvAsBool("CHAOS_ENABLED", false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deployments that run cmd/migrate as a separate step turn this off
+	autoMigrate, err := getEnvAsBool("AUTO_MIGRATE", true)
+	if err != nil {
+		return nil, err
+	}
+
+	// API docs are on by default outside production and staging
+	apiDocsEnabled, err := getEnvAsBool("API_DOCS_ENABLED", !secureByDefault)
+	if err != nil {
+		return nil, err
+	}
+
+	// Discord channel per sport, e.g. "table_tennis=https://...,table_football=https://..."
+	discordSportWebhooks, err := getEnvAsMap("DISCORD_SPORT_WEBHOOKS")
+	if err != nil {
+		return nil, err
+	}
+	discordShowLogins, err := getEnvAsBool("DISCORD_SHOW_LOGINS", false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Table-side kiosks by name, e.g. "table_1=<sha256 of its key>"; only hashes are configured
+	kioskAPIKeys, err := getEnvAsMap("KIOSK_API_KEYS")
+	if err != nil {
+		return nil, err
+	}
 
-	// Cookie settings - more secure than localStorage for JWT
-	useHTTPOnlyCookie := getEnv("USE_HTTPONLY_COOKIE", "false") == "true"
-	cookieDomain := getEnv("COOKIE_DOMAIN", "")
-	cookieSecure := getEnv("COOKIE_SECURE", "false") == "true"
+	// Fallback vocabulary for anonymous names (campus vocabularies live in the database)
+	anonAdjectives := getEnvAsSlice("ANON_ADJECTIVES", nil, ",")
+	anonAnimals := getEnvAsSlice("ANON_ANIMALS", nil, ",")
 
 	cfg := &Config{
-		DatabaseURL:       getEnv("DATABASE_URL", ""),
-		FTClientUID:       getEnv("FT_CLIENT_UID", ""),
-		FTClientSecret:    getEnv("FT_CLIENT_SECRET", ""),
-		FTRedirectURI:     getEnv("FT_REDIRECT_URI", ""),
-		JWTSecret:         getEnv("JWT_SECRET", ""),
-		Port:              getEnv("PORT", "8080"),
-		AllowedOrigins:    allowedOrigins,
-		FrontendURL:       frontendURL,
-		DefaultELO:        defaultELO,
-		ELOKFactor:        kFactor,
-		UseHTTPOnlyCookie: useHTTPOnlyCookie,
-		CookieDomain:      cookieDomain,
-		CookieSecure:      cookieSecure,
+		Environment:    env,
+		DatabaseURL:    getEnv("DATABASE_URL", ""),
+		DatabaseURLRO:  getEnv("DATABASE_URL_RO", ""),
+		FTClientUID:    getEnv("FT_CLIENT_UID", ""),
+		FTClientSecret: getEnv("FT_CLIENT_SECRET", ""),
+		FTRedirectURI:  getEnv("FT_REDIRECT_URI", ""),
+		JWTSecret:      getEnv("JWT_SECRET", ""),
+		Port:           port,
+		AllowedOrigins: allowedOrigins,
+		FrontendURL:    frontendURL,
+		PublicAPIURL:   publicAPIURL,
+		DefaultELO:     defaultELO,
+		ELOKFactor:     kFactor,
+		CookieConfig: CookieConfig{
+			UseHTTPOnlyCookie: useHTTPOnlyCookie,
+			CookieDomain:      getEnv("COOKIE_DOMAIN", ""),
+			CookieSecure:      cookieSecure,
+			CookieSameSite:    cookieSameSite,
+		},
+		AnonAdjectives:           anonAdjectives,
+		AnonAnimals:              anonAnimals,
+		RedisURL:                 getEnv("REDIS_URL", ""),
+		CacheBackend:             strings.ToLower(getEnv("CACHE_BACKEND", "memory")),
+		PendingMatchExpiryHours:  pendingExpiryHours,
+		ConfirmTokenTTLMinutes:   confirmTokenTTLMinutes,
+		NotificationLanguage:     strings.ToLower(getEnv("NOTIFICATION_LANGUAGE", "en")),
+		NotificationTemplatesDir: getEnv("NOTIFICATION_TEMPLATES_DIR", ""),
+		FingerprintRetentionDays: fingerprintRetentionDays,
+		MatchArchiveAfterDays:    matchArchiveAfterDays,
+		DiscordWebhookURL:        getEnv("DISCORD_WEBHOOK_URL", ""),
+		DiscordSportWebhooks:     discordSportWebhooks,
+		DiscordShowLogins:        discordShowLogins,
+		UsageSampleRate:          usageSampleRate,
+		UsageRetentionDays:       usageRetentionDays,
+		ProfileSyncIntervalHours: profileSyncIntervalHours,
+		ChaosEnabled:             chaosEnabled,
+		APIDocsEnabled:           apiDocsEnabled,
+		ForfeitELOFactor:         forfeitELOFactor,
+		ELODecayPoints:           eloDecayPoints,
+		ELODecayAfterWeeks:       eloDecayAfterWeeks,
+		SlackSigningSecret:       getEnv("SLACK_SIGNING_SECRET", ""),
+		SlackWebhookURL:          getEnv("SLACK_WEBHOOK_URL", ""),
+		DigestPush:               strings.ToLower(getEnv("DIGEST_PUSH", "")),
+		TrustedRateMultiplier:    trustedRateMultiplier,
+		RateLimitBackend:         strings.ToLower(getEnv("RATE_LIMIT_BACKEND", "memory")),
+		RateLimits:               rateLimits,
+		KioskAPIKeys:             kioskAPIKeys,
+		ActiveHoursFrom:          activeFrom,
+		ActiveHoursUntil:         activeUntil,
+		CompressionLevel:         compressionLevel,
+		CompressionMinSize:       compressionMinSize,
+		CompressionTypes:         compressionTypes,
+		AutoMigrate:              autoMigrate,
+		DBQueryTimeoutSeconds:    dbQueryTimeoutSeconds,
+		OTelEndpoint:             getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		OTelServiceName:          getEnv("OTEL_SERVICE_NAME", "elo-leaderboard"),
+		TracingSampleRate:        tracingSampleRate,
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -66,6 +352,11 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) Validate() error {
+	switch c.Environment {
+	case EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		return fmt.Errorf("APP_ENV must be one of: development, staging, production")
+	}
 	if c.DatabaseURL == "" {
 		return fmt.Errorf("DATABASE_URL is required")
 	}
@@ -85,9 +376,197 @@ func (c *Config) Validate() error {
 	if len(c.JWTSecret) < 32 {
 		return fmt.Errorf("JWT_SECRET must be at least 32 characters long for security")
 	}
+	if c.PendingMatchExpiryHours < 1 {
+		return fmt.Errorf("PENDING_MATCH_EXPIRY_HOURS must be at least 1")
+	}
+	if c.NotificationLanguage != "en" && c.NotificationLanguage != "de" {
+		return fmt.Errorf("NOTIFICATION_LANGUAGE must be 'en' or 'de'")
+	}
+	if c.ConfirmTokenTTLMinutes < 1 {
+		return fmt.Errorf("CONFIRM_TOKEN_TTL_MINUTES must be at least 1")
+	}
+	if c.FingerprintRetentionDays < 1 {
+		return fmt.Errorf("FINGERPRINT_RETENTION_DAYS must be at least 1")
+	}
+	if c.MatchArchiveAfterDays < 1 {
+		return fmt.Errorf("MATCH_ARCHIVE_AFTER_DAYS must be at least 1")
+	}
+	if c.UsageSampleRate <= 0 || c.UsageSampleRate > 1 {
+		return fmt.Errorf("USAGE_SAMPLE_RATE must be greater than 0 and at most 1")
+	}
+	if c.UsageRetentionDays < 1 {
+		return fmt.Errorf("USAGE_RETENTION_DAYS must be at least 1")
+	}
+	if c.ProfileSyncIntervalHours < 0 {
+		return fmt.Errorf("PROFILE_SYNC_INTERVAL_HOURS must not be negative")
+	}
+	switch c.DigestPush {
+	case "", "daily", "weekly":
+	default:
+		return fmt.Errorf("DIGEST_PUSH must be empty, \"daily\" or \"weekly\"")
+	}
+	if c.ForfeitELOFactor < 0 || c.ForfeitELOFactor > 1 {
+		return fmt.Errorf("FORFEIT_ELO_FACTOR must be between 0 and 1")
+	}
+	if c.ELODecayPoints < 0 || c.ELODecayPoints > 50 {
+		return fmt.Errorf("ELO_DECAY_POINTS must be between 0 and 50")
+	}
+	if c.ELODecayAfterWeeks < 1 {
+		return fmt.Errorf("ELO_DECAY_AFTER_WEEKS must be at least 1")
+	}
+	switch c.CacheBackend {
+	case "memory":
+	case "redis":
+		if c.RedisURL == "" {
+			return fmt.Errorf("CACHE_BACKEND=redis requires REDIS_URL")
+		}
+	default:
+		return fmt.Errorf("CACHE_BACKEND must be \"memory\" or \"redis\"")
+	}
+	switch c.RateLimitBackend {
+	case "memory":
+	case "redis":
+		if c.RedisURL == "" {
+			return fmt.Errorf("RATE_LIMIT_BACKEND=redis requires REDIS_URL")
+		}
+	default:
+		return fmt.Errorf("RATE_LIMIT_BACKEND must be \"memory\" or \"redis\"")
+	}
+	if c.TrustedRateMultiplier < 1 {
+		return fmt.Errorf("RATE_LIMIT_TRUSTED_MULTIPLIER must be at least 1")
+	}
+	for name, limit := range c.RateLimits {
+		if limit.Requests < 1 || limit.Window < time.Second {
+			return fmt.Errorf("RATE_LIMIT_%s must allow at least 1 request per second or longer", strings.ToUpper(name))
+		}
+	}
+	for name, hash := range c.KioskAPIKeys {
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 64 {
+			return fmt.Errorf("KIOSK_API_KEYS entry %q must be the SHA-256 hex digest of its key", name)
+		}
+	}
+	if c.CompressionLevel < 0 || c.CompressionLevel > 9 {
+		return fmt.Errorf("COMPRESSION_LEVEL must be between 0 and 9")
+	}
+	if c.CompressionMinSize < 0 {
+		return fmt.Errorf("COMPRESSION_MIN_SIZE must not be negative")
+	}
+	if c.DBQueryTimeoutSeconds < 0 {
+		return fmt.Errorf("DB_QUERY_TIMEOUT_SECONDS must not be negative")
+	}
+	if c.TracingSampleRate < 0 || c.TracingSampleRate > 1 {
+		return fmt.Errorf("TRACING_SAMPLE_RATE must be between 0 and 1")
+	}
+	if c.ChaosEnabled && c.Environment == EnvProduction {
+		return fmt.Errorf("CHAOS_ENABLED is not allowed in production")
+	}
+
+	if err := c.validateURLs(); err != nil {
+		return err
+	}
+	return c.validateCookies()
+}
+
+// validateURLs checks base URLs and CORS origins; production requires HTTPS everywhere
+func (c *Config) validateURLs() error {
+	urls := []struct {
+		name  string
+		value string
+	}{
+		{"FRONTEND_URL", c.FrontendURL},
+		{"PUBLIC_API_URL", c.PublicAPIURL},
+		{"FT_REDIRECT_URI", c.FTRedirectURI},
+	}
+	for _, u := range urls {
+		if u.value == "" {
+			return fmt.Errorf("%s is required in %s", u.name, c.Environment)
+		}
+		if err := validateAbsoluteURL(u.value, c.IsProduction()); err != nil {
+			return fmt.Errorf("invalid %s: %w", u.name, err)
+		}
+	}
+
+	if c.DiscordWebhookURL != "" {
+		if err := validateAbsoluteURL(c.DiscordWebhookURL, true); err != nil {
+			return fmt.Errorf("invalid DISCORD_WEBHOOK_URL: %w", err)
+		}
+	}
+	if c.SlackWebhookURL != "" {
+		if err := validateAbsoluteURL(c.SlackWebhookURL, true); err != nil {
+			return fmt.Errorf("invalid SLACK_WEBHOOK_URL: %w", err)
+		}
+	}
+	// Collectors usually run next to the server without TLS
+	if c.OTelEndpoint != "" {
+		if err := validateAbsoluteURL(c.OTelEndpoint, false); err != nil {
+			return fmt.Errorf("invalid OTEL_EXPORTER_OTLP_ENDPOINT: %w", err)
+		}
+	}
+	for sport, url := range c.DiscordSportWebhooks {
+		if err := validateAbsoluteURL(url, true); err != nil {
+			return fmt.Errorf("invalid DISCORD_SPORT_WEBHOOKS entry for %s: %w", sport, err)
+		}
+	}
+
+	if len(c.AllowedOrigins) == 0 {
+		return fmt.Errorf("ALLOWED_ORIGINS must contain at least one origin")
+	}
+	for _, origin := range c.AllowedOrigins {
+		// Wildcards are incompatible with credentialed CORS requests
+		if origin == "*" {
+			return fmt.Errorf("ALLOWED_ORIGINS cannot contain '*' because credentials are allowed")
+		}
+		if err := validateAbsoluteURL(origin, c.IsProduction()); err != nil {
+			return fmt.Errorf("invalid origin %q in ALLOWED_ORIGINS: %w", origin, err)
+		}
+	}
+
+	return nil
+}
+
+// validateCookies rejects cookie settings browsers would drop or that leak the token
+func (c *Config) validateCookies() error {
+	if c.IsProduction() && !c.CookieSecure {
+		return fmt.Errorf("COOKIE_SECURE must be true in %s", c.Environment)
+	}
+	if c.CookieSameSite == http.SameSiteNoneMode && !c.CookieSecure {
+		return fmt.Errorf("COOKIE_SAMESITE=none requires COOKIE_SECURE=true")
+	}
+	if c.CookieDomain != "" && strings.ContainsAny(c.CookieDomain, ":/ ") {
+		return fmt.Errorf("COOKIE_DOMAIN must be a bare domain such as .example.com")
+	}
+	return nil
+}
+
+// validateAbsoluteURL checks that a URL has an http(s) scheme and host
+func validateAbsoluteURL(raw string, requireHTTPS bool) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("must be an absolute http(s) URL")
+	}
+	if requireHTTPS && u.Scheme != "https" {
+		return fmt.Errorf("must use https")
+	}
 	return nil
 }
 
+// parseSameSite maps COOKIE_SAMESITE values to http.SameSite
+func parseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("COOKIE_SAMESITE must be one of: strict, lax, none")
+	}
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -95,6 +574,19 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvAsBool(key string, fallback bool) (bool, error) {
+	valStr, ok := os.LookupEnv(key)
+	if !ok || valStr == "" {
+		return fallback, nil
+	}
+
+	val, err := strconv.ParseBool(valStr)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return val, nil
+}
+
 func getEnvAsSlice(name string, defaultVal []string, sep string) []string {
 	valStr := getEnv(name, "")
 
@@ -106,3 +598,96 @@ func getEnvAsSlice(name string, defaultVal []string, sep string) []string {
 
 	return val
 }
+
+// getEnvAsHourRange parses a range of UTC hours such as "6-21"; it may wrap past midnight
+func getEnvAsHourRange(name string, defaultFrom, defaultUntil int) (int, int, error) {
+	valStr := getEnv(name, "")
+	if valStr == "" {
+		return defaultFrom, defaultUntil, nil
+	}
+
+	fromStr, untilStr, ok := strings.Cut(valStr, "-")
+	from, fromErr := strconv.Atoi(strings.TrimSpace(fromStr))
+	until, untilErr := strconv.Atoi(strings.TrimSpace(untilStr))
+	if !ok || fromErr != nil || untilErr != nil || from < 0 || from > 23 || until < 0 || until > 24 || from == until {
+		return 0, 0, fmt.Errorf("invalid %s: expected hours like 6-21, got %q", name, valStr)
+	}
+	return from, until, nil
+}
+
+// getEnvAsMap parses comma-separated key=value pairs
+func getEnvAsMap(name string) (map[string]string, error) {
+	result := make(map[string]string)
+	valStr := getEnv(name, "")
+	if valStr == "" {
+		return result, nil
+	}
+
+	for _, pair := range strings.Split(valStr, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || strings.TrimSpace(key) == "" || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("invalid %s: expected key=value pairs, got %q", name, pair)
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return result, nil
+}
+
+// getRateLimits reads the RATE_LIMIT_<NAME> overrides of RateLimitRoutes
+// Unknown names are rejected so a misspelt route does not silently keep its default
+func getRateLimits() (map[string]RateLimit, error) {
+	known := make(map[string]bool, len(RateLimitRoutes))
+	for _, name := range RateLimitRoutes {
+		known[name] = true
+	}
+
+	limits := make(map[string]RateLimit)
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		name, ok := strings.CutPrefix(key, "RATE_LIMIT_")
+		if !ok || name == "BACKEND" || name == "TRUSTED_MULTIPLIER" || value == "" {
+			continue
+		}
+		name = strings.ToLower(name)
+		if !known[name] {
+			names := append([]string(nil), RateLimitRoutes...)
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown rate limit %s, expected one of: %s", key, strings.Join(names, ", "))
+		}
+
+		limit, err := parseRateLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+// parseRateLimit parses "<requests>/<window>" where the window is s, m, h or a duration such as 10s
+func parseRateLimit(value string) (RateLimit, error) {
+	requestsStr, windowStr, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("expected requests per window like 20/m, got %q", value)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(requestsStr))
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("expected requests per window like 20/m, got %q", value)
+	}
+
+	var window time.Duration
+	switch windowStr = strings.TrimSpace(windowStr); windowStr {
+	case "s":
+		window = time.Second
+	case "m":
+		window = time.Minute
+	case "h":
+		window = time.Hour
+	default:
+		if window, err = time.ParseDuration(windowStr); err != nil {
+			return RateLimit{}, fmt.Errorf("expected a window of s, m, h or a duration like 10s, got %q", windowStr)
+		}
+	}
+	return RateLimit{Requests: requests, Window: window}, nil
+}
@@ -0,0 +1,10 @@
+// Package docs holds the OpenAPI contract of the HTTP API
+package docs
+
+import _ "embed"
+
+// OpenAPI is the hand-maintained OpenAPI 3 spec covering every route in internal/app/routes.go
+// Update it together with the handlers
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
@@ -1,36 +1,76 @@
 package handlers
 
 import (
+	"bytes"
+	"database/sql"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
 	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+	"github.com/42heilbronn/elo-leaderboard/internal/realtime"
 	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/revocation"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
 	"github.com/42heilbronn/elo-leaderboard/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type AdminHandler struct {
-	adminRepo *repositories.AdminRepository
-	userRepo  *repositories.UserRepository
-	matchRepo *repositories.MatchRepository
+	adminRepo    repositories.AdminStore
+	userRepo     repositories.UserStore
+	matchRepo    repositories.MatchStore
+	denyList     *revocation.DenyList
+	events       services.EventPublisher // moderation events, e.g. for outbound webhooks
+	matchService *services.MatchService
+	recompute    *services.RecomputeService
+	commentRepo  repositories.CommentStore
+	reactionRepo repositories.ReactionStore
+	hub          *realtime.Hub
+	flagRepo     repositories.MatchFlagStore
+	matchImport  *services.MatchImportService
+	inbox        *notifications.Inbox
 }
 
-func NewAdminHandler(adminRepo *repositories.AdminRepository, userRepo *repositories.UserRepository, matchRepo *repositories.MatchRepository) *AdminHandler {
+func NewAdminHandler(adminRepo repositories.AdminStore, userRepo repositories.UserStore, matchRepo repositories.MatchStore, denyList *revocation.DenyList, events services.EventPublisher, matchService *services.MatchService, recompute *services.RecomputeService, commentRepo repositories.CommentStore, reactionRepo repositories.ReactionStore, hub *realtime.Hub, flagRepo repositories.MatchFlagStore, matchImport *services.MatchImportService, inbox *notifications.Inbox) *AdminHandler {
 	return &AdminHandler{
-		adminRepo: adminRepo,
-		userRepo:  userRepo,
-		matchRepo: matchRepo,
+		adminRepo:    adminRepo,
+		userRepo:     userRepo,
+		matchRepo:    matchRepo,
+		denyList:     denyList,
+		events:       events,
+		matchService: matchService,
+		recompute:    recompute,
+		commentRepo:  commentRepo,
+		reactionRepo: reactionRepo,
+		hub:          hub,
+		flagRepo:     flagRepo,
+		matchImport:  matchImport,
+		inbox:        inbox,
+	}
+}
+
+// rebuildLeaderboard re-ranks the leaderboards of the given sports, or of all sports, after an
+// admin change; the change is already committed, so a failure is only logged and can be
+// repaired through RebuildLeaderboard
+func (h *AdminHandler) rebuildLeaderboard(c *gin.Context, sports ...string) {
+	if _, err := h.matchService.RebuildLeaderboard(c.Request.Context(), sports...); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to rebuild leaderboard", "error", err, "sports", sports)
 	}
 }
 
 // GetSystemHealth returns system health statistics
 func (h *AdminHandler) GetSystemHealth(c *gin.Context) {
-	health, err := h.adminRepo.GetSystemHealth()
+	health, err := h.adminRepo.GetSystemHealth(c.Request.Context())
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get system health", err)
 		return
@@ -39,6 +79,45 @@ func (h *AdminHandler) GetSystemHealth(c *gin.Context) {
 	utils.RespondWithJSON(c, http.StatusOK, health)
 }
 
+// Window bounds in days for the analytics dashboard
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+)
+
+// GetAnalytics returns the system health figures with daily engagement trends
+// GET /api/admin/analytics?days=30
+func (h *AdminHandler) GetAnalytics(c *gin.Context) {
+	days := defaultAnalyticsDays
+	if raw := c.Query("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxAnalyticsDays {
+			utils.RespondWithError(c, http.StatusBadRequest, "days must be between 1 and 365", err)
+			return
+		}
+		days = n
+	}
+
+	health, err := h.adminRepo.GetSystemHealth(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get system health", err)
+		return
+	}
+
+	series, totals, err := h.adminRepo.GetAnalytics(c.Request.Context(), days)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get analytics", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, models.AdminAnalytics{
+		Health: health,
+		Days:   days,
+		Totals: *totals,
+		Series: series,
+	})
+}
+
 // AdjustELO manually adjusts a user's ELO
 func (h *AdminHandler) AdjustELO(c *gin.Context) {
 	adminID, _ := middleware.GetUserID(c)
@@ -56,23 +135,28 @@ func (h *AdminHandler) AdjustELO(c *gin.Context) {
 	}
 
 	// Verify target user exists
-	user, err := h.userRepo.GetByID(req.UserID)
+	user, err := h.userRepo.GetByID(c.Request.Context(), req.UserID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
 		return
 	}
 
-	adjustment, err := h.adminRepo.AdjustELO(req.UserID, req.Sport, req.NewELO, req.Reason, adminID)
+	adjustment, err := h.adminRepo.AdjustELO(c.Request.Context(), req.UserID, req.Sport, req.NewELO, req.Reason, adminID)
 	if err != nil {
+		if err.Error() == "sport not found" {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+			return
+		}
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to adjust ELO", err)
 		return
 	}
+	h.matchService.LeaderboardChanged(req.Sport)
 
 	// Log admin action
-	h.adminRepo.LogAdminAction(adminID, "adjust_elo", "user", &req.UserID, map[string]interface{}{
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "adjust_elo", "user", &req.UserID, map[string]interface{}{
 		"sport":   req.Sport,
 		"old_elo": adjustment.OldELO,
-		"new_elo": req.NewELO,
+		"new_elo": adjustment.NewELO,
 		"reason":  req.Reason,
 		"user":    user.Login,
 	})
@@ -80,6 +164,68 @@ func (h *AdminHandler) AdjustELO(c *gin.Context) {
 	utils.RespondWithJSON(c, http.StatusOK, adjustment)
 }
 
+// PenalizeELO deducts points from a player's rating as a moderation penalty and tells the player why
+// POST /api/admin/elo/penalty
+func (h *AdminHandler) PenalizeELO(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req models.ELOPenaltyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if req.UserID == adminID {
+		utils.RespondWithError(c, http.StatusBadRequest, "cannot penalize your own ELO", nil)
+		return
+	}
+	if err := utils.ValidateReason(req.Reason); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	// Verify target user exists
+	user, err := h.userRepo.GetByID(c.Request.Context(), req.UserID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+
+	adjustment, err := h.adminRepo.PenalizeELO(c.Request.Context(), req.UserID, req.Sport, req.Points, req.Reason, adminID)
+	if err != nil {
+		if err.Error() == "sport not found" {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to apply ELO penalty", err)
+		return
+	}
+	h.matchService.LeaderboardChanged(req.Sport)
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "penalize_elo", "user", &req.UserID, map[string]interface{}{
+		"sport":   req.Sport,
+		"points":  req.Points,
+		"old_elo": adjustment.OldELO,
+		"new_elo": adjustment.NewELO,
+		"reason":  req.Reason,
+		"user":    user.Login,
+	})
+
+	// The rating floor may have absorbed part of the penalty, so report what was deducted
+	h.inbox.Notify(c.Request.Context(), &models.UserNotification{
+		UserID: req.UserID,
+		Kind:   models.NotificationELOPenalty,
+		Sport:  &req.Sport,
+		Data: map[string]interface{}{
+			"points":  adjustment.OldELO - adjustment.NewELO,
+			"new_elo": adjustment.NewELO,
+			"reason":  req.Reason,
+		},
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, adjustment)
+}
+
 // GetELOAdjustments returns ELO adjustment history
 func (h *AdminHandler) GetELOAdjustments(c *gin.Context) {
 	// Use pagination utility with enforced maximum limits
@@ -90,7 +236,7 @@ func (h *AdminHandler) GetELOAdjustments(c *gin.Context) {
 		500, // max limit for admin
 	)
 
-	adjustments, err := h.adminRepo.GetELOAdjustments(pagination.Limit)
+	adjustments, err := h.adminRepo.GetELOAdjustments(c.Request.Context(), pagination.Limit)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get ELO adjustments", err)
 		return
@@ -99,7 +245,7 @@ func (h *AdminHandler) GetELOAdjustments(c *gin.Context) {
 	utils.RespondWithJSON(c, http.StatusOK, adjustments)
 }
 
-// BanUser bans a user
+// BanUser bans a user, for duration_hours or until unbanned
 func (h *AdminHandler) BanUser(c *gin.Context) {
 	adminID, _ := middleware.GetUserID(c)
 
@@ -128,7 +274,7 @@ func (h *AdminHandler) BanUser(c *gin.Context) {
 	}
 
 	// Verify target user exists
-	user, err := h.userRepo.GetByID(req.UserID)
+	user, err := h.userRepo.GetByID(c.Request.Context(), req.UserID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
 		return
@@ -140,19 +286,43 @@ func (h *AdminHandler) BanUser(c *gin.Context) {
 		return
 	}
 
-	err = h.adminRepo.BanUser(req.UserID, req.Reason, adminID)
+	var until *time.Time
+	if req.DurationHours != nil {
+		end := time.Now().Add(time.Duration(*req.DurationHours) * time.Hour)
+		until = &end
+	}
+
+	err = h.adminRepo.BanUser(c.Request.Context(), req.UserID, req.Reason, adminID, until)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to ban user", err)
 		return
 	}
+	// Banned players drop out of the rankings of every sport
+	h.rebuildLeaderboard(c)
+
+	// Revoke existing sessions on every instance
+	if err := h.denyList.RevokeUser(c.Request.Context(), req.UserID); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to revoke tokens of banned user", "error", err, "user_id", req.UserID)
+	}
 
 	// Log admin action
-	h.adminRepo.LogAdminAction(adminID, "ban_user", "user", &req.UserID, map[string]interface{}{
-		"reason": req.Reason,
-		"user":   user.Login,
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "ban_user", "user", &req.UserID, map[string]interface{}{
+		"reason":       req.Reason,
+		"user":         user.Login,
+		"banned_until": until,
+	})
+
+	h.events.Publish("", models.WebhookEventUserBanned, map[string]interface{}{
+		"user_id":      req.UserID,
+		"login":        user.Login,
+		"reason":       req.Reason,
+		"banned_until": until,
 	})
 
-	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "user banned successfully"})
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{
+		"message":      "user banned successfully",
+		"banned_until": until,
+	})
 }
 
 // UnbanUser unbans a user
@@ -166,29 +336,191 @@ func (h *AdminHandler) UnbanUser(c *gin.Context) {
 	}
 
 	// Verify target user exists
-	user, err := h.userRepo.GetByID(userID)
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
 		return
 	}
 
-	err = h.adminRepo.UnbanUser(userID)
+	err = h.adminRepo.UnbanUser(c.Request.Context(), userID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to unban user", err)
 		return
 	}
+	h.rebuildLeaderboard(c)
 
 	// Log admin action
-	h.adminRepo.LogAdminAction(adminID, "unban_user", "user", &userID, map[string]interface{}{
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "unban_user", "user", &userID, map[string]interface{}{
 		"user": user.Login,
 	})
 
 	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "user unbanned successfully"})
 }
 
+// SetUserRole promotes or demotes a user to a staff role, or back to a regular player
+// The last superadmin cannot be demoted, so the staff can never lock itself out
+// POST /api/admin/users/:id/role
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID < 1 {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid user ID", err)
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required,oneof=user moderator admin superadmin"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	oldRole, err := h.adminRepo.SetRole(c.Request.Context(), userID, req.Role)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), nil)
+		case "cannot demote the last superadmin", "cannot promote a banned user":
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), nil)
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to update role", err)
+		}
+		return
+	}
+
+	if oldRole != req.Role {
+		h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "set_role", "user", &userID, map[string]interface{}{
+			"old_role": oldRole,
+			"new_role": req.Role,
+		})
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "role updated successfully", "role": req.Role})
+}
+
+// LinkIntraID relinks an existing profile to a new intra ID after a 42 account migration
+// Future logins with the new intra ID resolve to this profile, preserving ELO and history
+func (h *AdminHandler) LinkIntraID(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid user ID", err)
+		return
+	}
+
+	var req models.LinkIntraIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := utils.ValidateReason(req.Reason); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	// Verify target user exists
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+
+	if err := h.userRepo.RelinkIntraID(c.Request.Context(), req.IntraID, userID, "admin", &adminID); err != nil {
+		if errors.Is(err, repositories.ErrIntraIDInUse) {
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusBadRequest, "failed to link intra ID", err)
+		return
+	}
+
+	h.matchService.LeaderboardChanged()
+
+	// Log admin action
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "link_intra_id", "user", &userID, map[string]interface{}{
+		"intra_id": req.IntraID,
+		"reason":   req.Reason,
+		"user":     user.Login,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "intra ID linked successfully"})
+}
+
+// MergeUsers merges a duplicate profile into another one, e.g. after an intra ID change
+// that created a fresh profile which already has matches; the duplicate is removed
+// POST /api/admin/users/merge
+func (h *AdminHandler) MergeUsers(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req models.MergeUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err := utils.ValidateReason(req.Reason); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if req.SourceID == adminID {
+		utils.RespondWithError(c, http.StatusBadRequest, "cannot merge your own profile away", nil)
+		return
+	}
+
+	source, err := h.userRepo.GetByID(c.Request.Context(), req.SourceID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "source user not found", err)
+		return
+	}
+	target, err := h.userRepo.GetByID(c.Request.Context(), req.TargetID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "target user not found", err)
+		return
+	}
+
+	result, err := h.userRepo.MergeUsers(c.Request.Context(), req.SourceID, req.TargetID, adminID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUsersPlayedEachOther) || errors.Is(err, repositories.ErrUsersShareSports) {
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+			return
+		}
+		if err.Error() == "user not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusBadRequest, "failed to merge users", err)
+		return
+	}
+
+	h.matchService.LeaderboardChanged()
+
+	// The removed profile's sessions must not outlive it
+	if err := h.denyList.RevokeUser(c.Request.Context(), req.SourceID); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to revoke tokens of merged user", "error", err, "user_id", req.SourceID)
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "merge_users", "user", &req.TargetID, map[string]interface{}{
+		"reason":    req.Reason,
+		"source_id": req.SourceID,
+		"source":    source.Login,
+		"target":    target.Login,
+		"matches":   result.Matches,
+		"comments":  result.Comments,
+		"reactions": result.Reactions,
+		"sports":    result.Sports,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, result)
+}
+
 // GetBannedUsers returns all banned users
 func (h *AdminHandler) GetBannedUsers(c *gin.Context) {
-	users, err := h.adminRepo.GetBannedUsers()
+	users, err := h.adminRepo.GetBannedUsers(c.Request.Context())
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get banned users", err)
 		return
@@ -197,7 +529,8 @@ func (h *AdminHandler) GetBannedUsers(c *gin.Context) {
 	utils.RespondWithJSON(c, http.StatusOK, users)
 }
 
-// DeleteMatch permanently deletes a match
+// DeleteMatch soft-deletes a match; it can be brought back with RestoreMatch
+// DELETE /api/admin/matches/:id
 func (h *AdminHandler) DeleteMatch(c *gin.Context) {
 	adminID, _ := middleware.GetUserID(c)
 
@@ -208,20 +541,21 @@ func (h *AdminHandler) DeleteMatch(c *gin.Context) {
 	}
 
 	// Get match details before deleting for audit log
-	match, err := h.matchRepo.GetByID(matchID)
+	match, err := h.matchRepo.GetByID(c.Request.Context(), matchID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusNotFound, "match not found", err)
 		return
 	}
 
-	err = h.adminRepo.DeleteMatch(matchID)
+	err = h.adminRepo.DeleteMatch(c.Request.Context(), matchID, adminID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete match", err)
 		return
 	}
+	h.rebuildLeaderboard(c, match.Sport)
 
 	// Log admin action
-	h.adminRepo.LogAdminAction(adminID, "delete_match", "match", &matchID, map[string]interface{}{
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "delete_match", "match", &matchID, map[string]interface{}{
 		"sport":         match.Sport,
 		"player1_id":    match.Player1ID,
 		"player2_id":    match.Player2ID,
@@ -233,7 +567,10 @@ func (h *AdminHandler) DeleteMatch(c *gin.Context) {
 	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "match deleted successfully"})
 }
 
-// UpdateMatchStatus updates a match status
+// UpdateMatchStatus changes the status of a match as staff
+// Pending matches can be held for review as disputed, disputed ones confirmed with their ELO
+// applied, and matches that never counted denied or cancelled; confirmed matches are
+// corrected or reverted instead
 func (h *AdminHandler) UpdateMatchStatus(c *gin.Context) {
 	adminID, _ := middleware.GetUserID(c)
 
@@ -244,40 +581,232 @@ func (h *AdminHandler) UpdateMatchStatus(c *gin.Context) {
 	}
 
 	var req struct {
-		Status string `json:"status" binding:"required,oneof=pending confirmed denied cancelled disputed"`
+		Status string `json:"status" binding:"required,oneof=confirmed denied cancelled disputed"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "invalid request", err)
 		return
 	}
 
-	// Verify match exists
-	match, err := h.matchRepo.GetByID(matchID)
+	match, err := h.matchService.ResolveMatchStatus(c.Request.Context(), matchID, req.Status)
 	if err != nil {
-		utils.RespondWithError(c, http.StatusNotFound, "match not found", err)
+		switch {
+		case err.Error() == "match not found":
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+		case errors.Is(err, repositories.ErrMatchStatusChanged), strings.Contains(err.Error(), "cannot be set to"):
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+		default:
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		}
 		return
 	}
 
-	oldStatus := match.Status
+	// Log admin action
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "update_match_status", "match", &matchID, map[string]interface{}{
+		"old_status": match.Status,
+		"new_status": req.Status,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "match status updated successfully"})
+}
+
+// DeleteComment removes any user's comment (moderation), including its replies
+// DELETE /api/admin/comments/:id
+func (h *AdminHandler) DeleteComment(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
 
-	err = h.adminRepo.UpdateMatchStatus(matchID, req.Status)
+	commentID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to update match status", err)
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid comment ID", err)
 		return
 	}
 
-	// Log admin action
-	h.adminRepo.LogAdminAction(adminID, "update_match_status", "match", &matchID, map[string]interface{}{
-		"old_status": oldStatus,
-		"new_status": req.Status,
+	comment, err := h.commentRepo.GetByID(c.Request.Context(), commentID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get comment", err)
+		return
+	}
+	if comment == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "comment not found", nil)
+		return
+	}
+
+	if err := h.commentRepo.DeleteByID(c.Request.Context(), commentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(c, http.StatusNotFound, "comment not found", nil)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete comment", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "delete_comment", "comment", &commentID, map[string]interface{}{
+		"match_id": comment.MatchID,
+		"user_id":  comment.UserID,
+		"content":  comment.Content,
 	})
 
-	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "match status updated successfully"})
+	h.hub.Publish(realtime.MatchChannel(comment.MatchID), "comment.deleted", gin.H{"id": commentID})
+	h.hub.Publish(realtime.GlobalChannel, "comment.deleted", gin.H{"id": commentID, "match_id": comment.MatchID})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "comment deleted"})
+}
+
+// DeleteUserComments removes every comment of a user, e.g. after spam
+// DELETE /api/admin/users/:id/comments
+func (h *AdminHandler) DeleteUserComments(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid user ID", err)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+
+	deleted, err := h.commentRepo.DeleteByUser(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete comments", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "delete_user_comments", "user", &userID, map[string]interface{}{
+		"user":  user.Login,
+		"count": len(deleted),
+	})
+
+	for _, comment := range deleted {
+		h.hub.Publish(realtime.MatchChannel(comment.MatchID), "comment.deleted", gin.H{"id": comment.ID})
+		h.hub.Publish(realtime.GlobalChannel, "comment.deleted", gin.H{"id": comment.ID, "match_id": comment.MatchID})
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "comments deleted", "deleted": len(deleted)})
+}
+
+// RemoveReactions removes a user's reactions on a match; ?emoji= limits it to one emoji
+// DELETE /api/admin/matches/:id/reactions?user_id=
+func (h *AdminHandler) RemoveReactions(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+	userID, err := strconv.Atoi(c.Query("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "user_id is required", err)
+		return
+	}
+	emoji := c.Query("emoji")
+
+	removed, err := h.reactionRepo.RemoveByUser(c.Request.Context(), matchID, userID, emoji)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to remove reactions", err)
+		return
+	}
+	if removed == 0 {
+		utils.RespondWithError(c, http.StatusNotFound, "no matching reactions", nil)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "remove_reactions", "match", &matchID, map[string]interface{}{
+		"user_id": userID,
+		"emoji":   emoji,
+		"count":   removed,
+	})
+
+	if summary, err := h.reactionRepo.GetSummary(c.Request.Context(), matchID, 0); err == nil {
+		event := gin.H{"match_id": matchID, "user_id": userID, "emoji": emoji, "reactions": summary}
+		h.hub.Publish(realtime.MatchChannel(matchID), "reaction.removed", event)
+		h.hub.Publish(realtime.GlobalChannel, "reaction.removed", event)
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "reactions removed", "removed": removed})
+}
+
+// MuteUser shadow-mutes a user: new comments are only shown to the user themselves
+// POST /api/admin/users/:id/mute
+func (h *AdminHandler) MuteUser(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid user ID", err)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+	if user.IsAdmin {
+		utils.RespondWithError(c, http.StatusForbidden, "cannot mute staff", nil)
+		return
+	}
+
+	if err := h.adminRepo.MuteUser(c.Request.Context(), userID, adminID); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to mute user", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "mute_user", "user", &userID, map[string]interface{}{
+		"user": user.Login,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "user muted successfully"})
+}
+
+// UnmuteUser lifts a shadow mute; comments shadowed meanwhile stay hidden
+// DELETE /api/admin/users/:id/mute
+func (h *AdminHandler) UnmuteUser(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid user ID", err)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+
+	if err := h.adminRepo.UnmuteUser(c.Request.Context(), userID); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to unmute user", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "unmute_user", "user", &userID, map[string]interface{}{
+		"user": user.Login,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "user unmuted successfully"})
+}
+
+// GetMutedUsers lists the shadow-muted users
+// GET /api/admin/users/muted
+func (h *AdminHandler) GetMutedUsers(c *gin.Context) {
+	users, err := h.adminRepo.GetMutedUsers(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get muted users", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, users)
 }
 
 // GetDisputedMatches returns all disputed matches
 func (h *AdminHandler) GetDisputedMatches(c *gin.Context) {
-	matches, err := h.adminRepo.GetDisputedMatches()
+	matches, err := h.adminRepo.GetDisputedMatches(c.Request.Context())
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get disputed matches", err)
 		return
@@ -296,7 +825,7 @@ func (h *AdminHandler) GetConfirmedMatches(c *gin.Context) {
 		200, // max limit
 	)
 
-	matches, err := h.adminRepo.GetConfirmedMatches(pagination.Limit)
+	matches, err := h.adminRepo.GetConfirmedMatches(c.Request.Context(), pagination.Limit)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get confirmed matches", err)
 		return
@@ -305,7 +834,95 @@ func (h *AdminHandler) GetConfirmedMatches(c *gin.Context) {
 	utils.RespondWithJSON(c, http.StatusOK, matches)
 }
 
-// RevertMatch reverts a confirmed match by restoring ELO ratings and deleting the match
+// GetMatchAnomalies returns the anomaly-detection queue of matches whose
+// submission and confirmation came from the same (hashed) client fingerprint
+func (h *AdminHandler) GetMatchAnomalies(c *gin.Context) {
+	pagination := utils.ParsePaginationWithDefaults(
+		c.Query("limit"),
+		c.Query("offset"),
+		50,  // default limit
+		200, // max limit
+	)
+
+	anomalies, err := h.adminRepo.GetFingerprintAnomalies(c.Request.Context(), pagination.Limit, pagination.Offset)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get match anomalies", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, anomalies)
+}
+
+// GetFlaggedMatches returns the abuse scan's review queue, oldest flag first
+// GET /api/admin/matches/flagged?status=open|dismissed|confirmed
+func (h *AdminHandler) GetFlaggedMatches(c *gin.Context) {
+	status := c.DefaultQuery("status", models.FlagOpen)
+	switch status {
+	case models.FlagOpen, models.FlagDismissed, models.FlagConfirmed:
+	default:
+		utils.RespondWithError(c, http.StatusBadRequest, "status must be 'open', 'dismissed' or 'confirmed'", nil)
+		return
+	}
+
+	pagination := utils.ParsePaginationWithDefaults(
+		c.Query("limit"),
+		c.Query("offset"),
+		50,  // default limit
+		200, // max limit
+	)
+
+	flags, err := h.flagRepo.List(c.Request.Context(), status, pagination.Limit, pagination.Offset)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get flagged matches", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, flags)
+}
+
+// ReviewMatchFlag closes a flag as dismissed (legitimate) or confirmed (abuse)
+// Confirming does not touch the match; revert it or adjust ELO separately
+// POST /api/admin/matches/flags/:id/review
+func (h *AdminHandler) ReviewMatchFlag(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	flagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid flag ID", err)
+		return
+	}
+
+	var req models.ReviewMatchFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	flag, err := h.flagRepo.Review(c.Request.Context(), flagID, req.Status, adminID)
+	if err != nil {
+		switch err.Error() {
+		case "flag not found":
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+		case "flag is not open":
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to review flag", err)
+		}
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "review_match_flag", "match", &flag.MatchID, map[string]interface{}{
+		"flag_id": flag.ID,
+		"rule":    flag.Rule,
+		"status":  flag.Status,
+		"note":    utils.SanitizeString(req.Note),
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, flag)
+}
+
+// RevertMatch reverts a confirmed match by restoring ELO ratings and soft-deleting the match
+// POST /api/admin/matches/:id/revert
 func (h *AdminHandler) RevertMatch(c *gin.Context) {
 	adminID, _ := middleware.GetUserID(c)
 
@@ -316,21 +933,22 @@ func (h *AdminHandler) RevertMatch(c *gin.Context) {
 	}
 
 	// Get match details before reverting for logging
-	match, err := h.matchRepo.GetByID(matchID)
+	match, err := h.matchRepo.GetByID(c.Request.Context(), matchID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusNotFound, "match not found", err)
 		return
 	}
 
 	// Perform the revert
-	err = h.adminRepo.RevertMatch(matchID)
+	err = h.adminRepo.RevertMatch(c.Request.Context(), matchID, adminID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to revert match", err)
 		return
 	}
+	h.matchService.LeaderboardChanged(match.Sport)
 
 	// Log admin action
-	h.adminRepo.LogAdminAction(adminID, "revert_match", "match", &matchID, map[string]interface{}{
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "revert_match", "match", &matchID, map[string]interface{}{
 		"sport":             match.Sport,
 		"player1_id":        match.Player1ID,
 		"player2_id":        match.Player2ID,
@@ -343,6 +961,289 @@ func (h *AdminHandler) RevertMatch(c *gin.Context) {
 	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "match reverted successfully"})
 }
 
+// GetDeletedMatches returns deleted and reverted matches that can be restored
+// GET /api/admin/matches/deleted
+func (h *AdminHandler) GetDeletedMatches(c *gin.Context) {
+	pagination := utils.ParsePaginationWithDefaults(
+		c.Query("limit"),
+		c.Query("offset"),
+		50,  // default limit
+		200, // max limit
+	)
+
+	matches, err := h.adminRepo.GetDeletedMatches(c.Request.Context(), pagination.Limit)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get deleted matches", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, matches)
+}
+
+// RestoreMatch brings back a deleted match; a reverted match gets its ELO changes reapplied
+// POST /api/admin/matches/:id/restore
+func (h *AdminHandler) RestoreMatch(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	reverted, err := h.adminRepo.RestoreMatch(c.Request.Context(), matchID)
+	if err != nil {
+		switch err.Error() {
+		case "match not found":
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+		case "match has no ELO data to reapply":
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to restore match", err)
+		}
+		return
+	}
+
+	match, err := h.matchRepo.GetByID(c.Request.Context(), matchID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get restored match", err)
+		return
+	}
+	h.matchService.LeaderboardChanged(match.Sport)
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "restore_match", "match", &matchID, map[string]interface{}{
+		"sport":      match.Sport,
+		"player1_id": match.Player1ID,
+		"player2_id": match.Player2ID,
+		"status":     match.Status,
+		"reverted":   reverted,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, match)
+}
+
+// RecordForfeit records a forfeit between two players as organizer; it is applied without confirmation
+// POST /api/admin/matches/forfeit
+func (h *AdminHandler) RecordForfeit(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req models.RecordForfeitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	match, err := h.matchService.RecordForfeit(c.Request.Context(), &req, adminID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "record_forfeit", "match", &match.ID, map[string]interface{}{
+		"sport":        match.Sport,
+		"winner_id":    match.WinnerID,
+		"forfeited_by": req.ForfeitedBy,
+	})
+
+	utils.RespondWithJSON(c, http.StatusCreated, match)
+}
+
+// EditMatch corrects the scores or winner of a confirmed match and reapplies the ELO changes
+// PUT /api/admin/matches/:id
+func (h *AdminHandler) EditMatch(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	var req models.EditMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err := utils.ValidateReason(req.Reason); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before, after, err := h.matchService.CorrectMatch(c.Request.Context(), matchID, &req)
+	if err != nil {
+		if err.Error() == "match not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		if errors.Is(err, repositories.ErrMatchStatusChanged) {
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "edit_match", "match", &matchID, map[string]interface{}{
+		"reason": strings.TrimSpace(req.Reason),
+		"before": matchAuditSnapshot(before),
+		"after":  matchAuditSnapshot(after),
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, after)
+}
+
+// matchAuditSnapshot is the part of a match recorded in the audit log when it is edited
+func matchAuditSnapshot(match *models.Match) map[string]interface{} {
+	return map[string]interface{}{
+		"player1_score":     match.Player1Score,
+		"player2_score":     match.Player2Score,
+		"winner_id":         match.WinnerID,
+		"forfeited_by":      match.ForfeitedBy,
+		"player1_elo_delta": match.Player1ELODelta,
+		"player2_elo_delta": match.Player2ELODelta,
+	}
+}
+
+// maxMatchImportSize bounds the body of a CSV match import
+const maxMatchImportSize = 5 << 20
+
+// ImportMatchesCSV backfills historical matches from a CSV in the layout of the match export
+// Rows with errors are skipped and reported; ?dry_run=true only validates and reports
+// POST /api/admin/matches/import
+func (h *AdminHandler) ImportMatchesCSV(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "dry_run must be a boolean", err)
+			return
+		}
+		dryRun = parsed
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxMatchImportSize))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("CSV must be at most %d MB", maxMatchImportSize>>20), err)
+		return
+	}
+
+	report, err := h.matchImport.Import(c.Request.Context(), bytes.NewReader(body), dryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMatchCSV) {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to import matches", err)
+		return
+	}
+
+	if !dryRun && report.Imported > 0 {
+		h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "import_matches_csv", "system", nil, map[string]interface{}{
+			"rows":     report.Rows,
+			"imported": report.Imported,
+			"errors":   len(report.Errors),
+		})
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, report)
+}
+
+// RebuildLeaderboard re-ranks the materialized leaderboard of one sport, or of all sports
+// Rankings follow every rating change; this repairs them if a re-rank failed after a change
+// POST /api/admin/leaderboard/rebuild?sport=table_tennis
+func (h *AdminHandler) RebuildLeaderboard(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var sports []string
+	if sport := c.Query("sport"); sport != "" {
+		sports = append(sports, sport)
+	}
+
+	changed, err := h.matchService.RebuildLeaderboard(c.Request.Context(), sports...)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to rebuild leaderboard", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "rebuild_leaderboard", "system", nil, map[string]interface{}{
+		"sports":       sports,
+		"rows_changed": changed,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"rows_changed": changed})
+}
+
+// RecomputeELO rebuilds all ratings by replaying every confirmed match from scratch
+// Only reports the changed ratings unless ?apply=true
+// POST /api/admin/elo/recompute
+func (h *AdminHandler) RecomputeELO(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	apply := false
+	if v := c.Query("apply"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "apply must be a boolean", err)
+			return
+		}
+		apply = parsed
+	}
+
+	report, err := h.recompute.RecomputeELO(c.Request.Context(), apply)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to recompute ELO", err)
+		return
+	}
+
+	if apply {
+		h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "recompute_elo", "system", nil, map[string]interface{}{
+			"matches_replayed": report.MatchesReplayed,
+			"matches_changed":  report.MatchesChanged,
+			"ratings_changed":  len(report.Changes),
+		})
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, report)
+}
+
+// GetInconsistentMatches returns matches whose winner does not match the scores
+// GET /api/admin/matches/inconsistent
+func (h *AdminHandler) GetInconsistentMatches(c *gin.Context) {
+	matches, err := h.adminRepo.GetInconsistentWinners(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get inconsistent matches", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, matches)
+}
+
+// RepairMatchWinners recomputes winner_id from the scores for all inconsistent matches
+// POST /api/admin/matches/repair-winners
+func (h *AdminHandler) RepairMatchWinners(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	repairs, err := h.adminRepo.RepairMatchWinners(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to repair match winners", err)
+		return
+	}
+
+	if len(repairs) > 0 {
+		h.rebuildLeaderboard(c)
+		h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "repair_match_winners", "system", nil, map[string]interface{}{
+			"repairs": repairs,
+		})
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{
+		"repaired": len(repairs),
+		"matches":  repairs,
+	})
+}
+
 // GetAuditLog returns admin audit log
 func (h *AdminHandler) GetAuditLog(c *gin.Context) {
 	// Use pagination utility with enforced maximum limits
@@ -353,7 +1254,7 @@ func (h *AdminHandler) GetAuditLog(c *gin.Context) {
 		500, // max limit for admin
 	)
 
-	logs, err := h.adminRepo.GetAuditLog(pagination.Limit)
+	logs, err := h.adminRepo.GetAuditLog(c.Request.Context(), pagination.Limit)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get audit log", err)
 		return
@@ -366,14 +1267,14 @@ func (h *AdminHandler) GetAuditLog(c *gin.Context) {
 func (h *AdminHandler) ExportMatchesCSV(c *gin.Context) {
 	adminID, _ := middleware.GetUserID(c)
 
-	matches, err := h.adminRepo.ExportMatchesCSV()
+	matches, err := h.adminRepo.ExportMatchesCSV(c.Request.Context())
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to export matches", err)
 		return
 	}
 
 	// Log admin action
-	h.adminRepo.LogAdminAction(adminID, "export_matches_csv", "system", nil, map[string]interface{}{
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "export_matches_csv", "system", nil, map[string]interface{}{
 		"count": len(matches),
 	})
 
@@ -430,14 +1331,14 @@ func (h *AdminHandler) ExportMatchesCSV(c *gin.Context) {
 func (h *AdminHandler) ExportUsersCSV(c *gin.Context) {
 	adminID, _ := middleware.GetUserID(c)
 
-	users, err := h.adminRepo.ExportUsersCSV()
+	users, err := h.adminRepo.ExportUsersCSV(c.Request.Context())
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to export users", err)
 		return
 	}
 
 	// Log admin action
-	h.adminRepo.LogAdminAction(adminID, "export_users_csv", "system", nil, map[string]interface{}{
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "export_users_csv", "system", nil, map[string]interface{}{
 		"count": len(users),
 	})
 
@@ -450,7 +1351,7 @@ func (h *AdminHandler) ExportUsersCSV(c *gin.Context) {
 	// Write header
 	writer.Write([]string{
 		"ID", "IntraID", "Login", "DisplayName", "Campus",
-		"TableTennisELO", "TableFootballELO", "IsAdmin", "IsBanned",
+		"ELO", "IsAdmin", "IsBanned",
 		"BanReason", "BannedAt", "CreatedAt", "UpdatedAt",
 	})
 
@@ -471,8 +1372,7 @@ func (h *AdminHandler) ExportUsersCSV(c *gin.Context) {
 			u.Login,
 			u.DisplayName,
 			u.Campus,
-			strconv.Itoa(u.TableTennisELO),
-			strconv.Itoa(u.TableFootballELO),
+			formatSportELOs(u.Sports),
 			strconv.FormatBool(u.IsAdmin),
 			strconv.FormatBool(u.IsBanned),
 			banReason,
@@ -483,6 +1383,16 @@ func (h *AdminHandler) ExportUsersCSV(c *gin.Context) {
 	}
 }
 
+// formatSportELOs lists a user's ratings as "sport=elo" pairs sorted by sport, e.g. "chess=1012;table_tennis=980"
+func formatSportELOs(sports map[string]models.UserSportData) string {
+	pairs := make([]string, 0, len(sports))
+	for sport, data := range sports {
+		pairs = append(pairs, sport+"="+strconv.Itoa(data.CurrentELO))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
+
 // Helper function
 func intPtrToString(p *int) string {
 	if p == nil {
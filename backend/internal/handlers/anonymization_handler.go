@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AnonymizationHandler manages the anonymization vocabulary (admin only)
+type AnonymizationHandler struct {
+	anonRepo    repositories.AnonymizationStore
+	anonService *services.AnonymizationService
+	adminRepo   repositories.AdminStore
+}
+
+// NewAnonymizationHandler creates a new anonymization handler
+func NewAnonymizationHandler(
+	anonRepo repositories.AnonymizationStore,
+	anonService *services.AnonymizationService,
+	adminRepo repositories.AdminStore,
+) *AnonymizationHandler {
+	return &AnonymizationHandler{
+		anonRepo:    anonRepo,
+		anonService: anonService,
+		adminRepo:   adminRepo,
+	}
+}
+
+// ListWords returns the global and per-campus vocabularies
+// GET /api/admin/anonymization/words
+func (h *AnonymizationHandler) ListWords(c *gin.Context) {
+	words, err := h.anonRepo.ListWords(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch words", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, words)
+}
+
+// AddWord adds an adjective or animal to a campus vocabulary
+// POST /api/admin/anonymization/words
+func (h *AnonymizationHandler) AddWord(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req models.AddAnonymizationWordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	word, err := utils.ValidateInput(strings.TrimSpace(req.Word), 50, false)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid word", err)
+		return
+	}
+
+	created, err := h.anonRepo.AddWord(c.Request.Context(), strings.TrimSpace(req.Campus), req.Kind, word)
+	if err != nil {
+		if err.Error() == "word already exists" {
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to add word", err)
+		return
+	}
+
+	h.anonService.InvalidateCache()
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "add_anonymization_word", "system", &created.ID, map[string]interface{}{
+		"campus": created.Campus,
+		"kind":   created.Kind,
+		"word":   created.Word,
+	})
+
+	utils.RespondWithJSON(c, http.StatusCreated, created)
+}
+
+// DeleteWord removes a word from the vocabulary
+// Names already assigned to players are kept so aliases stay stable
+// DELETE /api/admin/anonymization/words/:id
+func (h *AnonymizationHandler) DeleteWord(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	wordID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid word ID", err)
+		return
+	}
+
+	if err := h.anonRepo.DeleteWord(c.Request.Context(), wordID); err != nil {
+		if err.Error() == "word not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete word", err)
+		return
+	}
+
+	h.anonService.InvalidateCache()
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "delete_anonymization_word", "system", &wordID, nil)
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "word deleted successfully"})
+}
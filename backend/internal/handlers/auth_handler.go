@@ -1,34 +1,49 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/42heilbronn/elo-leaderboard/internal/config"
 	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
 	"github.com/42heilbronn/elo-leaderboard/internal/models"
 	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/revocation"
 	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/telemetry"
 	"github.com/42heilbronn/elo-leaderboard/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type AuthHandler struct {
 	cfg          *config.Config
-	userRepo     *repositories.UserRepository
+	userRepo     repositories.UserStore
 	matchService *services.MatchService
+	denyList     *revocation.DenyList
+	deletions    *services.AccountDeletionService
+	legalRepo    repositories.LegalStore
+	anonService  *services.AnonymizationService
+	intraHTTP    *http.Client // OAuth calls to the 42 API on behalf of the user logging in
 }
 
-func NewAuthHandler(cfg *config.Config, userRepo *repositories.UserRepository, matchService *services.MatchService) *AuthHandler {
+func NewAuthHandler(cfg *config.Config, userRepo repositories.UserStore, matchService *services.MatchService, denyList *revocation.DenyList, deletions *services.AccountDeletionService, legalRepo repositories.LegalStore, anonService *services.AnonymizationService) *AuthHandler {
 	return &AuthHandler{
 		cfg:          cfg,
 		userRepo:     userRepo,
 		matchService: matchService,
+		denyList:     denyList,
+		deletions:    deletions,
+		legalRepo:    legalRepo,
+		anonService:  anonService,
+		intraHTTP:    &http.Client{Timeout: 10 * time.Second, Transport: telemetry.Transport(nil)},
 	}
 }
 
@@ -37,7 +52,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// Generate a cryptographically secure CSRF state token
 	state, err := utils.GenerateCSRFToken()
 	if err != nil {
-		slog.Error("Failed to generate CSRF token", "error", err)
+		slog.ErrorContext(c.Request.Context(), "Failed to generate CSRF token", "error", err)
 		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate security token", err)
 		return
 	}
@@ -78,7 +93,7 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 	expectedState, err := c.Cookie("oauth_state")
 	if err == nil && expectedState != "" {
 		if csrfErr := utils.ValidateCSRFToken(expectedState, state); csrfErr != nil {
-			slog.Warn("CSRF state mismatch", "error", csrfErr)
+			slog.WarnContext(c.Request.Context(), "CSRF state mismatch", "error", csrfErr)
 			c.Redirect(http.StatusTemporaryRedirect, h.cfg.FrontendURL+"/?error=invalid_state")
 			return
 		}
@@ -95,24 +110,24 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 	}
 
 	// Exchange code for token
-	token, err := h.exchangeCodeForToken(code)
+	token, err := h.exchangeCodeForToken(c.Request.Context(), code)
 	if err != nil {
-		slog.Error("Token exchange failed", "error", err)
+		slog.ErrorContext(c.Request.Context(), "Token exchange failed", "error", err)
 		c.Redirect(http.StatusTemporaryRedirect, h.cfg.FrontendURL+"/?error=token_exchange_failed")
 		return
 	}
 
 	// Get user info from 42 API
-	userInfo, err := h.get42UserInfo(token)
+	userInfo, err := h.get42UserInfo(c.Request.Context(), token)
 	if err != nil {
-		slog.Error("Failed to get user info", "error", err)
+		slog.ErrorContext(c.Request.Context(), "Failed to get user info", "error", err)
 		c.Redirect(http.StatusTemporaryRedirect, h.cfg.FrontendURL+"/?error=user_info_failed")
 		return
 	}
 
 	// Validate campus - check if user has Heilbronn campus
 	var campusName string
-	slog.Info("Checking user campus", "user", userInfo.Login, "campus_count", len(userInfo.Campus))
+	slog.InfoContext(c.Request.Context(), "Checking user campus", "user", userInfo.Login, "campus_count", len(userInfo.Campus))
 	for _, campus := range userInfo.Campus {
 		if campus.Name == "Heilbronn" {
 			campusName = "Heilbronn"
@@ -120,33 +135,94 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 		}
 	}
 	if campusName == "" {
-		slog.Warn("No Heilbronn campus found", "user", userInfo.Login)
+		slog.WarnContext(c.Request.Context(), "No Heilbronn campus found", "user", userInfo.Login)
 		c.Redirect(http.StatusTemporaryRedirect, h.cfg.FrontendURL+"/?error=invalid_campus")
 		return
 	}
 
+	// Resolve intra IDs that were relinked to an existing profile after an account migration
+	profileID := userInfo.ID
+	linkedID, linked, err := h.userRepo.GetLinkedUserID(c.Request.Context(), userInfo.ID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to resolve intra ID link", "error", err)
+		c.Redirect(http.StatusTemporaryRedirect, h.cfg.FrontendURL+"/?error=user_creation_failed")
+		return
+	}
+	if linked {
+		profileID = linkedID
+	}
+
 	// Create or update user
 	user := &models.User{
-		IntraID:     userInfo.ID,
+		IntraID:     profileID,
 		Login:       userInfo.Login,
 		DisplayName: userInfo.DisplayName,
 		AvatarURL:   userInfo.Image.Link,
 		Campus:      campusName,
 	}
 
-	if err := h.userRepo.CreateOrUpdate(user); err != nil {
-		slog.Error("Failed to create/update user", "error", err)
+	created, err := h.userRepo.CreateOrUpdate(c.Request.Context(), user)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to create/update user", "error", err)
 		c.Redirect(http.StatusTemporaryRedirect, h.cfg.FrontendURL+"/?error=user_creation_failed&details="+url.QueryEscape(err.Error()))
 		return
 	}
 
-	// Invalidate leaderboard cache to ensure new/updated user appears immediately
-	h.matchService.InvalidateLeaderboardCache()
+	// Guests see players under their anonymous name, so every player needs one before being listed
+	if _, err := h.anonService.AssignName(c.Request.Context(), *user); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to assign anonymous name", "error", err, "user_id", user.ID)
+		c.Redirect(http.StatusTemporaryRedirect, h.cfg.FrontendURL+"/?error=user_creation_failed")
+		return
+	}
+
+	// Coalition and piscine year for the coalition standings; login continues without them
+	h.syncIntraProfile(c.Request.Context(), token, userInfo, user.ID)
+
+	// Logging in again undoes a self-service deactivation
+	reactivated, err := h.userRepo.Reactivate(c.Request.Context(), user.ID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to reactivate account", "error", err, "user", user.Login)
+	} else if reactivated {
+		slog.InfoContext(c.Request.Context(), "Account reactivated on login", "user_id", user.ID, "login", user.Login)
+	}
+
+	// New and returning players were ranked with their account; show them right away
+	if created || reactivated {
+		h.matchService.LeaderboardChanged()
+	}
+
+	// Let the frontend offer self-service relinking if an older profile with the same login exists
+	relinkAvailable := false
+	if !linked {
+		candidate, err := h.userRepo.FindRelinkCandidate(c.Request.Context(), user.Login, user.ID)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "Failed to check for relink candidate", "error", err, "user", user.Login)
+		}
+		relinkAvailable = candidate != nil
+	}
+
+	// Warn the user on login that their account is about to be erased
+	deletionScheduled := false
+	if pending, err := h.deletions.Get(c.Request.Context(), user.ID); err != nil {
+		slog.WarnContext(c.Request.Context(), "Failed to check for scheduled account deletion", "error", err, "user", user.Login)
+	} else {
+		deletionScheduled = pending != nil
+	}
+
+	// Let the frontend ask for acceptance of the terms and privacy notice, e.g. after a new version
+	legalPending := false
+	if lang, err := h.userRepo.GetLanguage(c.Request.Context(), user.ID); err != nil {
+		slog.WarnContext(c.Request.Context(), "Failed to check for pending legal documents", "error", err, "user", user.Login)
+	} else if pending, err := h.legalRepo.ListPending(c.Request.Context(), user.ID, lang, models.LegalAcceptanceRequired); err != nil {
+		slog.WarnContext(c.Request.Context(), "Failed to check for pending legal documents", "error", err, "user", user.Login)
+	} else {
+		legalPending = len(pending) > 0
+	}
 
 	// Generate JWT
 	jwt, err := utils.GenerateJWT(user.ID, h.cfg.JWTSecret)
 	if err != nil {
-		slog.Error("Failed to generate JWT", "error", err)
+		slog.ErrorContext(c.Request.Context(), "Failed to generate JWT", "error", err)
 		c.Redirect(http.StatusTemporaryRedirect, h.cfg.FrontendURL+"/?error=token_generation_failed")
 		return
 	}
@@ -155,20 +231,23 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 	if h.cfg.UseHTTPOnlyCookie {
 		// Set httpOnly cookie - more secure than localStorage as it's not accessible via JavaScript
 		// This protects against XSS attacks stealing the token
-		http.SetCookie(c.Writer, &http.Cookie{
-			Name:     "auth_token",
-			Value:    jwt,
-			Path:     "/",
-			Domain:   h.cfg.CookieDomain,
-			MaxAge:   int(7 * 24 * time.Hour / time.Second), // 7 days
-			HttpOnly: true,                                   // Not accessible via JavaScript
-			Secure:   h.cfg.CookieSecure,                    // Only send over HTTPS in production
-			SameSite: http.SameSiteStrictMode,               // Prevent CSRF
-		})
+		h.setAuthCookie(c, jwt)
 		redirectURL := h.cfg.FrontendURL + "/?auth=success"
 		if state != "" {
 			redirectURL += "&state=" + url.QueryEscape(state)
 		}
+		if relinkAvailable {
+			redirectURL += "&relink=available"
+		}
+		if deletionScheduled {
+			redirectURL += "&deletion=scheduled"
+		}
+		if reactivated {
+			redirectURL += "&account=reactivated"
+		}
+		if legalPending {
+			redirectURL += "&legal=pending"
+		}
 		c.Redirect(http.StatusTemporaryRedirect, redirectURL)
 		return
 	}
@@ -178,11 +257,145 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 	if state != "" {
 		redirectURL += "&state=" + url.QueryEscape(state)
 	}
+	if relinkAvailable {
+		redirectURL += "&relink=available"
+	}
+	if deletionScheduled {
+		redirectURL += "&deletion=scheduled"
+	}
+	if reactivated {
+		redirectURL += "&account=reactivated"
+	}
+	if legalPending {
+		redirectURL += "&legal=pending"
+	}
 	c.Redirect(http.StatusTemporaryRedirect, redirectURL)
 }
 
-// Logout clears the auth cookie (for httpOnly cookie mode)
+// setAuthCookie stores the JWT in an httpOnly cookie
+func (h *AuthHandler) setAuthCookie(c *gin.Context, token string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "auth_token",
+		Value:    token,
+		Path:     "/",
+		Domain:   h.cfg.CookieDomain,
+		MaxAge:   int(7 * 24 * time.Hour / time.Second), // 7 days
+		HttpOnly: true,                                   // Not accessible via JavaScript
+		Secure:   h.cfg.CookieSecure,                    // Only send over HTTPS in production
+		SameSite: h.cfg.CookieSameSite,                  // Strict by default to prevent CSRF
+	})
+}
+
+// RelinkPreviousAccount moves the current login onto an older profile with the same login
+// Used after a 42 account migration issued a new intra ID: the fresh profile created at login
+// is discarded and the old profile (ELO, history) is kept. Returns a token for the old profile.
+func (h *AuthHandler) RelinkPreviousAccount(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+
+	// 42 logins are unique per person, so the same login proves ownership of the old profile
+	previous, err := h.userRepo.FindRelinkCandidate(c.Request.Context(), user.Login, user.ID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to look up previous profile", err)
+		return
+	}
+	if previous == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "no previous profile found for your login", nil)
+		return
+	}
+	if previous.Banned() {
+		utils.RespondWithError(c, http.StatusForbidden, "previous profile is banned", nil)
+		return
+	}
+
+	if err := h.userRepo.RelinkIntraID(c.Request.Context(), user.ID, previous.ID, "self_service", nil); err != nil {
+		if errors.Is(err, repositories.ErrIntraIDInUse) {
+			utils.RespondWithError(c, http.StatusConflict, "your current profile already has match history; ask an admin to merge accounts", err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to link previous profile", err)
+		return
+	}
+
+	h.matchService.LeaderboardChanged()
+	slog.InfoContext(c.Request.Context(), "Intra ID relinked to previous profile", "intra_id", user.ID, "user_id", previous.ID, "login", user.Login)
+
+	token, err := utils.GenerateJWT(previous.ID, h.cfg.JWTSecret)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate token", err)
+		return
+	}
+
+	if h.cfg.UseHTTPOnlyCookie {
+		h.setAuthCookie(c, token)
+		utils.RespondWithJSON(c, http.StatusOK, gin.H{"user": previous})
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"user": previous, "token": token})
+}
+
+// DeactivateAccount hides the current user from the leaderboards and blocks new matches and
+// challenges against them; unlike account deletion all data is kept and the next login
+// reactivates the account. All sessions of the user are signed out
+// POST /api/users/me/deactivate
+func (h *AuthHandler) DeactivateAccount(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	deactivated, err := h.userRepo.Deactivate(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to deactivate account", err)
+		return
+	}
+	if !deactivated {
+		utils.RespondWithError(c, http.StatusConflict, "account is already deactivated", nil)
+		return
+	}
+
+	h.matchService.LeaderboardChanged()
+	if err := h.denyList.RevokeUser(c.Request.Context(), userID); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to revoke tokens of deactivated user", "error", err, "user_id", userID)
+	}
+	slog.InfoContext(c.Request.Context(), "Account deactivated", "user_id", userID)
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "auth_token",
+		Value:    "",
+		Path:     "/",
+		Domain:   h.cfg.CookieDomain,
+		MaxAge:   -1, // Delete the cookie
+		HttpOnly: true,
+		Secure:   h.cfg.CookieSecure,
+		SameSite: h.cfg.CookieSameSite,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "account deactivated; log in again to reactivate it"})
+}
+
+// Logout revokes the current token and clears the auth cookie (for httpOnly cookie mode)
 func (h *AuthHandler) Logout(c *gin.Context) {
+	// Revoke the presented token so it can't be reused on any instance
+	if token := middleware.TokenFromRequest(c); token != "" {
+		if claims, err := utils.ValidateJWT(token, h.cfg.JWTSecret); err == nil && claims.ID != "" {
+			if err := h.denyList.RevokeToken(c.Request.Context(), claims); err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to revoke token on logout", "error", err, "user_id", claims.UserID)
+			}
+		}
+	}
+
 	// Clear the auth cookie by setting it with a past expiration
 	http.SetCookie(c.Writer, &http.Cookie{
 		Name:     "auth_token",
@@ -192,7 +405,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		MaxAge:   -1, // Delete the cookie
 		HttpOnly: true,
 		Secure:   h.cfg.CookieSecure,
-		SameSite: http.SameSiteStrictMode,
+		SameSite: h.cfg.CookieSameSite,
 	})
 
 	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "logged out"})
@@ -206,28 +419,36 @@ func (h *AuthHandler) Me(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.GetByID(userID)
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
 		return
 	}
 
+	pending, err := h.deletions.Get(c.Request.Context(), userID)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Failed to check for scheduled account deletion", "error", err, "user_id", userID)
+	} else if pending != nil {
+		user.DeletionScheduledFor = &pending.EraseAfter
+	}
+
 	utils.RespondWithJSON(c, http.StatusOK, user)
 }
 
 // GetUsers returns all users
+// Responds with CSV for Accept: text/csv or ?format=csv
 func (h *AuthHandler) GetUsers(c *gin.Context) {
-	users, err := h.userRepo.GetAll()
+	users, err := h.userRepo.GetAll(c.Request.Context())
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
 	}
 
-	utils.RespondWithJSON(c, http.StatusOK, users)
+	utils.RespondWithList(c, http.StatusOK, "users", users)
 }
 
 // exchangeCodeForToken exchanges authorization code for access token
-func (h *AuthHandler) exchangeCodeForToken(code string) (string, error) {
+func (h *AuthHandler) exchangeCodeForToken(ctx context.Context, code string) (string, error) {
 	data := url.Values{}
 	data.Set("grant_type", "authorization_code")
 	data.Set("client_id", h.cfg.FTClientUID)
@@ -235,7 +456,13 @@ func (h *AuthHandler) exchangeCodeForToken(code string) (string, error) {
 	data.Set("code", code)
 	data.Set("redirect_uri", h.cfg.FTRedirectURI)
 
-	resp, err := http.PostForm("https://api.intra.42.fr/oauth/token", data)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.intra.42.fr/oauth/token", strings.NewReader(data.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := h.intraHTTP.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -264,16 +491,15 @@ func (h *AuthHandler) exchangeCodeForToken(code string) (string, error) {
 }
 
 // get42UserInfo fetches user info from 42 API
-func (h *AuthHandler) get42UserInfo(token string) (*FTUserInfo, error) {
-	req, err := http.NewRequest("GET", "https://api.intra.42.fr/v2/me", nil)
+func (h *AuthHandler) get42UserInfo(ctx context.Context, token string) (*FTUserInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.intra.42.fr/v2/me", nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := h.intraHTTP.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -296,11 +522,59 @@ func (h *AuthHandler) get42UserInfo(token string) (*FTUserInfo, error) {
 	return &userInfo, nil
 }
 
+// syncIntraProfile stores the coalition and piscine year of a user who just logged in
+// Failures are only logged: the previous values are kept until the next login
+func (h *AuthHandler) syncIntraProfile(ctx context.Context, token string, userInfo *FTUserInfo, userID int) {
+	coalition, err := h.get42Coalition(ctx, token, userInfo.ID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to fetch coalition", "error", err, "user", userInfo.Login)
+		return
+	}
+	if err := h.userRepo.SetIntraProfile(ctx, userID, coalition, userInfo.PoolYear); err != nil {
+		slog.WarnContext(ctx, "Failed to store intra profile", "error", err, "user", userInfo.Login)
+	}
+}
+
+// get42Coalition fetches the coalition of a user from the 42 API
+// Returns the first coalition listed, or nil if the user has none
+func (h *AuthHandler) get42Coalition(ctx context.Context, token string, intraID int) (*models.Coalition, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.intra.42.fr/v2/users/%d/coalitions", intraID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := h.intraHTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get coalitions: status %d", resp.StatusCode)
+	}
+
+	var coalitions []models.Coalition
+	if err := json.NewDecoder(resp.Body).Decode(&coalitions); err != nil {
+		return nil, err
+	}
+	if len(coalitions) == 0 {
+		return nil, nil
+	}
+
+	return &coalitions[0], nil
+}
+
 // FTUserInfo represents 42 API user response
 type FTUserInfo struct {
 	ID          int    `json:"id"`
 	Login       string `json:"login"`
 	DisplayName string `json:"displayname"`
+	PoolYear    string `json:"pool_year"`
 	Image       struct {
 		Link string `json:"link"`
 	} `json:"image"`
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/cache"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CacheHandler reports how well the shared cache works
+type CacheHandler struct {
+	cache *cache.Instrumented
+}
+
+// NewCacheHandler creates a new cache handler
+func NewCacheHandler(c *cache.Instrumented) *CacheHandler {
+	return &CacheHandler{cache: c}
+}
+
+// GetStats returns the hits, misses, writes and invalidations of this instance per key namespace
+// GET /api/admin/cache
+func (h *CacheHandler) GetStats(c *gin.Context) {
+	utils.RespondWithJSON(c, http.StatusOK, h.cache.Stats())
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarHandler serves the iCalendar feed of a player's matches and challenges
+type CalendarHandler struct {
+	calendar *services.CalendarService
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(calendar *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendar: calendar}
+}
+
+// GetFeedLink returns the subscription URL of the caller's calendar feed
+// The URL carries a signed token, so it must be kept private like a password
+// GET /api/users/me/calendar
+func (h *CalendarHandler) GetFeedLink(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	utils.RespondWithJSON(c, http.StatusOK, h.calendar.FeedLink(userID))
+}
+
+// GetFeed returns the iCalendar feed of confirmed matches and scheduled challenges
+// Calendar apps authenticate with the ?token= from GetFeedLink; a logged-in browser
+// session works as well
+// GET /api/users/me/matches.ics?token=
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if token := c.Query("token"); token != "" {
+		var err error
+		userID, err = h.calendar.Validate(token)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusUnauthorized, err.Error(), err)
+			return
+		}
+	} else if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "calendar token required", nil)
+		return
+	}
+
+	feed, err := h.calendar.Feed(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		respondQueryError(c, "failed to build calendar", err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=900")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
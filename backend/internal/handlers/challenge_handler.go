@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ChallengeHandler serves scheduled matches between two players
+type ChallengeHandler struct {
+	challenges    *services.ChallengeService
+	sportService  *services.SportService
+	confirmTokens *services.ConfirmationTokenService
+	userRepo      repositories.UserStore
+	inbox         *notifications.Inbox
+}
+
+// NewChallengeHandler creates a new challenge handler
+func NewChallengeHandler(
+	challenges *services.ChallengeService,
+	sportService *services.SportService,
+	confirmTokens *services.ConfirmationTokenService,
+	userRepo repositories.UserStore,
+	inbox *notifications.Inbox,
+) *ChallengeHandler {
+	return &ChallengeHandler{
+		challenges:    challenges,
+		sportService:  sportService,
+		confirmTokens: confirmTokens,
+		userRepo:      userRepo,
+		inbox:         inbox,
+	}
+}
+
+// CreateChallenge challenges another player to a match at a time slot
+// POST /api/challenges
+func (h *ChallengeHandler) CreateChallenge(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.CreateChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	challenge, err := h.challenges.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	h.notify(c.Request.Context(), challenge, challenge.OpponentID, userID, models.NotificationChallengeReceived)
+	utils.RespondWithJSON(c, http.StatusCreated, challenge)
+}
+
+// ListChallenges returns the current user's challenges, most recent slot first
+// GET /api/challenges?direction=incoming|outgoing&status=pending
+func (h *ChallengeHandler) ListChallenges(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	direction := c.Query("direction")
+	if direction != "" && direction != repositories.ChallengesIncoming && direction != repositories.ChallengesOutgoing {
+		utils.RespondWithError(c, http.StatusBadRequest, "direction must be 'incoming' or 'outgoing'", nil)
+		return
+	}
+
+	var status *string
+	if s := c.Query("status"); s != "" {
+		switch s {
+		case models.ChallengePending, models.ChallengeAccepted, models.ChallengeDeclined,
+			models.ChallengeCancelled, models.ChallengeCompleted:
+			status = &s
+		default:
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid status", nil)
+			return
+		}
+	}
+
+	challenges, err := h.challenges.List(c.Request.Context(), userID, direction, status)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch challenges", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"challenges": challenges})
+}
+
+// GetChallenge returns a challenge of the current user
+// GET /api/challenges/:id
+func (h *ChallengeHandler) GetChallenge(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	challengeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid challenge ID", err)
+		return
+	}
+
+	challenge, err := h.challenges.Get(c.Request.Context(), challengeID, userID)
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, challenge)
+}
+
+// AcceptChallenge agrees to play a challenge received by the current user
+// POST /api/challenges/:id/accept
+func (h *ChallengeHandler) AcceptChallenge(c *gin.Context) {
+	h.answer(c, h.challenges.Accept, models.NotificationChallengeAccepted)
+}
+
+// DeclineChallenge refuses a challenge received by the current user
+// POST /api/challenges/:id/decline
+func (h *ChallengeHandler) DeclineChallenge(c *gin.Context) {
+	h.answer(c, h.challenges.Decline, models.NotificationChallengeDeclined)
+}
+
+// CancelChallenge withdraws a challenge sent by the current user
+// POST /api/challenges/:id/cancel
+func (h *ChallengeHandler) CancelChallenge(c *gin.Context) {
+	h.answer(c, h.challenges.Cancel, models.NotificationChallengeCancelled)
+}
+
+// answer applies a status change and notifies the other player
+func (h *ChallengeHandler) answer(c *gin.Context, apply func(ctx context.Context, challengeID, userID int) (*models.Challenge, error), kind string) {
+	userID, _ := middleware.GetUserID(c)
+
+	challengeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid challenge ID", err)
+		return
+	}
+
+	challenge, err := apply(c.Request.Context(), challengeID, userID)
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	recipientID := challenge.ChallengerID
+	if userID == challenge.ChallengerID {
+		recipientID = challenge.OpponentID
+	}
+	h.notify(c.Request.Context(), challenge, recipientID, userID, kind)
+
+	utils.RespondWithJSON(c, http.StatusOK, challenge)
+}
+
+// SubmitResult enters the result of an accepted challenge as a pending match
+// The other player confirms or denies it like any submitted match
+// POST /api/challenges/:id/result
+func (h *ChallengeHandler) SubmitResult(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	challengeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid challenge ID", err)
+		return
+	}
+
+	var result models.ChallengeResultRequest
+	if err := c.ShouldBindJSON(&result); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	challenge, err := h.challenges.Get(c.Request.Context(), challengeID, userID)
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	req := h.challenges.MatchRequest(challenge, userID, &result)
+	if err := utils.ScoreSeries(req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err := utils.ValidateMatchSubmission(req.Sport, req.OpponentID, req.PlayerScore, req.OpponentScore, userID); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	match, err := h.challenges.SubmitResult(c.Request.Context(), challengeID, userID, req, middleware.GetClientFingerprint(c))
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+	match.Confirmation = h.confirmTokens.Issue(match)
+
+	utils.RespondWithJSON(c, http.StatusCreated, match)
+}
+
+// notify puts a challenge update into the inbox of the other player
+func (h *ChallengeHandler) notify(ctx context.Context, challenge *models.Challenge, recipientID, actorID int, kind string) {
+	player := "A player"
+	if actor, err := h.userRepo.GetByID(ctx, actorID); err == nil {
+		player = actor.DisplayName
+	}
+	sportName := challenge.Sport
+	if sport, err := h.sportService.GetSport(challenge.Sport); err == nil {
+		sportName = sport.DisplayName
+	}
+
+	sport := challenge.Sport
+	h.inbox.Notify(ctx, &models.UserNotification{
+		UserID: recipientID,
+		Kind:   kind,
+		Sport:  &sport,
+		Data: map[string]interface{}{
+			"challenge_id": challenge.ID,
+			"player":       player,
+			"sport_name":   sportName,
+			"scheduled_at": challenge.ScheduledAt,
+		},
+	})
+}
+
+// respondChallengeError maps challenge service errors to HTTP statuses
+func respondChallengeError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "challenge not found":
+		utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+	case "only the challenged player can accept", "only the challenged player can decline", "only the challenger can cancel":
+		utils.RespondWithError(c, http.StatusForbidden, err.Error(), err)
+	case "challenge already exists", "challenge is not pending", "challenge is not open", "challenge is not accepted":
+		utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+	default:
+		if strings.HasPrefix(err.Error(), "failed to") {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to process challenge", err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ChaosHandler manages fault injection rules (admin only, never in production)
+type ChaosHandler struct {
+	injector  *middleware.ChaosInjector
+	adminRepo repositories.AdminStore
+}
+
+// NewChaosHandler creates a new chaos handler
+func NewChaosHandler(injector *middleware.ChaosInjector, adminRepo repositories.AdminStore) *ChaosHandler {
+	return &ChaosHandler{
+		injector:  injector,
+		adminRepo: adminRepo,
+	}
+}
+
+// ListRules returns the active chaos rules of this instance
+// GET /api/admin/chaos/rules
+func (h *ChaosHandler) ListRules(c *gin.Context) {
+	utils.RespondWithJSON(c, http.StatusOK, h.injector.Rules())
+}
+
+// CreateRule injects latency, DB errors or panics into a route
+// POST /api/admin/chaos/rules
+func (h *ChaosHandler) CreateRule(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req models.CreateChaosRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	rule, err := h.injector.AddRule(req, adminID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "create_chaos_rule", "system", &rule.ID, map[string]interface{}{
+		"method":      rule.Method,
+		"route":       rule.Route,
+		"fault":       rule.Fault,
+		"probability": rule.Probability,
+		"expires_at":  rule.ExpiresAt,
+	})
+
+	utils.RespondWithJSON(c, http.StatusCreated, rule)
+}
+
+// DeleteRule removes a chaos rule
+// DELETE /api/admin/chaos/rules/:id
+func (h *ChaosHandler) DeleteRule(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid rule ID", err)
+		return
+	}
+
+	if err := h.injector.RemoveRule(ruleID); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "delete_chaos_rule", "system", &ruleID, nil)
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "rule deleted successfully"})
+}
+
+// ClearRules removes all chaos rules
+// DELETE /api/admin/chaos/rules
+func (h *ChaosHandler) ClearRules(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	removed := h.injector.Clear()
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "clear_chaos_rules", "system", nil, map[string]interface{}{
+		"removed": removed,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"removed": removed})
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CompareHandler serves side-by-side player comparisons
+type CompareHandler struct {
+	userRepo       repositories.UserStore
+	userSportsRepo repositories.UserSportsStore
+	matchRepo      repositories.MatchStore
+	historyRepo    repositories.ELOHistoryStore
+	matchService   *services.MatchService
+	sportService   *services.SportService
+}
+
+// NewCompareHandler creates a new compare handler
+func NewCompareHandler(
+	userRepo repositories.UserStore,
+	userSportsRepo repositories.UserSportsStore,
+	matchRepo repositories.MatchStore,
+	historyRepo repositories.ELOHistoryStore,
+	matchService *services.MatchService,
+	sportService *services.SportService,
+) *CompareHandler {
+	return &CompareHandler{
+		userRepo:       userRepo,
+		userSportsRepo: userSportsRepo,
+		matchRepo:      matchRepo,
+		historyRepo:    historyRepo,
+		matchService:   matchService,
+		sportService:   sportService,
+	}
+}
+
+// ComparePlayers returns standings, head-to-head record, common opponents and
+// overlapping ELO trajectories of two players in one sport
+// GET /api/compare?user_a=&user_b=&sport=
+func (h *CompareHandler) ComparePlayers(c *gin.Context) {
+	userA, errA := strconv.Atoi(c.Query("user_a"))
+	userB, errB := strconv.Atoi(c.Query("user_b"))
+	if errA != nil || errB != nil || utils.ValidateUserID(userA) != nil || utils.ValidateUserID(userB) != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "user_a and user_b must be valid user IDs", nil)
+		return
+	}
+	if userA == userB {
+		utils.RespondWithError(c, http.StatusBadRequest, "cannot compare a player with themselves", nil)
+		return
+	}
+
+	sport := c.Query("sport")
+	if err := h.sportService.ValidateSportID(sport); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+		return
+	}
+
+	users := make([]*models.User, 2)
+	for i, id := range []int{userA, userB} {
+		user, err := h.userRepo.GetByID(c.Request.Context(), id)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+			return
+		}
+		users[i] = user
+	}
+
+	leaderboard, err := h.matchService.GetLeaderboard(c.Request.Context(), sport)
+	if err != nil {
+		respondQueryError(c, "failed to load leaderboard", err)
+		return
+	}
+	// Players still in their placement matches are not on the leaderboard
+	players := make([]models.ComparedPlayer, 2)
+	for i, user := range users {
+		player, ranked := comparedPlayer(leaderboard, user.ID)
+		if !ranked {
+			stats, err := h.userSportsRepo.GetUserSportStats(c.Request.Context(), user.ID, sport)
+			if err != nil {
+				utils.RespondWithError(c, http.StatusInternalServerError, "failed to compare players", err)
+				return
+			}
+			player = unrankedPlayer(user, stats)
+		}
+		players[i] = player
+	}
+
+	h2h, err := h.matchRepo.GetHeadToHead(c.Request.Context(), userA, userB, sport)
+	if err != nil {
+		respondQueryError(c, "failed to compare players", err)
+		return
+	}
+
+	opponents, err := h.matchRepo.GetCommonOpponents(c.Request.Context(), userA, userB, sport)
+	if err != nil {
+		respondQueryError(c, "failed to compare players", err)
+		return
+	}
+
+	historyA, err := h.historyRepo.GetHistory(c.Request.Context(), userA, &sport, nil, nil)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to compare players", err)
+		return
+	}
+	historyB, err := h.historyRepo.GetHistory(c.Request.Context(), userB, &sport, nil, nil)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to compare players", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, models.PlayerComparison{
+		Sport:           sport,
+		PlayerA:         players[0],
+		PlayerB:         players[1],
+		HeadToHead:      *h2h,
+		CommonOpponents: opponents,
+		Trajectory:      overlappingTrajectory(historyA, historyB),
+	})
+}
+
+// comparedPlayer builds one side of a comparison from the ranked leaderboard
+// Reports false if the player is not ranked
+func comparedPlayer(leaderboard []models.LeaderboardEntry, userID int) (models.ComparedPlayer, bool) {
+	var player models.ComparedPlayer
+	ranked := false
+	below := 0
+	for _, entry := range leaderboard {
+		if entry.User.ID == userID {
+			ranked = true
+			player = models.ComparedPlayer{
+				User: models.PlayerSummary{
+					ID:          entry.User.ID,
+					Login:       entry.User.Login,
+					DisplayName: entry.User.DisplayName,
+					AvatarURL:   entry.User.AvatarURL,
+				},
+				ELO:           entry.ELO,
+				Rank:          entry.Rank,
+				MatchesPlayed: entry.MatchesPlayed,
+				Wins:          entry.Wins,
+				Losses:        entry.Losses,
+			}
+			break
+		}
+	}
+	for _, entry := range leaderboard {
+		if entry.ELO < player.ELO {
+			below++
+		}
+	}
+	if others := len(leaderboard) - 1; others > 0 {
+		player.Percentile = math.Round(float64(below)/float64(others)*1000) / 10
+	}
+	return player, ranked
+}
+
+// unrankedPlayer builds one side of a comparison for a player without a rank
+func unrankedPlayer(user *models.User, stats *repositories.UserSportData) models.ComparedPlayer {
+	return models.ComparedPlayer{
+		User: models.PlayerSummary{
+			ID:          user.ID,
+			Login:       user.Login,
+			DisplayName: user.DisplayName,
+			AvatarURL:   user.AvatarURL,
+		},
+		ELO:           stats.CurrentELO,
+		MatchesPlayed: stats.MatchesPlayed,
+		Wins:          stats.Wins,
+		Losses:        stats.Losses,
+	}
+}
+
+// overlappingTrajectory trims both rating series to the period both players were rated
+// Each series starts with the rating held when the overlap began
+func overlappingTrajectory(historyA, historyB []models.ELOHistoryEntry) models.ComparisonTrajectory {
+	trajectory := models.ComparisonTrajectory{PlayerA: []models.ELOPoint{}, PlayerB: []models.ELOPoint{}}
+	if len(historyA) == 0 || len(historyB) == 0 {
+		return trajectory
+	}
+
+	since := historyA[0].CreatedAt
+	if historyB[0].CreatedAt.After(since) {
+		since = historyB[0].CreatedAt
+	}
+
+	trajectory.Since = &since
+	trajectory.PlayerA = trajectorySince(historyA, since)
+	trajectory.PlayerB = trajectorySince(historyB, since)
+	return trajectory
+}
+
+// trajectorySince returns rating points from since onwards, anchored at since
+func trajectorySince(history []models.ELOHistoryEntry, since time.Time) []models.ELOPoint {
+	points := []models.ELOPoint{}
+	start := history[0].ELOBefore
+	for _, entry := range history {
+		if entry.CreatedAt.Before(since) {
+			start = entry.ELOAfter
+			continue
+		}
+		if len(points) == 0 && entry.CreatedAt.After(since) {
+			points = append(points, models.ELOPoint{At: since, ELO: start})
+		}
+		points = append(points, models.ELOPoint{At: entry.CreatedAt, ELO: entry.ELOAfter})
+	}
+	return points
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DigestHandler serves the daily and weekly digests
+type DigestHandler struct {
+	digestRepo   repositories.DigestStore
+	userRepo     repositories.UserStore
+	sportService *services.SportService
+	anonService  *services.AnonymizationService
+}
+
+// NewDigestHandler creates a new digest handler
+func NewDigestHandler(
+	digestRepo repositories.DigestStore,
+	userRepo repositories.UserStore,
+	sportService *services.SportService,
+	anonService *services.AnonymizationService,
+) *DigestHandler {
+	return &DigestHandler{
+		digestRepo:   digestRepo,
+		userRepo:     userRepo,
+		sportService: sportService,
+		anonService:  anonService,
+	}
+}
+
+// GetLatestDigest returns the most recent digest of a sport with the players it mentions
+// Like the leaderboard, players are anonymized for visitors who are not logged in
+// GET /api/digest/:sport/latest?period=daily|weekly
+func (h *DigestHandler) GetLatestDigest(c *gin.Context) {
+	sport := c.Param("sport")
+	if err := h.sportService.ValidateSportID(sport); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+		return
+	}
+
+	period := c.DefaultQuery("period", models.DigestDaily)
+	if period != models.DigestDaily && period != models.DigestWeekly {
+		utils.RespondWithError(c, http.StatusBadRequest, "period must be 'daily' or 'weekly'", nil)
+		return
+	}
+
+	digest, err := h.digestRepo.GetLatest(c.Request.Context(), sport, period)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get digest", err)
+		return
+	}
+	if digest == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "no digest yet", nil)
+		return
+	}
+
+	var ids []int
+	for _, u := range digest.Upsets {
+		ids = append(ids, u.WinnerID, u.LoserID)
+	}
+	for _, a := range digest.MostActive {
+		ids = append(ids, a.UserID)
+	}
+	for _, m := range digest.RankMovers {
+		ids = append(ids, m.UserID)
+	}
+	users, err := h.userRepo.GetByIDs(c.Request.Context(), ids)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get players", err)
+		return
+	}
+
+	var names map[int]string
+	authenticated := middleware.IsAuthenticated(c)
+	if !authenticated {
+		names, err = h.anonService.AnonymousNames(c.Request.Context(), users)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to get anonymous names", err)
+			return
+		}
+	}
+	digest.Players = make(map[int]models.User, len(users))
+	for _, user := range users {
+		if !authenticated {
+			user = maskUserData(user, names[user.ID])
+		}
+		digest.Players[user.ID] = user
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, digest)
+}
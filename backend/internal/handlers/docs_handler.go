@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/docs"
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIVersion pins the Swagger UI assets loaded from the CDN
+const swaggerUIVersion = "5.17.14"
+
+// swaggerUIPage renders the embedded spec; the assets come from unpkg so nothing is vendored
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>ELO Leaderboard API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/docs/openapi.yaml", dom_id: "#swagger-ui", withCredentials: true });
+  </script>
+</body>
+</html>
+`
+
+// DocsHandler serves the OpenAPI spec and a Swagger UI for it
+type DocsHandler struct{}
+
+// NewDocsHandler creates a new docs handler
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+// Spec returns the OpenAPI 3 spec of the API
+// GET /api/docs/openapi.yaml
+func (h *DocsHandler) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", docs.OpenAPI)
+}
+
+// UI serves Swagger UI for the spec
+// GET /api/docs
+func (h *DocsHandler) UI(c *gin.Context) {
+	// The default policy only allows same-origin scripts; Swagger UI is loaded from unpkg
+	c.Header("Content-Security-Policy",
+		"default-src 'self'; "+
+			"script-src 'self' 'unsafe-inline' https://unpkg.com; "+
+			"style-src 'self' 'unsafe-inline' https://unpkg.com; "+
+			"img-src 'self' data:; "+
+			"frame-ancestors 'none'")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
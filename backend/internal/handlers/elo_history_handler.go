@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ELOHistoryHandler serves rating and rank time series for player graphs
+type ELOHistoryHandler struct {
+	historyRepo  repositories.ELOHistoryStore
+	snapshotRepo repositories.SnapshotStore
+	userRepo     repositories.UserStore
+	sportService *services.SportService
+	decayPolicy  models.ELODecayPolicy
+}
+
+// NewELOHistoryHandler creates a new ELO history handler
+func NewELOHistoryHandler(
+	historyRepo repositories.ELOHistoryStore,
+	snapshotRepo repositories.SnapshotStore,
+	userRepo repositories.UserStore,
+	sportService *services.SportService,
+	decayPolicy models.ELODecayPolicy,
+) *ELOHistoryHandler {
+	return &ELOHistoryHandler{
+		historyRepo:  historyRepo,
+		snapshotRepo: snapshotRepo,
+		userRepo:     userRepo,
+		sportService: sportService,
+		decayPolicy:  decayPolicy,
+	}
+}
+
+// GetDecayPolicy returns how the ratings of inactive players decay
+// GET /api/elo/decay-policy
+func (h *ELOHistoryHandler) GetDecayPolicy(c *gin.Context) {
+	utils.RespondWithJSON(c, http.StatusOK, h.decayPolicy)
+}
+
+// GetELOHistory returns a player's ELO changes in chronological order
+// GET /api/users/:id/elo-history?sport=&from=&to=
+// from and to accept RFC 3339 timestamps or YYYY-MM-DD dates; to is exclusive
+func (h *ELOHistoryHandler) GetELOHistory(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || utils.ValidateUserID(userID) != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid user ID", err)
+		return
+	}
+
+	if _, err := h.userRepo.GetByID(c.Request.Context(), userID); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+
+	var sport *string
+	if sportStr := c.Query("sport"); sportStr != "" {
+		if err := h.sportService.ValidateSportID(sportStr); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+			return
+		}
+		sport = &sportStr
+	}
+
+	from, err := parseHistoryTime(c.Query("from"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid from, use RFC 3339 or YYYY-MM-DD", err)
+		return
+	}
+
+	to, err := parseHistoryTime(c.Query("to"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid to, use RFC 3339 or YYYY-MM-DD", err)
+		return
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		utils.RespondWithError(c, http.StatusBadRequest, "from must be before to", nil)
+		return
+	}
+
+	history, err := h.historyRepo.GetHistory(c.Request.Context(), userID, sport, from, to)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get elo history", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, history)
+}
+
+// GetRankHistory returns a player's leaderboard rank from the daily snapshots, oldest first
+// GET /api/users/:id/rank-history?sport=&from=&to=
+// sport is required; from and to are YYYY-MM-DD dates (RFC 3339 is accepted) and to is exclusive
+func (h *ELOHistoryHandler) GetRankHistory(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || utils.ValidateUserID(userID) != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid user ID", err)
+		return
+	}
+
+	if _, err := h.userRepo.GetByID(c.Request.Context(), userID); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+
+	sport := c.Query("sport")
+	if err := h.sportService.ValidateSportID(sport); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+		return
+	}
+
+	from, err := parseHistoryTime(c.Query("from"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid from, use RFC 3339 or YYYY-MM-DD", err)
+		return
+	}
+
+	to, err := parseHistoryTime(c.Query("to"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid to, use RFC 3339 or YYYY-MM-DD", err)
+		return
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		utils.RespondWithError(c, http.StatusBadRequest, "from must be before to", nil)
+		return
+	}
+
+	history, err := h.snapshotRepo.GetUserHistory(c.Request.Context(), userID, sport, from, to)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get rank history", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, history)
+}
+
+// parseHistoryTime parses an optional RFC 3339 timestamp or date
+func parseHistoryTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
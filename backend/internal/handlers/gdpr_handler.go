@@ -4,9 +4,10 @@ import (
 	"database/sql"
 	"log/slog"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
 	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
 	"github.com/42heilbronn/elo-leaderboard/internal/services"
 	"github.com/42heilbronn/elo-leaderboard/internal/utils"
@@ -16,19 +17,25 @@ import (
 // GDPRHandler handles GDPR-related requests (data export, account deletion)
 type GDPRHandler struct {
 	db           *sql.DB
-	userRepo     *repositories.UserRepository
-	matchRepo    *repositories.MatchRepository
-	commentRepo  *repositories.CommentRepository
+	userRepo     repositories.UserStore
+	matchRepo    repositories.MatchStore
+	commentRepo  repositories.CommentStore
 	matchService *services.MatchService
+	dataExports  *services.DataExportService
+	anonService  *services.AnonymizationService
+	deletions    *services.AccountDeletionService
 }
 
 // NewGDPRHandler creates a new GDPR handler
 func NewGDPRHandler(
 	db *sql.DB,
-	userRepo *repositories.UserRepository,
-	matchRepo *repositories.MatchRepository,
-	commentRepo *repositories.CommentRepository,
+	userRepo repositories.UserStore,
+	matchRepo repositories.MatchStore,
+	commentRepo repositories.CommentStore,
 	matchService *services.MatchService,
+	dataExports *services.DataExportService,
+	anonService *services.AnonymizationService,
+	deletions *services.AccountDeletionService,
 ) *GDPRHandler {
 	return &GDPRHandler{
 		db:           db,
@@ -36,72 +43,16 @@ func NewGDPRHandler(
 		matchRepo:    matchRepo,
 		commentRepo:  commentRepo,
 		matchService: matchService,
+		dataExports:  dataExports,
+		anonService:  anonService,
+		deletions:    deletions,
 	}
 }
 
-// UserDataExport represents all data associated with a user (Art. 15 GDPR)
-type UserDataExport struct {
-	ExportDate    string                 `json:"export_date"`
-	ExportVersion string                 `json:"export_version"`
-	Profile       UserProfileExport      `json:"profile"`
-	Matches       []MatchExport          `json:"matches"`
-	Comments      []CommentExport        `json:"comments"`
-	DataInfo      DataProcessingInfo     `json:"data_processing_info"`
-}
-
-// UserProfileExport contains user profile data
-type UserProfileExport struct {
-	ID               int       `json:"id"`
-	IntraID          int       `json:"intra_id"`
-	Login            string    `json:"login"`
-	DisplayName      string    `json:"display_name"`
-	AvatarURL        string    `json:"avatar_url"`
-	Campus           string    `json:"campus"`
-	TableTennisELO   int       `json:"table_tennis_elo"`
-	TableFootballELO int       `json:"table_football_elo"`
-	IsAdmin          bool      `json:"is_admin"`
-	IsBanned         bool      `json:"is_banned"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
-}
-
-// MatchExport contains match data for export
-type MatchExport struct {
-	ID           int        `json:"id"`
-	Sport        string     `json:"sport"`
-	Role         string     `json:"role"` // "player1", "player2", "submitter"
-	OpponentID   int        `json:"opponent_id,omitempty"`
-	PlayerScore  int        `json:"player_score"`
-	OpponentScore int       `json:"opponent_score"`
-	Won          bool       `json:"won"`
-	Status       string     `json:"status"`
-	ELOBefore    *int       `json:"elo_before,omitempty"`
-	ELOAfter     *int       `json:"elo_after,omitempty"`
-	ELODelta     *int       `json:"elo_delta,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
-	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
-}
-
-// CommentExport contains comment data for export
-type CommentExport struct {
-	ID        int       `json:"id"`
-	MatchID   int       `json:"match_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
-
-// DataProcessingInfo provides information about data processing (Art. 13/14 GDPR)
-type DataProcessingInfo struct {
-	Purpose           string   `json:"purpose"`
-	LegalBasis        string   `json:"legal_basis"`
-	RetentionPeriod   string   `json:"retention_period"`
-	ThirdParties      []string `json:"third_parties"`
-	YourRights        []string `json:"your_rights"`
-	ContactEmail      string   `json:"contact_email"`
-}
-
 // ExportUserData handles GET /api/users/me/data-export (Art. 15 GDPR - Right to Access)
+// Kept read-only for existing links, so prefetchers cannot use up the daily export:
+// a downloadable export redirects to its link, a queued one is returned, and otherwise
+// the client is sent to POST /api/users/me/data-exports with 410
 func (h *GDPRHandler) ExportUserData(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -109,330 +60,259 @@ func (h *GDPRHandler) ExportUserData(c *gin.Context) {
 		return
 	}
 
-	// Get user profile
-	user, err := h.userRepo.GetByID(userID)
-	if err != nil {
-		slog.Error("Failed to get user for data export", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to retrieve user data", err)
+	export, err := h.dataExports.GetLatest(c.Request.Context(), userID)
+	if err != nil && err.Error() != "data export not found" {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch data export", err)
 		return
 	}
 
-	// Get user's matches
-	matches, err := h.getMatchesForUser(userID)
-	if err != nil {
-		slog.Error("Failed to get matches for data export", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to retrieve match data", err)
+	switch {
+	case export != nil && export.DownloadURL != "":
+		c.Redirect(http.StatusSeeOther, export.DownloadURL)
+	case export != nil && (export.Status == models.DataExportPending || export.Status == models.DataExportProcessing):
+		c.Header("Location", "/api/users/me/data-exports/latest")
+		utils.RespondWithJSON(c, http.StatusAccepted, export)
+	default:
+		utils.RespondWithError(c, http.StatusGone, "request a data export with POST /api/users/me/data-exports", nil)
+	}
+}
+
+// RequestDataExport queues an export that is built in the background; the user is
+// notified with a time-limited download link once it is ready
+// One export per user and day: an export requested within DataExportInterval, or one that is
+// still downloadable, is returned instead of queuing another
+// POST /api/users/me/data-exports
+func (h *GDPRHandler) RequestDataExport(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
 		return
 	}
 
-	// Get user's comments
-	comments, err := h.getCommentsForUser(userID)
+	export, created, err := h.dataExports.Request(c.Request.Context(), userID)
 	if err != nil {
-		slog.Error("Failed to get comments for data export", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to retrieve comment data", err)
+		slog.ErrorContext(c.Request.Context(), "Failed to request data export", "error", err, "user_id", userID)
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to request data export", err)
 		return
 	}
 
-	export := UserDataExport{
-		ExportDate:    time.Now().UTC().Format(time.RFC3339),
-		ExportVersion: "1.0",
-		Profile: UserProfileExport{
-			ID:               user.ID,
-			IntraID:          user.IntraID,
-			Login:            user.Login,
-			DisplayName:      user.DisplayName,
-			AvatarURL:        user.AvatarURL,
-			Campus:           user.Campus,
-			TableTennisELO:   user.TableTennisELO,
-			TableFootballELO: user.TableFootballELO,
-			IsAdmin:          user.IsAdmin,
-			IsBanned:         user.IsBanned,
-			CreatedAt:        user.CreatedAt,
-			UpdatedAt:        user.UpdatedAt,
-		},
-		Matches:   matches,
-		Comments:  comments,
-		DataInfo: DataProcessingInfo{
-			Purpose:         "ELO Leaderboard ranking system for table tennis and table football at 42 Heilbronn",
-			LegalBasis:      "Art. 6(1)(a) GDPR - Consent, Art. 6(1)(b) GDPR - Contract performance",
-			RetentionPeriod: "Data is retained until account deletion or upon request",
-			ThirdParties: []string{
-				"42 Intra API (authentication)",
-				"Hosting provider (infrastructure)",
-			},
-			YourRights: []string{
-				"Right to access (Art. 15 GDPR)",
-				"Right to rectification (Art. 16 GDPR)",
-				"Right to erasure (Art. 17 GDPR)",
-				"Right to restriction of processing (Art. 18 GDPR)",
-				"Right to data portability (Art. 20 GDPR)",
-				"Right to object (Art. 21 GDPR)",
-			},
-			ContactEmail: "privacy@example.com",
-		},
+	if created {
+		slog.InfoContext(c.Request.Context(), "Data export requested", "user_id", userID, "export_id", export.ID)
 	}
 
-	slog.Info("User data exported", "user_id", userID, "matches", len(matches), "comments", len(comments))
+	if export.Status == models.DataExportReady {
+		utils.RespondWithJSON(c, http.StatusOK, export)
+		return
+	}
 
-	// Set headers for download
-	c.Header("Content-Disposition", "attachment; filename=my-data-export.json")
-	c.Header("Content-Type", "application/json")
-	utils.RespondWithJSON(c, http.StatusOK, export)
+	// Clients poll the latest export until it is ready
+	c.Header("Location", "/api/users/me/data-exports/latest")
+	utils.RespondWithJSON(c, http.StatusAccepted, export)
 }
 
-// DeleteAccount handles DELETE /api/users/me/delete (Art. 17 GDPR - Right to Erasure)
-func (h *GDPRHandler) DeleteAccount(c *gin.Context) {
+// GetDataExport returns the state of the user's latest export, with its download link once ready
+// GET /api/users/me/data-exports/latest
+func (h *GDPRHandler) GetDataExport(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
 		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
 		return
 	}
 
-	// Verify user exists
-	user, err := h.userRepo.GetByID(userID)
+	export, err := h.dataExports.GetLatest(c.Request.Context(), userID)
 	if err != nil {
-		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
-		return
-	}
-
-	slog.Info("Starting account deletion", "user_id", userID, "login", user.Login)
-
-	// Ensure anonymized user exists (id = -1)
-	var anonymizedID int
-	err = h.db.QueryRow("SELECT id FROM users WHERE id = -1").Scan(&anonymizedID)
-	if err == sql.ErrNoRows {
-		// Create it
-		err = h.db.QueryRow(`
-			INSERT INTO users (id, login, display_name, avatar_url, campus, is_banned, ban_reason)
-			VALUES (-1, 'deleted_user', 'Deleted User', '', '42heilbronn', true, 'System account for anonymized data')
-			RETURNING id
-		`).Scan(&anonymizedID)
-		if err != nil {
-			slog.Error("Failed to create anonymized user", "error", err)
-			utils.RespondWithError(c, http.StatusInternalServerError, "failed to prepare deletion", err)
+		if err.Error() == "data export not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
 			return
 		}
-	} else if err != nil {
-		slog.Error("Failed to find anonymized user", "error", err)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to prepare deletion", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch data export", err)
 		return
 	}
 
-	// Start transaction for atomic deletion
-	tx, err := h.db.Begin()
+	utils.RespondWithJSON(c, http.StatusOK, export)
+}
+
+// DownloadDataExport serves a built export archive
+// The signed link is the credential, so it also works outside the SPA (e.g. from a mail client)
+// GET /api/data-exports/:id/download?expires=&signature=
+func (h *GDPRHandler) DownloadDataExport(c *gin.Context) {
+	exportID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		slog.Error("Failed to begin transaction for account deletion", "error", err)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to process deletion", err)
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid data export ID", err)
 		return
 	}
-	defer tx.Rollback()
 
-	// 1. Delete all comments by this user
-	_, err = tx.Exec("DELETE FROM comments WHERE user_id = $1", userID)
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
 	if err != nil {
-		slog.Error("Failed to delete comments", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete comments", err)
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid download link", err)
 		return
 	}
 
-	// 2. Delete all reactions by this user
-	_, err = tx.Exec("DELETE FROM reactions WHERE user_id = $1", userID)
+	archive, err := h.dataExports.Download(c.Request.Context(), exportID, expires, c.Query("signature"))
 	if err != nil {
-		slog.Error("Failed to delete reactions", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete reactions", err)
+		switch err.Error() {
+		case "invalid download link":
+			utils.RespondWithError(c, http.StatusForbidden, err.Error(), err)
+		case "download link expired", "data export not found":
+			utils.RespondWithError(c, http.StatusGone, "download link expired", err)
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch data export", err)
+		}
 		return
 	}
 
-	// 3. Anonymize matches where user is player1, player2, winner, or submitter
-	// We keep match history but remove personal data linkage
-	// Note: Must update player IDs and winner_id together to satisfy the
-	// valid_winner CHECK constraint (winner_id = player1_id OR winner_id = player2_id)
-	_, err = tx.Exec(`
-		UPDATE matches SET
-			player1_id = CASE WHEN player1_id = $2 THEN $1 ELSE player1_id END,
-			player2_id = CASE WHEN player2_id = $2 THEN $1 ELSE player2_id END,
-			winner_id = CASE WHEN winner_id = $2 THEN $1 ELSE winner_id END,
-			submitted_by = CASE WHEN submitted_by = $2 THEN $1 ELSE submitted_by END
-		WHERE player1_id = $2 OR player2_id = $2 OR winner_id = $2 OR submitted_by = $2
-	`, anonymizedID, userID)
-	if err != nil {
-		slog.Error("Failed to anonymize matches", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to anonymize matches", err)
+	c.Header("Content-Disposition", "attachment; filename=my-data-export.zip")
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// EraseData erases parts of the user's data while the account stays active (Art. 17 GDPR)
+// The selected scopes are erased in one transaction
+// POST /api/users/me/erase
+func (h *GDPRHandler) EraseData(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
 		return
 	}
 
-	// 4. Anonymize ELO adjustments made by this user (adjusted_by foreign key)
-	_, err = tx.Exec("UPDATE elo_adjustments SET adjusted_by = $1 WHERE adjusted_by = $2", anonymizedID, userID)
-	if err != nil {
-		slog.Error("Failed to anonymize ELO adjustments", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to anonymize elo adjustments", err)
+	var req models.PartialErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
 		return
 	}
 
-	// 5. Clear banned_by references (users banned by this user)
-	_, err = tx.Exec("UPDATE users SET banned_by = NULL WHERE banned_by = $1", userID)
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
-		slog.Error("Failed to clear banned_by references", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to clear ban references", err)
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
 		return
 	}
 
-	// 6. Delete audit log entries where this user was the admin (admin_id foreign key)
-	_, err = tx.Exec("DELETE FROM admin_audit_log WHERE admin_id = $1", userID)
-	if err != nil {
-		slog.Error("Failed to delete admin audit log entries", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete audit entries", err)
-		return
+	scopes := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scopes[scope] = true
 	}
 
-	// 7. Delete audit log entries related to this user (admin actions on this user)
-	_, err = tx.Exec("DELETE FROM admin_audit_log WHERE target_type = 'user' AND target_id = $1", userID)
-	if err != nil {
-		slog.Error("Failed to delete audit log entries targeting user", "error", err, "user_id", userID)
-		// Non-critical, continue
+	// The replacement display name is the user's existing leaderboard alias
+	var anonymousName string
+	if scopes[models.ErasureDisplayData] {
+		anonymousName, err = h.anonService.AssignName(c.Request.Context(), *user)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to get anonymous name", err)
+			return
+		}
 	}
 
-	// 8. Delete the user account
-	_, err = tx.Exec("DELETE FROM users WHERE id = $1", userID)
+	slog.InfoContext(c.Request.Context(), "Starting partial data erasure", "user_id", userID, "scopes", req.Scopes)
+
+	tx, err := h.db.BeginTx(c.Request.Context(), nil)
 	if err != nil {
-		slog.Error("Failed to delete user", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete user account", err)
+		slog.ErrorContext(c.Request.Context(), "Failed to begin transaction for partial erasure", "error", err)
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to process erasure", err)
 		return
 	}
+	defer tx.Rollback()
+
+	erased := gin.H{}
+
+	if scopes[models.ErasureComments] {
+		result, err := tx.ExecContext(c.Request.Context(), "DELETE FROM comments WHERE user_id = $1", userID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete comments", "error", err, "user_id", userID)
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete comments", err)
+			return
+		}
+		deleted, _ := result.RowsAffected()
+		erased["comments_deleted"] = deleted
+	}
+
+	if scopes[models.ErasureReactions] {
+		result, err := tx.ExecContext(c.Request.Context(), "DELETE FROM reactions WHERE user_id = $1", userID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete reactions", "error", err, "user_id", userID)
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete reactions", err)
+			return
+		}
+		deleted, _ := result.RowsAffected()
+		erased["reactions_deleted"] = deleted
+	}
+
+	if scopes[models.ErasureDisplayData] {
+		// The login sync skips users with erased display data, see UserRepository.CreateOrUpdate
+		_, err := tx.ExecContext(c.Request.Context(), `
+			UPDATE users SET display_name = $1, avatar_url = '', display_data_erased_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+		`, anonymousName, userID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to anonymize display data", "error", err, "user_id", userID)
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to anonymize display data", err)
+			return
+		}
+		erased["display_name"] = anonymousName
+	}
 
-	// Commit transaction
 	if err := tx.Commit(); err != nil {
-		slog.Error("Failed to commit account deletion", "error", err, "user_id", userID)
-		utils.RespondWithError(c, http.StatusInternalServerError, "failed to complete deletion", err)
+		slog.ErrorContext(c.Request.Context(), "Failed to commit partial erasure", "error", err, "user_id", userID)
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to complete erasure", err)
 		return
 	}
 
-	// Invalidate caches
-	h.matchService.InvalidateLeaderboardCache()
+	if scopes[models.ErasureDisplayData] {
+		h.matchService.InvalidateLeaderboardCache()
+	}
 
-	slog.Info("Account deleted successfully", "user_id", userID, "login", user.Login)
+	slog.InfoContext(c.Request.Context(), "Partial data erasure completed", "user_id", userID, "scopes", req.Scopes, "erased", erased)
 
 	utils.RespondWithJSON(c, http.StatusOK, gin.H{
-		"message": "Your account and associated data have been deleted",
-		"deleted": gin.H{
-			"user_id":            userID,
-			"comments_deleted":   true,
-			"matches_anonymized": true,
-		},
+		"message": "The selected data has been erased; your account remains active",
+		"erased":  erased,
 	})
 }
 
-// Helper methods
-
-func (h *GDPRHandler) getMatchesForUser(userID int) ([]MatchExport, error) {
-	query := `
-		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
-		       winner_id, status, player1_elo_before, player1_elo_after, player1_elo_delta,
-		       player2_elo_before, player2_elo_after, player2_elo_delta,
-		       submitted_by, created_at, confirmed_at
-		FROM matches
-		WHERE player1_id = $1 OR player2_id = $1 OR submitted_by = $1
-		ORDER BY created_at DESC
-	`
-
-	rows, err := h.db.Query(query, userID)
-	if err != nil {
-		return nil, err
+// DeleteAccount handles DELETE /api/users/me/delete (Art. 17 GDPR - Right to Erasure)
+// The account is erased after a grace period in which the deletion can be cancelled
+func (h *GDPRHandler) DeleteAccount(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
 	}
-	defer rows.Close()
-
-	var matches []MatchExport
-	for rows.Next() {
-		var m struct {
-			ID              int
-			Sport           string
-			Player1ID       int
-			Player2ID       int
-			Player1Score    int
-			Player2Score    int
-			WinnerID        int
-			Status          string
-			Player1ELOBefore *int
-			Player1ELOAfter  *int
-			Player1ELODelta  *int
-			Player2ELOBefore *int
-			Player2ELOAfter  *int
-			Player2ELODelta  *int
-			SubmittedBy     int
-			CreatedAt       time.Time
-			ConfirmedAt     *time.Time
-		}
-
-		if err := rows.Scan(
-			&m.ID, &m.Sport, &m.Player1ID, &m.Player2ID, &m.Player1Score, &m.Player2Score,
-			&m.WinnerID, &m.Status, &m.Player1ELOBefore, &m.Player1ELOAfter, &m.Player1ELODelta,
-			&m.Player2ELOBefore, &m.Player2ELOAfter, &m.Player2ELODelta,
-			&m.SubmittedBy, &m.CreatedAt, &m.ConfirmedAt,
-		); err != nil {
-			return nil, err
-		}
-
-		export := MatchExport{
-			ID:          m.ID,
-			Sport:       m.Sport,
-			Status:      m.Status,
-			CreatedAt:   m.CreatedAt,
-			ConfirmedAt: m.ConfirmedAt,
-		}
 
-		// Determine role and set appropriate fields
-		if m.Player1ID == userID {
-			export.Role = "player1"
-			export.OpponentID = m.Player2ID
-			export.PlayerScore = m.Player1Score
-			export.OpponentScore = m.Player2Score
-			export.Won = m.WinnerID == userID
-			export.ELOBefore = m.Player1ELOBefore
-			export.ELOAfter = m.Player1ELOAfter
-			export.ELODelta = m.Player1ELODelta
-		} else if m.Player2ID == userID {
-			export.Role = "player2"
-			export.OpponentID = m.Player1ID
-			export.PlayerScore = m.Player2Score
-			export.OpponentScore = m.Player1Score
-			export.Won = m.WinnerID == userID
-			export.ELOBefore = m.Player2ELOBefore
-			export.ELOAfter = m.Player2ELOAfter
-			export.ELODelta = m.Player2ELODelta
-		} else if m.SubmittedBy == userID {
-			export.Role = "submitter"
-			export.PlayerScore = m.Player1Score
-			export.OpponentScore = m.Player2Score
+	request, created, err := h.deletions.Request(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+			return
 		}
+		slog.ErrorContext(c.Request.Context(), "Failed to schedule account deletion", "error", err, "user_id", userID)
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to schedule deletion", err)
+		return
+	}
 
-		matches = append(matches, export)
+	if created {
+		slog.InfoContext(c.Request.Context(), "Account deletion scheduled", "user_id", userID, "erase_after", request.EraseAfter)
 	}
 
-	return matches, rows.Err()
+	utils.RespondWithJSON(c, http.StatusAccepted, gin.H{
+		"message":     "Your account is scheduled for deletion; you can cancel until then",
+		"erase_after": request.EraseAfter,
+	})
 }
 
-func (h *GDPRHandler) getCommentsForUser(userID int) ([]CommentExport, error) {
-	query := `
-		SELECT id, match_id, content, created_at, updated_at
-		FROM comments
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-	`
+// CancelDeletion handles POST /api/users/me/delete/cancel
+func (h *GDPRHandler) CancelDeletion(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
 
-	rows, err := h.db.Query(query, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var comments []CommentExport
-	for rows.Next() {
-		var c CommentExport
-		if err := rows.Scan(&c.ID, &c.MatchID, &c.Content, &c.CreatedAt, &c.UpdatedAt); err != nil {
-			return nil, err
+	if err := h.deletions.Cancel(c.Request.Context(), userID); err != nil {
+		if err.Error() == "no account deletion scheduled" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
 		}
-		comments = append(comments, c)
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to cancel deletion", err)
+		return
 	}
 
-	return comments, rows.Err()
+	slog.InfoContext(c.Request.Context(), "Account deletion cancelled", "user_id", userID)
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "Your account deletion has been cancelled"})
 }
@@ -7,19 +7,25 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/42heilbronn/elo-leaderboard/internal/jobs"
 	"github.com/gin-gonic/gin"
 )
 
 // HealthHandler handles health check endpoints
 type HealthHandler struct {
 	db        *sql.DB
+	replica   *sql.DB
+	scheduler *jobs.Scheduler
 	startTime time.Time
 }
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler(db *sql.DB) *HealthHandler {
+// replica and scheduler may be nil when there is no read replica or background jobs are disabled
+func NewHealthHandler(db, replica *sql.DB, scheduler *jobs.Scheduler) *HealthHandler {
 	return &HealthHandler{
 		db:        db,
+		replica:   replica,
+		scheduler: scheduler,
 		startTime: time.Now(),
 	}
 }
@@ -69,7 +75,7 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 	overallStatus := StatusHealthy
 
 	// Check database
-	dbCheck := h.checkDatabase(ctx)
+	dbCheck := h.checkDatabase(ctx, h.db)
 	checks["database"] = dbCheck
 	if dbCheck.Status != StatusHealthy {
 		overallStatus = StatusUnhealthy
@@ -98,12 +104,21 @@ func (h *HealthHandler) Health(c *gin.Context) {
 	overallStatus := StatusHealthy
 
 	// Check database
-	dbCheck := h.checkDatabase(ctx)
+	dbCheck := h.checkDatabase(ctx, h.db)
 	checks["database"] = dbCheck
 	if dbCheck.Status != StatusHealthy {
 		overallStatus = StatusUnhealthy
 	}
 
+	// A failing replica breaks the leaderboard and statistics, but not match submission
+	if h.replica != nil {
+		replicaCheck := h.checkDatabase(ctx, h.replica)
+		checks["database_replica"] = replicaCheck
+		if replicaCheck.Status != StatusHealthy && overallStatus == StatusHealthy {
+			overallStatus = StatusDegraded
+		}
+	}
+
 	// Check database connection pool
 	poolCheck := h.checkConnectionPool()
 	checks["connection_pool"] = poolCheck
@@ -127,6 +142,15 @@ func (h *HealthHandler) Health(c *gin.Context) {
 		overallStatus = StatusDegraded
 	}
 
+	// Check background jobs
+	if h.scheduler != nil {
+		jobsCheck := h.checkJobs()
+		checks["jobs"] = jobsCheck
+		if jobsCheck.Status == StatusDegraded && overallStatus == StatusHealthy {
+			overallStatus = StatusDegraded
+		}
+	}
+
 	statusCode := http.StatusOK
 	if overallStatus == StatusUnhealthy {
 		statusCode = http.StatusServiceUnavailable
@@ -141,10 +165,10 @@ func (h *HealthHandler) Health(c *gin.Context) {
 }
 
 // checkDatabase checks database connectivity
-func (h *HealthHandler) checkDatabase(ctx context.Context) CheckResult {
+func (h *HealthHandler) checkDatabase(ctx context.Context, db *sql.DB) CheckResult {
 	start := time.Now()
 
-	err := h.db.PingContext(ctx)
+	err := db.PingContext(ctx)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -160,7 +184,7 @@ func (h *HealthHandler) checkDatabase(ctx context.Context) CheckResult {
 
 	// Check if we can execute a simple query
 	var result int
-	err = h.db.QueryRowContext(ctx, "SELECT 1").Scan(&result)
+	err = db.QueryRowContext(ctx, "SELECT 1").Scan(&result)
 	queryDuration := time.Since(start)
 
 	if err != nil {
@@ -288,3 +312,41 @@ func (h *HealthHandler) checkGoroutines() CheckResult {
 		},
 	}
 }
+
+// checkJobs reports the last run of each background job
+// Overdue or failing jobs degrade the service but never make it unhealthy
+func (h *HealthHandler) checkJobs() CheckResult {
+	statuses := h.scheduler.Statuses()
+
+	status := StatusHealthy
+	message := "All background jobs are running on schedule"
+
+	var overdue, failing []string
+	for _, st := range statuses {
+		if st.Overdue {
+			overdue = append(overdue, st.Name)
+		}
+		if st.LastError != "" {
+			failing = append(failing, st.Name)
+		}
+	}
+
+	if len(overdue) > 0 {
+		status = StatusDegraded
+		message = "Background jobs have not run within their expected interval"
+	} else if len(failing) > 0 {
+		status = StatusDegraded
+		message = "Background jobs failed on their last run"
+	}
+
+	return CheckResult{
+		Status:   status,
+		Message:  message,
+		Duration: 0,
+		Details: map[string]interface{}{
+			"jobs":    statuses,
+			"overdue": overdue,
+			"failing": failing,
+		},
+	}
+}
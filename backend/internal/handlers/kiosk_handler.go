@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/config"
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/revocation"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// KioskHandler starts and ends the short sessions with which a table-side kiosk acts for a student
+// The kiosk itself is authenticated by its KIOSK_API_KEYS key before these handlers run
+type KioskHandler struct {
+	userRepo repositories.UserStore
+	denyList *revocation.DenyList
+	cfg      *config.Config
+}
+
+// NewKioskHandler creates a new kiosk handler
+func NewKioskHandler(userRepo repositories.UserStore, denyList *revocation.DenyList, cfg *config.Config) *KioskHandler {
+	return &KioskHandler{
+		userRepo: userRepo,
+		denyList: denyList,
+		cfg:      cfg,
+	}
+}
+
+// CreateSession issues a kiosk session for the student with the given intra login
+// The session can only submit matches; the opponent still confirms from their own account
+// POST /api/kiosk/session
+func (h *KioskHandler) CreateSession(c *gin.Context) {
+	var req models.KioskSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	user, err := h.userRepo.GetByLogin(c.Request.Context(), strings.ToLower(strings.TrimSpace(req.Login)))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.RespondWithError(c, http.StatusNotFound, "no player with this login; log in on the website once first", nil)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch user", err)
+		return
+	}
+	if user.Banned() {
+		utils.RespondWithError(c, http.StatusForbidden, "your account has been banned", nil)
+		return
+	}
+	if user.DeactivatedAt != nil {
+		utils.RespondWithError(c, http.StatusForbidden, "account is deactivated; log in on the website to reactivate it", nil)
+		return
+	}
+
+	kiosk := middleware.GetKiosk(c)
+	token, expiresAt, err := utils.GenerateKioskJWT(user.ID, kiosk, h.cfg.JWTSecret)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to start kiosk session", err)
+		return
+	}
+	slog.InfoContext(c.Request.Context(), "Kiosk session started", "kiosk", kiosk, "user_id", user.ID, "login", user.Login)
+
+	utils.RespondWithJSON(c, http.StatusCreated, models.KioskSession{
+		Token:       token,
+		ExpiresAt:   expiresAt,
+		UserID:      user.ID,
+		Login:       user.Login,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+	})
+}
+
+// EndSession revokes the presented kiosk session, e.g. when the students walk away
+// DELETE /api/kiosk/session
+func (h *KioskHandler) EndSession(c *gin.Context) {
+	token := middleware.TokenFromRequest(c)
+	claims, err := utils.ValidateKioskJWT(token, middleware.GetKiosk(c), h.cfg.JWTSecret)
+	// Expired or foreign sessions need no revocation
+	if err == nil && h.denyList != nil {
+		if err := h.denyList.RevokeToken(c.Request.Context(), claims); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to end kiosk session", err)
+			return
+		}
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "kiosk session ended"})
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LegalHandler serves versioned legal documents, records which versions users accepted
+// and lets admins publish new versions
+type LegalHandler struct {
+	legalRepo repositories.LegalStore
+	adminRepo repositories.AdminStore
+	userRepo  repositories.UserStore
+}
+
+// NewLegalHandler creates a new legal document handler
+func NewLegalHandler(legalRepo repositories.LegalStore, adminRepo repositories.AdminStore, userRepo repositories.UserStore) *LegalHandler {
+	return &LegalHandler{
+		legalRepo: legalRepo,
+		adminRepo: adminRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// GetDocument returns the latest version of a legal document, or a specific one with ?version=
+// Without a version in the requested language, the German original is served
+// GET /api/legal/:doc?lang=en&version=3
+func (h *LegalHandler) GetDocument(c *gin.Context) {
+	doc, lang, ok := parseLegalParams(c)
+	if !ok {
+		return
+	}
+
+	if versionStr := c.Query("version"); versionStr != "" {
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version < 1 {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid version", err)
+			return
+		}
+
+		document, err := h.legalRepo.GetVersion(c.Request.Context(), doc, lang, version)
+		if err != nil {
+			if err.Error() == "document not found" {
+				utils.RespondWithError(c, http.StatusNotFound, err.Error(), nil)
+				return
+			}
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to get document", err)
+			return
+		}
+
+		// Published versions never change
+		c.Header("Cache-Control", "public, max-age=86400, immutable")
+		c.Header("Content-Language", document.Lang)
+		utils.RespondWithJSON(c, http.StatusOK, document)
+		return
+	}
+
+	document, err := h.legalRepo.GetLatest(c.Request.Context(), doc, lang)
+	if err != nil && err.Error() == "document not found" && lang != models.DefaultLegalLang {
+		document, err = h.legalRepo.GetLatest(c.Request.Context(), doc, models.DefaultLegalLang)
+	}
+	if err != nil {
+		if err.Error() == "document not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get document", err)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Header("Content-Language", document.Lang)
+	utils.RespondWithJSON(c, http.StatusOK, document)
+}
+
+// ListVersions returns the version history of a legal document without content
+// GET /api/legal/:doc/versions?lang=de
+func (h *LegalHandler) ListVersions(c *gin.Context) {
+	doc, lang, ok := parseLegalParams(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.legalRepo.ListVersions(c.Request.Context(), doc, lang)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to list versions", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, versions)
+}
+
+// AcceptDocument records that the current user accepted the current version of a document
+// in a language; a stale version (a newer one was published meanwhile) is rejected
+// POST /api/legal/:doc/accept
+func (h *LegalHandler) AcceptDocument(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	doc := c.Param("doc")
+	if !isLegalDocument(doc) {
+		utils.RespondWithError(c, http.StatusNotFound, "document not found", nil)
+		return
+	}
+	if !requiresAcceptance(doc) {
+		utils.RespondWithError(c, http.StatusBadRequest, "document does not need to be accepted", nil)
+		return
+	}
+
+	var req models.AcceptLegalDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	current, err := h.legalRepo.GetLatest(c.Request.Context(), doc, req.Lang)
+	if err != nil {
+		if err.Error() == "document not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get document", err)
+		return
+	}
+	if current.Version != req.Version {
+		utils.RespondWithError(c, http.StatusConflict, "a newer version has been published, please review it", nil)
+		return
+	}
+
+	acceptance, err := h.legalRepo.Accept(c.Request.Context(), userID, current)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to record acceptance", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, acceptance)
+}
+
+// GetMyLegalStatus lists the versions the current user accepted and the current versions of
+// the documents that still have to be accepted, in the user's notification language
+// GET /api/users/me/legal
+func (h *LegalHandler) GetMyLegalStatus(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	lang, err := h.userRepo.GetLanguage(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+
+	pending, err := h.legalRepo.ListPending(c.Request.Context(), userID, lang, models.LegalAcceptanceRequired)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to list pending documents", err)
+		return
+	}
+	accepted, err := h.legalRepo.ListAcceptances(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to list acceptances", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, models.LegalStatus{Pending: pending, Accepted: accepted})
+}
+
+// PublishDocument publishes a new version of a legal document
+// POST /api/admin/legal/:doc
+func (h *LegalHandler) PublishDocument(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	doc := c.Param("doc")
+	if !isLegalDocument(doc) {
+		utils.RespondWithError(c, http.StatusNotFound, "document not found", nil)
+		return
+	}
+
+	var req models.PublishLegalDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	document := &models.LegalDocument{
+		Doc:         doc,
+		Lang:        req.Lang,
+		Title:       strings.TrimSpace(req.Title),
+		Content:     req.Content,
+		PublishedBy: &adminID,
+	}
+	if err := h.legalRepo.Publish(c.Request.Context(), document); err != nil {
+		if err.Error() == "version conflict, please retry" {
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to publish document", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "publish_legal_document", "system", &document.ID, map[string]interface{}{
+		"doc":     document.Doc,
+		"lang":    document.Lang,
+		"version": document.Version,
+	})
+
+	utils.RespondWithJSON(c, http.StatusCreated, document)
+}
+
+// parseLegalParams validates the document name and the ?lang= parameter
+func parseLegalParams(c *gin.Context) (string, string, bool) {
+	doc := c.Param("doc")
+	if !isLegalDocument(doc) {
+		utils.RespondWithError(c, http.StatusNotFound, "document not found", nil)
+		return "", "", false
+	}
+
+	lang := strings.ToLower(c.DefaultQuery("lang", models.DefaultLegalLang))
+	if len(lang) != 2 || strings.Trim(lang, "abcdefghijklmnopqrstuvwxyz") != "" {
+		utils.RespondWithError(c, http.StatusBadRequest, "lang must be a two-letter language code", nil)
+		return "", "", false
+	}
+
+	return doc, lang, true
+}
+
+// isLegalDocument reports whether doc names a known legal document
+func isLegalDocument(doc string) bool {
+	switch doc {
+	case models.LegalImpressum, models.LegalDatenschutz, models.LegalNutzungsbedingungen:
+		return true
+	}
+	return false
+}
+
+// requiresAcceptance reports whether users have to accept doc
+func requiresAcceptance(doc string) bool {
+	for _, required := range models.LegalAcceptanceRequired {
+		if doc == required {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,22 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/42heilbronn/elo-leaderboard/internal/cache"
 	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
 	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+	"github.com/42heilbronn/elo-leaderboard/internal/realtime"
 	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
 	"github.com/42heilbronn/elo-leaderboard/internal/services"
 	"github.com/42heilbronn/elo-leaderboard/internal/utils"
@@ -14,20 +24,53 @@ import (
 )
 
 type MatchHandler struct {
-	matchService *services.MatchService
-	matchRepo    *repositories.MatchRepository
-	commentRepo  *repositories.CommentRepository
+	matchService  *services.MatchService
+	matchRepo     repositories.MatchStore
+	commentRepo   repositories.CommentStore
+	anonService   *services.AnonymizationService
+	reactionRepo  repositories.ReactionStore
+	hub           *realtime.Hub
+	seasonService *services.SeasonService
+	podiumCache   cache.Cache // Serialized top-N responses for frequently polling widgets
+	activity      *services.ActivityMonitor
+	confirmTokens *services.ConfirmationTokenService
+	userRepo      repositories.UserStore
+	inbox         *notifications.Inbox
+	sportService  *services.SportService
 }
 
+// maxPodiumSize is the largest n accepted by the top-N endpoint
+const maxPodiumSize = 10
+
 func NewMatchHandler(
 	matchService *services.MatchService,
-	matchRepo *repositories.MatchRepository,
-	commentRepo *repositories.CommentRepository,
+	matchRepo repositories.MatchStore,
+	commentRepo repositories.CommentStore,
+	anonService *services.AnonymizationService,
+	reactionRepo repositories.ReactionStore,
+	hub *realtime.Hub,
+	seasonService *services.SeasonService,
+	podiumCache cache.Cache,
+	activity *services.ActivityMonitor,
+	confirmTokens *services.ConfirmationTokenService,
+	userRepo repositories.UserStore,
+	inbox *notifications.Inbox,
+	sportService *services.SportService,
 ) *MatchHandler {
 	return &MatchHandler{
-		matchService: matchService,
-		matchRepo:    matchRepo,
-		commentRepo:  commentRepo,
+		matchService:  matchService,
+		matchRepo:     matchRepo,
+		commentRepo:   commentRepo,
+		anonService:   anonService,
+		reactionRepo:  reactionRepo,
+		hub:           hub,
+		seasonService: seasonService,
+		podiumCache:   podiumCache,
+		activity:      activity,
+		confirmTokens: confirmTokens,
+		userRepo:      userRepo,
+		inbox:         inbox,
+		sportService:  sportService,
 	}
 }
 
@@ -45,17 +88,54 @@ func (h *MatchHandler) SubmitMatch(c *gin.Context) {
 		return
 	}
 
+	// A best-of-N series is scored by the sets each player won
+	if err := utils.ScoreSeries(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
 	// Explicit validation beyond struct tags
 	if err := utils.ValidateMatchSubmission(req.Sport, req.OpponentID, req.PlayerScore, req.OpponentScore, userID); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
 		return
 	}
 
-	match, err := h.matchService.SubmitMatch(&req, userID)
+	match, err := h.matchService.SubmitMatch(c.Request.Context(), &req, userID, middleware.GetClientFingerprint(c))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	match.Confirmation = h.confirmTokens.Issue(match)
+
+	utils.RespondWithJSON(c, http.StatusCreated, match)
+}
+
+// SubmitForfeit reports a forfeit instead of a played match; the opponent confirms it like a match
+// POST /api/matches/forfeit
+func (h *MatchHandler) SubmitForfeit(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	var req models.SubmitForfeitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if err := utils.ValidateUserID(req.OpponentID); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	match, err := h.matchService.SubmitForfeit(c.Request.Context(), &req, userID, middleware.GetClientFingerprint(c))
 	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
 		return
 	}
+	match.Confirmation = h.confirmTokens.Issue(match)
 
 	utils.RespondWithJSON(c, http.StatusCreated, match)
 }
@@ -74,14 +154,115 @@ func (h *MatchHandler) ConfirmMatch(c *gin.Context) {
 		return
 	}
 
-	if err := h.matchService.ConfirmMatch(matchID, userID); err != nil {
-		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+	if err := h.matchService.ConfirmMatch(c.Request.Context(), matchID, userID, middleware.GetClientFingerprint(c)); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, repositories.ErrMatchStatusChanged) {
+			status = http.StatusConflict
+		}
+		utils.RespondWithError(c, status, err.Error(), err)
 		return
 	}
 
 	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "match confirmed"})
 }
 
+// GetMatchByConfirmToken shows the match behind a QR code or deep link without confirming it
+// The confirmation page calls this first so the opponent sees the score they are about to accept
+// GET /api/matches/confirm?token=
+func (h *MatchHandler) GetMatchByConfirmToken(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	match, ok := h.validateConfirmToken(c, c.Query("token"), userID)
+	if !ok {
+		return
+	}
+
+	sets, err := h.matchRepo.GetSets(c.Request.Context(), match.ID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get match sets", err)
+		return
+	}
+	match.Sets = sets
+
+	submitter, err := h.userRepo.GetByID(c.Request.Context(), match.SubmittedBy)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch submitter", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, models.MatchConfirmationPreview{
+		Match: match,
+		Submitter: models.PlayerSummary{
+			ID:          submitter.ID,
+			Login:       submitter.Login,
+			DisplayName: submitter.DisplayName,
+			AvatarURL:   submitter.AvatarURL,
+		},
+	})
+}
+
+// ConfirmMatchByToken confirms a match from the QR code or deep link shown to the submitter
+// The token only works for the opponent and only while the match is unchanged
+// POST /api/matches/confirm
+func (h *MatchHandler) ConfirmMatchByToken(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	var req models.ConfirmMatchByTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "token is required", err)
+		return
+	}
+
+	match, ok := h.validateConfirmToken(c, req.Token, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.matchService.ConfirmMatch(c.Request.Context(), match.ID, userID, middleware.GetClientFingerprint(c)); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, repositories.ErrMatchStatusChanged) {
+			status = http.StatusConflict
+		}
+		utils.RespondWithError(c, status, err.Error(), err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "match confirmed", "match_id": match.ID})
+}
+
+// validateConfirmToken returns the match a confirmation token presented by userID is for
+// On failure the error response has been written
+func (h *MatchHandler) validateConfirmToken(c *gin.Context, token string, userID int) (*models.Match, bool) {
+	if token == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, "token is required", nil)
+		return nil, false
+	}
+
+	match, err := h.confirmTokens.Validate(c.Request.Context(), token, userID)
+	if err != nil {
+		switch err.Error() {
+		case "invalid confirmation token":
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		case "confirmation token belongs to another player":
+			utils.RespondWithError(c, http.StatusForbidden, err.Error(), err)
+		case "confirmation token expired", "confirmation token already used", "match not found":
+			utils.RespondWithError(c, http.StatusGone, err.Error(), err)
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to validate confirmation token", err)
+		}
+		return nil, false
+	}
+	return match, true
+}
+
 // DenyMatch handles match denial
 func (h *MatchHandler) DenyMatch(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
@@ -96,14 +277,103 @@ func (h *MatchHandler) DenyMatch(c *gin.Context) {
 		return
 	}
 
-	if err := h.matchService.DenyMatch(matchID, userID); err != nil {
+	// The body is optional; it carries a counter-proposal with the corrected score
+	var req models.DenyMatchRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	if err := h.matchService.DenyMatch(c.Request.Context(), matchID, userID, &req); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
 		return
 	}
 
+	if req.PlayerScore != nil {
+		utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "match denied with counter-proposal"})
+		return
+	}
+
 	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "match denied"})
 }
 
+// GetCounterProposal returns the corrected score proposed when the match was denied
+func (h *MatchHandler) GetCounterProposal(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	proposal, err := h.matchService.GetCounterProposal(c.Request.Context(), matchID, userID)
+	if err != nil {
+		if err.Error() == "counter-proposal not found" || err.Error() == "match not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusForbidden, err.Error(), err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, proposal)
+}
+
+// AcceptCounterProposal confirms a denied match with the opponent's corrected score
+func (h *MatchHandler) AcceptCounterProposal(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	if err := h.matchService.AcceptCounterProposal(c.Request.Context(), matchID, userID, middleware.GetClientFingerprint(c)); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, repositories.ErrMatchStatusChanged) {
+			status = http.StatusConflict
+		}
+		utils.RespondWithError(c, status, err.Error(), err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "counter-proposal accepted, match confirmed"})
+}
+
+// RejectCounterProposal rejects the opponent's corrected score
+func (h *MatchHandler) RejectCounterProposal(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	if err := h.matchService.RejectCounterProposal(c.Request.Context(), matchID, userID); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "counter-proposal rejected"})
+}
+
 // CancelMatch handles match cancellation by the submitter
 func (h *MatchHandler) CancelMatch(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
@@ -118,7 +388,7 @@ func (h *MatchHandler) CancelMatch(c *gin.Context) {
 		return
 	}
 
-	if err := h.matchService.CancelMatch(matchID, userID); err != nil {
+	if err := h.matchService.CancelMatch(c.Request.Context(), matchID, userID); err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
 		return
 	}
@@ -126,11 +396,14 @@ func (h *MatchHandler) CancelMatch(c *gin.Context) {
 	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "match cancelled"})
 }
 
-// GetMatches lists matches with filters
+// GetMatches lists matches with filters, newest first
+// Paginated with ?cursor= (next_cursor of the previous page); ?offset= is deprecated
+// Responds with CSV for Accept: text/csv or ?format=csv, with the cursor in X-Next-Cursor
 func (h *MatchHandler) GetMatches(c *gin.Context) {
 	var userID *int
 	var sport *string
 	var status *string
+	var cursor *models.MatchCursor
 
 	if userIDStr := c.Query("user_id"); userIDStr != "" {
 		id, err := strconv.Atoi(userIDStr)
@@ -147,6 +420,18 @@ func (h *MatchHandler) GetMatches(c *gin.Context) {
 		status = &statusStr
 	}
 
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		createdAt, id, err := utils.DecodeCursor(cursorStr)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		cursor = &models.MatchCursor{CreatedAt: createdAt, ID: id}
+	} else if c.Query("offset") != "" {
+		// Offset pagination is kept for one release; it gets slow deep into the feed
+		c.Header("Deprecation", "true")
+	}
+
 	// Use pagination utility with enforced maximum limits
 	pagination := utils.ParsePaginationWithDefaults(
 		c.Query("limit"),
@@ -155,13 +440,55 @@ func (h *MatchHandler) GetMatches(c *gin.Context) {
 		100, // max limit
 	)
 
-	matches, err := h.matchRepo.GetMatches(userID, sport, status, pagination.Limit, pagination.Offset)
+	// Past seasons live in the archive and are only searched on request
+	includeArchived := c.Query("archived") == "true"
+
+	// One extra row tells whether another page follows
+	matches, err := h.matchRepo.GetMatchesWithPlayers(c.Request.Context(), userID, sport, status, cursor, pagination.Limit+1, pagination.Offset, includeArchived)
 	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		respondQueryError(c, err.Error(), err)
 		return
 	}
 
-	utils.RespondWithJSON(c, http.StatusOK, matches)
+	page := models.MatchPage{Matches: matches}
+	if len(matches) > pagination.Limit {
+		page.Matches = matches[:pagination.Limit]
+		last := page.Matches[len(page.Matches)-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	if page.Matches == nil {
+		page.Matches = []models.MatchWithPlayers{}
+	}
+
+	if utils.WantsCSV(c) {
+		if page.NextCursor != "" {
+			c.Header("X-Next-Cursor", page.NextCursor)
+		}
+		// CSV keeps one row of plain match columns per match
+		rows := make([]models.Match, len(page.Matches))
+		for i := range page.Matches {
+			rows[i] = page.Matches[i].Match
+		}
+		utils.RespondWithList(c, http.StatusOK, "matches", rows)
+		return
+	}
+
+	// Embed reaction summaries so the feed needs no extra call per match
+	matchIDs := make([]int, len(page.Matches))
+	for i := range page.Matches {
+		matchIDs[i] = page.Matches[i].ID
+	}
+	viewerID, _ := middleware.GetUserID(c)
+	reactions, err := h.reactionRepo.GetSummaries(c.Request.Context(), matchIDs, viewerID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch reactions", err)
+		return
+	}
+	for i := range page.Matches {
+		page.Matches[i].Reactions = reactions[page.Matches[i].ID]
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, page)
 }
 
 // GetMatch retrieves a single match
@@ -172,59 +499,292 @@ func (h *MatchHandler) GetMatch(c *gin.Context) {
 		return
 	}
 
-	match, err := h.matchRepo.GetByID(matchID)
+	match, err := h.matchRepo.GetByID(c.Request.Context(), matchID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusNotFound, "match not found", err)
 		return
 	}
 
+	match.Sets, err = h.matchRepo.GetSets(c.Request.Context(), matchID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get match sets", err)
+		return
+	}
+
 	utils.RespondWithJSON(c, http.StatusOK, match)
 }
 
+// GetELOBreakdown explains the rating changes of a confirmed match
+// GET /api/matches/:id/elo-breakdown
+func (h *MatchHandler) GetELOBreakdown(c *gin.Context) {
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	breakdown, err := h.matchService.ExplainMatchELO(c.Request.Context(), matchID)
+	if err != nil {
+		switch err.Error() {
+		case "match not found":
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+		case "match is not confirmed", "match has no ELO data":
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to explain match ELO", err)
+		}
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, breakdown)
+}
+
 // GetLeaderboard returns leaderboard for a sport
+// Paginated with ?limit=&offset=; the total player count is sent in X-Total-Count
+// With ?group_by=coalition the coalitions are ranked instead of players
+// Current standings carry rank_delta against yesterday's snapshot, or last week's with ?compare=week
+// Responds with CSV for Accept: text/csv or ?format=csv
 func (h *MatchHandler) GetLeaderboard(c *gin.Context) {
 	sport := c.Param("sport")
-	if sport != models.SportTableTennis && sport != models.SportTableFootball {
-		utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", nil)
+	if err := h.sportService.ValidateSportID(sport); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
 		return
 	}
 
-	leaderboard, err := h.matchService.GetLeaderboard(sport)
-	if err != nil {
-		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+	switch c.Query("group_by") {
+	case "":
+	case "coalition":
+		h.getCoalitionLeaderboard(c, sport)
+		return
+	default:
+		utils.RespondWithError(c, http.StatusBadRequest, "group_by must be coalition", nil)
 		return
 	}
 
+	// Without limit/offset the whole leaderboard is returned, as before pagination existed
+	limit, offset := 0, 0
+	if c.Query("limit") != "" || c.Query("offset") != "" {
+		pagination := utils.ParsePaginationWithDefaults(c.Query("limit"), c.Query("offset"), 100, 500)
+		limit, offset = pagination.Limit, pagination.Offset
+	}
+
+	var leaderboard []models.LeaderboardEntry
+	var total int
+	if seasonParam := c.Query("season"); seasonParam != "" {
+		// Archived standings of a closed season
+		seasonID, err := strconv.Atoi(seasonParam)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid season", err)
+			return
+		}
+		leaderboard, err = h.seasonService.GetSeasonLeaderboard(c.Request.Context(), seasonID, sport)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		total = len(leaderboard)
+		leaderboard = paginateEntries(leaderboard, limit, offset)
+	} else {
+		days := 1
+		switch c.Query("compare") {
+		case "", "day":
+		case "week":
+			days = 7
+		default:
+			utils.RespondWithError(c, http.StatusBadRequest, "compare must be day or week", nil)
+			return
+		}
+
+		var err error
+		leaderboard, total, err = h.matchService.GetLeaderboardPage(c.Request.Context(), sport, limit, offset)
+		if err != nil {
+			respondQueryError(c, err.Error(), err)
+			return
+		}
+		if err := h.matchService.ApplyRankDeltas(c.Request.Context(), sport, leaderboard, time.Now().UTC().AddDate(0, 0, -days)); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to compare ranks", err)
+			return
+		}
+	}
+	c.Header("X-Total-Count", strconv.Itoa(total))
+
 	// Check if user is authenticated - if not, mask personal data for privacy
 	if !middleware.IsAuthenticated(c) {
-		// Create a copy of the leaderboard to avoid modifying the cached data
-		// which is shared across requests
-		maskedLeaderboard := make([]models.LeaderboardEntry, len(leaderboard))
-		copy(maskedLeaderboard, leaderboard)
+		masked, err := h.maskLeaderboard(c.Request.Context(), leaderboard)
+		if err != nil {
+			respondQueryError(c, "failed to get anonymous names", err)
+			return
+		}
+		leaderboard = masked
+	}
+
+	utils.RespondWithList(c, http.StatusOK, "leaderboard_"+sport, leaderboard)
+}
+
+// getCoalitionLeaderboard responds with the coalition standings of a sport
+// Coalitions are aggregates without personal data, so nothing is masked for guests
+func (h *MatchHandler) getCoalitionLeaderboard(c *gin.Context, sport string) {
+	if c.Query("season") != "" {
+		utils.RespondWithError(c, http.StatusBadRequest, "coalition standings are only available for the current season", nil)
+		return
+	}
+
+	standings, err := h.matchService.GetCoalitionStandings(c.Request.Context(), sport)
+	if err != nil {
+		respondQueryError(c, err.Error(), err)
+		return
+	}
+	c.Header("X-Total-Count", strconv.Itoa(len(standings)))
+
+	utils.RespondWithList(c, http.StatusOK, "coalitions_"+sport, standings)
+}
+
+// GetLeaderboardTop returns only the first n players (default 3) for dashboard widgets
+// Responses are cached pre-serialized, so polling skips the database, ranking and
+// anonymization entirely; for a few seconds while people play, longer when it is quiet
+// GET /api/leaderboard/:sport/top?n=3
+func (h *MatchHandler) GetLeaderboardTop(c *gin.Context) {
+	sport := c.Param("sport")
+	if err := h.sportService.ValidateSportID(sport); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+		return
+	}
+
+	n := 3
+	if nStr := c.Query("n"); nStr != "" {
+		parsed, err := strconv.Atoi(nStr)
+		if err != nil || parsed < 1 || parsed > maxPodiumSize {
+			utils.RespondWithError(c, http.StatusBadRequest, fmt.Sprintf("n must be between 1 and %d", maxPodiumSize), nil)
+			return
+		}
+		n = parsed
+	}
+
+	authenticated := middleware.IsAuthenticated(c)
+	ttl := h.activity.TTL(services.PodiumTTL)
+	cacheControl := "public"
+	if authenticated {
+		cacheControl = "private"
+	}
+	c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", cacheControl, int(ttl.Seconds())))
 
-		for i := range maskedLeaderboard {
-			maskedLeaderboard[i].User = maskUserData(maskedLeaderboard[i].User)
+	cacheKey := fmt.Sprintf("podium:%s:%d:%t", sport, n, authenticated)
+	if body, found := h.podiumCache.Get(cacheKey); found {
+		c.Data(http.StatusOK, gin.MIMEJSON+"; charset=utf-8", body)
+		return
+	}
+
+	podium, _, err := h.matchService.GetLeaderboardPage(c.Request.Context(), sport, n, 0)
+	if err != nil {
+		respondQueryError(c, err.Error(), err)
+		return
+	}
+	if !authenticated {
+		podium, err = h.maskLeaderboard(c.Request.Context(), podium)
+		if err != nil {
+			respondQueryError(c, "failed to get anonymous names", err)
+			return
 		}
-		utils.RespondWithJSON(c, http.StatusOK, maskedLeaderboard)
+	}
+
+	body, err := json.Marshal(podium)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to encode leaderboard", err)
 		return
 	}
+	h.podiumCache.Set(cacheKey, body, ttl)
 
-	utils.RespondWithJSON(c, http.StatusOK, leaderboard)
+	c.Data(http.StatusOK, gin.MIMEJSON+"; charset=utf-8", body)
+}
+
+// GetUnrankedPlayers lists players who started but have not finished the placement
+// matches of a sport, so others can find new opponents
+// Paginated with ?limit=&offset=; the total is sent in X-Total-Count
+// GET /api/leaderboard/:sport/unranked
+func (h *MatchHandler) GetUnrankedPlayers(c *gin.Context) {
+	sport := c.Param("sport")
+	if err := h.sportService.ValidateSportID(sport); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+		return
+	}
+
+	pagination := utils.ParsePaginationWithDefaults(c.Query("limit"), c.Query("offset"), 50, 200)
+	players, total, err := h.matchService.GetUnrankedPlayers(c.Request.Context(), sport, pagination.Limit, pagination.Offset)
+	if err != nil {
+		respondQueryError(c, "failed to get unranked players", err)
+		return
+	}
+	c.Header("X-Total-Count", strconv.Itoa(total))
+
+	// Guests see anonymized players, like on the leaderboard
+	if !middleware.IsAuthenticated(c) {
+		masked := make([]models.UnrankedEntry, len(players))
+		copy(masked, players)
+
+		users := make([]models.User, len(players))
+		for i := range players {
+			users[i] = players[i].User
+		}
+		names, err := h.anonService.AnonymousNames(c.Request.Context(), users)
+		if err != nil {
+			respondQueryError(c, "failed to get anonymous names", err)
+			return
+		}
+		for i := range masked {
+			masked[i].User = maskUserData(masked[i].User, names[masked[i].User.ID])
+		}
+		players = masked
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, players)
+}
+
+// maskLeaderboard returns a copy of the entries with anonymized players
+// The input is never modified because it is shared through the leaderboard cache
+func (h *MatchHandler) maskLeaderboard(ctx context.Context, leaderboard []models.LeaderboardEntry) ([]models.LeaderboardEntry, error) {
+	masked := make([]models.LeaderboardEntry, len(leaderboard))
+	copy(masked, leaderboard)
+
+	users := make([]models.User, len(leaderboard))
+	for i := range leaderboard {
+		users[i] = leaderboard[i].User
+	}
+	names, err := h.anonService.AnonymousNames(ctx, users)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range masked {
+		masked[i].User = maskUserData(masked[i].User, names[masked[i].User.ID])
+	}
+	return masked, nil
+}
+
+// paginateEntries returns one page of an already ranked leaderboard; limit <= 0 keeps all
+func paginateEntries(entries []models.LeaderboardEntry, limit, offset int) []models.LeaderboardEntry {
+	if offset >= len(entries) {
+		return []models.LeaderboardEntry{}
+	}
+	entries = entries[offset:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries
 }
 
 // maskUserData replaces personal information with anonymous data
-func maskUserData(user models.User) models.User {
+// anonymousName is the user's persisted, collision-free alias
+func maskUserData(user models.User, anonymousName string) models.User {
 	return models.User{
-		ID:               user.ID,
-		IntraID:          0, // Hide real intra ID
-		Login:            utils.GenerateAnonymousLogin(user.ID),
-		DisplayName:      utils.GenerateAnonymousName(user.ID),
-		AvatarURL:        utils.DefaultAvatarURL(user.ID),
-		Campus:           user.Campus, // Keep campus for context
-		TableTennisELO:   user.TableTennisELO,
-		TableFootballELO: user.TableFootballELO,
-		CreatedAt:        user.CreatedAt,
-		UpdatedAt:        user.UpdatedAt,
+		ID:          user.ID,
+		IntraID:     0, // Hide real intra ID
+		Login:       utils.GenerateAnonymousLogin(user.ID),
+		DisplayName: anonymousName,
+		AvatarURL:   utils.DefaultAvatarURL(user.ID),
+		Campus:      user.Campus, // Keep campus for context
+		Sports:      user.Sports,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
 	}
 }
 
@@ -261,22 +821,85 @@ func (h *MatchHandler) AddComment(c *gin.Context) {
 		return
 	}
 
+	// Replies are limited to one level below top-level comments of the same match
+	if req.ParentCommentID != nil {
+		parent, err := h.commentRepo.GetByID(c.Request.Context(), *req.ParentCommentID)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+			return
+		}
+		if parent == nil || parent.MatchID != matchID {
+			utils.RespondWithError(c, http.StatusNotFound, "parent comment not found", nil)
+			return
+		}
+		if parent.ParentCommentID != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "replies cannot be answered", nil)
+			return
+		}
+	}
+
 	comment := &models.Comment{
-		MatchID: matchID,
-		UserID:  userID,
-		Content: sanitizedContent,
+		MatchID:         matchID,
+		UserID:          userID,
+		ParentCommentID: req.ParentCommentID,
+		Content:         sanitizedContent,
 	}
 
-	if err := h.commentRepo.Add(comment); err != nil {
+	if err := h.commentRepo.Add(c.Request.Context(), comment); err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
 	}
 
+	// Shadowed comments look posted to their author but reach nobody else
+	if !comment.Shadowed {
+		h.notifyMentions(c.Request.Context(), comment)
+
+		h.hub.Publish(realtime.MatchChannel(matchID), "comment.created", comment)
+		h.hub.Publish(realtime.GlobalChannel, "comment.created", comment)
+	}
+
 	utils.RespondWithJSON(c, http.StatusCreated, comment)
 }
 
+// notifyMentions notifies the users mentioned as @login in a comment
+// Unknown logins and self-mentions are ignored
+func (h *MatchHandler) notifyMentions(ctx context.Context, comment *models.Comment) {
+	logins := utils.ParseMentions(comment.Content)
+	if len(logins) == 0 {
+		return
+	}
+
+	mentioned, err := h.userRepo.GetByLogins(ctx, logins)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to resolve comment mentions", "error", err, "comment_id", comment.ID)
+		return
+	}
+
+	player := "A player"
+	if author, err := h.userRepo.GetByID(ctx, comment.UserID); err == nil {
+		player = author.DisplayName
+	}
+
+	for _, user := range mentioned {
+		if user.ID == comment.UserID {
+			continue
+		}
+		h.inbox.Notify(ctx, &models.UserNotification{
+			UserID: user.ID,
+			Kind:   models.NotificationCommentMention,
+			Data: map[string]interface{}{
+				"player":     player,
+				"match_id":   comment.MatchID,
+				"comment_id": comment.ID,
+			},
+		})
+	}
+}
+
 // GetComments retrieves comments for a match with optional pagination
 func (h *MatchHandler) GetComments(c *gin.Context) {
+	viewerID, _ := middleware.GetUserID(c)
+
 	matchID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
@@ -291,7 +914,7 @@ func (h *MatchHandler) GetComments(c *gin.Context) {
 		// Paginated request - use pagination utility with enforced limits
 		pagination := utils.ParsePagination(limitStr, offsetStr)
 
-		comments, total, err := h.commentRepo.GetByMatchIDPaginated(matchID, pagination.Limit, pagination.Offset)
+		comments, total, err := h.commentRepo.GetByMatchIDPaginated(c.Request.Context(), matchID, viewerID, pagination.Limit, pagination.Offset)
 		if err != nil {
 			utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 			return
@@ -307,7 +930,7 @@ func (h *MatchHandler) GetComments(c *gin.Context) {
 	}
 
 	// Non-paginated request (backwards compatibility)
-	comments, err := h.commentRepo.GetByMatchID(matchID)
+	comments, err := h.commentRepo.GetByMatchID(c.Request.Context(), matchID, viewerID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
@@ -330,7 +953,7 @@ func (h *MatchHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	if err := h.commentRepo.Delete(commentID, userID); err != nil {
+	if err := h.commentRepo.Delete(c.Request.Context(), commentID, userID); err != nil {
 		if err == sql.ErrNoRows {
 			utils.RespondWithError(c, http.StatusForbidden, "cannot delete comment", err)
 			return
@@ -339,5 +962,156 @@ func (h *MatchHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
+	if matchID, err := strconv.Atoi(c.Param("id")); err == nil {
+		h.hub.Publish(realtime.MatchChannel(matchID), "comment.deleted", gin.H{"id": commentID})
+		h.hub.Publish(realtime.GlobalChannel, "comment.deleted", gin.H{"id": commentID, "match_id": matchID})
+	}
+
 	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "comment deleted"})
+}
+
+// GetReactions returns the reaction summary for a match
+func (h *MatchHandler) GetReactions(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	summary, err := h.reactionRepo.GetSummary(c.Request.Context(), matchID, userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch reactions", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, summary)
+}
+
+// AddReaction adds an emoji reaction to a match
+func (h *MatchHandler) AddReaction(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	var req models.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if err := utils.ValidateReactionEmoji(req.Emoji); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if _, err := h.matchRepo.GetByID(c.Request.Context(), matchID); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "match not found", err)
+		return
+	}
+
+	reaction := &models.Reaction{
+		MatchID: matchID,
+		UserID:  userID,
+		Emoji:   req.Emoji,
+	}
+
+	added, err := h.reactionRepo.Add(c.Request.Context(), reaction)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to add reaction", err)
+		return
+	}
+	if !added {
+		utils.RespondWithError(c, http.StatusConflict, "reaction already exists", nil)
+		return
+	}
+
+	h.publishReactions(c.Request.Context(), matchID, "reaction.added", userID, req.Emoji)
+
+	utils.RespondWithJSON(c, http.StatusCreated, reaction)
+}
+
+// RemoveReaction removes the user's emoji reaction from a match
+// The emoji is passed as a query parameter: DELETE /matches/:id/reactions?emoji=...
+func (h *MatchHandler) RemoveReaction(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	emoji := c.Query("emoji")
+	if err := utils.ValidateReactionEmoji(emoji); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if err := h.reactionRepo.Remove(c.Request.Context(), matchID, userID, emoji); err != nil {
+		if err == sql.ErrNoRows {
+			utils.RespondWithError(c, http.StatusNotFound, "reaction not found", err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to remove reaction", err)
+		return
+	}
+
+	h.publishReactions(c.Request.Context(), matchID, "reaction.removed", userID, emoji)
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "reaction removed"})
+}
+
+// publishReactions pushes the updated reaction summary to live match and global subscribers
+func (h *MatchHandler) publishReactions(ctx context.Context, matchID int, eventType string, userID int, emoji string) {
+	channel := realtime.MatchChannel(matchID)
+	if h.hub.Subscribers(channel) == 0 && h.hub.Subscribers(realtime.GlobalChannel) == 0 {
+		return
+	}
+
+	summary, err := h.reactionRepo.GetSummary(ctx, matchID, 0)
+	if err != nil {
+		return
+	}
+
+	event := gin.H{
+		"match_id":  matchID,
+		"user_id":   userID,
+		"emoji":     emoji,
+		"reactions": summary,
+	}
+	h.hub.Publish(channel, eventType, event)
+	h.hub.Publish(realtime.GlobalChannel, eventType, event)
+}
+
+// SubscribeMatch upgrades to a WebSocket that streams new comments and reactions of a match
+func (h *MatchHandler) SubscribeMatch(c *gin.Context) {
+	matchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid match ID", err)
+		return
+	}
+
+	if _, err := h.matchRepo.GetByID(c.Request.Context(), matchID); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "match not found", err)
+		return
+	}
+
+	// On failure the upgrader has already written an HTTP error response
+	if err := h.hub.Serve(c.Writer, c.Request, realtime.MatchChannel(matchID)); err != nil {
+		c.Abort()
+	}
 }
\ No newline at end of file
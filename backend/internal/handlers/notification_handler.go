@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler exposes the delivery log and test sends (admin only)
+type NotificationHandler struct {
+	notifier     *notifications.Notifier
+	templates    *notifications.Templates
+	language     string // Language of channel-wide notifications
+	deliveryRepo repositories.DeliveryStore
+	adminRepo    repositories.AdminStore
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(
+	notifier *notifications.Notifier,
+	templates *notifications.Templates,
+	language string,
+	deliveryRepo repositories.DeliveryStore,
+	adminRepo repositories.AdminStore,
+) *NotificationHandler {
+	return &NotificationHandler{
+		notifier:     notifier,
+		templates:    templates,
+		language:     language,
+		deliveryRepo: deliveryRepo,
+		adminRepo:    adminRepo,
+	}
+}
+
+// ListDeliveries returns recent delivery attempts with status and response codes
+// GET /api/admin/deliveries?channel=discord&status=failed&limit=50
+func (h *NotificationHandler) ListDeliveries(c *gin.Context) {
+	var channel, status *string
+	if ch := c.Query("channel"); ch != "" {
+		channel = &ch
+	}
+	if st := c.Query("status"); st != "" {
+		if st != models.DeliveryDelivered && st != models.DeliveryFailed {
+			utils.RespondWithError(c, http.StatusBadRequest, "status must be 'delivered' or 'failed'", nil)
+			return
+		}
+		status = &st
+	}
+
+	pagination := utils.ParsePaginationWithDefaults(c.Query("limit"), "", 50, 200)
+
+	deliveries, err := h.deliveryRepo.ListRecent(c.Request.Context(), channel, status, pagination.Limit)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch deliveries", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{
+		"channels":   h.notifier.Channels(),
+		"deliveries": deliveries,
+	})
+}
+
+// SendTest sends a test message through one channel and returns the recorded attempt
+// POST /api/admin/deliveries/test/:channel
+func (h *NotificationHandler) SendTest(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	channel := c.Param("channel")
+
+	msg, err := h.templates.Render(h.language, notifications.FormatDefault, notifications.EventTest, gin.H{"Channel": channel})
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to render test notification", err)
+		return
+	}
+
+	delivery, err := h.notifier.Send(c.Request.Context(), channel, notifications.EventTest, msg)
+	if err != nil {
+		if err.Error() == "channel not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to send test notification", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "send_test_notification", "system", nil, map[string]interface{}{
+		"channel": channel,
+		"status":  delivery.Status,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, delivery)
+}
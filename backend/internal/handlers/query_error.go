@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest is logged for requests the client abandoned (nginx convention)
+const statusClientClosedRequest = 499
+
+// respondQueryError maps the error of a read that ran with the request context
+// A client that disconnected cancelled its own queries and gets nothing; a query that ran
+// out of time is reported as a temporary failure; anything else is an internal error
+func respondQueryError(c *gin.Context, message string, err error) {
+	switch {
+	case c.Request.Context().Err() != nil:
+		slog.DebugContext(c.Request.Context(), "Client went away, query cancelled", "path", c.Request.URL.Path)
+		c.AbortWithStatus(statusClientClosedRequest)
+	case repositories.IsCanceled(err):
+		c.Header("Retry-After", "5")
+		utils.RespondWithError(c, http.StatusServiceUnavailable, "the request took too long, please try again", err)
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, message, err)
+	}
+}
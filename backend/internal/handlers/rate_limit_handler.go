@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitHandler shows and clears the rate limits of a user or IP (admin only)
+type RateLimitHandler struct {
+	limiters  *middleware.RateLimitRegistry
+	adminRepo repositories.AdminStore
+}
+
+// NewRateLimitHandler creates a new rate limit handler
+func NewRateLimitHandler(limiters *middleware.RateLimitRegistry, adminRepo repositories.AdminStore) *RateLimitHandler {
+	return &RateLimitHandler{
+		limiters:  limiters,
+		adminRepo: adminRepo,
+	}
+}
+
+// rateLimitSubject resolves exactly one of a user ID or an IP to the client's rate limit key
+// Requests of a logged-in user are limited by user on write endpoints and by IP on reads
+func rateLimitSubject(userIDStr, ip string) (string, *int, error) {
+	switch {
+	case userIDStr != "" && ip != "":
+		return "", nil, errors.New("pass either user_id or ip, not both")
+	case userIDStr != "":
+		userID, err := strconv.Atoi(userIDStr)
+		if err != nil || userID < 1 {
+			return "", nil, errors.New("invalid user ID")
+		}
+		return middleware.UserRateLimitKey(userID), &userID, nil
+	case ip != "":
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return "", nil, errors.New("invalid IP address")
+		}
+		return middleware.IPRateLimitKey(parsed.String()), nil, nil
+	default:
+		return "", nil, errors.New("user_id or ip is required")
+	}
+}
+
+// GetRateLimits returns the current buckets of a user or IP in every limiter
+// Buckets that are full again may already have been dropped and are not listed
+// GET /api/admin/rate-limits?user_id=|ip=
+func (h *RateLimitHandler) GetRateLimits(c *gin.Context) {
+	subject, _, err := rateLimitSubject(c.Query("user_id"), c.Query("ip"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	buckets, err := h.limiters.Buckets(c.Request.Context(), subject)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch rate limits", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{
+		"subject": subject,
+		"buckets": buckets,
+	})
+}
+
+// ResetRateLimits clears the buckets of a user or IP so the next requests get the full limit
+// POST /api/admin/rate-limits/reset
+func (h *RateLimitHandler) ResetRateLimits(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		UserID int    `json:"user_id"`
+		IP     string `json:"ip"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	userIDStr := ""
+	if req.UserID != 0 {
+		userIDStr = strconv.Itoa(req.UserID)
+	}
+	subject, userID, err := rateLimitSubject(userIDStr, req.IP)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	cleared, err := h.limiters.Reset(c.Request.Context(), subject)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to reset rate limits", err)
+		return
+	}
+
+	targetType := "system"
+	if userID != nil {
+		targetType = "user"
+	}
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "reset_rate_limits", targetType, userID, map[string]interface{}{
+		"subject": subject,
+		"cleared": cleared,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{
+		"subject": subject,
+		"cleared": cleared,
+	})
+}
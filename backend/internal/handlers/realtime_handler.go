@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/42heilbronn/elo-leaderboard/internal/realtime"
+	"github.com/gin-gonic/gin"
+)
+
+// RealtimeHandler serves the site-wide WebSocket feed
+type RealtimeHandler struct {
+	hub *realtime.Hub
+}
+
+// NewRealtimeHandler creates a new realtime handler
+func NewRealtimeHandler(hub *realtime.Hub) *RealtimeHandler {
+	return &RealtimeHandler{hub: hub}
+}
+
+// Subscribe upgrades to a WebSocket that streams leaderboard changes, match lifecycle
+// events, comments and reactions so the frontend doesn't have to poll
+// GET /api/ws (authenticated; browsers pass the JWT via cookie or ?token=)
+func (h *RealtimeHandler) Subscribe(c *gin.Context) {
+	// On failure the upgrader has already written an HTTP error response
+	if err := h.hub.Serve(c.Writer, c.Request, realtime.GlobalChannel); err != nil {
+		c.Abort()
+	}
+}
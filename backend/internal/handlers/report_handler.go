@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler serves abuse reports and the staff review queue
+type ReportHandler struct {
+	reportRepo  repositories.ReportStore
+	matchRepo   repositories.MatchStore
+	commentRepo repositories.CommentStore
+	userRepo    repositories.UserStore
+	adminRepo   repositories.AdminStore
+	inbox       *notifications.Inbox
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(
+	reportRepo repositories.ReportStore,
+	matchRepo repositories.MatchStore,
+	commentRepo repositories.CommentStore,
+	userRepo repositories.UserStore,
+	adminRepo repositories.AdminStore,
+	inbox *notifications.Inbox,
+) *ReportHandler {
+	return &ReportHandler{
+		reportRepo:  reportRepo,
+		matchRepo:   matchRepo,
+		commentRepo: commentRepo,
+		userRepo:    userRepo,
+		adminRepo:   adminRepo,
+		inbox:       inbox,
+	}
+}
+
+// CreateReport reports a match, comment or player for abuse
+// POST /api/reports
+func (h *ReportHandler) CreateReport(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	// The target must exist, and users cannot report themselves or their own comments
+	switch req.TargetType {
+	case models.ReportTargetMatch:
+		if _, err := h.matchRepo.GetByID(c.Request.Context(), req.TargetID); err != nil {
+			utils.RespondWithError(c, http.StatusNotFound, "match not found", err)
+			return
+		}
+	case models.ReportTargetComment:
+		comment, err := h.commentRepo.GetByID(c.Request.Context(), req.TargetID)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to get comment", err)
+			return
+		}
+		if comment == nil {
+			utils.RespondWithError(c, http.StatusNotFound, "comment not found", nil)
+			return
+		}
+		if comment.UserID == userID {
+			utils.RespondWithError(c, http.StatusBadRequest, "cannot report your own comment", nil)
+			return
+		}
+	case models.ReportTargetUser:
+		if req.TargetID == userID {
+			utils.RespondWithError(c, http.StatusBadRequest, "cannot report yourself", nil)
+			return
+		}
+		if _, err := h.userRepo.GetByID(c.Request.Context(), req.TargetID); err != nil {
+			utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+			return
+		}
+	}
+
+	report := &models.Report{
+		ReporterID: userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+	}
+	if details := utils.SanitizeString(req.Details); details != "" {
+		report.Details = &details
+	}
+
+	if err := h.reportRepo.Create(c.Request.Context(), report); err != nil {
+		if err.Error() == "report already open" {
+			utils.RespondWithError(c, http.StatusConflict, "you already reported this", err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to create report", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusCreated, report)
+}
+
+// GetReports returns the report queue, oldest first
+// GET /api/admin/reports?status=open|resolved|dismissed
+func (h *ReportHandler) GetReports(c *gin.Context) {
+	status := c.DefaultQuery("status", models.ReportOpen)
+	switch status {
+	case models.ReportOpen, models.ReportResolved, models.ReportDismissed:
+	default:
+		utils.RespondWithError(c, http.StatusBadRequest, "status must be 'open', 'resolved' or 'dismissed'", nil)
+		return
+	}
+
+	pagination := utils.ParsePaginationWithDefaults(
+		c.Query("limit"),
+		c.Query("offset"),
+		50,  // default limit
+		200, // max limit
+	)
+
+	reports, err := h.reportRepo.List(c.Request.Context(), status, pagination.Limit, pagination.Offset)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get reports", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, reports)
+}
+
+// ResolveReport closes a report after staff acted on it
+// POST /api/admin/reports/:id/resolve
+func (h *ReportHandler) ResolveReport(c *gin.Context) {
+	h.closeReport(c, models.ReportResolved, "resolve_report", models.NotificationReportResolved)
+}
+
+// DismissReport closes a report without action
+// POST /api/admin/reports/:id/dismiss
+func (h *ReportHandler) DismissReport(c *gin.Context) {
+	h.closeReport(c, models.ReportDismissed, "dismiss_report", models.NotificationReportDismissed)
+}
+
+// closeReport moves an open report to status, logs it as action and notifies the reporter
+func (h *ReportHandler) closeReport(c *gin.Context, status, action, kind string) {
+	adminID, _ := middleware.GetUserID(c)
+
+	reportID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid report ID", err)
+		return
+	}
+
+	var req models.CloseReportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	}
+	var note *string
+	noteText := utils.SanitizeString(req.Note)
+	if noteText != "" {
+		note = &noteText
+	}
+
+	report, err := h.reportRepo.Close(c.Request.Context(), reportID, status, adminID, note)
+	if err != nil {
+		switch err.Error() {
+		case "report not found":
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+		case "report is not open":
+			utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to update report", err)
+		}
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, action, "report", &reportID, map[string]interface{}{
+		"target_type": report.TargetType,
+		"target_id":   report.TargetID,
+		"reason":      report.Reason,
+		"note":        noteText,
+	})
+
+	h.inbox.Notify(c.Request.Context(), &models.UserNotification{
+		UserID: report.ReporterID,
+		Kind:   kind,
+		Data: map[string]interface{}{
+			"report_id":   report.ID,
+			"target_type": report.TargetType,
+			"note":        noteText,
+		},
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, report)
+}
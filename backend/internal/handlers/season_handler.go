@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SeasonHandler handles season endpoints
+type SeasonHandler struct {
+	seasonService *services.SeasonService
+	adminRepo     repositories.AdminStore
+}
+
+// NewSeasonHandler creates a new season handler
+func NewSeasonHandler(seasonService *services.SeasonService, adminRepo repositories.AdminStore) *SeasonHandler {
+	return &SeasonHandler{
+		seasonService: seasonService,
+		adminRepo:     adminRepo,
+	}
+}
+
+// ListSeasons returns all seasons, newest first
+// GET /api/seasons
+func (h *SeasonHandler) ListSeasons(c *gin.Context) {
+	seasons, err := h.seasonService.ListSeasons(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch seasons", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, seasons)
+}
+
+// OpenSeason starts a new season and soft-resets ELO
+// POST /api/admin/seasons
+func (h *SeasonHandler) OpenSeason(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req models.OpenSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	name, err := utils.ValidateInput(strings.TrimSpace(req.Name), 100, false)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid season name", err)
+		return
+	}
+
+	season, err := h.seasonService.OpenSeason(c.Request.Context(), name, req.ResetFactor, adminID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "open_season", "system", &season.ID, map[string]interface{}{
+		"name":         season.Name,
+		"reset_factor": season.ResetFactor,
+	})
+
+	utils.RespondWithJSON(c, http.StatusCreated, season)
+}
+
+// CloseSeason ends a season and archives its final standings
+// POST /api/admin/seasons/:id/close
+func (h *SeasonHandler) CloseSeason(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	seasonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid season ID", err)
+		return
+	}
+
+	season, err := h.seasonService.CloseSeason(c.Request.Context(), seasonID, adminID)
+	if err != nil {
+		if err.Error() == "season not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "close_season", "system", &season.ID, map[string]interface{}{
+		"name": season.Name,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, season)
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/integrations"
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// slackLinkCodeTTL is how long a link code can be redeemed in Slack
+	slackLinkCodeTTL = 10 * time.Minute
+	// slackLinkCodeAlphabet avoids characters that are easily confused when typed
+	slackLinkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	slackLinkCodeLength   = 8
+	// slackPendingLimit bounds the matches listed by "/elo pending"
+	slackPendingLimit = 10
+)
+
+const slackHelp = "Usage:\n" +
+	"• `/elo submit <sport> <@opponent|login> <your score>-<their score>` submit a match\n" +
+	"• `/elo confirm <match id>` confirm a match your opponent submitted\n" +
+	"• `/elo pending` list matches waiting for your confirmation\n" +
+	"• `/elo link <code>` link your Slack account (create the code on the leaderboard)"
+
+// SlackHandler serves the Slack slash command and the account linking endpoints
+type SlackHandler struct {
+	slackRepo     repositories.SlackStore
+	userRepo      repositories.UserStore
+	matchRepo     repositories.MatchStore
+	matchService  *services.MatchService
+	signingSecret string
+}
+
+// NewSlackHandler creates a new Slack handler
+func NewSlackHandler(
+	slackRepo repositories.SlackStore,
+	userRepo repositories.UserStore,
+	matchRepo repositories.MatchStore,
+	matchService *services.MatchService,
+	signingSecret string,
+) *SlackHandler {
+	return &SlackHandler{
+		slackRepo:     slackRepo,
+		userRepo:      userRepo,
+		matchRepo:     matchRepo,
+		matchService:  matchService,
+		signingSecret: signingSecret,
+	}
+}
+
+// HandleCommand answers the /elo slash command
+// Slack expects HTTP 200 for anything it should show the user, so command errors
+// are returned as ephemeral messages; only unsigned requests get an error status
+// POST /api/integrations/slack
+func (h *SlackHandler) HandleCommand(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "failed to read request body", err)
+		return
+	}
+
+	if err := integrations.VerifySlackSignature(
+		h.signingSecret,
+		c.GetHeader("X-Slack-Request-Timestamp"),
+		c.GetHeader("X-Slack-Signature"),
+		body,
+		time.Now(),
+	); err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "invalid slack signature", err)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid form body", err)
+		return
+	}
+	teamID, slackUserID := form.Get("team_id"), form.Get("user_id")
+	cmd := integrations.ParseSlackCommand(form.Get("text"))
+
+	switch cmd.Action {
+	case "help":
+		utils.RespondWithJSON(c, http.StatusOK, integrations.SlackEphemeral(slackHelp))
+		return
+	case "link":
+		utils.RespondWithJSON(c, http.StatusOK, h.link(c.Request.Context(), teamID, slackUserID, cmd.Args))
+		return
+	}
+
+	userID, linked, err := h.slackRepo.GetUserID(c.Request.Context(), teamID, slackUserID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to look up slack link", err)
+		return
+	}
+	if !linked {
+		utils.RespondWithJSON(c, http.StatusOK, integrations.SlackEphemeral(
+			"Your Slack account is not linked yet. Create a link code on the leaderboard and run `/elo link <code>`."))
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch user", err)
+		return
+	}
+	if user.Banned() {
+		utils.RespondWithJSON(c, http.StatusOK, integrations.SlackEphemeral("Your account is banned."))
+		return
+	}
+
+	var resp integrations.SlackResponse
+	switch cmd.Action {
+	case "submit":
+		resp = h.submit(c.Request.Context(), teamID, user, cmd.Args)
+	case "confirm":
+		resp = h.confirm(c.Request.Context(), user, cmd.Args)
+	case "pending":
+		resp = h.pending(c.Request.Context(), user)
+	default:
+		resp = integrations.SlackEphemeral("Unknown command `%s`.\n%s", cmd.Action, slackHelp)
+	}
+	utils.RespondWithJSON(c, http.StatusOK, resp)
+}
+
+// link redeems a link code created on the leaderboard
+func (h *SlackHandler) link(ctx context.Context, teamID, slackUserID string, args []string) integrations.SlackResponse {
+	if len(args) != 1 {
+		return integrations.SlackEphemeral("Usage: `/elo link <code>`")
+	}
+
+	userID, err := h.slackRepo.RedeemLinkCode(ctx, strings.ToUpper(args[0]), teamID, slackUserID)
+	if err != nil {
+		return integrations.SlackEphemeral("Could not link your account: %s.", err.Error())
+	}
+
+	user, err := h.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return integrations.SlackEphemeral("Your Slack account is now linked.")
+	}
+	return integrations.SlackEphemeral("Your Slack account is now linked to *%s*.", user.Login)
+}
+
+// submit reports a match, validated exactly like a submission from the web app
+// The client fingerprint is left empty: the request comes from Slack, not the player's device
+func (h *SlackHandler) submit(ctx context.Context, teamID string, user *models.User, args []string) integrations.SlackResponse {
+	if len(args) != 3 {
+		return integrations.SlackEphemeral("Usage: `/elo submit <sport> <@opponent|login> <your score>-<their score>`")
+	}
+
+	opponent, err := h.resolveOpponent(ctx, teamID, args[1])
+	if err != nil {
+		return integrations.SlackEphemeral("Could not submit the match: %s.", err.Error())
+	}
+
+	playerScore, opponentScore, err := integrations.ParseSlackScore(args[2])
+	if err != nil {
+		return integrations.SlackEphemeral("Could not submit the match: %s.", err.Error())
+	}
+
+	req := models.SubmitMatchRequest{
+		Sport:         args[0],
+		OpponentID:    opponent.ID,
+		PlayerScore:   playerScore,
+		OpponentScore: opponentScore,
+	}
+	if err := utils.ValidateMatchSubmission(req.Sport, req.OpponentID, req.PlayerScore, req.OpponentScore, user.ID); err != nil {
+		return integrations.SlackEphemeral("Invalid match: %s.", err.Error())
+	}
+
+	match, err := h.matchService.SubmitMatch(ctx, &req, user.ID, "")
+	if err != nil {
+		return integrations.SlackEphemeral("Could not submit the match: %s.", err.Error())
+	}
+
+	return integrations.SlackEphemeral("Submitted match #%d against *%s* (%d-%d). It counts once %s confirms it.",
+		match.ID, opponent.Login, playerScore, opponentScore, opponent.Login)
+}
+
+// resolveOpponent finds a player by Slack mention or intra login
+func (h *SlackHandler) resolveOpponent(ctx context.Context, teamID, arg string) (*models.User, error) {
+	if slackUserID, ok := integrations.ParseSlackMention(arg); ok {
+		userID, linked, err := h.slackRepo.GetUserID(ctx, teamID, slackUserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up opponent")
+		}
+		if !linked {
+			return nil, fmt.Errorf("%s has not linked their Slack account, use their intra login instead", arg)
+		}
+		return h.userRepo.GetByID(ctx, userID)
+	}
+
+	login := strings.ToLower(strings.TrimPrefix(arg, "@"))
+	if err := utils.ValidateLogin(login); err != nil {
+		return nil, fmt.Errorf("unknown player %s", arg)
+	}
+	opponent, err := h.userRepo.GetByLogin(ctx, login)
+	if err != nil {
+		return nil, fmt.Errorf("unknown player %s", arg)
+	}
+	return opponent, nil
+}
+
+// confirm confirms a pending match submitted by the opponent
+func (h *SlackHandler) confirm(ctx context.Context, user *models.User, args []string) integrations.SlackResponse {
+	if len(args) != 1 {
+		return integrations.SlackEphemeral("Usage: `/elo confirm <match id>`")
+	}
+
+	matchID, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return integrations.SlackEphemeral("Invalid match ID `%s`.", args[0])
+	}
+
+	if err := h.matchService.ConfirmMatch(ctx, matchID, user.ID, ""); err != nil {
+		return integrations.SlackEphemeral("Could not confirm match #%d: %s.", matchID, err.Error())
+	}
+	return integrations.SlackEphemeral("Confirmed match #%d.", matchID)
+}
+
+// pending lists matches waiting for the user's confirmation
+func (h *SlackHandler) pending(ctx context.Context, user *models.User) integrations.SlackResponse {
+	status := models.StatusPending
+	matches, err := h.matchRepo.GetMatches(ctx, &user.ID, nil, &status, nil, slackPendingLimit, 0, false)
+	if err != nil {
+		return integrations.SlackEphemeral("Could not load your pending matches.")
+	}
+
+	var lines []string
+	for _, match := range matches {
+		if match.SubmittedBy == user.ID {
+			continue
+		}
+		submitter, err := h.userRepo.GetByID(ctx, match.SubmittedBy)
+		if err != nil {
+			continue
+		}
+		own, theirs := match.Player2Score, match.Player1Score
+		if match.Player1ID == user.ID {
+			own, theirs = theirs, own
+		}
+		lines = append(lines, fmt.Sprintf("• #%d %s vs *%s*: %d-%d", match.ID, match.Sport, submitter.Login, own, theirs))
+	}
+
+	if len(lines) == 0 {
+		return integrations.SlackEphemeral("No matches are waiting for your confirmation.")
+	}
+	return integrations.SlackEphemeral("Waiting for your confirmation (`/elo confirm <id>`):\n%s", strings.Join(lines, "\n"))
+}
+
+// generateSlackLinkCode returns a random code from slackLinkCodeAlphabet
+func generateSlackLinkCode() (string, error) {
+	max := big.NewInt(int64(len(slackLinkCodeAlphabet)))
+	code := make([]byte, slackLinkCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = slackLinkCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// CreateLinkCode creates a one-time code to link the caller's Slack account
+// POST /api/users/me/slack/link-code
+func (h *SlackHandler) CreateLinkCode(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	code, err := generateSlackLinkCode()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate link code", err)
+		return
+	}
+
+	linkCode := models.SlackLinkCode{Code: code, ExpiresAt: time.Now().Add(slackLinkCodeTTL).UTC()}
+	if err := h.slackRepo.CreateLinkCode(c.Request.Context(), userID, linkCode.Code, linkCode.ExpiresAt); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to create link code", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusCreated, linkCode)
+}
+
+// GetLink returns the caller's Slack link
+// GET /api/users/me/slack
+func (h *SlackHandler) GetLink(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	link, err := h.slackRepo.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		if err.Error() == "slack account not linked" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch slack link", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, link)
+}
+
+// Unlink removes the caller's Slack link
+// DELETE /api/users/me/slack
+func (h *SlackHandler) Unlink(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.RespondWithError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	if err := h.slackRepo.Unlink(c.Request.Context(), userID); err != nil {
+		if err.Error() == "slack account not linked" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to unlink slack account", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "slack account unlinked"})
+}
@@ -1,21 +1,29 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
 	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
 // SportHandler handles sport-related API endpoints
 type SportHandler struct {
 	sportService *services.SportService
+	adminRepo    repositories.AdminStore
 }
 
 // NewSportHandler creates a new sport handler
-func NewSportHandler(sportService *services.SportService) *SportHandler {
+func NewSportHandler(sportService *services.SportService, adminRepo repositories.AdminStore) *SportHandler {
 	return &SportHandler{
 		sportService: sportService,
+		adminRepo:    adminRepo,
 	}
 }
 
@@ -24,7 +32,7 @@ func NewSportHandler(sportService *services.SportService) *SportHandler {
 func (h *SportHandler) GetAllSports(c *gin.Context) {
 	sports, err := h.sportService.GetAllActiveSports()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sports"})
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch sports", err)
 		return
 	}
 
@@ -36,15 +44,207 @@ func (h *SportHandler) GetAllSports(c *gin.Context) {
 func (h *SportHandler) GetSport(c *gin.Context) {
 	sportID := c.Param("id")
 	if sportID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Sport ID is required"})
+		utils.RespondWithError(c, http.StatusBadRequest, "Sport ID is required", nil)
 		return
 	}
 
 	sport, err := h.sportService.GetSport(sportID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		utils.RespondWithError(c, http.StatusNotFound, err.Error(), nil)
 		return
 	}
 
 	c.JSON(http.StatusOK, sport)
 }
+
+// ExportSports downloads the configuration of all sports as JSON for another instance
+// GET /api/admin/sports/export
+func (h *SportHandler) ExportSports(c *gin.Context) {
+	export, err := h.sportService.ExportConfigs()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to export sports", err)
+		return
+	}
+
+	filename := fmt.Sprintf("sports-%s.json", export.ExportedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	utils.RespondWithJSON(c, http.StatusOK, export)
+}
+
+// ImportSports creates or updates sports from an export; ?dry_run=true only reports the changes
+// POST /api/admin/sports/import
+func (h *SportHandler) ImportSports(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "dry_run must be a boolean", err)
+			return
+		}
+		dryRun = parsed
+	}
+
+	var export services.SportConfigExport
+	if err := c.ShouldBindJSON(&export); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	result, err := h.sportService.ImportConfigs(c.Request.Context(), &export, dryRun)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if !dryRun {
+		h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "import_sports", "system", nil, map[string]interface{}{
+			"created": result.Created,
+			"updated": result.Updated,
+		})
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, result)
+}
+
+// CreateSport adds a sport from a configuration in the export format; it is active right away
+// POST /api/admin/sports
+func (h *SportHandler) CreateSport(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	// Fields missing from the body get the same defaults as the sports table
+	config := services.SportConfig{
+		DefaultELO:                1000,
+		KFactor:                   32,
+		MaxScore:                  999,
+		IsActive:                  true,
+		PendingMode:               services.PendingModeStrict,
+		PendingMinIntervalSeconds: 60,
+		MaxPendingPerPair:         5,
+		MaxMarginMultiplier:       1.5,
+		RatingFloor:               100,
+		RatingCeiling:             4000,
+	}
+	config.SetKFactorDefaults()
+	if err := c.ShouldBindJSON(&config); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if err := h.sportService.CreateSport(c.Request.Context(), config); err != nil {
+		respondSportError(c, "failed to create sport", err)
+		return
+	}
+
+	created, err := h.sportService.GetSportConfig(config.ID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to load sport", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "create_sport", "sport", nil, map[string]interface{}{
+		"sport":  created.ID,
+		"config": created,
+	})
+
+	utils.RespondWithJSON(c, http.StatusCreated, created)
+}
+
+// UpdateSport changes the configuration of a sport; fields missing from the body keep their value
+// Setting is_active to true reactivates a deactivated sport
+// PUT /api/admin/sports/:id
+func (h *SportHandler) UpdateSport(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	sportID := c.Param("id")
+
+	config, err := h.sportService.GetSportConfig(sportID)
+	if err != nil {
+		respondSportError(c, "failed to load sport", err)
+		return
+	}
+	if err := c.ShouldBindJSON(config); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if config.ID != sportID {
+		utils.RespondWithError(c, http.StatusBadRequest, "sport id cannot be changed", nil)
+		return
+	}
+
+	before, err := h.sportService.UpdateSport(c.Request.Context(), *config)
+	if err != nil {
+		respondSportError(c, "failed to update sport", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "update_sport", "sport", nil, map[string]interface{}{
+		"sport":  sportID,
+		"before": before,
+		"after":  config,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, config)
+}
+
+// DeactivateSport hides a sport from submission and the leaderboards, keeping its matches
+// and ratings; at least one sport always stays active
+// DELETE /api/admin/sports/:id
+func (h *SportHandler) DeactivateSport(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	sportID := c.Param("id")
+
+	changed, err := h.sportService.DeactivateSport(c.Request.Context(), sportID)
+	if err != nil {
+		respondSportError(c, "failed to deactivate sport", err)
+		return
+	}
+
+	if changed {
+		h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "deactivate_sport", "sport", nil, map[string]interface{}{
+			"sport": sportID,
+		})
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "sport deactivated successfully"})
+}
+
+// ReorderSports sets the display order; listed sports come first, the rest keep their order
+// POST /api/admin/sports/reorder
+func (h *SportHandler) ReorderSports(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req struct {
+		SportIDs []string `json:"sport_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	order, err := h.sportService.ReorderSports(c.Request.Context(), req.SportIDs)
+	if err != nil {
+		respondSportError(c, "failed to reorder sports", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "reorder_sports", "system", nil, map[string]interface{}{
+		"order": order,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"sport_ids": order})
+}
+
+// respondSportError maps the errors of the sport management methods to status codes
+func respondSportError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrSportNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, services.ErrSportExists), errors.Is(err, services.ErrLastActiveSport):
+		utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, services.ErrInvalidSportConfig):
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Histogram bucket width bounds for the distribution endpoint
+const (
+	defaultBucketSize = 50
+	minBucketSize     = 10
+	maxBucketSize     = 500
+)
+
+// Window bounds in days for the activity heatmap
+const (
+	defaultActivityDays = 28
+	maxActivityDays     = 365
+)
+
+// timeZonePattern admits IANA zone names such as Europe/Berlin or Etc/GMT+1
+var timeZonePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+/-]{0,63}$`)
+
+// StatsHandler serves aggregate statistics across all players
+type StatsHandler struct {
+	matchRepo    repositories.MatchStore
+	matchService *services.MatchService
+	sportService *services.SportService
+	anonService  *services.AnonymizationService
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(
+	matchRepo repositories.MatchStore,
+	matchService *services.MatchService,
+	sportService *services.SportService,
+	anonService *services.AnonymizationService,
+) *StatsHandler {
+	return &StatsHandler{
+		matchRepo:    matchRepo,
+		matchService: matchService,
+		sportService: sportService,
+		anonService:  anonService,
+	}
+}
+
+// GetGlobalStats returns players, matches per sport, average ELO, the #1 of every sport and
+// the most active player of the week; players are anonymized for visitors who are not logged in
+// GET /api/stats
+func (h *StatsHandler) GetGlobalStats(c *gin.Context) {
+	stats, err := h.matchService.GetGlobalStats(c.Request.Context())
+	if err != nil {
+		respondQueryError(c, "failed to get stats", err)
+		return
+	}
+
+	if !middleware.IsAuthenticated(c) {
+		var users []models.User
+		for _, summary := range stats.Sports {
+			if summary.Leader != nil {
+				users = append(users, summary.Leader.User)
+			}
+		}
+		if stats.MostActive != nil {
+			users = append(users, stats.MostActive.User)
+		}
+		names, err := h.anonService.AnonymousNames(c.Request.Context(), users)
+		if err != nil {
+			respondQueryError(c, "failed to get anonymous names", err)
+			return
+		}
+
+		for _, summary := range stats.Sports {
+			if summary.Leader != nil {
+				summary.Leader.User = maskUserData(summary.Leader.User, names[summary.Leader.User.ID])
+			}
+		}
+		if stats.MostActive != nil {
+			stats.MostActive.User = maskUserData(stats.MostActive.User, names[stats.MostActive.User.ID])
+		}
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, stats)
+}
+
+// GetDistribution returns the ELO histogram, median and percentiles of a sport's active players
+// Logged-in players who have played also get their own rating and percentile
+// GET /api/stats/:sport/distribution?bucket=50
+func (h *StatsHandler) GetDistribution(c *gin.Context) {
+	sport := c.Param("sport")
+	if err := h.sportService.ValidateSportID(sport); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+		return
+	}
+
+	bucketSize := defaultBucketSize
+	if raw := c.Query("bucket"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < minBucketSize || size > maxBucketSize {
+			utils.RespondWithError(c, http.StatusBadRequest, "bucket must be between 10 and 500", err)
+			return
+		}
+		bucketSize = size
+	}
+
+	dist, err := h.matchService.GetELODistribution(c.Request.Context(), sport, bucketSize)
+	if err != nil {
+		respondQueryError(c, "failed to get distribution", err)
+		return
+	}
+
+	if userID, ok := middleware.GetUserID(c); ok {
+		elo, percentile, played, err := h.matchRepo.GetELOPercentile(c.Request.Context(), sport, userID)
+		if err != nil {
+			respondQueryError(c, "failed to get percentile", err)
+			return
+		}
+		if played {
+			dist.UserELO = &elo
+			dist.UserPercentile = &percentile
+		}
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, dist)
+}
+
+// GetActivity returns the confirmed matches of a sport by weekday and hour for a
+// "when is the table free" heatmap; hours are local to ?tz= (an IANA zone, default UTC)
+// GET /api/stats/:sport/activity?days=28&tz=Europe/Berlin
+func (h *StatsHandler) GetActivity(c *gin.Context) {
+	sport := c.Param("sport")
+	if err := h.sportService.ValidateSportID(sport); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+		return
+	}
+
+	days := defaultActivityDays
+	if raw := c.Query("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxActivityDays {
+			utils.RespondWithError(c, http.StatusBadRequest, "days must be between 1 and 365", err)
+			return
+		}
+		days = n
+	}
+
+	tz := c.DefaultQuery("tz", "UTC")
+	if !timeZonePattern.MatchString(tz) {
+		utils.RespondWithError(c, http.StatusBadRequest, "unknown time zone", nil)
+		return
+	}
+
+	heatmap, err := h.matchService.GetActivityHeatmap(c.Request.Context(), sport, days, tz)
+	if err != nil {
+		if err.Error() == "unknown time zone" {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		respondQueryError(c, "failed to get activity", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, heatmap)
+}
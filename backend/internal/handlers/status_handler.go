@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/cache"
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// statusCacheTTL bounds how often the status page checks components
+	statusCacheTTL = 30 * time.Second
+	// statusIncidentWindow is how long resolved incidents stay on the status page
+	statusIncidentWindow = 7 * 24 * time.Hour
+	// statusIncidentLimit bounds the incidents shown on the status page
+	statusIncidentLimit = 20
+	statusCacheKey      = "status:public"
+)
+
+// Public component states of the status page
+const (
+	ComponentOperational = "operational"
+	ComponentMaintenance = "maintenance"
+	ComponentDegraded    = "degraded"
+	ComponentOutage      = "outage"
+)
+
+// componentSeverity orders states from best to worst
+var componentSeverity = map[string]int{
+	ComponentOperational: 0,
+	ComponentMaintenance: 1,
+	ComponentDegraded:    2,
+	ComponentOutage:      3,
+}
+
+// PublicStatus is the public status page payload
+// It only carries coarse states; details stay on the internal /health endpoint
+type PublicStatus struct {
+	Status     string                  `json:"status"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+	StartedAt  time.Time               `json:"started_at"`
+	UptimeSecs int64                   `json:"uptime_seconds"`
+	Components []ComponentStatus       `json:"components"`
+	Incidents  []models.StatusIncident `json:"incidents"`
+}
+
+// ComponentStatus is the state of one part of the service
+type ComponentStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// StatusHandler serves the public status page and manages its incidents
+type StatusHandler struct {
+	health       *HealthHandler
+	incidentRepo repositories.IncidentStore
+	adminRepo    repositories.AdminStore
+	cache        cache.Cache
+}
+
+// NewStatusHandler creates a new status handler
+// Component checks are shared with the health handler
+func NewStatusHandler(
+	health *HealthHandler,
+	incidentRepo repositories.IncidentStore,
+	adminRepo repositories.AdminStore,
+	statusCache cache.Cache,
+) *StatusHandler {
+	return &StatusHandler{
+		health:       health,
+		incidentRepo: incidentRepo,
+		adminRepo:    adminRepo,
+		cache:        statusCache,
+	}
+}
+
+// GetStatus returns the public service status
+// Always answers 200 so status pages can render an outage; cached for statusCacheTTL
+// GET /api/status
+func (h *StatusHandler) GetStatus(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(statusCacheTTL.Seconds())))
+
+	if body, found := h.cache.Get(statusCacheKey); found {
+		c.Data(http.StatusOK, gin.MIMEJSON+"; charset=utf-8", body)
+		return
+	}
+
+	body, err := json.Marshal(h.buildStatus(c.Request.Context()))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to encode status", err)
+		return
+	}
+	h.cache.Set(statusCacheKey, body, statusCacheTTL)
+
+	c.Data(http.StatusOK, gin.MIMEJSON+"; charset=utf-8", body)
+}
+
+// buildStatus runs the component checks and merges in the recent incidents
+func (h *StatusHandler) buildStatus(ctx context.Context) PublicStatus {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	status := PublicStatus{
+		Status:     ComponentOperational,
+		UpdatedAt:  now,
+		StartedAt:  h.health.startTime.UTC(),
+		UptimeSecs: int64(time.Since(h.health.startTime).Seconds()),
+		Components: []ComponentStatus{{Name: "api", Status: ComponentOperational}},
+		Incidents:  []models.StatusIncident{},
+	}
+
+	database := componentFromHealth(h.health.checkDatabase(ctx, h.health.db).Status)
+	if h.health.replica != nil && h.health.checkDatabase(ctx, h.health.replica).Status != StatusHealthy {
+		// Matches can still be submitted, but leaderboards and statistics fail
+		database = worseStatus(database, ComponentDegraded)
+	}
+	status.Components = append(status.Components, ComponentStatus{
+		Name:   "database",
+		Status: database,
+	})
+	if h.health.scheduler != nil {
+		status.Components = append(status.Components, ComponentStatus{
+			Name:   "background_jobs",
+			Status: componentFromHealth(h.health.checkJobs().Status),
+		})
+	}
+	for _, component := range status.Components {
+		status.Status = worseStatus(status.Status, component.Status)
+	}
+
+	incidents, err := h.incidentRepo.ListRecent(ctx, now.Add(-statusIncidentWindow), statusIncidentLimit)
+	if err != nil {
+		// The database check already reports the outage; the page still renders
+		slog.WarnContext(ctx, "Failed to load status incidents", "error", err)
+		return status
+	}
+	status.Incidents = incidents
+	for _, incident := range incidents {
+		if incident.ResolvedAt == nil {
+			status.Status = worseStatus(status.Status, incident.Impact)
+		}
+	}
+
+	return status
+}
+
+// componentFromHealth maps internal health states to public component states
+func componentFromHealth(health string) string {
+	switch health {
+	case StatusHealthy:
+		return ComponentOperational
+	case StatusDegraded:
+		return ComponentDegraded
+	default:
+		return ComponentOutage
+	}
+}
+
+func worseStatus(a, b string) string {
+	if componentSeverity[b] > componentSeverity[a] {
+		return b
+	}
+	return a
+}
+
+// ListIncidents returns all incidents, newest first
+// GET /api/admin/incidents?limit=50
+func (h *StatusHandler) ListIncidents(c *gin.Context) {
+	pagination := utils.ParsePaginationWithDefaults(c.Query("limit"), "", 50, 200)
+
+	incidents, err := h.incidentRepo.ListRecent(c.Request.Context(), time.Time{}, pagination.Limit)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch incidents", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, incidents)
+}
+
+// CreateIncident opens an incident on the status page
+// POST /api/admin/incidents
+func (h *StatusHandler) CreateIncident(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req models.CreateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	incident := &models.StatusIncident{
+		Title:     strings.TrimSpace(req.Title),
+		Message:   strings.TrimSpace(req.Message),
+		Impact:    req.Impact,
+		StartedAt: time.Now().UTC(),
+		CreatedBy: &adminID,
+	}
+	if req.StartedAt != nil {
+		incident.StartedAt = req.StartedAt.UTC()
+	}
+
+	if err := h.incidentRepo.Create(c.Request.Context(), incident); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to create incident", err)
+		return
+	}
+	h.cache.Delete(statusCacheKey)
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "create_incident", "system", &incident.ID, map[string]interface{}{
+		"title":  incident.Title,
+		"impact": incident.Impact,
+	})
+
+	utils.RespondWithJSON(c, http.StatusCreated, incident)
+}
+
+// UpdateIncident edits an incident or marks it resolved
+// PUT /api/admin/incidents/:id
+func (h *StatusHandler) UpdateIncident(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	incidentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid incident ID", err)
+		return
+	}
+
+	var req models.UpdateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	incident, err := h.incidentRepo.GetByID(c.Request.Context(), incidentID)
+	if err != nil {
+		if err.Error() == "incident not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch incident", err)
+		return
+	}
+
+	if req.Title != nil {
+		incident.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Message != nil {
+		incident.Message = strings.TrimSpace(*req.Message)
+	}
+	if req.Impact != nil {
+		incident.Impact = *req.Impact
+	}
+	if req.Resolved != nil {
+		switch {
+		case *req.Resolved && incident.ResolvedAt == nil:
+			now := time.Now().UTC()
+			incident.ResolvedAt = &now
+		case !*req.Resolved:
+			incident.ResolvedAt = nil
+		}
+	}
+
+	if err := h.incidentRepo.Update(c.Request.Context(), incident); err != nil {
+		if err.Error() == "incident not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to update incident", err)
+		return
+	}
+	h.cache.Delete(statusCacheKey)
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "update_incident", "system", &incident.ID, map[string]interface{}{
+		"impact":   incident.Impact,
+		"resolved": incident.ResolvedAt != nil,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, incident)
+}
+
+// DeleteIncident removes an incident, e.g. one opened by mistake
+// DELETE /api/admin/incidents/:id
+func (h *StatusHandler) DeleteIncident(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	incidentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid incident ID", err)
+		return
+	}
+
+	if err := h.incidentRepo.Delete(c.Request.Context(), incidentID); err != nil {
+		if err.Error() == "incident not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete incident", err)
+		return
+	}
+	h.cache.Delete(statusCacheKey)
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "delete_incident", "system", &incidentID, nil)
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "incident deleted successfully"})
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// TrustedClientHandler manages API keys with relaxed rate limits (admin only)
+type TrustedClientHandler struct {
+	clientRepo repositories.TrustedClientStore
+	adminRepo  repositories.AdminStore
+	tiers      *middleware.ClientTiers
+}
+
+// NewTrustedClientHandler creates a new trusted client handler
+func NewTrustedClientHandler(
+	clientRepo repositories.TrustedClientStore,
+	adminRepo repositories.AdminStore,
+	tiers *middleware.ClientTiers,
+) *TrustedClientHandler {
+	return &TrustedClientHandler{
+		clientRepo: clientRepo,
+		adminRepo:  adminRepo,
+		tiers:      tiers,
+	}
+}
+
+// generateClientKey returns a random API key; the prefix makes leaked keys easy to spot
+func generateClientKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "elo_" + hex.EncodeToString(b), nil
+}
+
+// ListTrustedClients returns all trusted clients; keys are never returned
+// GET /api/admin/trusted-clients
+func (h *TrustedClientHandler) ListTrustedClients(c *gin.Context) {
+	clients, err := h.clientRepo.List(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch trusted clients", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, clients)
+}
+
+// CreateTrustedClient registers a client and returns its API key once
+// The client sends the key in the X-Client-Key header
+// POST /api/admin/trusted-clients
+func (h *TrustedClientHandler) CreateTrustedClient(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req models.CreateTrustedClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	key, err := generateClientKey()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate key", err)
+		return
+	}
+
+	client := &models.TrustedClient{
+		Name:      strings.TrimSpace(req.Name),
+		KeyHash:   middleware.HashClientKey(key),
+		Tier:      req.Tier,
+		Active:    true,
+		CreatedBy: &adminID,
+	}
+	if err := h.clientRepo.Create(c.Request.Context(), client); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to create trusted client", err)
+		return
+	}
+	h.tiers.Invalidate()
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "create_trusted_client", "system", &client.ID, map[string]interface{}{
+		"name": client.Name,
+		"tier": client.Tier,
+	})
+
+	utils.RespondWithJSON(c, http.StatusCreated, gin.H{
+		"client": client,
+		"key":    key,
+	})
+}
+
+// UpdateTrustedClient changes the name, tier or active flag of a trusted client
+// PUT /api/admin/trusted-clients/:id
+func (h *TrustedClientHandler) UpdateTrustedClient(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	clientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid trusted client ID", err)
+		return
+	}
+
+	var req models.UpdateTrustedClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	client, err := h.clientRepo.GetByID(c.Request.Context(), clientID)
+	if err != nil {
+		if err.Error() == "trusted client not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch trusted client", err)
+		return
+	}
+
+	if req.Name != nil {
+		client.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Tier != nil {
+		client.Tier = *req.Tier
+	}
+	if req.Active != nil {
+		client.Active = *req.Active
+	}
+
+	if err := h.clientRepo.Update(c.Request.Context(), client); err != nil {
+		if err.Error() == "trusted client not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to update trusted client", err)
+		return
+	}
+	h.tiers.Invalidate()
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "update_trusted_client", "system", &client.ID, map[string]interface{}{
+		"name":   client.Name,
+		"tier":   client.Tier,
+		"active": client.Active,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, client)
+}
+
+// DeleteTrustedClient removes a trusted client; its key falls back to standard limits
+// DELETE /api/admin/trusted-clients/:id
+func (h *TrustedClientHandler) DeleteTrustedClient(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	clientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid trusted client ID", err)
+		return
+	}
+
+	if err := h.clientRepo.Delete(c.Request.Context(), clientID); err != nil {
+		if err.Error() == "trusted client not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete trusted client", err)
+		return
+	}
+	h.tiers.Invalidate()
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "delete_trusted_client", "system", &clientID, nil)
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "trusted client deleted successfully"})
+}
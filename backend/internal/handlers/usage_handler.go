@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler serves per-user API usage insights
+type UsageHandler struct {
+	usageRepo     repositories.UsageStore
+	sampleRate    float64
+	retentionDays int
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(usageRepo repositories.UsageStore, sampleRate float64, retentionDays int) *UsageHandler {
+	return &UsageHandler{
+		usageRepo:     usageRepo,
+		sampleRate:    sampleRate,
+		retentionDays: retentionDays,
+	}
+}
+
+// GetMyUsage returns the current user's request counts per endpoint
+// GET /api/users/me/usage?days=7
+func (h *UsageHandler) GetMyUsage(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	days, err := h.parseDays(c.Query("days"), 7)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	usage, err := h.usageRepo.GetUserUsage(c.Request.Context(), userID, usageSince(days))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get usage", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{
+		"days":        days,
+		"sample_rate": h.sampleRate,
+		"endpoints":   usage,
+	})
+}
+
+// GetUsageOverview returns the heaviest API users, those hitting rate limits first
+// GET /api/admin/usage?days=1&limit=50
+func (h *UsageHandler) GetUsageOverview(c *gin.Context) {
+	days, err := h.parseDays(c.Query("days"), 1)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	pagination := utils.ParsePaginationWithDefaults(c.Query("limit"), "", 50, 200)
+
+	users, err := h.usageRepo.GetTopUsers(c.Request.Context(), usageSince(days), pagination.Limit)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get usage overview", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{
+		"days":        days,
+		"sample_rate": h.sampleRate,
+		"users":       users,
+	})
+}
+
+// parseDays parses the window size, bounded by the retention period
+func (h *UsageHandler) parseDays(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 || days > h.retentionDays {
+		return 0, fmt.Errorf("days must be between 1 and %d", h.retentionDays)
+	}
+	return days, nil
+}
+
+// usageSince returns the first UTC day of a window of the given size, today included
+func usageSince(days int) time.Time {
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/realtime"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// UserNotificationHandler serves the current user's notification inbox
+type UserNotificationHandler struct {
+	notificationRepo repositories.UserNotificationStore
+	userRepo         repositories.UserStore
+	hub              *realtime.Hub
+}
+
+// NewUserNotificationHandler creates a new user notification handler
+func NewUserNotificationHandler(
+	notificationRepo repositories.UserNotificationStore,
+	userRepo repositories.UserStore,
+	hub *realtime.Hub,
+) *UserNotificationHandler {
+	return &UserNotificationHandler{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		hub:              hub,
+	}
+}
+
+// ListNotifications returns the current user's notifications, newest first
+// GET /api/users/me/notifications?unread=true&limit=50
+func (h *UserNotificationHandler) ListNotifications(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	unreadOnly := c.Query("unread") == "true"
+	pagination := utils.ParsePaginationWithDefaults(c.Query("limit"), "", 50, 100)
+
+	notifications, err := h.notificationRepo.ListForUser(c.Request.Context(), userID, unreadOnly, pagination.Limit)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch notifications", err)
+		return
+	}
+
+	unread, err := h.notificationRepo.CountUnread(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to count notifications", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{
+		"notifications": notifications,
+		"unread":        unread,
+	})
+}
+
+// MarkRead marks the given notifications as read, or all of them if no IDs are sent
+// POST /api/users/me/notifications/read
+func (h *UserNotificationHandler) MarkRead(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.MarkNotificationsReadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	marked, err := h.notificationRepo.MarkRead(c.Request.Context(), userID, req.IDs)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to mark notifications read", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"marked": marked})
+}
+
+// GetLanguage returns the language the current user's notifications are written in
+// GET /api/users/me/notifications/language
+func (h *UserNotificationHandler) GetLanguage(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	language, err := h.userRepo.GetLanguage(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch notification language", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"language": language})
+}
+
+// SetLanguage changes the language of the current user's future notifications
+// PUT /api/users/me/notifications/language
+func (h *UserNotificationHandler) SetLanguage(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.NotificationLanguageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if err := h.userRepo.SetLanguage(c.Request.Context(), userID, req.Language); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to update notification language", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"language": req.Language})
+}
+
+// SubscribeNotifications upgrades to a WebSocket that pushes new notifications of the current user
+// GET /api/users/me/notifications/ws
+func (h *UserNotificationHandler) SubscribeNotifications(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	// On failure the upgrader has already written an HTTP error response
+	if err := h.hub.Serve(c.Writer, c.Request, realtime.UserChannel(userID)); err != nil {
+		c.Abort()
+	}
+}
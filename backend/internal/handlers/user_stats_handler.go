@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// UserStatsHandler serves the per-sport profile aggregates kept in user_sports
+type UserStatsHandler struct {
+	userRepo       repositories.UserStore
+	userSportsRepo repositories.UserSportsStore
+	statsService   *services.StatsService
+	sportService   *services.SportService
+}
+
+// NewUserStatsHandler creates a new user stats handler
+func NewUserStatsHandler(
+	userRepo repositories.UserStore,
+	userSportsRepo repositories.UserSportsStore,
+	statsService *services.StatsService,
+	sportService *services.SportService,
+) *UserStatsHandler {
+	return &UserStatsHandler{
+		userRepo:       userRepo,
+		userSportsRepo: userSportsRepo,
+		statsService:   statsService,
+		sportService:   sportService,
+	}
+}
+
+// GetUserStats returns wins, losses, streaks and highest ELO per sport
+// GET /api/users/:id/stats
+func (h *UserStatsHandler) GetUserStats(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || utils.ValidateUserID(userID) != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid user ID", err)
+		return
+	}
+
+	if _, err := h.userRepo.GetByID(c.Request.Context(), userID); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "user not found", err)
+		return
+	}
+
+	stats, err := h.userSportsRepo.GetAllUserSports(c.Request.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to get user stats", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, stats)
+}
+
+// GetUserProfile returns a player's profile stats per sport: ELO, record, win rate,
+// streaks and most played rival
+// GET /api/users/:id/profile?sport=
+func (h *UserStatsHandler) GetUserProfile(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || utils.ValidateUserID(userID) != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid user ID", err)
+		return
+	}
+
+	var sport *string
+	if sportStr := c.Query("sport"); sportStr != "" {
+		if err := h.sportService.ValidateSportID(sportStr); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid sport", err)
+			return
+		}
+		sport = &sportStr
+	}
+
+	profile, err := h.statsService.GetPlayerProfile(c.Request.Context(), userID, sport)
+	if err != nil {
+		if err.Error() == "user not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		respondQueryError(c, "failed to get profile", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, profile)
+}
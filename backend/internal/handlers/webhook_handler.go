@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler manages outbound webhooks (admin only)
+type WebhookHandler struct {
+	webhookRepo  repositories.WebhookStore
+	deliveryRepo repositories.DeliveryStore
+	adminRepo    repositories.AdminStore
+	requireHTTPS bool
+}
+
+// NewWebhookHandler creates a new webhook handler
+// requireHTTPS rejects plain http URLs, set outside development
+func NewWebhookHandler(
+	webhookRepo repositories.WebhookStore,
+	deliveryRepo repositories.DeliveryStore,
+	adminRepo repositories.AdminStore,
+	requireHTTPS bool,
+) *WebhookHandler {
+	return &WebhookHandler{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		adminRepo:    adminRepo,
+		requireHTTPS: requireHTTPS,
+	}
+}
+
+// validateURL checks that a webhook URL is absolute and uses an allowed scheme
+func (h *WebhookHandler) validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("url must be absolute")
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if !h.requireHTTPS {
+			return nil
+		}
+	}
+	return fmt.Errorf("url must use https")
+}
+
+// generateWebhookSecret returns a random hex secret used to sign payloads
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ListWebhooks returns all registered webhooks; secrets are never returned
+// GET /api/admin/webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	hooks, err := h.webhookRepo.List(c.Request.Context())
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch webhooks", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, hooks)
+}
+
+// CreateWebhook registers a webhook and returns its signing secret once
+// POST /api/admin/webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if err := h.validateURL(req.URL); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to generate secret", err)
+		return
+	}
+
+	hook := &models.Webhook{
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      req.Events,
+		Description: strings.TrimSpace(req.Description),
+		Active:      true,
+		CreatedBy:   &adminID,
+	}
+	if err := h.webhookRepo.Create(c.Request.Context(), hook); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to create webhook", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "create_webhook", "system", &hook.ID, map[string]interface{}{
+		"url":    hook.URL,
+		"events": hook.Events,
+	})
+
+	utils.RespondWithJSON(c, http.StatusCreated, gin.H{
+		"webhook": hook,
+		"secret":  secret,
+	})
+}
+
+// UpdateWebhook changes the URL, events, description or active flag of a webhook
+// PUT /api/admin/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid webhook ID", err)
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	hook, err := h.webhookRepo.GetByID(c.Request.Context(), webhookID)
+	if err != nil {
+		if err.Error() == "webhook not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch webhook", err)
+		return
+	}
+
+	if req.URL != nil {
+		if err := h.validateURL(*req.URL); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		hook.URL = *req.URL
+	}
+	if req.Events != nil {
+		hook.Events = req.Events
+	}
+	if req.Description != nil {
+		hook.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+
+	if err := h.webhookRepo.Update(c.Request.Context(), hook); err != nil {
+		if err.Error() == "webhook not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to update webhook", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "update_webhook", "system", &hook.ID, map[string]interface{}{
+		"url":    hook.URL,
+		"events": hook.Events,
+		"active": hook.Active,
+	})
+
+	utils.RespondWithJSON(c, http.StatusOK, hook)
+}
+
+// DeleteWebhook removes a webhook
+// DELETE /api/admin/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid webhook ID", err)
+		return
+	}
+
+	if err := h.webhookRepo.Delete(c.Request.Context(), webhookID); err != nil {
+		if err.Error() == "webhook not found" {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to delete webhook", err)
+		return
+	}
+
+	h.adminRepo.LogAdminAction(c.Request.Context(), adminID, "delete_webhook", "system", &webhookID, nil)
+
+	utils.RespondWithJSON(c, http.StatusOK, gin.H{"message": "webhook deleted successfully"})
+}
+
+// ListWebhookDeliveries returns recent delivery attempts of one webhook for debugging
+// GET /api/admin/webhooks/:id/deliveries?status=failed&limit=50
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid webhook ID", err)
+		return
+	}
+
+	var status *string
+	if st := c.Query("status"); st != "" {
+		if st != models.DeliveryDelivered && st != models.DeliveryFailed {
+			utils.RespondWithError(c, http.StatusBadRequest, "status must be 'delivered' or 'failed'", nil)
+			return
+		}
+		status = &st
+	}
+
+	pagination := utils.ParsePaginationWithDefaults(c.Query("limit"), "", 50, 200)
+
+	channel := notifications.WebhookChannel(webhookID)
+	deliveries, err := h.deliveryRepo.ListRecent(c.Request.Context(), &channel, status, pagination.Limit)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "failed to fetch deliveries", err)
+		return
+	}
+
+	utils.RespondWithJSON(c, http.StatusOK, deliveries)
+}
@@ -0,0 +1,45 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+)
+
+// TestAdjustELORanksPlayer checks that ELO adjustments and penalties move the player on the
+// leaderboard in their own transaction, without a later re-rank
+func TestAdjustELORanksPlayer(t *testing.T) {
+	const sport = "table_tennis"
+	player := createUser(t, 900051, "adjusted_player")
+	admin := createUser(t, 900052, "adjusting_admin")
+
+	adjustment, err := testApp.Repos.Admin.AdjustELO(context.Background(), player.ID, sport, 1400, "tournament result", admin.ID)
+	if err != nil {
+		t.Fatalf("AdjustELO: %v", err)
+	}
+	if got := rankedELO(t, sport, player.ID); got != adjustment.NewELO {
+		t.Fatalf("ranked ELO after adjustment = %d, want %d", got, adjustment.NewELO)
+	}
+	checkRankings(t, sport)
+
+	penalty, err := testApp.Repos.Admin.PenalizeELO(context.Background(), player.ID, sport, 150, "unsporting behaviour", admin.ID)
+	if err != nil {
+		t.Fatalf("PenalizeELO: %v", err)
+	}
+	if got := rankedELO(t, sport, player.ID); got != penalty.NewELO {
+		t.Fatalf("ranked ELO after penalty = %d, want %d", got, penalty.NewELO)
+	}
+	checkRankings(t, sport)
+}
+
+// rankedELO returns the rating a player is ranked with, read past the leaderboard cache
+func rankedELO(t *testing.T, sport string, userID int) int {
+	t.Helper()
+	var elo int
+	err := testApp.DB.QueryRow(`SELECT elo FROM leaderboard_rankings WHERE sport_id = $1 AND user_id = $2`, sport, userID).Scan(&elo)
+	if err != nil {
+		t.Fatalf("ranked ELO of user %d: %v", userID, err)
+	}
+	return elo
+}
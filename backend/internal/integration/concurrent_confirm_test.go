@@ -0,0 +1,71 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// TestConcurrentConfirmations confirms matches of the same sport at the same time and checks
+// that every confirmation succeeds, that a match is only applied once and that the rankings
+// match a full re-rank afterwards
+func TestConcurrentConfirmations(t *testing.T) {
+	const sport = "table_football"
+	const pairs = 4
+
+	var matches []*models.Match
+	var opponents []*models.User
+	for i := 0; i < pairs; i++ {
+		submitter := createUser(t, 900031+2*i, fmt.Sprintf("concurrent_submitter_%d", i))
+		opponent := createUser(t, 900032+2*i, fmt.Sprintf("concurrent_opponent_%d", i))
+		match, err := testApp.Services.Match.SubmitMatch(context.Background(), &models.SubmitMatchRequest{
+			Sport:         sport,
+			OpponentID:    opponent.ID,
+			PlayerScore:   10,
+			OpponentScore: 4 + i,
+		}, submitter.ID, "")
+		if err != nil {
+			t.Fatalf("SubmitMatch: %v", err)
+		}
+		matches = append(matches, match)
+		opponents = append(opponents, opponent)
+	}
+	checkRankings(t, sport)
+
+	// Every match is confirmed twice at once; exactly one confirmation of each may apply
+	errs := make([]error, 2*pairs)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = testApp.Services.Match.ConfirmMatch(context.Background(), matches[i/2].ID, opponents[i/2].ID, "")
+		}(i)
+	}
+	wg.Wait()
+
+	for i, match := range matches {
+		first, second := errs[2*i], errs[2*i+1]
+		if (first == nil) == (second == nil) {
+			t.Fatalf("confirmations of match %d returned %v and %v, want exactly one success", match.ID, first, second)
+		}
+		failed := first
+		if failed == nil {
+			failed = second
+		}
+		if !errors.Is(failed, repositories.ErrMatchStatusChanged) && failed.Error() != "match is not pending" {
+			t.Fatalf("second confirmation of match %d: %v", match.ID, failed)
+		}
+		if n := eloHistoryCount(t, match.ID, models.ELOSourceMatch); n != 2 {
+			t.Fatalf("ELO history entries for match %d = %d, want 2", match.ID, n)
+		}
+	}
+	checkRankings(t, sport)
+}
@@ -0,0 +1,6 @@
+// Package integration holds end-to-end tests that run against real Postgres and
+// Redis containers started through dockertest. They need a Docker daemon and are
+// excluded from the regular test run:
+//
+//	go test -tags integration ./internal/integration/...
+package integration
@@ -0,0 +1,84 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// TestLegalAcceptance publishes versions of the terms in two languages and checks which
+// version a user still has to accept after each acceptance
+func TestLegalAcceptance(t *testing.T) {
+	user := createUser(t, 900021, "legal_user")
+	admin := createUser(t, 900022, "legal_admin")
+	if err := testApp.Repos.User.SetLanguage(context.Background(), user.ID, "en"); err != nil {
+		t.Fatalf("SetLanguage: %v", err)
+	}
+
+	publishTerms(t, "de", admin.ID)
+	enV1 := publishTerms(t, "en", admin.ID)
+	wantPending(t, user.ID, enV1)
+
+	if _, err := testApp.Repos.Legal.Accept(context.Background(), user.ID, enV1); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	wantPending(t, user.ID)
+
+	// A new German version does not change the current English one
+	deV2 := publishTerms(t, "de", admin.ID)
+	wantPending(t, user.ID)
+
+	// Accepting an older version in another language does not cover a newer English one
+	enV2 := publishTerms(t, "en", admin.ID)
+	if _, err := testApp.Repos.Legal.Accept(context.Background(), user.ID, deV2); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	wantPending(t, user.ID, enV2)
+
+	if _, err := testApp.Repos.Legal.Accept(context.Background(), user.ID, enV2); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	wantPending(t, user.ID)
+
+	accepted, err := testApp.Repos.Legal.ListAcceptances(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("ListAcceptances: %v", err)
+	}
+	if len(accepted) != 3 {
+		t.Fatalf("accepted %d versions, want 3", len(accepted))
+	}
+}
+
+func publishTerms(t *testing.T, lang string, adminID int) *models.LegalDocument {
+	t.Helper()
+	document := &models.LegalDocument{
+		Doc:         models.LegalNutzungsbedingungen,
+		Lang:        lang,
+		Title:       "Nutzungsbedingungen",
+		Content:     "Terms of use for the leaderboard",
+		PublishedBy: &adminID,
+	}
+	if err := testApp.Repos.Legal.Publish(context.Background(), document); err != nil {
+		t.Fatalf("Publish(%s): %v", lang, err)
+	}
+	return document
+}
+
+func wantPending(t *testing.T, userID int, want ...*models.LegalDocument) {
+	t.Helper()
+	pending, err := testApp.Repos.Legal.ListPending(context.Background(), userID, "en", models.LegalAcceptanceRequired)
+	if err != nil {
+		t.Fatalf("ListPending: %v", err)
+	}
+	if len(pending) != len(want) {
+		t.Fatalf("pending = %+v, want %d documents", pending, len(want))
+	}
+	for i, doc := range want {
+		if pending[i].ID != doc.ID {
+			t.Fatalf("pending[%d] = %s %s v%d, want %s v%d", i, pending[i].Doc, pending[i].Lang, pending[i].Version, doc.Lang, doc.Version)
+		}
+	}
+}
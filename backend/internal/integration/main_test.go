@@ -0,0 +1,125 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/app"
+	"github.com/42heilbronn/elo-leaderboard/internal/config"
+	_ "github.com/lib/pq"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/redis/go-redis/v9"
+)
+
+// testApp is wired like the CLI (database, repositories, cache, services) against the containers
+var testApp *app.App
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Failed to connect to Docker: %v", err)
+	}
+	pool.MaxWait = 2 * time.Minute
+
+	postgres, err := startContainer(pool, "postgres", "15-alpine", []string{
+		"POSTGRES_USER=elo",
+		"POSTGRES_PASSWORD=elo",
+		"POSTGRES_DB=elo_test",
+	})
+	if err != nil {
+		log.Fatalf("Failed to start Postgres: %v", err)
+	}
+	defer pool.Purge(postgres)
+
+	redisContainer, err := startContainer(pool, "redis", "7-alpine", nil)
+	if err != nil {
+		log.Fatalf("Failed to start Redis: %v", err)
+	}
+	defer pool.Purge(redisContainer)
+
+	databaseURL := fmt.Sprintf("postgres://elo:elo@%s/elo_test?sslmode=disable", postgres.GetHostPort("5432/tcp"))
+	redisURL := fmt.Sprintf("redis://%s/0", redisContainer.GetHostPort("6379/tcp"))
+
+	if err := pool.Retry(func() error {
+		db, err := sql.Open("postgres", databaseURL)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return db.Ping()
+	}); err != nil {
+		log.Fatalf("Postgres did not become ready: %v", err)
+	}
+
+	if err := pool.Retry(func() error {
+		opts, err := redis.ParseURL(redisURL)
+		if err != nil {
+			return err
+		}
+		client := redis.NewClient(opts)
+		defer client.Close()
+		return client.Ping(context.Background()).Err()
+	}); err != nil {
+		log.Fatalf("Redis did not become ready: %v", err)
+	}
+
+	// Configuration goes through config.Load so the defaults match a real deployment
+	for key, value := range map[string]string{
+		"APP_ENV":          "development",
+		"DATABASE_URL":     databaseURL,
+		"REDIS_URL":        redisURL,
+		"CACHE_BACKEND":    "redis",
+		"JWT_SECRET":       "integration-test-secret-with-32-chars!",
+		"FT_CLIENT_UID":    "integration",
+		"FT_CLIENT_SECRET": "integration",
+		"FT_REDIRECT_URI":  "http://localhost:8080/api/auth/callback",
+		"FRONTEND_URL":     "http://localhost:5173",
+		"PUBLIC_API_URL":   "http://localhost:8080",
+	} {
+		os.Setenv(key, value)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Migrations run as part of the database step
+	testApp, err = app.NewCLI(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize app: %v", err)
+	}
+	defer testApp.Close()
+
+	return m.Run()
+}
+
+// startContainer runs a throwaway container that is removed when it stops
+func startContainer(pool *dockertest.Pool, repository, tag string, env []string) (*dockertest.Resource, error) {
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: repository,
+		Tag:        tag,
+		Env:        env,
+	}, func(hc *docker.HostConfig) {
+		hc.AutoRemove = true
+		hc.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Safety net if the test binary is killed before the deferred purge
+	resource.Expire(600)
+	return resource, nil
+}
@@ -0,0 +1,60 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// TestFingerprintAnomaliesPaging confirms matches from the submitter's device and checks that
+// the second page of the anomaly queue continues where the first one ended
+func TestFingerprintAnomaliesPaging(t *testing.T) {
+	const fingerprint = "anomaly-test-device"
+	for i := 0; i < 3; i++ {
+		submitter := createUser(t, 900061+2*i, fmt.Sprintf("anomaly_submitter_%d", i))
+		opponent := createUser(t, 900062+2*i, fmt.Sprintf("anomaly_opponent_%d", i))
+		match, err := testApp.Services.Match.SubmitMatch(context.Background(), &models.SubmitMatchRequest{
+			Sport:         "table_tennis",
+			OpponentID:    opponent.ID,
+			PlayerScore:   11,
+			OpponentScore: 3,
+		}, submitter.ID, fingerprint)
+		if err != nil {
+			t.Fatalf("SubmitMatch: %v", err)
+		}
+		if err := testApp.Services.Match.ConfirmMatch(context.Background(), match.ID, opponent.ID, fingerprint); err != nil {
+			t.Fatalf("ConfirmMatch: %v", err)
+		}
+	}
+
+	all, err := testApp.Repos.Admin.GetFingerprintAnomalies(context.Background(), 200, 0)
+	if err != nil {
+		t.Fatalf("GetFingerprintAnomalies: %v", err)
+	}
+	if len(all) < 3 {
+		t.Fatalf("anomalies = %d, want at least 3", len(all))
+	}
+
+	first, err := testApp.Repos.Admin.GetFingerprintAnomalies(context.Background(), 2, 0)
+	if err != nil {
+		t.Fatalf("GetFingerprintAnomalies(page 1): %v", err)
+	}
+	second, err := testApp.Repos.Admin.GetFingerprintAnomalies(context.Background(), 2, 2)
+	if err != nil {
+		t.Fatalf("GetFingerprintAnomalies(page 2): %v", err)
+	}
+	if len(first) != 2 || len(second) == 0 {
+		t.Fatalf("pages have %d and %d anomalies, want 2 and at least 1", len(first), len(second))
+	}
+	for i, page := range [][]models.MatchAnomaly{first, second} {
+		for j, anomaly := range page {
+			if want := all[2*i+j].ID; anomaly.ID != want {
+				t.Fatalf("page %d, entry %d is match %d, want %d", i+1, j+1, anomaly.ID, want)
+			}
+		}
+	}
+}
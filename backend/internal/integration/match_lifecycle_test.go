@@ -0,0 +1,211 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// TestMatchLifecycle walks a match through submit, confirm, admin revert and restore and
+// checks the database and the (Redis-cached) leaderboard after every step
+func TestMatchLifecycle(t *testing.T) {
+	const sport = "table_tennis"
+	winner := createUser(t, 900001, "lifecycle_winner")
+	loser := createUser(t, 900002, "lifecycle_loser")
+	admin := createUser(t, 900003, "lifecycle_admin")
+	checkRankings(t, sport)
+
+	// Warm the leaderboard cache so stale entries would show up below
+	startELO := leaderboardEntry(t, sport, winner.ID).ELO
+	if other := leaderboardEntry(t, sport, loser.ID).ELO; other != startELO {
+		t.Fatalf("new players start with different ELO: %d and %d", startELO, other)
+	}
+
+	// Submit: pending, nothing applied yet
+	match, err := testApp.Services.Match.SubmitMatch(context.Background(), &models.SubmitMatchRequest{
+		Sport:         sport,
+		OpponentID:    loser.ID,
+		PlayerScore:   11,
+		OpponentScore: 7,
+	}, winner.ID, "")
+	if err != nil {
+		t.Fatalf("SubmitMatch: %v", err)
+	}
+	if match.Status != models.StatusPending {
+		t.Fatalf("submitted match status = %q, want %q", match.Status, models.StatusPending)
+	}
+	if match.WinnerID != winner.ID {
+		t.Fatalf("winner = %d, want %d", match.WinnerID, winner.ID)
+	}
+	if got := sportStats(t, winner.ID, sport); got.MatchesPlayed != 0 || got.CurrentELO != startELO {
+		t.Fatalf("pending match changed stats: %+v", got)
+	}
+
+	// Confirm: ELO and stats applied in one transaction
+	if err := testApp.Services.Match.ConfirmMatch(context.Background(), match.ID, winner.ID, ""); err == nil {
+		t.Fatal("submitter could confirm their own match")
+	}
+	if err := testApp.Services.Match.ConfirmMatch(context.Background(), match.ID, loser.ID, ""); err != nil {
+		t.Fatalf("ConfirmMatch: %v", err)
+	}
+
+	confirmed, err := testApp.Repos.Match.GetByID(context.Background(), match.ID)
+	if err != nil {
+		t.Fatalf("GetByID after confirm: %v", err)
+	}
+	if confirmed.Status != models.StatusConfirmed || confirmed.ConfirmedAt == nil {
+		t.Fatalf("confirmed match = status %q, confirmed_at %v", confirmed.Status, confirmed.ConfirmedAt)
+	}
+	if confirmed.Player1ELODelta == nil || confirmed.Player2ELODelta == nil {
+		t.Fatal("confirmed match has no ELO deltas")
+	}
+	// A confirmation decided on the pending match it read before must not apply a second time
+	err = testApp.Repos.Match.ConfirmMatch(context.Background(), nil, match.ID, models.StatusPending, map[string]int{}, nil)
+	if !errors.Is(err, repositories.ErrMatchStatusChanged) {
+		t.Fatalf("second ConfirmMatch = %v, want ErrMatchStatusChanged", err)
+	}
+	gain, loss := *confirmed.Player1ELODelta, *confirmed.Player2ELODelta
+	if gain <= 0 || loss >= 0 || gain != -loss {
+		t.Fatalf("ELO deltas = %+d / %+d, want a symmetric gain for the winner", gain, loss)
+	}
+
+	winnerStats := sportStats(t, winner.ID, sport)
+	loserStats := sportStats(t, loser.ID, sport)
+	if winnerStats.CurrentELO != startELO+gain || loserStats.CurrentELO != startELO+loss {
+		t.Fatalf("ELO after confirm = %d / %d, want %d / %d", winnerStats.CurrentELO, loserStats.CurrentELO, startELO+gain, startELO+loss)
+	}
+	if winnerStats.Wins != 1 || winnerStats.Losses != 0 || winnerStats.CurrentStreak != 1 {
+		t.Fatalf("winner stats after confirm: %+v", winnerStats)
+	}
+	if loserStats.Wins != 0 || loserStats.Losses != 1 || loserStats.CurrentStreak != -1 {
+		t.Fatalf("loser stats after confirm: %+v", loserStats)
+	}
+	if n := eloHistoryCount(t, match.ID, models.ELOSourceMatch); n != 2 {
+		t.Fatalf("ELO history entries for the match = %d, want 2", n)
+	}
+	checkRankings(t, sport)
+
+	// Leaderboard: confirmation invalidates the cache
+	winnerEntry := leaderboardEntry(t, sport, winner.ID)
+	loserEntry := leaderboardEntry(t, sport, loser.ID)
+	if winnerEntry.ELO != winnerStats.CurrentELO || loserEntry.ELO != loserStats.CurrentELO {
+		t.Fatalf("leaderboard ELO = %d / %d, want %d / %d", winnerEntry.ELO, loserEntry.ELO, winnerStats.CurrentELO, loserStats.CurrentELO)
+	}
+	if winnerEntry.Rank >= loserEntry.Rank {
+		t.Fatalf("winner ranked #%d behind loser #%d", winnerEntry.Rank, loserEntry.Rank)
+	}
+
+	// Revert: ratings restored, match soft-deleted, stats recomputed
+	if err := testApp.Repos.Admin.RevertMatch(context.Background(), match.ID, admin.ID); err != nil {
+		t.Fatalf("RevertMatch: %v", err)
+	}
+	testApp.Services.Match.InvalidateLeaderboardCache()
+
+	if _, err := testApp.Repos.Match.GetByID(context.Background(), match.ID); err == nil {
+		t.Fatal("reverted match still exists")
+	}
+	for _, userID := range []int{winner.ID, loser.ID} {
+		stats := sportStats(t, userID, sport)
+		if stats.CurrentELO != startELO || stats.MatchesPlayed != 0 || stats.CurrentStreak != 0 {
+			t.Fatalf("stats of user %d after revert: %+v", userID, stats)
+		}
+		if entry := leaderboardEntry(t, sport, userID); entry.ELO != startELO {
+			t.Fatalf("leaderboard ELO of user %d after revert = %d, want %d", userID, entry.ELO, startELO)
+		}
+	}
+	if n := eloHistoryCount(t, match.ID, models.ELOSourceRevert); n != 2 {
+		t.Fatalf("ELO history revert entries = %d, want 2", n)
+	}
+	checkRankings(t, sport)
+
+	// Restore: match back, ELO changes reapplied, stats recomputed
+	reverted, err := testApp.Repos.Admin.RestoreMatch(context.Background(), match.ID)
+	if err != nil {
+		t.Fatalf("RestoreMatch: %v", err)
+	}
+	if !reverted {
+		t.Fatal("restored match was not marked as reverted")
+	}
+	testApp.Services.Match.InvalidateLeaderboardCache()
+
+	if _, err := testApp.Repos.Match.GetByID(context.Background(), match.ID); err != nil {
+		t.Fatalf("GetByID after restore: %v", err)
+	}
+	for _, player := range []struct {
+		userID int
+		delta  *int
+	}{
+		{confirmed.Player1ID, confirmed.Player1ELODelta},
+		{confirmed.Player2ID, confirmed.Player2ELODelta},
+	} {
+		stats := sportStats(t, player.userID, sport)
+		if stats.CurrentELO != startELO+*player.delta || stats.MatchesPlayed != 1 {
+			t.Fatalf("stats of user %d after restore: %+v", player.userID, stats)
+		}
+	}
+	if n := eloHistoryCount(t, match.ID, models.ELOSourceRestore); n != 2 {
+		t.Fatalf("ELO history restore entries = %d, want 2", n)
+	}
+	checkRankings(t, sport)
+}
+
+func createUser(t *testing.T, id int, login string) *models.User {
+	t.Helper()
+	user := &models.User{IntraID: id, Login: login, DisplayName: login, Campus: "42heilbronn"}
+	if _, err := testApp.Repos.User.CreateOrUpdate(context.Background(), user); err != nil {
+		t.Fatalf("CreateOrUpdate(%s): %v", login, err)
+	}
+	return user
+}
+
+func sportStats(t *testing.T, userID int, sport string) *repositories.UserSportData {
+	t.Helper()
+	data, err := testApp.Repos.UserSports.GetUserSportStats(context.Background(), userID, sport)
+	if err != nil {
+		t.Fatalf("GetUserSportStats(%d): %v", userID, err)
+	}
+	return data
+}
+
+func leaderboardEntry(t *testing.T, sport string, userID int) models.LeaderboardEntry {
+	t.Helper()
+	entries, err := testApp.Services.Match.GetLeaderboard(context.Background(), sport)
+	if err != nil {
+		t.Fatalf("GetLeaderboard: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.User.ID == userID {
+			return entry
+		}
+	}
+	t.Fatalf("user %d missing from the %s leaderboard", userID, sport)
+	return models.LeaderboardEntry{}
+}
+
+// checkRankings fails the test if the rankings kept up to date by the changes so far differ
+// from a full re-rank of the sport
+func checkRankings(t *testing.T, sport string) {
+	t.Helper()
+	changed, err := testApp.Repos.Ranking.Refresh(context.Background(), nil, sport)
+	if err != nil {
+		t.Fatalf("Refresh(%s): %v", sport, err)
+	}
+	if changed != 0 {
+		t.Fatalf("%d %s rankings were out of date", changed, sport)
+	}
+}
+
+func eloHistoryCount(t *testing.T, matchID int, source string) int {
+	t.Helper()
+	var n int
+	err := testApp.DB.QueryRow(`SELECT COUNT(*) FROM elo_history WHERE match_id = $1 AND source = $2`, matchID, source).Scan(&n)
+	if err != nil {
+		t.Fatalf("count ELO history: %v", err)
+	}
+	return n
+}
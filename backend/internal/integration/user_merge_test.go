@@ -0,0 +1,157 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// TestMergeUsers merges a duplicate profile with a confirmed match and a comment into a fresh
+// profile and checks that history, records and the login link follow
+func TestMergeUsers(t *testing.T) {
+	const sport = "table_tennis"
+	duplicate := createUser(t, 900011, "merge_duplicate")
+	kept := createUser(t, 900012, "merge_kept")
+	opponent := createUser(t, 900013, "merge_opponent")
+	admin := createUser(t, 900014, "merge_admin")
+
+	match, err := testApp.Services.Match.SubmitMatch(context.Background(), &models.SubmitMatchRequest{
+		Sport:         sport,
+		OpponentID:    opponent.ID,
+		PlayerScore:   11,
+		OpponentScore: 5,
+	}, duplicate.ID, "")
+	if err != nil {
+		t.Fatalf("SubmitMatch: %v", err)
+	}
+	if err := testApp.Services.Match.ConfirmMatch(context.Background(), match.ID, opponent.ID, ""); err != nil {
+		t.Fatalf("ConfirmMatch: %v", err)
+	}
+	if err := testApp.Repos.Comment.Add(context.Background(), &models.Comment{MatchID: match.ID, UserID: duplicate.ID, Content: "good game"}); err != nil {
+		t.Fatalf("Add comment: %v", err)
+	}
+	duplicateELO := sportStats(t, duplicate.ID, sport).CurrentELO
+
+	result, err := testApp.Repos.User.MergeUsers(context.Background(), duplicate.ID, kept.ID, admin.ID)
+	if err != nil {
+		t.Fatalf("MergeUsers: %v", err)
+	}
+	if result.Matches != 1 || result.Comments != 1 {
+		t.Fatalf("merge result = %+v, want 1 match and 1 comment", result)
+	}
+
+	merged, err := testApp.Repos.Match.GetByID(context.Background(), match.ID)
+	if err != nil {
+		t.Fatalf("GetByID after merge: %v", err)
+	}
+	if merged.Player1ID != kept.ID || merged.WinnerID != kept.ID || merged.SubmittedBy != kept.ID {
+		t.Fatalf("merged match = player1 %d, winner %d, submitter %d, want %d", merged.Player1ID, merged.WinnerID, merged.SubmittedBy, kept.ID)
+	}
+
+	// The kept profile had not played the sport, so it takes over the duplicate's rating
+	stats := sportStats(t, kept.ID, sport)
+	if stats.CurrentELO != duplicateELO || stats.MatchesPlayed != 1 || stats.Wins != 1 {
+		t.Fatalf("kept profile stats after merge: %+v", stats)
+	}
+
+	if _, err := testApp.Repos.User.GetByID(context.Background(), duplicate.ID); err == nil {
+		t.Fatal("duplicate profile still exists after merge")
+	}
+	checkRankings(t, sport)
+	linked, ok, err := testApp.Repos.User.GetLinkedUserID(context.Background(), duplicate.ID)
+	if err != nil || !ok || linked != kept.ID {
+		t.Fatalf("GetLinkedUserID(%d) = %d, %v, %v, want %d", duplicate.ID, linked, ok, err, kept.ID)
+	}
+
+	// The kept profile now shares a match with the opponent
+	if _, err := testApp.Repos.User.MergeUsers(context.Background(), opponent.ID, kept.ID, admin.ID); !errors.Is(err, repositories.ErrUsersPlayedEachOther) {
+		t.Fatalf("merging opponents: err = %v, want %v", err, repositories.ErrUsersPlayedEachOther)
+	}
+}
+
+// TestMergeUsersWithSharedSport checks that profiles with ratings in the same sport are not
+// merged, and that an unplayed rating of the kept profile gives way to the duplicate's
+func TestMergeUsersWithSharedSport(t *testing.T) {
+	const sport = "table_tennis"
+	duplicate := createUser(t, 900041, "shared_duplicate")
+	kept := createUser(t, 900042, "shared_kept")
+	opponent := createUser(t, 900043, "shared_opponent")
+	admin := createUser(t, 900044, "shared_admin")
+
+	confirm := func(sport string, winner, loser *models.User) *models.Match {
+		t.Helper()
+		match, err := testApp.Services.Match.SubmitMatch(context.Background(), &models.SubmitMatchRequest{
+			Sport:         sport,
+			OpponentID:    loser.ID,
+			PlayerScore:   10,
+			OpponentScore: 5,
+		}, winner.ID, "")
+		if err != nil {
+			t.Fatalf("SubmitMatch: %v", err)
+		}
+		if err := testApp.Services.Match.ConfirmMatch(context.Background(), match.ID, loser.ID, ""); err != nil {
+			t.Fatalf("ConfirmMatch: %v", err)
+		}
+		return match
+	}
+	duplicateMatch := confirm(sport, duplicate, opponent)
+	confirm(sport, kept, opponent)
+	duplicateELO := sportStats(t, duplicate.ID, sport).CurrentELO
+	keptELO := sportStats(t, kept.ID, sport).CurrentELO
+
+	_, err := testApp.Repos.User.MergeUsers(context.Background(), duplicate.ID, kept.ID, admin.ID)
+	if !errors.Is(err, repositories.ErrUsersShareSports) {
+		t.Fatalf("merging profiles with shared sport: err = %v, want %v", err, repositories.ErrUsersShareSports)
+	}
+
+	// Nothing of either profile was moved or dropped
+	if got := sportStats(t, duplicate.ID, sport).CurrentELO; got != duplicateELO {
+		t.Fatalf("duplicate's rating after rejected merge = %d, want %d", got, duplicateELO)
+	}
+	if got := sportStats(t, kept.ID, sport).CurrentELO; got != keptELO {
+		t.Fatalf("kept profile's rating after rejected merge = %d, want %d", got, keptELO)
+	}
+	if n := eloHistoryCount(t, duplicateMatch.ID, models.ELOSourceMatch); n != 2 {
+		t.Fatalf("ELO history entries of the duplicate's match = %d, want 2", n)
+	}
+	checkRankings(t, sport)
+
+	// A rating the kept profile never played does not block the merge and is replaced
+	const otherSport = "table_football"
+	otherDuplicate := createUser(t, 900045, "shared_other_duplicate")
+	otherMatch := confirm(otherSport, otherDuplicate, opponent)
+	otherELO := sportStats(t, otherDuplicate.ID, otherSport).CurrentELO
+
+	tx, err := testApp.DB.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err := testApp.Repos.UserSports.EnsureUserSportExists(context.Background(), tx, kept.ID, otherSport, 1000); err != nil {
+		t.Fatalf("EnsureUserSportExists: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	result, err := testApp.Repos.User.MergeUsers(context.Background(), otherDuplicate.ID, kept.ID, admin.ID)
+	if err != nil {
+		t.Fatalf("MergeUsers: %v", err)
+	}
+	if len(result.Sports) != 1 || result.Sports[0] != otherSport {
+		t.Fatalf("moved ratings = %v, want [%s]", result.Sports, otherSport)
+	}
+	if stats := sportStats(t, kept.ID, otherSport); stats.CurrentELO != otherELO || stats.MatchesPlayed != 1 {
+		t.Fatalf("kept profile's %s stats after merge: %+v", otherSport, stats)
+	}
+	var history int
+	err = testApp.DB.QueryRow(`SELECT COUNT(*) FROM elo_history WHERE match_id = $1 AND user_id = $2`, otherMatch.ID, kept.ID).Scan(&history)
+	if err != nil || history != 1 {
+		t.Fatalf("kept profile's ELO history of the merged match = %d, %v, want 1", history, err)
+	}
+	checkRankings(t, otherSport)
+}
@@ -0,0 +1,153 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+)
+
+// EventDigest marks digest posts in the delivery log
+const EventDigest = "digest"
+
+// digestAnnouncement fills the digest template
+type digestAnnouncement struct {
+	Sport      string
+	Period     string // models.DigestDaily or models.DigestWeekly
+	Label      string // e.g. "2024-05-01" or "2024-W18"
+	Matches    int
+	Upsets     []digestUpsetLine
+	MostActive []digestActiveLine
+	RankMovers []digestMoverLine
+}
+
+type digestUpsetLine struct {
+	Winner      string
+	Loser       string
+	WinnerScore int
+	LoserScore  int
+	Gap         int // rating difference before the match
+}
+
+type digestActiveLine struct {
+	Name    string
+	Matches int
+	Wins    int
+}
+
+type digestMoverLine struct {
+	Name   string
+	Rank   int
+	Change int // positive = climbed
+}
+
+// DigestPoster posts the digests of one period to the sport's Discord channel and to Slack
+// Names follow the Discord announcer: anonymous unless logins are configured
+type DigestPoster struct {
+	discord      *DiscordAnnouncer
+	slackChannel string // notifier channel of the Slack webhook, "" if none
+	period       string // digests of other periods are not posted
+}
+
+// NewDigestPoster creates a poster for digests of period ("" disables posting)
+func NewDigestPoster(discord *DiscordAnnouncer, slackChannel, period string) *DigestPoster {
+	return &DigestPoster{discord: discord, slackChannel: slackChannel, period: period}
+}
+
+// Enabled reports whether digests are posted anywhere
+func (p *DigestPoster) Enabled() bool {
+	return p.period != "" && (p.discord.Enabled() || p.slackChannel != "")
+}
+
+// Post sends a digest to every configured channel; digests without matches are skipped
+// Failures are recorded in the delivery log and returned together
+func (p *DigestPoster) Post(ctx context.Context, digest *models.Digest) error {
+	if digest.Period != p.period || digest.Matches == 0 {
+		return nil
+	}
+
+	channels := map[string]string{} // channel -> message format
+	if channel := p.discord.channelFor(digest.Sport); channel != "" {
+		channels[channel] = notifications.FormatDiscord
+	}
+	if p.slackChannel != "" {
+		channels[p.slackChannel] = notifications.FormatDefault
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	announcement, err := p.announcement(ctx, digest)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for channel, format := range channels {
+		msg, err := p.discord.templates.Render(p.discord.cfg.Language, format, EventDigest, announcement)
+		if err != nil {
+			return err
+		}
+		msg.URL = p.discord.leaderboardURL(digest.Sport)
+
+		delivery, err := p.discord.notifier.Send(ctx, channel, EventDigest+":"+digest.Sport, msg)
+		if err != nil || delivery.Status != models.DeliveryDelivered {
+			failed = append(failed, channel)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("digest delivery failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// announcement resolves the players of a digest to the names they are announced with
+func (p *DigestPoster) announcement(ctx context.Context, digest *models.Digest) (digestAnnouncement, error) {
+	var ids []int
+	for _, u := range digest.Upsets {
+		ids = append(ids, u.WinnerID, u.LoserID)
+	}
+	for _, a := range digest.MostActive {
+		ids = append(ids, a.UserID)
+	}
+	for _, m := range digest.RankMovers {
+		ids = append(ids, m.UserID)
+	}
+	users, err := p.discord.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return digestAnnouncement{}, err
+	}
+	names, err := p.discord.displayNames(ctx, users)
+	if err != nil {
+		return digestAnnouncement{}, err
+	}
+
+	label := digest.PeriodStart.Format("2006-01-02")
+	if digest.Period == models.DigestWeekly {
+		label = isoWeek(digest.PeriodStart)
+	}
+	announcement := digestAnnouncement{
+		Sport:   p.discord.sportName(digest.Sport),
+		Period:  digest.Period,
+		Label:   label,
+		Matches: digest.Matches,
+	}
+	for _, u := range digest.Upsets {
+		announcement.Upsets = append(announcement.Upsets, digestUpsetLine{
+			Winner:      names[u.WinnerID],
+			Loser:       names[u.LoserID],
+			WinnerScore: u.WinnerScore,
+			LoserScore:  u.LoserScore,
+			Gap:         u.LoserELOBefore - u.WinnerELOBefore,
+		})
+	}
+	for _, a := range digest.MostActive {
+		announcement.MostActive = append(announcement.MostActive, digestActiveLine{Name: names[a.UserID], Matches: a.Matches, Wins: a.Wins})
+	}
+	for _, m := range digest.RankMovers {
+		announcement.RankMovers = append(announcement.RankMovers, digestMoverLine{Name: names[m.UserID], Rank: m.Rank, Change: m.Change})
+	}
+	return announcement, nil
+}
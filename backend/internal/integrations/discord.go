@@ -0,0 +1,375 @@
+// Package integrations posts leaderboard activity to third-party services.
+//
+// Delivery goes through the notifications package, so every post shows up in
+// the admin delivery log like any other notification.
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+)
+
+const (
+	// EventWeeklySummary marks weekly leaderboard summaries in the delivery log
+	EventWeeklySummary = "leaderboard.weekly_summary"
+
+	// weeklySummaryDay and weeklySummaryHour set when the summary is posted (UTC)
+	weeklySummaryDay  = time.Monday
+	weeklySummaryHour = 8
+
+	// weeklySummarySize is how many players the summary lists
+	weeklySummarySize = 10
+)
+
+// DiscordConfig configures the Discord announcements
+type DiscordConfig struct {
+	DefaultChannel string            // Notifier channel used for sports without their own webhook ("" = none)
+	SportWebhooks  map[string]string // Sport ID -> Discord webhook URL
+	ShowLogins     bool              // Use logins instead of anonymous names
+	FrontendURL    string            // Base URL for links back to the leaderboard
+	Language       string            // Language of the announcements, one per server
+}
+
+// matchAnnouncement fills the match.confirmed template
+type matchAnnouncement struct {
+	Sport       string
+	Winner      string
+	Loser       string
+	WinnerScore int
+	LoserScore  int
+	WinnerELO   string // e.g. "1216 ELO (+16)", empty without ELO data
+	LoserELO    string
+	Forfeit     bool
+}
+
+// weeklySummary fills the leaderboard.weekly_summary template
+type weeklySummary struct {
+	Sport   string
+	Week    string
+	Entries []summaryEntry
+}
+
+// summaryEntry is one line of the weekly summary
+type summaryEntry struct {
+	Rank   int
+	Name   string
+	ELO    int
+	New    bool // not on last week's leaderboard
+	Change int  // ranks climbed since last week, negative if dropped
+}
+
+// DiscordAnnouncer posts confirmed match results and weekly leaderboard summaries to Discord
+// It implements services.EventPublisher; match announcements are fire-and-forget
+type DiscordAnnouncer struct {
+	notifier      *notifications.Notifier
+	templates     *notifications.Templates
+	matchRepo     *repositories.MatchRepository
+	userRepo      *repositories.UserRepository
+	snapshotRepo  *repositories.SnapshotRepository
+	deliveryRepo  *repositories.DeliveryRepository
+	matchService  *services.MatchService
+	sportService  *services.SportService
+	anonService   *services.AnonymizationService
+	sportChannels map[string]string // sport ID -> notifier channel name
+	cfg           DiscordConfig
+}
+
+// NewDiscordAnnouncer registers one notifier channel per sport webhook and creates the announcer
+func NewDiscordAnnouncer(
+	cfg DiscordConfig,
+	notifier *notifications.Notifier,
+	templates *notifications.Templates,
+	matchRepo *repositories.MatchRepository,
+	userRepo *repositories.UserRepository,
+	snapshotRepo *repositories.SnapshotRepository,
+	deliveryRepo *repositories.DeliveryRepository,
+	matchService *services.MatchService,
+	sportService *services.SportService,
+	anonService *services.AnonymizationService,
+) *DiscordAnnouncer {
+	a := &DiscordAnnouncer{
+		notifier:      notifier,
+		templates:     templates,
+		matchRepo:     matchRepo,
+		userRepo:      userRepo,
+		snapshotRepo:  snapshotRepo,
+		deliveryRepo:  deliveryRepo,
+		matchService:  matchService,
+		sportService:  sportService,
+		anonService:   anonService,
+		sportChannels: make(map[string]string),
+		cfg:           cfg,
+	}
+
+	for sport, url := range cfg.SportWebhooks {
+		if err := sportService.ValidateSportID(sport); err != nil {
+			slog.Warn("Discord webhook configured for unknown sport", "sport", sport)
+		}
+		name := "discord:" + sport
+		notifier.Register(notifications.NewDiscordChannel(name, url))
+		a.sportChannels[sport] = name
+	}
+
+	return a
+}
+
+// Enabled reports whether any Discord channel is configured
+func (a *DiscordAnnouncer) Enabled() bool {
+	return a.cfg.DefaultChannel != "" || len(a.sportChannels) > 0
+}
+
+// channelFor returns the notifier channel of a sport, "" if it has none
+func (a *DiscordAnnouncer) channelFor(sport string) string {
+	if name, ok := a.sportChannels[sport]; ok {
+		return name
+	}
+	return a.cfg.DefaultChannel
+}
+
+// Publish announces confirmed matches in the background
+func (a *DiscordAnnouncer) Publish(channel, eventType string, data interface{}) {
+	if eventType != services.EventMatchConfirmed {
+		return
+	}
+	event, ok := data.(services.MatchEvent)
+	if !ok || a.channelFor(event.Sport) == "" {
+		return
+	}
+
+	middleware.SafeGoroutineWithContext("discord_match_announcement", func() {
+		if err := a.announceMatch(context.Background(), event.MatchID); err != nil {
+			slog.Error("Failed to announce match on Discord", "match_id", event.MatchID, "error", err)
+		}
+	})
+}
+
+// announceMatch posts the result of a confirmed match
+func (a *DiscordAnnouncer) announceMatch(ctx context.Context, matchID int) error {
+	match, err := a.matchRepo.GetByID(ctx, matchID)
+	if err != nil {
+		return err
+	}
+	player1, err := a.userRepo.GetByID(ctx, match.Player1ID)
+	if err != nil {
+		return err
+	}
+	player2, err := a.userRepo.GetByID(ctx, match.Player2ID)
+	if err != nil {
+		return err
+	}
+	names, err := a.displayNames(ctx, []models.User{*player1, *player2})
+	if err != nil {
+		return err
+	}
+
+	winner, loser := player1, player2
+	winnerScore, loserScore := match.Player1Score, match.Player2Score
+	winnerDelta, loserDelta := match.Player1ELODelta, match.Player2ELODelta
+	winnerELO, loserELO := match.Player1ELOAfter, match.Player2ELOAfter
+	if match.WinnerID == match.Player2ID {
+		winner, loser = player2, player1
+		winnerScore, loserScore = loserScore, winnerScore
+		winnerDelta, loserDelta = loserDelta, winnerDelta
+		winnerELO, loserELO = loserELO, winnerELO
+	}
+
+	msg, err := a.templates.Render(a.cfg.Language, notifications.FormatDiscord, services.EventMatchConfirmed, matchAnnouncement{
+		Sport:       a.sportName(match.Sport),
+		Winner:      names[winner.ID],
+		Loser:       names[loser.ID],
+		WinnerScore: winnerScore,
+		LoserScore:  loserScore,
+		WinnerELO:   formatELO(winnerELO, winnerDelta),
+		LoserELO:    formatELO(loserELO, loserDelta),
+		Forfeit:     match.Result == models.ResultForfeit,
+	})
+	if err != nil {
+		return err
+	}
+	msg.URL = a.leaderboardURL(match.Sport)
+
+	delivery, err := a.notifier.Send(ctx, a.channelFor(match.Sport), services.EventMatchConfirmed, msg)
+	if err != nil {
+		return err
+	}
+	if delivery.Status != models.DeliveryDelivered {
+		return fmt.Errorf("delivery failed: %s", derefString(delivery.Error))
+	}
+	return nil
+}
+
+// PostWeeklySummaries posts the top of each sport's leaderboard once a week
+// Meant to run periodically; the delivery log makes it idempotent across restarts
+// and a failed post is retried on the next run
+func (a *DiscordAnnouncer) PostWeeklySummaries(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	weekStart := startOfSummaryWeek(now)
+	if now.Before(weekStart) {
+		return nil
+	}
+
+	sports, err := a.sportService.GetAllActiveSports()
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, sport := range sports {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		channel := a.channelFor(sport.ID)
+		if channel == "" {
+			continue
+		}
+		posted, err := a.deliveryRepo.HasDelivered(ctx, channel, summaryEvent(sport.ID), weekStart)
+		if err != nil {
+			return err
+		}
+		if posted {
+			continue
+		}
+
+		if err := a.postSummary(ctx, channel, sport, now); err != nil {
+			slog.Error("Failed to post weekly Discord summary", "sport", sport.ID, "error", err)
+			failed = append(failed, sport.ID)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("weekly summary failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// postSummary posts one sport's top players with their movement since last week's snapshot
+func (a *DiscordAnnouncer) postSummary(ctx context.Context, channel string, sport *services.Sport, now time.Time) error {
+	entries, _, err := a.matchService.GetLeaderboardPage(ctx, sport.ID, weeklySummarySize, 0)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	lastWeek, err := a.snapshotRepo.GetRanks(ctx, now.AddDate(0, 0, -7), sport.ID)
+	if err != nil {
+		return err
+	}
+
+	users := make([]models.User, len(entries))
+	for i := range entries {
+		users[i] = entries[i].User
+	}
+	names, err := a.displayNames(ctx, users)
+	if err != nil {
+		return err
+	}
+
+	summary := weeklySummary{
+		Sport:   sport.DisplayName,
+		Week:    isoWeek(now),
+		Entries: make([]summaryEntry, len(entries)),
+	}
+	for i, entry := range entries {
+		summary.Entries[i] = summaryEntry{Rank: entry.Rank, Name: names[entry.User.ID], ELO: entry.ELO}
+		summary.Entries[i].New, summary.Entries[i].Change = movement(lastWeek, entry)
+	}
+
+	msg, err := a.templates.Render(a.cfg.Language, notifications.FormatDiscord, EventWeeklySummary, summary)
+	if err != nil {
+		return err
+	}
+	msg.URL = a.leaderboardURL(sport.ID)
+
+	delivery, err := a.notifier.Send(ctx, channel, summaryEvent(sport.ID), msg)
+	if err != nil {
+		return err
+	}
+	if delivery.Status != models.DeliveryDelivered {
+		return fmt.Errorf("delivery failed: %s", derefString(delivery.Error))
+	}
+	return nil
+}
+
+// displayNames returns the names players are announced with
+// Discord is outside the login wall, so anonymous names are used unless configured otherwise
+func (a *DiscordAnnouncer) displayNames(ctx context.Context, users []models.User) (map[int]string, error) {
+	if !a.cfg.ShowLogins {
+		return a.anonService.AnonymousNames(ctx, users)
+	}
+
+	names := make(map[int]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Login
+	}
+	return names, nil
+}
+
+func (a *DiscordAnnouncer) sportName(sportID string) string {
+	if sport, err := a.sportService.GetSport(sportID); err == nil {
+		return sport.DisplayName
+	}
+	return sportID
+}
+
+func (a *DiscordAnnouncer) leaderboardURL(sport string) string {
+	if a.cfg.FrontendURL == "" {
+		return ""
+	}
+	return strings.TrimRight(a.cfg.FrontendURL, "/") + "/leaderboard/" + sport
+}
+
+// summaryEvent is the delivery log event of a sport's weekly summary
+func summaryEvent(sport string) string {
+	return EventWeeklySummary + ":" + sport
+}
+
+// startOfSummaryWeek returns when this week's summary is due
+func startOfSummaryWeek(now time.Time) time.Time {
+	daysSince := (int(now.Weekday()) - int(weeklySummaryDay) + 7) % 7
+	day := now.AddDate(0, 0, -daysSince)
+	return time.Date(day.Year(), day.Month(), day.Day(), weeklySummaryHour, 0, 0, 0, time.UTC)
+}
+
+func isoWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// movement returns a player's rank change since last week (positive = climbed)
+// and whether they are new to the top; both are zero without a snapshot
+func movement(lastWeek map[int]int, entry models.LeaderboardEntry) (bool, int) {
+	if len(lastWeek) == 0 {
+		return false, 0
+	}
+	previous, ok := lastWeek[entry.User.ID]
+	if !ok {
+		return true, 0
+	}
+	return false, previous - entry.Rank
+}
+
+func formatELO(elo, delta *int) string {
+	if elo == nil || delta == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d ELO (%+d)", *elo, *delta)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
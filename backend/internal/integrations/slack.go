@@ -0,0 +1,96 @@
+package integrations
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// slackMaxClockSkew is how old a Slack request may be before it is treated as a replay
+const slackMaxClockSkew = 5 * time.Minute
+
+// VerifySlackSignature checks the X-Slack-Signature of a request body
+// Slack signs "v0:<timestamp>:<body>" with the app's signing secret
+func VerifySlackSignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("slack signing secret not configured")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid request timestamp")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > slackMaxClockSkew || age < -slackMaxClockSkew {
+		return fmt.Errorf("request timestamp too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("invalid request signature")
+	}
+	return nil
+}
+
+// SlackCommand is a parsed "/elo <action> <args...>" slash command
+type SlackCommand struct {
+	Action string
+	Args   []string
+}
+
+// ParseSlackCommand splits the text of a slash command into action and arguments
+func ParseSlackCommand(text string) SlackCommand {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return SlackCommand{Action: "help"}
+	}
+	return SlackCommand{Action: strings.ToLower(fields[0]), Args: fields[1:]}
+}
+
+// ParseSlackScore parses a score written as "11-7" or "11:7", submitter first
+func ParseSlackScore(s string) (int, int, error) {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == ':' })
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("score must look like 11-7")
+	}
+	own, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("score must look like 11-7")
+	}
+	opponent, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("score must look like 11-7")
+	}
+	return own, opponent, nil
+}
+
+// ParseSlackMention returns the Slack user ID of an escaped mention like "<@U123|name>"
+// ok is false for anything else, e.g. a plain intra login
+func ParseSlackMention(s string) (string, bool) {
+	if !strings.HasPrefix(s, "<@") || !strings.HasSuffix(s, ">") {
+		return "", false
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(s, "<@"), ">")
+	if i := strings.IndexByte(id, '|'); i >= 0 {
+		id = id[:i]
+	}
+	return id, id != ""
+}
+
+// SlackResponse is the JSON reply to a slash command
+type SlackResponse struct {
+	ResponseType string `json:"response_type"` // "ephemeral" (only the caller sees it) or "in_channel"
+	Text         string `json:"text"`
+}
+
+// SlackEphemeral returns a reply only the user who ran the command sees
+func SlackEphemeral(format string, args ...interface{}) SlackResponse {
+	return SlackResponse{ResponseType: "ephemeral", Text: fmt.Sprintf(format, args...)}
+}
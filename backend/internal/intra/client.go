@@ -0,0 +1,186 @@
+// Package intra calls the 42 Intra API with the application's own credentials.
+//
+// Requests authenticate with a client-credentials token, which is cached until
+// shortly before it expires, and are spaced out to stay below the API's
+// per-application rate limit.
+package intra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/telemetry"
+)
+
+const (
+	baseURL = "https://api.intra.42.fr"
+
+	// minRequestInterval keeps us below the 2 requests per second allowed per application
+	minRequestInterval = 500 * time.Millisecond
+
+	// tokenExpiryMargin renews the token this long before it expires
+	tokenExpiryMargin = time.Minute
+)
+
+// ErrNotFound is returned when the 42 API does not know the requested resource
+var ErrNotFound = errors.New("not found on the 42 API")
+
+// User is the part of a 42 user profile the leaderboard keeps in sync
+type User struct {
+	ID          int    `json:"id"`
+	Login       string `json:"login"`
+	DisplayName string `json:"displayname"`
+	Image       struct {
+		Link string `json:"link"`
+	} `json:"image"`
+}
+
+// Client is a 42 API client authenticated as the application, safe for concurrent use
+type Client struct {
+	clientID     string
+	clientSecret string
+	http         *http.Client
+
+	mu          sync.Mutex
+	token       string
+	expiresAt   time.Time
+	lastRequest time.Time
+}
+
+// NewClient creates a 42 API client for the OAuth application's credentials
+func NewClient(clientID, clientSecret string) *Client {
+	return &Client{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		http:         &http.Client{Timeout: 10 * time.Second, Transport: telemetry.Transport(nil)},
+	}
+}
+
+// GetUser fetches the profile of a user by login
+// Returns ErrNotFound if the login does not exist (anymore)
+func (c *Client) GetUser(ctx context.Context, login string) (*User, error) {
+	var user User
+	if err := c.get(ctx, "/v2/users/"+url.PathEscape(login), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// get performs an authenticated GET request and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized:
+		// Revoked or expired early; fetch a new token next time
+		c.mu.Lock()
+		c.token = ""
+		c.mu.Unlock()
+		return fmt.Errorf("42 API rejected the application token")
+	default:
+		return fmt.Errorf("42 API request failed: status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// accessToken returns the cached client-credentials token, requesting a new one when needed
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		token := c.token
+		c.mu.Unlock()
+		return token, nil
+	}
+	c.mu.Unlock()
+
+	if err := c.wait(ctx); err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", c.clientID)
+	form.Set("client_secret", c.clientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get application token: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("access token not found in response")
+	}
+
+	c.mu.Lock()
+	c.token = result.AccessToken
+	c.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - tokenExpiryMargin)
+	c.mu.Unlock()
+
+	return result.AccessToken, nil
+}
+
+// wait blocks until minRequestInterval has passed since the previous request
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	next := c.lastRequest.Add(minRequestInterval)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	c.lastRequest = next
+	c.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(next)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,417 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/integrations"
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/notifications"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+)
+
+// PendingMatchExpiry cancels pending matches that were never confirmed or denied within maxAge
+func PendingMatchExpiry(matchRepo *repositories.MatchRepository, maxAge time.Duration) Job {
+	return Job{
+		Name:     "expiry",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			expired, err := matchRepo.ExpireStalePending(ctx, time.Now().Add(-maxAge))
+			if err != nil {
+				return fmt.Errorf("failed to expire pending matches: %w", err)
+			}
+			if expired > 0 {
+				slog.Info("Expired stale pending matches", "count", expired)
+			}
+			return nil
+		},
+	}
+}
+
+// LeaderboardSnapshots stores one leaderboard snapshot per active sport and day
+// Runs hourly so a missed run (restart, outage) is caught up the same day
+func LeaderboardSnapshots(
+	sportService *services.SportService,
+	matchService *services.MatchService,
+	snapshotRepo *repositories.SnapshotRepository,
+) Job {
+	return Job{
+		Name:     "snapshots",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			sports, err := sportService.GetAllActiveSports()
+			if err != nil {
+				return err
+			}
+
+			today := time.Now().UTC()
+			for _, sport := range sports {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				exists, err := snapshotRepo.HasSnapshot(ctx, today, sport.ID)
+				if err != nil {
+					return err
+				}
+				if exists {
+					continue
+				}
+
+				entries, err := matchService.GetLeaderboard(ctx, sport.ID)
+				if err != nil {
+					return fmt.Errorf("failed to load leaderboard for %s: %w", sport.ID, err)
+				}
+				if err := snapshotRepo.SaveSnapshot(ctx, today, sport.ID, entries); err != nil {
+					return err
+				}
+				slog.Info("Leaderboard snapshot stored", "sport", sport.ID, "entries", len(entries))
+			}
+			return nil
+		},
+	}
+}
+
+// FingerprintRetention clears hashed client fingerprints older than the retention period
+func FingerprintRetention(matchRepo *repositories.MatchRepository, retention time.Duration) Job {
+	return Job{
+		Name:     "retention",
+		Interval: 24 * time.Hour,
+		Run: func(ctx context.Context) error {
+			purged, err := matchRepo.PurgeFingerprints(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return fmt.Errorf("failed to purge fingerprints: %w", err)
+			}
+			if purged > 0 {
+				slog.Info("Purged expired client fingerprints", "matches", purged)
+			}
+			return nil
+		},
+	}
+}
+
+// DeliveryLogRetention purges old notification delivery log entries
+func DeliveryLogRetention(deliveryRepo *repositories.DeliveryRepository, retention time.Duration) Job {
+	return Job{
+		Name:     "delivery_log_retention",
+		Interval: 24 * time.Hour,
+		Run: func(ctx context.Context) error {
+			purged, err := deliveryRepo.PurgeBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return fmt.Errorf("failed to purge delivery log: %w", err)
+			}
+			if purged > 0 {
+				slog.Info("Purged old notification deliveries", "deliveries", purged)
+			}
+			return nil
+		},
+	}
+}
+
+// UserNotificationRetention removes old entries from user notification inboxes
+func UserNotificationRetention(notificationRepo *repositories.UserNotificationRepository, retention time.Duration) Job {
+	return Job{
+		Name:     "user_notification_retention",
+		Interval: 24 * time.Hour,
+		Run: func(ctx context.Context) error {
+			purged, err := notificationRepo.PurgeBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return fmt.Errorf("failed to purge user notifications: %w", err)
+			}
+			if purged > 0 {
+				slog.Info("Purged old user notifications", "notifications", purged)
+			}
+			return nil
+		},
+	}
+}
+
+// DataExports builds the queued GDPR data exports and notifies each user with the download link
+// Exports whose link has expired are purged on every run
+func DataExports(exportService *services.DataExportService, inbox *notifications.Inbox) Job {
+	return Job{
+		Name:     "data_exports",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			for ctx.Err() == nil {
+				export, err := exportService.ProcessNext(ctx)
+				if err != nil {
+					return err
+				}
+				if export == nil {
+					break
+				}
+
+				inbox.Notify(ctx, &models.UserNotification{
+					UserID: export.UserID,
+					Kind:   models.NotificationDataExport,
+					Data: map[string]interface{}{
+						"export_id":    export.ID,
+						"download_url": export.DownloadURL,
+						"expires_at":   export.ExpiresAt,
+					},
+				})
+			}
+
+			purged, err := exportService.PurgeExpired(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to purge data exports: %w", err)
+			}
+			if purged > 0 {
+				slog.Info("Purged expired data exports", "exports", purged)
+			}
+			return ctx.Err()
+		},
+	}
+}
+
+// BanExpiry lifts temporary bans that have ended, re-ranks the leaderboards the players
+// return to and tells each player in their inbox
+// BannedUserMiddleware already lets them in once the ban ends, so the interval only delays the rest
+func BanExpiry(adminRepo *repositories.AdminRepository, matchService *services.MatchService, inbox *notifications.Inbox) Job {
+	return Job{
+		Name:     "ban_expiry",
+		Interval: 5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			lifted, err := adminRepo.LiftExpiredBans(ctx)
+			if err != nil {
+				return err
+			}
+			if len(lifted) == 0 {
+				return nil
+			}
+
+			for userID, login := range lifted {
+				inbox.Notify(ctx, &models.UserNotification{
+					UserID: userID,
+					Kind:   models.NotificationBanLifted,
+				})
+				slog.Info("Temporary ban ended", "user_id", userID, "login", login)
+			}
+
+			if _, err := matchService.RebuildLeaderboard(ctx); err != nil {
+				return fmt.Errorf("failed to re-rank after lifting bans: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// AccountDeletions erases accounts whose deletion grace period has ended
+func AccountDeletions(deletionService *services.AccountDeletionService) Job {
+	return Job{
+		Name:     "account_deletions",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			erased, err := deletionService.ProcessDue(ctx)
+			if erased > 0 {
+				slog.Info("Erased accounts after grace period", "count", erased)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to erase accounts: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// AnonymousNames assigns anonymous names to users who have none yet
+// Logins assign them too; this catches existing, seeded and system accounts
+func AnonymousNames(anonService *services.AnonymizationService) Job {
+	return Job{
+		Name:     "anonymous_names",
+		Interval: 10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			assigned, err := anonService.AssignMissingNames(ctx)
+			if assigned > 0 {
+				slog.Info("Assigned anonymous names", "users", assigned)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to assign anonymous names: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// ProfileSync refreshes avatars and display names of active users from the 42 API
+// Each run handles one batch, so a backlog after downtime is worked off over several runs
+func ProfileSync(syncService *services.ProfileSyncService) Job {
+	return Job{
+		Name:     "profile_sync",
+		Interval: 10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			changed, err := syncService.SyncDue(ctx)
+			if changed > 0 {
+				slog.Info("Refreshed 42 profiles", "changed", changed)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to sync 42 profiles: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// AbuseScan flags recently confirmed matches that look like ELO farming for staff review
+func AbuseScan(abuseService *services.AbuseDetectionService) Job {
+	return Job{
+		Name:     "abuse_scan",
+		Interval: 15 * time.Minute,
+		Run: func(ctx context.Context) error {
+			if _, err := abuseService.Scan(ctx); err != nil {
+				return fmt.Errorf("failed to scan for suspicious matches: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// Digests generates the daily and weekly digests once their period is over and posts
+// them to Discord and Slack if configured
+// Runs hourly; a digest is generated once, so a failed post is not repeated
+func Digests(digestService *services.DigestService, poster *integrations.DigestPoster) Job {
+	return Job{
+		Name:     "digests",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			digests, err := digestService.GenerateDue(ctx, time.Now())
+			for _, digest := range digests {
+				if !poster.Enabled() {
+					break
+				}
+				if err := poster.Post(ctx, digest); err != nil {
+					slog.Error("Failed to post digest", "sport", digest.Sport, "period", digest.Period, "error", err)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to generate digests: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// DiscordWeeklySummary posts the weekly leaderboard summaries once they are due
+// Runs hourly; the announcer skips sports already posted this week
+func DiscordWeeklySummary(announcer *integrations.DiscordAnnouncer) Job {
+	return Job{
+		Name:     "discord_weekly_summary",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			return announcer.PostWeeklySummaries(ctx, time.Now())
+		},
+	}
+}
+
+// UsageFlush writes the API usage counted in memory to the database
+// Purges counters older than the retention period once a day
+func UsageFlush(tracker *middleware.UsageTracker, usageRepo *repositories.UsageRepository, retention time.Duration) Job {
+	var lastPurge time.Time
+	return Job{
+		Name:     "usage",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			if err := usageRepo.AddCounts(ctx, tracker.Drain()); err != nil {
+				return fmt.Errorf("failed to flush API usage: %w", err)
+			}
+
+			if time.Since(lastPurge) < 24*time.Hour {
+				return nil
+			}
+			purged, err := usageRepo.PurgeBefore(ctx, time.Now().UTC().Add(-retention))
+			if err != nil {
+				return fmt.Errorf("failed to purge API usage: %w", err)
+			}
+			lastPurge = time.Now()
+			if purged > 0 {
+				slog.Info("Purged old API usage counters", "rows", purged)
+			}
+			return nil
+		},
+	}
+}
+
+// MatchArchival moves finished matches from past seasons into the archive table
+// The cutoff is the start of the most recently closed season, so the current and the
+// previous season stay hot; without seasons, matches older than fallbackAge are archived
+func MatchArchival(matchRepo *repositories.MatchRepository, seasonRepo *repositories.SeasonRepository, fallbackAge time.Duration) Job {
+	return Job{
+		Name:     "archival",
+		Interval: 24 * time.Hour,
+		Run: func(ctx context.Context) error {
+			cutoff := time.Now().Add(-fallbackAge)
+
+			season, err := seasonRepo.GetLatestClosed(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to load latest closed season: %w", err)
+			}
+			if season != nil {
+				cutoff = season.StartedAt
+			}
+
+			archived, err := matchRepo.ArchiveMatchesBefore(ctx, cutoff)
+			if err != nil {
+				return err
+			}
+			if archived > 0 {
+				slog.Info("Archived old matches", "count", archived, "cutoff", cutoff)
+			}
+			return nil
+		},
+	}
+}
+
+// RatingDecay lowers the ratings of players who stopped playing a sport, as set by the policy
+// Runs hourly; each player decays at most once a week, so the interval only bounds the delay
+func RatingDecay(userSportsRepo *repositories.UserSportsRepository, matchService *services.MatchService, policy models.ELODecayPolicy) Job {
+	return Job{
+		Name:     "rating_decay",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			decayed, err := userSportsRepo.ApplyDecay(ctx, policy.Points, policy.AfterWeeks)
+			if err != nil {
+				return err
+			}
+			if len(decayed) > 0 {
+				if _, err := matchService.RebuildLeaderboard(ctx); err != nil {
+					return err
+				}
+				slog.Info("Decayed ratings of inactive players", "ratings", len(decayed))
+			}
+			return nil
+		},
+	}
+}
+
+// StatsConsistency recomputes the profile aggregates in user_sports from confirmed matches
+// and corrects rows that drifted from the incremental updates, then re-ranks the leaderboards
+// so they also catch up with changes whose re-ranking failed after they were committed
+func StatsConsistency(userSportsRepo *repositories.UserSportsRepository, matchService *services.MatchService) Job {
+	return Job{
+		Name:     "consistency",
+		Interval: 6 * time.Hour,
+		Run: func(ctx context.Context) error {
+			fixed, err := userSportsRepo.ReconcileStats(ctx, nil, nil)
+			if err != nil {
+				return err
+			}
+			if fixed > 0 {
+				slog.Warn("Reconciled drifted player stats", "rows", fixed)
+			}
+
+			moved, err := matchService.RebuildLeaderboard(ctx)
+			if err != nil {
+				return err
+			}
+			if moved > 0 {
+				slog.Warn("Corrected drifted leaderboard rankings", "rows", moved)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,212 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// errPanicked is recorded as the job error when a run panics
+var errPanicked = errors.New("job panicked")
+
+// Job is a named task run periodically by the Scheduler
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Status is a snapshot of a job's run history, used by the health check
+type Status struct {
+	Name         string     `json:"name"`
+	Interval     string     `json:"interval"`
+	LastRun      *time.Time `json:"last_run,omitempty"`
+	LastSuccess  *time.Time `json:"last_success,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	LastDuration int64      `json:"last_duration_ms"`
+	Runs         int        `json:"runs"`
+	Failures     int        `json:"failures"`
+	Running      bool       `json:"running"`
+	Overdue      bool       `json:"overdue"`
+}
+
+// entry tracks the runtime state of a registered job
+type entry struct {
+	job          Job
+	mu           sync.Mutex
+	lastRun      time.Time
+	lastSuccess  time.Time
+	lastError    string
+	lastDuration time.Duration
+	runs         int
+	failures     int
+	running      bool
+}
+
+// Scheduler runs registered jobs on their intervals until stopped
+type Scheduler struct {
+	mu        sync.Mutex
+	entries   []*entry
+	startedAt time.Time
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewScheduler creates a new job scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job; must be called before Start
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &entry{job: job})
+}
+
+// Start launches one goroutine per job; each job runs once immediately, then on its interval
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	// Jobs are stopped through cancel rather than the per-query timeout meant for requests
+	ctx, cancel := context.WithCancel(repositories.WithoutQueryTimeout(context.Background()))
+	s.cancel = cancel
+	s.startedAt = time.Now()
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(ctx, e)
+	}
+
+	slog.Info("Background jobs started", "count", len(s.entries))
+}
+
+// Stop cancels all jobs and waits for running ones to finish or the context to expire
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Statuses returns the current status of every registered job
+// A job is overdue when it hasn't completed a run within twice its interval
+func (s *Scheduler) Statuses() []Status {
+	s.mu.Lock()
+	entries := s.entries
+	startedAt := s.startedAt
+	s.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]Status, 0, len(entries))
+	for _, e := range entries {
+		e.mu.Lock()
+		st := Status{
+			Name:         e.job.Name,
+			Interval:     e.job.Interval.String(),
+			LastError:    e.lastError,
+			LastDuration: e.lastDuration.Milliseconds(),
+			Runs:         e.runs,
+			Failures:     e.failures,
+			Running:      e.running,
+		}
+		if !e.lastRun.IsZero() {
+			lastRun := e.lastRun
+			st.LastRun = &lastRun
+		}
+		if !e.lastSuccess.IsZero() {
+			lastSuccess := e.lastSuccess
+			st.LastSuccess = &lastSuccess
+		}
+
+		reference := e.lastRun
+		if reference.IsZero() {
+			reference = startedAt
+		}
+		st.Overdue = !startedAt.IsZero() && now.Sub(reference) > 2*e.job.Interval
+		e.mu.Unlock()
+
+		statuses = append(statuses, st)
+	}
+
+	return statuses
+}
+
+// loop runs a single job until the context is cancelled
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(e.job.Interval)
+	defer ticker.Stop()
+
+	s.runOnce(ctx, e)
+	for {
+		select {
+		case <-ticker.C:
+			s.runOnce(ctx, e)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runOnce executes a job, recovering from panics and recording the outcome
+func (s *Scheduler) runOnce(ctx context.Context, e *entry) {
+	e.mu.Lock()
+	e.running = true
+	e.mu.Unlock()
+
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Background job panicked", "job", e.job.Name, "panic", r)
+				err = errPanicked
+			}
+		}()
+		return e.job.Run(ctx)
+	}()
+	duration := time.Since(start)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.running = false
+	e.lastRun = start
+	e.lastDuration = duration
+	e.runs++
+	if err != nil {
+		e.failures++
+		e.lastError = err.Error()
+		slog.Error("Background job failed", "job", e.job.Name, "error", err, "duration", duration)
+		return
+	}
+	e.lastSuccess = start
+	e.lastError = ""
+}
@@ -2,14 +2,50 @@ package middleware
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
 	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
 	"github.com/42heilbronn/elo-leaderboard/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
-// AdminMiddleware checks if the authenticated user is an admin
-func AdminMiddleware(userRepo *repositories.UserRepository) gin.HandlerFunc {
+// moderatorPermissions cover day-to-day moderation without touching ratings or bans
+var moderatorPermissions = []string{
+	models.PermissionResolveDisputes,
+	models.PermissionModerateComments,
+	models.PermissionHandleReports,
+}
+
+// adminPermissions are everything except managing the staff itself
+var adminPermissions = append([]string{
+	models.PermissionManageMatches,
+	models.PermissionAdjustELO,
+	models.PermissionBanUsers,
+	models.PermissionManageUsers,
+	models.PermissionManageSystem,
+}, moderatorPermissions...)
+
+// rolePermissions maps each staff role to the permissions it grants
+var rolePermissions = map[string][]string{
+	models.RoleModerator:  moderatorPermissions,
+	models.RoleAdmin:      adminPermissions,
+	models.RoleSuperadmin: append([]string{models.PermissionManageRoles}, adminPermissions...),
+}
+
+// HasPermission reports whether a role grants a permission
+func HasPermission(role, permission string) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminMiddleware checks if the authenticated user has a staff role (moderator or above)
+// The role is stored in the context for RequirePermission
+func AdminMiddleware(userRepo repositories.UserStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := GetUserID(c)
 		if !exists {
@@ -18,7 +54,7 @@ func AdminMiddleware(userRepo *repositories.UserRepository) gin.HandlerFunc {
 			return
 		}
 
-		user, err := userRepo.GetByID(userID)
+		user, err := userRepo.GetByID(c.Request.Context(), userID)
 		if err != nil {
 			utils.RespondWithError(c, http.StatusUnauthorized, "user not found", err)
 			c.Abort()
@@ -32,19 +68,40 @@ func AdminMiddleware(userRepo *repositories.UserRepository) gin.HandlerFunc {
 		}
 
 		// Check if admin is banned (should not happen, but safety check)
-		if user.IsBanned {
+		if user.Banned() {
 			utils.RespondWithError(c, http.StatusForbidden, "account is banned", nil)
 			c.Abort()
 			return
 		}
 
+		c.Set("role", user.Role)
+		c.Next()
+	}
+}
+
+// RequirePermission only lets staff whose role grants the permission through
+// Must run after AdminMiddleware
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(GetRole(c), permission) {
+			utils.RespondWithError(c, http.StatusForbidden, "insufficient permissions", nil)
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
 
+// GetRole returns the staff role set by AdminMiddleware, or "" outside the admin area
+func GetRole(c *gin.Context) string {
+	return c.GetString("role")
+}
+
 // BannedUserMiddleware checks if the authenticated user is banned
 // This should be applied after auth middleware to prevent banned users from taking actions
-func BannedUserMiddleware(userRepo *repositories.UserRepository) gin.HandlerFunc {
+// Temporary bans stop applying as soon as they end, before the ban expiry job clears them
+func BannedUserMiddleware(userRepo repositories.UserStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := GetUserID(c)
 		if !exists {
@@ -53,15 +110,19 @@ func BannedUserMiddleware(userRepo *repositories.UserRepository) gin.HandlerFunc
 			return
 		}
 
-		user, err := userRepo.GetByID(userID)
+		user, err := userRepo.GetByID(c.Request.Context(), userID)
 		if err != nil {
 			// User not found, let other middleware handle it
 			c.Next()
 			return
 		}
 
-		if user.IsBanned {
-			utils.RespondWithError(c, http.StatusForbidden, "your account has been banned", nil)
+		if user.Banned() {
+			message := "your account has been banned"
+			if user.BannedUntil != nil {
+				message = "your account is banned until " + user.BannedUntil.UTC().Format(time.RFC3339)
+			}
+			utils.RespondWithError(c, http.StatusForbidden, message, nil)
 			c.Abort()
 			return
 		}
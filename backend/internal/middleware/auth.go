@@ -1,15 +1,17 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/42heilbronn/elo-leaderboard/internal/revocation"
 	"github.com/42heilbronn/elo-leaderboard/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
-// getTokenFromRequest extracts the JWT token from either Authorization header or httpOnly cookie
-func getTokenFromRequest(c *gin.Context) string {
+// TokenFromRequest extracts the JWT token from either Authorization header or httpOnly cookie
+func TokenFromRequest(c *gin.Context) string {
 	// First, try Authorization header
 	authHeader := c.GetHeader("Authorization")
 	if authHeader != "" {
@@ -25,14 +27,21 @@ func getTokenFromRequest(c *gin.Context) string {
 		return cookie
 	}
 
+	// Browsers cannot set headers on WebSocket handshakes, so allow a query token there
+	if c.IsWebsocket() {
+		return c.Query("token")
+	}
+
 	return ""
 }
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// AuthMiddleware requires a valid, non-revoked JWT
+// denyList may be nil when token revocation is not configured
+func AuthMiddleware(jwtSecret string, denyList *revocation.DenyList) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := getTokenFromRequest(c)
+		tokenString := TokenFromRequest(c)
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization required"})
+			utils.RespondWithError(c, http.StatusUnauthorized, "authorization required", nil)
 			c.Abort()
 			return
 		}
@@ -40,7 +49,13 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		// Validate token
 		claims, err := utils.ValidateJWT(tokenString, jwtSecret)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			utils.RespondWithError(c, http.StatusUnauthorized, "invalid token", nil)
+			c.Abort()
+			return
+		}
+
+		if denyList != nil && denyList.IsRevoked(c.Request.Context(), claims) {
+			utils.RespondWithError(c, http.StatusUnauthorized, "token revoked", nil)
 			c.Abort()
 			return
 		}
@@ -63,9 +78,9 @@ func GetUserID(c *gin.Context) (int, bool) {
 
 // OptionalAuthMiddleware extracts user ID from token if present, but doesn't require it
 // This allows endpoints to behave differently for authenticated vs unauthenticated users
-func OptionalAuthMiddleware(jwtSecret string) gin.HandlerFunc {
+func OptionalAuthMiddleware(jwtSecret string, denyList *revocation.DenyList) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := getTokenFromRequest(c)
+		tokenString := TokenFromRequest(c)
 		if tokenString == "" {
 			// No token - continue as unauthenticated
 			c.Set("authenticated", false)
@@ -75,8 +90,11 @@ func OptionalAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 
 		// Validate token
 		claims, err := utils.ValidateJWT(tokenString, jwtSecret)
+		if err == nil && denyList != nil && denyList.IsRevoked(c.Request.Context(), claims) {
+			err = fmt.Errorf("token revoked")
+		}
 		if err != nil {
-			// Invalid token - continue as unauthenticated
+			// Invalid or revoked token - continue as unauthenticated
 			c.Set("authenticated", false)
 			c.Next()
 			return
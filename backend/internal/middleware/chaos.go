@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// chaosRoutePrefix is never subject to chaos so injected faults can always be removed
+const chaosRoutePrefix = "/api/admin/chaos"
+
+// ChaosRule injects a fault into requests matching a route template
+type ChaosRule struct {
+	ID          int       `json:"id"`
+	Method      string    `json:"method,omitempty"`
+	Route       string    `json:"route"`
+	Fault       string    `json:"fault"`
+	LatencyMS   int       `json:"latency_ms,omitempty"`
+	Probability float64   `json:"probability"`
+	CreatedBy   int       `json:"created_by"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ChaosInjector holds the active chaos rules of this instance
+// Rules live in memory and expire on their own, so a forgotten experiment can't
+// leave staging broken; with several instances each one needs its own rules
+type ChaosInjector struct {
+	mu     sync.RWMutex
+	rules  map[int]*ChaosRule
+	nextID int
+}
+
+// NewChaosInjector creates an injector without rules
+func NewChaosInjector() *ChaosInjector {
+	return &ChaosInjector{
+		rules:  make(map[int]*ChaosRule),
+		nextID: 1,
+	}
+}
+
+// AddRule activates a rule; defaults: every request, 2s latency, 10 minutes
+func (ci *ChaosInjector) AddRule(req models.CreateChaosRuleRequest, adminID int) (*ChaosRule, error) {
+	if strings.HasPrefix(req.Route, chaosRoutePrefix) {
+		return nil, fmt.Errorf("chaos routes cannot be targeted")
+	}
+
+	rule := &ChaosRule{
+		Method:      req.Method,
+		Route:       req.Route,
+		Fault:       req.Fault,
+		LatencyMS:   req.LatencyMS,
+		Probability: req.Probability,
+		CreatedBy:   adminID,
+	}
+	if rule.Probability == 0 {
+		rule.Probability = 1
+	}
+	if rule.Fault == models.ChaosLatency && rule.LatencyMS == 0 {
+		rule.LatencyMS = 2000
+	}
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if duration == 0 {
+		duration = 10 * time.Minute
+	}
+	rule.ExpiresAt = time.Now().Add(duration)
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	rule.ID = ci.nextID
+	ci.nextID++
+	ci.rules[rule.ID] = rule
+
+	slog.Warn("Chaos rule activated", "id", rule.ID, "method", rule.Method, "route", rule.Route, "fault", rule.Fault, "expires_at", rule.ExpiresAt)
+	return rule, nil
+}
+
+// RemoveRule deactivates a rule
+func (ci *ChaosInjector) RemoveRule(id int) error {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	if _, exists := ci.rules[id]; !exists {
+		return fmt.Errorf("rule not found")
+	}
+	delete(ci.rules, id)
+	return nil
+}
+
+// Clear deactivates all rules and returns how many were active
+func (ci *ChaosInjector) Clear() int {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	count := len(ci.rules)
+	ci.rules = make(map[int]*ChaosRule)
+	return count
+}
+
+// Rules returns the active rules ordered by ID
+func (ci *ChaosInjector) Rules() []ChaosRule {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	ci.pruneExpired()
+	rules := make([]ChaosRule, 0, len(ci.rules))
+	for _, rule := range ci.rules {
+		rules = append(rules, *rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// match returns the first active rule for the request that fires this time
+func (ci *ChaosInjector) match(method, route string) *ChaosRule {
+	ci.mu.RLock()
+	defer ci.mu.RUnlock()
+
+	now := time.Now()
+	for _, rule := range ci.rules {
+		if rule.Route != route || (rule.Method != "" && rule.Method != method) || now.After(rule.ExpiresAt) {
+			continue
+		}
+		if rand.Float64() < rule.Probability {
+			copied := *rule
+			return &copied
+		}
+	}
+	return nil
+}
+
+// pruneExpired drops expired rules; callers hold the write lock
+func (ci *ChaosInjector) pruneExpired() {
+	now := time.Now()
+	for id, rule := range ci.rules {
+		if now.After(rule.ExpiresAt) {
+			delete(ci.rules, id)
+		}
+	}
+}
+
+// Middleware applies matching rules before the handler runs
+// Panics propagate to RecoveryMiddleware; DB errors are answered the way handlers
+// answer a failed query, since repositories don't take a context to fail through
+func (ci *ChaosInjector) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule := ci.match(c.Request.Method, c.FullPath())
+		if rule == nil {
+			c.Next()
+			return
+		}
+
+		c.Header("X-Chaos-Rule", fmt.Sprintf("%d", rule.ID))
+
+		switch rule.Fault {
+		case models.ChaosLatency:
+			select {
+			case <-time.After(time.Duration(rule.LatencyMS) * time.Millisecond):
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+		case models.ChaosDBError:
+			utils.RespondWithError(c, http.StatusInternalServerError, "database error", fmt.Errorf("chaos rule %d: %w", rule.ID, sql.ErrConnDone))
+			c.Abort()
+			return
+		case models.ChaosPanic:
+			panic(fmt.Sprintf("chaos rule %d: injected panic", rule.ID))
+		}
+
+		c.Next()
+	}
+}
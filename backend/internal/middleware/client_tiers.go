@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientKeyHeader carries the API key of a trusted client
+const ClientKeyHeader = "X-Client-Key"
+
+// clientTierRefresh bounds how long other instances keep using a changed or removed key
+const clientTierRefresh = time.Minute
+
+// ClientTiers resolves the rate limit tier of a request from its client key
+// Active keys are cached in memory and reloaded every clientTierRefresh, or
+// immediately on this instance after Invalidate
+type ClientTiers struct {
+	repo     repositories.TrustedClientStore
+	mu       sync.RWMutex
+	byHash   map[string]models.TrustedClient
+	loadedAt time.Time
+}
+
+// NewClientTiers creates a tier resolver backed by the trusted clients table
+func NewClientTiers(repo repositories.TrustedClientStore) *ClientTiers {
+	return &ClientTiers{repo: repo}
+}
+
+// HashClientKey returns the stored form of a client key
+func HashClientKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Invalidate forces a reload on the next request, after clients were changed
+func (t *ClientTiers) Invalidate() {
+	t.mu.Lock()
+	t.loadedAt = time.Time{}
+	t.mu.Unlock()
+}
+
+func (t *ClientTiers) lookup(ctx context.Context, hash string) (models.TrustedClient, bool) {
+	t.mu.RLock()
+	fresh := time.Since(t.loadedAt) < clientTierRefresh
+	client, ok := t.byHash[hash]
+	t.mu.RUnlock()
+	if fresh {
+		return client, ok
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if time.Since(t.loadedAt) >= clientTierRefresh {
+		clients, err := t.repo.ListActive(ctx)
+		if err != nil {
+			// Keep the previous keys; retry on the next request
+			slog.Warn("Failed to load trusted clients", "error", err)
+		} else {
+			t.byHash = make(map[string]models.TrustedClient, len(clients))
+			for _, c := range clients {
+				t.byHash[c.KeyHash] = c
+			}
+			t.loadedAt = time.Now()
+		}
+	}
+	client, ok = t.byHash[hash]
+	return client, ok
+}
+
+// Middleware tags requests carrying a known client key with its tier
+// Unknown keys are ignored, so the request falls back to the standard limits
+func (t *ClientTiers) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(ClientKeyHeader); key != "" {
+			if client, ok := t.lookup(c.Request.Context(), HashClientKey(key)); ok {
+				c.Set("rate_limit_tier", client.Tier)
+				c.Set("trusted_client_id", client.ID)
+			}
+		}
+		c.Next()
+	}
+}
+
+// RateLimitTier returns the tier set by ClientTiers, or "" for standard limits
+func RateLimitTier(c *gin.Context) string {
+	return c.GetString("rate_limit_tier")
+}
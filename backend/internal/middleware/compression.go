@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Content codings the middleware produces, in order of preference when a client weighs them equally
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// encoder is a compressing writer that is pooled and reused across responses
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// CompressionMiddleware compresses responses with brotli or gzip, whichever the client
+// prefers; level is the gzip level and brotli quality
+// The body is held back until minSize bytes are written, so small responses and
+// content types outside types (media type without parameters) go out unchanged
+// Streams work: flushing before minSize is reached decides on the content type alone
+func CompressionMiddleware(level, minSize int, types []string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+
+	pools := map[string]*sync.Pool{
+		encodingBrotli: {
+			New: func() interface{} {
+				return brotli.NewWriterLevel(io.Discard, level)
+			},
+		},
+		encodingGzip: {
+			New: func() interface{} {
+				// The level is validated with the config, so this cannot fail
+				gz, _ := gzip.NewWriterLevel(io.Discard, level)
+				return gz
+			},
+		},
+	}
+
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, allowed: allowed, minSize: minSize, encoding: encoding, pool: pools[encoding]}
+		c.Writer = w
+		defer func() {
+			w.close()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the content coding for an Accept-Encoding header: the supported
+// coding with the highest q-value, brotli on a tie; "" leaves the response unencoded, which
+// is also the case when the client weighs identity above both
+func negotiateEncoding(header string) string {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		weight, ok := qValue(params)
+		if !ok {
+			continue
+		}
+		weights[coding] = weight
+	}
+
+	best, bestWeight := "", 0.0
+	for _, coding := range []string{encodingBrotli, encodingGzip} {
+		weight, ok := weights[coding]
+		if !ok {
+			weight = weights["*"]
+		}
+		if weight > bestWeight {
+			best, bestWeight = coding, weight
+		}
+	}
+	if weights["identity"] > bestWeight {
+		return ""
+	}
+	return best
+}
+
+// qValue returns the weight among the parameters of an Accept-Encoding entry, 1 if it has
+// none; ok is false for malformed weights, so the entry is ignored
+func qValue(params string) (float64, bool) {
+	for _, param := range strings.Split(params, ";") {
+		q, found := strings.CutPrefix(strings.TrimSpace(param), "q=")
+		if !found {
+			continue
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return weight, err == nil && weight >= 0 && weight <= 1
+	}
+	return 1, true
+}
+
+// compressWriter buffers the start of a response until it knows whether to compress it
+type compressWriter struct {
+	gin.ResponseWriter
+	allowed  map[string]bool
+	minSize  int
+	encoding string
+	pool     *sync.Pool
+
+	buf     []byte
+	decided bool
+	enc     encoder
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) < w.minSize {
+		return len(data), nil
+	}
+	w.decide(true)
+	return len(data), w.flushBuffer()
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow sends the headers uncompressed unless a body is already buffered
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided && len(w.buf) == 0 {
+		w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+		w.flushBuffer()
+	}
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide picks compression for the rest of the response; large is false if the
+// response turned out to be too small to be worth it
+func (w *compressWriter) decide(large bool) {
+	w.decided = true
+
+	header := w.Header()
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if !w.allowed[mediaType] {
+		return
+	}
+	// Caches must not hand a compressed body to clients that did not ask for one
+	header.Add("Vary", "Accept-Encoding")
+
+	status := w.ResponseWriter.Status()
+	if !large || header.Get("Content-Encoding") != "" ||
+		status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return
+	}
+
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	w.enc = w.pool.Get().(encoder)
+	w.enc.Reset(w.ResponseWriter)
+}
+
+// flushBuffer writes what was held back through the chosen encoding
+func (w *compressWriter) flushBuffer() error {
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close completes the response once the handler is done
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide(false)
+		w.flushBuffer()
+	}
+	if w.enc != nil {
+		w.enc.Close()
+		w.enc.Reset(io.Discard)
+		w.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
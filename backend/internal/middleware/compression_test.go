@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// compressionRouter serves a JSON body of size bytes behind the compression middleware
+func compressionRouter(size int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CompressionMiddleware(6, 1024, []string{"application/json"}))
+	router.GET("/", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`"`+strings.Repeat("a", size-2)+`"`))
+	})
+	return router
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"br", "br"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"gzip;q=0.8, br;q=0.9", "br"},
+		{"br;q=0, gzip;q=0", ""},
+		{"*", "br"},
+		{"*;q=0.5, br;q=0", "gzip"},
+		{"identity", ""},
+		{"identity;q=1, gzip;q=0.5", ""},
+		{"GZIP;Q=0.5", "gzip"},
+		{"gzip;q=abc", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	const size = 4096
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"br": func(r io.Reader) (io.Reader, error) {
+			return brotli.NewReader(r), nil
+		},
+		"gzip": func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		"": func(r io.Reader) (io.Reader, error) {
+			return r, nil
+		},
+	}
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		want           string
+	}{
+		{"brotli", "gzip, br", "br"},
+		{"gzip", "gzip, br;q=0.1", "gzip"},
+		{"identity", "identity", ""},
+		{"no header", "", ""},
+	}
+	router := compressionRouter(size)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tt.want {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.want)
+			}
+			if got := rec.Header().Get("Vary"); tt.want != "" && got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			reader, err := decoders[tt.want](rec.Body)
+			if err != nil {
+				t.Fatalf("decoding %q body: %v", tt.want, err)
+			}
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("reading %q body: %v", tt.want, err)
+			}
+			if len(body) != size {
+				t.Errorf("decoded body has %d bytes, want %d", len(body), size)
+			}
+		})
+	}
+}
+
+func TestCompressionMiddlewareSkipsSmallResponses(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rec := httptest.NewRecorder()
+	compressionRouter(100).ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding = %q, want none", got)
+	}
+	if rec.Body.Len() != 100 {
+		t.Errorf("body has %d bytes, want 100", rec.Body.Len())
+	}
+}
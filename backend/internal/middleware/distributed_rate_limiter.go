@@ -3,9 +3,11 @@ package middleware
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,6 +18,7 @@ type DistributedRateLimiter struct {
 	maxRequests  int
 	window       time.Duration
 	keyPrefix    string
+	trusted      *DistributedRateLimiter // Separate counters for trusted clients, nil = standard limits
 }
 
 // RateLimitStore defines the interface for a distributed rate limit store
@@ -27,6 +30,10 @@ type RateLimitStore interface {
 	Get(ctx context.Context, key string) (int64, error)
 	// Reset resets the counter for the given key
 	Reset(ctx context.Context, key string) error
+	// TTL returns how long until the counter for the given key expires
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Keys returns the counter keys matching a glob pattern
+	Keys(ctx context.Context, pattern string) ([]string, error)
 }
 
 // RedisRateLimitStore implements RateLimitStore using Redis
@@ -43,6 +50,7 @@ type RedisClient interface {
 	Get(ctx context.Context, key string) (string, error)
 	Del(ctx context.Context, keys ...string) error
 	TTL(ctx context.Context, key string) (time.Duration, error)
+	Scan(ctx context.Context, pattern string) ([]string, error)
 }
 
 // NewRedisRateLimitStore creates a new Redis-backed rate limit store
@@ -85,6 +93,20 @@ func (s *RedisRateLimitStore) Reset(ctx context.Context, key string) error {
 	return s.client.Del(ctx, key)
 }
 
+// TTL returns how long until the window of a key ends
+func (s *RedisRateLimitStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return s.client.TTL(ctx, key)
+}
+
+// Keys returns the counter keys matching a glob pattern
+func (s *RedisRateLimitStore) Keys(ctx context.Context, pattern string) ([]string, error) {
+	keys, err := s.client.Scan(ctx, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan rate limit counters: %w", err)
+	}
+	return keys, nil
+}
+
 // InMemoryRateLimitStore provides a fallback for local development
 // It wraps the existing RateLimiter for compatibility
 type InMemoryRateLimitStore struct {
@@ -116,6 +138,16 @@ func (s *InMemoryRateLimitStore) Reset(ctx context.Context, key string) error {
 	return nil
 }
 
+// TTL returns the full window as a placeholder
+func (s *InMemoryRateLimitStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return s.limiter.window, nil
+}
+
+// Keys returns no keys; inspect the wrapped RateLimiter instead
+func (s *InMemoryRateLimitStore) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return nil, nil
+}
+
 // Stop stops the cleanup goroutine
 func (s *InMemoryRateLimitStore) Stop() {
 	s.limiter.Stop()
@@ -131,15 +163,45 @@ func NewDistributedRateLimiter(store RateLimitStore, maxRequests int, window tim
 	}
 }
 
+// WithTrustedTier gives trusted clients their own counters with multiplier times the limit
+func (rl *DistributedRateLimiter) WithTrustedTier(multiplier int) *DistributedRateLimiter {
+	if multiplier > 1 {
+		rl.trusted = NewDistributedRateLimiter(rl.store, rl.maxRequests*multiplier, rl.window, rl.keyPrefix+":trusted")
+	}
+	return rl
+}
+
 // Allow checks if a request should be allowed
 func (rl *DistributedRateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	status, err := rl.Take(ctx, key)
+	return status.Allowed, err
+}
+
+// Take counts a request and reports what is left of the limit in the current window
+// On a store error the request is allowed and the error returned
+func (rl *DistributedRateLimiter) Take(ctx context.Context, key string) (RateLimitStatus, error) {
 	fullKey := fmt.Sprintf("%s:%s", rl.keyPrefix, key)
 	count, err := rl.store.Increment(ctx, fullKey, rl.window)
 	if err != nil {
-		// On error, allow the request but log the issue
-		return true, err
+		return RateLimitStatus{Allowed: true}, err
+	}
+
+	// A missing expiry (negative TTL) means the window is just starting
+	ttl, err := rl.store.TTL(ctx, fullKey)
+	if err != nil || ttl <= 0 {
+		ttl = rl.window
+	}
+
+	status := RateLimitStatus{
+		Allowed:   count <= int64(rl.maxRequests),
+		Limit:     rl.maxRequests,
+		Remaining: max(rl.maxRequests-int(count), 0),
+		Reset:     time.Now().Add(ttl),
+	}
+	if !status.Allowed {
+		status.RetryAfter = ttl
 	}
-	return count <= int64(rl.maxRequests), nil
+	return status, nil
 }
 
 // GetRemainingRequests returns how many requests are remaining for a key
@@ -162,6 +224,89 @@ func (rl *DistributedRateLimiter) Reset(ctx context.Context, key string) error {
 	return rl.store.Reset(ctx, fullKey)
 }
 
+// subjectKeys returns the counter keys of a client, alone or combined with an endpoint
+func (rl *DistributedRateLimiter) subjectKeys(ctx context.Context, subject string) ([]string, error) {
+	prefix := rl.keyPrefix + ":" + globEscaper.Replace(subject)
+	var keys []string
+	for _, pattern := range []string{prefix, prefix + ":*"} {
+		found, err := rl.store.Keys(ctx, pattern)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, found...)
+	}
+	return keys, nil
+}
+
+// globEscaper escapes the Redis glob characters of a client key
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// Buckets returns the counters of a client, see RateLimitInspector
+func (rl *DistributedRateLimiter) Buckets(ctx context.Context, subject string) ([]RateLimitBucket, error) {
+	keys, err := rl.subjectKeys(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]RateLimitBucket, 0, len(keys))
+	for _, fullKey := range keys {
+		count, err := rl.store.Get(ctx, fullKey)
+		if err != nil {
+			return nil, err
+		}
+		ttl, err := rl.store.TTL(ctx, fullKey)
+		if err != nil {
+			return nil, err
+		}
+		if ttl <= 0 {
+			continue // Expired between scan and lookup
+		}
+		buckets = append(buckets, RateLimitBucket{
+			Key:       strings.TrimPrefix(fullKey, rl.keyPrefix+":"),
+			Limit:     rl.maxRequests,
+			Remaining: max(rl.maxRequests-int(count), 0),
+			Reset:     time.Now().Add(ttl),
+		})
+	}
+
+	if rl.trusted != nil {
+		trusted, err := rl.trusted.Buckets(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range trusted {
+			b.Trusted = true
+			buckets = append(buckets, b)
+		}
+	}
+	return buckets, nil
+}
+
+// ResetSubject deletes the counters of a client so it starts with the full limit again
+func (rl *DistributedRateLimiter) ResetSubject(ctx context.Context, subject string) (int, error) {
+	keys, err := rl.subjectKeys(ctx, subject)
+	if err != nil {
+		return 0, err
+	}
+
+	cleared := 0
+	for _, fullKey := range keys {
+		if err := rl.store.Reset(ctx, fullKey); err != nil {
+			return cleared, err
+		}
+		cleared++
+	}
+
+	if rl.trusted != nil {
+		trusted, err := rl.trusted.ResetSubject(ctx, subject)
+		cleared += trusted
+		if err != nil {
+			return cleared, err
+		}
+	}
+	return cleared, nil
+}
+
 // Pre-configured distributed rate limiters
 
 // NewDistributedStrictRateLimiter for sensitive endpoints (10 req/min)
@@ -180,36 +325,33 @@ func NewDistributedLooseRateLimiter(store RateLimitStore) *DistributedRateLimite
 }
 
 // DistributedRateLimitMiddleware creates a Gin middleware for distributed rate limiting
+// Exempt clients skip the limit and trusted clients use the trusted tier if configured
 func DistributedRateLimitMiddleware(rl *DistributedRateLimiter, keyFunc func(*gin.Context) string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		key := keyFunc(c)
+		limiter := rl
+		switch RateLimitTier(c) {
+		case models.RateLimitTierExempt:
+			c.Next()
+			return
+		case models.RateLimitTierTrusted:
+			if rl.trusted != nil {
+				limiter = rl.trusted
+			}
+		}
 
-		allowed, err := rl.Allow(c.Request.Context(), key)
+		status, err := limiter.Take(c.Request.Context(), keyFunc(c))
 		if err != nil {
 			// Log error but allow request to proceed (fail-open for availability)
 			// In a strict security environment, you might want to fail-closed instead
+			slog.WarnContext(c.Request.Context(), "Rate limit store unavailable, allowing request", "limiter", limiter.keyPrefix, "error", err)
 			c.Next()
 			return
 		}
 
-		if !allowed {
-			remaining, _ := rl.GetRemainingRequests(c.Request.Context(), key)
-			c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-			c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", rl.maxRequests))
-			c.Header("Retry-After", "60")
-
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "too many requests, please try again later",
-			})
-			c.Abort()
+		if !respondRateLimit(c, status) {
 			return
 		}
 
-		// Add rate limit headers for visibility
-		remaining, _ := rl.GetRemainingRequests(c.Request.Context(), key)
-		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", rl.maxRequests))
-
 		c.Next()
 	}
 }
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETagMiddleware makes GET responses conditional for clients that poll
+// A successful response is buffered and tagged with a hash of its body; when the
+// client's If-None-Match holds that tag, 304 Not Modified is sent without a body
+// The hash covers the exact payload, so anonymized and logged-in views never share a tag
+// Only for routes that answer with a single body, not streams or WebSockets
+func ETagMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		w := &etagWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.status != http.StatusOK {
+			w.flush()
+			return
+		}
+
+		sum := sha256.Sum256(w.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Add("Vary", "Authorization, Cookie")
+
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			w.Header().Del("Content-Type")
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			w.ResponseWriter.WriteHeaderNow()
+			return
+		}
+		w.flush()
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag or is "*"
+// Weak comparison, as RFC 9110 requires for If-None-Match
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter holds back the status and body until the handler is done
+type etagWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+// WriteHeaderNow is deferred to flush
+func (w *etagWriter) WriteHeaderNow() {}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *etagWriter) Status() int {
+	return w.status
+}
+
+func (w *etagWriter) Size() int {
+	return w.body.Len()
+}
+
+// flush sends the buffered status and body unchanged
+func (w *etagWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.ResponseWriter.Write(w.body.Bytes())
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientFingerprintMiddleware computes a hashed client fingerprint and stores it in the context
+// Apply this to routes that record fingerprints (match submission and confirmation)
+func ClientFingerprintMiddleware(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fingerprint := utils.ClientFingerprint(c.ClientIP(), c.Request.UserAgent(), secret)
+		c.Set("client_fingerprint", fingerprint)
+		c.Next()
+	}
+}
+
+// GetClientFingerprint returns the client fingerprint stored by ClientFingerprintMiddleware
+func GetClientFingerprint(c *gin.Context) string {
+	fingerprint, exists := c.Get("client_fingerprint")
+	if !exists {
+		return ""
+	}
+
+	fp, _ := fingerprint.(string)
+	return fp
+}
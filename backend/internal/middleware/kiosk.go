@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/revocation"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// KioskKeyHeader carries the API key of a table-side kiosk
+const KioskKeyHeader = "X-Kiosk-Key"
+
+// KioskKeyMiddleware requires the API key of a kiosk configured in KIOSK_API_KEYS
+// keys maps kiosk names to the SHA-256 hex digest of their key
+func KioskKeyMiddleware(keys map[string]string) gin.HandlerFunc {
+	hashes := make(map[string][]byte, len(keys))
+	for name, hash := range keys {
+		hashes[name] = []byte(strings.ToLower(hash))
+	}
+
+	return func(c *gin.Context) {
+		if key := c.GetHeader(KioskKeyHeader); key != "" {
+			hash := []byte(HashClientKey(key))
+			for name, expected := range hashes {
+				if subtle.ConstantTimeCompare(hash, expected) == 1 {
+					c.Set("kiosk", name)
+					c.Next()
+					return
+				}
+			}
+		}
+
+		utils.RespondWithError(c, http.StatusUnauthorized, "kiosk key required", nil)
+		c.Abort()
+	}
+}
+
+// GetKiosk returns the kiosk name set by KioskKeyMiddleware, or "" outside kiosk routes
+func GetKiosk(c *gin.Context) string {
+	return c.GetString("kiosk")
+}
+
+// KioskSessionMiddleware requires a kiosk session token issued to the calling kiosk
+// and acts as the student it was issued for. Must run after KioskKeyMiddleware
+// denyList may be nil when token revocation is not configured
+func KioskSessionMiddleware(jwtSecret string, denyList *revocation.DenyList) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := TokenFromRequest(c)
+		if tokenString == "" {
+			utils.RespondWithError(c, http.StatusUnauthorized, "kiosk session required", nil)
+			c.Abort()
+			return
+		}
+
+		claims, err := utils.ValidateKioskJWT(tokenString, GetKiosk(c), jwtSecret)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusUnauthorized, "invalid kiosk session", nil)
+			c.Abort()
+			return
+		}
+
+		if denyList != nil && denyList.IsRevoked(c.Request.Context(), claims) {
+			utils.RespondWithError(c, http.StatusUnauthorized, "token revoked", nil)
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Next()
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitBucket is what a limiter currently holds for one key of a client
+type RateLimitBucket struct {
+	Limiter   string    `json:"limiter"`
+	Key       string    `json:"key"`     // The client key, with method and route for per-endpoint limits
+	Trusted   bool      `json:"trusted"` // Counted against the trusted tier's limit
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"` // When the full limit is available again
+}
+
+// RateLimitInspector looks up and clears the buckets a limiter keeps for a client
+// A client is a key such as UserRateLimitKey or IPRateLimitKey; buckets of that key
+// combined with an endpoint (CombinedKeyFunc) belong to it as well
+type RateLimitInspector interface {
+	Buckets(ctx context.Context, subject string) ([]RateLimitBucket, error)
+	ResetSubject(ctx context.Context, subject string) (int, error)
+}
+
+// matchesSubject reports whether a bucket key belongs to a client
+func matchesSubject(key, subject string) bool {
+	return key == subject || strings.HasPrefix(key, subject+":")
+}
+
+// RateLimitRegistry collects the limiters of the application for admin inspection
+type RateLimitRegistry struct {
+	mu       sync.RWMutex
+	limiters map[string]RateLimitInspector
+}
+
+// NewRateLimitRegistry creates an empty registry
+func NewRateLimitRegistry() *RateLimitRegistry {
+	return &RateLimitRegistry{limiters: make(map[string]RateLimitInspector)}
+}
+
+// Register adds a limiter under the name reported in its buckets
+func (r *RateLimitRegistry) Register(name string, limiter RateLimitInspector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limiters[name] = limiter
+}
+
+// Buckets returns the buckets of a client across all limiters, ordered by limiter and key
+func (r *RateLimitRegistry) Buckets(ctx context.Context, subject string) ([]RateLimitBucket, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	buckets := []RateLimitBucket{}
+	for name, limiter := range r.limiters {
+		found, err := limiter.Buckets(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range found {
+			b.Limiter = name
+			buckets = append(buckets, b)
+		}
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Limiter != buckets[j].Limiter {
+			return buckets[i].Limiter < buckets[j].Limiter
+		}
+		if buckets[i].Key != buckets[j].Key {
+			return buckets[i].Key < buckets[j].Key
+		}
+		return !buckets[i].Trusted && buckets[j].Trusted
+	})
+	return buckets, nil
+}
+
+// Reset clears the buckets of a client in all limiters and returns how many were cleared
+func (r *RateLimitRegistry) Reset(ctx context.Context, subject string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	total := 0
+	for _, limiter := range r.limiters {
+		cleared, err := limiter.ResetSubject(ctx, subject)
+		total += cleared
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,6 +20,17 @@ type RateLimiter struct {
 	refillRate   time.Duration // How often to add a token
 	cleanupEvery time.Duration // How often to cleanup old buckets
 	stopCleanup  chan struct{}
+	window       time.Duration
+	trusted      *RateLimiter // Separate buckets for trusted clients, nil = standard limits
+}
+
+// RateLimitStatus is the outcome of counting one request against a limit
+type RateLimitStatus struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Time     // When the full limit is available again
+	RetryAfter time.Duration // Until the next request is allowed, 0 when this one was
 }
 
 type bucket struct {
@@ -33,6 +48,7 @@ func NewRateLimiter(maxRequests int, window time.Duration) *RateLimiter {
 		refillRate:   window / time.Duration(maxRequests),
 		cleanupEvery: 10 * time.Minute,
 		stopCleanup:  make(chan struct{}),
+		window:       window,
 	}
 
 	// Start cleanup goroutine
@@ -43,6 +59,11 @@ func NewRateLimiter(maxRequests int, window time.Duration) *RateLimiter {
 
 // Allow checks if a request from the given key should be allowed
 func (rl *RateLimiter) Allow(key string) bool {
+	return rl.Take(key).Allowed
+}
+
+// Take counts a request from the given key and reports what is left of the limit
+func (rl *RateLimiter) Take(key string) RateLimitStatus {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -50,28 +71,93 @@ func (rl *RateLimiter) Allow(key string) bool {
 	b, exists := rl.buckets[key]
 
 	if !exists {
-		rl.buckets[key] = &bucket{
-			tokens:    rl.maxTokens - 1, // Use one token for this request
+		b = &bucket{
+			tokens:    rl.maxTokens,
 			lastRefill: now,
 		}
-		return true
+		rl.buckets[key] = b
 	}
 
-	// Refill tokens based on elapsed time
+	rl.refill(b, now)
+
+	status := RateLimitStatus{Limit: rl.maxTokens}
+	if b.tokens > 0 {
+		b.tokens--
+		status.Allowed = true
+	} else {
+		status.RetryAfter = b.lastRefill.Add(rl.refillRate).Sub(now)
+	}
+	status.Remaining = b.tokens
+	status.Reset = rl.fullAt(b)
+
+	return status
+}
+
+// refill adds the tokens earned since the last refill, keeping the progress towards the next token
+func (rl *RateLimiter) refill(b *bucket, now time.Time) {
 	elapsed := now.Sub(b.lastRefill)
 	tokensToAdd := int(elapsed / rl.refillRate)
 
 	if tokensToAdd > 0 {
 		b.tokens = min(b.tokens+tokensToAdd, rl.maxTokens)
-		b.lastRefill = now
+		b.lastRefill = b.lastRefill.Add(time.Duration(tokensToAdd) * rl.refillRate)
+		if b.tokens == rl.maxTokens {
+			b.lastRefill = now
+		}
 	}
+}
 
-	if b.tokens > 0 {
-		b.tokens--
-		return true
+// fullAt returns when a bucket is full again
+func (rl *RateLimiter) fullAt(b *bucket) time.Time {
+	return b.lastRefill.Add(time.Duration(rl.maxTokens-b.tokens) * rl.refillRate)
+}
+
+// Buckets returns the buckets of a client, see RateLimitInspector
+func (rl *RateLimiter) Buckets(ctx context.Context, subject string) ([]RateLimitBucket, error) {
+	rl.mu.Lock()
+	now := time.Now()
+	var buckets []RateLimitBucket
+	for key, b := range rl.buckets {
+		if !matchesSubject(key, subject) {
+			continue
+		}
+		rl.refill(b, now)
+		buckets = append(buckets, RateLimitBucket{
+			Key:       key,
+			Limit:     rl.maxTokens,
+			Remaining: b.tokens,
+			Reset:     rl.fullAt(b),
+		})
 	}
+	rl.mu.Unlock()
 
-	return false
+	if rl.trusted != nil {
+		trusted, _ := rl.trusted.Buckets(ctx, subject)
+		for _, b := range trusted {
+			b.Trusted = true
+			buckets = append(buckets, b)
+		}
+	}
+	return buckets, nil
+}
+
+// ResetSubject drops the buckets of a client so it starts with the full limit again
+func (rl *RateLimiter) ResetSubject(ctx context.Context, subject string) (int, error) {
+	rl.mu.Lock()
+	cleared := 0
+	for key := range rl.buckets {
+		if matchesSubject(key, subject) {
+			delete(rl.buckets, key)
+			cleared++
+		}
+	}
+	rl.mu.Unlock()
+
+	if rl.trusted != nil {
+		trusted, _ := rl.trusted.ResetSubject(ctx, subject)
+		cleared += trusted
+	}
+	return cleared, nil
 }
 
 // cleanup periodically removes old buckets to prevent memory leaks
@@ -97,22 +183,40 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// WithTrustedTier gives trusted clients their own buckets with multiplier times the limit
+func (rl *RateLimiter) WithTrustedTier(multiplier int) *RateLimiter {
+	if multiplier > 1 {
+		rl.trusted = NewRateLimiter(rl.maxTokens*multiplier, rl.window)
+	}
+	return rl
+}
+
 // Stop stops the cleanup goroutine
 func (rl *RateLimiter) Stop() {
 	close(rl.stopCleanup)
+	if rl.trusted != nil {
+		rl.trusted.Stop()
+	}
 }
 
 // RateLimitMiddleware creates a Gin middleware for rate limiting
 // keyFunc determines how to identify requests (by IP, user ID, etc.)
+// Exempt clients skip the limit and trusted clients use the trusted tier if configured
 func RateLimitMiddleware(rl *RateLimiter, keyFunc func(*gin.Context) string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		key := keyFunc(c)
+		limiter := rl
+		switch RateLimitTier(c) {
+		case models.RateLimitTierExempt:
+			c.Next()
+			return
+		case models.RateLimitTierTrusted:
+			if rl.trusted != nil {
+				limiter = rl.trusted
+			}
+		}
 
-		if !rl.Allow(key) {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "too many requests, please try again later",
-			})
-			c.Abort()
+		status := limiter.Take(keyFunc(c))
+		if !respondRateLimit(c, status) {
 			return
 		}
 
@@ -120,19 +224,51 @@ func RateLimitMiddleware(rl *RateLimiter, keyFunc func(*gin.Context) string) gin
 	}
 }
 
+// respondRateLimit sets the X-RateLimit headers and answers 429 with Retry-After once the limit is used up
+// It reports whether the request may proceed
+func respondRateLimit(c *gin.Context, status RateLimitStatus) bool {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(status.Reset.Unix(), 10))
+
+	if status.Allowed {
+		return true
+	}
+
+	// Whole seconds, rounded up so a client waiting this long is not rejected again
+	retryAfter := int64((status.RetryAfter + time.Second - 1) / time.Second)
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	utils.RespondWithError(c, http.StatusTooManyRequests, "too many requests, please try again later", nil)
+	c.Abort()
+	return false
+}
+
 // IPKeyFunc returns the client IP as the rate limit key
 func IPKeyFunc(c *gin.Context) string {
-	return c.ClientIP()
+	return IPRateLimitKey(c.ClientIP())
 }
 
 // UserOrIPKeyFunc returns user ID if authenticated, otherwise IP
 func UserOrIPKeyFunc(c *gin.Context) string {
 	if userID, ok := c.Get("user_id"); ok {
 		if id, ok := userID.(int); ok {
-			return "user:" + string(rune(id))
+			return UserRateLimitKey(id)
 		}
 	}
-	return "ip:" + c.ClientIP()
+	return IPRateLimitKey(c.ClientIP())
+}
+
+// UserRateLimitKey is the rate limit key of an authenticated user
+func UserRateLimitKey(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+// IPRateLimitKey is the rate limit key of a client IP
+func IPRateLimitKey(ip string) string {
+	return "ip:" + ip
 }
 
 // CombinedKeyFunc returns a combination of user ID (or IP) and endpoint
@@ -40,7 +40,7 @@ func RecoveryMiddlewareWithConfig(cfg RecoveryConfig) gin.HandlerFunc {
 				stack := debug.Stack()
 
 				// Log the error
-				slog.Error("Panic recovered",
+				slog.ErrorContext(c.Request.Context(), "Panic recovered",
 					"error", fmt.Sprintf("%v", err),
 					"path", c.Request.URL.Path,
 					"method", c.Request.Method,
@@ -49,7 +49,7 @@ func RecoveryMiddlewareWithConfig(cfg RecoveryConfig) gin.HandlerFunc {
 
 				// Log stack trace if enabled
 				if cfg.EnableStackTrace {
-					slog.Error("Stack trace", "stack", string(stack))
+					slog.ErrorContext(c.Request.Context(), "Stack trace", "stack", string(stack))
 				}
 
 				// Call custom panic handler if provided
@@ -60,8 +60,9 @@ func RecoveryMiddlewareWithConfig(cfg RecoveryConfig) gin.HandlerFunc {
 				// Abort with internal server error
 				// Don't expose internal error details to client
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-					"error":   "internal server error",
-					"message": "An unexpected error occurred. Please try again later.",
+					"error":      "internal server error",
+					"message":    "An unexpected error occurred. Please try again later.",
+					"request_id": GetRequestID(c),
 				})
 			}
 		}()
@@ -76,12 +77,13 @@ func ErrorBoundary(handler gin.HandlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				slog.Error("Handler panic recovered",
+				slog.ErrorContext(c.Request.Context(), "Handler panic recovered",
 					"error", fmt.Sprintf("%v", err),
 					"path", c.Request.URL.Path,
 				)
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-					"error": "internal server error",
+					"error":      "internal server error",
+					"request_id": GetRequestID(c),
 				})
 			}
 		}()
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageTracker counts authenticated requests per user, route template and day
+// Successful requests are sampled and scaled up; throttled and failed requests are
+// always counted since they are what operators look for. Only route templates are
+// recorded (/api/matches/:id), never concrete paths, query strings or IPs.
+type UsageTracker struct {
+	mu         sync.Mutex
+	counts     map[usageKey]*usageCounter
+	sampleRate float64
+	weight     int64 // Requests represented by one sampled request
+}
+
+type usageKey struct {
+	userID int
+	method string
+	route  string
+	day    string
+}
+
+type usageCounter struct {
+	requests  int64
+	throttled int64
+	errors    int64
+}
+
+// NewUsageTracker creates a tracker sampling the given fraction of requests (0 < rate <= 1)
+func NewUsageTracker(sampleRate float64) *UsageTracker {
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
+	return &UsageTracker{
+		counts:     make(map[usageKey]*usageCounter),
+		sampleRate: sampleRate,
+		weight:     int64(math.Round(1 / sampleRate)),
+	}
+}
+
+// Middleware records the request after the handler chain ran, so the user ID set by
+// AuthMiddleware and the final status (including 429 from rate limiting) are known
+func (t *UsageTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID, ok := GetUserID(c)
+		route := c.FullPath()
+		if !ok || route == "" {
+			return
+		}
+
+		status := c.Writer.Status()
+		throttled := status == http.StatusTooManyRequests
+		failed := status >= http.StatusInternalServerError
+
+		requests := int64(1)
+		if !throttled && !failed {
+			if t.sampleRate < 1 && rand.Float64() >= t.sampleRate {
+				return
+			}
+			requests = t.weight
+		}
+
+		key := usageKey{
+			userID: userID,
+			method: c.Request.Method,
+			route:  route,
+			day:    time.Now().UTC().Format("2006-01-02"),
+		}
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		counter, exists := t.counts[key]
+		if !exists {
+			counter = &usageCounter{}
+			t.counts[key] = counter
+		}
+		counter.requests += requests
+		if throttled {
+			counter.throttled++
+		}
+		if failed {
+			counter.errors++
+		}
+	}
+}
+
+// Drain returns the counts collected since the last drain and resets the tracker
+func (t *UsageTracker) Drain() []models.APIUsageCount {
+	t.mu.Lock()
+	counts := t.counts
+	t.counts = make(map[usageKey]*usageCounter)
+	t.mu.Unlock()
+
+	result := make([]models.APIUsageCount, 0, len(counts))
+	for key, counter := range counts {
+		day, _ := time.Parse("2006-01-02", key.day)
+		result = append(result, models.APIUsageCount{
+			UserID: key.userID,
+			Day:    day,
+			APIEndpointUsage: models.APIEndpointUsage{
+				Method:    key.method,
+				Route:     key.route,
+				Requests:  counter.requests,
+				Throttled: counter.throttled,
+				Errors:    counter.errors,
+			},
+		})
+	}
+	return result
+}
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+-- Hashed client fingerprints (IP prefix + user agent hash) recorded on
+-- submission and confirmation. Only exposed to admins for anomaly detection,
+-- e.g. spotting one person controlling both sides of a confirmation.
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS submit_fingerprint VARCHAR(64);
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS confirm_fingerprint VARCHAR(64);
+
+-- Partial index for the admin anomaly queue (same device on both sides)
+CREATE INDEX IF NOT EXISTS idx_matches_fingerprint_match
+ON matches(confirmed_at DESC)
+WHERE submit_fingerprint IS NOT NULL AND submit_fingerprint = confirm_fingerprint;
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_matches_fingerprint_match;
+ALTER TABLE matches DROP COLUMN IF EXISTS confirm_fingerprint;
+ALTER TABLE matches DROP COLUMN IF EXISTS submit_fingerprint;
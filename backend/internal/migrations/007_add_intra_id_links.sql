@@ -0,0 +1,19 @@
+-- +migrate Up
+
+-- Maps new intra IDs to an existing profile after 42 account migrations.
+-- users.id is the original intra ID; a link lets a new intra ID log in as
+-- that profile so ELO and match history are preserved.
+CREATE TABLE IF NOT EXISTS intra_id_links (
+    intra_id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('admin', 'self_service')),
+    linked_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_intra_id_links_user_id ON intra_id_links(user_id);
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_intra_id_links_user_id;
+DROP TABLE IF EXISTS intra_id_links;
@@ -0,0 +1,51 @@
+-- +migrate Up
+
+-- Vocabulary used to build anonymous display names for logged-out visitors.
+-- campus = '' is the global default; campuses can add their own words, which
+-- replace the defaults for that campus once both word kinds are present.
+CREATE TABLE IF NOT EXISTS anonymization_words (
+    id SERIAL PRIMARY KEY,
+    campus VARCHAR(100) NOT NULL DEFAULT '',
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('adjective', 'animal')),
+    word VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (campus, kind, word)
+);
+
+CREATE INDEX IF NOT EXISTS idx_anonymization_words_campus ON anonymization_words(campus, kind);
+
+-- Persisted anonymous name per user. The unique constraint on name guarantees
+-- that no two players are shown under the same alias.
+CREATE TABLE IF NOT EXISTS anonymous_names (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(120) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Default vocabulary (previously hardcoded)
+INSERT INTO anonymization_words (campus, kind, word)
+SELECT '', 'adjective', w FROM unnest(ARRAY[
+    'Swift', 'Silent', 'Mighty', 'Clever', 'Bold',
+    'Fierce', 'Mystic', 'Noble', 'Brave', 'Quick',
+    'Stealthy', 'Cosmic', 'Thunder', 'Shadow', 'Crystal',
+    'Golden', 'Silver', 'Iron', 'Blazing', 'Frozen',
+    'Ancient', 'Electric', 'Phantom', 'Radiant', 'Stellar',
+    'Crimson', 'Azure', 'Emerald', 'Obsidian', 'Jade'
+]) AS w
+ON CONFLICT DO NOTHING;
+
+INSERT INTO anonymization_words (campus, kind, word)
+SELECT '', 'animal', w FROM unnest(ARRAY[
+    'Penguin', 'Fox', 'Wolf', 'Eagle', 'Tiger',
+    'Dragon', 'Phoenix', 'Falcon', 'Panther', 'Bear',
+    'Hawk', 'Lion', 'Shark', 'Cobra', 'Raven',
+    'Owl', 'Leopard', 'Viper', 'Lynx', 'Puma',
+    'Jaguar', 'Scorpion', 'Mantis', 'Griffin', 'Hydra',
+    'Sphinx', 'Kraken', 'Chimera', 'Basilisk', 'Wyvern'
+]) AS w
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS anonymous_names;
+DROP TABLE IF EXISTS anonymization_words;
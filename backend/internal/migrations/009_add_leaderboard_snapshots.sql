@@ -0,0 +1,21 @@
+-- +migrate Up
+
+-- Daily leaderboard snapshots, written by the background snapshot job.
+-- One row per user, sport and day; re-running the job on the same day
+-- overwrites that day's snapshot.
+CREATE TABLE IF NOT EXISTS leaderboard_snapshots (
+    snapshot_date DATE NOT NULL,
+    sport_id VARCHAR(50) NOT NULL REFERENCES sports(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    elo INTEGER NOT NULL,
+    matches_played INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (snapshot_date, sport_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leaderboard_snapshots_user ON leaderboard_snapshots(user_id, sport_id, snapshot_date DESC);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS leaderboard_snapshots;
@@ -0,0 +1,19 @@
+-- +migrate Up
+
+-- Per-sport rules for pending matches between the same pair of players.
+--   strict:   at most one pending match per pair (default, previous behaviour)
+--   multiple: several pending matches allowed, e.g. for back-to-back games,
+--             as long as submissions are spaced by pending_min_interval_seconds
+--             and the pair stays below max_pending_per_pair
+ALTER TABLE sports ADD COLUMN IF NOT EXISTS pending_mode VARCHAR(20) NOT NULL DEFAULT 'strict'
+    CHECK (pending_mode IN ('strict', 'multiple'));
+ALTER TABLE sports ADD COLUMN IF NOT EXISTS pending_min_interval_seconds INTEGER NOT NULL DEFAULT 60
+    CHECK (pending_min_interval_seconds >= 0);
+ALTER TABLE sports ADD COLUMN IF NOT EXISTS max_pending_per_pair INTEGER NOT NULL DEFAULT 5
+    CHECK (max_pending_per_pair >= 1);
+
+-- +migrate Down
+
+ALTER TABLE sports DROP COLUMN IF EXISTS max_pending_per_pair;
+ALTER TABLE sports DROP COLUMN IF EXISTS pending_min_interval_seconds;
+ALTER TABLE sports DROP COLUMN IF EXISTS pending_mode;
@@ -0,0 +1,37 @@
+-- +migrate Up
+
+-- Seasons: at most one season is open (ended_at IS NULL) at any time.
+-- Opening a season soft-resets ELO towards each sport's default by reset_factor
+-- (0 = full reset, 1 = no reset).
+CREATE TABLE IF NOT EXISTS seasons (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    reset_factor NUMERIC(3, 2) NOT NULL DEFAULT 0.50 CHECK (reset_factor >= 0 AND reset_factor <= 1),
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ended_at TIMESTAMP,
+    opened_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    closed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_seasons_single_open ON seasons((ended_at IS NULL)) WHERE ended_at IS NULL;
+
+-- Final standings archived when a season is closed
+CREATE TABLE IF NOT EXISTS season_results (
+    season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    sport_id VARCHAR(50) NOT NULL REFERENCES sports(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    elo INTEGER NOT NULL,
+    matches_played INTEGER NOT NULL DEFAULT 0,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (season_id, sport_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_season_results_rank ON season_results(season_id, sport_id, rank);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS season_results;
+DROP TABLE IF EXISTS seasons;
@@ -0,0 +1,20 @@
+-- +migrate Up
+
+-- Corrected score attached by the opponent when denying a match. If the
+-- original submitter accepts it, the match is confirmed with these scores.
+CREATE TABLE IF NOT EXISTS match_counter_proposals (
+    match_id INTEGER PRIMARY KEY REFERENCES matches(id) ON DELETE CASCADE,
+    player1_score INTEGER NOT NULL CHECK (player1_score >= 0),
+    player2_score INTEGER NOT NULL CHECK (player2_score >= 0),
+    proposed_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    CHECK (player1_score <> player2_score)
+);
+
+CREATE INDEX IF NOT EXISTS idx_counter_proposals_pending ON match_counter_proposals(status) WHERE status = 'pending';
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS match_counter_proposals;
@@ -0,0 +1,72 @@
+-- +migrate Up
+
+-- Cold storage for finished matches from past seasons. The archive mirrors the
+-- matches table column for column, so any column added to matches must be added
+-- here as well (and to the matches_all view).
+CREATE TABLE IF NOT EXISTS matches_archive (
+    LIKE matches INCLUDING DEFAULTS INCLUDING CONSTRAINTS INCLUDING INDEXES
+);
+
+ALTER TABLE matches_archive
+    ADD CONSTRAINT matches_archive_sport_fk FOREIGN KEY (sport) REFERENCES sports(id),
+    ADD CONSTRAINT matches_archive_player1_fk FOREIGN KEY (player1_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT matches_archive_player2_fk FOREIGN KEY (player2_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT matches_archive_winner_fk FOREIGN KEY (winner_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT matches_archive_submitter_fk FOREIGN KEY (submitted_by) REFERENCES users(id) ON DELETE CASCADE;
+
+-- Hot and archived matches together, for history queries spanning all seasons
+CREATE OR REPLACE VIEW matches_all AS
+    SELECT * FROM matches
+    UNION ALL
+    SELECT * FROM matches_archive;
+
+-- Comments, reactions and counter-proposals stay attached when a match moves to the
+-- archive, so their foreign keys to matches are replaced by a cleanup trigger that
+-- fires when a match is really deleted from either table.
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_match_id_fkey;
+ALTER TABLE reactions DROP CONSTRAINT IF EXISTS reactions_match_id_fkey;
+ALTER TABLE match_counter_proposals DROP CONSTRAINT IF EXISTS match_counter_proposals_match_id_fkey;
+
+CREATE OR REPLACE FUNCTION delete_match_dependents()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Moving a match into the archive is not a delete
+    IF current_setting('elo.archiving', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+
+    DELETE FROM comments WHERE match_id = OLD.id;
+    DELETE FROM reactions WHERE match_id = OLD.id;
+    DELETE FROM match_counter_proposals WHERE match_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER delete_match_dependents
+    AFTER DELETE ON matches
+    FOR EACH ROW
+    EXECUTE FUNCTION delete_match_dependents();
+
+CREATE TRIGGER delete_archived_match_dependents
+    AFTER DELETE ON matches_archive
+    FOR EACH ROW
+    EXECUTE FUNCTION delete_match_dependents();
+
+-- +migrate Down
+
+-- Move archived matches back before restoring the foreign keys
+INSERT INTO matches SELECT * FROM matches_archive;
+
+DROP TRIGGER IF EXISTS delete_archived_match_dependents ON matches_archive;
+DROP TRIGGER IF EXISTS delete_match_dependents ON matches;
+DROP FUNCTION IF EXISTS delete_match_dependents();
+
+DROP VIEW IF EXISTS matches_all;
+DROP TABLE IF EXISTS matches_archive;
+
+ALTER TABLE comments ADD CONSTRAINT comments_match_id_fkey
+    FOREIGN KEY (match_id) REFERENCES matches(id) ON DELETE CASCADE;
+ALTER TABLE reactions ADD CONSTRAINT reactions_match_id_fkey
+    FOREIGN KEY (match_id) REFERENCES matches(id) ON DELETE CASCADE;
+ALTER TABLE match_counter_proposals ADD CONSTRAINT match_counter_proposals_match_id_fkey
+    FOREIGN KEY (match_id) REFERENCES matches(id) ON DELETE CASCADE;
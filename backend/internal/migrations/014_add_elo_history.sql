@@ -0,0 +1,36 @@
+-- +migrate Up
+
+-- Every ELO change of a player, for rating graphs. match_id has no foreign key
+-- because matches move to matches_archive and reverted matches are deleted.
+CREATE TABLE IF NOT EXISTS elo_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sport_id VARCHAR(50) NOT NULL REFERENCES sports(id),
+    elo_before INTEGER NOT NULL,
+    elo_after INTEGER NOT NULL,
+    delta INTEGER NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('match', 'revert', 'adjustment', 'season_reset')),
+    match_id INTEGER,
+    adjustment_id INTEGER REFERENCES elo_adjustments(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_elo_history_user_sport_time ON elo_history(user_id, sport_id, created_at);
+
+-- Backfill from confirmed matches and past admin adjustments
+INSERT INTO elo_history (user_id, sport_id, elo_before, elo_after, delta, source, match_id, created_at)
+SELECT player1_id, sport, player1_elo_before, player1_elo_after, COALESCE(player1_elo_delta, player1_elo_after - player1_elo_before), 'match', id, COALESCE(confirmed_at, created_at)
+FROM matches_all
+WHERE status = 'confirmed' AND player1_elo_before IS NOT NULL AND player1_elo_after IS NOT NULL
+UNION ALL
+SELECT player2_id, sport, player2_elo_before, player2_elo_after, COALESCE(player2_elo_delta, player2_elo_after - player2_elo_before), 'match', id, COALESCE(confirmed_at, created_at)
+FROM matches_all
+WHERE status = 'confirmed' AND player2_elo_before IS NOT NULL AND player2_elo_after IS NOT NULL;
+
+INSERT INTO elo_history (user_id, sport_id, elo_before, elo_after, delta, source, adjustment_id, created_at)
+SELECT user_id, sport, old_elo, new_elo, new_elo - old_elo, 'adjustment', id, created_at
+FROM elo_adjustments;
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS elo_history;
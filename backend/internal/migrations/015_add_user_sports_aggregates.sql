@@ -0,0 +1,15 @@
+-- +migrate Up
+
+-- Profile aggregates maintained on every confirmation and revert, so profiles
+-- don't recompute them from the matches table. current_streak is positive for
+-- consecutive wins and negative for consecutive losses. The consistency job
+-- backfills these columns on its first run and reconciles drift afterwards.
+ALTER TABLE user_sports ADD COLUMN IF NOT EXISTS current_streak INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_sports ADD COLUMN IF NOT EXISTS longest_win_streak INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_sports ADD COLUMN IF NOT EXISTS last_match_at TIMESTAMP;
+
+-- +migrate Down
+
+ALTER TABLE user_sports DROP COLUMN IF EXISTS last_match_at;
+ALTER TABLE user_sports DROP COLUMN IF EXISTS longest_win_streak;
+ALTER TABLE user_sports DROP COLUMN IF EXISTS current_streak;
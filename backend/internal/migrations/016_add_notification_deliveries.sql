@@ -0,0 +1,22 @@
+-- +migrate Up
+
+-- Every outbound notification/webhook attempt, so operators can see why
+-- messages stopped arriving (bad webhook URL, rate limits, outages)
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    channel VARCHAR(100) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('delivered', 'failed')),
+    status_code INTEGER,
+    error TEXT,
+    response_body TEXT,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_created ON notification_deliveries(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_channel ON notification_deliveries(channel, created_at DESC);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS notification_deliveries;
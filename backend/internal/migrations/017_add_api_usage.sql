@@ -0,0 +1,20 @@
+-- +migrate Up
+
+-- Per-user API usage, aggregated per route template and day
+-- Only authenticated requests are counted; no IPs, paths with IDs or query strings are stored
+CREATE TABLE IF NOT EXISTS api_usage (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    day DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    throttled BIGINT NOT NULL DEFAULT 0,
+    errors BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, method, route, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_day ON api_usage(day);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS api_usage;
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+-- Keyset pagination of the match feed orders by (created_at, id); this index
+-- lets cursor pages seek directly instead of scanning past an offset
+CREATE INDEX IF NOT EXISTS idx_matches_created_id ON matches(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_matches_archive_created_id ON matches_archive(created_at DESC, id DESC);
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_matches_archive_created_id;
+DROP INDEX IF EXISTS idx_matches_created_id;
@@ -0,0 +1,21 @@
+-- +migrate Up
+
+-- Versioned legal texts (Impressum, Datenschutzerklaerung, Nutzungsbedingungen)
+-- Versions are append-only so consent records can reference the exact text a user saw
+CREATE TABLE IF NOT EXISTS legal_documents (
+    id SERIAL PRIMARY KEY,
+    doc VARCHAR(50) NOT NULL CHECK (doc IN ('impressum', 'datenschutz', 'nutzungsbedingungen')),
+    lang VARCHAR(5) NOT NULL,
+    version INTEGER NOT NULL CHECK (version > 0),
+    title VARCHAR(200) NOT NULL,
+    content TEXT NOT NULL,
+    published_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    published_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (doc, lang, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_legal_documents_latest ON legal_documents(doc, lang, version DESC);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS legal_documents;
@@ -0,0 +1,47 @@
+-- +migrate Up
+
+-- Forfeits and walkovers: a match can be won without being played. Scores of
+-- forfeits are 0:0 and forfeited_by names the player who did not play.
+ALTER TABLE matches
+    ADD COLUMN IF NOT EXISTS result VARCHAR(20) NOT NULL DEFAULT 'played',
+    ADD COLUMN IF NOT EXISTS forfeited_by INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE matches
+    ADD CONSTRAINT matches_result_check CHECK (result IN ('played', 'forfeit')),
+    ADD CONSTRAINT matches_forfeit_check CHECK (
+        (result = 'forfeit') = (forfeited_by IS NOT NULL)
+        AND (forfeited_by IS NULL OR (forfeited_by IN (player1_id, player2_id) AND forfeited_by <> winner_id))
+    );
+
+-- The archive mirrors matches column for column
+ALTER TABLE matches_archive
+    ADD COLUMN IF NOT EXISTS result VARCHAR(20) NOT NULL DEFAULT 'played',
+    ADD COLUMN IF NOT EXISTS forfeited_by INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE OR REPLACE VIEW matches_all AS
+    SELECT * FROM matches
+    UNION ALL
+    SELECT * FROM matches_archive;
+
+-- Rating changes of forfeits are marked in the ELO history
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'forfeit', 'revert', 'adjustment', 'season_reset'));
+
+-- +migrate Down
+
+UPDATE elo_history SET source = 'match' WHERE source = 'forfeit';
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'revert', 'adjustment', 'season_reset'));
+
+DROP VIEW IF EXISTS matches_all;
+
+ALTER TABLE matches_archive DROP COLUMN IF EXISTS forfeited_by, DROP COLUMN IF EXISTS result;
+ALTER TABLE matches DROP CONSTRAINT IF EXISTS matches_forfeit_check, DROP CONSTRAINT IF EXISTS matches_result_check;
+ALTER TABLE matches DROP COLUMN IF EXISTS forfeited_by, DROP COLUMN IF EXISTS result;
+
+CREATE VIEW matches_all AS
+    SELECT * FROM matches
+    UNION ALL
+    SELECT * FROM matches_archive;
@@ -0,0 +1,22 @@
+-- +migrate Up
+
+-- Slack accounts linked to intra users for the /elo slash command
+CREATE TABLE IF NOT EXISTS slack_links (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    slack_team_id VARCHAR(32) NOT NULL,
+    slack_user_id VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (slack_team_id, slack_user_id)
+);
+
+-- Short-lived codes a logged-in user redeems in Slack with "/elo link <code>"
+CREATE TABLE IF NOT EXISTS slack_link_codes (
+    code VARCHAR(16) PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS slack_link_codes;
+DROP TABLE IF EXISTS slack_links;
@@ -0,0 +1,23 @@
+-- +migrate Up
+
+-- Admin-managed incident annotations shown on the public status page
+CREATE TABLE IF NOT EXISTS status_incidents (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    impact VARCHAR(20) NOT NULL CHECK (impact IN ('degraded', 'outage', 'maintenance')),
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_incidents_started ON status_incidents(started_at DESC);
+
+CREATE TRIGGER update_status_incidents_updated_at BEFORE UPDATE ON status_incidents
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS status_incidents;
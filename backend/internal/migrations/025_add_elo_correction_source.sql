@@ -0,0 +1,13 @@
+-- +migrate Up
+
+-- Admin corrections of confirmed matches reapply the rating difference, marked in the ELO history
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'forfeit', 'correction', 'revert', 'adjustment', 'season_reset'));
+
+-- +migrate Down
+
+UPDATE elo_history SET source = 'adjustment' WHERE source = 'correction';
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'forfeit', 'revert', 'adjustment', 'season_reset'));
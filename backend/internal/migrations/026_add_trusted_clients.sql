@@ -0,0 +1,21 @@
+-- +migrate Up
+
+-- Kiosks, display screens and admin tooling with relaxed rate limits
+-- Clients send their key in X-Client-Key; only its SHA-256 hash is stored
+CREATE TABLE IF NOT EXISTS trusted_clients (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    tier VARCHAR(20) NOT NULL CHECK (tier IN ('trusted', 'exempt')),
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_trusted_clients_updated_at BEFORE UPDATE ON trusted_clients
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS trusted_clients;
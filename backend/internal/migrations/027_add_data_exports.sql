@@ -0,0 +1,26 @@
+-- +migrate Up
+
+-- Asynchronous GDPR data exports (Art. 15/20), built by a background job
+-- The zipped archive is kept until its signed download link expires
+CREATE TABLE IF NOT EXISTS data_exports (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'ready', 'failed')),
+    archive BYTEA,
+    size_bytes INTEGER,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user ON data_exports(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_data_exports_queue ON data_exports(created_at) WHERE status IN ('pending', 'processing');
+
+-- At most one export per user is queued or being built
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_one_active ON data_exports(user_id) WHERE status IN ('pending', 'processing');
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS data_exports;
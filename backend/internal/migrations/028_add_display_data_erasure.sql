@@ -0,0 +1,9 @@
+-- +migrate Up
+
+-- Users who erased their display data keep an active account; the login sync
+-- must not restore their 42 display name and avatar
+ALTER TABLE users ADD COLUMN IF NOT EXISTS display_data_erased_at TIMESTAMP;
+
+-- +migrate Down
+
+ALTER TABLE users DROP COLUMN IF EXISTS display_data_erased_at;
@@ -0,0 +1,8 @@
+-- +migrate Up
+
+-- Language of the user's notifications (inbox and live pushes)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(5) NOT NULL DEFAULT 'en' CHECK (language IN ('en', 'de'));
+
+-- +migrate Down
+
+ALTER TABLE users DROP COLUMN IF EXISTS language;
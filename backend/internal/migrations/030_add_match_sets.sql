@@ -0,0 +1,47 @@
+-- +migrate Up
+
+-- Per-set scores of best-of-N series. The match itself stores the number of sets
+-- won by each player, so its winner follows from the sets. Like comments, sets stay
+-- attached when a match moves to the archive and are removed by the cleanup trigger.
+CREATE TABLE IF NOT EXISTS match_sets (
+    match_id INTEGER NOT NULL,
+    set_number SMALLINT NOT NULL CHECK (set_number BETWEEN 1 AND 7),
+    player1_score INTEGER NOT NULL CHECK (player1_score >= 0),
+    player2_score INTEGER NOT NULL CHECK (player2_score >= 0),
+    PRIMARY KEY (match_id, set_number),
+    CHECK (player1_score <> player2_score)
+);
+
+CREATE OR REPLACE FUNCTION delete_match_dependents()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Moving a match into the archive is not a delete
+    IF current_setting('elo.archiving', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+
+    DELETE FROM comments WHERE match_id = OLD.id;
+    DELETE FROM reactions WHERE match_id = OLD.id;
+    DELETE FROM match_counter_proposals WHERE match_id = OLD.id;
+    DELETE FROM match_sets WHERE match_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+-- +migrate Down
+
+CREATE OR REPLACE FUNCTION delete_match_dependents()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF current_setting('elo.archiving', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+
+    DELETE FROM comments WHERE match_id = OLD.id;
+    DELETE FROM reactions WHERE match_id = OLD.id;
+    DELETE FROM match_counter_proposals WHERE match_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS match_sets;
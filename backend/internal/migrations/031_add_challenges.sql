@@ -0,0 +1,34 @@
+-- +migrate Up
+
+-- Scheduled matches: a player challenges another for a sport and time slot. Once the
+-- challenge is accepted and played, entering the result turns it into a pending match
+-- that is confirmed like any other. match_id has no foreign key because matches move
+-- to the archive.
+CREATE TABLE IF NOT EXISTS challenges (
+    id SERIAL PRIMARY KEY,
+    sport VARCHAR(50) NOT NULL REFERENCES sports(id),
+    challenger_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    opponent_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scheduled_at TIMESTAMP NOT NULL,
+    message TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled', 'completed')),
+    match_id INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    responded_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (challenger_id <> opponent_id),
+    CHECK ((status = 'completed') = (match_id IS NOT NULL))
+);
+
+-- A slot can only be challenged once while the challenge is open
+CREATE UNIQUE INDEX IF NOT EXISTS idx_challenges_open_slot
+    ON challenges (challenger_id, opponent_id, sport, scheduled_at)
+    WHERE status IN ('pending', 'accepted');
+
+CREATE INDEX IF NOT EXISTS idx_challenges_challenger ON challenges (challenger_id, scheduled_at DESC);
+CREATE INDEX IF NOT EXISTS idx_challenges_opponent ON challenges (opponent_id, scheduled_at DESC);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS challenges;
@@ -0,0 +1,16 @@
+-- +migrate Up
+
+-- Account deletions requested by users (Art. 17 GDPR). The account stays usable
+-- during a grace period in which the user can cancel; afterwards a background job
+-- erases it. Rows disappear with the account or when the request is cancelled.
+CREATE TABLE IF NOT EXISTS deletion_requests (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    requested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    erase_after TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_deletion_requests_erase_after ON deletion_requests (erase_after);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS deletion_requests;
@@ -0,0 +1,14 @@
+-- +migrate Up
+
+-- One level of replies: a reply points to a top-level comment of the same match.
+-- Replies disappear with the comment they answer.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_comment_id INTEGER
+    REFERENCES comments(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_comment_id
+    ON comments (parent_comment_id) WHERE parent_comment_id IS NOT NULL;
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_comments_parent_comment_id;
+ALTER TABLE comments DROP COLUMN IF EXISTS parent_comment_id;
@@ -0,0 +1,24 @@
+-- +migrate Up
+
+-- Coalitions as reported by the 42 API; refreshed whenever a member logs in
+CREATE TABLE IF NOT EXISTS coalitions (
+    id INTEGER PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL DEFAULT '',
+    color VARCHAR(16) NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Coalition and piscine year of each user, filled in on login; NULL until then
+ALTER TABLE users ADD COLUMN IF NOT EXISTS coalition_id INTEGER REFERENCES coalitions(id) ON DELETE SET NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pool_year VARCHAR(4);
+
+CREATE INDEX IF NOT EXISTS idx_users_coalition ON users (coalition_id) WHERE coalition_id IS NOT NULL;
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_users_coalition;
+ALTER TABLE users DROP COLUMN IF EXISTS pool_year;
+ALTER TABLE users DROP COLUMN IF EXISTS coalition_id;
+DROP TABLE IF EXISTS coalitions;
@@ -0,0 +1,17 @@
+-- +migrate Up
+
+-- Background refresh of avatars and display names from the 42 API.
+-- next_attempt_at limits how often a user is fetched and backs off after failures.
+CREATE TABLE IF NOT EXISTS profile_syncs (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    last_synced_at TIMESTAMP,
+    next_attempt_at TIMESTAMP NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_profile_syncs_next_attempt ON profile_syncs (next_attempt_at);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS profile_syncs;
@@ -0,0 +1,20 @@
+-- +migrate Up
+
+-- Roles replace the is_admin flag: moderator < admin < superadmin.
+-- Existing admins become superadmins so nobody loses a privilege they had.
+-- is_admin stays as a generated column (any staff role) for existing readers.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD CONSTRAINT valid_role CHECK (role IN ('user', 'moderator', 'admin', 'superadmin'));
+
+UPDATE users SET role = 'superadmin' WHERE is_admin;
+
+ALTER TABLE users DROP COLUMN is_admin;
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN GENERATED ALWAYS AS (role <> 'user') STORED;
+
+-- +migrate Down
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE users SET is_admin = (role <> 'user');
+ALTER TABLE users DROP CONSTRAINT IF EXISTS valid_role;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,13 @@
+-- +migrate Up
+
+-- Shadow-muted users keep commenting, but their new comments are only shown to themselves
+ALTER TABLE users ADD COLUMN IF NOT EXISTS comments_muted_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS comments_muted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS shadowed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+
+ALTER TABLE comments DROP COLUMN IF EXISTS shadowed;
+ALTER TABLE users DROP COLUMN IF EXISTS comments_muted_by;
+ALTER TABLE users DROP COLUMN IF EXISTS comments_muted_at;
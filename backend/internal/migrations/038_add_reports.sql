@@ -0,0 +1,25 @@
+-- +migrate Up
+
+-- Abuse reports on matches, comments and players. A reporter can have one open
+-- report per target; staff resolve or dismiss them and the reporter is notified.
+CREATE TABLE IF NOT EXISTS reports (
+    id SERIAL PRIMARY KEY,
+    reporter_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('match', 'comment', 'user')),
+    target_id INTEGER NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    details TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    resolution_note TEXT,
+    handled_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    handled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_open_per_reporter
+    ON reports (reporter_id, target_type, target_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_reports_status_created ON reports (status, created_at);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS reports;
@@ -0,0 +1,25 @@
+-- +migrate Up
+
+-- Confirmed matches flagged by the abuse scan (ELO farming, arranged results) for staff review
+-- A match is flagged at most once per rule; reverting or deleting the match drops its flags
+CREATE TABLE IF NOT EXISTS match_flags (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    rule VARCHAR(30) NOT NULL CHECK (rule IN ('pair_frequency', 'alternating_wins', 'elo_farming')),
+    details JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'confirmed')),
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (match_id, rule)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_flags_status_created ON match_flags (status, created_at);
+
+-- The scan looks up recent confirmed matches of a pair of players
+CREATE INDEX IF NOT EXISTS idx_matches_confirmed_at ON matches (confirmed_at) WHERE status = 'confirmed';
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_matches_confirmed_at;
+DROP TABLE IF EXISTS match_flags;
@@ -0,0 +1,21 @@
+-- +migrate Up
+
+-- Daily and weekly digests per sport: biggest upsets, most active players and rank movers
+-- Player entries only hold user IDs; names are resolved (and anonymized) when served
+CREATE TABLE IF NOT EXISTS digests (
+    id SERIAL PRIMARY KEY,
+    sport VARCHAR(50) NOT NULL,
+    period VARCHAR(10) NOT NULL CHECK (period IN ('daily', 'weekly')),
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    matches INTEGER NOT NULL DEFAULT 0,
+    upsets JSONB NOT NULL DEFAULT '[]',
+    most_active JSONB NOT NULL DEFAULT '[]',
+    rank_movers JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (sport, period, period_start)
+);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS digests;
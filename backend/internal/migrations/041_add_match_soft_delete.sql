@@ -0,0 +1,59 @@
+-- +migrate Up
+
+-- Deleted and reverted matches are kept with a deleted_at mark so admins can restore
+-- them. reverted records whether the ratings were rolled back when the match was
+-- removed, in which case restoring it reapplies the ELO changes.
+ALTER TABLE matches
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reverted BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The archive mirrors matches column for column
+ALTER TABLE matches_archive
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reverted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_matches_deleted_at ON matches(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_matches_archive_deleted_at ON matches_archive(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- History queries only ever see live matches
+CREATE OR REPLACE VIEW matches_all AS
+    SELECT * FROM matches WHERE deleted_at IS NULL
+    UNION ALL
+    SELECT * FROM matches_archive WHERE deleted_at IS NULL;
+
+-- Soft-deleted matches from both tables, for admin review and restore
+CREATE OR REPLACE VIEW archived_matches AS
+    SELECT * FROM matches WHERE deleted_at IS NOT NULL
+    UNION ALL
+    SELECT * FROM matches_archive WHERE deleted_at IS NOT NULL;
+
+-- Restores of reverted matches reapply their rating changes, marked in the ELO history
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'forfeit', 'correction', 'revert', 'restore', 'adjustment', 'season_reset'));
+
+-- +migrate Down
+
+UPDATE elo_history SET source = 'correction' WHERE source = 'restore';
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'forfeit', 'correction', 'revert', 'adjustment', 'season_reset'));
+
+DROP VIEW IF EXISTS archived_matches;
+DROP VIEW IF EXISTS matches_all;
+
+-- Soft-deleted matches were deleted before this migration
+DELETE FROM matches WHERE deleted_at IS NOT NULL;
+DELETE FROM matches_archive WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_matches_archive_deleted_at;
+DROP INDEX IF EXISTS idx_matches_deleted_at;
+ALTER TABLE matches_archive DROP COLUMN IF EXISTS reverted, DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE matches DROP COLUMN IF EXISTS reverted, DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
+
+CREATE VIEW matches_all AS
+    SELECT * FROM matches
+    UNION ALL
+    SELECT * FROM matches_archive;
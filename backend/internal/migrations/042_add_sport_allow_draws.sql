@@ -0,0 +1,9 @@
+-- +migrate Up
+
+-- Whether a sport can end without a winner, configured by admins per sport
+-- Stored for clients; match submission still requires a winner until matches can record a draw
+ALTER TABLE sports ADD COLUMN IF NOT EXISTS allow_draws BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+
+ALTER TABLE sports DROP COLUMN IF EXISTS allow_draws;
@@ -0,0 +1,63 @@
+-- +migrate Up
+
+-- How a sport picks the K-factor of each player in a match.
+--   fixed:  every player uses k_factor (previous behaviour)
+--   tiered: players with fewer than provisional_matches matches use provisional_k_factor,
+--           players rated at or above high_rating_threshold use high_rating_k_factor,
+--           everyone else uses k_factor
+ALTER TABLE sports
+    ADD COLUMN IF NOT EXISTS k_factor_strategy VARCHAR(20) NOT NULL DEFAULT 'fixed'
+        CHECK (k_factor_strategy IN ('fixed', 'tiered')),
+    ADD COLUMN IF NOT EXISTS provisional_matches INTEGER NOT NULL DEFAULT 10
+        CHECK (provisional_matches >= 0),
+    ADD COLUMN IF NOT EXISTS provisional_k_factor INTEGER NOT NULL DEFAULT 48
+        CHECK (provisional_k_factor > 0),
+    ADD COLUMN IF NOT EXISTS high_rating_threshold INTEGER NOT NULL DEFAULT 2100,
+    ADD COLUMN IF NOT EXISTS high_rating_k_factor INTEGER NOT NULL DEFAULT 16
+        CHECK (high_rating_k_factor > 0);
+
+-- The K-factor each player's rating change was calculated with; NULL for matches
+-- confirmed before it was recorded
+ALTER TABLE matches
+    ADD COLUMN IF NOT EXISTS player1_k_factor INTEGER,
+    ADD COLUMN IF NOT EXISTS player2_k_factor INTEGER;
+
+-- The archive mirrors matches column for column
+ALTER TABLE matches_archive
+    ADD COLUMN IF NOT EXISTS player1_k_factor INTEGER,
+    ADD COLUMN IF NOT EXISTS player2_k_factor INTEGER;
+
+CREATE OR REPLACE VIEW matches_all AS
+    SELECT * FROM matches WHERE deleted_at IS NULL
+    UNION ALL
+    SELECT * FROM matches_archive WHERE deleted_at IS NULL;
+
+CREATE OR REPLACE VIEW archived_matches AS
+    SELECT * FROM matches WHERE deleted_at IS NOT NULL
+    UNION ALL
+    SELECT * FROM matches_archive WHERE deleted_at IS NOT NULL;
+
+-- +migrate Down
+
+DROP VIEW IF EXISTS archived_matches;
+DROP VIEW IF EXISTS matches_all;
+
+ALTER TABLE matches_archive DROP COLUMN IF EXISTS player2_k_factor, DROP COLUMN IF EXISTS player1_k_factor;
+ALTER TABLE matches DROP COLUMN IF EXISTS player2_k_factor, DROP COLUMN IF EXISTS player1_k_factor;
+
+CREATE VIEW matches_all AS
+    SELECT * FROM matches WHERE deleted_at IS NULL
+    UNION ALL
+    SELECT * FROM matches_archive WHERE deleted_at IS NULL;
+
+CREATE VIEW archived_matches AS
+    SELECT * FROM matches WHERE deleted_at IS NOT NULL
+    UNION ALL
+    SELECT * FROM matches_archive WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE sports
+    DROP COLUMN IF EXISTS high_rating_k_factor,
+    DROP COLUMN IF EXISTS high_rating_threshold,
+    DROP COLUMN IF EXISTS provisional_k_factor,
+    DROP COLUMN IF EXISTS provisional_matches,
+    DROP COLUMN IF EXISTS k_factor_strategy;
@@ -0,0 +1,15 @@
+-- +migrate Up
+
+-- Optional margin-of-victory weighting per sport. When enabled, a match's rating changes
+-- are scaled by how clearly it was won: the winner's margin relative to the winning score
+-- maps linearly onto 1 (no margin) up to max_margin_multiplier (a shutout)
+ALTER TABLE sports
+    ADD COLUMN IF NOT EXISTS margin_of_victory BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS max_margin_multiplier DOUBLE PRECISION NOT NULL DEFAULT 1.5
+        CHECK (max_margin_multiplier >= 1 AND max_margin_multiplier <= 3);
+
+-- +migrate Down
+
+ALTER TABLE sports
+    DROP COLUMN IF EXISTS max_margin_multiplier,
+    DROP COLUMN IF EXISTS margin_of_victory;
@@ -0,0 +1,13 @@
+-- +migrate Up
+
+-- The decay job lowers the ratings of inactive players, marked in the ELO history
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'forfeit', 'correction', 'revert', 'restore', 'adjustment', 'season_reset', 'decay'));
+
+-- +migrate Down
+
+UPDATE elo_history SET source = 'adjustment' WHERE source = 'decay';
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'forfeit', 'correction', 'revert', 'restore', 'adjustment', 'season_reset'));
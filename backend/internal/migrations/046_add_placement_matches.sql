@@ -0,0 +1,11 @@
+-- +migrate Up
+
+-- Players only appear on a sport's leaderboard once they have played placement_matches
+-- matches in it; until then they are listed as unranked. 0 ranks everyone right away.
+ALTER TABLE sports
+    ADD COLUMN IF NOT EXISTS placement_matches INTEGER NOT NULL DEFAULT 0
+        CHECK (placement_matches >= 0 AND placement_matches <= 50);
+
+-- +migrate Down
+
+ALTER TABLE sports DROP COLUMN IF EXISTS placement_matches;
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+-- Ratings of a sport are clamped to [rating_floor, rating_ceiling] whenever they change,
+-- so reverts, corrections and manual edits cannot push them negative or out of reach.
+-- A sport's default rating has to lie within its bounds.
+ALTER TABLE sports
+    ADD COLUMN IF NOT EXISTS rating_floor INTEGER NOT NULL DEFAULT 100
+        CHECK (rating_floor >= 0),
+    ADD COLUMN IF NOT EXISTS rating_ceiling INTEGER NOT NULL DEFAULT 4000;
+
+ALTER TABLE sports
+    ADD CONSTRAINT sports_rating_bounds_check
+        CHECK (rating_floor < rating_ceiling AND default_elo BETWEEN rating_floor AND rating_ceiling);
+
+-- +migrate Down
+
+ALTER TABLE sports DROP CONSTRAINT IF EXISTS sports_rating_bounds_check;
+ALTER TABLE sports DROP COLUMN IF EXISTS rating_ceiling, DROP COLUMN IF EXISTS rating_floor;
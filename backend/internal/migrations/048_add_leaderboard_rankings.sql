@@ -0,0 +1,40 @@
+-- +migrate Up
+
+-- The ranked leaderboard of every sport, kept current by the transactions that change ratings,
+-- so a leaderboard page is a range scan on position instead of ranking all players per request.
+-- position is the row's place in leaderboard order (1..n without gaps), rank shares places on tied ELO.
+CREATE TABLE IF NOT EXISTS leaderboard_rankings (
+    sport_id VARCHAR(50) NOT NULL REFERENCES sports(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    elo INTEGER NOT NULL,
+    wins INTEGER NOT NULL,
+    matches_played INTEGER NOT NULL,
+    rank INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (sport_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leaderboard_rankings_position ON leaderboard_rankings(sport_id, position);
+
+INSERT INTO leaderboard_rankings (sport_id, user_id, elo, wins, matches_played, rank, position)
+SELECT
+    sport_id, user_id, elo, wins, matches_played,
+    RANK() OVER (PARTITION BY sport_id ORDER BY elo DESC),
+    ROW_NUMBER() OVER (PARTITION BY sport_id ORDER BY elo DESC, wins DESC, matches_played DESC, user_id ASC)
+FROM (
+    SELECT
+        s.id AS sport_id,
+        u.id AS user_id,
+        COALESCE(us.current_elo, s.default_elo) AS elo,
+        COALESCE(us.wins, 0) AS wins,
+        COALESCE(us.matches_played, 0) AS matches_played
+    FROM users u
+    CROSS JOIN sports s
+    LEFT JOIN user_sports us ON us.user_id = u.id AND us.sport_id = s.id
+    WHERE u.id != -1 AND NOT u.is_banned AND COALESCE(us.matches_played, 0) >= s.placement_matches
+) standings
+ON CONFLICT (sport_id, user_id) DO NOTHING;
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS leaderboard_rankings;
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+-- Temporary bans end at banned_until; NULL keeps a ban until an admin lifts it
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned_until TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_banned_until ON users(banned_until) WHERE is_banned AND banned_until IS NOT NULL;
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS idx_users_banned_until;
+ALTER TABLE users DROP COLUMN IF EXISTS banned_until;
@@ -0,0 +1,19 @@
+-- +migrate Up
+
+-- Admins either set a rating ('set') or deduct points from it as a moderation penalty ('penalty');
+-- both are replayed as the change they made, penalties are marked in the ELO history
+ALTER TABLE elo_adjustments ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'set'
+    CHECK (kind IN ('set', 'penalty'));
+
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'forfeit', 'correction', 'revert', 'restore', 'adjustment', 'penalty', 'season_reset', 'decay'));
+
+-- +migrate Down
+
+UPDATE elo_history SET source = 'adjustment' WHERE source = 'penalty';
+ALTER TABLE elo_history DROP CONSTRAINT IF EXISTS elo_history_source_check;
+ALTER TABLE elo_history ADD CONSTRAINT elo_history_source_check
+    CHECK (source IN ('match', 'forfeit', 'correction', 'revert', 'restore', 'adjustment', 'season_reset', 'decay'));
+
+ALTER TABLE elo_adjustments DROP COLUMN IF EXISTS kind;
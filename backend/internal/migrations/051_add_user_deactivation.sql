@@ -0,0 +1,9 @@
+-- +migrate Up
+
+-- Self-service deactivation: the player is hidden from the leaderboards and cannot be
+-- challenged until their next login, which clears deactivated_at again
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP;
+
+-- +migrate Down
+
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
@@ -0,0 +1,14 @@
+-- +migrate Up
+
+-- Which version of a legal document each user accepted, one row per accepted version
+-- Versions are never changed, so the row proves the exact text the user agreed to
+CREATE TABLE IF NOT EXISTS legal_acceptances (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    document_id INTEGER NOT NULL REFERENCES legal_documents(id),
+    accepted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, document_id)
+);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS legal_acceptances;
@@ -0,0 +1,39 @@
+-- +migrate Up
+
+-- Ratings live in user_sports only; the per-sport columns on users were kept in sync by a
+-- trigger during the transition from 005 and are no longer read
+DROP TRIGGER IF EXISTS sync_user_sports_to_legacy ON user_sports;
+DROP FUNCTION IF EXISTS sync_legacy_elo_columns();
+
+ALTER TABLE users DROP COLUMN IF EXISTS table_tennis_elo;
+ALTER TABLE users DROP COLUMN IF EXISTS table_football_elo;
+
+-- +migrate Down
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS table_tennis_elo INTEGER NOT NULL DEFAULT 1000;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS table_football_elo INTEGER NOT NULL DEFAULT 1000;
+
+UPDATE users u SET table_tennis_elo = us.current_elo
+FROM user_sports us
+WHERE us.user_id = u.id AND us.sport_id = 'table_tennis';
+
+UPDATE users u SET table_football_elo = us.current_elo
+FROM user_sports us
+WHERE us.user_id = u.id AND us.sport_id = 'table_football';
+
+CREATE OR REPLACE FUNCTION sync_legacy_elo_columns()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.sport_id = 'table_tennis' THEN
+        UPDATE users SET table_tennis_elo = NEW.current_elo WHERE id = NEW.user_id;
+    ELSIF NEW.sport_id = 'table_football' THEN
+        UPDATE users SET table_football_elo = NEW.current_elo WHERE id = NEW.user_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER sync_user_sports_to_legacy
+    AFTER INSERT OR UPDATE OF current_elo ON user_sports
+    FOR EACH ROW
+    EXECUTE FUNCTION sync_legacy_elo_columns();
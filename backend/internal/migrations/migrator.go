@@ -273,6 +273,42 @@ func (m *Migrator) MigrateDownTo(targetVersion int) error {
 	return nil
 }
 
+// Redo rolls back the last applied migration and applies it again, for iterating on a
+// migration during development
+func (m *Migrator) Redo() error {
+	applied, err := m.GetAppliedVersions()
+	if err != nil {
+		return fmt.Errorf("failed to get applied versions: %w", err)
+	}
+	if len(applied) == 0 {
+		return fmt.Errorf("no migrations to redo")
+	}
+	lastVersion := applied[len(applied)-1]
+
+	var migration *Migration
+	for i := range m.migrations {
+		if m.migrations[i].Version == lastVersion {
+			migration = &m.migrations[i]
+			break
+		}
+	}
+	if migration == nil {
+		return fmt.Errorf("migration %d not found in files", lastVersion)
+	}
+
+	if err := m.MigrateDown(); err != nil {
+		return err
+	}
+
+	slog.Info("Reapplying migration", "version", migration.Version, "name", migration.Name)
+	if err := m.applyMigration(*migration); err != nil {
+		return fmt.Errorf("failed to apply migration %d: %w", migration.Version, err)
+	}
+	slog.Info("Migration applied successfully", "version", migration.Version)
+
+	return nil
+}
+
 // applyMigration applies a single migration within a transaction
 func (m *Migrator) applyMigration(migration Migration) error {
 	tx, err := m.db.Begin()
@@ -331,31 +367,61 @@ func (m *Migrator) rollbackMigration(migration Migration) error {
 
 // Status returns the current migration status
 func (m *Migrator) Status() ([]MigrationStatus, error) {
-	applied, err := m.GetAppliedVersions()
+	rows, err := m.db.Query("SELECT version, applied_at FROM schema_migrations")
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	appliedMap := make(map[int]bool)
-	for _, v := range applied {
-		appliedMap[v] = true
+	appliedAt := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	var status []MigrationStatus
 	for _, migration := range m.migrations {
-		status = append(status, MigrationStatus{
+		s := MigrationStatus{
 			Version: migration.Version,
 			Name:    migration.Name,
-			Applied: appliedMap[migration.Version],
-		})
+		}
+		if at, ok := appliedAt[migration.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = &at
+		}
+		status = append(status, s)
 	}
 
 	return status, nil
 }
 
+// Pending returns the migrations that have not been applied yet
+func (m *Migrator) Pending() ([]MigrationStatus, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []MigrationStatus
+	for _, s := range status {
+		if !s.Applied {
+			pending = append(pending, s)
+		}
+	}
+	return pending, nil
+}
+
 // MigrationStatus represents the status of a migration
 type MigrationStatus struct {
-	Version int    `json:"version"`
-	Name    string `json:"name"`
-	Applied bool   `json:"applied"`
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
 }
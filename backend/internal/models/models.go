@@ -14,6 +14,14 @@ const (
 	StatusConfirmed = "confirmed"
 	StatusDenied    = "denied"
 	StatusCancelled = "cancelled"
+	StatusDisputed  = "disputed" // held back by staff for review
+)
+
+// Match result types
+// Forfeits are won without playing; their scores are 0:0 and ForfeitedBy names the absent player
+const (
+	ResultPlayed  = "played"
+	ResultForfeit = "forfeit"
 )
 
 // UserSportData represents a user's statistics for a specific sport
@@ -27,23 +35,35 @@ type UserSportData struct {
 
 // User represents a 42 student
 type User struct {
-	ID               int        `json:"id"`
-	IntraID          int        `json:"intra_id"`
-	Login            string     `json:"login"`
-	DisplayName      string     `json:"display_name"`
-	AvatarURL        string     `json:"avatar_url"`
-	Campus           string     `json:"campus"`
-	TableTennisELO   int        `json:"table_tennis_elo"`
-	TableFootballELO int        `json:"table_football_elo"`
-	IsAdmin          bool       `json:"is_admin"`
-	IsBanned         bool       `json:"is_banned"`
-	BanReason        *string    `json:"ban_reason,omitempty"`
-	BannedAt         *time.Time `json:"banned_at,omitempty"`
-	BannedBy         *int       `json:"banned_by,omitempty"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
+	ID            int        `json:"id"`
+	IntraID       int        `json:"intra_id"`
+	Login         string     `json:"login"`
+	DisplayName   string     `json:"display_name"`
+	AvatarURL     string     `json:"avatar_url"`
+	Campus        string     `json:"campus"`
+	IsAdmin       bool       `json:"is_admin"` // Any staff role, moderator or above
+	Role          string     `json:"role,omitempty"`
+	IsBanned      bool       `json:"is_banned"`
+	BanReason     *string    `json:"ban_reason,omitempty"`
+	BannedAt      *time.Time `json:"banned_at,omitempty"`
+	BannedBy      *int       `json:"banned_by,omitempty"`
+	BannedUntil   *time.Time `json:"banned_until,omitempty"`   // End of a temporary ban, nil = until lifted
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"` // Set while the player deactivated their account
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 	// Sports contains per-sport ELO and statistics (new modular system)
 	Sports map[string]UserSportData `json:"sports,omitempty"`
+	// DeletionScheduledFor is set on /api/auth/me while an account deletion is pending
+	DeletionScheduledFor *time.Time `json:"deletion_scheduled_for,omitempty"`
+	// CoalitionID and PoolYear are taken from the 42 API on login; unset before the first login
+	CoalitionID *int    `json:"coalition_id,omitempty"`
+	PoolYear    *string `json:"pool_year,omitempty"`
+}
+
+// Banned reports whether a ban is in force; a temporary ban counts as lifted once
+// BannedUntil has passed, even before the ban expiry job clears it
+func (u *User) Banned() bool {
+	return u.IsBanned && (u.BannedUntil == nil || time.Now().Before(*u.BannedUntil))
 }
 
 // Match represents a game between two players
@@ -63,11 +83,67 @@ type Match struct {
 	Player2ELOBefore *int       `json:"player2_elo_before,omitempty"`
 	Player2ELOAfter  *int       `json:"player2_elo_after,omitempty"`
 	Player2ELODelta  *int       `json:"player2_elo_delta,omitempty"`
+	Player1KFactor   *int       `json:"player1_k_factor,omitempty"` // K-factors applied at confirmation
+	Player2KFactor   *int       `json:"player2_k_factor,omitempty"`
 	SubmittedBy      int        `json:"submitted_by"`
 	ConfirmedAt      *time.Time `json:"confirmed_at,omitempty"`
 	DeniedAt         *time.Time `json:"denied_at,omitempty"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
+	Result           string     `json:"result"`
+	ForfeitedBy      *int       `json:"forfeited_by,omitempty"`
+	Sets             []MatchSet `json:"sets,omitempty"` // Set breakdown of best-of-N series
+	// Reaction counts per emoji - only embedded in the match feed
+	Reactions []ReactionSummary `json:"reactions,omitempty"`
+	// Hashed client fingerprints - never serialized to regular API responses
+	SubmitFingerprint  *string `json:"-"`
+	ConfirmFingerprint *string `json:"-"`
+	// QR/deep link for the opponent - only set in the submission response
+	Confirmation *MatchConfirmationLink `json:"confirmation,omitempty"`
+}
+
+// MatchSet is one set of a best-of-N series
+type MatchSet struct {
+	SetNumber    int `json:"set_number"`
+	Player1Score int `json:"player1_score"`
+	Player2Score int `json:"player2_score"`
+}
+
+// MatchConfirmationLink lets the opponent confirm a match by scanning a QR code
+type MatchConfirmationLink struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MatchConfirmationPreview shows the opponent a match before they confirm it through its QR code
+type MatchConfirmationPreview struct {
+	Match     *Match        `json:"match"`
+	Submitter PlayerSummary `json:"submitter"`
+}
+
+// ConfirmMatchByTokenRequest confirms the match behind a QR code / deep link token
+type ConfirmMatchByTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// CalendarFeedLink is the iCalendar subscription URL of a player's matches and challenges
+type CalendarFeedLink struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// MatchCursor is a keyset position in the match feed (created_at DESC, id DESC)
+type MatchCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// MatchPage is one page of the match feed
+// NextCursor is empty on the last page
+type MatchPage struct {
+	Matches    []MatchWithPlayers `json:"matches"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
 // MatchWithPlayers includes player details
@@ -81,12 +157,23 @@ type MatchWithPlayers struct {
 
 // Comment represents a comment on a match
 type Comment struct {
-	ID        int       `json:"id"`
-	MatchID   int       `json:"match_id"`
-	UserID    int       `json:"user_id"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              int       `json:"id"`
+	MatchID         int       `json:"match_id"`
+	UserID          int       `json:"user_id"`
+	ParentCommentID *int      `json:"parent_comment_id,omitempty"` // Set on replies; only top-level comments can be answered
+	Content         string    `json:"content"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Shadowed        bool      `json:"-"` // Written while the author was shadow-muted; only the author sees it
+}
+
+// MutedUser is a user shadow-muted from commenting
+type MutedUser struct {
+	UserID      int       `json:"user_id"`
+	Login       string    `json:"login"`
+	DisplayName string    `json:"display_name"`
+	MutedAt     time.Time `json:"muted_at"`
+	MutedBy     *int      `json:"muted_by,omitempty"`
 }
 
 // CommentWithUser includes user details
@@ -95,15 +182,200 @@ type CommentWithUser struct {
 	User User `json:"user"`
 }
 
+// Counter-proposal statuses
+const (
+	CounterProposalPending  = "pending"
+	CounterProposalAccepted = "accepted"
+	CounterProposalRejected = "rejected"
+)
+
+// CounterProposal is a corrected score proposed by the opponent when denying a match
+type CounterProposal struct {
+	MatchID      int        `json:"match_id"`
+	Player1Score int        `json:"player1_score"`
+	Player2Score int        `json:"player2_score"`
+	ProposedBy   int        `json:"proposed_by"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Challenge statuses
+const (
+	ChallengePending   = "pending"
+	ChallengeAccepted  = "accepted"
+	ChallengeDeclined  = "declined"
+	ChallengeCancelled = "cancelled"
+	ChallengeCompleted = "completed"
+)
+
+// Challenge is a match one player proposes to another for a time slot
+// Once played, its result becomes a pending match linked by MatchID
+type Challenge struct {
+	ID           int        `json:"id"`
+	Sport        string     `json:"sport"`
+	ChallengerID int        `json:"challenger_id"`
+	OpponentID   int        `json:"opponent_id"`
+	ScheduledAt  time.Time  `json:"scheduled_at"`
+	Message      *string    `json:"message,omitempty"`
+	Status       string     `json:"status"`
+	MatchID      *int       `json:"match_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RespondedAt  *time.Time `json:"responded_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Report targets and statuses
+const (
+	ReportTargetMatch   = "match"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// Report is a user's abuse report on a match, comment or player
+type Report struct {
+	ID             int        `json:"id"`
+	ReporterID     int        `json:"reporter_id"`
+	TargetType     string     `json:"target_type"`
+	TargetID       int        `json:"target_id"`
+	Reason         string     `json:"reason"`
+	Details        *string    `json:"details,omitempty"`
+	Status         string     `json:"status"`
+	ResolutionNote *string    `json:"resolution_note,omitempty"`
+	HandledBy      *int       `json:"handled_by,omitempty"`
+	HandledAt      *time.Time `json:"handled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Reaction represents an emoji reaction on a match
+type Reaction struct {
+	ID        int       `json:"id"`
+	MatchID   int       `json:"match_id"`
+	UserID    int       `json:"user_id"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReactionSummary aggregates reactions on a match per emoji
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"` // Whether the requesting user used this emoji
+}
+
 // LeaderboardEntry represents a player's rank
 type LeaderboardEntry struct {
-	Rank         int    `json:"rank"`
-	User         User   `json:"user"`
-	ELO          int    `json:"elo"`
-	MatchesPlayed int   `json:"matches_played"`
-	Wins         int    `json:"wins"`
-	Losses       int    `json:"losses"`
-	WinRate      float64 `json:"win_rate"`
+	Rank             int     `json:"rank"`
+	User             User    `json:"user"`
+	ELO              int     `json:"elo"`
+	MatchesPlayed    int     `json:"matches_played"`
+	Wins             int     `json:"wins"`
+	Losses           int     `json:"losses"`
+	WinRate          float64 `json:"win_rate"`
+	CurrentStreak    int     `json:"current_streak"`       // Positive for wins in a row, negative for losses
+	LongestWinStreak int     `json:"longest_win_streak"`   // Not kept for archived seasons
+	IsProvisional    bool    `json:"is_provisional"`       // Within the provisional matches of a tiered K-factor; not kept for archived seasons
+	RankDelta        *int    `json:"rank_delta,omitempty"` // Places gained since the compared snapshot, nil if not ranked then
+}
+
+// UnrankedEntry is a player still playing their placement matches in a sport
+type UnrankedEntry struct {
+	User                      User       `json:"user"`
+	MatchesPlayed             int        `json:"matches_played"`
+	PlacementMatchesRemaining int        `json:"placement_matches_remaining"`
+	LastMatchAt               *time.Time `json:"last_match_at,omitempty"`
+}
+
+// RankSnapshot is a player's position in one daily leaderboard snapshot
+type RankSnapshot struct {
+	Date          string `json:"date"` // YYYY-MM-DD
+	Rank          int    `json:"rank"`
+	ELO           int    `json:"elo"`
+	MatchesPlayed int    `json:"matches_played"`
+}
+
+// Coalition is a 42 coalition as reported by the 42 API
+type Coalition struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Color    string `json:"color"`
+	ImageURL string `json:"image_url"`
+}
+
+// ActivityHeatmap counts the confirmed matches of a sport by local weekday and hour
+// Counts has one row per weekday starting Monday, each with 24 hourly counts
+type ActivityHeatmap struct {
+	Sport    string  `json:"sport"`
+	Days     int     `json:"days"`
+	TimeZone string  `json:"time_zone"`
+	Matches  int     `json:"matches"`
+	Counts   [][]int `json:"counts"`
+}
+
+// CoalitionStanding aggregates the current standings of one coalition in a sport
+// Only players with at least one match count towards the ELO figures
+type CoalitionStanding struct {
+	Rank          int       `json:"rank"`
+	Coalition     Coalition `json:"coalition"`
+	Members       int       `json:"members"`
+	ActivePlayers int       `json:"active_players"`
+	AverageELO    int       `json:"average_elo"`
+	TopELO        int       `json:"top_elo"`
+	MatchesPlayed int       `json:"matches_played"`
+	Wins          int       `json:"wins"`
+}
+
+// ELODistribution describes how the ratings of the active players (at least one match) of a
+// sport are spread; the viewer fields are only set for a logged-in player who has played
+type ELODistribution struct {
+	Sport          string      `json:"sport"`
+	Players        int         `json:"players"`
+	BucketSize     int         `json:"bucket_size"`
+	Buckets        []ELOBucket `json:"buckets"`
+	Average        float64     `json:"average"`
+	Median         float64     `json:"median"`
+	P10            float64     `json:"p10"`
+	P25            float64     `json:"p25"`
+	P75            float64     `json:"p75"`
+	P90            float64     `json:"p90"`
+	UserELO        *int        `json:"user_elo,omitempty"`
+	UserPercentile *float64    `json:"user_percentile,omitempty"` // Share of the other players rated lower, 0-100
+}
+
+// ELOBucket is one histogram bar covering ratings in [Min, Max)
+type ELOBucket struct {
+	Min     int `json:"min"`
+	Max     int `json:"max"`
+	Players int `json:"players"`
+}
+
+// GlobalStats summarizes the whole leaderboard for the landing page
+// The week starts on Monday 00:00 UTC
+type GlobalStats struct {
+	TotalPlayers    int            `json:"total_players"`
+	MatchesThisWeek int            `json:"matches_this_week"`
+	Sports          []SportSummary `json:"sports"`
+	MostActive      *ActivePlayer  `json:"most_active,omitempty"` // Most confirmed matches this week, across sports
+}
+
+// SportSummary holds the global figures of one active sport
+type SportSummary struct {
+	Sport           string            `json:"sport"`
+	Matches         int               `json:"matches"` // Confirmed, archived ones included
+	MatchesThisWeek int               `json:"matches_this_week"`
+	AverageELO      int               `json:"average_elo"` // Players with at least one match
+	Leader          *LeaderboardEntry `json:"leader,omitempty"`
+}
+
+// ActivePlayer is a player with their number of confirmed matches in some period
+type ActivePlayer struct {
+	User    User `json:"user"`
+	Matches int  `json:"matches"`
 }
 
 // PlayerStats represents detailed statistics for a player
@@ -120,43 +392,176 @@ type PlayerStats struct {
 	LongestWinStreak  int    `json:"longest_win_streak"`
 	MostPlayedRival   *User  `json:"most_played_rival,omitempty"`
 	RivalMatchCount   int    `json:"rival_match_count"`
+	ForfeitWins       int    `json:"forfeit_wins"`   // Included in Wins
+	ForfeitLosses     int    `json:"forfeit_losses"` // Included in Losses
 }
 
 // SubmitMatchRequest is the request body for submitting a match
+// A best-of-N series lists its sets; the scores are then the number of sets won
 type SubmitMatchRequest struct {
-	Sport        string `json:"sport" binding:"required,oneof=table_tennis table_football"`
-	OpponentID   int    `json:"opponent_id" binding:"required,min=1"`
-	PlayerScore  int    `json:"player_score" binding:"required,min=0"`
-	OpponentScore int   `json:"opponent_score" binding:"required,min=0"`
-	Context      string `json:"context"`
+	Sport         string     `json:"sport" binding:"required,max=50"`
+	OpponentID    int        `json:"opponent_id" binding:"required,min=1"`
+	PlayerScore   int        `json:"player_score" binding:"min=0"`
+	OpponentScore int        `json:"opponent_score" binding:"min=0"`
+	Context       string     `json:"context"`
+	BestOf        int        `json:"best_of" binding:"omitempty,oneof=3 5 7"`
+	Sets          []SetScore `json:"sets" binding:"omitempty,max=7,dive"`
+}
+
+// SetScore is one set of a submitted series from the submitter's perspective
+type SetScore struct {
+	PlayerScore   int `json:"player_score" binding:"min=0"`
+	OpponentScore int `json:"opponent_score" binding:"min=0"`
+}
+
+// SubmitForfeitRequest reports a forfeit against an opponent
+// Conceded means the submitter forfeits; otherwise the opponent did not show up
+type SubmitForfeitRequest struct {
+	Sport      string `json:"sport" binding:"required,max=50"`
+	OpponentID int    `json:"opponent_id" binding:"required,min=1"`
+	Conceded   bool   `json:"conceded"`
+	Context    string `json:"context"`
+}
+
+// RecordForfeitRequest records a forfeit on behalf of two players (organizers/admins)
+type RecordForfeitRequest struct {
+	Sport       string `json:"sport" binding:"required,max=50"`
+	WinnerID    int    `json:"winner_id" binding:"required,min=1"`
+	ForfeitedBy int    `json:"forfeited_by" binding:"required,min=1"`
+	Context     string `json:"context"`
+}
+
+// DenyMatchRequest is the optional request body for denying a match
+// Scores are from the denying player's perspective and form a counter-proposal
+type DenyMatchRequest struct {
+	PlayerScore   *int `json:"player_score" binding:"omitempty,min=0"`
+	OpponentScore *int `json:"opponent_score" binding:"omitempty,min=0"`
+}
+
+// CreateChallengeRequest challenges an opponent to a match at a time slot
+type CreateChallengeRequest struct {
+	Sport       string    `json:"sport" binding:"required,max=50"`
+	OpponentID  int       `json:"opponent_id" binding:"required,min=1"`
+	ScheduledAt time.Time `json:"scheduled_at" binding:"required"`
+	Message     string    `json:"message" binding:"max=500"`
+}
+
+// ChallengeResultRequest enters the result of an accepted challenge
+// Scores are from the submitting player's perspective, as in SubmitMatchRequest
+type ChallengeResultRequest struct {
+	PlayerScore   int        `json:"player_score" binding:"min=0"`
+	OpponentScore int        `json:"opponent_score" binding:"min=0"`
+	BestOf        int        `json:"best_of" binding:"omitempty,oneof=3 5 7"`
+	Sets          []SetScore `json:"sets" binding:"omitempty,max=7,dive"`
+}
+
+// CreateReportRequest reports a match, comment or player for abuse
+type CreateReportRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=match comment user"`
+	TargetID   int    `json:"target_id" binding:"required,min=1"`
+	Reason     string `json:"reason" binding:"required,oneof=spam harassment cheating inappropriate other"`
+	Details    string `json:"details" binding:"max=1000"`
+}
+
+// CloseReportRequest resolves or dismisses a report; the note is shown to the reporter
+type CloseReportRequest struct {
+	Note string `json:"note" binding:"max=500"`
 }
 
 // AddCommentRequest is the request body for adding a comment
 type AddCommentRequest struct {
-	Content string `json:"content" binding:"required,max=500"`
+	Content         string `json:"content" binding:"required,max=500"`
+	ParentCommentID *int   `json:"parent_comment_id" binding:"omitempty,min=1"` // Reply to a top-level comment
+}
+
+// ReactionRequest is the request body for adding or removing a reaction
+type ReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=10"`
 }
 
 // Admin-related models
 
+// ELOPenaltyRequest is the request body for deducting points from a user's ELO
+type ELOPenaltyRequest struct {
+	UserID int    `json:"user_id" binding:"required,min=1"`
+	Sport  string `json:"sport" binding:"required,max=50"`
+	Points int    `json:"points" binding:"required,min=1,max=1000"` // Deducted from the current rating
+	Reason string `json:"reason" binding:"required,min=5,max=500"`
+}
+
 // AdjustELORequest is the request body for manually adjusting a user's ELO
 type AdjustELORequest struct {
 	UserID int    `json:"user_id" binding:"required,min=1"`
-	Sport  string `json:"sport" binding:"required,oneof=table_tennis table_football"`
+	Sport  string `json:"sport" binding:"required,max=50"`
 	NewELO int    `json:"new_elo" binding:"required,min=0,max=5000"`
 	Reason string `json:"reason" binding:"required,min=5,max=500"`
 }
 
 // BanUserRequest is the request body for banning a user
+// Without DurationHours the ban lasts until an admin lifts it
 type BanUserRequest struct {
-	UserID int    `json:"user_id" binding:"required,min=1"`
-	Reason string `json:"reason" binding:"required,min=5,max=500"`
+	UserID        int    `json:"user_id" binding:"required,min=1"`
+	Reason        string `json:"reason" binding:"required,min=5,max=500"`
+	DurationHours *int   `json:"duration_hours" binding:"omitempty,min=1,max=8760"`
+}
+
+// LinkIntraIDRequest is the request body for relinking a profile to a new intra ID
+type LinkIntraIDRequest struct {
+	IntraID int    `json:"intra_id" binding:"required,min=1"`
+	Reason  string `json:"reason" binding:"required,min=5,max=500"`
+}
+
+// MergeUsersRequest is the request body for merging a duplicate profile into another one
+type MergeUsersRequest struct {
+	SourceID int    `json:"source_id" binding:"required,min=1"` // The duplicate, removed by the merge
+	TargetID int    `json:"target_id" binding:"required,min=1"` // The profile that is kept
+	Reason   string `json:"reason" binding:"required,min=5,max=500"`
+}
+
+// UserMergeResult counts what a merge moved from the duplicate to the kept profile
+type UserMergeResult struct {
+	SourceID  int      `json:"source_id"`
+	TargetID  int      `json:"target_id"`
+	Matches   int64    `json:"matches"`
+	Comments  int64    `json:"comments"`
+	Reactions int64    `json:"reactions"`
+	Sports    []string `json:"sports"` // Sports whose rating moved; in shared sports the kept profile's rating stays
+}
+
+// AddAnonymizationWordRequest adds a word to the anonymization vocabulary
+type AddAnonymizationWordRequest struct {
+	Campus string `json:"campus" binding:"max=100"`
+	Kind   string `json:"kind" binding:"required,oneof=adjective animal"`
+	Word   string `json:"word" binding:"required,min=2,max=50"`
+}
+
+// OpenSeasonRequest is the request body for opening a new season
+// ResetFactor scales each player's distance to the sport default (0 = full reset, 1 = keep ELO)
+type OpenSeasonRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=100"`
+	ResetFactor *float64 `json:"reset_factor" binding:"omitempty,min=0,max=1"`
+}
+
+// PublishLegalDocumentRequest publishes a new version of a legal document
+type PublishLegalDocumentRequest struct {
+	Lang    string `json:"lang" binding:"required,len=2,alpha,lowercase"`
+	Title   string `json:"title" binding:"required,min=2,max=200"`
+	Content string `json:"content" binding:"required,min=10,max=100000"`
+}
+
+// AcceptLegalDocumentRequest accepts the current version of a legal document in a language
+type AcceptLegalDocumentRequest struct {
+	Lang    string `json:"lang" binding:"required,len=2,alpha,lowercase"`
+	Version int    `json:"version" binding:"required,min=1"`
 }
 
-// EditMatchRequest is the request body for editing a match
+// EditMatchRequest is the request body for correcting a confirmed match
+// Scores of played matches determine the winner; WinnerID is only needed for forfeits
 type EditMatchRequest struct {
-	Player1Score *int    `json:"player1_score,omitempty"`
-	Player2Score *int    `json:"player2_score,omitempty"`
-	Status       *string `json:"status,omitempty"`
+	Player1Score *int   `json:"player1_score,omitempty"`
+	Player2Score *int   `json:"player2_score,omitempty"`
+	WinnerID     *int   `json:"winner_id,omitempty"`
+	Reason       string `json:"reason" binding:"required"`
 }
 
 // ELOAdjustment represents a manual ELO adjustment
@@ -164,6 +569,7 @@ type ELOAdjustment struct {
 	ID         int       `json:"id"`
 	UserID     int       `json:"user_id"`
 	Sport      string    `json:"sport"`
+	Kind       string    `json:"kind"` // ELOAdjustmentSet or ELOAdjustmentPenalty
 	OldELO     int       `json:"old_elo"`
 	NewELO     int       `json:"new_elo"`
 	Reason     string    `json:"reason"`
@@ -171,6 +577,492 @@ type ELOAdjustment struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// Kinds of manual ELO adjustments
+const (
+	ELOAdjustmentSet     = "set"     // The rating was set to a value
+	ELOAdjustmentPenalty = "penalty" // Points were deducted as a moderation penalty
+)
+
+// ELORecomputeChange is a rating that differs after replaying all matches
+type ELORecomputeChange struct {
+	UserID int    `json:"user_id"`
+	Login  string `json:"login"`
+	Sport  string `json:"sport"`
+	OldELO int    `json:"old_elo"`
+	NewELO int    `json:"new_elo"`
+	Delta  int    `json:"delta"`
+}
+
+// ELORecomputeReport summarizes a full ELO recomputation; Applied is false for dry runs
+type ELORecomputeReport struct {
+	Applied         bool                 `json:"applied"`
+	MatchesReplayed int                  `json:"matches_replayed"`
+	MatchesChanged  int                  `json:"matches_changed"`
+	SeasonResets    int                  `json:"season_resets"`
+	Adjustments     int                  `json:"adjustments"`
+	Decays          int                  `json:"decays"`
+	Changes         []ELORecomputeChange `json:"changes"`
+}
+
+// MatchImportReport is the outcome of a CSV match import; DryRun imports are rolled back
+// Rows with errors are skipped, the others are imported and all ratings replayed
+type MatchImportReport struct {
+	DryRun   bool                `json:"dry_run"`
+	Rows     int                 `json:"rows"`
+	Imported int                 `json:"imported"`
+	Errors   []MatchImportError  `json:"errors"`
+	Replay   *ELORecomputeReport `json:"replay,omitempty"` // Nil if nothing was imported
+}
+
+// MatchImportError explains why a CSV row was skipped; Line is the line in the file
+type MatchImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ELOBreakdown explains how the rating changes of a confirmed match were calculated
+// MarginMultiplier scales both changes by the margin of victory; it is 1 for forfeits
+// and for sports without margin-of-victory weighting
+// KFactor is the sport's base K-factor; each player's applied one is in their side
+type ELOBreakdown struct {
+	MatchID          int                `json:"match_id"`
+	Sport            string             `json:"sport"`
+	Result           string             `json:"result"`
+	KFactor          int                `json:"k_factor"`
+	MarginMultiplier float64            `json:"margin_multiplier"`
+	ForfeitFactor    *float64           `json:"forfeit_factor,omitempty"` // Only for forfeits
+	Player1          ELOBreakdownPlayer `json:"player1"`
+	Player2          ELOBreakdownPlayer `json:"player2"`
+}
+
+// ELOBreakdownPlayer is one player's side of an ELOBreakdown
+// RawChange is K * multipliers * (actual - expected) before it is cut to whole
+// points; Delta is the change that was applied to the rating
+type ELOBreakdownPlayer struct {
+	UserID        int     `json:"user_id"`
+	ELOBefore     int     `json:"elo_before"`
+	ELOAfter      int     `json:"elo_after"`
+	KFactor       int     `json:"k_factor"`
+	ExpectedScore float64 `json:"expected_score"`
+	ActualScore   float64 `json:"actual_score"`
+	RawChange     float64 `json:"raw_change"`
+	Delta         int     `json:"delta"`
+}
+
+// ELO history sources
+const (
+	ELOSourceMatch       = "match"
+	ELOSourceForfeit     = "forfeit"
+	ELOSourceCorrection  = "correction"
+	ELOSourceRevert      = "revert"
+	ELOSourceRestore     = "restore"
+	ELOSourceAdjustment  = "adjustment"
+	ELOSourcePenalty     = "penalty"
+	ELOSourceSeasonReset = "season_reset"
+	ELOSourceDecay       = "decay"
+)
+
+// ELODecayPolicy describes how the ratings of inactive players decay
+// Once a player has no confirmed match in a sport for AfterWeeks weeks, their rating
+// loses Points every week, but never drops below the sport's default rating
+type ELODecayPolicy struct {
+	Enabled    bool `json:"enabled"`
+	Points     int  `json:"points"`
+	AfterWeeks int  `json:"after_weeks"`
+}
+
+// ELOHistoryEntry is a single change of a player's rating in a sport
+type ELOHistoryEntry struct {
+	ID           int64     `json:"id"`
+	UserID       int       `json:"user_id"`
+	Sport        string    `json:"sport"`
+	ELOBefore    int       `json:"elo_before"`
+	ELOAfter     int       `json:"elo_after"`
+	Delta        int       `json:"delta"`
+	Source       string    `json:"source"`
+	MatchID      *int      `json:"match_id,omitempty"`
+	AdjustmentID *int      `json:"adjustment_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PlayerComparison is a side-by-side comparison of two players in one sport
+type PlayerComparison struct {
+	Sport           string               `json:"sport"`
+	PlayerA         ComparedPlayer       `json:"player_a"`
+	PlayerB         ComparedPlayer       `json:"player_b"`
+	HeadToHead      HeadToHead           `json:"head_to_head"`
+	CommonOpponents []CommonOpponent     `json:"common_opponents"`
+	Trajectory      ComparisonTrajectory `json:"trajectory"`
+}
+
+// ComparedPlayer is one side of a comparison
+// Percentile is the share of ranked players with a lower ELO; Rank and Percentile are 0
+// while the player is still in their placement matches
+type ComparedPlayer struct {
+	User          PlayerSummary `json:"user"`
+	ELO           int           `json:"elo"`
+	Rank          int           `json:"rank"`
+	Percentile    float64       `json:"percentile"`
+	MatchesPlayed int           `json:"matches_played"`
+	Wins          int           `json:"wins"`
+	Losses        int           `json:"losses"`
+}
+
+// PlayerSummary identifies a player in comparisons
+type PlayerSummary struct {
+	ID          int    `json:"id"`
+	Login       string `json:"login"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+}
+
+// HeadToHead summarizes confirmed matches between two players
+type HeadToHead struct {
+	Matches       int        `json:"matches"`
+	PlayerAWins   int        `json:"player_a_wins"`
+	PlayerBWins   int        `json:"player_b_wins"`
+	PlayerAPoints int        `json:"player_a_points"`
+	PlayerBPoints int        `json:"player_b_points"`
+	LastPlayedAt  *time.Time `json:"last_played_at,omitempty"`
+}
+
+// CommonOpponent is a player both compared players have faced
+type CommonOpponent struct {
+	Opponent      PlayerSummary `json:"opponent"`
+	PlayerAWins   int           `json:"player_a_wins"`
+	PlayerALosses int           `json:"player_a_losses"`
+	PlayerBWins   int           `json:"player_b_wins"`
+	PlayerBLosses int           `json:"player_b_losses"`
+}
+
+// ComparisonTrajectory holds both rating series over the period both players were rated
+// Since is nil when either player has no rating history yet
+type ComparisonTrajectory struct {
+	Since   *time.Time `json:"since,omitempty"`
+	PlayerA []ELOPoint `json:"player_a"`
+	PlayerB []ELOPoint `json:"player_b"`
+}
+
+// ELOPoint is a player's rating at a point in time
+type ELOPoint struct {
+	At  time.Time `json:"at"`
+	ELO int       `json:"elo"`
+}
+
+// Notification delivery statuses
+const (
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// NotificationDelivery is one attempt to deliver a notification or webhook
+type NotificationDelivery struct {
+	ID           int64     `json:"id"`
+	Channel      string    `json:"channel"`
+	Event        string    `json:"event"`
+	Status       string    `json:"status"`
+	StatusCode   *int      `json:"status_code,omitempty"`
+	Error        *string   `json:"error,omitempty"`
+	ResponseBody *string   `json:"response_body,omitempty"`
+	DurationMS   int       `json:"duration_ms"`
+	Attempt      int       `json:"attempt"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// User notification kinds
+const (
+	NotificationRankOvertaken = "rank.overtaken"
+	NotificationTop10Entered  = "rank.top10_entered"
+	NotificationTop10Left     = "rank.top10_left"
+	NotificationDataExport    = "data_export.ready"
+	NotificationBanLifted     = "account.ban_lifted"
+	NotificationELOPenalty    = "rating.penalty"
+
+	NotificationChallengeReceived  = "challenge.received"
+	NotificationChallengeAccepted  = "challenge.accepted"
+	NotificationChallengeDeclined  = "challenge.declined"
+	NotificationChallengeCancelled = "challenge.cancelled"
+
+	NotificationCommentMention = "comment.mention"
+
+	NotificationReportResolved  = "report.resolved"
+	NotificationReportDismissed = "report.dismissed"
+)
+
+// Notification languages; missing translations fall back to English
+const (
+	LanguageEnglish = "en"
+	LanguageGerman  = "de"
+)
+
+// NotificationLanguageRequest sets the language of the current user's notifications
+type NotificationLanguageRequest struct {
+	Language string `json:"language" binding:"required,oneof=en de"`
+}
+
+// UserNotification is an entry in a user's notification inbox
+type UserNotification struct {
+	ID        int                    `json:"id"`
+	UserID    int                    `json:"user_id"`
+	Kind      string                 `json:"kind"`
+	Sport     *string                `json:"sport,omitempty"`
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	ReadAt    *time.Time             `json:"read_at,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// MarkNotificationsReadRequest marks inbox entries as read; no IDs marks all
+type MarkNotificationsReadRequest struct {
+	IDs []int `json:"ids" binding:"max=100"`
+}
+
+// SlackLink connects a Slack account to a user for slash commands
+type SlackLink struct {
+	UserID      int       `json:"user_id"`
+	SlackTeamID string    `json:"slack_team_id"`
+	SlackUserID string    `json:"slack_user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SlackLinkCode is a one-time code redeemed in Slack to link an account
+type SlackLinkCode struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Events outbound webhooks can subscribe to
+const (
+	WebhookEventMatchConfirmed = "match.confirmed"
+	WebhookEventTop3Changed    = "leaderboard.top3_changed"
+	WebhookEventUserBanned     = "user.banned"
+)
+
+// Webhook is an admin-registered endpoint receiving signed event payloads
+type Webhook struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedBy   *int      `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateWebhookRequest registers a webhook
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2000"`
+	Events      []string `json:"events" binding:"required,min=1,dive,oneof=match.confirmed leaderboard.top3_changed user.banned"`
+	Description string   `json:"description" binding:"max=200"`
+}
+
+// UpdateWebhookRequest changes a webhook; omitted fields are kept
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,url,max=2000"`
+	Events      []string `json:"events" binding:"omitempty,min=1,dive,oneof=match.confirmed leaderboard.top3_changed user.banned"`
+	Description *string  `json:"description" binding:"omitempty,max=200"`
+	Active      *bool    `json:"active"`
+}
+
+// Incident impact levels shown on the status page
+const (
+	IncidentDegraded    = "degraded"
+	IncidentOutage      = "outage"
+	IncidentMaintenance = "maintenance"
+)
+
+// StatusIncident is an admin-written annotation on the public status page
+type StatusIncident struct {
+	ID         int        `json:"id"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	Impact     string     `json:"impact"`
+	StartedAt  time.Time  `json:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedBy  *int       `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CreateIncidentRequest opens an incident; StartedAt defaults to now
+type CreateIncidentRequest struct {
+	Title     string     `json:"title" binding:"required,max=200"`
+	Message   string     `json:"message" binding:"max=5000"`
+	Impact    string     `json:"impact" binding:"required,oneof=degraded outage maintenance"`
+	StartedAt *time.Time `json:"started_at"`
+}
+
+// UpdateIncidentRequest changes an incident; omitted fields are kept
+type UpdateIncidentRequest struct {
+	Title    *string `json:"title" binding:"omitempty,max=200"`
+	Message  *string `json:"message" binding:"omitempty,max=5000"`
+	Impact   *string `json:"impact" binding:"omitempty,oneof=degraded outage maintenance"`
+	Resolved *bool   `json:"resolved"`
+}
+
+// Staff roles, from least to most privileged; players have RoleUser
+const (
+	RoleUser       = "user"
+	RoleModerator  = "moderator"
+	RoleAdmin      = "admin"
+	RoleSuperadmin = "superadmin"
+)
+
+// Admin permissions; which role has which is decided by the admin middleware
+const (
+	PermissionResolveDisputes  = "resolve_disputes"  // review disputed matches and set their status
+	PermissionModerateComments = "moderate_comments" // delete comments of any user
+	PermissionHandleReports    = "handle_reports"    // work through the abuse report queue
+	PermissionManageMatches    = "manage_matches"    // edit, revert, delete and forfeit matches
+	PermissionAdjustELO        = "adjust_elo"        // adjust and recompute ratings
+	PermissionBanUsers         = "ban_users"         // ban and unban players
+	PermissionManageUsers      = "manage_users"      // relink intra IDs, export users
+	PermissionManageSystem     = "manage_system"     // seasons, legal documents, integrations, clients, diagnostics
+	PermissionManageRoles      = "manage_roles"      // promote and demote staff
+)
+
+// Rate limit tiers of trusted clients; everyone else gets the standard limits
+const (
+	RateLimitTierTrusted = "trusted" // limits multiplied by RATE_LIMIT_TRUSTED_MULTIPLIER
+	RateLimitTierExempt  = "exempt"  // not rate limited at all
+)
+
+// TrustedClient is a kiosk, display screen or tool identified by an API key
+type TrustedClient struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	KeyHash   string    `json:"-"`
+	Tier      string    `json:"tier"`
+	Active    bool      `json:"active"`
+	CreatedBy *int      `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateTrustedClientRequest registers a trusted client
+type CreateTrustedClientRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Tier string `json:"tier" binding:"required,oneof=trusted exempt"`
+}
+
+// UpdateTrustedClientRequest changes a trusted client; omitted fields are kept
+type UpdateTrustedClientRequest struct {
+	Name   *string `json:"name" binding:"omitempty,max=100"`
+	Tier   *string `json:"tier" binding:"omitempty,oneof=trusted exempt"`
+	Active *bool   `json:"active"`
+}
+
+// KioskSessionRequest starts a kiosk session for the student who typed their login
+type KioskSessionRequest struct {
+	Login string `json:"login" binding:"required,max=50"`
+}
+
+// KioskSession lets a kiosk submit matches as one student until it expires
+type KioskSession struct {
+	Token       string    `json:"token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	UserID      int       `json:"user_id"`
+	Login       string    `json:"login"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+}
+
+// Partial erasure scopes; each keeps the account active
+const (
+	ErasureComments    = "comments"     // delete all comments
+	ErasureReactions   = "reactions"    // delete all reactions
+	ErasureDisplayData = "display_data" // replace display name and avatar with anonymous ones
+)
+
+// PartialErasureRequest selects what to erase from an account that stays active
+type PartialErasureRequest struct {
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=comments reactions display_data"`
+}
+
+// Data export states; pending exports are picked up by the data export job
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportReady      = "ready"
+	DataExportFailed     = "failed"
+)
+
+// DataExport is an asynchronous GDPR data export of one user
+type DataExport struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	Status      string     `json:"status"`
+	SizeBytes   *int       `json:"size_bytes,omitempty"`
+	Error       *string    `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"` // Signed link, only while ready
+}
+
+// DeletionRequest is an account deletion waiting for its grace period to end
+type DeletionRequest struct {
+	UserID      int       `json:"user_id"`
+	RequestedAt time.Time `json:"requested_at"`
+	EraseAfter  time.Time `json:"erase_after"`
+}
+
+// ProfileSyncCandidate is a user whose 42 profile is due for a refresh
+// Failures counts the consecutive failed attempts so far
+type ProfileSyncCandidate struct {
+	UserID   int
+	Login    string
+	Failures int
+}
+
+// APIEndpointUsage holds estimated request counts for one route
+// Counts are scaled up from sampled requests, so they are estimates
+type APIEndpointUsage struct {
+	Method    string `json:"method"`
+	Route     string `json:"route"`
+	Requests  int64  `json:"requests"`
+	Throttled int64  `json:"throttled"` // Requests rejected by rate limiting (429)
+	Errors    int64  `json:"errors"`    // Requests that failed with a 5xx status
+}
+
+// APIUsageCount is one user's usage of a route on a day
+type APIUsageCount struct {
+	UserID int
+	Day    time.Time
+	APIEndpointUsage
+}
+
+// APIUsageUserSummary aggregates one user's API usage for the admin view
+type APIUsageUserSummary struct {
+	UserID    int    `json:"user_id"`
+	Login     string `json:"login"`
+	Requests  int64  `json:"requests"`
+	Throttled int64  `json:"throttled"`
+	Errors    int64  `json:"errors"`
+	TopRoute  string `json:"top_route"` // Method and route with the most requests
+}
+
+// Chaos fault types injected by the staging chaos middleware
+const (
+	ChaosLatency = "latency"
+	ChaosDBError = "db_error"
+	ChaosPanic   = "panic"
+)
+
+// CreateChaosRuleRequest injects a fault into one route for a limited time
+type CreateChaosRuleRequest struct {
+	Method          string  `json:"method" binding:"omitempty,oneof=GET POST PUT DELETE"` // Empty matches every method
+	Route           string  `json:"route" binding:"required,startswith=/api/"`           // Route template, e.g. /api/matches/:id
+	Fault           string  `json:"fault" binding:"required,oneof=latency db_error panic"`
+	LatencyMS       int     `json:"latency_ms" binding:"omitempty,min=1,max=30000"`
+	Probability     float64 `json:"probability" binding:"omitempty,gt=0,lte=1"` // Defaults to 1 (every request)
+	DurationSeconds int     `json:"duration_seconds" binding:"omitempty,min=1,max=3600"`
+}
+
 // AdminAuditLog represents an admin action log entry
 type AdminAuditLog struct {
 	ID         int       `json:"id"`
@@ -182,6 +1074,181 @@ type AdminAuditLog struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// MatchAnomaly is a match flagged for admin review, including the hashed client fingerprints
+type MatchAnomaly struct {
+	Match
+	SubmitFingerprint  *string `json:"submit_fingerprint,omitempty"`
+	ConfirmFingerprint *string `json:"confirm_fingerprint,omitempty"`
+	Reason             string  `json:"reason"`
+}
+
+// DeletedMatch is a soft-deleted match that an admin can restore
+// Reverted matches had their ratings rolled back, which a restore reapplies
+type DeletedMatch struct {
+	Match
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy *int      `json:"deleted_by,omitempty"`
+	Reverted  bool      `json:"reverted"`
+}
+
+// Digest periods
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// Digest summarizes one day or week of a sport
+// Entries reference players by ID; Players is filled in when the digest is served
+type Digest struct {
+	ID          int               `json:"id"`
+	Sport       string            `json:"sport"`
+	Period      string            `json:"period"`
+	PeriodStart time.Time         `json:"period_start"`
+	PeriodEnd   time.Time         `json:"period_end"`
+	Matches     int               `json:"matches"` // confirmed matches in the period
+	Upsets      []DigestUpset     `json:"upsets"`
+	MostActive  []DigestActivity  `json:"most_active"`
+	RankMovers  []DigestRankMover `json:"rank_movers"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Players     map[int]User      `json:"players,omitempty"`
+}
+
+// DigestUpset is a win over a higher-rated opponent
+type DigestUpset struct {
+	MatchID         int `json:"match_id"`
+	WinnerID        int `json:"winner_id"`
+	LoserID         int `json:"loser_id"`
+	WinnerScore     int `json:"winner_score"`
+	LoserScore      int `json:"loser_score"`
+	WinnerELOBefore int `json:"winner_elo_before"`
+	LoserELOBefore  int `json:"loser_elo_before"`
+	ELOGained       int `json:"elo_gained"`
+}
+
+// DigestActivity is a player's match count in the period
+type DigestActivity struct {
+	UserID  int `json:"user_id"`
+	Matches int `json:"matches"`
+	Wins    int `json:"wins"`
+}
+
+// DigestRankMover is a player's rank change over the period (positive = climbed)
+type DigestRankMover struct {
+	UserID       int `json:"user_id"`
+	PreviousRank int `json:"previous_rank"`
+	Rank         int `json:"rank"`
+	Change       int `json:"change"`
+}
+
+// Match flag rules of the abuse scan
+const (
+	FlagPairFrequency   = "pair_frequency"   // the same pair played unusually often in a short window
+	FlagAlternatingWins = "alternating_wins" // the pair's recent results strictly alternate
+	FlagELOFarming      = "elo_farming"      // repeated ELO gains from a much lower-rated opponent
+)
+
+// Match flag review statuses
+const (
+	FlagOpen      = "open"
+	FlagDismissed = "dismissed"
+	FlagConfirmed = "confirmed"
+)
+
+// MatchFlag is a confirmed match flagged by the abuse scan, with the match's players and result
+type MatchFlag struct {
+	ID          int                    `json:"id"`
+	MatchID     int                    `json:"match_id"`
+	Rule        string                 `json:"rule"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+	Status      string                 `json:"status"`
+	ReviewedBy  *int                   `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time             `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	Sport       string                 `json:"sport"`
+	Player1ID   int                    `json:"player1_id"`
+	Player2ID   int                    `json:"player2_id"`
+	WinnerID    int                    `json:"winner_id"`
+	ConfirmedAt *time.Time             `json:"confirmed_at,omitempty"`
+}
+
+// ReviewMatchFlagRequest records the outcome of a flag review
+type ReviewMatchFlagRequest struct {
+	Status string `json:"status" binding:"required,oneof=dismissed confirmed"`
+	Note   string `json:"note" binding:"max=500"`
+}
+
+// WinnerRepair describes a match whose winner_id was corrected to match its scores
+type WinnerRepair struct {
+	MatchID     int    `json:"match_id"`
+	Sport       string `json:"sport"`
+	Status      string `json:"status"`
+	OldWinnerID int    `json:"old_winner_id"`
+	NewWinnerID int    `json:"new_winner_id"`
+	StatsFixed  bool   `json:"stats_fixed"` // Win/loss stats were recomputed for a confirmed match
+}
+
+// AnonymizationWord is an adjective or animal used to build anonymous display names
+// An empty campus means the word belongs to the global default vocabulary
+type AnonymizationWord struct {
+	ID        int       `json:"id"`
+	Campus    string    `json:"campus"`
+	Kind      string    `json:"kind"`
+	Word      string    `json:"word"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Season is a ranked period; ELO is soft-reset when a season opens
+type Season struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	ResetFactor float64    `json:"reset_factor"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+	OpenedBy    *int       `json:"opened_by,omitempty"`
+	ClosedBy    *int       `json:"closed_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Legal documents served by /api/legal/:doc
+const (
+	LegalImpressum           = "impressum"
+	LegalDatenschutz         = "datenschutz"
+	LegalNutzungsbedingungen = "nutzungsbedingungen"
+)
+
+// DefaultLegalLang is served when a document has no version in the requested language
+const DefaultLegalLang = "de"
+
+// LegalAcceptanceRequired lists the documents users have to accept; a new version has to be accepted again
+var LegalAcceptanceRequired = []string{LegalNutzungsbedingungen, LegalDatenschutz}
+
+// LegalDocument is one published version of a legal text in one language
+type LegalDocument struct {
+	ID          int       `json:"id"`
+	Doc         string    `json:"doc"`
+	Lang        string    `json:"lang"`
+	Version     int       `json:"version"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content,omitempty"` // Omitted in version listings
+	PublishedBy *int      `json:"published_by,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// LegalAcceptance records that a user accepted one version of a legal document
+type LegalAcceptance struct {
+	DocumentID int       `json:"document_id"`
+	Doc        string    `json:"doc"`
+	Lang       string    `json:"lang"`
+	Version    int       `json:"version"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// LegalStatus is what a user accepted and which current documents still await acceptance
+type LegalStatus struct {
+	Pending  []LegalDocument   `json:"pending"` // Without content
+	Accepted []LegalAcceptance `json:"accepted"`
+}
+
 // SystemHealth represents the system health status
 type SystemHealth struct {
 	Status           string `json:"status"`
@@ -194,3 +1261,35 @@ type SystemHealth struct {
 	MatchesToday     int    `json:"matches_today"`
 	ActiveUsersToday int    `json:"active_users_today"`
 }
+
+// AdminAnalytics is the admin dashboard: the current health figures plus daily engagement
+// over the last Days days (UTC), oldest first, and the same figures for the whole window
+type AdminAnalytics struct {
+	Health *SystemHealth   `json:"health"`
+	Days   int             `json:"days"`
+	Totals AnalyticsPeriod `json:"totals"`
+	Series []AnalyticsDay  `json:"series"`
+}
+
+// AnalyticsDay holds the figures of the matches submitted and users who joined on one day
+type AnalyticsDay struct {
+	Date string `json:"date"`
+	AnalyticsPeriod
+}
+
+// AnalyticsPeriod holds engagement figures of matches by submission time
+// Confirmation latency is submit to confirm of played matches confirmed by the opponent;
+// DenialRate is denied of confirmed plus denied, DisputeRate is disputed of all matches,
+// where a match counts as disputed if it was set to disputed, reported or answered with
+// a counter-proposal. Averages and rates are nil without matches to base them on
+type AnalyticsPeriod struct {
+	Matches                   int      `json:"matches"`
+	Confirmed                 int      `json:"confirmed"`
+	Denied                    int      `json:"denied"`
+	Disputed                  int      `json:"disputed"`
+	NewUsers                  int      `json:"new_users"`
+	AvgConfirmationSeconds    *float64 `json:"avg_confirmation_seconds"`
+	MedianConfirmationSeconds *float64 `json:"median_confirmation_seconds"`
+	DenialRate                *float64 `json:"denial_rate"`
+	DisputeRate               *float64 `json:"dispute_rate"`
+}
@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxResponseBody bounds how much of a failed response is kept for debugging
+const maxResponseBody = 1024
+
+// discordEmbedColor is the accent color of posted embeds
+const discordEmbedColor = 0x00BABC
+
+// DiscordChannel posts messages to a Discord webhook
+type DiscordChannel struct {
+	name       string
+	webhookURL string
+	client     *http.Client
+}
+
+// NewDiscordChannel creates a channel that posts to the given webhook URL
+func NewDiscordChannel(name, webhookURL string) *DiscordChannel {
+	return &DiscordChannel{
+		name:       name,
+		webhookURL: webhookURL,
+		client:     &http.Client{},
+	}
+}
+
+// Name returns the channel name used in the delivery log
+func (d *DiscordChannel) Name() string {
+	return d.name
+}
+
+type discordEmbed struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Color       int    `json:"color"`
+}
+
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+// Send posts the message as a single embed
+func (d *DiscordChannel) Send(ctx context.Context, msg Message) (Result, error) {
+	payload, err := json.Marshal(discordPayload{
+		Embeds: []discordEmbed{{
+			Title:       msg.Title,
+			Description: msg.Body,
+			URL:         msg.URL,
+			Color:       discordEmbedColor,
+		}},
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := Result{StatusCode: resp.StatusCode}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		result.ResponseBody = string(body)
+		return result, fmt.Errorf("discord returned status %d", resp.StatusCode)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package notifications
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/realtime"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+)
+
+// EventNotification is the realtime event type of a new inbox entry
+const EventNotification = "notification"
+
+// Inbox delivers notifications to individual users: stored for later and
+// pushed to the user's realtime channel if they are connected
+type Inbox struct {
+	repo      *repositories.UserNotificationRepository
+	users     *repositories.UserRepository
+	templates *Templates
+	events    services.EventPublisher
+}
+
+// NewInbox creates an inbox; events may be nil to disable live pushes
+func NewInbox(
+	repo *repositories.UserNotificationRepository,
+	users *repositories.UserRepository,
+	templates *Templates,
+	events services.EventPublisher,
+) *Inbox {
+	return &Inbox{repo: repo, users: users, templates: templates, events: events}
+}
+
+// Notify renders a notification in the user's language, stores it and pushes it live
+// Title and body come from the template of the kind, filled with Data (and "sport")
+// Failures are logged; a lost notification must never fail the caller, so it is
+// stored even if ctx is cancelled once the caller has responded
+func (i *Inbox) Notify(ctx context.Context, n *models.UserNotification) {
+	ctx = context.WithoutCancel(ctx)
+	i.render(ctx, n)
+
+	if err := i.repo.Create(ctx, n); err != nil {
+		slog.Error("Failed to store user notification", "user_id", n.UserID, "kind", n.Kind, "error", err)
+		return
+	}
+
+	if i.events != nil {
+		i.events.Publish(realtime.UserChannel(n.UserID), EventNotification, n)
+	}
+}
+
+// render sets the title and body from the templates; on failure the caller's
+// title and body are kept, or the kind if there are none
+func (i *Inbox) render(ctx context.Context, n *models.UserNotification) {
+	lang, err := i.users.GetLanguage(ctx, n.UserID)
+	if err != nil {
+		slog.Warn("Failed to load notification language", "user_id", n.UserID, "error", err)
+		lang = models.LanguageEnglish
+	}
+
+	data := make(map[string]interface{}, len(n.Data)+1)
+	for k, v := range n.Data {
+		data[k] = v
+	}
+	if n.Sport != nil {
+		data["sport"] = *n.Sport
+	}
+
+	msg, err := i.templates.Render(lang, FormatInbox, n.Kind, data)
+	if err != nil {
+		slog.Error("Failed to render user notification", "kind", n.Kind, "lang", lang, "error", err)
+		if n.Title == "" {
+			n.Title = n.Kind
+		}
+		return
+	}
+	n.Title, n.Body = msg.Title, msg.Body
+}
@@ -0,0 +1,138 @@
+// Package notifications delivers messages to external channels such as Discord
+// webhooks and records every attempt in the delivery log.
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// EventTest marks deliveries triggered from the admin "send test" endpoint
+const EventTest = "test"
+
+// Message is a channel-agnostic notification
+type Message struct {
+	Title string
+	Body  string
+	URL   string // Optional link back to the leaderboard
+}
+
+// Result describes the outcome of a single delivery attempt
+type Result struct {
+	StatusCode   int    // HTTP status of the remote side, 0 if no response was received
+	ResponseBody string // Truncated response body, kept for failed deliveries
+}
+
+// Channel is a destination notifications can be delivered to
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) (Result, error)
+}
+
+// Notifier fans messages out to registered channels and logs each attempt
+type Notifier struct {
+	mu         sync.RWMutex
+	channels   map[string]Channel
+	deliveries *repositories.DeliveryRepository
+	timeout    time.Duration
+}
+
+// NewNotifier creates a notifier that records attempts in the delivery log
+func NewNotifier(deliveries *repositories.DeliveryRepository) *Notifier {
+	return &Notifier{
+		channels:   make(map[string]Channel),
+		deliveries: deliveries,
+		timeout:    10 * time.Second,
+	}
+}
+
+// Register adds a channel, replacing any channel with the same name
+func (n *Notifier) Register(ch Channel) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.channels[ch.Name()] = ch
+}
+
+// Channels returns the names of all registered channels, sorted
+func (n *Notifier) Channels() []string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	names := make([]string, 0, len(n.channels))
+	for name := range n.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Send delivers a message to one channel and records the attempt
+func (n *Notifier) Send(ctx context.Context, channel, event string, msg Message) (*models.NotificationDelivery, error) {
+	n.mu.RLock()
+	ch, ok := n.channels[channel]
+	n.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("channel not found")
+	}
+
+	return n.deliver(ctx, ch, event, msg), nil
+}
+
+// Broadcast delivers a message to every registered channel
+// Failures are recorded in the delivery log and don't stop other channels
+func (n *Notifier) Broadcast(ctx context.Context, event string, msg Message) {
+	n.mu.RLock()
+	channels := make([]Channel, 0, len(n.channels))
+	for _, ch := range n.channels {
+		channels = append(channels, ch)
+	}
+	n.mu.RUnlock()
+
+	for _, ch := range channels {
+		n.deliver(ctx, ch, event, msg)
+	}
+}
+
+// deliver sends to a channel with a timeout and writes the delivery log entry
+func (n *Notifier) deliver(ctx context.Context, ch Channel, event string, msg Message) *models.NotificationDelivery {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := ch.Send(ctx, msg)
+
+	delivery := &models.NotificationDelivery{
+		Channel:    ch.Name(),
+		Event:      event,
+		Status:     models.DeliveryDelivered,
+		DurationMS: int(time.Since(start).Milliseconds()),
+	}
+	if result.StatusCode != 0 {
+		code := result.StatusCode
+		delivery.StatusCode = &code
+	}
+	if err != nil {
+		errMsg := err.Error()
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = &errMsg
+		if result.ResponseBody != "" {
+			body := result.ResponseBody
+			delivery.ResponseBody = &body
+		}
+		slog.Warn("Notification delivery failed", "channel", ch.Name(), "event", event, "status_code", result.StatusCode, "error", err)
+	}
+
+	// Logged even when the send ran out of time
+	if recordErr := n.deliveries.Record(context.WithoutCancel(ctx), delivery); recordErr != nil {
+		slog.Error("Failed to record notification delivery", "channel", ch.Name(), "error", recordErr)
+	}
+
+	return delivery
+}
@@ -0,0 +1,249 @@
+package notifications
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+)
+
+const (
+	// rankTopN is the leaderboard region players are told about entering or leaving
+	rankTopN = 10
+	// rankUpdateQueue bounds pending leaderboard updates; dropped updates are caught up by the next diff
+	rankUpdateQueue = 64
+)
+
+// rankKey identifies a player on one sport's leaderboard
+type rankKey struct {
+	sport  string
+	userID int
+}
+
+// rankChange collects a player's rank changes during the debounce window
+type rankChange struct {
+	startRank   int
+	overtakenBy map[int]struct{}
+	timer       *time.Timer
+}
+
+// RankWatcher notifies players when they are overtaken or enter or leave the top 10
+// Every leaderboard update is diffed against the last known ranks. Changes are
+// collected per player for a debounce window, so a burst of matches produces a
+// single notification and a rank lost and regained within the window none at all
+type RankWatcher struct {
+	leaderboard LeaderboardFunc
+	inbox       *Inbox
+	debounce    time.Duration
+
+	updates chan string
+	done    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	ranks   map[string]map[int]int // sport -> user -> rank
+	pending map[rankKey]*rankChange
+}
+
+// NewRankWatcher creates a watcher and starts its worker
+func NewRankWatcher(leaderboard LeaderboardFunc, inbox *Inbox, debounce time.Duration) *RankWatcher {
+	w := &RankWatcher{
+		leaderboard: leaderboard,
+		inbox:       inbox,
+		debounce:    debounce,
+		updates:     make(chan string, rankUpdateQueue),
+		done:        make(chan struct{}),
+		ranks:       make(map[string]map[int]int),
+		pending:     make(map[rankKey]*rankChange),
+	}
+
+	w.wg.Add(1)
+	middleware.SafeGoroutineWithContext("rank_watcher", func() {
+		defer w.wg.Done()
+		for {
+			select {
+			case <-w.done:
+				return
+			case sport := <-w.updates:
+				w.update(sport)
+			}
+		}
+	})
+
+	return w
+}
+
+// Publish queues a diff whenever a leaderboard changes
+func (w *RankWatcher) Publish(channel, eventType string, data interface{}) {
+	if eventType != services.EventLeaderboardUpdated {
+		return
+	}
+	payload, ok := data.(map[string]string)
+	if !ok || payload["sport"] == "" {
+		return
+	}
+	w.queue(payload["sport"])
+}
+
+// Prime records the current ranks of the given sports as the baseline
+func (w *RankWatcher) Prime(sports ...string) {
+	for _, sport := range sports {
+		w.queue(sport)
+	}
+}
+
+func (w *RankWatcher) queue(sport string) {
+	select {
+	case <-w.done:
+		return
+	default:
+	}
+
+	select {
+	case w.updates <- sport:
+	default:
+		slog.Warn("Rank watcher queue full, dropping leaderboard update", "sport", sport)
+	}
+}
+
+// update diffs the current leaderboard against the last known ranks
+func (w *RankWatcher) update(sport string) {
+	entries, err := w.leaderboard(sport)
+	if err != nil {
+		slog.Error("Failed to load leaderboard for rank notifications", "sport", sport, "error", err)
+		return
+	}
+
+	current := make(map[int]int, len(entries))
+	for _, entry := range entries {
+		current[entry.User.ID] = entry.Rank
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	previous, known := w.ranks[sport]
+	w.ranks[sport] = current
+	if !known {
+		return
+	}
+
+	// Players new to the leaderboard count as ranked just below everyone else
+	unranked := len(previous) + 1
+	rankBefore := func(userID int) int {
+		if rank, ok := previous[userID]; ok {
+			return rank
+		}
+		return unranked
+	}
+
+	var climbers []int
+	for userID, rank := range current {
+		if rank < rankBefore(userID) {
+			climbers = append(climbers, userID)
+		}
+	}
+
+	for userID, rank := range current {
+		before := rankBefore(userID)
+		if rank == before {
+			continue
+		}
+
+		key := rankKey{sport: sport, userID: userID}
+		change, ok := w.pending[key]
+		if !ok {
+			change = &rankChange{startRank: before, overtakenBy: make(map[int]struct{})}
+			change.timer = time.AfterFunc(w.debounce, func() { w.flush(key) })
+			w.pending[key] = change
+		}
+
+		if rank > before {
+			for _, climber := range climbers {
+				if rankBefore(climber) > before && current[climber] < rank {
+					change.overtakenBy[climber] = struct{}{}
+				}
+			}
+		}
+	}
+}
+
+// flush turns the net rank change of a debounce window into a notification
+func (w *RankWatcher) flush(key rankKey) {
+	w.mu.Lock()
+	change, ok := w.pending[key]
+	delete(w.pending, key)
+	rank, ranked := w.ranks[key.sport][key.userID]
+	if !ok || !ranked {
+		w.mu.Unlock()
+		return
+	}
+	// Only count overtakers that are still ahead at the end of the window
+	overtakers := 0
+	for userID := range change.overtakenBy {
+		if other, ok := w.ranks[key.sport][userID]; ok && other < rank {
+			overtakers++
+		}
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return
+	default:
+	}
+
+	n := &models.UserNotification{
+		UserID: key.userID,
+		Sport:  &key.sport,
+		Data: map[string]interface{}{
+			"previous_rank": change.startRank,
+			"rank":          rank,
+			"top_n":         rankTopN,
+		},
+	}
+
+	switch {
+	case change.startRank > rankTopN && rank <= rankTopN:
+		n.Kind = models.NotificationTop10Entered
+	case change.startRank <= rankTopN && rank > rankTopN:
+		n.Kind = models.NotificationTop10Left
+	case rank > change.startRank && overtakers > 0:
+		n.Kind = models.NotificationRankOvertaken
+		n.Data["overtaken_by"] = overtakers
+	default:
+		return
+	}
+
+	w.inbox.Notify(context.Background(), n)
+}
+
+// Close stops the watcher; changes still inside their debounce window are dropped
+func (w *RankWatcher) Close(ctx context.Context) error {
+	w.once.Do(func() { close(w.done) })
+
+	w.mu.Lock()
+	for key, change := range w.pending {
+		change.timer.Stop()
+		delete(w.pending, key)
+	}
+	w.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SlackChannel posts messages to a Slack incoming webhook
+type SlackChannel struct {
+	name       string
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackChannel creates a channel that posts to the given incoming webhook URL
+func NewSlackChannel(name, webhookURL string) *SlackChannel {
+	return &SlackChannel{
+		name:       name,
+		webhookURL: webhookURL,
+		client:     &http.Client{},
+	}
+}
+
+// Name returns the channel name used in the delivery log
+func (s *SlackChannel) Name() string {
+	return s.name
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// Send posts the message as mrkdwn text: bold title, body and link
+func (s *SlackChannel) Send(ctx context.Context, msg Message) (Result, error) {
+	lines := []string{"*" + msg.Title + "*"}
+	if msg.Body != "" {
+		lines = append(lines, msg.Body)
+	}
+	if msg.URL != "" {
+		lines = append(lines, "<"+msg.URL+">")
+	}
+	payload, err := json.Marshal(slackPayload{Text: strings.Join(lines, "\n")})
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := Result{StatusCode: resp.StatusCode}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		result.ResponseBody = string(body)
+		return result, fmt.Errorf("slack returned status %d", resp.StatusCode)
+	}
+
+	return result, nil
+}
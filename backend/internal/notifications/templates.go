@@ -0,0 +1,162 @@
+package notifications
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// Message formats; a template for FormatDefault is used by channels without their own
+const (
+	FormatDefault = "default"
+	FormatInbox   = "inbox"   // plain text for the inbox and live pushes
+	FormatDiscord = "discord" // Discord embeds, markdown allowed
+)
+
+//go:embed templates/*.json
+var builtinTemplates embed.FS
+
+// templateFile is the on-disk form of a language: kind -> format -> title and body
+type templateFile map[string]map[string]struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type messageTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// Templates renders notifications in the recipient's language and the format of the channel
+// Each language lives in a <lang>.json file: the built-in ones are embedded, and
+// files in an override directory replace single templates without a rebuild
+type Templates struct {
+	langs map[string]map[string]map[string]messageTemplate // language -> kind -> format
+}
+
+var templateFuncs = template.FuncMap{
+	// datetime formats a time in UTC, e.g. "2024-05-01 18:30"
+	"datetime": func(v interface{}) string {
+		switch t := v.(type) {
+		case time.Time:
+			return t.UTC().Format("2006-01-02 15:04")
+		case *time.Time:
+			if t != nil {
+				return t.UTC().Format("2006-01-02 15:04")
+			}
+		}
+		return ""
+	},
+	"abs": func(n int) int {
+		if n < 0 {
+			return -n
+		}
+		return n
+	},
+}
+
+// LoadTemplates parses the built-in templates, then the overrides in dir ("" = none)
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{langs: make(map[string]map[string]map[string]messageTemplate)}
+
+	builtin, err := fs.Sub(builtinTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	if err := t.loadDir(builtin); err != nil {
+		return nil, err
+	}
+
+	if dir != "" {
+		if err := t.loadDir(os.DirFS(dir)); err != nil {
+			return nil, fmt.Errorf("failed to load notification templates from %s: %w", dir, err)
+		}
+		slog.Info("Notification template overrides loaded", "dir", dir)
+	}
+
+	if _, ok := t.langs[models.LanguageEnglish]; !ok {
+		return nil, fmt.Errorf("notification templates for %q are missing", models.LanguageEnglish)
+	}
+	return t, nil
+}
+
+func (t *Templates) loadDir(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		raw, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var file templateFile
+		if err := json.Unmarshal(raw, &file); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		lang := strings.TrimSuffix(filepath.Base(name), ".json")
+		if t.langs[lang] == nil {
+			t.langs[lang] = make(map[string]map[string]messageTemplate)
+		}
+		for kind, formats := range file {
+			if t.langs[lang][kind] == nil {
+				t.langs[lang][kind] = make(map[string]messageTemplate)
+			}
+			for format, text := range formats {
+				id := lang + "/" + kind + "/" + format
+				title, err := template.New(id + "/title").Funcs(templateFuncs).Option("missingkey=error").Parse(text.Title)
+				if err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				body, err := template.New(id + "/body").Funcs(templateFuncs).Option("missingkey=error").Parse(text.Body)
+				if err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				t.langs[lang][kind][format] = messageTemplate{title: title, body: body}
+			}
+		}
+	}
+	return nil
+}
+
+// lookup finds the template of a kind, preferring the channel's own format
+func (t *Templates) lookup(lang, format, kind string) (messageTemplate, bool) {
+	formats := t.langs[lang][kind]
+	if tmpl, ok := formats[format]; ok {
+		return tmpl, true
+	}
+	tmpl, ok := formats[FormatDefault]
+	return tmpl, ok
+}
+
+// Render builds the message of a notification kind; unknown languages and
+// missing translations fall back to English
+func (t *Templates) Render(lang, format, kind string, data interface{}) (Message, error) {
+	tmpl, ok := t.lookup(lang, format, kind)
+	if !ok {
+		tmpl, ok = t.lookup(models.LanguageEnglish, format, kind)
+	}
+	if !ok {
+		return Message{}, fmt.Errorf("no notification template for %s", kind)
+	}
+
+	var title, body strings.Builder
+	if err := tmpl.title.Execute(&title, data); err != nil {
+		return Message{}, err
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	return Message{Title: strings.TrimSpace(title.String()), Body: strings.TrimSpace(body.String())}, nil
+}
@@ -0,0 +1,340 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/middleware"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/services"
+)
+
+const (
+	// webhookMaxAttempts is how often a delivery is tried before it is given up
+	webhookMaxAttempts = 5
+	// webhookRetryBase is the delay before the first retry; it doubles per attempt
+	webhookRetryBase = 10 * time.Second
+	// webhookWorkers bounds concurrent deliveries
+	webhookWorkers = 4
+	// webhookQueueSize bounds pending work; events are dropped when it is full
+	webhookQueueSize = 256
+)
+
+// WebhookChannelPrefix prefixes the delivery log channel of a webhook ("webhook:<id>")
+const WebhookChannelPrefix = "webhook:"
+
+// WebhookChannel returns the delivery log channel name of a webhook
+func WebhookChannel(id int) string {
+	return WebhookChannelPrefix + strconv.Itoa(id)
+}
+
+// LeaderboardFunc returns the ranked leaderboard of a sport, best first
+type LeaderboardFunc func(sport string) ([]models.LeaderboardEntry, error)
+
+// webhookEnvelope is the JSON body posted to webhooks
+type webhookEnvelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// podiumSpot is one place of a top-3; only IDs are sent so privacy settings apply on refetch
+type podiumSpot struct {
+	Rank   int `json:"rank"`
+	UserID int `json:"user_id"`
+	ELO    int `json:"elo"`
+}
+
+type podiumChange struct {
+	Sport    string       `json:"sport"`
+	Previous []podiumSpot `json:"previous"`
+	Current  []podiumSpot `json:"current"`
+}
+
+// WebhookDispatcher posts signed event payloads to admin-registered webhooks
+// It implements services.EventPublisher; deliveries run on a small worker pool
+// and failed attempts are retried with exponential backoff
+type WebhookDispatcher struct {
+	webhooks   *repositories.WebhookRepository
+	deliveries *repositories.DeliveryRepository
+	podium     LeaderboardFunc
+	client     *http.Client
+
+	queue chan func()
+	done  chan struct{}
+	wg    sync.WaitGroup
+	once  sync.Once
+
+	mu      sync.Mutex
+	podiums map[string][]podiumSpot // last known top-3 per sport
+}
+
+// NewWebhookDispatcher creates a dispatcher and starts its workers
+func NewWebhookDispatcher(
+	webhooks *repositories.WebhookRepository,
+	deliveries *repositories.DeliveryRepository,
+	podium LeaderboardFunc,
+) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		podium:     podium,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		queue:      make(chan func(), webhookQueueSize),
+		done:       make(chan struct{}),
+		podiums:    make(map[string][]podiumSpot),
+	}
+
+	for i := 0; i < webhookWorkers; i++ {
+		d.wg.Add(1)
+		middleware.SafeGoroutineWithContext("webhook_worker", func() {
+			defer d.wg.Done()
+			d.work()
+		})
+	}
+
+	return d
+}
+
+func (d *WebhookDispatcher) work() {
+	for {
+		select {
+		case <-d.done:
+			return
+		case fn := <-d.queue:
+			fn()
+		}
+	}
+}
+
+// enqueue schedules work without blocking the caller
+func (d *WebhookDispatcher) enqueue(fn func()) {
+	select {
+	case <-d.done:
+		return
+	default:
+	}
+
+	select {
+	case d.queue <- fn:
+	default:
+		slog.Warn("Webhook queue full, dropping work")
+	}
+}
+
+// Publish maps service events to webhook events
+func (d *WebhookDispatcher) Publish(channel, eventType string, data interface{}) {
+	switch eventType {
+	case services.EventMatchConfirmed:
+		d.enqueue(func() { d.dispatch(models.WebhookEventMatchConfirmed, data) })
+	case services.EventLeaderboardUpdated:
+		payload, ok := data.(map[string]string)
+		if !ok || payload["sport"] == "" {
+			return
+		}
+		sport := payload["sport"]
+		d.enqueue(func() { d.checkPodium(sport) })
+	case models.WebhookEventUserBanned:
+		d.enqueue(func() { d.dispatch(models.WebhookEventUserBanned, data) })
+	}
+}
+
+// PrimePodiums records the current top-3 of the given sports so the first
+// change after startup is detected
+func (d *WebhookDispatcher) PrimePodiums(sports ...string) {
+	for _, sport := range sports {
+		sport := sport
+		d.enqueue(func() { d.checkPodium(sport) })
+	}
+}
+
+// checkPodium compares a sport's top-3 to the last known one and emits a change event
+func (d *WebhookDispatcher) checkPodium(sport string) {
+	entries, err := d.podium(sport)
+	if err != nil {
+		slog.Error("Failed to load podium for webhooks", "sport", sport, "error", err)
+		return
+	}
+
+	current := make([]podiumSpot, 0, 3)
+	for _, entry := range entries {
+		if len(current) == 3 {
+			break
+		}
+		current = append(current, podiumSpot{Rank: entry.Rank, UserID: entry.User.ID, ELO: entry.ELO})
+	}
+
+	d.mu.Lock()
+	previous, known := d.podiums[sport]
+	d.podiums[sport] = current
+	d.mu.Unlock()
+
+	if !known || samePodium(previous, current) {
+		return
+	}
+
+	d.dispatch(models.WebhookEventTop3Changed, podiumChange{Sport: sport, Previous: previous, Current: current})
+}
+
+// samePodium reports whether the same players hold the same ranks; ELO changes alone don't count
+func samePodium(a, b []podiumSpot) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].UserID != b[i].UserID || a[i].Rank != b[i].Rank {
+			return false
+		}
+	}
+	return true
+}
+
+// dispatch sends an event to every active webhook subscribed to it
+func (d *WebhookDispatcher) dispatch(event string, data interface{}) {
+	hooks, err := d.webhooks.ListActiveForEvent(context.Background(), event)
+	if err != nil {
+		slog.Error("Failed to load webhooks", "event", event, "error", err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(webhookEnvelope{
+		ID:        newDeliveryID(),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		slog.Error("Failed to encode webhook payload", "event", event, "error", err)
+		return
+	}
+
+	for _, hook := range hooks {
+		d.attempt(hook, event, body, 1)
+	}
+}
+
+// attempt delivers a payload once, records it and schedules a retry if worthwhile
+func (d *WebhookDispatcher) attempt(hook models.Webhook, event string, body []byte, attempt int) {
+	start := time.Now()
+	statusCode, responseBody, err := d.post(hook, event, body)
+
+	delivery := &models.NotificationDelivery{
+		Channel:    WebhookChannel(hook.ID),
+		Event:      event,
+		Status:     models.DeliveryDelivered,
+		DurationMS: int(time.Since(start).Milliseconds()),
+		Attempt:    attempt,
+	}
+	if statusCode != 0 {
+		delivery.StatusCode = &statusCode
+	}
+	if err != nil {
+		errMsg := err.Error()
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = &errMsg
+		if responseBody != "" {
+			delivery.ResponseBody = &responseBody
+		}
+	}
+
+	if recordErr := d.deliveries.Record(context.Background(), delivery); recordErr != nil {
+		slog.Error("Failed to record webhook delivery", "webhook_id", hook.ID, "error", recordErr)
+	}
+
+	if err == nil {
+		return
+	}
+
+	retryable := statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+	if !retryable || attempt >= webhookMaxAttempts {
+		slog.Warn("Webhook delivery failed", "webhook_id", hook.ID, "event", event, "attempt", attempt, "status_code", statusCode, "error", err)
+		return
+	}
+
+	delay := webhookRetryBase << (attempt - 1)
+	time.AfterFunc(delay, func() {
+		d.enqueue(func() { d.attempt(hook, event, body, attempt+1) })
+	})
+}
+
+// post sends one signed request
+func (d *WebhookDispatcher) post(hook models.Webhook, event string, body []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "elo-leaderboard-webhooks")
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-ID", strconv.Itoa(hook.ID))
+	req.Header.Set("X-Webhook-Signature", "t="+timestamp+",v1="+Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		return resp.StatusCode, string(respBody), fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, "", nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" with the webhook secret
+// Receivers recompute it from the X-Webhook-Signature timestamp to verify a payload
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDeliveryID returns a random ID receivers can use to deduplicate retries
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Close stops the workers; queued work and pending retries are dropped
+func (d *WebhookDispatcher) Close(ctx context.Context) error {
+	d.once.Do(func() { close(d.done) })
+
+	finished := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,97 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+
+	// Time allowed to read the next pong message from the peer
+	pongWait = 60 * time.Second
+
+	// Send pings to peer with this period (must be less than pongWait)
+	pingPeriod = (pongWait * 9) / 10
+
+	// Clients only send control frames, so inbound messages stay tiny
+	maxMessageSize = 512
+
+	// Buffered outbound events per client before it is considered too slow
+	sendBufferSize = 32
+)
+
+// client is a single WebSocket connection subscribed to one channel
+type client struct {
+	hub       *Hub
+	conn      *websocket.Conn
+	channel   string
+	send      chan []byte
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, channel string) *client {
+	return &client{
+		hub:     hub,
+		conn:    conn,
+		channel: channel,
+		send:    make(chan []byte, sendBufferSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// close unsubscribes the client and tears down the connection (safe to call repeatedly)
+func (cl *client) close() {
+	cl.closeOnce.Do(func() {
+		cl.hub.unsubscribe(cl)
+		close(cl.done)
+		cl.conn.Close()
+	})
+}
+
+// readPump discards inbound messages and keeps the read deadline alive via pongs
+func (cl *client) readPump() {
+	defer cl.close()
+
+	cl.conn.SetReadLimit(maxMessageSize)
+	cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	cl.conn.SetPongHandler(func(string) error {
+		return cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := cl.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump forwards published events to the connection and sends periodic pings
+func (cl *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		cl.close()
+	}()
+
+	for {
+		select {
+		case message := <-cl.send:
+			cl.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := cl.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			cl.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := cl.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-cl.done:
+			return
+		}
+	}
+}
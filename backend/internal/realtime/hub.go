@@ -0,0 +1,130 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Event is a message pushed to all subscribers of a channel
+type Event struct {
+	Channel string      `json:"channel"`
+	Type    string      `json:"type"`
+	Data    interface{} `json:"data"`
+}
+
+// Hub fans out events to WebSocket clients subscribed to named channels
+type Hub struct {
+	upgrader websocket.Upgrader
+	mu       sync.RWMutex
+	channels map[string]map[*client]struct{}
+}
+
+// NewHub creates a new Hub that accepts connections from the allowed origins
+func NewHub(allowedOrigins []string) *Hub {
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origins[origin] = true
+	}
+
+	return &Hub{
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				// Non-browser clients don't send an Origin header
+				return origin == "" || origins[origin]
+			},
+		},
+		channels: make(map[string]map[*client]struct{}),
+	}
+}
+
+// GlobalChannel carries site-wide events (leaderboard changes, match lifecycle, comments, reactions)
+const GlobalChannel = "global"
+
+// MatchChannel returns the channel name for live updates of a match
+func MatchChannel(matchID int) string {
+	return fmt.Sprintf("match:%d", matchID)
+}
+
+// UserChannel returns the private channel of a user (notifications)
+func UserChannel(userID int) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// Serve upgrades the request to a WebSocket and subscribes it to a channel
+// The connection is served in the background; Serve returns once the upgrade is done
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, channel string) error {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	cl := newClient(h, conn, channel)
+	h.subscribe(cl)
+
+	go cl.writePump()
+	go cl.readPump()
+
+	return nil
+}
+
+// Publish sends an event to every subscriber of a channel
+// Slow subscribers whose buffers are full are disconnected instead of blocking the publisher
+func (h *Hub) Publish(channel, eventType string, data interface{}) {
+	payload, err := json.Marshal(Event{Channel: channel, Type: eventType, Data: data})
+	if err != nil {
+		slog.Error("Failed to marshal realtime event", "error", err, "type", eventType)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for cl := range h.channels[channel] {
+		select {
+		case cl.send <- payload:
+		default:
+			go cl.close()
+		}
+	}
+}
+
+// Subscribers returns the number of clients subscribed to a channel
+func (h *Hub) Subscribers(channel string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.channels[channel])
+}
+
+func (h *Hub) subscribe(cl *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.channels[cl.channel]
+	if !ok {
+		subs = make(map[*client]struct{})
+		h.channels[cl.channel] = subs
+	}
+	subs[cl] = struct{}{}
+}
+
+func (h *Hub) unsubscribe(cl *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.channels[cl.channel]
+	if !ok {
+		return
+	}
+	delete(subs, cl)
+	if len(subs) == 0 {
+		delete(h.channels, cl.channel)
+	}
+}
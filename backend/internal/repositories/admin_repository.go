@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -18,57 +19,60 @@ func NewAdminRepository(db *sql.DB) *AdminRepository {
 }
 
 // GetSystemHealth returns system health statistics
-func (r *AdminRepository) GetSystemHealth() (*models.SystemHealth, error) {
+func (r *AdminRepository) GetSystemHealth(ctx context.Context) (*models.SystemHealth, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	health := &models.SystemHealth{
 		Status:         "healthy",
 		DatabaseStatus: "connected",
 	}
 
 	// Get total users
-	err := r.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&health.TotalUsers)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&health.TotalUsers)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get total matches
-	err = r.db.QueryRow("SELECT COUNT(*) FROM matches").Scan(&health.TotalMatches)
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM matches WHERE deleted_at IS NULL").Scan(&health.TotalMatches)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get pending matches
-	err = r.db.QueryRow("SELECT COUNT(*) FROM matches WHERE status = 'pending'").Scan(&health.PendingMatches)
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM matches WHERE status = 'pending' AND deleted_at IS NULL").Scan(&health.PendingMatches)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get disputed matches
-	err = r.db.QueryRow("SELECT COUNT(*) FROM matches WHERE status = 'disputed'").Scan(&health.DisputedMatches)
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM matches WHERE status = 'disputed' AND deleted_at IS NULL").Scan(&health.DisputedMatches)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get banned users
-	err = r.db.QueryRow("SELECT COUNT(*) FROM users WHERE is_banned = true").Scan(&health.BannedUsers)
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE is_banned = true").Scan(&health.BannedUsers)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get matches today
 	today := time.Now().Truncate(24 * time.Hour)
-	err = r.db.QueryRow("SELECT COUNT(*) FROM matches WHERE created_at >= $1", today).Scan(&health.MatchesToday)
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM matches WHERE created_at >= $1 AND deleted_at IS NULL", today).Scan(&health.MatchesToday)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get active users today (submitted or confirmed a match)
-	err = r.db.QueryRow(`
+	err = r.db.QueryRowContext(ctx, `
 		SELECT COUNT(DISTINCT user_id) FROM (
-			SELECT submitted_by as user_id FROM matches WHERE created_at >= $1
+			SELECT submitted_by as user_id FROM matches WHERE created_at >= $1 AND deleted_at IS NULL
 			UNION
-			SELECT player1_id as user_id FROM matches WHERE confirmed_at >= $1
+			SELECT player1_id as user_id FROM matches WHERE confirmed_at >= $1 AND deleted_at IS NULL
 			UNION
-			SELECT player2_id as user_id FROM matches WHERE confirmed_at >= $1
+			SELECT player2_id as user_id FROM matches WHERE confirmed_at >= $1 AND deleted_at IS NULL
 		) active_users
 	`, today).Scan(&health.ActiveUsersToday)
 	if err != nil {
@@ -78,58 +82,345 @@ func (r *AdminRepository) GetSystemHealth() (*models.SystemHealth, error) {
 	return health, nil
 }
 
-// BanUser bans a user
-func (r *AdminRepository) BanUser(userID int, reason string, adminID int) error {
+// GetAnalytics returns the daily engagement figures of the days days ending with today (UTC),
+// oldest first and without gaps, and the figures of the whole window
+func (r *AdminRepository) GetAnalytics(ctx context.Context, days int) ([]models.AnalyticsDay, *models.AnalyticsPeriod, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -(days - 1))
+	to := today.AddDate(0, 0, 1)
+
+	// Rows with a NULL day are the totals of the grouping set ()
+	// Forfeits and imported matches never waited for the opponent, so they have no latency
+	matchQuery := `
+		SELECT day, COUNT(*),
+		       COUNT(*) FILTER (WHERE status = 'confirmed'),
+		       COUNT(*) FILTER (WHERE status = 'denied'),
+		       COUNT(*) FILTER (WHERE disputed),
+		       AVG(latency),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY latency)
+		FROM (
+			SELECT m.created_at::date AS day, m.status,
+			       CASE WHEN m.status = 'confirmed' AND m.result = 'played' AND m.context IS DISTINCT FROM 'import'
+			            THEN EXTRACT(EPOCH FROM m.confirmed_at - m.created_at) END AS latency,
+			       m.status = 'disputed'
+			       OR EXISTS (SELECT 1 FROM match_counter_proposals cp WHERE cp.match_id = m.id)
+			       OR EXISTS (SELECT 1 FROM reports rp WHERE rp.target_type = 'match' AND rp.target_id = m.id) AS disputed
+			FROM matches_all m
+			WHERE m.created_at >= $1 AND m.created_at < $2
+		) m
+		GROUP BY GROUPING SETS ((day), ())
+	`
+	rows, err := r.db.QueryContext(ctx, matchQuery, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get match analytics: %w", err)
+	}
+	defer rows.Close()
+
+	periods := make(map[string]*models.AnalyticsPeriod)
+	totals := &models.AnalyticsPeriod{}
+	for rows.Next() {
+		var day sql.NullTime
+		var avg, median sql.NullFloat64
+		p := &models.AnalyticsPeriod{}
+		if err := rows.Scan(&day, &p.Matches, &p.Confirmed, &p.Denied, &p.Disputed, &avg, &median); err != nil {
+			return nil, nil, err
+		}
+		if avg.Valid {
+			p.AvgConfirmationSeconds = &avg.Float64
+		}
+		if median.Valid {
+			p.MedianConfirmationSeconds = &median.Float64
+		}
+		if day.Valid {
+			periods[day.Time.Format("2006-01-02")] = p
+		} else {
+			totals = p
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	userQuery := `
+		SELECT created_at::date, COUNT(*)
+		FROM users
+		WHERE id > 0 AND created_at >= $1 AND created_at < $2
+		GROUP BY ROLLUP (created_at::date)
+	`
+	userRows, err := r.db.QueryContext(ctx, userQuery, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user analytics: %w", err)
+	}
+	defer userRows.Close()
+
+	newUsers := make(map[string]int)
+	for userRows.Next() {
+		var day sql.NullTime
+		var count int
+		if err := userRows.Scan(&day, &count); err != nil {
+			return nil, nil, err
+		}
+		if day.Valid {
+			newUsers[day.Time.Format("2006-01-02")] = count
+		} else {
+			totals.NewUsers = count
+		}
+	}
+	if err := userRows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	series := make([]models.AnalyticsDay, 0, days)
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		day := models.AnalyticsDay{Date: date}
+		if p, ok := periods[date]; ok {
+			day.AnalyticsPeriod = *p
+		}
+		day.NewUsers = newUsers[date]
+		setAnalyticsRates(&day.AnalyticsPeriod)
+		series = append(series, day)
+	}
+	setAnalyticsRates(totals)
+
+	return series, totals, nil
+}
+
+// setAnalyticsRates derives the denial and dispute rates from the counts of a period
+func setAnalyticsRates(p *models.AnalyticsPeriod) {
+	if decided := p.Confirmed + p.Denied; decided > 0 {
+		rate := float64(p.Denied) / float64(decided)
+		p.DenialRate = &rate
+	}
+	if p.Matches > 0 {
+		rate := float64(p.Disputed) / float64(p.Matches)
+		p.DisputeRate = &rate
+	}
+}
+
+// BanUser bans a user until the given time, or until lifted when until is nil
+// Banning an already banned user replaces the reason and the end of the ban
+func (r *AdminRepository) BanUser(ctx context.Context, userID int, reason string, adminID int, until *time.Time) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE users
-		SET is_banned = true, ban_reason = $1, banned_at = $2, banned_by = $3, updated_at = $2
+		SET is_banned = true, ban_reason = $1, banned_at = $2, banned_by = $3, banned_until = $5, updated_at = $2
 		WHERE id = $4
 	`
 	now := time.Now()
-	_, err := r.db.Exec(query, reason, now, adminID, userID)
+	_, err := r.db.ExecContext(ctx, query, reason, now, adminID, userID, until)
 	return err
 }
 
 // UnbanUser unbans a user
-func (r *AdminRepository) UnbanUser(userID int) error {
+func (r *AdminRepository) UnbanUser(ctx context.Context, userID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE users
-		SET is_banned = false, ban_reason = NULL, banned_at = NULL, banned_by = NULL, updated_at = CURRENT_TIMESTAMP
+		SET is_banned = false, ban_reason = NULL, banned_at = NULL, banned_by = NULL, banned_until = NULL, updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1
 	`
-	_, err := r.db.Exec(query, userID)
+	_, err := r.db.ExecContext(ctx, query, userID)
 	return err
 }
 
-// SetAdmin sets or removes admin privileges
-func (r *AdminRepository) SetAdmin(userID int, isAdmin bool) error {
-	query := `UPDATE users SET is_admin = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
-	_, err := r.db.Exec(query, isAdmin, userID)
+// LiftExpiredBans unbans users whose temporary ban ended and returns their IDs and logins
+func (r *AdminRepository) LiftExpiredBans(ctx context.Context) (map[int]string, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET is_banned = false, ban_reason = NULL, banned_at = NULL, banned_by = NULL, banned_until = NULL, updated_at = $1
+		WHERE is_banned AND banned_until IS NOT NULL AND banned_until <= $1
+		RETURNING id, login
+	`
+	rows, err := r.db.QueryContext(ctx, query, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to lift expired bans: %w", err)
+	}
+	defer rows.Close()
+
+	lifted := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var login string
+		if err := rows.Scan(&id, &login); err != nil {
+			return nil, fmt.Errorf("failed to scan lifted ban: %w", err)
+		}
+		lifted[id] = login
+	}
+	return lifted, rows.Err()
+}
+
+// MuteUser shadow-mutes a user from commenting; muting again keeps the original time
+func (r *AdminRepository) MuteUser(ctx context.Context, userID, adminID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET comments_muted_at = COALESCE(comments_muted_at, CURRENT_TIMESTAMP),
+		    comments_muted_by = COALESCE(comments_muted_by, $2)
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, adminID)
 	return err
 }
 
+// UnmuteUser lets a user comment visibly again; earlier shadowed comments stay hidden
+func (r *AdminRepository) UnmuteUser(ctx context.Context, userID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `UPDATE users SET comments_muted_at = NULL, comments_muted_by = NULL WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+// GetMutedUsers returns the shadow-muted users, most recently muted first
+func (r *AdminRepository) GetMutedUsers(ctx context.Context) ([]models.MutedUser, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, login, display_name, comments_muted_at, comments_muted_by
+		FROM users
+		WHERE comments_muted_at IS NOT NULL
+		ORDER BY comments_muted_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.MutedUser{}
+	for rows.Next() {
+		var u models.MutedUser
+		if err := rows.Scan(&u.UserID, &u.Login, &u.DisplayName, &u.MutedAt, &u.MutedBy); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// SetRole changes the staff role of a user and returns the previous role
+// models.RoleUser removes all admin privileges. The last superadmin cannot be demoted:
+// superadmins are locked first so concurrent demotions cannot both succeed
+func (r *AdminRepository) SetRole(ctx context.Context, userID int, role string) (string, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM users WHERE role = $1 FOR UPDATE`, models.RoleSuperadmin)
+	if err != nil {
+		return "", err
+	}
+	superadmins := 0
+	for rows.Next() {
+		superadmins++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	var oldRole string
+	var isBanned bool
+	err = tx.QueryRowContext(ctx, `SELECT role, is_banned FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&oldRole, &isBanned)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if oldRole == models.RoleSuperadmin && role != models.RoleSuperadmin && superadmins <= 1 {
+		return "", fmt.Errorf("cannot demote the last superadmin")
+	}
+	if isBanned && role != models.RoleUser {
+		return "", fmt.Errorf("cannot promote a banned user")
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, role, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return oldRole, tx.Commit()
+}
+
 // AdjustELO manually adjusts a user's ELO
-func (r *AdminRepository) AdjustELO(userID int, sport string, newELO int, reason string, adminID int) (*models.ELOAdjustment, error) {
-	// Get current ELO
-	var oldELO int
-	var query string
-	if sport == models.SportTableTennis {
-		query = "SELECT table_tennis_elo FROM users WHERE id = $1"
-	} else {
-		query = "SELECT table_football_elo FROM users WHERE id = $1"
+// The new rating is clamped to the sport's rating bounds; the returned adjustment holds
+// the rating that was set
+func (r *AdminRepository) AdjustELO(ctx context.Context, userID int, sport string, newELO int, reason string, adminID int) (*models.ELOAdjustment, error) {
+	return r.adjustELO(ctx, userID, sport, models.ELOAdjustmentSet, newELO, reason, adminID)
+}
+
+// PenalizeELO deducts points from a user's current ELO as a moderation penalty
+// The result is clamped to the sport's rating bounds like any other adjustment
+func (r *AdminRepository) PenalizeELO(ctx context.Context, userID int, sport string, points int, reason string, adminID int) (*models.ELOAdjustment, error) {
+	return r.adjustELO(ctx, userID, sport, models.ELOAdjustmentPenalty, points, reason, adminID)
+}
+
+// adjustELO sets the rating to value, or lowers it by value for a penalty, and records the
+// adjustment and its ELO history entry in one transaction, which also moves the player to
+// their new place on the leaderboard
+func (r *AdminRepository) adjustELO(ctx context.Context, userID int, sport, kind string, value int, reason string, adminID int) (*models.ELOAdjustment, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the user like match confirmations do, so neither overwrites the other's rating
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		return nil, err
+	}
+
+	// Get current ELO; players without a match in the sport start from its default
+	var oldELO, newELO int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(us.current_elo, s.default_elo),
+		       GREATEST(s.rating_floor, LEAST(s.rating_ceiling,
+		           CASE WHEN $4 = 'penalty' THEN COALESCE(us.current_elo, s.default_elo) - $3 ELSE $3 END))
+		FROM sports s
+		LEFT JOIN user_sports us ON us.sport_id = s.id AND us.user_id = $1
+		WHERE s.id = $2 AND s.is_active
+	`, userID, sport, value, kind).Scan(&oldELO, &newELO)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sport not found")
 	}
-	err := r.db.QueryRow(query, userID).Scan(&oldELO)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update ELO
-	if sport == models.SportTableTennis {
-		query = "UPDATE users SET table_tennis_elo = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2"
-	} else {
-		query = "UPDATE users SET table_football_elo = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2"
-	}
-	_, err = r.db.Exec(query, newELO, userID)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_sports (user_id, sport_id, current_elo, highest_elo)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id, sport_id) DO UPDATE SET
+			current_elo = $3,
+			highest_elo = GREATEST(user_sports.highest_elo, $3),
+			updated_at = CURRENT_TIMESTAMP
+	`, userID, sport, newELO)
 	if err != nil {
 		return nil, err
 	}
@@ -138,30 +429,56 @@ func (r *AdminRepository) AdjustELO(userID int, sport string, newELO int, reason
 	adjustment := &models.ELOAdjustment{
 		UserID:     userID,
 		Sport:      sport,
+		Kind:       kind,
 		OldELO:     oldELO,
 		NewELO:     newELO,
 		Reason:     reason,
 		AdjustedBy: adminID,
 	}
 
-	err = r.db.QueryRow(`
-		INSERT INTO elo_adjustments (user_id, sport, old_elo, new_elo, reason, adjusted_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO elo_adjustments (user_id, sport, kind, old_elo, new_elo, reason, adjusted_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at
-	`, userID, sport, oldELO, newELO, reason, adminID).Scan(&adjustment.ID, &adjustment.CreatedAt)
+	`, userID, sport, kind, oldELO, newELO, reason, adminID).Scan(&adjustment.ID, &adjustment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	source := models.ELOSourceAdjustment
+	if kind == models.ELOAdjustmentPenalty {
+		source = models.ELOSourcePenalty
+	}
+	err = recordELOChange(ctx, tx, &models.ELOHistoryEntry{
+		UserID:       userID,
+		Sport:        sport,
+		ELOBefore:    oldELO,
+		ELOAfter:     newELO,
+		Source:       source,
+		AdjustmentID: &adjustment.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rankPlayers(ctx, tx, []int{userID}, sport); err != nil {
+		return nil, err
+	}
 
-	return adjustment, err
+	return adjustment, tx.Commit()
 }
 
 // GetELOAdjustments returns all ELO adjustments
-func (r *AdminRepository) GetELOAdjustments(limit int) ([]models.ELOAdjustment, error) {
+func (r *AdminRepository) GetELOAdjustments(ctx context.Context, limit int) ([]models.ELOAdjustment, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, user_id, sport, old_elo, new_elo, reason, adjusted_by, created_at
+		SELECT id, user_id, sport, kind, old_elo, new_elo, reason, adjusted_by, created_at
 		FROM elo_adjustments
 		ORDER BY created_at DESC
 		LIMIT $1
 	`
-	rows, err := r.db.Query(query, limit)
+	rows, err := r.db.QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +487,7 @@ func (r *AdminRepository) GetELOAdjustments(limit int) ([]models.ELOAdjustment,
 	var adjustments []models.ELOAdjustment
 	for rows.Next() {
 		var adj models.ELOAdjustment
-		err := rows.Scan(&adj.ID, &adj.UserID, &adj.Sport, &adj.OldELO, &adj.NewELO, &adj.Reason, &adj.AdjustedBy, &adj.CreatedAt)
+		err := rows.Scan(&adj.ID, &adj.UserID, &adj.Sport, &adj.Kind, &adj.OldELO, &adj.NewELO, &adj.Reason, &adj.AdjustedBy, &adj.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -180,31 +497,82 @@ func (r *AdminRepository) GetELOAdjustments(limit int) ([]models.ELOAdjustment,
 	return adjustments, rows.Err()
 }
 
-// DeleteMatch permanently deletes a match
-func (r *AdminRepository) DeleteMatch(matchID int) error {
-	_, err := r.db.Exec("DELETE FROM matches WHERE id = $1", matchID)
-	return err
+// DeleteMatch soft-deletes a match, hot or archived; it stays restorable in archived_matches
+// Fails with "match not found" if the match does not exist or is already deleted
+func (r *AdminRepository) DeleteMatch(ctx context.Context, matchID, adminID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	for _, table := range []string{"matches", "matches_archive"} {
+		result, err := r.db.ExecContext(ctx, `
+			UPDATE `+table+` SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $1
+			WHERE id = $2 AND deleted_at IS NULL
+		`, adminID, matchID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n > 0 {
+			return err
+		}
+	}
+	return fmt.Errorf("match not found")
 }
 
-// UpdateMatchStatus updates a match status
-func (r *AdminRepository) UpdateMatchStatus(matchID int, status string) error {
-	query := `UPDATE matches SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
-	_, err := r.db.Exec(query, status, matchID)
-	return err
+// GetDeletedMatches returns soft-deleted matches, most recently deleted first
+func (r *AdminRepository) GetDeletedMatches(ctx context.Context, limit int) ([]models.DeletedMatch, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
+		       winner_id, status, player1_elo_before, player1_elo_after, player1_elo_delta,
+		       player2_elo_before, player2_elo_after, player2_elo_delta,
+		       submitted_by, confirmed_at, denied_at, created_at, updated_at, result, forfeited_by,
+		       deleted_at, deleted_by, reverted
+		FROM archived_matches
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []models.DeletedMatch{}
+	for rows.Next() {
+		var m models.DeletedMatch
+		err := rows.Scan(
+			&m.ID, &m.Sport, &m.Player1ID, &m.Player2ID, &m.Player1Score, &m.Player2Score,
+			&m.WinnerID, &m.Status, &m.Player1ELOBefore, &m.Player1ELOAfter, &m.Player1ELODelta,
+			&m.Player2ELOBefore, &m.Player2ELOAfter, &m.Player2ELODelta,
+			&m.SubmittedBy, &m.ConfirmedAt, &m.DeniedAt, &m.CreatedAt, &m.UpdatedAt, &m.Result, &m.ForfeitedBy,
+			&m.DeletedAt, &m.DeletedBy, &m.Reverted,
+		)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, rows.Err()
 }
 
 // GetDisputedMatches returns all disputed matches
-func (r *AdminRepository) GetDisputedMatches() ([]models.Match, error) {
+func (r *AdminRepository) GetDisputedMatches(ctx context.Context) ([]models.Match, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
 		       winner_id, status, player1_elo_before, player1_elo_after, player1_elo_delta,
 		       player2_elo_before, player2_elo_after, player2_elo_delta,
-		       submitted_by, confirmed_at, denied_at, created_at, updated_at
+		       submitted_by, confirmed_at, denied_at, created_at, updated_at, result, forfeited_by
 		FROM matches
-		WHERE status = 'disputed'
+		WHERE status = 'disputed' AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -217,7 +585,7 @@ func (r *AdminRepository) GetDisputedMatches() ([]models.Match, error) {
 			&m.ID, &m.Sport, &m.Player1ID, &m.Player2ID, &m.Player1Score, &m.Player2Score,
 			&m.WinnerID, &m.Status, &m.Player1ELOBefore, &m.Player1ELOAfter, &m.Player1ELODelta,
 			&m.Player2ELOBefore, &m.Player2ELOAfter, &m.Player2ELODelta,
-			&m.SubmittedBy, &m.ConfirmedAt, &m.DeniedAt, &m.CreatedAt, &m.UpdatedAt,
+			&m.SubmittedBy, &m.ConfirmedAt, &m.DeniedAt, &m.CreatedAt, &m.UpdatedAt, &m.Result, &m.ForfeitedBy,
 		)
 		if err != nil {
 			return nil, err
@@ -228,8 +596,169 @@ func (r *AdminRepository) GetDisputedMatches() ([]models.Match, error) {
 	return matches, rows.Err()
 }
 
+// GetFingerprintAnomalies returns confirmed matches where the confirming client fingerprint
+// matches a device the submitter used, suggesting one person controlled both sides
+// Newest confirmation first; offset skips that many anomalies for paging
+func (r *AdminRepository) GetFingerprintAnomalies(ctx context.Context, limit, offset int) ([]models.MatchAnomaly, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT m.id, m.sport, m.player1_id, m.player2_id, m.player1_score, m.player2_score,
+		       m.winner_id, m.status, m.player1_elo_before, m.player1_elo_after, m.player1_elo_delta,
+		       m.player2_elo_before, m.player2_elo_after, m.player2_elo_delta,
+		       m.submitted_by, m.confirmed_at, m.denied_at, m.created_at, m.updated_at, m.result, m.forfeited_by,
+		       m.submit_fingerprint, m.confirm_fingerprint,
+		       CASE WHEN m.submit_fingerprint = m.confirm_fingerprint
+		            THEN 'same_device' ELSE 'submitter_device_reused' END AS reason
+		FROM matches m
+		WHERE m.confirm_fingerprint IS NOT NULL
+		  AND m.deleted_at IS NULL
+		  AND (
+			m.submit_fingerprint = m.confirm_fingerprint
+			OR EXISTS (
+				SELECT 1 FROM matches o
+				WHERE o.submitted_by = m.submitted_by
+				  AND o.id != m.id
+				  AND o.deleted_at IS NULL
+				  AND o.submit_fingerprint = m.confirm_fingerprint
+			)
+		  )
+		ORDER BY m.confirmed_at DESC, m.id DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []models.MatchAnomaly
+	for rows.Next() {
+		var a models.MatchAnomaly
+		err := rows.Scan(
+			&a.ID, &a.Sport, &a.Player1ID, &a.Player2ID, &a.Player1Score, &a.Player2Score,
+			&a.WinnerID, &a.Status, &a.Player1ELOBefore, &a.Player1ELOAfter, &a.Player1ELODelta,
+			&a.Player2ELOBefore, &a.Player2ELOAfter, &a.Player2ELODelta,
+			&a.SubmittedBy, &a.ConfirmedAt, &a.DeniedAt, &a.CreatedAt, &a.UpdatedAt, &a.Result, &a.ForfeitedBy,
+			&a.SubmitFingerprint, &a.ConfirmFingerprint, &a.Reason,
+		)
+		if err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, a)
+	}
+
+	return anomalies, rows.Err()
+}
+
+// inconsistentWinnersQuery selects matches whose winner_id contradicts the scores
+// Tied scores are skipped since no winner can be derived from them, and so are forfeits
+const inconsistentWinnersQuery = `
+	SELECT id, sport, status, player1_id, player2_id, player1_score, player2_score, winner_id
+	FROM matches
+	WHERE player1_score <> player2_score
+	  AND result = 'played'
+	  AND deleted_at IS NULL
+	  AND winner_id <> CASE WHEN player1_score > player2_score THEN player1_id ELSE player2_id END
+	ORDER BY id
+`
+
+// GetInconsistentWinners returns matches whose winner_id does not match the scores
+func (r *AdminRepository) GetInconsistentWinners(ctx context.Context) ([]models.WinnerRepair, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, inconsistentWinnersQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWinnerRepairs(rows)
+}
+
+// RepairMatchWinners sets winner_id from the scores for all inconsistent matches
+// For confirmed matches the players' win/loss stats are recomputed as well; ELO is left
+// untouched since later matches were calculated from it (use AdjustELO if needed)
+func (r *AdminRepository) RepairMatchWinners(ctx context.Context) ([]models.WinnerRepair, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, inconsistentWinnersQuery+" FOR UPDATE")
+	if err != nil {
+		return nil, err
+	}
+	repairs, err := scanWinnerRepairs(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var affected []int
+	for i := range repairs {
+		repair := &repairs[i]
+
+		_, err := tx.ExecContext(ctx, `UPDATE matches SET winner_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, repair.NewWinnerID, repair.MatchID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to repair match %d: %w", repair.MatchID, err)
+		}
+
+		if repair.Status == models.StatusConfirmed {
+			affected = append(affected, repair.OldWinnerID, repair.NewWinnerID)
+			repair.StatsFixed = true
+		}
+	}
+
+	// Recompute wins, losses and streaks of players in repaired confirmed matches
+	if len(affected) > 0 {
+		if _, err := reconcileUserSportStats(ctx, tx, affected); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repairs, nil
+}
+
+// scanWinnerRepairs reads rows of inconsistentWinnersQuery
+func scanWinnerRepairs(rows *sql.Rows) ([]models.WinnerRepair, error) {
+	repairs := []models.WinnerRepair{}
+	for rows.Next() {
+		var repair models.WinnerRepair
+		var player1ID, player2ID, player1Score, player2Score int
+		err := rows.Scan(
+			&repair.MatchID, &repair.Sport, &repair.Status,
+			&player1ID, &player2ID, &player1Score, &player2Score, &repair.OldWinnerID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if player1Score > player2Score {
+			repair.NewWinnerID = player1ID
+		} else {
+			repair.NewWinnerID = player2ID
+		}
+		repairs = append(repairs, repair)
+	}
+
+	return repairs, rows.Err()
+}
+
 // LogAdminAction logs an admin action
-func (r *AdminRepository) LogAdminAction(adminID int, action string, targetType string, targetID *int, details interface{}) error {
+func (r *AdminRepository) LogAdminAction(ctx context.Context, adminID int, action string, targetType string, targetID *int, details interface{}) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var detailsJSON []byte
 	var err error
 	if details != nil {
@@ -243,19 +772,22 @@ func (r *AdminRepository) LogAdminAction(adminID int, action string, targetType
 		INSERT INTO admin_audit_log (admin_id, action, target_type, target_id, details)
 		VALUES ($1, $2, $3, $4, $5)
 	`
-	_, err = r.db.Exec(query, adminID, action, targetType, targetID, detailsJSON)
+	_, err = r.db.ExecContext(ctx, query, adminID, action, targetType, targetID, detailsJSON)
 	return err
 }
 
 // GetAuditLog returns admin audit log entries
-func (r *AdminRepository) GetAuditLog(limit int) ([]models.AdminAuditLog, error) {
+func (r *AdminRepository) GetAuditLog(ctx context.Context, limit int) ([]models.AdminAuditLog, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, admin_id, action, target_type, target_id, details, created_at
 		FROM admin_audit_log
 		ORDER BY created_at DESC
 		LIMIT $1
 	`
-	rows, err := r.db.Query(query, limit)
+	rows, err := r.db.QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -279,16 +811,20 @@ func (r *AdminRepository) GetAuditLog(limit int) ([]models.AdminAuditLog, error)
 }
 
 // GetBannedUsers returns all banned users
-func (r *AdminRepository) GetBannedUsers() ([]models.User, error) {
+func (r *AdminRepository) GetBannedUsers(ctx context.Context) ([]models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, id, login, display_name, avatar_url, campus,
-		       table_tennis_elo, table_football_elo, is_admin, is_banned,
-		       ban_reason, banned_at, banned_by, created_at, updated_at
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       ` + userSportsColumn + `
 		FROM users
 		WHERE is_banned = true
 		ORDER BY banned_at DESC
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -299,8 +835,9 @@ func (r *AdminRepository) GetBannedUsers() ([]models.User, error) {
 		var u models.User
 		err := rows.Scan(
 			&u.ID, &u.IntraID, &u.Login, &u.DisplayName, &u.AvatarURL, &u.Campus,
-			&u.TableTennisELO, &u.TableFootballELO, &u.IsAdmin, &u.IsBanned,
-			&u.BanReason, &u.BannedAt, &u.BannedBy, &u.CreatedAt, &u.UpdatedAt,
+			&u.IsAdmin, &u.IsBanned,
+			&u.BanReason, &u.BannedAt, &u.BannedBy, &u.BannedUntil, &u.DeactivatedAt, &u.CreatedAt, &u.UpdatedAt,
+			scanSports(&u.Sports),
 		)
 		if err != nil {
 			return nil, err
@@ -312,16 +849,20 @@ func (r *AdminRepository) GetBannedUsers() ([]models.User, error) {
 }
 
 // ExportMatchesCSV returns all matches for CSV export
-func (r *AdminRepository) ExportMatchesCSV() ([]models.Match, error) {
+func (r *AdminRepository) ExportMatchesCSV(ctx context.Context) ([]models.Match, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
 		       winner_id, status, player1_elo_before, player1_elo_after, player1_elo_delta,
 		       player2_elo_before, player2_elo_after, player2_elo_delta,
-		       submitted_by, confirmed_at, denied_at, created_at, updated_at
+		       submitted_by, confirmed_at, denied_at, created_at, updated_at, result, forfeited_by
 		FROM matches
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -334,7 +875,7 @@ func (r *AdminRepository) ExportMatchesCSV() ([]models.Match, error) {
 			&m.ID, &m.Sport, &m.Player1ID, &m.Player2ID, &m.Player1Score, &m.Player2Score,
 			&m.WinnerID, &m.Status, &m.Player1ELOBefore, &m.Player1ELOAfter, &m.Player1ELODelta,
 			&m.Player2ELOBefore, &m.Player2ELOAfter, &m.Player2ELODelta,
-			&m.SubmittedBy, &m.ConfirmedAt, &m.DeniedAt, &m.CreatedAt, &m.UpdatedAt,
+			&m.SubmittedBy, &m.ConfirmedAt, &m.DeniedAt, &m.CreatedAt, &m.UpdatedAt, &m.Result, &m.ForfeitedBy,
 		)
 		if err != nil {
 			return nil, err
@@ -346,15 +887,19 @@ func (r *AdminRepository) ExportMatchesCSV() ([]models.Match, error) {
 }
 
 // ExportUsersCSV returns all users for CSV export
-func (r *AdminRepository) ExportUsersCSV() ([]models.User, error) {
+func (r *AdminRepository) ExportUsersCSV(ctx context.Context) ([]models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, id, login, display_name, avatar_url, campus,
-		       table_tennis_elo, table_football_elo, is_admin, is_banned,
-		       ban_reason, banned_at, banned_by, created_at, updated_at
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       ` + userSportsColumn + `
 		FROM users
 		ORDER BY id
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -365,8 +910,9 @@ func (r *AdminRepository) ExportUsersCSV() ([]models.User, error) {
 		var u models.User
 		err := rows.Scan(
 			&u.ID, &u.IntraID, &u.Login, &u.DisplayName, &u.AvatarURL, &u.Campus,
-			&u.TableTennisELO, &u.TableFootballELO, &u.IsAdmin, &u.IsBanned,
-			&u.BanReason, &u.BannedAt, &u.BannedBy, &u.CreatedAt, &u.UpdatedAt,
+			&u.IsAdmin, &u.IsBanned,
+			&u.BanReason, &u.BannedAt, &u.BannedBy, &u.BannedUntil, &u.DeactivatedAt, &u.CreatedAt, &u.UpdatedAt,
+			scanSports(&u.Sports),
 		)
 		if err != nil {
 			return nil, err
@@ -378,18 +924,21 @@ func (r *AdminRepository) ExportUsersCSV() ([]models.User, error) {
 }
 
 // GetConfirmedMatches returns all confirmed matches (revertable)
-func (r *AdminRepository) GetConfirmedMatches(limit int) ([]models.Match, error) {
+func (r *AdminRepository) GetConfirmedMatches(ctx context.Context, limit int) ([]models.Match, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
 		       winner_id, status, player1_elo_before, player1_elo_after, player1_elo_delta,
 		       player2_elo_before, player2_elo_after, player2_elo_delta,
-		       submitted_by, confirmed_at, denied_at, created_at, updated_at
+		       submitted_by, confirmed_at, denied_at, created_at, updated_at, result, forfeited_by
 		FROM matches
-		WHERE status = 'confirmed'
+		WHERE status = 'confirmed' AND deleted_at IS NULL
 		ORDER BY confirmed_at DESC
 		LIMIT $1
 	`
-	rows, err := r.db.Query(query, limit)
+	rows, err := r.db.QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -402,7 +951,7 @@ func (r *AdminRepository) GetConfirmedMatches(limit int) ([]models.Match, error)
 			&m.ID, &m.Sport, &m.Player1ID, &m.Player2ID, &m.Player1Score, &m.Player2Score,
 			&m.WinnerID, &m.Status, &m.Player1ELOBefore, &m.Player1ELOAfter, &m.Player1ELODelta,
 			&m.Player2ELOBefore, &m.Player2ELOAfter, &m.Player2ELODelta,
-			&m.SubmittedBy, &m.ConfirmedAt, &m.DeniedAt, &m.CreatedAt, &m.UpdatedAt,
+			&m.SubmittedBy, &m.ConfirmedAt, &m.DeniedAt, &m.CreatedAt, &m.UpdatedAt, &m.Result, &m.ForfeitedBy,
 		)
 		if err != nil {
 			return nil, err
@@ -413,10 +962,24 @@ func (r *AdminRepository) GetConfirmedMatches(limit int) ([]models.Match, error)
 	return matches, rows.Err()
 }
 
-// RevertMatch reverts a confirmed match by restoring players' ELO ratings and deleting the match
-func (r *AdminRepository) RevertMatch(matchID int) error {
+// setClampedELOQuery sets a player's rating, clamped to the sport's rating bounds,
+// and returns the rating that was set
+const setClampedELOQuery = `
+	UPDATE user_sports us
+	SET current_elo = GREATEST(s.rating_floor, LEAST(s.rating_ceiling, $1)), updated_at = CURRENT_TIMESTAMP
+	FROM sports s
+	WHERE s.id = us.sport_id AND us.user_id = $2 AND us.sport_id = $3
+	RETURNING us.current_elo
+`
+
+// RevertMatch reverts a confirmed match by restoring players' ELO ratings and soft-deleting the match
+// Restored ratings are clamped to the sport's current rating bounds
+func (r *AdminRepository) RevertMatch(ctx context.Context, matchID, adminID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	// Start transaction
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -424,9 +987,9 @@ func (r *AdminRepository) RevertMatch(matchID int) error {
 
 	// Get the match details
 	var match models.Match
-	err = tx.QueryRow(`
+	err = tx.QueryRowContext(ctx, `
 		SELECT id, sport, player1_id, player2_id, player1_elo_before, player2_elo_before, status
-		FROM matches WHERE id = $1
+		FROM matches WHERE id = $1 AND deleted_at IS NULL
 	`, matchID).Scan(
 		&match.ID, &match.Sport, &match.Player1ID, &match.Player2ID,
 		&match.Player1ELOBefore, &match.Player2ELOBefore, &match.Status,
@@ -439,31 +1002,159 @@ func (r *AdminRepository) RevertMatch(matchID int) error {
 	if match.Status != "confirmed" {
 		return fmt.Errorf("can only revert confirmed matches")
 	}
+	if match.Player1ELOBefore == nil || match.Player2ELOBefore == nil {
+		return fmt.Errorf("match has no ELO data to restore")
+	}
 
-	// Restore player 1's ELO
-	var updateQuery string
-	if match.Sport == models.SportTableTennis {
-		updateQuery = "UPDATE users SET table_tennis_elo = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2"
-	} else {
-		updateQuery = "UPDATE users SET table_football_elo = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2"
+	// Current ratings, for the ELO history
+	selectQuery := "SELECT current_elo FROM user_sports WHERE user_id = $1 AND sport_id = $2 FOR UPDATE"
+	var player1CurrentELO, player2CurrentELO int
+	if err := tx.QueryRowContext(ctx, selectQuery, match.Player1ID, match.Sport).Scan(&player1CurrentELO); err != nil {
+		return err
+	}
+	if err := tx.QueryRowContext(ctx, selectQuery, match.Player2ID, match.Sport).Scan(&player2CurrentELO); err != nil {
+		return err
 	}
 
-	_, err = tx.Exec(updateQuery, match.Player1ELOBefore, match.Player1ID)
+	// Restore player 1's ELO, within the sport's current rating bounds
+	var player1RestoredELO, player2RestoredELO int
+	err = tx.QueryRowContext(ctx, setClampedELOQuery, *match.Player1ELOBefore, match.Player1ID, match.Sport).Scan(&player1RestoredELO)
 	if err != nil {
 		return err
 	}
 
 	// Restore player 2's ELO
-	_, err = tx.Exec(updateQuery, match.Player2ELOBefore, match.Player2ID)
+	err = tx.QueryRowContext(ctx, setClampedELOQuery, *match.Player2ELOBefore, match.Player2ID, match.Sport).Scan(&player2RestoredELO)
 	if err != nil {
 		return err
 	}
 
-	// Delete the match
-	_, err = tx.Exec("DELETE FROM matches WHERE id = $1", matchID)
+	// Log the restored ratings
+	for _, change := range []models.ELOHistoryEntry{
+		{UserID: match.Player1ID, ELOBefore: player1CurrentELO, ELOAfter: player1RestoredELO},
+		{UserID: match.Player2ID, ELOBefore: player2CurrentELO, ELOAfter: player2RestoredELO},
+	} {
+		change.Sport = match.Sport
+		change.Source = models.ELOSourceRevert
+		change.MatchID = &matchID
+		if err := recordELOChange(ctx, tx, &change); err != nil {
+			return err
+		}
+	}
+
+	// Soft-delete the match, marked as reverted so a restore reapplies the ratings
+	_, err = tx.ExecContext(ctx, `
+		UPDATE matches SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $1, reverted = TRUE
+		WHERE id = $2
+	`, adminID, matchID)
 	if err != nil {
 		return err
 	}
 
+	// Recompute wins, losses and streaks of both players without the reverted match
+	if _, err := reconcileUserSportStats(ctx, tx, []int{match.Player1ID, match.Player2ID}); err != nil {
+		return err
+	}
+	if _, err := rankPlayers(ctx, tx, []int{match.Player1ID, match.Player2ID}, match.Sport); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
+
+// RestoreMatch brings back a soft-deleted match, hot or archived
+// A reverted match gets its ELO changes reapplied on top of the players' current ratings,
+// within the sport's rating bounds
+// Returns whether the match had been reverted; fails with "match not found" if it is not deleted
+func (r *AdminRepository) RestoreMatch(ctx context.Context, matchID int) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var match models.Match
+	var reverted bool
+	table := ""
+	for _, candidate := range []string{"matches", "matches_archive"} {
+		err = tx.QueryRowContext(ctx, `
+			SELECT id, sport, player1_id, player2_id, player1_elo_delta, player2_elo_delta, status, reverted
+			FROM `+candidate+` WHERE id = $1 AND deleted_at IS NOT NULL
+			FOR UPDATE
+		`, matchID).Scan(
+			&match.ID, &match.Sport, &match.Player1ID, &match.Player2ID,
+			&match.Player1ELODelta, &match.Player2ELODelta, &match.Status, &reverted,
+		)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		table = candidate
+		break
+	}
+	if table == "" {
+		return false, fmt.Errorf("match not found")
+	}
+
+	if reverted {
+		if match.Player1ELODelta == nil || match.Player2ELODelta == nil {
+			return false, fmt.Errorf("match has no ELO data to reapply")
+		}
+
+		selectQuery := "SELECT current_elo FROM user_sports WHERE user_id = $1 AND sport_id = $2 FOR UPDATE"
+		for _, player := range []struct {
+			userID int
+			delta  int
+		}{
+			{match.Player1ID, *match.Player1ELODelta},
+			{match.Player2ID, *match.Player2ELODelta},
+		} {
+			var currentELO, restoredELO int
+			if err := tx.QueryRowContext(ctx, selectQuery, player.userID, match.Sport).Scan(&currentELO); err != nil {
+				return false, err
+			}
+			if err := tx.QueryRowContext(ctx, setClampedELOQuery, currentELO+player.delta, player.userID, match.Sport).Scan(&restoredELO); err != nil {
+				return false, err
+			}
+
+			change := models.ELOHistoryEntry{
+				UserID:    player.userID,
+				Sport:     match.Sport,
+				ELOBefore: currentELO,
+				ELOAfter:  restoredELO,
+				Source:    models.ELOSourceRestore,
+				MatchID:   &matchID,
+			}
+			if err := recordELOChange(ctx, tx, &change); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE `+table+` SET deleted_at = NULL, deleted_by = NULL, reverted = FALSE, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, matchID)
+	if err != nil {
+		return false, err
+	}
+
+	// The restored match counts towards wins, losses and streaks again
+	if match.Status == models.StatusConfirmed {
+		if _, err := reconcileUserSportStats(ctx, tx, []int{match.Player1ID, match.Player2ID}); err != nil {
+			return false, err
+		}
+	}
+	if reverted || match.Status == models.StatusConfirmed {
+		if _, err := rankPlayers(ctx, tx, []int{match.Player1ID, match.Player2ID}, match.Sport); err != nil {
+			return false, err
+		}
+	}
+
+	return reverted, tx.Commit()
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/lib/pq"
+)
+
+// AnonymizationRepository handles the anonymization vocabulary and persisted anonymous names
+type AnonymizationRepository struct {
+	db *sql.DB
+}
+
+// NewAnonymizationRepository creates a new AnonymizationRepository instance
+func NewAnonymizationRepository(db *sql.DB) *AnonymizationRepository {
+	return &AnonymizationRepository{db: db}
+}
+
+// ListWords returns all vocabulary words, ordered by campus, kind and word
+func (r *AnonymizationRepository) ListWords(ctx context.Context) ([]models.AnonymizationWord, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, campus, kind, word, created_at
+		FROM anonymization_words
+		ORDER BY campus, kind, word
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list anonymization words: %w", err)
+	}
+	defer rows.Close()
+
+	words := []models.AnonymizationWord{}
+	for rows.Next() {
+		var w models.AnonymizationWord
+		if err := rows.Scan(&w.ID, &w.Campus, &w.Kind, &w.Word, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan anonymization word: %w", err)
+		}
+		words = append(words, w)
+	}
+
+	return words, rows.Err()
+}
+
+// AddWord adds a word to the vocabulary of a campus (empty campus for the global default)
+func (r *AnonymizationRepository) AddWord(ctx context.Context, campus, kind, word string) (*models.AnonymizationWord, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO anonymization_words (campus, kind, word)
+		VALUES ($1, $2, $3)
+		RETURNING id, campus, kind, word, created_at
+	`
+
+	var w models.AnonymizationWord
+	err := r.db.QueryRowContext(ctx, query, campus, kind, word).Scan(&w.ID, &w.Campus, &w.Kind, &w.Word, &w.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("word already exists")
+		}
+		return nil, fmt.Errorf("failed to add anonymization word: %w", err)
+	}
+
+	return &w, nil
+}
+
+// DeleteWord removes a word from the vocabulary
+// Already assigned anonymous names are kept so aliases stay stable
+func (r *AnonymizationRepository) DeleteWord(ctx context.Context, id int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM anonymization_words WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete anonymization word: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("word not found")
+	}
+
+	return nil
+}
+
+// GetNames returns the persisted anonymous names for the given users
+func (r *AnonymizationRepository) GetNames(ctx context.Context, userIDs []int) (map[int]string, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	names := make(map[int]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return names, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT user_id, name FROM anonymous_names WHERE user_id = ANY($1)`, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anonymous names: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int
+		var name string
+		if err := rows.Scan(&userID, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan anonymous name: %w", err)
+		}
+		names[userID] = name
+	}
+
+	return names, rows.Err()
+}
+
+// ListUnnamedUsers returns up to limit users without an anonymous name, oldest accounts first
+func (r *AnonymizationRepository) ListUnnamedUsers(ctx context.Context, limit int) ([]models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id, u.login, u.campus
+		FROM users u
+		LEFT JOIN anonymous_names n ON n.user_id = u.id
+		WHERE n.user_id IS NULL
+		ORDER BY u.id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users without anonymous name: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Login, &u.Campus); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+// ClaimName tries to assign a name to a user
+// Returns the user's name after the call and whether the requested name was taken by someone else
+func (r *AnonymizationRepository) ClaimName(ctx context.Context, userID int, name string) (string, bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO anonymous_names (user_id, name)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, name)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to claim anonymous name: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return "", false, err
+	}
+	if rows == 1 {
+		return name, false, nil
+	}
+
+	// Either the name is taken or a concurrent request already assigned this user a name
+	var existing string
+	err = r.db.QueryRowContext(ctx, `SELECT name FROM anonymous_names WHERE user_id = $1`, userID).Scan(&existing)
+	if err == sql.ErrNoRows {
+		return "", true, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get anonymous name: %w", err)
+	}
+
+	return existing, false, nil
+}
@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// ChallengeRepository handles scheduled matches between two players
+type ChallengeRepository struct {
+	db *sql.DB
+}
+
+// NewChallengeRepository creates a new ChallengeRepository instance
+func NewChallengeRepository(db *sql.DB) *ChallengeRepository {
+	return &ChallengeRepository{db: db}
+}
+
+// Challenge list directions, seen from the requesting user
+const (
+	ChallengesIncoming = "incoming"
+	ChallengesOutgoing = "outgoing"
+)
+
+// maxChallengeList caps the challenges returned by ListForUser
+const maxChallengeList = 100
+
+const challengeColumns = `id, sport, challenger_id, opponent_id, scheduled_at, message, status, match_id, created_at, responded_at, updated_at`
+
+func scanChallenge(row interface{ Scan(...interface{}) error }) (*models.Challenge, error) {
+	challenge := &models.Challenge{}
+	err := row.Scan(
+		&challenge.ID,
+		&challenge.Sport,
+		&challenge.ChallengerID,
+		&challenge.OpponentID,
+		&challenge.ScheduledAt,
+		&challenge.Message,
+		&challenge.Status,
+		&challenge.MatchID,
+		&challenge.CreatedAt,
+		&challenge.RespondedAt,
+		&challenge.UpdatedAt,
+	)
+	return challenge, err
+}
+
+// Create stores a new pending challenge
+// Fails with "challenge already exists" if the same slot is already challenged and open
+func (r *ChallengeRepository) Create(ctx context.Context, challenge *models.Challenge) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO challenges (sport, challenger_id, opponent_id, scheduled_at, message)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (challenger_id, opponent_id, sport, scheduled_at) WHERE status IN ('pending', 'accepted') DO NOTHING
+		RETURNING ` + challengeColumns
+
+	created, err := scanChallenge(r.db.QueryRowContext(ctx, query,
+		challenge.Sport, challenge.ChallengerID, challenge.OpponentID, challenge.ScheduledAt, challenge.Message,
+	))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("challenge already exists")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create challenge: %w", err)
+	}
+	*challenge = *created
+	return nil
+}
+
+// GetByID retrieves a challenge by ID
+func (r *ChallengeRepository) GetByID(ctx context.Context, id int) (*models.Challenge, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	challenge, err := scanChallenge(r.db.QueryRowContext(ctx, `SELECT `+challengeColumns+` FROM challenges WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("challenge not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// GetByIDForUpdate retrieves a challenge with a row lock, for use within a transaction
+func (r *ChallengeRepository) GetByIDForUpdate(ctx context.Context, tx *sql.Tx, id int) (*models.Challenge, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	challenge, err := scanChallenge(tx.QueryRowContext(ctx, `SELECT `+challengeColumns+` FROM challenges WHERE id = $1 FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("challenge not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// ListForUser returns the challenges a user sent or received, most recent slot first
+// direction is ChallengesIncoming, ChallengesOutgoing or empty for both; status is optional
+func (r *ChallengeRepository) ListForUser(ctx context.Context, userID int, direction string, status *string) ([]models.Challenge, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var participant string
+	switch direction {
+	case ChallengesIncoming:
+		participant = `opponent_id = $1`
+	case ChallengesOutgoing:
+		participant = `challenger_id = $1`
+	default:
+		participant = `(challenger_id = $1 OR opponent_id = $1)`
+	}
+
+	query := `
+		SELECT ` + challengeColumns + `
+		FROM challenges
+		WHERE ` + participant + ` AND ($2::text IS NULL OR status = $2)
+		ORDER BY scheduled_at DESC, id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, status, maxChallengeList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list challenges: %w", err)
+	}
+	defer rows.Close()
+
+	challenges := []models.Challenge{}
+	for rows.Next() {
+		challenge, err := scanChallenge(rows)
+		if err != nil {
+			return nil, err
+		}
+		challenges = append(challenges, *challenge)
+	}
+	return challenges, rows.Err()
+}
+
+// Respond accepts or declines a pending challenge on behalf of its opponent
+// Returns "challenge is not pending" if it was answered or cancelled meanwhile
+func (r *ChallengeRepository) Respond(ctx context.Context, id, opponentID int, status string) (*models.Challenge, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE challenges SET status = $1, responded_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND opponent_id = $3 AND status = 'pending'
+		RETURNING ` + challengeColumns
+
+	challenge, err := scanChallenge(r.db.QueryRowContext(ctx, query, status, id, opponentID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("challenge is not pending")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// Cancel withdraws an open challenge on behalf of its challenger
+// Returns "challenge is not open" if it was declined or completed meanwhile
+func (r *ChallengeRepository) Cancel(ctx context.Context, id, challengerID int) (*models.Challenge, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE challenges SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND challenger_id = $2 AND status IN ('pending', 'accepted')
+		RETURNING ` + challengeColumns
+
+	challenge, err := scanChallenge(r.db.QueryRowContext(ctx, query, id, challengerID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("challenge is not open")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// Complete links the match created from the result of an accepted challenge
+func (r *ChallengeRepository) Complete(ctx context.Context, tx *sql.Tx, id, matchID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `
+		UPDATE challenges SET status = 'completed', match_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = 'accepted'
+	`, matchID, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete challenge: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/42heilbronn/elo-leaderboard/internal/models"
@@ -15,27 +16,66 @@ func NewCommentRepository(db *sql.DB) *CommentRepository {
 }
 
 // Add creates a new comment
-func (r *CommentRepository) Add(comment *models.Comment) error {
+// Comments of shadow-muted authors are stored with Shadowed set
+func (r *CommentRepository) Add(ctx context.Context, comment *models.Comment) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
-		INSERT INTO comments (match_id, user_id, content)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at, updated_at
+		INSERT INTO comments (match_id, user_id, parent_comment_id, content, shadowed)
+		VALUES ($1, $2, $3, $4, COALESCE((SELECT comments_muted_at IS NOT NULL FROM users WHERE id = $2), false))
+		RETURNING id, created_at, updated_at, shadowed
 	`
 
-	return r.db.QueryRow(query, comment.MatchID, comment.UserID, comment.Content).
-		Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt)
+	return r.db.QueryRowContext(ctx, query, comment.MatchID, comment.UserID, comment.ParentCommentID, comment.Content).
+		Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt, &comment.Shadowed)
+}
+
+// GetByID retrieves a comment, or nil if it does not exist
+func (r *CommentRepository) GetByID(ctx context.Context, commentID int) (*models.Comment, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, match_id, user_id, parent_comment_id, content, created_at, updated_at
+		FROM comments
+		WHERE id = $1
+	`
+
+	comment := &models.Comment{}
+	err := r.db.QueryRowContext(ctx, query, commentID).Scan(
+		&comment.ID,
+		&comment.MatchID,
+		&comment.UserID,
+		&comment.ParentCommentID,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
 }
 
-// GetByMatchID retrieves all comments for a match
-func (r *CommentRepository) GetByMatchID(matchID int) ([]models.Comment, error) {
+// GetByMatchID retrieves all comments for a match visible to viewerID
+// Replies are returned alongside top-level comments; clients thread them by parent_comment_id
+// Shadowed comments are only returned to their author
+func (r *CommentRepository) GetByMatchID(ctx context.Context, matchID, viewerID int) ([]models.Comment, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, match_id, user_id, content, created_at, updated_at
+		SELECT id, match_id, user_id, parent_comment_id, content, created_at, updated_at
 		FROM comments
-		WHERE match_id = $1
+		WHERE match_id = $1 AND (NOT shadowed OR user_id = $2)
 		ORDER BY created_at ASC
 	`
 
-	rows, err := r.db.Query(query, matchID)
+	rows, err := r.db.QueryContext(ctx, query, matchID, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +88,7 @@ func (r *CommentRepository) GetByMatchID(matchID int) ([]models.Comment, error)
 			&comment.ID,
 			&comment.MatchID,
 			&comment.UserID,
+			&comment.ParentCommentID,
 			&comment.Content,
 			&comment.CreatedAt,
 			&comment.UpdatedAt,
@@ -65,25 +106,28 @@ func (r *CommentRepository) GetByMatchID(matchID int) ([]models.Comment, error)
 	return comments, rows.Err()
 }
 
-// GetByMatchIDPaginated retrieves comments for a match with pagination
-func (r *CommentRepository) GetByMatchIDPaginated(matchID, limit, offset int) ([]models.Comment, int, error) {
+// GetByMatchIDPaginated retrieves comments for a match visible to viewerID with pagination
+func (r *CommentRepository) GetByMatchIDPaginated(ctx context.Context, matchID, viewerID, limit, offset int) ([]models.Comment, int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	// Get total count first
-	countQuery := `SELECT COUNT(*) FROM comments WHERE match_id = $1`
+	countQuery := `SELECT COUNT(*) FROM comments WHERE match_id = $1 AND (NOT shadowed OR user_id = $2)`
 	var total int
-	if err := r.db.QueryRow(countQuery, matchID).Scan(&total); err != nil {
+	if err := r.db.QueryRowContext(ctx, countQuery, matchID, viewerID).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated comments
 	query := `
-		SELECT id, match_id, user_id, content, created_at, updated_at
+		SELECT id, match_id, user_id, parent_comment_id, content, created_at, updated_at
 		FROM comments
-		WHERE match_id = $1
+		WHERE match_id = $1 AND (NOT shadowed OR user_id = $2)
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := r.db.Query(query, matchID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, matchID, viewerID, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -96,6 +140,7 @@ func (r *CommentRepository) GetByMatchIDPaginated(matchID, limit, offset int) ([
 			&comment.ID,
 			&comment.MatchID,
 			&comment.UserID,
+			&comment.ParentCommentID,
 			&comment.Content,
 			&comment.CreatedAt,
 			&comment.UpdatedAt,
@@ -113,10 +158,59 @@ func (r *CommentRepository) GetByMatchIDPaginated(matchID, limit, offset int) ([
 	return comments, total, rows.Err()
 }
 
+// DeleteByID removes a comment regardless of its author, for moderation
+// Replies to it are removed with it
+func (r *CommentRepository) DeleteByID(ctx context.Context, commentID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM comments WHERE id = $1`, commentID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteByUser removes all comments of a user (and the replies to them), for moderation
+// Returns the deleted comments with their match IDs
+func (r *CommentRepository) DeleteByUser(ctx context.Context, userID int) ([]models.Comment, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `DELETE FROM comments WHERE user_id = $1 RETURNING id, match_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deleted := []models.Comment{}
+	for rows.Next() {
+		comment := models.Comment{UserID: userID}
+		if err := rows.Scan(&comment.ID, &comment.MatchID); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, comment)
+	}
+	return deleted, rows.Err()
+}
+
 // Delete removes a comment
-func (r *CommentRepository) Delete(commentID, userID int) error {
+func (r *CommentRepository) Delete(ctx context.Context, commentID, userID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `DELETE FROM comments WHERE id = $1 AND user_id = $2`
-	result, err := r.db.Exec(query, commentID, userID)
+	result, err := r.db.ExecContext(ctx, query, commentID, userID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// DataExportRepository handles the queue of asynchronous GDPR data exports
+type DataExportRepository struct {
+	db *sql.DB
+}
+
+// NewDataExportRepository creates a new DataExportRepository instance
+func NewDataExportRepository(db *sql.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+const dataExportColumns = `id, user_id, status, size_bytes, error, created_at, started_at, completed_at, expires_at`
+
+func scanDataExport(row interface{ Scan(...interface{}) error }) (*models.DataExport, error) {
+	export := &models.DataExport{}
+	err := row.Scan(
+		&export.ID,
+		&export.UserID,
+		&export.Status,
+		&export.SizeBytes,
+		&export.Error,
+		&export.CreatedAt,
+		&export.StartedAt,
+		&export.CompletedAt,
+		&export.ExpiresAt,
+	)
+	return export, err
+}
+
+// Create queues an export for a user
+// Fails with "data export already in progress" if one is pending or being built
+func (r *DataExportRepository) Create(ctx context.Context, userID int) (*models.DataExport, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO data_exports (user_id, status)
+		VALUES ($1, 'pending')
+		ON CONFLICT (user_id) WHERE status IN ('pending', 'processing') DO NOTHING
+		RETURNING ` + dataExportColumns
+
+	export, err := scanDataExport(r.db.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("data export already in progress")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+	return export, nil
+}
+
+// GetLatestForUser returns the most recent export of a user
+func (r *DataExportRepository) GetLatestForUser(ctx context.Context, userID int) (*models.DataExport, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT 1`
+
+	export, err := scanDataExport(r.db.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("data export not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch data export: %w", err)
+	}
+	return export, nil
+}
+
+// ClaimNext marks the oldest pending export as processing and returns it, or nil if the queue is empty
+// Exports stuck in processing since before staleBefore (crashed worker) are claimed again
+// SKIP LOCKED lets several instances work the queue without building an export twice
+func (r *DataExportRepository) ClaimNext(ctx context.Context, staleBefore time.Time) (*models.DataExport, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE data_exports SET status = 'processing', started_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM data_exports
+			WHERE status = 'pending' OR (status = 'processing' AND started_at < $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + dataExportColumns
+
+	export, err := scanDataExport(r.db.QueryRowContext(ctx, query, staleBefore))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim data export: %w", err)
+	}
+	return export, nil
+}
+
+// Complete stores the archive of an export and makes it downloadable until expiresAt
+func (r *DataExportRepository) Complete(ctx context.Context, export *models.DataExport, archive []byte, expiresAt time.Time) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE data_exports
+		SET status = 'ready', archive = $1, size_bytes = $2, error = NULL,
+		    completed_at = CURRENT_TIMESTAMP, expires_at = $3
+		WHERE id = $4
+		RETURNING ` + dataExportColumns
+
+	updated, err := scanDataExport(r.db.QueryRowContext(ctx, query, archive, len(archive), expiresAt, export.ID))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("data export not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to complete data export: %w", err)
+	}
+	*export = *updated
+	return nil
+}
+
+// Fail records why an export could not be built; the record is kept until expiresAt
+// The user may request a new export right away
+func (r *DataExportRepository) Fail(ctx context.Context, id int, reason string, expiresAt time.Time) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE data_exports SET status = 'failed', error = $1, completed_at = CURRENT_TIMESTAMP, expires_at = $2
+		WHERE id = $3
+	`, reason, expiresAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark data export as failed: %w", err)
+	}
+	return nil
+}
+
+// GetArchive returns the archive of a ready export that has not expired yet
+func (r *DataExportRepository) GetArchive(ctx context.Context, id int) ([]byte, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var archive []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT archive FROM data_exports
+		WHERE id = $1 AND status = 'ready' AND archive IS NOT NULL AND expires_at > CURRENT_TIMESTAMP
+	`, id).Scan(&archive)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("data export not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch data export archive: %w", err)
+	}
+	return archive, nil
+}
+
+// DeleteExpired removes ready and failed exports that expired before the given time
+func (r *DataExportRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM data_exports WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired data exports: %w", err)
+	}
+	return result.RowsAffected()
+}
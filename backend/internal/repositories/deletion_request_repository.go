@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// DeletionRequestRepository handles account deletions waiting for their grace period
+type DeletionRequestRepository struct {
+	db *sql.DB
+}
+
+// NewDeletionRequestRepository creates a new DeletionRequestRepository instance
+func NewDeletionRequestRepository(db *sql.DB) *DeletionRequestRepository {
+	return &DeletionRequestRepository{db: db}
+}
+
+// Create schedules the erasure of an account
+// An existing request is kept so repeating the request does not extend the grace period
+// Returns the request in effect and whether it was created
+func (r *DeletionRequestRepository) Create(ctx context.Context, userID int, eraseAfter time.Time) (*models.DeletionRequest, bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	req := &models.DeletionRequest{}
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO deletion_requests (user_id, erase_after)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO NOTHING
+		RETURNING user_id, requested_at, erase_after
+	`, userID, eraseAfter).Scan(&req.UserID, &req.RequestedAt, &req.EraseAfter)
+	if err == sql.ErrNoRows {
+		existing, err := r.GetForUser(ctx, userID)
+		return existing, false, err
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+	return req, true, nil
+}
+
+// GetForUser returns the pending deletion of an account, or nil if none is scheduled
+func (r *DeletionRequestRepository) GetForUser(ctx context.Context, userID int) (*models.DeletionRequest, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	req := &models.DeletionRequest{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, requested_at, erase_after FROM deletion_requests WHERE user_id = $1
+	`, userID).Scan(&req.UserID, &req.RequestedAt, &req.EraseAfter)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deletion request: %w", err)
+	}
+	return req, nil
+}
+
+// Delete cancels the pending deletion of an account
+// Returns false if none was scheduled
+func (r *DeletionRequestRepository) Delete(ctx context.Context, userID int) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM deletion_requests WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ListDue returns the accounts whose grace period ended before now, oldest first
+func (r *DeletionRequestRepository) ListDue(ctx context.Context, now time.Time) ([]int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id FROM deletion_requests WHERE erase_after <= $1 ORDER BY erase_after
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due deletions: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// Claim removes a due deletion request inside the erasing transaction
+// Returns false if it was cancelled or claimed by another instance meanwhile
+func (r *DeletionRequestRepository) Claim(ctx context.Context, tx *sql.Tx, userID int, now time.Time) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM deletion_requests WHERE user_id = $1 AND erase_after <= $2`, userID, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim deletion request: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// DeliveryRepository handles the notification delivery log
+type DeliveryRepository struct {
+	db *sql.DB
+}
+
+// NewDeliveryRepository creates a new DeliveryRepository instance
+func NewDeliveryRepository(db *sql.DB) *DeliveryRepository {
+	return &DeliveryRepository{db: db}
+}
+
+// Record stores a delivery attempt
+// Attempt defaults to 1 for channels that don't retry
+func (r *DeliveryRepository) Record(ctx context.Context, delivery *models.NotificationDelivery) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	attempt := delivery.Attempt
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	query := `
+		INSERT INTO notification_deliveries (channel, event, status, status_code, error, response_body, duration_ms, attempt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx,
+		query,
+		delivery.Channel,
+		delivery.Event,
+		delivery.Status,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.ResponseBody,
+		delivery.DurationMS,
+		attempt,
+	).Scan(&delivery.ID, &delivery.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record delivery: %w", err)
+	}
+	return nil
+}
+
+// ListRecent returns the most recent delivery attempts, optionally filtered by channel and status
+func (r *DeliveryRepository) ListRecent(ctx context.Context, channel, status *string, limit int) ([]models.NotificationDelivery, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, channel, event, status, status_code, error, response_body, duration_ms, attempt, created_at
+		FROM notification_deliveries
+		WHERE 1=1
+	`
+
+	args := []interface{}{}
+	argCount := 1
+
+	if channel != nil {
+		query += fmt.Sprintf(" AND channel = $%d", argCount)
+		args = append(args, *channel)
+		argCount++
+	}
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, *status)
+		argCount++
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", argCount)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.NotificationDelivery{}
+	for rows.Next() {
+		var d models.NotificationDelivery
+		if err := rows.Scan(
+			&d.ID, &d.Channel, &d.Event, &d.Status, &d.StatusCode,
+			&d.Error, &d.ResponseBody, &d.DurationMS, &d.Attempt, &d.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// HasDelivered reports whether an event was successfully delivered to a channel since the given time
+func (r *DeliveryRepository) HasDelivered(ctx context.Context, channel, event string, since time.Time) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var exists bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM notification_deliveries
+			WHERE channel = $1 AND event = $2 AND status = $3 AND created_at >= $4
+		)
+	`
+	if err := r.db.QueryRowContext(ctx, query, channel, event, models.DeliveryDelivered, since).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// PurgeBefore deletes delivery log entries older than the cutoff
+func (r *DeliveryRepository) PurgeBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM notification_deliveries WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// DigestRepository stores daily and weekly digests and computes their figures
+type DigestRepository struct {
+	db *sql.DB
+}
+
+// NewDigestRepository creates a new DigestRepository instance
+func NewDigestRepository(db *sql.DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+// Exists reports whether the digest of a sport and period starting at start was generated
+func (r *DigestRepository) Exists(ctx context.Context, sport, period string, start time.Time) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM digests WHERE sport = $1 AND period = $2 AND period_start = $3)`
+	if err := r.db.QueryRowContext(ctx, query, sport, period, start).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check digest: %w", err)
+	}
+	return exists, nil
+}
+
+// Save stores a digest; returns false if one for the same sport and period already exists
+func (r *DigestRepository) Save(ctx context.Context, digest *models.Digest) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	upsets, err := json.Marshal(digest.Upsets)
+	if err != nil {
+		return false, err
+	}
+	mostActive, err := json.Marshal(digest.MostActive)
+	if err != nil {
+		return false, err
+	}
+	rankMovers, err := json.Marshal(digest.RankMovers)
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO digests (sport, period, period_start, period_end, matches, upsets, most_active, rank_movers)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (sport, period, period_start) DO NOTHING
+		RETURNING id, created_at
+	`
+	err = r.db.QueryRowContext(ctx, query,
+		digest.Sport, digest.Period, digest.PeriodStart, digest.PeriodEnd, digest.Matches,
+		upsets, mostActive, rankMovers,
+	).Scan(&digest.ID, &digest.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to save digest: %w", err)
+	}
+	return true, nil
+}
+
+// GetLatest returns the most recent digest of a sport and period, or nil if there is none
+func (r *DigestRepository) GetLatest(ctx context.Context, sport, period string) (*models.Digest, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, sport, period, period_start, period_end, matches, upsets, most_active, rank_movers, created_at
+		FROM digests
+		WHERE sport = $1 AND period = $2
+		ORDER BY period_start DESC
+		LIMIT 1
+	`
+
+	digest := &models.Digest{}
+	var upsets, mostActive, rankMovers []byte
+	err := r.db.QueryRowContext(ctx, query, sport, period).Scan(
+		&digest.ID,
+		&digest.Sport,
+		&digest.Period,
+		&digest.PeriodStart,
+		&digest.PeriodEnd,
+		&digest.Matches,
+		&upsets,
+		&mostActive,
+		&rankMovers,
+		&digest.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch digest: %w", err)
+	}
+
+	if err := json.Unmarshal(upsets, &digest.Upsets); err != nil {
+		return nil, fmt.Errorf("failed to decode digest upsets: %w", err)
+	}
+	if err := json.Unmarshal(mostActive, &digest.MostActive); err != nil {
+		return nil, fmt.Errorf("failed to decode digest activity: %w", err)
+	}
+	if err := json.Unmarshal(rankMovers, &digest.RankMovers); err != nil {
+		return nil, fmt.Errorf("failed to decode digest rank movers: %w", err)
+	}
+	return digest, nil
+}
+
+// CountMatches returns the number of matches of a sport confirmed in [from, to)
+func (r *DigestRepository) CountMatches(ctx context.Context, sport string, from, to time.Time) (int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var count int
+	query := `SELECT COUNT(*) FROM matches WHERE sport = $1 AND status = 'confirmed' AND deleted_at IS NULL AND confirmed_at >= $2 AND confirmed_at < $3`
+	if err := r.db.QueryRowContext(ctx, query, sport, from, to).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count matches: %w", err)
+	}
+	return count, nil
+}
+
+// GetUpsets returns the played matches confirmed in [from, to) that the lower-rated
+// player won, largest rating gap first
+func (r *DigestRepository) GetUpsets(ctx context.Context, sport string, from, to time.Time, limit int) ([]models.DigestUpset, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, winner_id, loser_id, winner_score, loser_score, winner_elo_before, loser_elo_before, elo_gained
+		FROM (
+			SELECT id, winner_id,
+			       CASE WHEN winner_id = player1_id THEN player2_id ELSE player1_id END AS loser_id,
+			       CASE WHEN winner_id = player1_id THEN player1_score ELSE player2_score END AS winner_score,
+			       CASE WHEN winner_id = player1_id THEN player2_score ELSE player1_score END AS loser_score,
+			       CASE WHEN winner_id = player1_id THEN player1_elo_before ELSE player2_elo_before END AS winner_elo_before,
+			       CASE WHEN winner_id = player1_id THEN player2_elo_before ELSE player1_elo_before END AS loser_elo_before,
+			       CASE WHEN winner_id = player1_id THEN player1_elo_delta ELSE player2_elo_delta END AS elo_gained
+			FROM matches
+			WHERE sport = $1 AND status = 'confirmed' AND result = 'played' AND deleted_at IS NULL
+			  AND confirmed_at >= $2 AND confirmed_at < $3
+		) m
+		WHERE loser_elo_before > winner_elo_before
+		ORDER BY loser_elo_before - winner_elo_before DESC, id
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sport, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upsets: %w", err)
+	}
+	defer rows.Close()
+
+	upsets := []models.DigestUpset{}
+	for rows.Next() {
+		var u models.DigestUpset
+		var gained sql.NullInt64
+		if err := rows.Scan(&u.MatchID, &u.WinnerID, &u.LoserID, &u.WinnerScore, &u.LoserScore,
+			&u.WinnerELOBefore, &u.LoserELOBefore, &gained); err != nil {
+			return nil, err
+		}
+		u.ELOGained = int(gained.Int64)
+		upsets = append(upsets, u)
+	}
+	return upsets, rows.Err()
+}
+
+// GetMostActive returns the players with the most matches confirmed in [from, to)
+func (r *DigestRepository) GetMostActive(ctx context.Context, sport string, from, to time.Time, limit int) ([]models.DigestActivity, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT p.user_id, COUNT(*) AS matches, COUNT(*) FILTER (WHERE m.winner_id = p.user_id) AS wins
+		FROM matches m
+		CROSS JOIN LATERAL (VALUES (m.player1_id), (m.player2_id)) AS p(user_id)
+		WHERE m.sport = $1 AND m.status = 'confirmed' AND m.deleted_at IS NULL
+		  AND m.confirmed_at >= $2 AND m.confirmed_at < $3
+		  AND p.user_id > 0
+		GROUP BY p.user_id
+		ORDER BY matches DESC, wins DESC, p.user_id
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sport, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get most active players: %w", err)
+	}
+	defer rows.Close()
+
+	active := []models.DigestActivity{}
+	for rows.Next() {
+		var a models.DigestActivity
+		if err := rows.Scan(&a.UserID, &a.Matches, &a.Wins); err != nil {
+			return nil, err
+		}
+		active = append(active, a)
+	}
+	return active, rows.Err()
+}
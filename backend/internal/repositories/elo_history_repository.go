@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// maxELOHistoryEntries caps a single history response
+const maxELOHistoryEntries = 5000
+
+// ELOHistoryRepository handles the per-player ELO change log
+type ELOHistoryRepository struct {
+	db *sql.DB
+}
+
+// NewELOHistoryRepository creates a new ELOHistoryRepository instance
+func NewELOHistoryRepository(db *sql.DB) *ELOHistoryRepository {
+	return &ELOHistoryRepository{db: db}
+}
+
+// Record appends an ELO change to the history
+func (r *ELOHistoryRepository) Record(ctx context.Context, tx *sql.Tx, entry *models.ELOHistoryEntry) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	if tx != nil {
+		return recordELOChange(ctx, tx, entry)
+	}
+	return recordELOChange(ctx, r.db, entry)
+}
+
+// GetHistory returns a player's ELO changes in chronological order
+// sport, from and to are optional filters
+func (r *ELOHistoryRepository) GetHistory(ctx context.Context, userID int, sport *string, from, to *time.Time) ([]models.ELOHistoryEntry, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, sport_id, elo_before, elo_after, delta, source, match_id, adjustment_id, created_at
+		FROM elo_history
+		WHERE user_id = $1
+	`
+
+	args := []interface{}{userID}
+	argCount := 2
+
+	if sport != nil {
+		query += fmt.Sprintf(" AND sport_id = $%d", argCount)
+		args = append(args, *sport)
+		argCount++
+	}
+
+	if from != nil {
+		query += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, *from)
+		argCount++
+	}
+
+	if to != nil {
+		query += fmt.Sprintf(" AND created_at < $%d", argCount)
+		args = append(args, *to)
+		argCount++
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at ASC, id ASC LIMIT $%d", argCount)
+	args = append(args, maxELOHistoryEntries)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get elo history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.ELOHistoryEntry{}
+	for rows.Next() {
+		var e models.ELOHistoryEntry
+		if err := rows.Scan(
+			&e.ID, &e.UserID, &e.Sport, &e.ELOBefore, &e.ELOAfter, &e.Delta,
+			&e.Source, &e.MatchID, &e.AdjustmentID, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan elo history entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// recordELOChange inserts a history entry using a transaction or the database handle
+// Shared with AdminRepository, which changes ELO in its own transactions
+func recordELOChange(ctx context.Context, exec interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, entry *models.ELOHistoryEntry) error {
+	query := `
+		INSERT INTO elo_history (user_id, sport_id, elo_before, elo_after, delta, source, match_id, adjustment_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	entry.Delta = entry.ELOAfter - entry.ELOBefore
+	err := exec.QueryRowContext(ctx,
+		query,
+		entry.UserID,
+		entry.Sport,
+		entry.ELOBefore,
+		entry.ELOAfter,
+		entry.Delta,
+		entry.Source,
+		entry.MatchID,
+		entry.AdjustmentID,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record elo history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// ELOReplayRepository loads and saves everything a full ELO recomputation touches
+// All methods run inside the caller's transaction
+type ELOReplayRepository struct {
+	db *sql.DB
+}
+
+// NewELOReplayRepository creates a new ELOReplayRepository instance
+func NewELOReplayRepository(db *sql.DB) *ELOReplayRepository {
+	return &ELOReplayRepository{db: db}
+}
+
+// ReplayMatch is a confirmed match with the ELO data stored for it
+type ReplayMatch struct {
+	ID        int
+	Sport     string
+	Player1ID int
+	Player2ID int
+	WinnerID  int
+	Result    string
+	PlayedAt  time.Time
+	Archived  bool
+	// Scores, for margin-of-victory weighting
+	Player1Score, Player2Score int
+	// Stored ELO data, compared against the replayed values
+	Player1Before, Player1After, Player1Delta, Player1KFactor *int
+	Player2Before, Player2After, Player2Delta, Player2KFactor *int
+}
+
+// ReplayRating is a player's current rating in a sport
+type ReplayRating struct {
+	UserID int
+	Login  string
+	Sport  string
+	ELO    int
+}
+
+// Lock blocks confirmations, reverts and rating changes until the transaction ends
+func (r *ELOReplayRepository) Lock(ctx context.Context, tx *sql.Tx) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE matches, matches_archive, user_sports IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock tables for replay: %w", err)
+	}
+	return nil
+}
+
+// LoadMatches returns all confirmed matches, hot and archived, in the order they were played
+// Soft-deleted matches are left out, as they no longer count
+func (r *ELOReplayRepository) LoadMatches(ctx context.Context, tx *sql.Tx) ([]ReplayMatch, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, sport, player1_id, player2_id, winner_id, result, played_at, archived,
+		       player1_score, player2_score,
+		       player1_elo_before, player1_elo_after, player1_elo_delta, player1_k_factor,
+		       player2_elo_before, player2_elo_after, player2_elo_delta, player2_k_factor
+		FROM (
+			SELECT *, COALESCE(confirmed_at, created_at) AS played_at, false AS archived
+			FROM matches WHERE status = 'confirmed' AND deleted_at IS NULL
+			UNION ALL
+			SELECT *, COALESCE(confirmed_at, created_at) AS played_at, true AS archived
+			FROM matches_archive WHERE status = 'confirmed' AND deleted_at IS NULL
+		) confirmed
+		ORDER BY played_at, id
+	`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load matches for replay: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []ReplayMatch
+	for rows.Next() {
+		var m ReplayMatch
+		if err := rows.Scan(
+			&m.ID, &m.Sport, &m.Player1ID, &m.Player2ID, &m.WinnerID, &m.Result, &m.PlayedAt, &m.Archived,
+			&m.Player1Score, &m.Player2Score,
+			&m.Player1Before, &m.Player1After, &m.Player1Delta, &m.Player1KFactor,
+			&m.Player2Before, &m.Player2After, &m.Player2Delta, &m.Player2KFactor,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan match for replay: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// LoadSeasonStarts returns all seasons, oldest first; each start soft-reset the ratings
+func (r *ELOReplayRepository) LoadSeasonStarts(ctx context.Context, tx *sql.Tx) ([]models.Season, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+seasonColumns+` FROM seasons ORDER BY started_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seasons for replay: %w", err)
+	}
+	defer rows.Close()
+
+	var seasons []models.Season
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan season for replay: %w", err)
+		}
+		seasons = append(seasons, *season)
+	}
+	return seasons, rows.Err()
+}
+
+// LoadAdjustments returns all manual ELO adjustments, oldest first
+func (r *ELOReplayRepository) LoadAdjustments(ctx context.Context, tx *sql.Tx) ([]models.ELOAdjustment, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, user_id, sport, kind, old_elo, new_elo, reason, adjusted_by, created_at
+		FROM elo_adjustments
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load adjustments for replay: %w", err)
+	}
+	defer rows.Close()
+
+	var adjustments []models.ELOAdjustment
+	for rows.Next() {
+		var a models.ELOAdjustment
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Sport, &a.Kind, &a.OldELO, &a.NewELO, &a.Reason, &a.AdjustedBy, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan adjustment for replay: %w", err)
+		}
+		adjustments = append(adjustments, a)
+	}
+	return adjustments, rows.Err()
+}
+
+// LoadDecays returns all rating decays of inactive players, oldest first
+func (r *ELOReplayRepository) LoadDecays(ctx context.Context, tx *sql.Tx) ([]models.ELOHistoryEntry, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, user_id, sport_id, elo_before, elo_after, delta, source, created_at
+		FROM elo_history
+		WHERE source = 'decay'
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load decays for replay: %w", err)
+	}
+	defer rows.Close()
+
+	var decays []models.ELOHistoryEntry
+	for rows.Next() {
+		var d models.ELOHistoryEntry
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Sport, &d.ELOBefore, &d.ELOAfter, &d.Delta, &d.Source, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan decay for replay: %w", err)
+		}
+		decays = append(decays, d)
+	}
+	return decays, rows.Err()
+}
+
+// LoadRatings returns every player's current rating per sport
+func (r *ELOReplayRepository) LoadRatings(ctx context.Context, tx *sql.Tx) ([]ReplayRating, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT us.user_id, u.login, us.sport_id, us.current_elo
+		FROM user_sports us
+		JOIN users u ON u.id = us.user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ratings for replay: %w", err)
+	}
+	defer rows.Close()
+
+	var ratings []ReplayRating
+	for rows.Next() {
+		var rating ReplayRating
+		if err := rows.Scan(&rating.UserID, &rating.Login, &rating.Sport, &rating.ELO); err != nil {
+			return nil, fmt.Errorf("failed to scan rating for replay: %w", err)
+		}
+		ratings = append(ratings, rating)
+	}
+	return ratings, rows.Err()
+}
+
+// LoadDefaultELOs returns the starting rating of every sport
+func (r *ELOReplayRepository) LoadDefaultELOs(ctx context.Context, tx *sql.Tx) (map[string]int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, default_elo FROM sports`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sport defaults for replay: %w", err)
+	}
+	defer rows.Close()
+
+	defaults := make(map[string]int)
+	for rows.Next() {
+		var sport string
+		var elo int
+		if err := rows.Scan(&sport, &elo); err != nil {
+			return nil, fmt.Errorf("failed to scan sport default for replay: %w", err)
+		}
+		defaults[sport] = elo
+	}
+	return defaults, rows.Err()
+}
+
+// UpdateMatchELO stores the replayed ELO data of a match in the hot or archive table
+func (r *ELOReplayRepository) UpdateMatchELO(ctx context.Context, tx *sql.Tx, m *ReplayMatch) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	table := "matches"
+	if m.Archived {
+		table = "matches_archive"
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		UPDATE `+table+` SET
+			player1_elo_before = $1, player1_elo_after = $2, player1_elo_delta = $3,
+			player2_elo_before = $4, player2_elo_after = $5, player2_elo_delta = $6,
+			player1_k_factor = $8, player2_k_factor = $9
+		WHERE id = $7
+	`, m.Player1Before, m.Player1After, m.Player1Delta, m.Player2Before, m.Player2After, m.Player2Delta, m.ID,
+		m.Player1KFactor, m.Player2KFactor)
+	if err != nil {
+		return fmt.Errorf("failed to update ELO of match %d: %w", m.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package fakes
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// Challenges is an in-memory repositories.ChallengeStore
+type Challenges struct {
+	repositories.ChallengeStore
+
+	mu         sync.Mutex
+	challenges map[int]*models.Challenge
+	nextID     int
+}
+
+// NewChallenges returns an empty store
+func NewChallenges() *Challenges {
+	return &Challenges{challenges: make(map[int]*models.Challenge)}
+}
+
+// Create stores a new pending challenge
+// Fails with "challenge already exists" if the same slot is already challenged and open
+func (s *Challenges) Create(ctx context.Context, challenge *models.Challenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.challenges {
+		if c.ChallengerID == challenge.ChallengerID && c.OpponentID == challenge.OpponentID &&
+			c.Sport == challenge.Sport && c.ScheduledAt.Equal(challenge.ScheduledAt) && isOpen(c) {
+			return fmt.Errorf("challenge already exists")
+		}
+	}
+
+	s.nextID++
+	now := time.Now()
+	challenge.ID = s.nextID
+	challenge.Status = models.ChallengePending
+	challenge.CreatedAt, challenge.UpdatedAt = now, now
+	stored := *challenge
+	s.challenges[stored.ID] = &stored
+	return nil
+}
+
+// GetByID returns a copy of a challenge
+func (s *Challenges) GetByID(ctx context.Context, id int) (*models.Challenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge, ok := s.challenges[id]
+	if !ok {
+		return nil, fmt.Errorf("challenge not found")
+	}
+	found := *challenge
+	return &found, nil
+}
+
+// GetByIDForUpdate returns a copy of a challenge; there are no row locks to take
+func (s *Challenges) GetByIDForUpdate(ctx context.Context, tx *sql.Tx, id int) (*models.Challenge, error) {
+	return s.GetByID(ctx, id)
+}
+
+// Respond accepts or declines a pending challenge on behalf of its opponent
+func (s *Challenges) Respond(ctx context.Context, id, opponentID int, status string) (*models.Challenge, error) {
+	return s.update(id, "challenge is not pending", func(c *models.Challenge) bool {
+		if c.OpponentID != opponentID || c.Status != models.ChallengePending {
+			return false
+		}
+		now := time.Now()
+		c.Status = status
+		c.RespondedAt = &now
+		return true
+	})
+}
+
+// Cancel withdraws an open challenge on behalf of its challenger
+func (s *Challenges) Cancel(ctx context.Context, id, challengerID int) (*models.Challenge, error) {
+	return s.update(id, "challenge is not open", func(c *models.Challenge) bool {
+		if c.ChallengerID != challengerID || !isOpen(c) {
+			return false
+		}
+		c.Status = models.ChallengeCancelled
+		return true
+	})
+}
+
+// Complete links the match created from the result of an accepted challenge
+func (s *Challenges) Complete(ctx context.Context, tx *sql.Tx, id, matchID int) error {
+	_, err := s.update(id, "", func(c *models.Challenge) bool {
+		if c.Status != models.ChallengeAccepted {
+			return false
+		}
+		c.Status = models.ChallengeCompleted
+		c.MatchID = &matchID
+		return true
+	})
+	return err
+}
+
+// update applies a change to a stored challenge; a change that does not apply fails with
+// notApplied, an empty notApplied leaves the challenge untouched without an error
+func (s *Challenges) update(id int, notApplied string, change func(c *models.Challenge) bool) (*models.Challenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge, ok := s.challenges[id]
+	if !ok || !change(challenge) {
+		if notApplied == "" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s", notApplied)
+	}
+	challenge.UpdatedAt = time.Now()
+	updated := *challenge
+	return &updated, nil
+}
+
+// isOpen reports whether a challenge can still be answered or played
+func isOpen(c *models.Challenge) bool {
+	return c.Status == models.ChallengePending || c.Status == models.ChallengeAccepted
+}
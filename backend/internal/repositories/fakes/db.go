@@ -0,0 +1,46 @@
+// Package fakes provides in-memory implementations of the repository store interfaces
+// for service tests. The fakes keep the observable behavior of the repositories (error
+// messages, status transitions, defaults) without a database. Methods a fake does not
+// implement panic through the embedded interface, so a test notices when a service
+// starts to depend on something new.
+package fakes
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// errNoDatabase is returned by every statement run on the database of NewDB
+var errNoDatabase = errors.New("fakes: no database, use a store fake instead")
+
+// NewDB returns a *sql.DB whose transactions can be begun, committed and rolled back
+// but which cannot run statements. Services open their transactions on it and hand
+// the *sql.Tx to the store fakes, which ignore it.
+func NewDB() *sql.DB {
+	return sql.OpenDB(connector{})
+}
+
+type connector struct{}
+
+func (connector) Connect(context.Context) (driver.Conn, error) { return conn{}, nil }
+func (connector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return conn{}, nil }
+
+type conn struct{}
+
+func (conn) Prepare(string) (driver.Stmt, error) { return nil, errNoDatabase }
+func (conn) Close() error                        { return nil }
+func (conn) Begin() (driver.Tx, error)           { return tx{}, nil }
+
+// BeginTx accepts any isolation level and ignores it
+func (conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) { return tx{}, nil }
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
@@ -0,0 +1,50 @@
+package fakes
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// ELOHistory is an in-memory repositories.ELOHistoryStore
+type ELOHistory struct {
+	repositories.ELOHistoryStore
+
+	mu      sync.Mutex
+	entries []models.ELOHistoryEntry
+}
+
+// NewELOHistory returns an empty history
+func NewELOHistory() *ELOHistory {
+	return &ELOHistory{}
+}
+
+// Record appends an entry, filling in its ID, delta and time like the database
+func (s *ELOHistory) Record(ctx context.Context, tx *sql.Tx, entry *models.ELOHistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.ID = int64(len(s.entries) + 1)
+	entry.Delta = entry.ELOAfter - entry.ELOBefore
+	entry.CreatedAt = time.Now()
+	s.entries = append(s.entries, *entry)
+	return nil
+}
+
+// Entries returns the recorded entries of a user, oldest first
+func (s *ELOHistory) Entries(userID int) []models.ELOHistoryEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []models.ELOHistoryEntry
+	for _, entry := range s.entries {
+		if entry.UserID == userID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
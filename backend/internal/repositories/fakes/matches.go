@@ -0,0 +1,310 @@
+package fakes
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// Matches is an in-memory repositories.MatchStore
+// Writes take effect right away, also inside a transaction that is rolled back later
+type Matches struct {
+	repositories.MatchStore
+
+	mu        sync.Mutex
+	matches   map[int]*models.Match
+	proposals map[int]*models.CounterProposal
+	nextID    int
+}
+
+// NewMatches returns an empty store
+func NewMatches() *Matches {
+	return &Matches{
+		matches:   make(map[int]*models.Match),
+		proposals: make(map[int]*models.CounterProposal),
+	}
+}
+
+// Add stores a match as it is, e.g. an already confirmed one; a zero ID is assigned
+func (s *Matches) Add(match models.Match) *models.Match {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if match.ID == 0 {
+		s.nextID++
+		match.ID = s.nextID
+	} else if match.ID > s.nextID {
+		s.nextID = match.ID
+	}
+	if match.Result == "" {
+		match.Result = models.ResultPlayed
+	}
+	s.matches[match.ID] = &match
+	return copyMatch(&match)
+}
+
+// Create stores a new match and sets its ID and timestamps
+func (s *Matches) Create(ctx context.Context, tx *sql.Tx, match *models.Match) error {
+	now := time.Now()
+	match.CreatedAt, match.UpdatedAt = now, now
+	created := s.Add(*match)
+	match.ID = created.ID
+	match.Result = created.Result
+	return nil
+}
+
+// CreateSets stores the sets of a series
+func (s *Matches) CreateSets(ctx context.Context, tx *sql.Tx, matchID int, sets []models.MatchSet) error {
+	return s.update(matchID, func(m *models.Match) error {
+		m.Sets = append([]models.MatchSet(nil), sets...)
+		return nil
+	})
+}
+
+// DeleteSets removes the sets of a series
+func (s *Matches) DeleteSets(ctx context.Context, tx *sql.Tx, matchID int) error {
+	return s.update(matchID, func(m *models.Match) error {
+		m.Sets = nil
+		return nil
+	})
+}
+
+// GetByID returns a copy of a match
+func (s *Matches) GetByID(ctx context.Context, id int) (*models.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	match, ok := s.matches[id]
+	if !ok {
+		return nil, fmt.Errorf("match not found")
+	}
+	return copyMatch(match), nil
+}
+
+// GetUserMatches returns a user's confirmed matches, newest first; the filters are optional
+func (s *Matches) GetUserMatches(ctx context.Context, userID int, sport *string, opponentID *int, won *bool) ([]models.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matches := []models.Match{}
+	for _, m := range s.matches {
+		if m.Status != models.StatusConfirmed || (m.Player1ID != userID && m.Player2ID != userID) {
+			continue
+		}
+		if sport != nil && m.Sport != *sport {
+			continue
+		}
+		if opponentID != nil && m.Player1ID != *opponentID && m.Player2ID != *opponentID {
+			continue
+		}
+		if won != nil && (m.WinnerID == userID) != *won {
+			continue
+		}
+		matches = append(matches, *copyMatch(m))
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID > matches[j].ID })
+	return matches, nil
+}
+
+// GetPendingMatchBetweenPlayers returns a pending match of the pair in either order, or nil
+func (s *Matches) GetPendingMatchBetweenPlayers(ctx context.Context, player1ID, player2ID int, sport string) (*models.Match, error) {
+	pending := s.pendingBetween(player1ID, player2ID, sport)
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	return pending[0], nil
+}
+
+// GetPendingStatsBetweenPlayers counts the pending matches of the pair and returns the latest submission time
+func (s *Matches) GetPendingStatsBetweenPlayers(ctx context.Context, player1ID, player2ID int, sport string) (int, *time.Time, error) {
+	pending := s.pendingBetween(player1ID, player2ID, sport)
+	var latest *time.Time
+	for _, m := range pending {
+		if latest == nil || m.CreatedAt.After(*latest) {
+			createdAt := m.CreatedAt
+			latest = &createdAt
+		}
+	}
+	return len(pending), latest, nil
+}
+
+func (s *Matches) pendingBetween(player1ID, player2ID int, sport string) []*models.Match {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []*models.Match
+	for _, m := range s.matches {
+		samePair := (m.Player1ID == player1ID && m.Player2ID == player2ID) ||
+			(m.Player1ID == player2ID && m.Player2ID == player1ID)
+		if samePair && m.Sport == sport && m.Status == models.StatusPending {
+			pending = append(pending, copyMatch(m))
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+	return pending
+}
+
+// ConfirmMatch marks a match confirmed with the ELO data of the confirmation
+func (s *Matches) ConfirmMatch(ctx context.Context, tx *sql.Tx, matchID int, from string, eloData map[string]int, confirmFingerprint *string) error {
+	return s.update(matchID, func(m *models.Match) error {
+		if m.Status != from {
+			return repositories.ErrMatchStatusChanged
+		}
+		now := time.Now()
+		m.Status = models.StatusConfirmed
+		m.ConfirmedAt = &now
+		m.Player1ELOBefore = intPtr(eloData["player1_before"])
+		m.Player1ELOAfter = intPtr(eloData["player1_after"])
+		m.Player1ELODelta = intPtr(eloData["player1_delta"])
+		m.Player2ELOBefore = intPtr(eloData["player2_before"])
+		m.Player2ELOAfter = intPtr(eloData["player2_after"])
+		m.Player2ELODelta = intPtr(eloData["player2_delta"])
+		m.Player1KFactor = intPtr(eloData["player1_k"])
+		m.Player2KFactor = intPtr(eloData["player2_k"])
+		m.ConfirmFingerprint = confirmFingerprint
+		return nil
+	})
+}
+
+// DenyMatch marks a match denied
+func (s *Matches) DenyMatch(ctx context.Context, tx *sql.Tx, matchID int) error {
+	return s.update(matchID, func(m *models.Match) error {
+		now := time.Now()
+		m.Status = models.StatusDenied
+		m.DeniedAt = &now
+		return nil
+	})
+}
+
+// CancelMatch marks a match cancelled
+func (s *Matches) CancelMatch(ctx context.Context, matchID int) error {
+	return s.update(matchID, func(m *models.Match) error {
+		m.Status = models.StatusCancelled
+		return nil
+	})
+}
+
+// SetStatus moves a match from one status to another
+func (s *Matches) SetStatus(ctx context.Context, matchID int, from, to string) error {
+	return s.update(matchID, func(m *models.Match) error {
+		if m.Status != from {
+			return repositories.ErrMatchStatusChanged
+		}
+		m.Status = to
+		if to == models.StatusDenied {
+			now := time.Now()
+			m.DeniedAt = &now
+		}
+		return nil
+	})
+}
+
+// UpdateScores replaces the scores and the winner of a match
+func (s *Matches) UpdateScores(ctx context.Context, tx *sql.Tx, matchID, player1Score, player2Score, winnerID int) error {
+	return s.update(matchID, func(m *models.Match) error {
+		m.Player1Score, m.Player2Score, m.WinnerID = player1Score, player2Score, winnerID
+		return nil
+	})
+}
+
+// CorrectResult stores the corrected result and ELO changes of a confirmed match
+func (s *Matches) CorrectResult(ctx context.Context, tx *sql.Tx, match *models.Match) error {
+	return s.update(match.ID, func(m *models.Match) error {
+		if m.Status != models.StatusConfirmed || !m.UpdatedAt.Equal(match.UpdatedAt) {
+			return repositories.ErrMatchStatusChanged
+		}
+		m.Player1Score, m.Player2Score, m.WinnerID = match.Player1Score, match.Player2Score, match.WinnerID
+		m.ForfeitedBy = match.ForfeitedBy
+		m.Player1ELOAfter, m.Player1ELODelta = match.Player1ELOAfter, match.Player1ELODelta
+		m.Player2ELOAfter, m.Player2ELODelta = match.Player2ELOAfter, match.Player2ELODelta
+		return nil
+	})
+}
+
+// CreateCounterProposal stores a pending counter-proposal
+func (s *Matches) CreateCounterProposal(ctx context.Context, tx *sql.Tx, proposal *models.CounterProposal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposal.Status = models.CounterProposalPending
+	proposal.CreatedAt = time.Now()
+	stored := *proposal
+	s.proposals[proposal.MatchID] = &stored
+	return nil
+}
+
+// GetCounterProposal returns the counter-proposal of a match, or nil if there is none
+func (s *Matches) GetCounterProposal(ctx context.Context, matchID int) (*models.CounterProposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposal, ok := s.proposals[matchID]
+	if !ok {
+		return nil, nil
+	}
+	found := *proposal
+	return &found, nil
+}
+
+// ResolveCounterProposal marks a pending counter-proposal as accepted or rejected
+func (s *Matches) ResolveCounterProposal(ctx context.Context, tx *sql.Tx, matchID int, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposal, ok := s.proposals[matchID]
+	if !ok || proposal.Status != models.CounterProposalPending {
+		return fmt.Errorf("counter-proposal is no longer pending")
+	}
+	now := time.Now()
+	proposal.Status = status
+	proposal.ResolvedAt = &now
+	return nil
+}
+
+// update applies a change to a stored match
+func (s *Matches) update(matchID int, change func(m *models.Match) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	match, ok := s.matches[matchID]
+	if !ok {
+		return fmt.Errorf("match not found")
+	}
+	if err := change(match); err != nil {
+		return err
+	}
+	match.UpdatedAt = time.Now()
+	return nil
+}
+
+// confirmed returns copies of the confirmed matches of a user in a sport, oldest first
+func (s *Matches) confirmed(userID int, sport string) []models.Match {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matches []models.Match
+	for _, m := range s.matches {
+		if m.Status == models.StatusConfirmed && m.Sport == sport && (m.Player1ID == userID || m.Player2ID == userID) {
+			matches = append(matches, *copyMatch(m))
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches
+}
+
+// copyMatch copies a match so callers cannot change the stored one
+func copyMatch(m *models.Match) *models.Match {
+	c := *m
+	c.Sets = append([]models.MatchSet(nil), m.Sets...)
+	return &c
+}
+
+func intPtr(v int) *int {
+	return &v
+}
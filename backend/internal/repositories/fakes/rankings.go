@@ -0,0 +1,59 @@
+package fakes
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// Rankings is an in-memory repositories.RankingStore that records which sports were refreshed
+// and which players were ranked
+type Rankings struct {
+	mu        sync.Mutex
+	refreshed []string
+	ranked    []int
+}
+
+var _ repositories.RankingStore = (*Rankings)(nil)
+
+// NewRankings returns a store without refreshes
+func NewRankings() *Rankings {
+	return &Rankings{}
+}
+
+// Refresh records the sports, "*" for a refresh of all sports
+func (s *Rankings) Refresh(ctx context.Context, tx *sql.Tx, sports ...string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(sports) == 0 {
+		sports = []string{"*"}
+	}
+	s.refreshed = append(s.refreshed, sports...)
+	return int64(len(sports)), nil
+}
+
+// RankPlayers records the players
+func (s *Rankings) RankPlayers(ctx context.Context, tx *sql.Tx, userIDs []int, sports ...string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ranked = append(s.ranked, userIDs...)
+	return int64(len(userIDs)), nil
+}
+
+// Ranked returns the ranked players in order
+func (s *Rankings) Ranked() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.ranked...)
+}
+
+// Refreshed returns the refreshed sports in order
+func (s *Rankings) Refreshed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.refreshed...)
+}
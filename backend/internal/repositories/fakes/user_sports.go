@@ -0,0 +1,181 @@
+package fakes
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// defaultELO is what the repository reports for a player without a row in a sport
+const defaultELO = 1000
+
+// UserSports is an in-memory repositories.UserSportsStore
+// ReconcileStats recomputes the records from the confirmed matches of Matches
+type UserSports struct {
+	repositories.UserSportsStore
+
+	mu      sync.Mutex
+	rows    map[userSportKey]*repositories.UserSportData
+	matches *Matches
+}
+
+type userSportKey struct {
+	userID  int
+	sportID string
+}
+
+// NewUserSports returns an empty store that reconciles stats against matches
+func NewUserSports(matches *Matches) *UserSports {
+	return &UserSports{
+		rows:    make(map[userSportKey]*repositories.UserSportData),
+		matches: matches,
+	}
+}
+
+// SetELO stores a player's rating in a sport, creating the row if needed
+func (s *UserSports) SetELO(userID int, sportID string, elo int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	row := s.row(userID, sportID, elo)
+	row.CurrentELO = elo
+	row.HighestELO = max(row.HighestELO, elo)
+}
+
+// Get returns a copy of a player's row in a sport, or nil if there is none
+func (s *UserSports) Get(userID int, sportID string) *repositories.UserSportData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	row, ok := s.rows[userSportKey{userID, sportID}]
+	if !ok {
+		return nil
+	}
+	data := *row
+	return &data
+}
+
+// EnsureUserSportExists creates a player's row with the default rating if it is missing
+func (s *UserSports) EnsureUserSportExists(ctx context.Context, tx *sql.Tx, userID int, sportID string, defaultELO int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.row(userID, sportID, defaultELO)
+	return nil
+}
+
+// GetRatingForUpdate returns a player's rating and matches played
+func (s *UserSports) GetRatingForUpdate(ctx context.Context, tx *sql.Tx, userID int, sportID string) (int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	row, ok := s.rows[userSportKey{userID, sportID}]
+	if !ok {
+		return defaultELO, 0, nil
+	}
+	return row.CurrentELO, row.MatchesPlayed, nil
+}
+
+// GetUserELOForUpdate returns a player's rating
+func (s *UserSports) GetUserELOForUpdate(ctx context.Context, tx *sql.Tx, userID int, sportID string) (int, error) {
+	elo, _, err := s.GetRatingForUpdate(ctx, tx, userID, sportID)
+	return elo, err
+}
+
+// UpdateUserELO sets a player's rating, creating the row if needed
+func (s *UserSports) UpdateUserELO(ctx context.Context, tx *sql.Tx, userID int, sportID string, newELO int) error {
+	s.SetELO(userID, sportID, newELO)
+	return nil
+}
+
+// IncrementMatchStats counts a win or a loss and updates the streaks
+func (s *UserSports) IncrementMatchStats(ctx context.Context, tx *sql.Tx, userID int, sportID string, won bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	row := s.row(userID, sportID, defaultELO)
+	now := time.Now()
+	countResult(row, won)
+	row.LastMatchAt = &now
+	return nil
+}
+
+// ReconcileStats recomputes the records of the given users from their confirmed matches
+// Returns the number of rows that changed
+func (s *UserSports) ReconcileStats(ctx context.Context, tx *sql.Tx, userIDs []int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := make(map[int]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+
+	var corrected int64
+	for key, row := range s.rows {
+		if userIDs != nil && !wanted[key.userID] {
+			continue
+		}
+		recomputed := *row
+		recomputed.MatchesPlayed, recomputed.Wins, recomputed.Losses = 0, 0, 0
+		recomputed.CurrentStreak, recomputed.LongestWinStreak = 0, 0
+		for _, m := range s.matches.confirmed(key.userID, key.sportID) {
+			countResult(&recomputed, m.WinnerID == key.userID)
+		}
+		if recomputed != *row {
+			*row = recomputed
+			corrected++
+		}
+	}
+	return corrected, nil
+}
+
+// GetAllUserSports returns copies of all rows of a player
+func (s *UserSports) GetAllUserSports(ctx context.Context, userID int) (map[string]*repositories.UserSportData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sports := make(map[string]*repositories.UserSportData)
+	for key, row := range s.rows {
+		if key.userID == userID {
+			data := *row
+			sports[key.sportID] = &data
+		}
+	}
+	return sports, nil
+}
+
+// row returns a player's row, creating it with elo if it is missing
+func (s *UserSports) row(userID int, sportID string, elo int) *repositories.UserSportData {
+	key := userSportKey{userID, sportID}
+	row, ok := s.rows[key]
+	if !ok {
+		now := time.Now()
+		row = &repositories.UserSportData{
+			UserID:     userID,
+			SportID:    sportID,
+			CurrentELO: elo,
+			HighestELO: elo,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		s.rows[key] = row
+	}
+	return row
+}
+
+// countResult adds one result to a record like IncrementMatchStats does in SQL
+func countResult(row *repositories.UserSportData, won bool) {
+	row.MatchesPlayed++
+	if won {
+		row.Wins++
+		row.CurrentStreak = max(row.CurrentStreak, 0) + 1
+		row.LongestWinStreak = max(row.LongestWinStreak, row.CurrentStreak)
+	} else {
+		row.Losses++
+		row.CurrentStreak = min(row.CurrentStreak, 0) - 1
+	}
+}
@@ -0,0 +1,66 @@
+package fakes
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// Users is an in-memory repositories.UserStore
+type Users struct {
+	repositories.UserStore
+
+	mu    sync.Mutex
+	users map[int]models.User
+}
+
+// NewUsers returns a store holding the given users
+func NewUsers(users ...models.User) *Users {
+	s := &Users{users: make(map[int]models.User)}
+	for _, user := range users {
+		s.Add(user)
+	}
+	return s
+}
+
+// Add stores or replaces a user
+func (s *Users) Add(user models.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.ID] = user
+}
+
+// GetByID returns a copy of a user
+func (s *Users) GetByID(ctx context.Context, id int) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	return &user, nil
+}
+
+// GetByIDForUpdate returns a copy of a user; there are no row locks to take
+func (s *Users) GetByIDForUpdate(ctx context.Context, tx *sql.Tx, id int) (*models.User, error) {
+	return s.GetByID(ctx, id)
+}
+
+// GetByIDs returns the users that exist among ids
+func (s *Users) GetByIDs(ctx context.Context, ids []int) ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := []models.User{}
+	for _, id := range ids {
+		if user, ok := s.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
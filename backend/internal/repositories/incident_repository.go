@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// IncidentRepository handles incident annotations of the status page
+type IncidentRepository struct {
+	db *sql.DB
+}
+
+// NewIncidentRepository creates a new IncidentRepository instance
+func NewIncidentRepository(db *sql.DB) *IncidentRepository {
+	return &IncidentRepository{db: db}
+}
+
+const incidentColumns = `id, title, message, impact, started_at, resolved_at, created_by, created_at, updated_at`
+
+func scanIncident(row interface{ Scan(...interface{}) error }) (*models.StatusIncident, error) {
+	incident := &models.StatusIncident{}
+	err := row.Scan(
+		&incident.ID,
+		&incident.Title,
+		&incident.Message,
+		&incident.Impact,
+		&incident.StartedAt,
+		&incident.ResolvedAt,
+		&incident.CreatedBy,
+		&incident.CreatedAt,
+		&incident.UpdatedAt,
+	)
+	return incident, err
+}
+
+// Create opens an incident
+func (r *IncidentRepository) Create(ctx context.Context, incident *models.StatusIncident) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO status_incidents (title, message, impact, started_at, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, incident.Title, incident.Message, incident.Impact, incident.StartedAt, incident.CreatedBy).
+		Scan(&incident.ID, &incident.CreatedAt, &incident.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create incident: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns an incident by ID
+func (r *IncidentRepository) GetByID(ctx context.Context, id int) (*models.StatusIncident, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT ` + incidentColumns + ` FROM status_incidents WHERE id = $1`
+
+	incident, err := scanIncident(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("incident not found")
+	}
+	return incident, err
+}
+
+// ListRecent returns unresolved incidents and those resolved after the cutoff, newest first
+func (r *IncidentRepository) ListRecent(ctx context.Context, resolvedSince time.Time, limit int) ([]models.StatusIncident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM status_incidents
+		WHERE resolved_at IS NULL OR resolved_at >= $1
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, resolvedSince, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []models.StatusIncident{}
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan incident: %w", err)
+		}
+		incidents = append(incidents, *incident)
+	}
+	return incidents, rows.Err()
+}
+
+// Update saves the title, message, impact and resolution time of an incident
+func (r *IncidentRepository) Update(ctx context.Context, incident *models.StatusIncident) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE status_incidents SET title = $1, message = $2, impact = $3, resolved_at = $4
+		WHERE id = $5
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, incident.Title, incident.Message, incident.Impact, incident.ResolvedAt, incident.ID).
+		Scan(&incident.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("incident not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update incident: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an incident
+func (r *IncidentRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM status_incidents WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete incident: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete incident: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("incident not found")
+	}
+	return nil
+}
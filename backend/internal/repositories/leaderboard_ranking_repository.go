@@ -0,0 +1,342 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// LeaderboardRankingRepository maintains leaderboard_rankings, the materialized ranked
+// leaderboard of every sport that GetLeaderboardEntries pages through
+type LeaderboardRankingRepository struct {
+	db *sql.DB
+}
+
+func NewLeaderboardRankingRepository(db *sql.DB) *LeaderboardRankingRepository {
+	return &LeaderboardRankingRepository{db: db}
+}
+
+// Refresh re-ranks the leaderboards of the given sports, or of all sports when none are given,
+// and returns the number of rankings that were added, moved or dropped
+// Run it in the transaction that changed ratings, records, bans or placement rules, so the
+// rankings commit with the change. Only rows whose place or figures changed are written.
+// Rankings of the same sport are changed by one transaction at a time; the lock is taken
+// within tx, so tx must run at READ COMMITTED for its queries to see the rankings committed
+// while it waited. A REPEATABLE READ or SERIALIZABLE tx took its snapshot before and fails.
+// A nil tx runs in a transaction of its own.
+func (r *LeaderboardRankingRepository) Refresh(ctx context.Context, tx *sql.Tx, sports ...string) (int64, error) {
+	// Whole leaderboards can take longer than the query timeout meant for requests
+	ctx, cancel := queryContext(WithoutQueryTimeout(ctx))
+	defer cancel()
+
+	if tx == nil {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		defer tx.Rollback()
+
+		changed, err := r.Refresh(ctx, tx, sports...)
+		if err != nil {
+			return 0, err
+		}
+		return changed, tx.Commit()
+	}
+
+	filter, err := lockRankings(ctx, tx, sports)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		WITH standings AS (
+			SELECT
+				s.id AS sport_id,
+				u.id AS user_id,
+				COALESCE(us.current_elo, s.default_elo) AS elo,
+				COALESCE(us.wins, 0) AS wins,
+				COALESCE(us.matches_played, 0) AS matches_played
+			FROM users u
+			JOIN sports s ON $1::text[] IS NULL OR s.id = ANY($1::text[])
+			LEFT JOIN user_sports us ON us.user_id = u.id AND us.sport_id = s.id
+			WHERE u.id != -1 AND NOT u.is_banned AND u.deactivated_at IS NULL
+			  AND COALESCE(us.matches_played, 0) >= s.placement_matches
+		),
+		ranked AS (
+			SELECT
+				sport_id, user_id, elo, wins, matches_played,
+				RANK() OVER (PARTITION BY sport_id ORDER BY elo DESC) AS rank,
+				ROW_NUMBER() OVER (PARTITION BY sport_id ORDER BY elo DESC, wins DESC, matches_played DESC, user_id ASC) AS position
+			FROM standings
+		),
+		dropped AS (
+			DELETE FROM leaderboard_rankings lr
+			WHERE ($1::text[] IS NULL OR lr.sport_id = ANY($1::text[]))
+			  AND NOT EXISTS (SELECT 1 FROM ranked WHERE ranked.sport_id = lr.sport_id AND ranked.user_id = lr.user_id)
+			RETURNING 1
+		),
+		upserted AS (
+			INSERT INTO leaderboard_rankings AS lr (sport_id, user_id, elo, wins, matches_played, rank, position)
+			SELECT sport_id, user_id, elo, wins, matches_played, rank, position FROM ranked
+			ON CONFLICT (sport_id, user_id) DO UPDATE SET
+				elo = EXCLUDED.elo,
+				wins = EXCLUDED.wins,
+				matches_played = EXCLUDED.matches_played,
+				rank = EXCLUDED.rank,
+				position = EXCLUDED.position
+			WHERE (lr.elo, lr.wins, lr.matches_played, lr.rank, lr.position)
+				IS DISTINCT FROM (EXCLUDED.elo, EXCLUDED.wins, EXCLUDED.matches_played, EXCLUDED.rank, EXCLUDED.position)
+			RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM dropped) + (SELECT COUNT(*) FROM upserted)
+	`
+
+	var changed int64
+	if err := tx.QueryRowContext(ctx, query, filter).Scan(&changed); err != nil {
+		return 0, fmt.Errorf("failed to refresh leaderboard rankings: %w", err)
+	}
+	return changed, nil
+}
+
+// RankPlayers moves the given players to their current place on the leaderboards of the given
+// sports, or of all sports when none are given, and returns the number of rankings written
+// It is the cheap form of Refresh for changes that only touch these players, such as a confirmed
+// match or a first login: besides their own rows only the rows between a player's old and new
+// place move, by one position, and only ranks that the player passed or fell behind change.
+// Run it in the transaction that made the change, at READ COMMITTED like Refresh; the rest
+// of the leaderboard must be current.
+func (r *LeaderboardRankingRepository) RankPlayers(ctx context.Context, tx *sql.Tx, userIDs []int, sports ...string) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	return rankPlayers(ctx, tx, userIDs, sports...)
+}
+
+// lockRankings serializes changes to the leaderboards of the given sports, or of all sports,
+// until tx ends and returns the sport filter of the ranking queries
+// The sports are locked in a fixed order so concurrent refreshes of several sports cannot deadlock
+func lockRankings(ctx context.Context, tx *sql.Tx, sports []string) (interface{}, error) {
+	// A nil slice is NULL and selects every sport
+	var filter interface{} = pq.Array(sports)
+	if len(sports) == 0 {
+		filter = nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		SELECT pg_advisory_xact_lock(hashtext('leaderboard_rankings:' || id))
+		FROM (SELECT id FROM sports WHERE $1::text[] IS NULL OR id = ANY($1::text[]) ORDER BY id) locked
+	`, filter); err != nil {
+		return nil, fmt.Errorf("failed to lock leaderboard rankings: %w", err)
+	}
+	return filter, nil
+}
+
+// playerStanding is what places a player on the leaderboard of a sport
+type playerStanding struct {
+	elo           int
+	wins          int
+	matchesPlayed int
+}
+
+// rankPlayers is RankPlayers inside the caller's transaction, for repositories that change
+// ratings, records or accounts in a transaction of their own
+func rankPlayers(ctx context.Context, tx *sql.Tx, userIDs []int, sports ...string) (int64, error) {
+	filter, err := lockRankings(ctx, tx, sports)
+	if err != nil {
+		return 0, err
+	}
+
+	// Current standings, nil for players the leaderboard of a sport does not show
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			s.id,
+			u.id,
+			COALESCE(us.current_elo, s.default_elo),
+			COALESCE(us.wins, 0),
+			COALESCE(us.matches_played, 0),
+			u.id != -1 AND NOT u.is_banned AND u.deactivated_at IS NULL
+				AND COALESCE(us.matches_played, 0) >= s.placement_matches
+		FROM sports s
+		JOIN users u ON u.id = ANY($2::int[])
+		LEFT JOIN user_sports us ON us.user_id = u.id AND us.sport_id = s.id
+		WHERE $1::text[] IS NULL OR s.id = ANY($1::text[])
+		ORDER BY s.id, u.id
+	`, filter, pq.Array(userIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to load player standings: %w", err)
+	}
+	type placement struct {
+		sport    string
+		userID   int
+		standing *playerStanding
+	}
+	var placements []placement
+	for rows.Next() {
+		var p placement
+		var standing playerStanding
+		var ranked bool
+		if err := rows.Scan(&p.sport, &p.userID, &standing.elo, &standing.wins, &standing.matchesPlayed, &ranked); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if ranked {
+			p.standing = &standing
+		}
+		placements = append(placements, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var changed int64
+	for _, p := range placements {
+		n, err := placePlayer(ctx, tx, p.sport, p.userID, p.standing)
+		if err != nil {
+			return 0, err
+		}
+		changed += n
+	}
+	return changed, nil
+}
+
+// unrankPlayers takes the given players off every leaderboard, closing the gaps they leave
+// Run it before deleting users, whose rankings would otherwise be dropped without moving the rest
+func unrankPlayers(ctx context.Context, tx *sql.Tx, userIDs []int) (int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT sport_id FROM leaderboard_rankings WHERE user_id = ANY($1::int[]) ORDER BY sport_id
+	`, pq.Array(userIDs))
+	if err != nil {
+		return 0, err
+	}
+	var sports []string
+	for rows.Next() {
+		var sport string
+		if err := rows.Scan(&sport); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		sports = append(sports, sport)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(sports) == 0 {
+		return 0, nil
+	}
+
+	if _, err := lockRankings(ctx, tx, sports); err != nil {
+		return 0, err
+	}
+
+	var changed int64
+	for _, sport := range sports {
+		for _, userID := range userIDs {
+			n, err := placePlayer(ctx, tx, sport, userID, nil)
+			if err != nil {
+				return 0, err
+			}
+			changed += n
+		}
+	}
+	return changed, nil
+}
+
+// placePlayer moves one player to the place of standing on the leaderboard of a sport,
+// or takes them off it for a nil standing, and returns the number of rows written
+// The leaderboard of the sport must be locked by the caller
+func placePlayer(ctx context.Context, tx *sql.Tx, sport string, userID int, standing *playerStanding) (int64, error) {
+	var old struct {
+		playerStanding
+		position int
+	}
+	err := tx.QueryRowContext(ctx, `
+		SELECT elo, wins, matches_played, position FROM leaderboard_rankings
+		WHERE sport_id = $1 AND user_id = $2
+	`, sport, userID).Scan(&old.elo, &old.wins, &old.matchesPlayed, &old.position)
+	listed := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to load ranking: %w", err)
+	}
+	if !listed && standing == nil {
+		return 0, nil
+	}
+	if listed && standing != nil && old.playerStanding == *standing {
+		return 0, nil
+	}
+
+	// The rest of the leaderboard as seen without the player: its size, how many of them the
+	// player is placed behind and how many have a higher rating
+	var key playerStanding
+	if standing != nil {
+		key = *standing
+	}
+	var others, ahead, higher int
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE (elo, wins, matches_played, -user_id) > ($3, $4, $5, -$2::int)),
+			COUNT(*) FILTER (WHERE elo > $3)
+		FROM leaderboard_rankings
+		WHERE sport_id = $1 AND user_id != $2
+	`, sport, userID, key.elo, key.wins, key.matchesPlayed).Scan(&others, &ahead, &higher)
+	if err != nil {
+		return 0, fmt.Errorf("failed to place ranking: %w", err)
+	}
+
+	// Old and new place, where a player who is not listed is placed after the last row
+	oldPosition, newPosition := others+1, others+1
+	var oldELO, newELO sql.NullInt64
+	if listed {
+		oldPosition = old.position
+		oldELO = sql.NullInt64{Int64: int64(old.elo), Valid: true}
+	}
+	if standing != nil {
+		newPosition = ahead + 1
+		newELO = sql.NullInt64{Int64: int64(standing.elo), Valid: true}
+	}
+
+	// Rows between the two places move by one towards the old place. A rank counts the rows
+	// with a higher rating, so it changes for the rows whose rating the player passed or fell
+	// behind; a missing rating counts as below everyone
+	from, to, shift := newPosition, oldPosition-1, 1
+	if newPosition > oldPosition {
+		from, to, shift = oldPosition+1, newPosition, -1
+	}
+	result, err := tx.ExecContext(ctx, `
+		UPDATE leaderboard_rankings SET
+			position = position + CASE WHEN position BETWEEN $3 AND $4 THEN $5 ELSE 0 END,
+			rank = rank + COALESCE((elo < $6::int)::int, 0) - COALESCE((elo < $7::int)::int, 0)
+		WHERE sport_id = $1 AND user_id != $2
+		  AND (position BETWEEN $3 AND $4 OR COALESCE(elo < $6::int, false) != COALESCE(elo < $7::int, false))
+	`, sport, userID, from, to, shift, newELO, oldELO)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move rankings: %w", err)
+	}
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if standing == nil {
+		_, err = tx.ExecContext(ctx, `DELETE FROM leaderboard_rankings WHERE sport_id = $1 AND user_id = $2`, sport, userID)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO leaderboard_rankings AS lr (sport_id, user_id, elo, wins, matches_played, rank, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (sport_id, user_id) DO UPDATE SET
+				elo = EXCLUDED.elo,
+				wins = EXCLUDED.wins,
+				matches_played = EXCLUDED.matches_played,
+				rank = EXCLUDED.rank,
+				position = EXCLUDED.position
+		`, sport, userID, standing.elo, standing.wins, standing.matchesPlayed, higher+1, newPosition)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write ranking: %w", err)
+	}
+	return changed + 1, nil
+}
@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/lib/pq"
+)
+
+// LegalRepository handles versioned legal documents
+type LegalRepository struct {
+	db *sql.DB
+}
+
+// NewLegalRepository creates a new LegalRepository instance
+func NewLegalRepository(db *sql.DB) *LegalRepository {
+	return &LegalRepository{db: db}
+}
+
+const legalColumns = `id, doc, lang, version, title, content, published_by, published_at`
+
+func scanLegalDocument(row interface{ Scan(...interface{}) error }) (*models.LegalDocument, error) {
+	doc := &models.LegalDocument{}
+	err := row.Scan(
+		&doc.ID,
+		&doc.Doc,
+		&doc.Lang,
+		&doc.Version,
+		&doc.Title,
+		&doc.Content,
+		&doc.PublishedBy,
+		&doc.PublishedAt,
+	)
+	return doc, err
+}
+
+// GetLatest returns the newest version of a document in a language
+func (r *LegalRepository) GetLatest(ctx context.Context, doc, lang string) (*models.LegalDocument, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT ` + legalColumns + ` FROM legal_documents
+		WHERE doc = $1 AND lang = $2 ORDER BY version DESC LIMIT 1`
+
+	document, err := scanLegalDocument(r.db.QueryRowContext(ctx, query, doc, lang))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("document not found")
+	}
+	return document, err
+}
+
+// GetVersion returns a specific version of a document in a language
+func (r *LegalRepository) GetVersion(ctx context.Context, doc, lang string, version int) (*models.LegalDocument, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT ` + legalColumns + ` FROM legal_documents
+		WHERE doc = $1 AND lang = $2 AND version = $3`
+
+	document, err := scanLegalDocument(r.db.QueryRowContext(ctx, query, doc, lang, version))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("document not found")
+	}
+	return document, err
+}
+
+// ListVersions returns all versions of a document in a language without content, newest first
+func (r *LegalRepository) ListVersions(ctx context.Context, doc, lang string) ([]models.LegalDocument, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, doc, lang, version, title, published_by, published_at
+		FROM legal_documents
+		WHERE doc = $1 AND lang = $2
+		ORDER BY version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, doc, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []models.LegalDocument{}
+	for rows.Next() {
+		var d models.LegalDocument
+		if err := rows.Scan(&d.ID, &d.Doc, &d.Lang, &d.Version, &d.Title, &d.PublishedBy, &d.PublishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan version: %w", err)
+		}
+		versions = append(versions, d)
+	}
+
+	return versions, rows.Err()
+}
+
+// Publish stores a new version, numbered after the latest one in that language
+// Published versions are never changed so consent can reference them
+func (r *LegalRepository) Publish(ctx context.Context, document *models.LegalDocument) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO legal_documents (doc, lang, version, title, content, published_by)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5
+		FROM legal_documents
+		WHERE doc = $1 AND lang = $2
+		RETURNING id, version, published_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, document.Doc, document.Lang, document.Title, document.Content, document.PublishedBy).
+		Scan(&document.ID, &document.Version, &document.PublishedAt)
+	if err != nil {
+		// Two admins publishing at once compete for the same version number
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("version conflict, please retry")
+		}
+		return fmt.Errorf("failed to publish document: %w", err)
+	}
+	return nil
+}
+
+// Accept records that a user accepted a version of a document
+// Accepting the same version again keeps the time of the first acceptance
+func (r *LegalRepository) Accept(ctx context.Context, userID int, document *models.LegalDocument) (*models.LegalAcceptance, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	acceptance := &models.LegalAcceptance{
+		DocumentID: document.ID,
+		Doc:        document.Doc,
+		Lang:       document.Lang,
+		Version:    document.Version,
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO legal_acceptances (user_id, document_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, document_id) DO UPDATE SET accepted_at = legal_acceptances.accepted_at
+		RETURNING accepted_at
+	`, userID, document.ID).Scan(&acceptance.AcceptedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record acceptance: %w", err)
+	}
+	return acceptance, nil
+}
+
+// ListAcceptances returns every version a user accepted, newest first
+func (r *LegalRepository) ListAcceptances(ctx context.Context, userID int) ([]models.LegalAcceptance, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT d.id, d.doc, d.lang, d.version, a.accepted_at
+		FROM legal_acceptances a
+		JOIN legal_documents d ON d.id = a.document_id
+		WHERE a.user_id = $1
+		ORDER BY a.accepted_at DESC, d.id DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list acceptances: %w", err)
+	}
+	defer rows.Close()
+
+	acceptances := []models.LegalAcceptance{}
+	for rows.Next() {
+		var a models.LegalAcceptance
+		if err := rows.Scan(&a.DocumentID, &a.Doc, &a.Lang, &a.Version, &a.AcceptedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan acceptance: %w", err)
+		}
+		acceptances = append(acceptances, a)
+	}
+
+	return acceptances, rows.Err()
+}
+
+// ListPending returns the current versions of docs a user has not accepted yet, without content
+// The current version is the latest one in lang, or in DefaultLegalLang if lang has none; it
+// counts as accepted once the user accepted a version of the document in any language that
+// was published at the same time or later. Documents that were never published are not pending
+func (r *LegalRepository) ListPending(ctx context.Context, userID int, lang string, docs []string) ([]models.LegalDocument, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		WITH current AS (
+			SELECT DISTINCT ON (doc) id, doc, lang, version, title, published_by, published_at
+			FROM legal_documents
+			WHERE doc = ANY($2) AND lang IN ($3, $4)
+			ORDER BY doc, lang = $3 DESC, version DESC
+		)
+		SELECT c.id, c.doc, c.lang, c.version, c.title, c.published_by, c.published_at
+		FROM current c
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM legal_acceptances a
+			JOIN legal_documents d ON d.id = a.document_id
+			WHERE a.user_id = $1 AND d.doc = c.doc AND d.published_at >= c.published_at
+		)
+		ORDER BY c.doc
+	`, userID, pq.Array(docs), lang, models.DefaultLegalLang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending documents: %w", err)
+	}
+	defer rows.Close()
+
+	pending := []models.LegalDocument{}
+	for rows.Next() {
+		var d models.LegalDocument
+		if err := rows.Scan(&d.ID, &d.Doc, &d.Lang, &d.Version, &d.Title, &d.PublishedBy, &d.PublishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		pending = append(pending, d)
+	}
+
+	return pending, rows.Err()
+}
@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// MatchFlagRepository stores the results of the abuse scan
+// Every Flag* method checks the confirmed matches since a cutoff against one rule and
+// flags the matching ones; flagging is idempotent, so overlapping scans are harmless
+type MatchFlagRepository struct {
+	db *sql.DB
+}
+
+// NewMatchFlagRepository creates a new MatchFlagRepository instance
+func NewMatchFlagRepository(db *sql.DB) *MatchFlagRepository {
+	return &MatchFlagRepository{db: db}
+}
+
+// samePair matches o against the players and sport of m, in either order
+const samePair = `
+	o.status = 'confirmed'
+	AND o.deleted_at IS NULL
+	AND o.sport = m.sport
+	AND LEAST(o.player1_id, o.player2_id) = LEAST(m.player1_id, m.player2_id)
+	AND GREATEST(o.player1_id, o.player2_id) = GREATEST(m.player1_id, m.player2_id)
+	AND o.confirmed_at <= m.confirmed_at
+`
+
+// FlagPairFrequency flags matches after which the pair had played at least minMatches
+// confirmed matches within window
+func (r *MatchFlagRepository) FlagPairFrequency(ctx context.Context, since time.Time, window time.Duration, minMatches int) (int64, error) {
+	query := `
+		INSERT INTO match_flags (match_id, rule, details)
+		SELECT m.id, $1, jsonb_build_object('matches', w.played, 'window_hours', $3::int)
+		FROM matches m
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS played
+			FROM matches o
+			WHERE ` + samePair + `
+			  AND o.confirmed_at > m.confirmed_at - make_interval(hours => $3::int)
+		) w
+		WHERE m.status = 'confirmed' AND m.deleted_at IS NULL AND m.confirmed_at >= $2 AND w.played >= $4
+		ON CONFLICT (match_id, rule) DO NOTHING
+	`
+	return r.flag(ctx, query, models.FlagPairFrequency, since, int(window.Hours()), minMatches)
+}
+
+// FlagAlternatingWins flags matches that end a run of streak results of the pair
+// in which the winner changed every time
+func (r *MatchFlagRepository) FlagAlternatingWins(ctx context.Context, since time.Time, streak int) (int64, error) {
+	query := `
+		INSERT INTO match_flags (match_id, rule, details)
+		SELECT m.id, $1, jsonb_build_object('matches', $3::int)
+		FROM matches m
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS played, COUNT(*) FILTER (WHERE winner_id = previous_winner) AS repeats
+			FROM (
+				SELECT winner_id, LAG(winner_id) OVER (ORDER BY confirmed_at, id) AS previous_winner
+				FROM (
+					SELECT o.winner_id, o.confirmed_at, o.id
+					FROM matches o
+					WHERE ` + samePair + `
+					ORDER BY o.confirmed_at DESC, o.id DESC
+					LIMIT $3::int
+				) latest
+			) results
+		) a
+		WHERE m.status = 'confirmed' AND m.deleted_at IS NULL AND m.confirmed_at >= $2 AND a.played = $3::int AND a.repeats = 0
+		ON CONFLICT (match_id, rule) DO NOTHING
+	`
+	return r.flag(ctx, query, models.FlagAlternatingWins, since, streak)
+}
+
+// winnerGap is how much higher the winner of o was rated than the loser before the match
+const winnerGap = `
+	CASE WHEN o.winner_id = o.player1_id
+	     THEN o.player1_elo_before - o.player2_elo_before
+	     ELSE o.player2_elo_before - o.player1_elo_before END
+`
+
+// FlagELOFarming flags matches after which the winner had gained at least minGain ELO within
+// window from wins over the same opponent, each against an opponent rated at least minGap lower
+func (r *MatchFlagRepository) FlagELOFarming(ctx context.Context, since time.Time, minGap int, window time.Duration, minGain int) (int64, error) {
+	query := `
+		INSERT INTO match_flags (match_id, rule, details)
+		SELECT m.id, $1, jsonb_build_object('wins', f.wins, 'gained', f.gained, 'rating_gap', f.gap, 'window_days', $4::int)
+		FROM matches m
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS wins,
+			       COALESCE(SUM(CASE WHEN o.winner_id = o.player1_id THEN o.player1_elo_delta ELSE o.player2_elo_delta END), 0) AS gained,
+			       MAX(` + winnerGap + `) FILTER (WHERE o.id = m.id) AS gap
+			FROM matches o
+			WHERE ` + samePair + `
+			  AND o.winner_id = m.winner_id
+			  AND o.confirmed_at > m.confirmed_at - make_interval(days => $4::int)
+			  AND ` + winnerGap + ` >= $3
+		) f
+		WHERE m.status = 'confirmed' AND m.deleted_at IS NULL AND m.confirmed_at >= $2 AND f.gap IS NOT NULL AND f.gained >= $5
+		ON CONFLICT (match_id, rule) DO NOTHING
+	`
+	return r.flag(ctx, query, models.FlagELOFarming, since, minGap, int(window.Hours()/24), minGain)
+}
+
+// flag runs one rule query and returns the number of newly flagged matches
+func (r *MatchFlagRepository) flag(ctx context.Context, query, rule string, args ...interface{}) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, query, append([]interface{}{rule}, args...)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to flag matches (%s): %w", rule, err)
+	}
+	return result.RowsAffected()
+}
+
+const matchFlagColumns = `
+	f.id, f.match_id, f.rule, f.details, f.status, f.reviewed_by, f.reviewed_at, f.created_at,
+	m.sport, m.player1_id, m.player2_id, m.winner_id, m.confirmed_at
+`
+
+func scanMatchFlag(row interface{ Scan(...interface{}) error }) (*models.MatchFlag, error) {
+	flag := &models.MatchFlag{}
+	var details []byte
+	err := row.Scan(
+		&flag.ID,
+		&flag.MatchID,
+		&flag.Rule,
+		&details,
+		&flag.Status,
+		&flag.ReviewedBy,
+		&flag.ReviewedAt,
+		&flag.CreatedAt,
+		&flag.Sport,
+		&flag.Player1ID,
+		&flag.Player2ID,
+		&flag.WinnerID,
+		&flag.ConfirmedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if details != nil {
+		if err := json.Unmarshal(details, &flag.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode flag details: %w", err)
+		}
+	}
+	return flag, nil
+}
+
+// List returns flags with the given status, oldest first
+func (r *MatchFlagRepository) List(ctx context.Context, status string, limit, offset int) ([]models.MatchFlag, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + matchFlagColumns + `
+		FROM match_flags f
+		JOIN matches m ON m.id = f.match_id
+		WHERE f.status = $1 AND m.deleted_at IS NULL
+		ORDER BY f.created_at ASC, f.id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list match flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := []models.MatchFlag{}
+	for rows.Next() {
+		flag, err := scanMatchFlag(rows)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, *flag)
+	}
+	return flags, rows.Err()
+}
+
+// Review closes an open flag as dismissed or confirmed
+// Fails with "flag not found" or "flag is not open" if it was already reviewed
+func (r *MatchFlagRepository) Review(ctx context.Context, id int, status string, reviewedBy int) (*models.MatchFlag, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		WITH reviewed AS (
+			UPDATE match_flags SET status = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP
+			WHERE id = $3 AND status = 'open'
+			RETURNING *
+		)
+		SELECT ` + matchFlagColumns + `
+		FROM reviewed f
+		JOIN matches m ON m.id = f.match_id
+	`
+
+	flag, err := scanMatchFlag(r.db.QueryRowContext(ctx, query, status, reviewedBy, id))
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM match_flags WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to review match flag: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("flag not found")
+		}
+		return nil, fmt.Errorf("flag is not open")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review match flag: %w", err)
+	}
+	return flag, nil
+}
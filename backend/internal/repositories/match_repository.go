@@ -1,28 +1,56 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/lib/pq"
 )
 
+// ErrMatchStatusChanged is returned when a match left the status a change was based on,
+// e.g. because it was confirmed or cancelled concurrently
+var ErrMatchStatusChanged = errors.New("match was changed meanwhile, reload it and try again")
+
+// MatchRepository stores matches
+// The leaderboard, match feed and statistics reads, the methods taking a context,
+// go to the read replica unless the context asks for the primary (see ReadFromPrimary);
+// writes and everything else use the primary
 type MatchRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	replica *sql.DB
 }
 
-func NewMatchRepository(db *sql.DB) *MatchRepository {
-	return &MatchRepository{db: db}
+// NewMatchRepository creates a match repository, a nil replica reads from the primary
+func NewMatchRepository(db, replica *sql.DB) *MatchRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &MatchRepository{db: db, replica: replica}
+}
+
+// reader returns the database a replica-safe read runs on
+func (r *MatchRepository) reader(ctx context.Context) *sql.DB {
+	if readsFromPrimary(ctx) {
+		return r.db
+	}
+	return r.replica
 }
 
 // Create creates a new match
-func (r *MatchRepository) Create(tx *sql.Tx, match *models.Match) error {
+func (r *MatchRepository) Create(ctx context.Context, tx *sql.Tx, match *models.Match) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO matches (
 			sport, player1_id, player2_id, player1_score, player2_score,
-			winner_id, status, submitted_by, context
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			winner_id, status, submitted_by, context, submit_fingerprint,
+			result, forfeited_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -31,7 +59,7 @@ func (r *MatchRepository) Create(tx *sql.Tx, match *models.Match) error {
 	}
 
 	if tx != nil {
-		scanner = tx.QueryRow(
+		scanner = tx.QueryRowContext(ctx,
 			query,
 			match.Sport,
 			match.Player1ID,
@@ -42,9 +70,12 @@ func (r *MatchRepository) Create(tx *sql.Tx, match *models.Match) error {
 			match.Status,
 			match.SubmittedBy,
 			match.Context,
+			match.SubmitFingerprint,
+			matchResult(match),
+			match.ForfeitedBy,
 		)
 	} else {
-		scanner = r.db.QueryRow(
+		scanner = r.db.QueryRowContext(ctx,
 			query,
 			match.Sport,
 			match.Player1ID,
@@ -55,24 +86,86 @@ func (r *MatchRepository) Create(tx *sql.Tx, match *models.Match) error {
 			match.Status,
 			match.SubmittedBy,
 			match.Context,
+			match.SubmitFingerprint,
+			matchResult(match),
+			match.ForfeitedBy,
 		)
 	}
 
 	return scanner.Scan(&match.ID, &match.CreatedAt, &match.UpdatedAt)
 }
 
-// GetByID retrieves a match by ID
-func (r *MatchRepository) GetByID(id int) (*models.Match, error) {
+// Import inserts a historical match with its own timestamps; ELO data is left to a replay
+func (r *MatchRepository) Import(ctx context.Context, tx *sql.Tx, match *models.Match) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO matches (
+			sport, player1_id, player2_id, player1_score, player2_score,
+			winner_id, status, submitted_by, context, result,
+			confirmed_at, denied_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+
+	return tx.QueryRowContext(ctx, query,
+		match.Sport, match.Player1ID, match.Player2ID, match.Player1Score, match.Player2Score,
+		match.WinnerID, match.Status, match.SubmittedBy, match.Context, matchResult(match),
+		match.ConfirmedAt, match.DeniedAt, match.CreatedAt, match.UpdatedAt,
+	).Scan(&match.ID)
+}
+
+// FindDuplicate returns the ID of a live match, archived ones included, between the same
+// players with the same score created at the same time, or 0 if there is none
+func (r *MatchRepository) FindDuplicate(ctx context.Context, tx *sql.Tx, match *models.Match) (int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id FROM matches_all
+		WHERE sport = $1 AND created_at = $6
+		  AND ((player1_id = $2 AND player2_id = $3 AND player1_score = $4 AND player2_score = $5)
+		    OR (player1_id = $3 AND player2_id = $2 AND player1_score = $5 AND player2_score = $4))
+		LIMIT 1
+	`
+
+	var id int
+	err := tx.QueryRowContext(ctx, query,
+		match.Sport, match.Player1ID, match.Player2ID, match.Player1Score, match.Player2Score, match.CreatedAt,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// matchResult defaults the result of new matches to played
+func matchResult(match *models.Match) string {
+	if match.Result == "" {
+		return models.ResultPlayed
+	}
+	return match.Result
+}
+
+// GetByID retrieves a match by ID, including archived matches
+// Archived matches are always finished, so status checks keep them read-only
+// Soft-deleted matches are not found
+func (r *MatchRepository) GetByID(ctx context.Context, id int) (*models.Match, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	match := &models.Match{}
 	query := `
 		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
 		       winner_id, status, context, player1_elo_before, player1_elo_after, player1_elo_delta,
 		       player2_elo_before, player2_elo_after, player2_elo_delta,
-		       submitted_by, confirmed_at, denied_at, created_at, updated_at
-		FROM matches WHERE id = $1
+		       submitted_by, confirmed_at, denied_at, created_at, updated_at, result, forfeited_by,
+		       player1_k_factor, player2_k_factor
+		FROM matches_all WHERE id = $1
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&match.ID,
 		&match.Sport,
 		&match.Player1ID,
@@ -93,6 +186,10 @@ func (r *MatchRepository) GetByID(id int) (*models.Match, error) {
 		&match.DeniedAt,
 		&match.CreatedAt,
 		&match.UpdatedAt,
+		&match.Result,
+		&match.ForfeitedBy,
+		&match.Player1KFactor,
+		&match.Player2KFactor,
 	)
 
 	if err == sql.ErrNoRows {
@@ -103,21 +200,26 @@ func (r *MatchRepository) GetByID(id int) (*models.Match, error) {
 }
 
 // GetPendingMatchBetweenPlayers checks for pending match between two players
-func (r *MatchRepository) GetPendingMatchBetweenPlayers(player1ID, player2ID int, sport string) (*models.Match, error) {
+func (r *MatchRepository) GetPendingMatchBetweenPlayers(ctx context.Context, player1ID, player2ID int, sport string) (*models.Match, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	match := &models.Match{}
 	query := `
 		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
 		       winner_id, status, context, player1_elo_before, player1_elo_after, player1_elo_delta,
 		       player2_elo_before, player2_elo_after, player2_elo_delta,
-		       submitted_by, confirmed_at, denied_at, created_at, updated_at
+		       submitted_by, confirmed_at, denied_at, created_at, updated_at, result, forfeited_by,
+		       player1_k_factor, player2_k_factor
 		FROM matches
 		WHERE sport = $1
 		  AND status = $2
+		  AND deleted_at IS NULL
 		  AND ((player1_id = $3 AND player2_id = $4) OR (player1_id = $4 AND player2_id = $3))
 		LIMIT 1
 	`
 
-	err := r.db.QueryRow(query, sport, models.StatusPending, player1ID, player2ID).Scan(
+	err := r.db.QueryRowContext(ctx, query, sport, models.StatusPending, player1ID, player2ID).Scan(
 		&match.ID,
 		&match.Sport,
 		&match.Player1ID,
@@ -138,6 +240,10 @@ func (r *MatchRepository) GetPendingMatchBetweenPlayers(player1ID, player2ID int
 		&match.DeniedAt,
 		&match.CreatedAt,
 		&match.UpdatedAt,
+		&match.Result,
+		&match.ForfeitedBy,
+		&match.Player1KFactor,
+		&match.Player2KFactor,
 	)
 
 	if err == sql.ErrNoRows {
@@ -147,8 +253,41 @@ func (r *MatchRepository) GetPendingMatchBetweenPlayers(player1ID, player2ID int
 	return match, err
 }
 
+// GetPendingStatsBetweenPlayers returns how many pending matches two players have in a sport
+// and when the most recent one was submitted (nil if none)
+func (r *MatchRepository) GetPendingStatsBetweenPlayers(ctx context.Context, player1ID, player2ID int, sport string) (int, *time.Time, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT COUNT(*), MAX(created_at)
+		FROM matches
+		WHERE sport = $1
+		  AND status = $2
+		  AND deleted_at IS NULL
+		  AND ((player1_id = $3 AND player2_id = $4) OR (player1_id = $4 AND player2_id = $3))
+	`
+
+	var count int
+	var latest sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, sport, models.StatusPending, player1ID, player2ID).Scan(&count, &latest); err != nil {
+		return 0, nil, err
+	}
+	if !latest.Valid {
+		return count, nil, nil
+	}
+
+	return count, &latest.Time, nil
+}
+
 // ConfirmMatch confirms a match and updates ELO
-func (r *MatchRepository) ConfirmMatch(tx *sql.Tx, matchID int, eloData map[string]int) error {
+// from is the status the confirmation was decided on; if the match no longer has it,
+// ErrMatchStatusChanged is returned and nothing is written
+// confirmFingerprint is the hashed client fingerprint of the confirming user (may be nil)
+func (r *MatchRepository) ConfirmMatch(ctx context.Context, tx *sql.Tx, matchID int, from string, eloData map[string]int, confirmFingerprint *string) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	now := time.Now()
 	query := `
 		UPDATE matches SET
@@ -159,154 +298,868 @@ func (r *MatchRepository) ConfirmMatch(tx *sql.Tx, matchID int, eloData map[stri
 			player1_elo_delta = $5,
 			player2_elo_before = $6,
 			player2_elo_after = $7,
-			player2_elo_delta = $8
-		WHERE id = $9
+			player2_elo_delta = $8,
+			confirm_fingerprint = $10,
+			player1_k_factor = $11,
+			player2_k_factor = $12
+		WHERE id = $9 AND status = $13
 	`
+	args := []interface{}{
+		models.StatusConfirmed,
+		now,
+		eloData["player1_before"],
+		eloData["player1_after"],
+		eloData["player1_delta"],
+		eloData["player2_before"],
+		eloData["player2_after"],
+		eloData["player2_delta"],
+		matchID,
+		confirmFingerprint,
+		eloData["player1_k"],
+		eloData["player2_k"],
+		from,
+	}
 
+	var result sql.Result
 	var err error
 	if tx != nil {
-		_, err = tx.Exec(
-			query,
-			models.StatusConfirmed,
-			now,
-			eloData["player1_before"],
-			eloData["player1_after"],
-			eloData["player1_delta"],
-			eloData["player2_before"],
-			eloData["player2_after"],
-			eloData["player2_delta"],
-			matchID,
-		)
+		result, err = tx.ExecContext(ctx, query, args...)
 	} else {
-		_, err = r.db.Exec(
-			query,
-			models.StatusConfirmed,
-			now,
-			eloData["player1_before"],
-			eloData["player1_after"],
-			eloData["player1_delta"],
-			eloData["player2_before"],
-			eloData["player2_after"],
-			eloData["player2_delta"],
-			matchID,
-		)
+		result, err = r.db.ExecContext(ctx, query, args...)
 	}
-
-	return err
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrMatchStatusChanged
+	}
+	return nil
 }
 
 // DenyMatch denies a match
-func (r *MatchRepository) DenyMatch(matchID int) error {
+func (r *MatchRepository) DenyMatch(ctx context.Context, tx *sql.Tx, matchID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	now := time.Now()
 	query := `UPDATE matches SET status = $1, denied_at = $2 WHERE id = $3`
-	_, err := r.db.Exec(query, models.StatusDenied, now, matchID)
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, models.StatusDenied, now, matchID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, models.StatusDenied, now, matchID)
+	}
 	return err
 }
 
-// GetLeaderboardEntries retrieves all users with their match statistics in a single optimized query
-// This eliminates the N+1 query problem by using aggregation
-func (r *MatchRepository) GetLeaderboardEntries(sport string) ([]models.LeaderboardEntry, error) {
-	// Single query that gets all users and their match statistics
-	query := `
-		WITH user_stats AS (
-			SELECT
-				u.id,
-				u.id as intra_id,
-				u.login,
-				u.display_name,
-				u.avatar_url,
-				u.campus,
-				u.table_tennis_elo,
-				u.table_football_elo,
-				u.created_at,
-				u.updated_at,
-				COALESCE(COUNT(m.id), 0) as matches_played,
-				COALESCE(SUM(CASE WHEN m.winner_id = u.id THEN 1 ELSE 0 END), 0) as wins
-			FROM users u
-			LEFT JOIN matches m ON (m.player1_id = u.id OR m.player2_id = u.id)
-				AND m.sport = $1
-				AND m.status = $2
-			WHERE u.id != -1
-			GROUP BY u.id, u.login, u.display_name, u.avatar_url, u.campus,
-				u.table_tennis_elo, u.table_football_elo, u.created_at, u.updated_at
+// UpdateScores replaces the scores and winner of a match
+func (r *MatchRepository) UpdateScores(ctx context.Context, tx *sql.Tx, matchID, player1Score, player2Score, winnerID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `UPDATE matches SET player1_score = $1, player2_score = $2, winner_id = $3, updated_at = $4 WHERE id = $5`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, player1Score, player2Score, winnerID, time.Now(), matchID)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, player1Score, player2Score, winnerID, time.Now(), matchID)
+	}
+	return err
+}
+
+// CreateSets stores the set breakdown of a best-of-N series
+func (r *MatchRepository) CreateSets(ctx context.Context, tx *sql.Tx, matchID int, sets []models.MatchSet) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	for _, set := range sets {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO match_sets (match_id, set_number, player1_score, player2_score) VALUES ($1, $2, $3, $4)`,
+			matchID, set.SetNumber, set.Player1Score, set.Player2Score,
 		)
+		if err != nil {
+			return fmt.Errorf("failed to store match sets: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetSets returns the set breakdown of a match in playing order
+// Matches that were not played as a series have no sets
+func (r *MatchRepository) GetSets(ctx context.Context, matchID int) ([]models.MatchSet, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT set_number, player1_score, player2_score
+		FROM match_sets
+		WHERE match_id = $1
+		ORDER BY set_number
+	`, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sets []models.MatchSet
+	for rows.Next() {
+		var set models.MatchSet
+		if err := rows.Scan(&set.SetNumber, &set.Player1Score, &set.Player2Score); err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, rows.Err()
+}
+
+// DeleteSets drops the set breakdown once the score of a match is replaced
+func (r *MatchRepository) DeleteSets(ctx context.Context, tx *sql.Tx, matchID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM match_sets WHERE match_id = $1`, matchID)
+	return err
+}
+
+// CorrectResult saves the corrected result and ELO data of a confirmed match
+// match.UpdatedAt is the one the correction was computed from; ErrMatchStatusChanged is
+// returned if the match was changed, corrected or reverted since
+func (r *MatchRepository) CorrectResult(ctx context.Context, tx *sql.Tx, match *models.Match) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE matches SET
+			player1_score = $1,
+			player2_score = $2,
+			winner_id = $3,
+			forfeited_by = $4,
+			player1_elo_after = $5,
+			player1_elo_delta = $6,
+			player2_elo_after = $7,
+			player2_elo_delta = $8,
+			updated_at = $9
+		WHERE id = $10 AND status = $11 AND updated_at = $12
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		match.Player1Score, match.Player2Score, match.WinnerID, match.ForfeitedBy,
+		match.Player1ELOAfter, match.Player1ELODelta, match.Player2ELOAfter, match.Player2ELODelta,
+		time.Now(), match.ID, models.StatusConfirmed, match.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to correct match: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrMatchStatusChanged
+	}
+	return err
+}
+
+// CreateCounterProposal stores a corrected score proposed when denying a match
+func (r *MatchRepository) CreateCounterProposal(ctx context.Context, tx *sql.Tx, proposal *models.CounterProposal) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO match_counter_proposals (match_id, player1_score, player2_score, proposed_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING status, created_at
+	`
+
+	var scanner interface{ Scan(...interface{}) error }
+	if tx != nil {
+		scanner = tx.QueryRowContext(ctx, query, proposal.MatchID, proposal.Player1Score, proposal.Player2Score, proposal.ProposedBy)
+	} else {
+		scanner = r.db.QueryRowContext(ctx, query, proposal.MatchID, proposal.Player1Score, proposal.Player2Score, proposal.ProposedBy)
+	}
+
+	return scanner.Scan(&proposal.Status, &proposal.CreatedAt)
+}
+
+// GetCounterProposal returns the counter-proposal of a match, or nil if there is none
+func (r *MatchRepository) GetCounterProposal(ctx context.Context, matchID int) (*models.CounterProposal, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	proposal := &models.CounterProposal{}
+	query := `
+		SELECT match_id, player1_score, player2_score, proposed_by, status, created_at, resolved_at
+		FROM match_counter_proposals WHERE match_id = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, matchID).Scan(
+		&proposal.MatchID,
+		&proposal.Player1Score,
+		&proposal.Player2Score,
+		&proposal.ProposedBy,
+		&proposal.Status,
+		&proposal.CreatedAt,
+		&proposal.ResolvedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return proposal, err
+}
+
+// ResolveCounterProposal marks a pending counter-proposal as accepted or rejected
+func (r *MatchRepository) ResolveCounterProposal(ctx context.Context, tx *sql.Tx, matchID int, status string) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE match_counter_proposals SET status = $1, resolved_at = $2
+		WHERE match_id = $3 AND status = $4
+	`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, query, status, time.Now(), matchID, models.CounterProposalPending)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, status, time.Now(), matchID, models.CounterProposalPending)
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("counter-proposal is no longer pending")
+	}
+	return nil
+}
+
+// GetLeaderboardEntries returns a ranked page of the leaderboard for a sport
+// Pages through leaderboard_rankings, which LeaderboardRankingRepository keeps ranked:
+// RANK() gives tied ELO the same rank, the position breaks ties by wins, matches played
+// and user ID. limit <= 0 returns everyone. Players who have not finished the sport's
+// placement matches and banned players are not ranked.
+// Also returns the total number of ranked players for pagination.
+func (r *MatchRepository) GetLeaderboardEntries(ctx context.Context, sport string, limit, offset int) ([]models.LeaderboardEntry, int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
 		SELECT
-			id, intra_id, login, display_name, avatar_url, campus,
-			table_tennis_elo, table_football_elo, created_at, updated_at,
-			matches_played, wins
-		FROM user_stats
+			lr.rank,
+			u.id, u.login, u.display_name, u.avatar_url, u.campus,
+			u.created_at, u.updated_at,
+			lr.elo, lr.matches_played, lr.wins,
+			COALESCE(us.losses, 0) AS losses,
+			COALESCE(us.current_streak, 0) AS current_streak,
+			COALESCE(us.longest_win_streak, 0) AS longest_win_streak,
+			s.k_factor_strategy = 'tiered' AND lr.matches_played < s.provisional_matches AS is_provisional
+		FROM leaderboard_rankings lr
+		JOIN users u ON u.id = lr.user_id
+		JOIN sports s ON s.id = lr.sport_id
+		LEFT JOIN user_sports us ON us.user_id = lr.user_id AND us.sport_id = lr.sport_id
+		WHERE lr.sport_id = $1 AND lr.position > $3
+		ORDER BY lr.position
+		LIMIT $2
 	`
 
-	rows, err := r.db.Query(query, sport, models.StatusConfirmed)
+	// LIMIT NULL means no limit
+	var limitArg interface{}
+	if limit > 0 {
+		limitArg = limit
+	}
+
+	// Positions run from 1 to the number of ranked players
+	var total int
+	if err := r.reader(ctx).QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(position), 0) FROM leaderboard_rankings WHERE sport_id = $1
+	`, sport).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, sport, limitArg, offset)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var entries []models.LeaderboardEntry
+	entries := []models.LeaderboardEntry{}
 	for rows.Next() {
-		var user models.User
-		var matchesPlayed, wins int
+		var entry models.LeaderboardEntry
+		user := &entry.User
 
 		if err := rows.Scan(
+			&entry.Rank,
 			&user.ID,
-			&user.IntraID,
 			&user.Login,
 			&user.DisplayName,
 			&user.AvatarURL,
 			&user.Campus,
-			&user.TableTennisELO,
-			&user.TableFootballELO,
 			&user.CreatedAt,
 			&user.UpdatedAt,
-			&matchesPlayed,
-			&wins,
+			&entry.ELO,
+			&entry.MatchesPlayed,
+			&entry.Wins,
+			&entry.Losses,
+			&entry.CurrentStreak,
+			&entry.LongestWinStreak,
+			&entry.IsProvisional,
 		); err != nil {
+			return nil, 0, err
+		}
+
+		user.IntraID = user.ID
+		if entry.MatchesPlayed > 0 {
+			entry.WinRate = float64(entry.Wins) / float64(entry.MatchesPlayed) * 100
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// GetUnrankedPlayers returns a page of the players who started but have not finished the
+// placement matches of a sport, most matches played first, and their total number
+func (r *MatchRepository) GetUnrankedPlayers(ctx context.Context, sport string, limit, offset int) ([]models.UnrankedEntry, int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			COUNT(*) OVER () AS total,
+			u.id, u.login, u.display_name, u.avatar_url, u.campus,
+			u.created_at, u.updated_at,
+			us.matches_played, s.placement_matches - us.matches_played, us.last_match_at
+		FROM user_sports us
+		JOIN users u ON u.id = us.user_id
+		JOIN sports s ON s.id = us.sport_id
+		WHERE us.sport_id = $1 AND u.id != -1 AND u.deactivated_at IS NULL
+		  AND us.matches_played > 0 AND us.matches_played < s.placement_matches
+		ORDER BY us.matches_played DESC, us.last_match_at DESC NULLS LAST, u.id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, sport, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.UnrankedEntry{}
+	total := 0
+	for rows.Next() {
+		var entry models.UnrankedEntry
+		user := &entry.User
+
+		if err := rows.Scan(
+			&total,
+			&user.ID,
+			&user.Login,
+			&user.DisplayName,
+			&user.AvatarURL,
+			&user.Campus,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&entry.MatchesPlayed,
+			&entry.PlacementMatchesRemaining,
+			&entry.LastMatchAt,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		user.IntraID = user.ID
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// An offset past the end returns no rows and therefore no window total
+	if len(entries) == 0 && offset > 0 {
+		err := r.reader(ctx).QueryRowContext(ctx, `
+			SELECT COUNT(*)
+			FROM user_sports us
+			JOIN users u ON u.id = us.user_id
+			JOIN sports s ON s.id = us.sport_id
+			WHERE us.sport_id = $1 AND u.id != -1 AND u.deactivated_at IS NULL
+			  AND us.matches_played > 0 AND us.matches_played < s.placement_matches
+		`, sport).Scan(&total)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return entries, total, nil
+}
+
+// GetCoalitionStandings ranks the coalitions of a sport by the average ELO of their
+// active players (at least one match); members without a match only count as members
+func (r *MatchRepository) GetCoalitionStandings(ctx context.Context, sport string) ([]models.CoalitionStanding, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		WITH standings AS (
+			SELECT
+				u.coalition_id,
+				COALESCE(us.current_elo, s.default_elo) AS elo,
+				COALESCE(us.matches_played, 0) AS matches_played,
+				COALESCE(us.wins, 0) AS wins
+			FROM users u
+			JOIN sports s ON s.id = $1
+			LEFT JOIN user_sports us ON us.user_id = u.id AND us.sport_id = s.id
+			WHERE u.id != -1 AND u.coalition_id IS NOT NULL
+		), coalition_totals AS (
+			SELECT
+				coalition_id,
+				COUNT(*) AS members,
+				COUNT(*) FILTER (WHERE matches_played > 0) AS active_players,
+				COALESCE(ROUND(AVG(elo) FILTER (WHERE matches_played > 0)), 0)::INTEGER AS average_elo,
+				COALESCE(MAX(elo) FILTER (WHERE matches_played > 0), 0) AS top_elo,
+				SUM(matches_played) AS matches_played,
+				SUM(wins) AS wins
+			FROM standings
+			GROUP BY coalition_id
+		)
+		SELECT
+			RANK() OVER (ORDER BY t.average_elo DESC) AS rank,
+			c.id, c.name, c.slug, c.color, c.image_url,
+			t.members, t.active_players, t.average_elo, t.top_elo, t.matches_played, t.wins
+		FROM coalition_totals t
+		JOIN coalitions c ON c.id = t.coalition_id
+		ORDER BY t.average_elo DESC, t.wins DESC, c.id ASC
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, sport)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	standings := []models.CoalitionStanding{}
+	for rows.Next() {
+		var standing models.CoalitionStanding
+		coalition := &standing.Coalition
+		if err := rows.Scan(
+			&standing.Rank,
+			&coalition.ID,
+			&coalition.Name,
+			&coalition.Slug,
+			&coalition.Color,
+			&coalition.ImageURL,
+			&standing.Members,
+			&standing.ActivePlayers,
+			&standing.AverageELO,
+			&standing.TopELO,
+			&standing.MatchesPlayed,
+			&standing.Wins,
+		); err != nil {
+			return nil, err
+		}
+		standings = append(standings, standing)
+	}
+	return standings, rows.Err()
+}
+
+// activePlayers selects the rating of every player of sport $1 with at least one match
+const activePlayers = `
+	SELECT us.user_id, us.current_elo AS elo
+	FROM user_sports us
+	WHERE us.sport_id = $1 AND us.matches_played > 0 AND us.user_id != -1
+`
+
+// GetELODistribution returns the rating histogram and percentiles of the active players of a sport
+// Buckets are bucketSize wide and contiguous from the lowest to the highest rating
+func (r *MatchRepository) GetELODistribution(ctx context.Context, sport string, bucketSize int) (*models.ELODistribution, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	dist := &models.ELODistribution{Sport: sport, BucketSize: bucketSize, Buckets: []models.ELOBucket{}}
+
+	query := `
+		SELECT COUNT(*), COALESCE(AVG(elo), 0),
+		       percentile_cont(ARRAY[0.1, 0.25, 0.5, 0.75, 0.9]) WITHIN GROUP (ORDER BY elo)
+		FROM (` + activePlayers + `) p
+	`
+	var percentiles pq.Float64Array
+	if err := r.reader(ctx).QueryRowContext(ctx, query, sport).Scan(&dist.Players, &dist.Average, &percentiles); err != nil {
+		return nil, fmt.Errorf("failed to compute elo percentiles: %w", err)
+	}
+	if dist.Players == 0 {
+		return dist, nil
+	}
+	dist.P10, dist.P25, dist.Median, dist.P75, dist.P90 = percentiles[0], percentiles[1], percentiles[2], percentiles[3], percentiles[4]
+
+	query = `
+		SELECT FLOOR(elo::numeric / $2::int)::int * $2::int AS bucket, COUNT(*)
+		FROM (` + activePlayers + `) p
+		GROUP BY bucket
+		ORDER BY bucket
+	`
+	rows, err := r.reader(ctx).QueryContext(ctx, query, sport, bucketSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute elo histogram: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var low, players int
+		if err := rows.Scan(&low, &players); err != nil {
 			return nil, err
 		}
+		// Fill the gap since the previous bucket with empty ones
+		for n := len(dist.Buckets); n > 0 && dist.Buckets[n-1].Max < low; n++ {
+			prev := dist.Buckets[n-1].Max
+			dist.Buckets = append(dist.Buckets, models.ELOBucket{Min: prev, Max: prev + bucketSize})
+		}
+		dist.Buckets = append(dist.Buckets, models.ELOBucket{Min: low, Max: low + bucketSize, Players: players})
+	}
+	return dist, rows.Err()
+}
+
+// GetELOPercentile returns a player's rating in a sport and the share (0-100) of the other
+// active players rated lower; ok is false if the player has no match in that sport
+func (r *MatchRepository) GetELOPercentile(ctx context.Context, sport string, userID int) (elo int, percentile float64, ok bool, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT elo, percentile
+		FROM (
+			SELECT user_id, elo, PERCENT_RANK() OVER (ORDER BY elo) * 100 AS percentile
+			FROM (` + activePlayers + `) p
+		) ranked
+		WHERE user_id = $2
+	`
+	err = r.reader(ctx).QueryRowContext(ctx, query, sport, userID).Scan(&elo, &percentile)
+	if err == sql.ErrNoRows {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("failed to compute elo percentile: %w", err)
+	}
+	return elo, percentile, true, nil
+}
 
-		losses := matchesPlayed - wins
-		winRate := 0.0
-		if matchesPlayed > 0 {
-			winRate = float64(wins) / float64(matchesPlayed) * 100
+// GetActivityHeatmap counts the confirmed matches of a sport submitted since a time, archived
+// ones included, by weekday and hour in the given IANA time zone
+// Fails with "unknown time zone" if PostgreSQL does not know tz
+func (r *MatchRepository) GetActivityHeatmap(ctx context.Context, sport string, since time.Time, tz string) ([][]int, int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT EXTRACT(ISODOW FROM local_hour)::int, EXTRACT(HOUR FROM local_hour)::int, COUNT(*)
+		FROM (
+			SELECT date_trunc('hour', (created_at AT TIME ZONE 'UTC') AT TIME ZONE $3) AS local_hour
+			FROM matches_all
+			WHERE sport = $1 AND status = 'confirmed' AND created_at >= $2
+		) m
+		GROUP BY 1, 2
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, sport, since, tz)
+	if err != nil {
+		// invalid_parameter_value is raised for unrecognized time zones
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "22023" {
+			return nil, 0, fmt.Errorf("unknown time zone")
 		}
+		return nil, 0, fmt.Errorf("failed to get activity heatmap: %w", err)
+	}
+	defer rows.Close()
 
-		var elo int
-		if sport == models.SportTableTennis {
-			elo = user.TableTennisELO
-		} else {
-			elo = user.TableFootballELO
+	counts := make([][]int, 7)
+	for i := range counts {
+		counts[i] = make([]int, 24)
+	}
+	total := 0
+	for rows.Next() {
+		var weekday, hour, matches int
+		if err := rows.Scan(&weekday, &hour, &matches); err != nil {
+			return nil, 0, err
 		}
+		counts[weekday-1][hour] = matches
+		total += matches
+	}
+	return counts, total, rows.Err()
+}
 
-		entries = append(entries, models.LeaderboardEntry{
-			User:          user,
-			ELO:           elo,
-			MatchesPlayed: matchesPlayed,
-			Wins:          wins,
-			Losses:        losses,
-			WinRate:       winRate,
-		})
+// GetSportSummaries returns the match and rating figures of every active sport in display order
+// Leaders are left to the caller, which has the ranked leaderboard at hand
+func (r *MatchRepository) GetSportSummaries(ctx context.Context, since time.Time) ([]models.SportSummary, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT s.id, COALESCE(m.matches, 0), COALESCE(m.this_week, 0), COALESCE(p.average_elo, 0)
+		FROM sports s
+		LEFT JOIN (
+			SELECT sport, COUNT(*) AS matches, COUNT(*) FILTER (WHERE confirmed_at >= $1) AS this_week
+			FROM matches_all
+			WHERE status = 'confirmed'
+			GROUP BY sport
+		) m ON m.sport = s.id
+		LEFT JOIN (
+			SELECT sport_id, ROUND(AVG(current_elo))::INTEGER AS average_elo
+			FROM user_sports
+			WHERE matches_played > 0 AND user_id != -1
+			GROUP BY sport_id
+		) p ON p.sport_id = s.id
+		WHERE s.is_active
+		ORDER BY s.sort_order, s.id
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sport summaries: %w", err)
 	}
+	defer rows.Close()
 
-	return entries, rows.Err()
+	summaries := []models.SportSummary{}
+	for rows.Next() {
+		var summary models.SportSummary
+		if err := rows.Scan(&summary.Sport, &summary.Matches, &summary.MatchesThisWeek, &summary.AverageELO); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+// GetMostActivePlayer returns the player with the most confirmed matches since a time across
+// all sports and that number; ok is false if nobody played
+func (r *MatchRepository) GetMostActivePlayer(ctx context.Context, since time.Time) (userID, matches int, ok bool, err error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT p.user_id, COUNT(*) AS matches
+		FROM matches m
+		CROSS JOIN LATERAL (VALUES (m.player1_id), (m.player2_id)) AS p(user_id)
+		WHERE m.status = 'confirmed' AND m.deleted_at IS NULL AND m.confirmed_at >= $1 AND p.user_id > 0
+		GROUP BY p.user_id
+		ORDER BY matches DESC, p.user_id
+		LIMIT 1
+	`
+	err = r.reader(ctx).QueryRowContext(ctx, query, since).Scan(&userID, &matches)
+	if err == sql.ErrNoRows {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("failed to get most active player: %w", err)
+	}
+	return userID, matches, true, nil
 }
 
 // CancelMatch cancels a pending match (by submitter)
-func (r *MatchRepository) CancelMatch(matchID int) error {
+func (r *MatchRepository) CancelMatch(ctx context.Context, matchID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `UPDATE matches SET status = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.Exec(query, models.StatusCancelled, time.Now(), matchID)
+	_, err := r.db.ExecContext(ctx, query, models.StatusCancelled, time.Now(), matchID)
 	return err
 }
 
-// GetMatches retrieves matches with filters
-func (r *MatchRepository) GetMatches(userID *int, sport *string, status *string, limit int, offset int) ([]models.Match, error) {
+// SetStatus moves a match from one status to another without touching ratings
+// Returns ErrMatchStatusChanged if the match no longer has status from
+func (r *MatchRepository) SetStatus(ctx context.Context, matchID int, from, to string) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE matches SET
+			status = $1,
+			denied_at = CASE WHEN $1 = 'denied' THEN $2 ELSE denied_at END,
+			updated_at = $2
+		WHERE id = $3 AND status = $4
+	`
+	result, err := r.db.ExecContext(ctx, query, to, time.Now(), matchID, from)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrMatchStatusChanged
+	}
+	return nil
+}
+
+// matchesSource returns the table or view to read matches from
+func matchesSource(includeArchived bool) string {
+	if includeArchived {
+		return "matches_all"
+	}
+	return "matches"
+}
+
+// archiveBatchSize bounds how many matches are moved per statement
+const archiveBatchSize = 1000
+
+// ArchiveMatchesBefore moves finished matches created before the cutoff into matches_archive
+// Comments and reactions stay attached; returns the number of archived matches
+func (r *MatchRepository) ArchiveMatchesBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		WITH moved AS (
+			DELETE FROM matches
+			WHERE id IN (
+				SELECT id FROM matches
+				WHERE status IN ($1, $2, $3) AND created_at < $4
+				ORDER BY id
+				LIMIT $5
+			)
+			RETURNING *
+		)
+		INSERT INTO matches_archive
+		SELECT * FROM moved
+	`
+
+	var total int64
+	for {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return total, err
+		}
+
+		// Tells the delete trigger to keep comments and reactions of moved matches
+		if _, err := tx.ExecContext(ctx, `SET LOCAL elo.archiving = 'on'`); err != nil {
+			tx.Rollback()
+			return total, err
+		}
+
+		result, err := tx.ExecContext(ctx, query, models.StatusConfirmed, models.StatusDenied, models.StatusCancelled, cutoff, archiveBatchSize)
+		if err != nil {
+			tx.Rollback()
+			return total, fmt.Errorf("failed to archive matches: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return total, err
+		}
+
+		moved, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += moved
+		if moved < archiveBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// ExpireStalePending cancels pending matches created before the cutoff
+// Returns the number of matches that were expired
+func (r *MatchRepository) ExpireStalePending(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `UPDATE matches SET status = $1, updated_at = $2 WHERE status = $3 AND created_at < $4 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, models.StatusCancelled, time.Now(), models.StatusPending, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeFingerprints clears client fingerprints of matches created before the cutoff
+// Fingerprints are only needed for recent anomaly review, so they are not kept indefinitely
+func (r *MatchRepository) PurgeFingerprints(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var total int64
+	for _, table := range []string{"matches", "matches_archive"} {
+		query := `
+			UPDATE ` + table + ` SET submit_fingerprint = NULL, confirm_fingerprint = NULL
+			WHERE created_at < $1 AND (submit_fingerprint IS NOT NULL OR confirm_fingerprint IS NOT NULL)
+		`
+		result, err := r.db.ExecContext(ctx, query, cutoff)
+		if err != nil {
+			return total, err
+		}
+		purged, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += purged
+	}
+	return total, nil
+}
+
+// GetMatches retrieves matches with filters, newest first
+// With a cursor, matches after it are returned (keyset pagination) and offset is ignored
+// includeArchived also searches matches archived from past seasons, which is slower
+func (r *MatchRepository) GetMatches(ctx context.Context, userID *int, sport *string, status *string, cursor *models.MatchCursor, limit int, offset int, includeArchived bool) ([]models.Match, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query, args := matchFeedQuery(userID, sport, status, cursor, limit, offset, includeArchived)
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []models.Match
+	for rows.Next() {
+		var match models.Match
+		if err := rows.Scan(
+			&match.ID,
+			&match.Sport,
+			&match.Player1ID,
+			&match.Player2ID,
+			&match.Player1Score,
+			&match.Player2Score,
+			&match.WinnerID,
+			&match.Status,
+			&match.Context,
+			&match.Player1ELOBefore,
+			&match.Player1ELOAfter,
+			&match.Player1ELODelta,
+			&match.Player2ELOBefore,
+			&match.Player2ELOAfter,
+			&match.Player2ELODelta,
+			&match.SubmittedBy,
+			&match.ConfirmedAt,
+			&match.DeniedAt,
+			&match.CreatedAt,
+			&match.UpdatedAt,
+			&match.Result,
+			&match.ForfeitedBy,
+			&match.Player1KFactor,
+			&match.Player2KFactor,
+		); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+// matchFeedQuery builds the filtered, paginated match feed query shared by GetMatches and GetMatchesWithPlayers
+func matchFeedQuery(userID *int, sport *string, status *string, cursor *models.MatchCursor, limit int, offset int, includeArchived bool) (string, []interface{}) {
 	query := `
 		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
 		       winner_id, status, context, player1_elo_before, player1_elo_after, player1_elo_delta,
 		       player2_elo_before, player2_elo_after, player2_elo_delta,
-		       submitted_by, confirmed_at, denied_at, created_at, updated_at
-		FROM matches
-		WHERE 1=1
+		       submitted_by, confirmed_at, denied_at, created_at, updated_at, result, forfeited_by,
+		       player1_k_factor, player2_k_factor
+		FROM ` + matchesSource(includeArchived) + `
+		WHERE deleted_at IS NULL
 	`
 
 	args := []interface{}{}
@@ -330,20 +1183,83 @@ func (r *MatchRepository) GetMatches(userID *int, sport *string, status *string,
 		argCount++
 	}
 
-	query += " ORDER BY created_at DESC"
+	if cursor != nil {
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argCount, argCount+1)
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		argCount += 2
+		offset = 0
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
 	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1)
 	args = append(args, limit, offset)
 
-	rows, err := r.db.Query(query, args...)
+	return query, args
+}
+
+// feedUserColumns lists the user columns joined into the match feed under the given alias
+func feedUserColumns(alias string) string {
+	return fmt.Sprintf(`%[1]s.id, %[1]s.id, %[1]s.login, %[1]s.display_name, %[1]s.avatar_url, %[1]s.campus,
+		       %[1]s.is_admin, %[1]s.is_banned,
+		       %[1]s.ban_reason, %[1]s.banned_at, %[1]s.banned_by, %[1]s.banned_until, %[1]s.deactivated_at, %[1]s.created_at, %[1]s.updated_at`, alias)
+}
+
+// feedUserDest returns the scan destinations matching feedUserColumns
+func feedUserDest(user *models.User) []interface{} {
+	return []interface{}{
+		&user.ID,
+		&user.IntraID,
+		&user.Login,
+		&user.DisplayName,
+		&user.AvatarURL,
+		&user.Campus,
+		&user.IsAdmin,
+		&user.IsBanned,
+		&user.BanReason,
+		&user.BannedAt,
+		&user.BannedBy,
+		&user.BannedUntil,
+		&user.DeactivatedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	}
+}
+
+// GetMatchesWithPlayers is GetMatches with both players, the winner and the submitter joined in
+// Saves clients from resolving player IDs one by one
+func (r *MatchRepository) GetMatchesWithPlayers(ctx context.Context, userID *int, sport *string, status *string, cursor *models.MatchCursor, limit int, offset int, includeArchived bool) ([]models.MatchWithPlayers, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	feed, args := matchFeedQuery(userID, sport, status, cursor, limit, offset, includeArchived)
+	query := `
+		SELECT m.id, m.sport, m.player1_id, m.player2_id, m.player1_score, m.player2_score,
+		       m.winner_id, m.status, m.context, m.player1_elo_before, m.player1_elo_after, m.player1_elo_delta,
+		       m.player2_elo_before, m.player2_elo_after, m.player2_elo_delta,
+		       m.submitted_by, m.confirmed_at, m.denied_at, m.created_at, m.updated_at, m.result, m.forfeited_by,
+		       m.player1_k_factor, m.player2_k_factor,
+		       ` + feedUserColumns("p1") + `,
+		       ` + feedUserColumns("p2") + `,
+		       ` + feedUserColumns("w") + `,
+		       ` + feedUserColumns("s") + `
+		FROM (` + feed + `) m
+		JOIN users p1 ON p1.id = m.player1_id
+		JOIN users p2 ON p2.id = m.player2_id
+		JOIN users w ON w.id = m.winner_id
+		JOIN users s ON s.id = m.submitted_by
+		ORDER BY m.created_at DESC, m.id DESC
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var matches []models.Match
+	var matches []models.MatchWithPlayers
 	for rows.Next() {
-		var match models.Match
-		if err := rows.Scan(
+		var match models.MatchWithPlayers
+		dest := []interface{}{
 			&match.ID,
 			&match.Sport,
 			&match.Player1ID,
@@ -364,7 +1280,16 @@ func (r *MatchRepository) GetMatches(userID *int, sport *string, status *string,
 			&match.DeniedAt,
 			&match.CreatedAt,
 			&match.UpdatedAt,
-		); err != nil {
+			&match.Result,
+			&match.ForfeitedBy,
+			&match.Player1KFactor,
+			&match.Player2KFactor,
+		}
+		dest = append(dest, feedUserDest(&match.Player1)...)
+		dest = append(dest, feedUserDest(&match.Player2)...)
+		dest = append(dest, feedUserDest(&match.Winner)...)
+		dest = append(dest, feedUserDest(&match.SubmittedBy_)...)
+		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
 		matches = append(matches, match)
@@ -374,15 +1299,20 @@ func (r *MatchRepository) GetMatches(userID *int, sport *string, status *string,
 }
 
 // GetUserMatches retrieves all matches for a user with filters
-func (r *MatchRepository) GetUserMatches(userID int, sport *string, opponentID *int, won *bool) ([]models.Match, error) {
+func (r *MatchRepository) GetUserMatches(ctx context.Context, userID int, sport *string, opponentID *int, won *bool) ([]models.Match, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
 		       winner_id, status, context, player1_elo_before, player1_elo_after, player1_elo_delta,
 		       player2_elo_before, player2_elo_after, player2_elo_delta,
-		       submitted_by, confirmed_at, denied_at, created_at, updated_at
+		       submitted_by, confirmed_at, denied_at, created_at, updated_at, result, forfeited_by,
+		       player1_k_factor, player2_k_factor
 		FROM matches
 		WHERE (player1_id = $1 OR player2_id = $1)
 		  AND status = $2
+		  AND deleted_at IS NULL
 	`
 
 	args := []interface{}{userID, models.StatusConfirmed}
@@ -412,7 +1342,7 @@ func (r *MatchRepository) GetUserMatches(userID int, sport *string, opponentID *
 
 	query += " ORDER BY created_at DESC"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -442,6 +1372,10 @@ func (r *MatchRepository) GetUserMatches(userID int, sport *string, opponentID *
 			&match.DeniedAt,
 			&match.CreatedAt,
 			&match.UpdatedAt,
+			&match.Result,
+			&match.ForfeitedBy,
+			&match.Player1KFactor,
+			&match.Player2KFactor,
 		); err != nil {
 			return nil, err
 		}
@@ -450,3 +1384,188 @@ func (r *MatchRepository) GetUserMatches(userID int, sport *string, opponentID *
 
 	return matches, rows.Err()
 }
+
+// GetHeadToHead summarizes confirmed matches between two players in a sport, archive included
+func (r *MatchRepository) GetHeadToHead(ctx context.Context, playerA, playerB int, sport string) (*models.HeadToHead, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE winner_id = $1),
+			COUNT(*) FILTER (WHERE winner_id = $2),
+			COALESCE(SUM(CASE WHEN player1_id = $1 THEN player1_score ELSE player2_score END), 0),
+			COALESCE(SUM(CASE WHEN player1_id = $2 THEN player1_score ELSE player2_score END), 0),
+			MAX(COALESCE(confirmed_at, created_at))
+		FROM matches_all
+		WHERE sport = $3 AND status = $4
+		  AND ((player1_id = $1 AND player2_id = $2) OR (player1_id = $2 AND player2_id = $1))
+	`
+
+	h2h := &models.HeadToHead{}
+	err := r.reader(ctx).QueryRowContext(ctx, query, playerA, playerB, sport, models.StatusConfirmed).Scan(
+		&h2h.Matches,
+		&h2h.PlayerAWins,
+		&h2h.PlayerBWins,
+		&h2h.PlayerAPoints,
+		&h2h.PlayerBPoints,
+		&h2h.LastPlayedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head-to-head: %w", err)
+	}
+	return h2h, nil
+}
+
+// maxCommonOpponents bounds the common opponents returned by a comparison
+const maxCommonOpponents = 20
+
+// GetCommonOpponents returns players both players have a confirmed match against,
+// with each player's record against them, most played first
+func (r *MatchRepository) GetCommonOpponents(ctx context.Context, playerA, playerB int, sport string) ([]models.CommonOpponent, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		WITH results AS (
+			SELECT
+				p.id AS player_id,
+				CASE WHEN m.player1_id = p.id THEN m.player2_id ELSE m.player1_id END AS opponent_id,
+				m.winner_id = p.id AS won
+			FROM matches_all m
+			JOIN (VALUES ($1::int), ($2::int)) AS p(id) ON p.id IN (m.player1_id, m.player2_id)
+			WHERE m.sport = $3 AND m.status = $4
+		),
+		records AS (
+			SELECT
+				opponent_id,
+				COUNT(*) FILTER (WHERE player_id = $1 AND won) AS a_wins,
+				COUNT(*) FILTER (WHERE player_id = $1 AND NOT won) AS a_losses,
+				COUNT(*) FILTER (WHERE player_id = $2 AND won) AS b_wins,
+				COUNT(*) FILTER (WHERE player_id = $2 AND NOT won) AS b_losses
+			FROM results
+			WHERE opponent_id NOT IN ($1, $2)
+			GROUP BY opponent_id
+		)
+		SELECT u.id, u.login, u.display_name, u.avatar_url,
+		       rec.a_wins, rec.a_losses, rec.b_wins, rec.b_losses
+		FROM records rec
+		JOIN users u ON u.id = rec.opponent_id
+		WHERE rec.a_wins + rec.a_losses > 0 AND rec.b_wins + rec.b_losses > 0
+		ORDER BY rec.a_wins + rec.a_losses + rec.b_wins + rec.b_losses DESC, u.id
+		LIMIT $5
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, playerA, playerB, sport, models.StatusConfirmed, maxCommonOpponents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get common opponents: %w", err)
+	}
+	defer rows.Close()
+
+	opponents := []models.CommonOpponent{}
+	for rows.Next() {
+		var o models.CommonOpponent
+		if err := rows.Scan(
+			&o.Opponent.ID, &o.Opponent.Login, &o.Opponent.DisplayName, &o.Opponent.AvatarURL,
+			&o.PlayerAWins, &o.PlayerALosses, &o.PlayerBWins, &o.PlayerBLosses,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan common opponent: %w", err)
+		}
+		opponents = append(opponents, o)
+	}
+
+	return opponents, rows.Err()
+}
+
+// Rival is the opponent a player has faced most often in a sport
+type Rival struct {
+	User    models.User
+	Matches int
+}
+
+// GetMostPlayedRivals returns each sport's most frequent confirmed opponent, archive included
+// Ties go to the opponent played most recently
+func (r *MatchRepository) GetMostPlayedRivals(ctx context.Context, userID int) (map[string]Rival, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		WITH opponents AS (
+			SELECT
+				m.sport,
+				CASE WHEN m.player1_id = $1 THEN m.player2_id ELSE m.player1_id END AS opponent_id,
+				COUNT(*) AS matches,
+				MAX(m.created_at) AS last_played
+			FROM matches_all m
+			WHERE (m.player1_id = $1 OR m.player2_id = $1) AND m.status = $2
+			GROUP BY m.sport, opponent_id
+		)
+		SELECT DISTINCT ON (o.sport)
+			o.sport, o.matches, u.id, u.login, u.display_name, u.avatar_url, u.campus
+		FROM opponents o
+		JOIN users u ON u.id = o.opponent_id
+		ORDER BY o.sport, o.matches DESC, o.last_played DESC
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, userID, models.StatusConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rivals: %w", err)
+	}
+	defer rows.Close()
+
+	rivals := make(map[string]Rival)
+	for rows.Next() {
+		var sport string
+		var rival Rival
+		if err := rows.Scan(
+			&sport, &rival.Matches,
+			&rival.User.ID, &rival.User.Login, &rival.User.DisplayName, &rival.User.AvatarURL, &rival.User.Campus,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan rival: %w", err)
+		}
+		rival.User.IntraID = rival.User.ID
+		rivals[sport] = rival
+	}
+
+	return rivals, rows.Err()
+}
+
+// ForfeitCount is how many forfeits a player won and lost in a sport
+type ForfeitCount struct {
+	Wins   int
+	Losses int
+}
+
+// GetForfeitCounts returns a player's confirmed forfeits per sport, archive included
+func (r *MatchRepository) GetForfeitCounts(ctx context.Context, userID int) (map[string]ForfeitCount, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT sport,
+		       COUNT(*) FILTER (WHERE winner_id = $1),
+		       COUNT(*) FILTER (WHERE forfeited_by = $1)
+		FROM matches_all
+		WHERE (player1_id = $1 OR player2_id = $1) AND status = $2 AND result = $3
+		GROUP BY sport
+	`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, query, userID, models.StatusConfirmed, models.ResultForfeit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forfeits: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]ForfeitCount)
+	for rows.Next() {
+		var sport string
+		var count ForfeitCount
+		if err := rows.Scan(&sport, &count.Wins, &count.Losses); err != nil {
+			return nil, fmt.Errorf("failed to scan forfeits: %w", err)
+		}
+		counts[sport] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// ProfileSyncRepository tracks when each user's 42 profile was last refreshed
+type ProfileSyncRepository struct {
+	db *sql.DB
+}
+
+// NewProfileSyncRepository creates a new ProfileSyncRepository instance
+func NewProfileSyncRepository(db *sql.DB) *ProfileSyncRepository {
+	return &ProfileSyncRepository{db: db}
+}
+
+// ListDue returns up to limit users who played since activeSince and are due for a refresh
+// Users never synced come first; users who erased their display data are skipped
+func (r *ProfileSyncRepository) ListDue(ctx context.Context, now, activeSince time.Time, limit int) ([]models.ProfileSyncCandidate, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id, u.login, COALESCE(ps.failures, 0)
+		FROM users u
+		LEFT JOIN profile_syncs ps ON ps.user_id = u.id
+		WHERE u.id != -1
+		  AND u.display_data_erased_at IS NULL
+		  AND (ps.next_attempt_at IS NULL OR ps.next_attempt_at <= $1)
+		  AND EXISTS (
+			SELECT 1 FROM matches m
+			WHERE (m.player1_id = u.id OR m.player2_id = u.id) AND m.created_at >= $2 AND m.deleted_at IS NULL
+		  )
+		ORDER BY ps.next_attempt_at ASC NULLS FIRST, u.id ASC
+		LIMIT $3
+	`, now, activeSince, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due profile syncs: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []models.ProfileSyncCandidate
+	for rows.Next() {
+		var candidate models.ProfileSyncCandidate
+		if err := rows.Scan(&candidate.UserID, &candidate.Login, &candidate.Failures); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+// RecordSuccess resets the failure count and schedules the next regular refresh
+func (r *ProfileSyncRepository) RecordSuccess(ctx context.Context, userID int, syncedAt, nextAttempt time.Time) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO profile_syncs (user_id, last_synced_at, next_attempt_at, failures, last_error)
+		VALUES ($1, $2, $3, 0, NULL)
+		ON CONFLICT (user_id) DO UPDATE SET
+			last_synced_at = EXCLUDED.last_synced_at,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			failures = 0,
+			last_error = NULL
+	`, userID, syncedAt, nextAttempt)
+	if err != nil {
+		return fmt.Errorf("failed to record profile sync: %w", err)
+	}
+	return nil
+}
+
+// RecordFailure stores a failed attempt and when to retry
+func (r *ProfileSyncRepository) RecordFailure(ctx context.Context, userID, failures int, nextAttempt time.Time, lastError string) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO profile_syncs (user_id, next_attempt_at, failures, last_error)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			failures = EXCLUDED.failures,
+			last_error = EXCLUDED.last_error
+	`, userID, nextAttempt, failures, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to record profile sync failure: %w", err)
+	}
+	return nil
+}
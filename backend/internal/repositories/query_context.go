@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DefaultQueryTimeout bounds a repository call unless its context is marked WithoutQueryTimeout
+const DefaultQueryTimeout = 5 * time.Second
+
+// queryTimeout is set once at startup, before any request is served
+var queryTimeout = DefaultQueryTimeout
+
+// SetQueryTimeout changes how long a repository call that takes a context may run
+// 0 leaves only the deadline and cancellation of the caller's context
+func SetQueryTimeout(timeout time.Duration) {
+	queryTimeout = timeout
+}
+
+// queryContext derives the context the queries of one repository call run with
+// They are cancelled when the client disconnects or the timeout runs out, whichever comes first
+func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if queryTimeout <= 0 || unbounded(ctx) {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, queryTimeout)
+}
+
+type noTimeoutKey struct{}
+
+// WithoutQueryTimeout marks ctx so repository calls are only bounded by ctx itself,
+// for background jobs whose batch queries may legitimately run longer than a request
+func WithoutQueryTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTimeoutKey{}, true)
+}
+
+func unbounded(ctx context.Context) bool {
+	unbounded, _ := ctx.Value(noTimeoutKey{}).(bool)
+	return unbounded
+}
+
+type primaryReadKey struct{}
+
+// ReadFromPrimary marks ctx so reads that would go to the read replica use the primary,
+// for callers that must see their own or the latest commits, such as closing a season
+func ReadFromPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+func readsFromPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadKey{}).(bool)
+	return primary
+}
+
+// IsCanceled reports whether a query failed because its context was cancelled or timed out
+// PostgreSQL reports the cancellation as query_canceled rather than the context's error
+func IsCanceled(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "57014"
+}
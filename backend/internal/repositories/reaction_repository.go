@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/lib/pq"
+)
+
+type ReactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) *ReactionRepository {
+	return &ReactionRepository{db: db}
+}
+
+// Add creates a reaction, returning false if the user already reacted with this emoji
+func (r *ReactionRepository) Add(ctx context.Context, reaction *models.Reaction) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO reactions (match_id, user_id, emoji)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (match_id, user_id, emoji) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, reaction.MatchID, reaction.UserID, reaction.Emoji).
+		Scan(&reaction.ID, &reaction.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Remove deletes a user's reaction, returning sql.ErrNoRows if it did not exist
+func (r *ReactionRepository) Remove(ctx context.Context, matchID, userID int, emoji string) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reactions WHERE match_id = $1 AND user_id = $2 AND emoji = $3`, matchID, userID, emoji)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RemoveByUser deletes a user's reactions on a match, for moderation
+// An empty emoji removes all of them; returns the number removed
+func (r *ReactionRepository) RemoveByUser(ctx context.Context, matchID, userID int, emoji string) (int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM reactions WHERE match_id = $1 AND user_id = $2 AND ($3 = '' OR emoji = $3)
+	`, matchID, userID, emoji)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// GetSummary returns reaction counts per emoji for a match
+// viewerID marks the emoji the viewer reacted with; pass 0 for anonymous summaries
+func (r *ReactionRepository) GetSummary(ctx context.Context, matchID, viewerID int) ([]models.ReactionSummary, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT emoji, COUNT(*), COALESCE(BOOL_OR(user_id = $2), false)
+		FROM reactions
+		WHERE match_id = $1
+		GROUP BY emoji
+		ORDER BY MIN(created_at) ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, matchID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := []models.ReactionSummary{}
+	for rows.Next() {
+		var s models.ReactionSummary
+		if err := rows.Scan(&s.Emoji, &s.Count, &s.Reacted); err != nil {
+			return nil, err
+		}
+		summary = append(summary, s)
+	}
+
+	return summary, rows.Err()
+}
+
+// GetSummaries returns the reaction summaries of several matches in one query, keyed by match ID
+// Matches without reactions are missing from the map
+func (r *ReactionRepository) GetSummaries(ctx context.Context, matchIDs []int, viewerID int) (map[int][]models.ReactionSummary, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	summaries := make(map[int][]models.ReactionSummary)
+	if len(matchIDs) == 0 {
+		return summaries, nil
+	}
+
+	query := `
+		SELECT match_id, emoji, COUNT(*), COALESCE(BOOL_OR(user_id = $2), false)
+		FROM reactions
+		WHERE match_id = ANY($1)
+		GROUP BY match_id, emoji
+		ORDER BY match_id, MIN(created_at) ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(matchIDs), viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var matchID int
+		var s models.ReactionSummary
+		if err := rows.Scan(&matchID, &s.Emoji, &s.Count, &s.Reacted); err != nil {
+			return nil, err
+		}
+		summaries[matchID] = append(summaries[matchID], s)
+	}
+
+	return summaries, rows.Err()
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// ReportRepository handles abuse reports filed by users
+type ReportRepository struct {
+	db *sql.DB
+}
+
+// NewReportRepository creates a new ReportRepository instance
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+const reportColumns = `id, reporter_id, target_type, target_id, reason, details, status, resolution_note, handled_by, handled_at, created_at`
+
+func scanReport(row interface{ Scan(...interface{}) error }) (*models.Report, error) {
+	report := &models.Report{}
+	err := row.Scan(
+		&report.ID,
+		&report.ReporterID,
+		&report.TargetType,
+		&report.TargetID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.ResolutionNote,
+		&report.HandledBy,
+		&report.HandledAt,
+		&report.CreatedAt,
+	)
+	return report, err
+}
+
+// Create stores a new open report
+// Fails with "report already open" if the reporter already has an open report on the target
+func (r *ReportRepository) Create(ctx context.Context, report *models.Report) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO reports (reporter_id, target_type, target_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (reporter_id, target_type, target_id) WHERE status = 'open' DO NOTHING
+		RETURNING ` + reportColumns
+
+	created, err := scanReport(r.db.QueryRowContext(ctx, query,
+		report.ReporterID, report.TargetType, report.TargetID, report.Reason, report.Details,
+	))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("report already open")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	*report = *created
+	return nil
+}
+
+// List returns reports with the given status, oldest first so the queue is worked in order
+func (r *ReportRepository) List(ctx context.Context, status string, limit, offset int) ([]models.Report, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + reportColumns + `
+		FROM reports
+		WHERE status = $1
+		ORDER BY created_at ASC, id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []models.Report{}
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, rows.Err()
+}
+
+// Close resolves or dismisses an open report on behalf of a staff member
+// Fails with "report not found" or "report is not open" if it was already handled
+func (r *ReportRepository) Close(ctx context.Context, id int, status string, handledBy int, note *string) (*models.Report, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE reports SET status = $1, resolution_note = $2, handled_by = $3, handled_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND status = 'open'
+		RETURNING ` + reportColumns
+
+	report, err := scanReport(r.db.QueryRowContext(ctx, query, status, note, handledBy, id))
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM reports WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to close report: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("report not found")
+		}
+		return nil, fmt.Errorf("report is not open")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to close report: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,245 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// SeasonRepository handles seasons and their archived standings
+type SeasonRepository struct {
+	db *sql.DB
+}
+
+// NewSeasonRepository creates a new SeasonRepository instance
+func NewSeasonRepository(db *sql.DB) *SeasonRepository {
+	return &SeasonRepository{db: db}
+}
+
+const seasonColumns = `id, name, reset_factor, started_at, ended_at, opened_by, closed_by, created_at`
+
+func scanSeason(row interface{ Scan(...interface{}) error }) (*models.Season, error) {
+	season := &models.Season{}
+	err := row.Scan(
+		&season.ID,
+		&season.Name,
+		&season.ResetFactor,
+		&season.StartedAt,
+		&season.EndedAt,
+		&season.OpenedBy,
+		&season.ClosedBy,
+		&season.CreatedAt,
+	)
+	return season, err
+}
+
+// Create inserts a new open season
+func (r *SeasonRepository) Create(ctx context.Context, tx *sql.Tx, season *models.Season) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO seasons (name, reset_factor, opened_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, started_at, created_at
+	`
+
+	err := tx.QueryRowContext(ctx, query, season.Name, season.ResetFactor, season.OpenedBy).
+		Scan(&season.ID, &season.StartedAt, &season.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create season: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a season by ID
+func (r *SeasonRepository) GetByID(ctx context.Context, id int) (*models.Season, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	season, err := scanSeason(r.db.QueryRowContext(ctx, `SELECT `+seasonColumns+` FROM seasons WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("season not found")
+	}
+	return season, err
+}
+
+// GetOpen returns the currently open season, or nil if there is none
+func (r *SeasonRepository) GetOpen(ctx context.Context) (*models.Season, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	season, err := scanSeason(r.db.QueryRowContext(ctx, `SELECT `+seasonColumns+` FROM seasons WHERE ended_at IS NULL`))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return season, err
+}
+
+// GetLatestClosed returns the most recently closed season, or nil if none was closed yet
+func (r *SeasonRepository) GetLatestClosed(ctx context.Context) (*models.Season, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT ` + seasonColumns + ` FROM seasons WHERE ended_at IS NOT NULL ORDER BY ended_at DESC LIMIT 1`
+	season, err := scanSeason(r.db.QueryRowContext(ctx, query))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return season, err
+}
+
+// List returns all seasons, newest first
+func (r *SeasonRepository) List(ctx context.Context) ([]models.Season, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+seasonColumns+` FROM seasons ORDER BY started_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := []models.Season{}
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, *season)
+	}
+
+	return seasons, rows.Err()
+}
+
+// Close marks a season as ended
+func (r *SeasonRepository) Close(ctx context.Context, tx *sql.Tx, seasonID, closedBy int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE seasons SET ended_at = CURRENT_TIMESTAMP, closed_by = $2
+		WHERE id = $1 AND ended_at IS NULL
+	`, seasonID, closedBy)
+	if err != nil {
+		return fmt.Errorf("failed to close season: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("season is not open")
+	}
+	return nil
+}
+
+// SaveResults archives the final standings of a sport for a season
+func (r *SeasonRepository) SaveResults(ctx context.Context, tx *sql.Tx, seasonID int, sport string, entries []models.LeaderboardEntry) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO season_results (season_id, sport_id, user_id, rank, elo, matches_played, wins, losses)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (season_id, sport_id, user_id) DO UPDATE
+		SET rank = EXCLUDED.rank, elo = EXCLUDED.elo, matches_played = EXCLUDED.matches_played,
+		    wins = EXCLUDED.wins, losses = EXCLUDED.losses
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare season results insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		if _, err := stmt.ExecContext(ctx, seasonID, sport, e.User.ID, e.Rank, e.ELO, e.MatchesPlayed, e.Wins, e.Losses); err != nil {
+			return fmt.Errorf("failed to save season result: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetResults returns the archived standings of a sport for a season, ordered by rank
+func (r *SeasonRepository) GetResults(ctx context.Context, seasonID int, sport string) ([]models.LeaderboardEntry, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT sr.rank, sr.elo, sr.matches_played, sr.wins, sr.losses,
+		       u.id, u.id, u.login, u.display_name, u.avatar_url, u.campus,
+		       u.created_at, u.updated_at
+		FROM season_results sr
+		JOIN users u ON u.id = sr.user_id
+		WHERE sr.season_id = $1 AND sr.sport_id = $2
+		ORDER BY sr.rank ASC, u.id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, seasonID, sport)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.LeaderboardEntry{}
+	for rows.Next() {
+		var e models.LeaderboardEntry
+		if err := rows.Scan(
+			&e.Rank,
+			&e.ELO,
+			&e.MatchesPlayed,
+			&e.Wins,
+			&e.Losses,
+			&e.User.ID,
+			&e.User.IntraID,
+			&e.User.Login,
+			&e.User.DisplayName,
+			&e.User.AvatarURL,
+			&e.User.Campus,
+			&e.User.CreatedAt,
+			&e.User.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if e.MatchesPlayed > 0 {
+			e.WinRate = float64(e.Wins) / float64(e.MatchesPlayed) * 100
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// SoftResetELO pulls every player's current ELO towards the sport default
+// new = default + (current - default) * factor, within the sport's rating bounds;
+// changed ratings are logged to elo_history
+// Returns the number of ratings that changed
+func (r *SeasonRepository) SoftResetELO(ctx context.Context, tx *sql.Tx, factor float64) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, `
+		WITH before AS (
+			SELECT user_id, sport_id, current_elo FROM user_sports
+		), reset AS (
+			UPDATE user_sports us
+			SET current_elo = GREATEST(s.rating_floor, LEAST(s.rating_ceiling,
+				s.default_elo + ROUND((us.current_elo - s.default_elo) * $1)::INTEGER))
+			FROM sports s
+			WHERE s.id = us.sport_id
+			RETURNING us.user_id, us.sport_id, us.current_elo
+		)
+		INSERT INTO elo_history (user_id, sport_id, elo_before, elo_after, delta, source)
+		SELECT reset.user_id, reset.sport_id, before.current_elo, reset.current_elo,
+		       reset.current_elo - before.current_elo, $2
+		FROM reset
+		JOIN before ON before.user_id = reset.user_id AND before.sport_id = reset.sport_id
+		WHERE reset.current_elo <> before.current_elo
+	`, factor, models.ELOSourceSeasonReset)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset ELO: %w", err)
+	}
+	return result.RowsAffected()
+}
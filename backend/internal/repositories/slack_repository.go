@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/lib/pq"
+)
+
+// SlackRepository handles Slack account links used by the slash command
+type SlackRepository struct {
+	db *sql.DB
+}
+
+// NewSlackRepository creates a new SlackRepository instance
+func NewSlackRepository(db *sql.DB) *SlackRepository {
+	return &SlackRepository{db: db}
+}
+
+// CreateLinkCode stores a link code for a user, replacing any earlier one
+func (r *SlackRepository) CreateLinkCode(ctx context.Context, userID int, code string, expiresAt time.Time) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO slack_link_codes (code, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET code = EXCLUDED.code, expires_at = EXCLUDED.expires_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, code, userID, expiresAt); err != nil {
+		return fmt.Errorf("failed to create link code: %w", err)
+	}
+	return nil
+}
+
+// RedeemLinkCode consumes a link code and links the Slack account to its user
+// The code is single use; an expired or unknown code returns "invalid or expired link code"
+func (r *SlackRepository) RedeemLinkCode(ctx context.Context, code, teamID, slackUserID string) (int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM slack_link_codes
+		WHERE code = $1 AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id
+	`, code).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("invalid or expired link code")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to redeem link code: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO slack_links (user_id, slack_team_id, slack_user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET slack_team_id = EXCLUDED.slack_team_id, slack_user_id = EXCLUDED.slack_user_id, created_at = CURRENT_TIMESTAMP
+	`, userID, teamID, slackUserID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return 0, fmt.Errorf("slack account is already linked to another user")
+		}
+		return 0, fmt.Errorf("failed to link slack account: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// GetUserID returns the user a Slack account is linked to
+func (r *SlackRepository) GetUserID(ctx context.Context, teamID, slackUserID string) (int, bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var userID int
+	err := r.db.QueryRowContext(ctx, `SELECT user_id FROM slack_links WHERE slack_team_id = $1 AND slack_user_id = $2`, teamID, slackUserID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return userID, true, nil
+}
+
+// GetByUserID returns a user's Slack link
+func (r *SlackRepository) GetByUserID(ctx context.Context, userID int) (*models.SlackLink, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	link := &models.SlackLink{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, slack_team_id, slack_user_id, created_at
+		FROM slack_links WHERE user_id = $1
+	`, userID).Scan(&link.UserID, &link.SlackTeamID, &link.SlackUserID, &link.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("slack account not linked")
+	}
+	return link, err
+}
+
+// Unlink removes a user's Slack link
+func (r *SlackRepository) Unlink(ctx context.Context, userID int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM slack_links WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink slack account: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("slack account not linked")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// SnapshotRepository handles daily leaderboard snapshots
+type SnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewSnapshotRepository creates a new SnapshotRepository instance
+func NewSnapshotRepository(db *sql.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// SaveSnapshot stores the ranked leaderboard of a sport for the given day
+// An existing snapshot for the same day is replaced
+func (r *SnapshotRepository) SaveSnapshot(ctx context.Context, date time.Time, sport string, entries []models.LeaderboardEntry) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	day := date.Format("2006-01-02")
+	if _, err := tx.ExecContext(ctx, `DELETE FROM leaderboard_snapshots WHERE snapshot_date = $1 AND sport_id = $2`, day, sport); err != nil {
+		return fmt.Errorf("failed to clear snapshot: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO leaderboard_snapshots (snapshot_date, sport_id, user_id, rank, elo, matches_played)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare snapshot insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		if _, err := stmt.ExecContext(ctx, day, sport, entry.User.ID, entry.Rank, entry.ELO, entry.MatchesPlayed); err != nil {
+			return fmt.Errorf("failed to insert snapshot row: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// HasSnapshot reports whether a snapshot exists for a sport and day
+func (r *SnapshotRepository) HasSnapshot(ctx context.Context, date time.Time, sport string) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM leaderboard_snapshots WHERE snapshot_date = $1 AND sport_id = $2)`
+	if err := r.db.QueryRowContext(ctx, query, date.Format("2006-01-02"), sport).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// GetRanks returns the ranks stored in a snapshot keyed by user ID
+// An empty map means there is no snapshot for that sport and day
+func (r *SnapshotRepository) GetRanks(ctx context.Context, date time.Time, sport string) (map[int]int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT user_id, rank FROM leaderboard_snapshots WHERE snapshot_date = $1 AND sport_id = $2`
+
+	rows, err := r.db.QueryContext(ctx, query, date.Format("2006-01-02"), sport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	ranks := make(map[int]int)
+	for rows.Next() {
+		var userID, rank int
+		if err := rows.Scan(&userID, &rank); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot row: %w", err)
+		}
+		ranks[userID] = rank
+	}
+	return ranks, rows.Err()
+}
+
+// GetUserHistory returns a player's daily snapshots of a sport in chronological order
+// from and to are optional dates; to is exclusive
+func (r *SnapshotRepository) GetUserHistory(ctx context.Context, userID int, sport string, from, to *time.Time) ([]models.RankSnapshot, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT snapshot_date, rank, elo, matches_played
+		FROM leaderboard_snapshots
+		WHERE user_id = $1 AND sport_id = $2
+		  AND ($3::date IS NULL OR snapshot_date >= $3::date)
+		  AND ($4::date IS NULL OR snapshot_date < $4::date)
+		ORDER BY snapshot_date ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, sport, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rank history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []models.RankSnapshot{}
+	for rows.Next() {
+		var s models.RankSnapshot
+		var date time.Time
+		if err := rows.Scan(&date, &s.Rank, &s.ELO, &s.MatchesPlayed); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot row: %w", err)
+		}
+		s.Date = date.Format("2006-01-02")
+		history = append(history, s)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,347 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// The store interfaces describe what the services, handlers and middleware need from
+// each repository, so they can be tested against the in-memory fakes of package fakes
+// instead of a live database. Each lists only the methods they call; the repositories
+// satisfy them as they are. Methods taking a *sql.Tx run inside the caller's transaction,
+// a nil tx runs on its own.
+
+// UserStore reads and manages user accounts
+type UserStore interface {
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []int) ([]models.User, error)
+	GetByLogin(ctx context.Context, login string) (*models.User, error)
+	GetByLogins(ctx context.Context, logins []string) ([]models.User, error)
+	GetByIDForUpdate(ctx context.Context, tx *sql.Tx, id int) (*models.User, error)
+	GetAll(ctx context.Context) ([]models.User, error)
+	CountPlayers(ctx context.Context) (int, error)
+	UpdateDisplayData(ctx context.Context, userID int, displayName, avatarURL string) (bool, error)
+	CreateOrUpdate(ctx context.Context, user *models.User) (bool, error)
+	SetIntraProfile(ctx context.Context, userID int, coalition *models.Coalition, poolYear string) error
+	GetLanguage(ctx context.Context, userID int) (string, error)
+	SetLanguage(ctx context.Context, userID int, language string) error
+	Deactivate(ctx context.Context, userID int) (bool, error)
+	Reactivate(ctx context.Context, userID int) (bool, error)
+	GetLinkedUserID(ctx context.Context, intraID int) (int, bool, error)
+	FindRelinkCandidate(ctx context.Context, login string, excludeID int) (*models.User, error)
+	RelinkIntraID(ctx context.Context, newIntraID, userID int, source string, linkedBy *int) error
+	MergeUsers(ctx context.Context, sourceID, targetID, adminID int) (*models.UserMergeResult, error)
+}
+
+// MatchStore stores matches and answers the leaderboard and statistics queries
+type MatchStore interface {
+	Create(ctx context.Context, tx *sql.Tx, match *models.Match) error
+	CreateSets(ctx context.Context, tx *sql.Tx, matchID int, sets []models.MatchSet) error
+	DeleteSets(ctx context.Context, tx *sql.Tx, matchID int) error
+	Import(ctx context.Context, tx *sql.Tx, match *models.Match) error
+	FindDuplicate(ctx context.Context, tx *sql.Tx, match *models.Match) (int, error)
+	GetByID(ctx context.Context, id int) (*models.Match, error)
+	GetSets(ctx context.Context, matchID int) ([]models.MatchSet, error)
+	GetMatches(ctx context.Context, userID *int, sport *string, status *string, cursor *models.MatchCursor, limit int, offset int, includeArchived bool) ([]models.Match, error)
+	GetMatchesWithPlayers(ctx context.Context, userID *int, sport *string, status *string, cursor *models.MatchCursor, limit int, offset int, includeArchived bool) ([]models.MatchWithPlayers, error)
+	GetUserMatches(ctx context.Context, userID int, sport *string, opponentID *int, won *bool) ([]models.Match, error)
+	GetPendingMatchBetweenPlayers(ctx context.Context, player1ID, player2ID int, sport string) (*models.Match, error)
+	GetPendingStatsBetweenPlayers(ctx context.Context, player1ID, player2ID int, sport string) (int, *time.Time, error)
+	ConfirmMatch(ctx context.Context, tx *sql.Tx, matchID int, from string, eloData map[string]int, confirmFingerprint *string) error
+	DenyMatch(ctx context.Context, tx *sql.Tx, matchID int) error
+	CancelMatch(ctx context.Context, matchID int) error
+	SetStatus(ctx context.Context, matchID int, from, to string) error
+	UpdateScores(ctx context.Context, tx *sql.Tx, matchID, player1Score, player2Score, winnerID int) error
+	CorrectResult(ctx context.Context, tx *sql.Tx, match *models.Match) error
+	CreateCounterProposal(ctx context.Context, tx *sql.Tx, proposal *models.CounterProposal) error
+	GetCounterProposal(ctx context.Context, matchID int) (*models.CounterProposal, error)
+	ResolveCounterProposal(ctx context.Context, tx *sql.Tx, matchID int, status string) error
+	GetLeaderboardEntries(ctx context.Context, sport string, limit, offset int) ([]models.LeaderboardEntry, int, error)
+	GetUnrankedPlayers(ctx context.Context, sport string, limit, offset int) ([]models.UnrankedEntry, int, error)
+	GetCoalitionStandings(ctx context.Context, sport string) ([]models.CoalitionStanding, error)
+	GetELODistribution(ctx context.Context, sport string, bucketSize int) (*models.ELODistribution, error)
+	GetActivityHeatmap(ctx context.Context, sport string, since time.Time, tz string) ([][]int, int, error)
+	GetSportSummaries(ctx context.Context, since time.Time) ([]models.SportSummary, error)
+	GetMostActivePlayer(ctx context.Context, since time.Time) (userID, matches int, ok bool, err error)
+	GetForfeitCounts(ctx context.Context, userID int) (map[string]ForfeitCount, error)
+	GetMostPlayedRivals(ctx context.Context, userID int) (map[string]Rival, error)
+	GetHeadToHead(ctx context.Context, playerA, playerB int, sport string) (*models.HeadToHead, error)
+	GetCommonOpponents(ctx context.Context, playerA, playerB int, sport string) ([]models.CommonOpponent, error)
+	GetELOPercentile(ctx context.Context, sport string, userID int) (elo int, percentile float64, ok bool, err error)
+}
+
+// UserSportsStore stores each player's rating and record per sport
+type UserSportsStore interface {
+	EnsureUserSportExists(ctx context.Context, tx *sql.Tx, userID int, sportID string, defaultELO int) error
+	GetRatingForUpdate(ctx context.Context, tx *sql.Tx, userID int, sportID string) (int, int, error)
+	GetUserELOForUpdate(ctx context.Context, tx *sql.Tx, userID int, sportID string) (int, error)
+	UpdateUserELO(ctx context.Context, tx *sql.Tx, userID int, sportID string, newELO int) error
+	IncrementMatchStats(ctx context.Context, tx *sql.Tx, userID int, sportID string, won bool) error
+	ReconcileStats(ctx context.Context, tx *sql.Tx, userIDs []int) (int64, error)
+	GetAllUserSports(ctx context.Context, userID int) (map[string]*UserSportData, error)
+	GetUserSportStats(ctx context.Context, userID int, sportID string) (*UserSportData, error)
+}
+
+// RankingStore keeps the materialized leaderboards ranked
+type RankingStore interface {
+	Refresh(ctx context.Context, tx *sql.Tx, sports ...string) (int64, error)
+	RankPlayers(ctx context.Context, tx *sql.Tx, userIDs []int, sports ...string) (int64, error)
+}
+
+// ELOHistoryStore logs rating changes
+type ELOHistoryStore interface {
+	Record(ctx context.Context, tx *sql.Tx, entry *models.ELOHistoryEntry) error
+	GetHistory(ctx context.Context, userID int, sport *string, from, to *time.Time) ([]models.ELOHistoryEntry, error)
+}
+
+// SnapshotStore reads the daily rank snapshots
+type SnapshotStore interface {
+	GetRanks(ctx context.Context, date time.Time, sport string) (map[int]int, error)
+	GetUserHistory(ctx context.Context, userID int, sport string, from, to *time.Time) ([]models.RankSnapshot, error)
+}
+
+// ChallengeStore stores challenges between players
+type ChallengeStore interface {
+	Create(ctx context.Context, challenge *models.Challenge) error
+	GetByID(ctx context.Context, id int) (*models.Challenge, error)
+	GetByIDForUpdate(ctx context.Context, tx *sql.Tx, id int) (*models.Challenge, error)
+	ListForUser(ctx context.Context, userID int, direction string, status *string) ([]models.Challenge, error)
+	Respond(ctx context.Context, id, opponentID int, status string) (*models.Challenge, error)
+	Cancel(ctx context.Context, id, challengerID int) (*models.Challenge, error)
+	Complete(ctx context.Context, tx *sql.Tx, id, matchID int) error
+}
+
+// SeasonStore stores seasons and their final standings
+type SeasonStore interface {
+	Create(ctx context.Context, tx *sql.Tx, season *models.Season) error
+	Close(ctx context.Context, tx *sql.Tx, seasonID, closedBy int) error
+	GetByID(ctx context.Context, id int) (*models.Season, error)
+	GetOpen(ctx context.Context) (*models.Season, error)
+	List(ctx context.Context) ([]models.Season, error)
+	GetResults(ctx context.Context, seasonID int, sport string) ([]models.LeaderboardEntry, error)
+	SaveResults(ctx context.Context, tx *sql.Tx, seasonID int, sport string, entries []models.LeaderboardEntry) error
+	SoftResetELO(ctx context.Context, tx *sql.Tx, factor float64) (int64, error)
+}
+
+// ELOReplayStore loads everything an ELO recompute replays and saves its results
+type ELOReplayStore interface {
+	Lock(ctx context.Context, tx *sql.Tx) error
+	LoadDefaultELOs(ctx context.Context, tx *sql.Tx) (map[string]int, error)
+	LoadRatings(ctx context.Context, tx *sql.Tx) ([]ReplayRating, error)
+	LoadMatches(ctx context.Context, tx *sql.Tx) ([]ReplayMatch, error)
+	LoadSeasonStarts(ctx context.Context, tx *sql.Tx) ([]models.Season, error)
+	LoadAdjustments(ctx context.Context, tx *sql.Tx) ([]models.ELOAdjustment, error)
+	LoadDecays(ctx context.Context, tx *sql.Tx) ([]models.ELOHistoryEntry, error)
+	UpdateMatchELO(ctx context.Context, tx *sql.Tx, m *ReplayMatch) error
+}
+
+// MatchFlagStore flags suspicious match patterns for review
+type MatchFlagStore interface {
+	FlagPairFrequency(ctx context.Context, since time.Time, window time.Duration, minMatches int) (int64, error)
+	FlagELOFarming(ctx context.Context, since time.Time, minGap int, window time.Duration, minGain int) (int64, error)
+	FlagAlternatingWins(ctx context.Context, since time.Time, streak int) (int64, error)
+	List(ctx context.Context, status string, limit, offset int) ([]models.MatchFlag, error)
+	Review(ctx context.Context, id int, status string, reviewedBy int) (*models.MatchFlag, error)
+}
+
+// DeletionRequestStore stores scheduled account deletions
+type DeletionRequestStore interface {
+	Create(ctx context.Context, userID int, eraseAfter time.Time) (*models.DeletionRequest, bool, error)
+	GetForUser(ctx context.Context, userID int) (*models.DeletionRequest, error)
+	Delete(ctx context.Context, userID int) (bool, error)
+	ListDue(ctx context.Context, now time.Time) ([]int, error)
+	Claim(ctx context.Context, tx *sql.Tx, userID int, now time.Time) (bool, error)
+}
+
+// AnonymizationStore stores anonymous names and their vocabulary
+type AnonymizationStore interface {
+	ListWords(ctx context.Context) ([]models.AnonymizationWord, error)
+	AddWord(ctx context.Context, campus, kind, word string) (*models.AnonymizationWord, error)
+	DeleteWord(ctx context.Context, id int) error
+	GetNames(ctx context.Context, userIDs []int) (map[int]string, error)
+	ListUnnamedUsers(ctx context.Context, limit int) ([]models.User, error)
+	ClaimName(ctx context.Context, userID int, name string) (string, bool, error)
+}
+
+// DataExportStore stores GDPR data exports
+type DataExportStore interface {
+	Create(ctx context.Context, userID int) (*models.DataExport, error)
+	GetLatestForUser(ctx context.Context, userID int) (*models.DataExport, error)
+	GetArchive(ctx context.Context, id int) ([]byte, error)
+	ClaimNext(ctx context.Context, staleBefore time.Time) (*models.DataExport, error)
+	Complete(ctx context.Context, export *models.DataExport, archive []byte, expiresAt time.Time) error
+	Fail(ctx context.Context, id int, reason string, expiresAt time.Time) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// DigestStore answers the digest queries and stores finished digests
+type DigestStore interface {
+	CountMatches(ctx context.Context, sport string, from, to time.Time) (int, error)
+	GetMostActive(ctx context.Context, sport string, from, to time.Time, limit int) ([]models.DigestActivity, error)
+	GetUpsets(ctx context.Context, sport string, from, to time.Time, limit int) ([]models.DigestUpset, error)
+	Exists(ctx context.Context, sport, period string, start time.Time) (bool, error)
+	Save(ctx context.Context, digest *models.Digest) (bool, error)
+	GetLatest(ctx context.Context, sport, period string) (*models.Digest, error)
+}
+
+// ProfileSyncStore schedules refreshes of profiles from the 42 API
+type ProfileSyncStore interface {
+	ListDue(ctx context.Context, now, activeSince time.Time, limit int) ([]models.ProfileSyncCandidate, error)
+	RecordSuccess(ctx context.Context, userID int, syncedAt, nextAttempt time.Time) error
+	RecordFailure(ctx context.Context, userID, failures int, nextAttempt time.Time, lastError string) error
+}
+
+// AdminStore runs the moderation actions and reports of the admin panel
+type AdminStore interface {
+	LogAdminAction(ctx context.Context, adminID int, action string, targetType string, targetID *int, details interface{}) error
+	GetAuditLog(ctx context.Context, limit int) ([]models.AdminAuditLog, error)
+	BanUser(ctx context.Context, userID int, reason string, adminID int, until *time.Time) error
+	UnbanUser(ctx context.Context, userID int) error
+	GetBannedUsers(ctx context.Context) ([]models.User, error)
+	MuteUser(ctx context.Context, userID, adminID int) error
+	UnmuteUser(ctx context.Context, userID int) error
+	GetMutedUsers(ctx context.Context) ([]models.MutedUser, error)
+	SetRole(ctx context.Context, userID int, role string) (string, error)
+	AdjustELO(ctx context.Context, userID int, sport string, newELO int, reason string, adminID int) (*models.ELOAdjustment, error)
+	PenalizeELO(ctx context.Context, userID int, sport string, points int, reason string, adminID int) (*models.ELOAdjustment, error)
+	GetELOAdjustments(ctx context.Context, limit int) ([]models.ELOAdjustment, error)
+	GetConfirmedMatches(ctx context.Context, limit int) ([]models.Match, error)
+	GetDisputedMatches(ctx context.Context) ([]models.Match, error)
+	GetDeletedMatches(ctx context.Context, limit int) ([]models.DeletedMatch, error)
+	RevertMatch(ctx context.Context, matchID, adminID int) error
+	DeleteMatch(ctx context.Context, matchID, adminID int) error
+	RestoreMatch(ctx context.Context, matchID int) (bool, error)
+	GetInconsistentWinners(ctx context.Context) ([]models.WinnerRepair, error)
+	RepairMatchWinners(ctx context.Context) ([]models.WinnerRepair, error)
+	GetFingerprintAnomalies(ctx context.Context, limit, offset int) ([]models.MatchAnomaly, error)
+	GetAnalytics(ctx context.Context, days int) ([]models.AnalyticsDay, *models.AnalyticsPeriod, error)
+	GetSystemHealth(ctx context.Context) (*models.SystemHealth, error)
+	ExportUsersCSV(ctx context.Context) ([]models.User, error)
+	ExportMatchesCSV(ctx context.Context) ([]models.Match, error)
+}
+
+// CommentStore stores the comments on matches
+type CommentStore interface {
+	Add(ctx context.Context, comment *models.Comment) error
+	GetByID(ctx context.Context, commentID int) (*models.Comment, error)
+	GetByMatchID(ctx context.Context, matchID, viewerID int) ([]models.Comment, error)
+	GetByMatchIDPaginated(ctx context.Context, matchID, viewerID, limit, offset int) ([]models.Comment, int, error)
+	Delete(ctx context.Context, commentID, userID int) error
+	DeleteByID(ctx context.Context, commentID int) error
+	DeleteByUser(ctx context.Context, userID int) ([]models.Comment, error)
+}
+
+// ReactionStore stores the emoji reactions on matches
+type ReactionStore interface {
+	Add(ctx context.Context, reaction *models.Reaction) (bool, error)
+	Remove(ctx context.Context, matchID, userID int, emoji string) error
+	RemoveByUser(ctx context.Context, matchID, userID int, emoji string) (int, error)
+	GetSummary(ctx context.Context, matchID, viewerID int) ([]models.ReactionSummary, error)
+	GetSummaries(ctx context.Context, matchIDs []int, viewerID int) (map[int][]models.ReactionSummary, error)
+}
+
+// ReportStore stores reports of abusive content
+type ReportStore interface {
+	Create(ctx context.Context, report *models.Report) error
+	List(ctx context.Context, status string, limit, offset int) ([]models.Report, error)
+	Close(ctx context.Context, id int, status string, handledBy int, note *string) (*models.Report, error)
+}
+
+// LegalStore stores the versions of the legal documents and who accepted them
+type LegalStore interface {
+	GetLatest(ctx context.Context, doc, lang string) (*models.LegalDocument, error)
+	GetVersion(ctx context.Context, doc, lang string, version int) (*models.LegalDocument, error)
+	ListVersions(ctx context.Context, doc, lang string) ([]models.LegalDocument, error)
+	Publish(ctx context.Context, document *models.LegalDocument) error
+	Accept(ctx context.Context, userID int, document *models.LegalDocument) (*models.LegalAcceptance, error)
+	ListAcceptances(ctx context.Context, userID int) ([]models.LegalAcceptance, error)
+	ListPending(ctx context.Context, userID int, lang string, docs []string) ([]models.LegalDocument, error)
+}
+
+// IncidentStore stores the incidents shown on the status page
+type IncidentStore interface {
+	Create(ctx context.Context, incident *models.StatusIncident) error
+	GetByID(ctx context.Context, id int) (*models.StatusIncident, error)
+	ListRecent(ctx context.Context, resolvedSince time.Time, limit int) ([]models.StatusIncident, error)
+	Update(ctx context.Context, incident *models.StatusIncident) error
+	Delete(ctx context.Context, id int) error
+}
+
+// SlackStore links Slack accounts to players
+type SlackStore interface {
+	CreateLinkCode(ctx context.Context, userID int, code string, expiresAt time.Time) error
+	RedeemLinkCode(ctx context.Context, code, teamID, slackUserID string) (int, error)
+	GetUserID(ctx context.Context, teamID, slackUserID string) (int, bool, error)
+	GetByUserID(ctx context.Context, userID int) (*models.SlackLink, error)
+	Unlink(ctx context.Context, userID int) error
+}
+
+// TrustedClientStore stores the API clients with higher rate limits
+type TrustedClientStore interface {
+	Create(ctx context.Context, client *models.TrustedClient) error
+	GetByID(ctx context.Context, id int) (*models.TrustedClient, error)
+	List(ctx context.Context) ([]models.TrustedClient, error)
+	ListActive(ctx context.Context) ([]models.TrustedClient, error)
+	Update(ctx context.Context, client *models.TrustedClient) error
+	Delete(ctx context.Context, id int) error
+}
+
+// UsageStore answers the API usage reports
+type UsageStore interface {
+	GetTopUsers(ctx context.Context, since time.Time, limit int) ([]models.APIUsageUserSummary, error)
+	GetUserUsage(ctx context.Context, userID int, since time.Time) ([]models.APIEndpointUsage, error)
+}
+
+// UserNotificationStore reads the in-app notifications of a user
+type UserNotificationStore interface {
+	ListForUser(ctx context.Context, userID int, unreadOnly bool, limit int) ([]models.UserNotification, error)
+	CountUnread(ctx context.Context, userID int) (int, error)
+	MarkRead(ctx context.Context, userID int, ids []int) (int64, error)
+}
+
+// WebhookStore stores the outgoing webhooks
+type WebhookStore interface {
+	Create(ctx context.Context, hook *models.Webhook) error
+	GetByID(ctx context.Context, id int) (*models.Webhook, error)
+	List(ctx context.Context) ([]models.Webhook, error)
+	Update(ctx context.Context, hook *models.Webhook) error
+	Delete(ctx context.Context, id int) error
+}
+
+// DeliveryStore reads the log of notification deliveries
+type DeliveryStore interface {
+	ListRecent(ctx context.Context, channel, status *string, limit int) ([]models.NotificationDelivery, error)
+}
+
+var (
+	_ UserStore             = (*UserRepository)(nil)
+	_ MatchStore            = (*MatchRepository)(nil)
+	_ UserSportsStore       = (*UserSportsRepository)(nil)
+	_ RankingStore          = (*LeaderboardRankingRepository)(nil)
+	_ ELOHistoryStore       = (*ELOHistoryRepository)(nil)
+	_ SnapshotStore         = (*SnapshotRepository)(nil)
+	_ ChallengeStore        = (*ChallengeRepository)(nil)
+	_ SeasonStore           = (*SeasonRepository)(nil)
+	_ ELOReplayStore        = (*ELOReplayRepository)(nil)
+	_ MatchFlagStore        = (*MatchFlagRepository)(nil)
+	_ DeletionRequestStore  = (*DeletionRequestRepository)(nil)
+	_ AnonymizationStore    = (*AnonymizationRepository)(nil)
+	_ DataExportStore       = (*DataExportRepository)(nil)
+	_ DigestStore           = (*DigestRepository)(nil)
+	_ ProfileSyncStore      = (*ProfileSyncRepository)(nil)
+	_ AdminStore            = (*AdminRepository)(nil)
+	_ CommentStore          = (*CommentRepository)(nil)
+	_ ReactionStore         = (*ReactionRepository)(nil)
+	_ ReportStore           = (*ReportRepository)(nil)
+	_ LegalStore            = (*LegalRepository)(nil)
+	_ IncidentStore         = (*IncidentRepository)(nil)
+	_ SlackStore            = (*SlackRepository)(nil)
+	_ TrustedClientStore    = (*TrustedClientRepository)(nil)
+	_ UsageStore            = (*UsageRepository)(nil)
+	_ UserNotificationStore = (*UserNotificationRepository)(nil)
+	_ WebhookStore          = (*WebhookRepository)(nil)
+	_ DeliveryStore         = (*DeliveryRepository)(nil)
+)
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// TrustedClientRepository handles API keys of clients with relaxed rate limits
+type TrustedClientRepository struct {
+	db *sql.DB
+}
+
+// NewTrustedClientRepository creates a new TrustedClientRepository instance
+func NewTrustedClientRepository(db *sql.DB) *TrustedClientRepository {
+	return &TrustedClientRepository{db: db}
+}
+
+const trustedClientColumns = `id, name, key_hash, tier, active, created_by, created_at, updated_at`
+
+func scanTrustedClient(row interface{ Scan(...interface{}) error }) (*models.TrustedClient, error) {
+	client := &models.TrustedClient{}
+	err := row.Scan(
+		&client.ID,
+		&client.Name,
+		&client.KeyHash,
+		&client.Tier,
+		&client.Active,
+		&client.CreatedBy,
+		&client.CreatedAt,
+		&client.UpdatedAt,
+	)
+	return client, err
+}
+
+// Create registers a trusted client
+func (r *TrustedClientRepository) Create(ctx context.Context, client *models.TrustedClient) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO trusted_clients (name, key_hash, tier, active, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, client.Name, client.KeyHash, client.Tier, client.Active, client.CreatedBy).
+		Scan(&client.ID, &client.CreatedAt, &client.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create trusted client: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a trusted client by ID
+func (r *TrustedClientRepository) GetByID(ctx context.Context, id int) (*models.TrustedClient, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT ` + trustedClientColumns + ` FROM trusted_clients WHERE id = $1`
+
+	client, err := scanTrustedClient(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("trusted client not found")
+	}
+	return client, err
+}
+
+// List returns all trusted clients, oldest first
+func (r *TrustedClientRepository) List(ctx context.Context) ([]models.TrustedClient, error) {
+	return r.query(ctx, `SELECT `+trustedClientColumns+` FROM trusted_clients ORDER BY id`)
+}
+
+// ListActive returns the trusted clients whose keys are currently accepted
+func (r *TrustedClientRepository) ListActive(ctx context.Context) ([]models.TrustedClient, error) {
+	return r.query(ctx, `SELECT `+trustedClientColumns+` FROM trusted_clients WHERE active ORDER BY id`)
+}
+
+func (r *TrustedClientRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.TrustedClient, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trusted clients: %w", err)
+	}
+	defer rows.Close()
+
+	clients := []models.TrustedClient{}
+	for rows.Next() {
+		client, err := scanTrustedClient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trusted client: %w", err)
+		}
+		clients = append(clients, *client)
+	}
+	return clients, rows.Err()
+}
+
+// Update saves the name, tier and active flag of a trusted client
+func (r *TrustedClientRepository) Update(ctx context.Context, client *models.TrustedClient) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE trusted_clients SET name = $1, tier = $2, active = $3
+		WHERE id = $4
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, client.Name, client.Tier, client.Active, client.ID).Scan(&client.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("trusted client not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update trusted client: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a trusted client; its key stops working
+func (r *TrustedClientRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM trusted_clients WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete trusted client: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete trusted client: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("trusted client not found")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
+
+// UsageRepository handles per-user API usage counters
+type UsageRepository struct {
+	db *sql.DB
+}
+
+// NewUsageRepository creates a new UsageRepository instance
+func NewUsageRepository(db *sql.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// AddCounts adds sampled counts to the daily totals in one transaction
+// Counts for users that no longer exist are dropped
+func (r *UsageRepository) AddCounts(ctx context.Context, counts []models.APIUsageCount) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO api_usage (user_id, method, route, day, requests, throttled, errors)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE EXISTS (SELECT 1 FROM users WHERE id = $1)
+		ON CONFLICT (user_id, method, route, day) DO UPDATE SET
+			requests = api_usage.requests + EXCLUDED.requests,
+			throttled = api_usage.throttled + EXCLUDED.throttled,
+			errors = api_usage.errors + EXCLUDED.errors
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare usage upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, c := range counts {
+		if _, err := stmt.ExecContext(ctx, c.UserID, c.Method, c.Route, c.Day, c.Requests, c.Throttled, c.Errors); err != nil {
+			return fmt.Errorf("failed to store usage: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetUserUsage returns a user's per-route totals since the given day, busiest first
+func (r *UsageRepository) GetUserUsage(ctx context.Context, userID int, since time.Time) ([]models.APIEndpointUsage, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT method, route, SUM(requests), SUM(throttled), SUM(errors)
+		FROM api_usage
+		WHERE user_id = $1 AND day >= $2
+		GROUP BY method, route
+		ORDER BY SUM(requests) DESC, route, method
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []models.APIEndpointUsage{}
+	for rows.Next() {
+		var u models.APIEndpointUsage
+		if err := rows.Scan(&u.Method, &u.Route, &u.Requests, &u.Throttled, &u.Errors); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
+
+// GetTopUsers returns the users with the most requests since the given day
+// Users are ordered by throttled requests first so clients hitting rate limits surface on top
+func (r *UsageRepository) GetTopUsers(ctx context.Context, since time.Time, limit int) ([]models.APIUsageUserSummary, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		WITH per_route AS (
+			SELECT user_id, method, route,
+			       SUM(requests) AS requests,
+			       SUM(throttled) AS throttled,
+			       SUM(errors) AS errors
+			FROM api_usage
+			WHERE day >= $1
+			GROUP BY user_id, method, route
+		),
+		per_user AS (
+			SELECT user_id,
+			       SUM(requests) AS requests,
+			       SUM(throttled) AS throttled,
+			       SUM(errors) AS errors
+			FROM per_route
+			GROUP BY user_id
+		),
+		top_route AS (
+			SELECT DISTINCT ON (user_id) user_id, method || ' ' || route AS route
+			FROM per_route
+			ORDER BY user_id, requests DESC, route, method
+		)
+		SELECT u.id, u.login, pu.requests, pu.throttled, pu.errors, tr.route
+		FROM per_user pu
+		JOIN users u ON u.id = pu.user_id
+		JOIN top_route tr ON tr.user_id = pu.user_id
+		ORDER BY pu.throttled DESC, pu.requests DESC, u.id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top users: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []models.APIUsageUserSummary{}
+	for rows.Next() {
+		var s models.APIUsageUserSummary
+		if err := rows.Scan(&s.UserID, &s.Login, &s.Requests, &s.Throttled, &s.Errors, &s.TopRoute); err != nil {
+			return nil, fmt.Errorf("failed to scan usage summary: %w", err)
+		}
+		summaries = append(summaries, s)
+	}
+
+	return summaries, rows.Err()
+}
+
+// PurgeBefore deletes usage counters for days before the cutoff
+func (r *UsageRepository) PurgeBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_usage WHERE day < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/lib/pq"
+)
+
+// UserNotificationRepository handles the per-user notification inbox
+type UserNotificationRepository struct {
+	db *sql.DB
+}
+
+// NewUserNotificationRepository creates a new UserNotificationRepository instance
+func NewUserNotificationRepository(db *sql.DB) *UserNotificationRepository {
+	return &UserNotificationRepository{db: db}
+}
+
+// Create stores a notification
+func (r *UserNotificationRepository) Create(ctx context.Context, n *models.UserNotification) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var data []byte
+	if n.Data != nil {
+		var err error
+		data, err = json.Marshal(n.Data)
+		if err != nil {
+			return fmt.Errorf("failed to encode notification data: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO user_notifications (user_id, kind, sport_id, title, body, data)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, n.UserID, n.Kind, n.Sport, n.Title, n.Body, data).Scan(&n.ID, &n.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// ListForUser returns a user's notifications, newest first
+func (r *UserNotificationRepository) ListForUser(ctx context.Context, userID int, unreadOnly bool, limit int) ([]models.UserNotification, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, kind, sport_id, title, body, data, read_at, created_at
+		FROM user_notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.UserNotification{}
+	for rows.Next() {
+		var n models.UserNotification
+		var data []byte
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Sport, &n.Title, &n.Body, &data, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		if data != nil {
+			if err := json.Unmarshal(data, &n.Data); err != nil {
+				return nil, fmt.Errorf("failed to decode notification data: %w", err)
+			}
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// CountUnread returns how many unread notifications a user has
+func (r *UserNotificationRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks a user's notifications as read; empty ids marks all of them
+func (r *UserNotificationRepository) MarkRead(ctx context.Context, userID int, ids []int) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE user_notifications SET read_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND read_at IS NULL
+	`
+	args := []interface{}{userID}
+	if len(ids) > 0 {
+		query += ` AND id = ANY($2)`
+		args = append(args, pq.Array(ids))
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// PurgeBefore deletes notifications older than the cutoff
+func (r *UserNotificationRepository) PurgeBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_notifications WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -1,12 +1,53 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/lib/pq"
 )
 
+// ErrIntraIDInUse is returned when relinking to an intra ID whose own profile already has activity
+var ErrIntraIDInUse = errors.New("intra ID already belongs to a profile with match history")
+
+// ErrUsersPlayedEachOther is returned when merging two profiles that share a match
+var ErrUsersPlayedEachOther = errors.New("profiles have played each other and cannot be merged")
+
+// ErrUsersShareSports is returned when merging two profiles that both have a rating in a sport;
+// one of them would be lost, as both were computed without the other's matches
+var ErrUsersShareSports = errors.New("profiles both have ratings in the same sport and cannot be merged")
+
+// userSportsColumn aggregates a user's ratings from user_sports into the JSON object read by
+// scanSports; selects that use it must not alias the users table
+const userSportsColumn = `COALESCE((
+			SELECT json_object_agg(us.sport_id, json_build_object(
+				'current_elo', us.current_elo, 'highest_elo', us.highest_elo,
+				'matches_played', us.matches_played, 'wins', us.wins, 'losses', us.losses))
+			FROM user_sports us WHERE us.user_id = users.id
+		), '{}')`
+
+// sportsScanner reads userSportsColumn into a user's Sports map
+type sportsScanner struct {
+	sports *map[string]models.UserSportData
+}
+
+func scanSports(sports *map[string]models.UserSportData) sportsScanner {
+	return sportsScanner{sports: sports}
+}
+
+func (s sportsScanner) Scan(src any) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unexpected sports column type %T", src)
+	}
+	return json.Unmarshal(data, s.sports)
+}
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -16,20 +57,33 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 }
 
 // CreateOrUpdate creates a new user or updates if exists
-func (r *UserRepository) CreateOrUpdate(user *models.User) error {
+// and returns whether it was created; a new user is ranked right away in the sports
+// without placement matches
+func (r *UserRepository) CreateOrUpdate(ctx context.Context, user *models.User) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO users (id, login, display_name, avatar_url, campus)
 		VALUES ($1, $2, $3, $4, $5)
 		ON CONFLICT (id) DO UPDATE SET
 			login = EXCLUDED.login,
-			display_name = EXCLUDED.display_name,
-			avatar_url = EXCLUDED.avatar_url,
+			-- Display data erased by the user (partial GDPR erasure) is not restored
+			display_name = CASE WHEN users.display_data_erased_at IS NULL THEN EXCLUDED.display_name ELSE users.display_name END,
+			avatar_url = CASE WHEN users.display_data_erased_at IS NULL THEN EXCLUDED.avatar_url ELSE users.avatar_url END,
 			campus = EXCLUDED.campus,
 			updated_at = CURRENT_TIMESTAMP
-		RETURNING id, table_tennis_elo, table_football_elo, created_at, updated_at
+		RETURNING id, created_at, updated_at, xmax = 0
 	`
 
-	return r.db.QueryRow(
+	var created bool
+	err = tx.QueryRowContext(ctx,
 		query,
 		user.IntraID,
 		user.Login,
@@ -38,39 +92,117 @@ func (r *UserRepository) CreateOrUpdate(user *models.User) error {
 		user.Campus,
 	).Scan(
 		&user.ID,
-		&user.TableTennisELO,
-		&user.TableFootballELO,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&created,
 	)
+	if err != nil {
+		return false, err
+	}
+
+	if created {
+		if _, err := rankPlayers(ctx, tx, []int{user.ID}); err != nil {
+			return false, err
+		}
+	}
+
+	return created, tx.Commit()
+}
+
+// SetIntraProfile stores the coalition and piscine year reported by the 42 API
+// The coalition row is created or refreshed first; nil clears the user's coalition
+func (r *UserRepository) SetIntraProfile(ctx context.Context, userID int, coalition *models.Coalition, poolYear string) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var coalitionID *int
+	if coalition != nil {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO coalitions (id, name, slug, color, image_url)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (id) DO UPDATE SET
+				name = EXCLUDED.name,
+				slug = EXCLUDED.slug,
+				color = EXCLUDED.color,
+				image_url = EXCLUDED.image_url,
+				updated_at = CURRENT_TIMESTAMP
+		`, coalition.ID, coalition.Name, coalition.Slug, coalition.Color, coalition.ImageURL)
+		if err != nil {
+			return fmt.Errorf("failed to store coalition: %w", err)
+		}
+		coalitionID = &coalition.ID
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users SET coalition_id = $2, pool_year = NULLIF($3, '') WHERE id = $1
+	`, userID, coalitionID, poolYear)
+	if err != nil {
+		return fmt.Errorf("failed to store intra profile: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateDisplayData refreshes the display name and avatar from the 42 API
+// Users who erased their display data are left alone; returns whether anything changed
+func (r *UserRepository) UpdateDisplayData(ctx context.Context, userID int, displayName, avatarURL string) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET display_name = $2, avatar_url = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND display_data_erased_at IS NULL
+		  AND (display_name IS DISTINCT FROM $2 OR avatar_url IS DISTINCT FROM $3)
+	`, userID, displayName, avatarURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to update display data: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
 }
 
 // GetByID retrieves a user by ID
-func (r *UserRepository) GetByID(id int) (*models.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	user := &models.User{}
 	query := `
 		SELECT id, id, login, display_name, avatar_url, campus,
-		       table_tennis_elo, table_football_elo, is_admin, is_banned,
-		       ban_reason, banned_at, banned_by, created_at, updated_at
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       coalition_id, pool_year, role,
+		       ` + userSportsColumn + `
 		FROM users WHERE id = $1
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.IntraID,
 		&user.Login,
 		&user.DisplayName,
 		&user.AvatarURL,
 		&user.Campus,
-		&user.TableTennisELO,
-		&user.TableFootballELO,
 		&user.IsAdmin,
 		&user.IsBanned,
 		&user.BanReason,
 		&user.BannedAt,
 		&user.BannedBy,
+		&user.BannedUntil,
+		&user.DeactivatedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.CoalitionID,
+		&user.PoolYear,
+		&user.Role,
+		scanSports(&user.Sports),
 	)
 
 	if err == sql.ErrNoRows {
@@ -81,31 +213,40 @@ func (r *UserRepository) GetByID(id int) (*models.User, error) {
 }
 
 // GetByIntraID retrieves a user by Intra ID
-func (r *UserRepository) GetByIntraID(intraID int) (*models.User, error) {
+func (r *UserRepository) GetByIntraID(ctx context.Context, intraID int) (*models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	user := &models.User{}
 	query := `
 		SELECT id, id, login, display_name, avatar_url, campus,
-		       table_tennis_elo, table_football_elo, is_admin, is_banned,
-		       ban_reason, banned_at, banned_by, created_at, updated_at
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       coalition_id, pool_year, role,
+		       ` + userSportsColumn + `
 		FROM users WHERE id = $1
 	`
 
-	err := r.db.QueryRow(query, intraID).Scan(
+	err := r.db.QueryRowContext(ctx, query, intraID).Scan(
 		&user.ID,
 		&user.IntraID,
 		&user.Login,
 		&user.DisplayName,
 		&user.AvatarURL,
 		&user.Campus,
-		&user.TableTennisELO,
-		&user.TableFootballELO,
 		&user.IsAdmin,
 		&user.IsBanned,
 		&user.BanReason,
 		&user.BannedAt,
 		&user.BannedBy,
+		&user.BannedUntil,
+		&user.DeactivatedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.CoalitionID,
+		&user.PoolYear,
+		&user.Role,
+		scanSports(&user.Sports),
 	)
 
 	if err == sql.ErrNoRows {
@@ -115,34 +256,185 @@ func (r *UserRepository) GetByIntraID(intraID int) (*models.User, error) {
 	return user, err
 }
 
+// GetByLogin retrieves a user by intra login
+func (r *UserRepository) GetByLogin(ctx context.Context, login string) (*models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	query := `
+		SELECT id, id, login, display_name, avatar_url, campus,
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       ` + userSportsColumn + `
+		FROM users WHERE login = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, login).Scan(
+		&user.ID,
+		&user.IntraID,
+		&user.Login,
+		&user.DisplayName,
+		&user.AvatarURL,
+		&user.Campus,
+		&user.IsAdmin,
+		&user.IsBanned,
+		&user.BanReason,
+		&user.BannedAt,
+		&user.BannedBy,
+		&user.BannedUntil,
+		&user.DeactivatedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		scanSports(&user.Sports),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return user, err
+}
+
+// GetByLogins retrieves the users with the given intra logins; unknown logins are skipped
+func (r *UserRepository) GetByLogins(ctx context.Context, logins []string) ([]models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, id, login, display_name, avatar_url, campus,
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       ` + userSportsColumn + `
+		FROM users WHERE login = ANY($1)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(logins))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(
+			&user.ID,
+			&user.IntraID,
+			&user.Login,
+			&user.DisplayName,
+			&user.AvatarURL,
+			&user.Campus,
+			&user.IsAdmin,
+			&user.IsBanned,
+			&user.BanReason,
+			&user.BannedAt,
+			&user.BannedBy,
+			&user.BannedUntil,
+			&user.DeactivatedAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			scanSports(&user.Sports),
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// GetByIDs retrieves the users with the given IDs; unknown IDs are skipped
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []int) ([]models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, id, login, display_name, avatar_url, campus,
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       ` + userSportsColumn + `
+		FROM users WHERE id = ANY($1)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(
+			&user.ID,
+			&user.IntraID,
+			&user.Login,
+			&user.DisplayName,
+			&user.AvatarURL,
+			&user.Campus,
+			&user.IsAdmin,
+			&user.IsBanned,
+			&user.BanReason,
+			&user.BannedAt,
+			&user.BannedBy,
+			&user.BannedUntil,
+			&user.DeactivatedAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			scanSports(&user.Sports),
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// CountPlayers returns the number of registered players, without the deleted-user placeholder
+func (r *UserRepository) CountPlayers(ctx context.Context) (int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE id != -1`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count players: %w", err)
+	}
+	return count, nil
+}
+
 // GetByIDForUpdate retrieves a user by ID with a row lock for update
 // This should be used within a transaction to prevent race conditions
-func (r *UserRepository) GetByIDForUpdate(tx *sql.Tx, id int) (*models.User, error) {
+func (r *UserRepository) GetByIDForUpdate(ctx context.Context, tx *sql.Tx, id int) (*models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	user := &models.User{}
 	query := `
 		SELECT id, id, login, display_name, avatar_url, campus,
-		       table_tennis_elo, table_football_elo, is_admin, is_banned,
-		       ban_reason, banned_at, banned_by, created_at, updated_at
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       ` + userSportsColumn + `
 		FROM users WHERE id = $1
 		FOR UPDATE
 	`
 
-	err := tx.QueryRow(query, id).Scan(
+	err := tx.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.IntraID,
 		&user.Login,
 		&user.DisplayName,
 		&user.AvatarURL,
 		&user.Campus,
-		&user.TableTennisELO,
-		&user.TableFootballELO,
 		&user.IsAdmin,
 		&user.IsBanned,
 		&user.BanReason,
 		&user.BannedAt,
 		&user.BannedBy,
+		&user.BannedUntil,
+		&user.DeactivatedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		scanSports(&user.Sports),
 	)
 
 	if err == sql.ErrNoRows {
@@ -153,17 +445,21 @@ func (r *UserRepository) GetByIDForUpdate(tx *sql.Tx, id int) (*models.User, err
 }
 
 // GetAll retrieves all users
-func (r *UserRepository) GetAll() ([]models.User, error) {
+func (r *UserRepository) GetAll(ctx context.Context) ([]models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, id, login, display_name, avatar_url, campus,
-		       table_tennis_elo, table_football_elo, is_admin, is_banned,
-		       ban_reason, banned_at, banned_by, created_at, updated_at
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       ` + userSportsColumn + `
 		FROM users
 		WHERE id != -1
 		ORDER BY login
 	`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -179,15 +475,16 @@ func (r *UserRepository) GetAll() ([]models.User, error) {
 			&user.DisplayName,
 			&user.AvatarURL,
 			&user.Campus,
-			&user.TableTennisELO,
-			&user.TableFootballELO,
 			&user.IsAdmin,
 			&user.IsBanned,
 			&user.BanReason,
 			&user.BannedAt,
 			&user.BannedBy,
+			&user.BannedUntil,
+			&user.DeactivatedAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			scanSports(&user.Sports),
 		); err != nil {
 			return nil, err
 		}
@@ -197,36 +494,442 @@ func (r *UserRepository) GetAll() ([]models.User, error) {
 	return users, rows.Err()
 }
 
-// UpdateELO updates a user's ELO rating for a specific sport
-func (r *UserRepository) UpdateELO(tx *sql.Tx, userID int, sport string, newELO int) error {
-	var query string
-	if sport == models.SportTableTennis {
-		query = `UPDATE users SET table_tennis_elo = $1 WHERE id = $2`
-	} else {
-		query = `UPDATE users SET table_football_elo = $1 WHERE id = $2`
+// GetLinkedUserID resolves an intra ID that was relinked to an existing profile
+// Returns false if no link exists for the intra ID
+func (r *UserRepository) GetLinkedUserID(ctx context.Context, intraID int) (int, bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var userID int
+	err := r.db.QueryRowContext(ctx, `SELECT user_id FROM intra_id_links WHERE intra_id = $1`, intraID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
 	}
+	return userID, true, nil
+}
 
-	var result sql.Result
-	var err error
+// FindRelinkCandidate finds an older profile with the same login but a different ID
+// This is the typical result of a 42 account migration that issued a new intra ID
+func (r *UserRepository) FindRelinkCandidate(ctx context.Context, login string, excludeID int) (*models.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
 
-	if tx != nil {
-		result, err = tx.Exec(query, newELO, userID)
-	} else {
-		result, err = r.db.Exec(query, newELO, userID)
+	user := &models.User{}
+	query := `
+		SELECT id, id, login, display_name, avatar_url, campus,
+		       is_admin, is_banned,
+		       ban_reason, banned_at, banned_by, banned_until, deactivated_at, created_at, updated_at,
+		       ` + userSportsColumn + `
+		FROM users
+		WHERE login = $1 AND id != $2 AND id != -1
+		  AND id NOT IN (SELECT intra_id FROM intra_id_links)
+		ORDER BY created_at ASC
+		LIMIT 1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, login, excludeID).Scan(
+		&user.ID,
+		&user.IntraID,
+		&user.Login,
+		&user.DisplayName,
+		&user.AvatarURL,
+		&user.Campus,
+		&user.IsAdmin,
+		&user.IsBanned,
+		&user.BanReason,
+		&user.BannedAt,
+		&user.BannedBy,
+		&user.BannedUntil,
+		&user.DeactivatedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		scanSports(&user.Sports),
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return user, err
+}
+
+// RelinkIntraID links a new intra ID to an existing profile so future logins resolve to it
+// If a fresh profile was already created for the new intra ID it is removed, provided it
+// has no matches or comments; otherwise ErrIntraIDInUse is returned.
+// source is "admin" or "self_service"; linkedBy is the acting admin (nil for self-service)
+func (r *UserRepository) RelinkIntraID(ctx context.Context, newIntraID, userID int, source string, linkedBy *int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	if newIntraID == userID {
+		return fmt.Errorf("intra ID already belongs to this profile")
 	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	// Lock the target profile so it cannot be deleted concurrently
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT true FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user not found")
+		}
+		return err
+	}
+
+	// Remove the fresh profile for the new intra ID, if any, but never discard history
+	var activity int
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM matches WHERE player1_id = $1 OR player2_id = $1 OR submitted_by = $1) +
+			(SELECT COUNT(*) FROM comments WHERE user_id = $1)
+	`, newIntraID).Scan(&activity)
 	if err != nil {
 		return err
 	}
+	if activity > 0 {
+		return ErrIntraIDInUse
+	}
 
-	if rows == 0 {
-		return fmt.Errorf("user not found")
+	if _, err := unrankPlayers(ctx, tx, []int{newIntraID}); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, newIntraID); err != nil {
+		return err
 	}
 
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO intra_id_links (intra_id, user_id, source, linked_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (intra_id) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			source = EXCLUDED.source,
+			linked_by = EXCLUDED.linked_by,
+			created_at = CURRENT_TIMESTAMP
+	`, newIntraID, userID, source, linkedBy)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MergeUsers moves the history of a duplicate profile (source) into the kept profile (target)
+// and removes the duplicate; its intra ID is linked to the target so logins resolve there.
+// Matches, comments and reactions move, and so do the ratings, ELO history and adjustments of
+// the source; profiles that both have a rating in a sport are rejected with ErrUsersShareSports,
+// a rating of the target that was never played gives way to the source's. The target's
+// records are recomputed from the combined matches. Other personal data of the source (notifications,
+// challenges, exports) is deleted with it. adminID is the acting admin
+func (r *UserRepository) MergeUsers(ctx context.Context, sourceID, targetID, adminID int) (*models.UserMergeResult, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	if sourceID == targetID {
+		return nil, fmt.Errorf("cannot merge a profile into itself")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock both profiles in a fixed order so concurrent merges cannot deadlock
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM users WHERE id IN ($1, $2) ORDER BY id FOR UPDATE`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	locked := 0
+	for rows.Next() {
+		locked++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if locked != 2 {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	// A shared match would become a match against oneself
+	var played bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM matches WHERE (player1_id = $1 AND player2_id = $2) OR (player1_id = $2 AND player2_id = $1)
+			UNION ALL
+			SELECT 1 FROM matches_archive WHERE (player1_id = $1 AND player2_id = $2) OR (player1_id = $2 AND player2_id = $1)
+		)
+	`, sourceID, targetID).Scan(&played)
+	if err != nil {
+		return nil, err
+	}
+	if played {
+		return nil, ErrUsersPlayedEachOther
+	}
+
+	// A rating counts once it has been played or changed; both ratings of a sport could only be
+	// combined by replaying the merged matches, which changes the opponents' ratings as well
+	var shared []string
+	err = tx.QueryRowContext(ctx, `
+		WITH rated AS (
+			SELECT us.user_id, us.sport_id
+			FROM user_sports us
+			WHERE us.user_id IN ($1, $2)
+			  AND (us.matches_played > 0 OR EXISTS (
+				SELECT 1 FROM elo_history h WHERE h.user_id = us.user_id AND h.sport_id = us.sport_id
+			  ))
+		)
+		SELECT COALESCE(array_agg(s.sport_id ORDER BY s.sport_id), '{}')
+		FROM rated s
+		JOIN rated t ON t.sport_id = s.sport_id AND t.user_id = $2
+		WHERE s.user_id = $1
+	`, sourceID, targetID).Scan(pq.Array(&shared))
+	if err != nil {
+		return nil, err
+	}
+	if len(shared) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUsersShareSports, strings.Join(shared, ", "))
+	}
+
+	result := &models.UserMergeResult{SourceID: sourceID, TargetID: targetID, Sports: []string{}}
+
+	// Unplayed ratings of the target make way for the source's
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM user_sports t
+		WHERE t.user_id = $2
+		  AND t.sport_id IN (SELECT sport_id FROM user_sports WHERE user_id = $1)
+		  AND t.matches_played = 0
+		  AND NOT EXISTS (SELECT 1 FROM elo_history h WHERE h.user_id = $2 AND h.sport_id = t.sport_id)
+	`, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to drop unplayed ratings: %w", err)
+	}
+
+	// Ratings move with their history and adjustments; the source's unplayed ones in sports the
+	// target has played are dropped with the profile
+	err = tx.QueryRowContext(ctx, `
+		WITH moved AS (
+			UPDATE user_sports SET user_id = $2, updated_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1
+			  AND sport_id NOT IN (SELECT sport_id FROM user_sports WHERE user_id = $2)
+			RETURNING sport_id
+		)
+		SELECT COALESCE(array_agg(sport_id ORDER BY sport_id), '{}') FROM moved
+	`, sourceID, targetID).Scan(pq.Array(&result.Sports))
+	if err != nil {
+		return nil, fmt.Errorf("failed to move ratings: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE elo_history SET user_id = $2 WHERE user_id = $1 AND sport_id = ANY($3)`, sourceID, targetID, pq.Array(result.Sports)); err != nil {
+		return nil, fmt.Errorf("failed to move elo history: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE elo_adjustments SET user_id = $2 WHERE user_id = $1 AND sport = ANY($3)`, sourceID, targetID, pq.Array(result.Sports)); err != nil {
+		return nil, fmt.Errorf("failed to move elo adjustments: %w", err)
+	}
+
+	// Player IDs and winner_id change together to satisfy the valid_winner CHECK constraint
+	for _, table := range []string{"matches", "matches_archive"} {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE `+table+` SET
+				player1_id = CASE WHEN player1_id = $1 THEN $2 ELSE player1_id END,
+				player2_id = CASE WHEN player2_id = $1 THEN $2 ELSE player2_id END,
+				winner_id = CASE WHEN winner_id = $1 THEN $2 ELSE winner_id END,
+				submitted_by = CASE WHEN submitted_by = $1 THEN $2 ELSE submitted_by END,
+				forfeited_by = CASE WHEN forfeited_by = $1 THEN $2 ELSE forfeited_by END,
+				deleted_by = CASE WHEN deleted_by = $1 THEN $2 ELSE deleted_by END
+			WHERE player1_id = $1 OR player2_id = $1 OR submitted_by = $1 OR deleted_by = $1
+		`, sourceID, targetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move %s: %w", table, err)
+		}
+		moved, _ := res.RowsAffected()
+		result.Matches += moved
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE comments SET user_id = $2 WHERE user_id = $1`, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move comments: %w", err)
+	}
+	result.Comments, _ = res.RowsAffected()
+
+	// Both profiles may have left the same reaction on a match; the target's one is kept
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM reactions s USING reactions t
+		WHERE s.user_id = $1 AND t.user_id = $2 AND t.match_id = s.match_id AND t.emoji = s.emoji
+	`, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move reactions: %w", err)
+	}
+	res, err = tx.ExecContext(ctx, `UPDATE reactions SET user_id = $2 WHERE user_id = $1`, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move reactions: %w", err)
+	}
+	result.Reactions, _ = res.RowsAffected()
+
+	// Past standings move where the target has none of its own for that day or season
+	for _, table := range []string{"season_results", "leaderboard_snapshots"} {
+		key := "season_id"
+		if table == "leaderboard_snapshots" {
+			key = "snapshot_date"
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE `+table+` s SET user_id = $2
+			WHERE s.user_id = $1 AND NOT EXISTS (
+				SELECT 1 FROM `+table+` t
+				WHERE t.user_id = $2 AND t.`+key+` = s.`+key+` AND t.sport_id = s.sport_id
+			)
+		`, sourceID, targetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move %s: %w", table, err)
+		}
+	}
+
+	// Staff actions of the source stay attributed to the same person
+	for _, ref := range []struct{ table, column string }{
+		{"elo_adjustments", "adjusted_by"},
+		{"admin_audit_log", "admin_id"},
+		{"users", "banned_by"},
+	} {
+		if _, err := tx.ExecContext(ctx, `UPDATE `+ref.table+` SET `+ref.column+` = $2 WHERE `+ref.column+` = $1`, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("failed to move %s.%s: %w", ref.table, ref.column, err)
+		}
+	}
+
+	// Intra IDs that logged in as the source, and the source's own, now log in as the target
+	if _, err := tx.ExecContext(ctx, `UPDATE intra_id_links SET user_id = $2 WHERE user_id = $1`, sourceID, targetID); err != nil {
+		return nil, fmt.Errorf("failed to move intra ID links: %w", err)
+	}
+	if _, err := unrankPlayers(ctx, tx, []int{sourceID}); err != nil {
+		return nil, fmt.Errorf("failed to unrank merged profile: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to delete merged profile: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO intra_id_links (intra_id, user_id, source, linked_by)
+		VALUES ($1, $2, 'admin', $3)
+		ON CONFLICT (intra_id) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			source = EXCLUDED.source,
+			linked_by = EXCLUDED.linked_by,
+			created_at = CURRENT_TIMESTAMP
+	`, sourceID, targetID, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link intra ID: %w", err)
+	}
+
+	if _, err := reconcileUserSportStats(ctx, tx, []int{targetID}); err != nil {
+		return nil, err
+	}
+	if _, err := rankPlayers(ctx, tx, []int{targetID}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to complete merge: %w", err)
+	}
+	return result, nil
+}
+
+// Deactivate hides a player from the leaderboards and blocks new matches against them
+// until their next login; their data is kept. Returns false if already deactivated
+func (r *UserRepository) Deactivate(ctx context.Context, userID int) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET deactivated_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deactivated_at IS NULL
+	`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to deactivate account: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to deactivate account: %w", err)
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	if _, err := rankPlayers(ctx, tx, []int{userID}); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Reactivate undoes a deactivation; called on login. Returns false if the account was active
+func (r *UserRepository) Reactivate(ctx context.Context, userID int) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET deactivated_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deactivated_at IS NOT NULL
+	`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to reactivate account: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to reactivate account: %w", err)
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	if _, err := rankPlayers(ctx, tx, []int{userID}); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GetLanguage returns the language a user receives notifications in
+func (r *UserRepository) GetLanguage(ctx context.Context, userID int) (string, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var language string
+	err := r.db.QueryRowContext(ctx, `SELECT language FROM users WHERE id = $1`, userID).Scan(&language)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("user not found")
+	}
+	return language, err
+}
+
+// SetLanguage changes the language a user receives notifications in
+func (r *UserRepository) SetLanguage(ctx context.Context, userID int, language string) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET language = $1 WHERE id = $2`, language, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update language: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update language: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("user not found")
+	}
 	return nil
 }
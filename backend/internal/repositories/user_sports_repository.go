@@ -1,22 +1,29 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/lib/pq"
 )
 
 // UserSportData represents a user's statistics for a specific sport
 type UserSportData struct {
-	UserID        int       `json:"user_id"`
-	SportID       string    `json:"sport_id"`
-	CurrentELO    int       `json:"current_elo"`
-	HighestELO    int       `json:"highest_elo"`
-	MatchesPlayed int       `json:"matches_played"`
-	Wins          int       `json:"wins"`
-	Losses        int       `json:"losses"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	UserID           int        `json:"user_id"`
+	SportID          string     `json:"sport_id"`
+	CurrentELO       int        `json:"current_elo"`
+	HighestELO       int        `json:"highest_elo"`
+	MatchesPlayed    int        `json:"matches_played"`
+	Wins             int        `json:"wins"`
+	Losses           int        `json:"losses"`
+	CurrentStreak    int        `json:"current_streak"` // Positive for wins in a row, negative for losses
+	LongestWinStreak int        `json:"longest_win_streak"`
+	LastMatchAt      *time.Time `json:"last_match_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
 // UserSportsRepository handles database operations for user sports data
@@ -31,11 +38,14 @@ func NewUserSportsRepository(db *sql.DB) *UserSportsRepository {
 
 // GetUserELO retrieves a user's current ELO for a specific sport
 // Returns the default ELO (1000) if no record exists
-func (r *UserSportsRepository) GetUserELO(userID int, sportID string) (int, error) {
+func (r *UserSportsRepository) GetUserELO(ctx context.Context, userID int, sportID string) (int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var currentELO int
 	query := `SELECT current_elo FROM user_sports WHERE user_id = $1 AND sport_id = $2`
 
-	err := r.db.QueryRow(query, userID, sportID).Scan(&currentELO)
+	err := r.db.QueryRowContext(ctx, query, userID, sportID).Scan(&currentELO)
 	if err == sql.ErrNoRows {
 		return 1000, nil // Default ELO for new users
 	}
@@ -48,11 +58,14 @@ func (r *UserSportsRepository) GetUserELO(userID int, sportID string) (int, erro
 
 // GetUserELOForUpdate retrieves a user's current ELO with a row lock for update
 // This should be used within a transaction to prevent race conditions
-func (r *UserSportsRepository) GetUserELOForUpdate(tx *sql.Tx, userID int, sportID string) (int, error) {
+func (r *UserSportsRepository) GetUserELOForUpdate(ctx context.Context, tx *sql.Tx, userID int, sportID string) (int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var currentELO int
 	query := `SELECT current_elo FROM user_sports WHERE user_id = $1 AND sport_id = $2 FOR UPDATE`
 
-	err := tx.QueryRow(query, userID, sportID).Scan(&currentELO)
+	err := tx.QueryRowContext(ctx, query, userID, sportID).Scan(&currentELO)
 	if err == sql.ErrNoRows {
 		return 1000, nil // Default ELO for new users
 	}
@@ -63,9 +76,32 @@ func (r *UserSportsRepository) GetUserELOForUpdate(tx *sql.Tx, userID int, sport
 	return currentELO, nil
 }
 
+// GetRatingForUpdate retrieves a user's current ELO and matches played with a row lock
+// This should be used within a transaction to prevent race conditions
+func (r *UserSportsRepository) GetRatingForUpdate(ctx context.Context, tx *sql.Tx, userID int, sportID string) (int, int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var currentELO, matchesPlayed int
+	query := `SELECT current_elo, matches_played FROM user_sports WHERE user_id = $1 AND sport_id = $2 FOR UPDATE`
+
+	err := tx.QueryRowContext(ctx, query, userID, sportID).Scan(&currentELO, &matchesPlayed)
+	if err == sql.ErrNoRows {
+		return 1000, 0, nil // Default ELO for new users
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get user rating for update: %w", err)
+	}
+
+	return currentELO, matchesPlayed, nil
+}
+
 // UpdateUserELO updates a user's ELO for a specific sport
 // Creates the record if it doesn't exist (upsert)
-func (r *UserSportsRepository) UpdateUserELO(tx *sql.Tx, userID int, sportID string, newELO int) error {
+func (r *UserSportsRepository) UpdateUserELO(ctx context.Context, tx *sql.Tx, userID int, sportID string, newELO int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO user_sports (user_id, sport_id, current_elo, highest_elo)
 		VALUES ($1, $2, $3, $3)
@@ -79,9 +115,9 @@ func (r *UserSportsRepository) UpdateUserELO(tx *sql.Tx, userID int, sportID str
 	var err error
 
 	if tx != nil {
-		result, err = tx.Exec(query, userID, sportID, newELO)
+		result, err = tx.ExecContext(ctx, query, userID, sportID, newELO)
 	} else {
-		result, err = r.db.Exec(query, userID, sportID, newELO)
+		result, err = r.db.ExecContext(ctx, query, userID, sportID, newELO)
 	}
 
 	if err != nil {
@@ -100,34 +136,42 @@ func (r *UserSportsRepository) UpdateUserELO(tx *sql.Tx, userID int, sportID str
 	return nil
 }
 
-// IncrementMatchStats updates a user's match statistics after a game
-func (r *UserSportsRepository) IncrementMatchStats(tx *sql.Tx, userID int, sportID string, won bool) error {
+// IncrementMatchStats updates a user's match statistics and streaks after a game
+func (r *UserSportsRepository) IncrementMatchStats(ctx context.Context, tx *sql.Tx, userID int, sportID string, won bool) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var query string
 	if won {
 		query = `
-			INSERT INTO user_sports (user_id, sport_id, matches_played, wins, losses)
-			VALUES ($1, $2, 1, 1, 0)
+			INSERT INTO user_sports (user_id, sport_id, matches_played, wins, losses, current_streak, longest_win_streak, last_match_at)
+			VALUES ($1, $2, 1, 1, 0, 1, 1, CURRENT_TIMESTAMP)
 			ON CONFLICT (user_id, sport_id) DO UPDATE SET
 				matches_played = user_sports.matches_played + 1,
 				wins = user_sports.wins + 1,
+				current_streak = GREATEST(user_sports.current_streak, 0) + 1,
+				longest_win_streak = GREATEST(user_sports.longest_win_streak, GREATEST(user_sports.current_streak, 0) + 1),
+				last_match_at = CURRENT_TIMESTAMP,
 				updated_at = CURRENT_TIMESTAMP
 		`
 	} else {
 		query = `
-			INSERT INTO user_sports (user_id, sport_id, matches_played, wins, losses)
-			VALUES ($1, $2, 1, 0, 1)
+			INSERT INTO user_sports (user_id, sport_id, matches_played, wins, losses, current_streak, longest_win_streak, last_match_at)
+			VALUES ($1, $2, 1, 0, 1, -1, 0, CURRENT_TIMESTAMP)
 			ON CONFLICT (user_id, sport_id) DO UPDATE SET
 				matches_played = user_sports.matches_played + 1,
 				losses = user_sports.losses + 1,
+				current_streak = LEAST(user_sports.current_streak, 0) - 1,
+				last_match_at = CURRENT_TIMESTAMP,
 				updated_at = CURRENT_TIMESTAMP
 		`
 	}
 
 	var err error
 	if tx != nil {
-		_, err = tx.Exec(query, userID, sportID)
+		_, err = tx.ExecContext(ctx, query, userID, sportID)
 	} else {
-		_, err = r.db.Exec(query, userID, sportID)
+		_, err = r.db.ExecContext(ctx, query, userID, sportID)
 	}
 
 	if err != nil {
@@ -138,13 +182,24 @@ func (r *UserSportsRepository) IncrementMatchStats(tx *sql.Tx, userID int, sport
 }
 
 // DecrementMatchStats reverses match statistics (used when reverting a match)
-func (r *UserSportsRepository) DecrementMatchStats(tx *sql.Tx, userID int, sportID string, wasWin bool) error {
+// Streaks are unwound assuming the reverted match was the player's latest result;
+// reverting an older match leaves them to ReconcileStats
+func (r *UserSportsRepository) DecrementMatchStats(ctx context.Context, tx *sql.Tx, userID int, sportID string, wasWin bool) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var query string
 	if wasWin {
+		// The right-hand sides see the old current_streak
 		query = `
 			UPDATE user_sports SET
 				matches_played = GREATEST(0, matches_played - 1),
 				wins = GREATEST(0, wins - 1),
+				current_streak = CASE WHEN current_streak > 0 THEN current_streak - 1 ELSE current_streak END,
+				longest_win_streak = CASE
+					WHEN current_streak > 0 AND longest_win_streak = current_streak THEN longest_win_streak - 1
+					ELSE longest_win_streak
+				END,
 				updated_at = CURRENT_TIMESTAMP
 			WHERE user_id = $1 AND sport_id = $2
 		`
@@ -153,6 +208,7 @@ func (r *UserSportsRepository) DecrementMatchStats(tx *sql.Tx, userID int, sport
 			UPDATE user_sports SET
 				matches_played = GREATEST(0, matches_played - 1),
 				losses = GREATEST(0, losses - 1),
+				current_streak = CASE WHEN current_streak < 0 THEN current_streak + 1 ELSE current_streak END,
 				updated_at = CURRENT_TIMESTAMP
 			WHERE user_id = $1 AND sport_id = $2
 		`
@@ -160,9 +216,9 @@ func (r *UserSportsRepository) DecrementMatchStats(tx *sql.Tx, userID int, sport
 
 	var err error
 	if tx != nil {
-		_, err = tx.Exec(query, userID, sportID)
+		_, err = tx.ExecContext(ctx, query, userID, sportID)
 	} else {
-		_, err = r.db.Exec(query, userID, sportID)
+		_, err = r.db.ExecContext(ctx, query, userID, sportID)
 	}
 
 	if err != nil {
@@ -173,16 +229,20 @@ func (r *UserSportsRepository) DecrementMatchStats(tx *sql.Tx, userID int, sport
 }
 
 // GetUserSportStats retrieves comprehensive stats for a user in a specific sport
-func (r *UserSportsRepository) GetUserSportStats(userID int, sportID string) (*UserSportData, error) {
+func (r *UserSportsRepository) GetUserSportStats(ctx context.Context, userID int, sportID string) (*UserSportData, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	data := &UserSportData{}
 	query := `
 		SELECT user_id, sport_id, current_elo, highest_elo, matches_played,
-		       wins, losses, created_at, updated_at
+		       wins, losses, current_streak, longest_win_streak, last_match_at,
+		       created_at, updated_at
 		FROM user_sports
 		WHERE user_id = $1 AND sport_id = $2
 	`
 
-	err := r.db.QueryRow(query, userID, sportID).Scan(
+	err := r.db.QueryRowContext(ctx, query, userID, sportID).Scan(
 		&data.UserID,
 		&data.SportID,
 		&data.CurrentELO,
@@ -190,6 +250,9 @@ func (r *UserSportsRepository) GetUserSportStats(userID int, sportID string) (*U
 		&data.MatchesPlayed,
 		&data.Wins,
 		&data.Losses,
+		&data.CurrentStreak,
+		&data.LongestWinStreak,
+		&data.LastMatchAt,
 		&data.CreatedAt,
 		&data.UpdatedAt,
 	)
@@ -215,15 +278,19 @@ func (r *UserSportsRepository) GetUserSportStats(userID int, sportID string) (*U
 }
 
 // GetAllUserSports retrieves all sport data for a user
-func (r *UserSportsRepository) GetAllUserSports(userID int) (map[string]*UserSportData, error) {
+func (r *UserSportsRepository) GetAllUserSports(ctx context.Context, userID int) (map[string]*UserSportData, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		SELECT user_id, sport_id, current_elo, highest_elo, matches_played,
-		       wins, losses, created_at, updated_at
+		       wins, losses, current_streak, longest_win_streak, last_match_at,
+		       created_at, updated_at
 		FROM user_sports
 		WHERE user_id = $1
 	`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query user sports: %w", err)
 	}
@@ -240,6 +307,9 @@ func (r *UserSportsRepository) GetAllUserSports(userID int) (map[string]*UserSpo
 			&data.MatchesPlayed,
 			&data.Wins,
 			&data.Losses,
+			&data.CurrentStreak,
+			&data.LongestWinStreak,
+			&data.LastMatchAt,
 			&data.CreatedAt,
 			&data.UpdatedAt,
 		); err != nil {
@@ -257,7 +327,10 @@ func (r *UserSportsRepository) GetAllUserSports(userID int) (map[string]*UserSpo
 
 // EnsureUserSportExists creates a user_sports record if it doesn't exist
 // This is useful when a new user is created or when initializing stats
-func (r *UserSportsRepository) EnsureUserSportExists(tx *sql.Tx, userID int, sportID string, defaultELO int) error {
+func (r *UserSportsRepository) EnsureUserSportExists(ctx context.Context, tx *sql.Tx, userID int, sportID string, defaultELO int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO user_sports (user_id, sport_id, current_elo, highest_elo)
 		VALUES ($1, $2, $3, $3)
@@ -266,9 +339,9 @@ func (r *UserSportsRepository) EnsureUserSportExists(tx *sql.Tx, userID int, spo
 
 	var err error
 	if tx != nil {
-		_, err = tx.Exec(query, userID, sportID, defaultELO)
+		_, err = tx.ExecContext(ctx, query, userID, sportID, defaultELO)
 	} else {
-		_, err = r.db.Exec(query, userID, sportID, defaultELO)
+		_, err = r.db.ExecContext(ctx, query, userID, sportID, defaultELO)
 	}
 
 	if err != nil {
@@ -277,3 +350,155 @@ func (r *UserSportsRepository) EnsureUserSportExists(tx *sql.Tx, userID int, spo
 
 	return nil
 }
+
+// ReconcileStats recomputes the aggregates in user_sports from confirmed matches
+// (including archived ones) and fixes rows that drifted. userIDs limits the check to
+// those users; nil checks everyone. Returns the number of rows that were corrected.
+func (r *UserSportsRepository) ReconcileStats(ctx context.Context, tx *sql.Tx, userIDs []int) (int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	if tx != nil {
+		return reconcileUserSportStats(ctx, tx, userIDs)
+	}
+	return reconcileUserSportStats(ctx, r.db, userIDs)
+}
+
+// reconcileUserSportStats is shared with AdminRepository.RevertMatch
+func reconcileUserSportStats(ctx context.Context, exec interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}, userIDs []int) (int64, error) {
+	query := `
+		WITH results AS (
+			SELECT player1_id AS user_id, sport, winner_id = player1_id AS won,
+			       COALESCE(confirmed_at, created_at) AS played_at, id
+			FROM matches_all WHERE status = 'confirmed'
+			UNION ALL
+			SELECT player2_id AS user_id, sport, winner_id = player2_id AS won,
+			       COALESCE(confirmed_at, created_at) AS played_at, id
+			FROM matches_all WHERE status = 'confirmed'
+		), scoped AS (
+			SELECT * FROM results WHERE $1::INTEGER[] IS NULL OR user_id = ANY($1)
+		), grouped AS (
+			-- Consecutive results with the same outcome share a group number
+			SELECT *,
+			       ROW_NUMBER() OVER (PARTITION BY user_id, sport ORDER BY played_at, id)
+			     - ROW_NUMBER() OVER (PARTITION BY user_id, sport, won ORDER BY played_at, id) AS grp
+			FROM scoped
+		), runs AS (
+			SELECT user_id, sport, won, COUNT(*) AS len, MAX(played_at) AS last_at, MAX(id) AS last_id
+			FROM grouped
+			GROUP BY user_id, sport, won, grp
+		), agg AS (
+			SELECT user_id, sport,
+			       SUM(len) AS played,
+			       COALESCE(SUM(len) FILTER (WHERE won), 0) AS wins,
+			       COALESCE(SUM(len) FILTER (WHERE NOT won), 0) AS losses,
+			       COALESCE(MAX(len) FILTER (WHERE won), 0) AS longest,
+			       MAX(last_at) AS last_match_at
+			FROM runs
+			GROUP BY user_id, sport
+		), latest AS (
+			SELECT DISTINCT ON (user_id, sport) user_id, sport,
+			       CASE WHEN won THEN len ELSE -len END AS streak
+			FROM runs
+			ORDER BY user_id, sport, last_at DESC, last_id DESC
+		), expected AS (
+			SELECT us.user_id, us.sport_id,
+			       COALESCE(agg.played, 0)::INTEGER AS played,
+			       COALESCE(agg.wins, 0)::INTEGER AS wins,
+			       COALESCE(agg.losses, 0)::INTEGER AS losses,
+			       COALESCE(latest.streak, 0)::INTEGER AS streak,
+			       COALESCE(agg.longest, 0)::INTEGER AS longest,
+			       agg.last_match_at
+			FROM user_sports us
+			LEFT JOIN agg ON agg.user_id = us.user_id AND agg.sport = us.sport_id
+			LEFT JOIN latest ON latest.user_id = us.user_id AND latest.sport = us.sport_id
+			WHERE $1::INTEGER[] IS NULL OR us.user_id = ANY($1)
+		)
+		UPDATE user_sports us SET
+			matches_played = e.played,
+			wins = e.wins,
+			losses = e.losses,
+			current_streak = e.streak,
+			longest_win_streak = e.longest,
+			last_match_at = e.last_match_at,
+			highest_elo = GREATEST(us.highest_elo, us.current_elo),
+			updated_at = CURRENT_TIMESTAMP
+		FROM expected e
+		WHERE us.user_id = e.user_id AND us.sport_id = e.sport_id
+		  AND (
+			(us.matches_played, us.wins, us.losses, us.current_streak, us.longest_win_streak, us.highest_elo)
+				IS DISTINCT FROM (e.played, e.wins, e.losses, e.streak, e.longest, GREATEST(us.highest_elo, us.current_elo))
+			-- last_match_at is stamped by the database on confirmation, confirmed_at by the server
+			OR (us.last_match_at IS NULL) <> (e.last_match_at IS NULL)
+			OR ABS(EXTRACT(EPOCH FROM us.last_match_at - e.last_match_at)) > 60
+		  )
+	`
+
+	var ids interface{}
+	if userIDs != nil {
+		ids = pq.Array(userIDs)
+	}
+
+	result, err := exec.ExecContext(ctx, query, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reconcile user sport stats: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ApplyDecay lowers the ratings of players without a confirmed match in a sport for
+// afterWeeks weeks by points, at most once a week and never below the sport's default
+// rating, and records each change in the ELO history. Inactive sports don't decay.
+// Players are locked in ID order like confirmations do, so the two never deadlock.
+// Returns the recorded history entries.
+func (r *UserSportsRepository) ApplyDecay(ctx context.Context, points, afterWeeks int) ([]models.ELOHistoryEntry, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		WITH due AS (
+			SELECT us.user_id, us.sport_id, us.current_elo AS elo_before,
+			       GREATEST(s.default_elo, us.current_elo - $1) AS elo_after
+			FROM user_sports us
+			JOIN users u ON u.id = us.user_id
+			JOIN sports s ON s.id = us.sport_id
+			WHERE s.is_active
+			  AND us.current_elo > s.default_elo
+			  AND us.last_match_at < CURRENT_TIMESTAMP - make_interval(weeks => $2)
+			  AND NOT EXISTS (
+				SELECT 1 FROM elo_history h
+				WHERE h.user_id = us.user_id AND h.sport_id = us.sport_id
+				  AND h.source = 'decay' AND h.created_at > CURRENT_TIMESTAMP - INTERVAL '1 week'
+			  )
+			ORDER BY us.user_id, us.sport_id
+			FOR UPDATE OF u, us
+		), decayed AS (
+			UPDATE user_sports us
+			SET current_elo = due.elo_after, updated_at = CURRENT_TIMESTAMP
+			FROM due
+			WHERE us.user_id = due.user_id AND us.sport_id = due.sport_id
+		)
+		INSERT INTO elo_history (user_id, sport_id, elo_before, elo_after, delta, source)
+		SELECT user_id, sport_id, elo_before, elo_after, elo_after - elo_before, 'decay'
+		FROM due
+		RETURNING id, user_id, sport_id, elo_before, elo_after, delta, source, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, points, afterWeeks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply rating decay: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.ELOHistoryEntry
+	for rows.Next() {
+		var e models.ELOHistoryEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Sport, &e.ELOBefore, &e.ELOAfter, &e.Delta, &e.Source, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan decayed rating: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/lib/pq"
+)
+
+// WebhookRepository handles admin-registered outbound webhooks
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository instance
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookColumns = `id, url, secret, events, description, active, created_by, created_at, updated_at`
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*models.Webhook, error) {
+	hook := &models.Webhook{}
+	err := row.Scan(
+		&hook.ID,
+		&hook.URL,
+		&hook.Secret,
+		pq.Array(&hook.Events),
+		&hook.Description,
+		&hook.Active,
+		&hook.CreatedBy,
+		&hook.CreatedAt,
+		&hook.UpdatedAt,
+	)
+	return hook, err
+}
+
+// Create registers a new webhook
+func (r *WebhookRepository) Create(ctx context.Context, hook *models.Webhook) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhooks (url, secret, events, description, active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, hook.URL, hook.Secret, pq.Array(hook.Events), hook.Description, hook.Active, hook.CreatedBy).
+		Scan(&hook.ID, &hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a webhook by ID
+func (r *WebhookRepository) GetByID(ctx context.Context, id int) (*models.Webhook, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+
+	hook, err := scanWebhook(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	return hook, err
+}
+
+// List returns all webhooks, oldest first
+func (r *WebhookRepository) List(ctx context.Context) ([]models.Webhook, error) {
+	return r.query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+}
+
+// ListActiveForEvent returns the active webhooks subscribed to an event
+func (r *WebhookRepository) ListActiveForEvent(ctx context.Context, event string) ([]models.Webhook, error) {
+	return r.query(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE active AND $1 = ANY(events) ORDER BY id`, event)
+}
+
+func (r *WebhookRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.Webhook, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []models.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		hooks = append(hooks, *hook)
+	}
+	return hooks, rows.Err()
+}
+
+// Update saves the URL, events, description and active flag of a webhook
+func (r *WebhookRepository) Update(ctx context.Context, hook *models.Webhook) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE webhooks SET url = $1, events = $2, description = $3, active = $4
+		WHERE id = $5
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, hook.URL, pq.Array(hook.Events), hook.Description, hook.Active, hook.ID).Scan(&hook.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("webhook not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a webhook; its delivery log entries are kept until retention purges them
+func (r *WebhookRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/cache"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+)
+
+// Lookup timeouts and local cache lifetimes
+const (
+	lookupTimeout = 200 * time.Millisecond
+	// negativeTTL bounds how long another instance's revocation can go unnoticed
+	negativeTTL   = 15 * time.Second
+	localMaxItems = 50000
+)
+
+// DenyList checks tokens against a revocation Store with a local cache in front,
+// so most authenticated requests don't need a round trip to the store
+type DenyList struct {
+	store Store
+	local *cache.MemoryCache
+}
+
+// NewDenyList creates a new DenyList backed by the given store
+func NewDenyList(store Store) *DenyList {
+	return &DenyList{
+		store: store,
+		local: cache.NewMemoryCacheWithConfig(cache.CacheConfig{
+			TTL:             negativeTTL,
+			CleanupInterval: time.Minute,
+			MaxItems:        localMaxItems,
+		}),
+	}
+}
+
+// RevokeToken revokes a single token (e.g. on logout)
+func (d *DenyList) RevokeToken(ctx context.Context, claims *utils.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return fmt.Errorf("token cannot be revoked individually")
+	}
+
+	if err := d.store.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	d.local.SetWithTTL("jti:"+claims.ID, true, time.Until(claims.ExpiresAt.Time))
+	return nil
+}
+
+// RevokeUser revokes all tokens issued to a user up to now (e.g. on ban)
+func (d *DenyList) RevokeUser(ctx context.Context, userID int) error {
+	now := time.Now()
+	if err := d.store.RevokeUser(ctx, userID, now, utils.JWTLifetime); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	d.local.SetWithTTL(fmt.Sprintf("user:%d", userID), now, utils.JWTLifetime)
+	return nil
+}
+
+// IsRevoked reports whether a validated token has been revoked
+// Store errors fail open (logged) so a Redis outage doesn't log everyone out
+func (d *DenyList) IsRevoked(ctx context.Context, claims *utils.Claims) bool {
+	if claims.ID != "" && d.isTokenRevoked(ctx, claims) {
+		return true
+	}
+
+	revokedAt, ok := d.userRevokedAt(ctx, claims.UserID)
+	if !ok {
+		return false
+	}
+	if claims.IssuedAt == nil {
+		return true
+	}
+
+	// iat has second precision; anything issued in or before the revocation second is denied
+	return !claims.IssuedAt.Time.After(revokedAt.Truncate(time.Second))
+}
+
+func (d *DenyList) isTokenRevoked(ctx context.Context, claims *utils.Claims) bool {
+	key := "jti:" + claims.ID
+	if value, ok := d.local.Get(key); ok {
+		return value.(bool)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	revoked, err := d.store.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		slog.Warn("Token deny-list lookup failed", "error", err)
+		return false
+	}
+
+	if revoked && claims.ExpiresAt != nil {
+		d.local.SetWithTTL(key, true, time.Until(claims.ExpiresAt.Time))
+	} else {
+		d.local.Set(key, false)
+	}
+	return revoked
+}
+
+func (d *DenyList) userRevokedAt(ctx context.Context, userID int) (time.Time, bool) {
+	key := fmt.Sprintf("user:%d", userID)
+	if value, ok := d.local.Get(key); ok {
+		revokedAt, revoked := value.(time.Time)
+		return revokedAt, revoked
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	revokedAt, revoked, err := d.store.UserRevokedAt(ctx, userID)
+	if err != nil {
+		slog.Warn("User revocation lookup failed", "error", err, "user_id", userID)
+		return time.Time{}, false
+	}
+
+	if revoked {
+		d.local.Set(key, revokedAt)
+	} else {
+		d.local.Set(key, false)
+	}
+	return revokedAt, revoked
+}
+
+// Close stops the local cache
+func (d *DenyList) Close() {
+	d.local.Stop()
+}
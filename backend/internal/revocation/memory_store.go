@@ -0,0 +1,59 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/cache"
+)
+
+// MemoryStore keeps the deny-list in process memory
+// Only suitable for single-instance deployments; revocations are lost on restart
+type MemoryStore struct {
+	cache *cache.MemoryCache
+}
+
+// NewMemoryStore creates a new in-memory revocation store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		cache: cache.NewMemoryCacheWithConfig(cache.CacheConfig{
+			TTL:             24 * time.Hour,
+			CleanupInterval: 5 * time.Minute,
+			MaxItems:        0, // Revocations must never be evicted early
+		}),
+	}
+}
+
+// Revoke marks a single token as revoked until it expires
+func (s *MemoryStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.cache.SetWithTTL("jti:"+jti, true, time.Until(expiresAt))
+	return nil
+}
+
+// IsRevoked reports whether a token ID has been revoked
+func (s *MemoryStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	_, revoked := s.cache.Get("jti:" + jti)
+	return revoked, nil
+}
+
+// RevokeUser revokes every token of a user issued at or before the given time
+func (s *MemoryStore) RevokeUser(ctx context.Context, userID int, at time.Time, ttl time.Duration) error {
+	s.cache.SetWithTTL(fmt.Sprintf("user:%d", userID), at, ttl)
+	return nil
+}
+
+// UserRevokedAt returns the time before which all of a user's tokens are revoked
+func (s *MemoryStore) UserRevokedAt(ctx context.Context, userID int) (time.Time, bool, error) {
+	value, ok := s.cache.Get(fmt.Sprintf("user:%d", userID))
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	return value.(time.Time), true, nil
+}
+
+// Close stops the background cleanup
+func (s *MemoryStore) Close() error {
+	s.cache.Stop()
+	return nil
+}
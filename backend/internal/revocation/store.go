@@ -0,0 +1,21 @@
+package revocation
+
+import (
+	"context"
+	"time"
+)
+
+// Store persists revoked token IDs (jti) and per-user revocation timestamps
+// Implementations must expire entries on their own once the tokens they cover have expired
+type Store interface {
+	// Revoke marks a single token as revoked until it expires
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	// IsRevoked reports whether a token ID has been revoked
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	// RevokeUser revokes every token of a user issued at or before the given time
+	RevokeUser(ctx context.Context, userID int, at time.Time, ttl time.Duration) error
+	// UserRevokedAt returns the time before which all of a user's tokens are revoked
+	UserRevokedAt(ctx context.Context, userID int) (time.Time, bool, error)
+	// Close releases the store's resources
+	Close() error
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -135,6 +136,8 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration
 	IdleTimeout     time.Duration
 	ShutdownTimeout time.Duration
+	// ShutdownManager lets callers register cleanups before the server exists (optional)
+	ShutdownManager *ShutdownManager
 }
 
 // DefaultServerConfig returns sensible defaults
@@ -151,6 +154,11 @@ func DefaultServerConfig(addr string, handler http.Handler) ServerConfig {
 
 // NewServer creates a new server with graceful shutdown
 func NewServer(cfg ServerConfig) *Server {
+	sm := cfg.ShutdownManager
+	if sm == nil {
+		sm = NewShutdownManager(cfg.ShutdownTimeout)
+	}
+
 	return &Server{
 		httpServer: &http.Server{
 			Addr:         cfg.Addr,
@@ -159,7 +167,7 @@ func NewServer(cfg ServerConfig) *Server {
 			WriteTimeout: cfg.WriteTimeout,
 			IdleTimeout:  cfg.IdleTimeout,
 		},
-		shutdownManager: NewShutdownManager(cfg.ShutdownTimeout),
+		shutdownManager: sm,
 	}
 }
 
@@ -174,20 +182,32 @@ func (s *Server) RegisterSimple(name string, cleanup func()) {
 }
 
 // Start starts the server and blocks until shutdown
+// A shutdown signal drains in-flight requests before the cleanups run; if the server
+// fails instead, e.g. because the port is taken, the cleanups run and the error is returned
 func (s *Server) Start() error {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(quit)
+
 	// Start server in goroutine
+	serveErr := make(chan error, 1)
 	go func() {
 		slog.Info("Server starting", "addr", s.httpServer.Addr)
 		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			slog.Error("Server error", "error", err)
-			os.Exit(1)
+			serveErr <- err
 		}
 	}()
 
 	// Wait for shutdown signal
-	s.shutdownManager.WaitForShutdown(s.httpServer)
-
-	return nil
+	select {
+	case sig := <-quit:
+		slog.Info("Shutdown signal received", "signal", sig.String())
+		s.shutdownManager.Shutdown(s.httpServer)
+		return nil
+	case err := <-serveErr:
+		s.shutdownManager.Shutdown(nil)
+		return fmt.Errorf("server failed: %w", err)
+	}
 }
 
 // StartWithContext starts the server with a context for cancellation
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+const (
+	// abuseScanLookback is how far back each scan re-checks confirmed matches
+	// Scans run far more often, so a missed run or a late confirmation is still covered
+	abuseScanLookback = 24 * time.Hour
+
+	// pairFrequencyWindow and pairFrequencyMatches: the same pair playing this often is suspicious
+	pairFrequencyWindow  = 24 * time.Hour
+	pairFrequencyMatches = 8
+
+	// alternatingWinsStreak is how many results in a row must alternate between the pair
+	alternatingWinsStreak = 6
+
+	// eloFarmingGap, eloFarmingWindow and eloFarmingGain: winning this much ELO from an
+	// opponent rated this much lower within the window is suspicious
+	eloFarmingGap    = 300
+	eloFarmingWindow = 7 * 24 * time.Hour
+	eloFarmingGain   = 40
+)
+
+// AbuseDetectionService flags confirmed matches that look like ELO farming or arranged results
+// Flags only queue matches for staff review; ratings are never changed automatically
+type AbuseDetectionService struct {
+	flagRepo repositories.MatchFlagStore
+}
+
+// NewAbuseDetectionService creates an abuse detection service
+func NewAbuseDetectionService(flagRepo repositories.MatchFlagStore) *AbuseDetectionService {
+	return &AbuseDetectionService{flagRepo: flagRepo}
+}
+
+// Scan checks the recently confirmed matches against every rule
+// Returns how many matches were newly flagged; a failing rule does not stop the others
+func (s *AbuseDetectionService) Scan(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "AbuseDetectionService.Scan")
+	defer span.End()
+
+	since := time.Now().Add(-abuseScanLookback)
+
+	rules := []struct {
+		name string
+		run  func() (int64, error)
+	}{
+		{models.FlagPairFrequency, func() (int64, error) {
+			return s.flagRepo.FlagPairFrequency(ctx, since, pairFrequencyWindow, pairFrequencyMatches)
+		}},
+		{models.FlagAlternatingWins, func() (int64, error) {
+			return s.flagRepo.FlagAlternatingWins(ctx, since, alternatingWinsStreak)
+		}},
+		{models.FlagELOFarming, func() (int64, error) {
+			return s.flagRepo.FlagELOFarming(ctx, since, eloFarmingGap, eloFarmingWindow, eloFarmingGain)
+		}},
+	}
+
+	var flagged int64
+	var firstErr error
+	for _, rule := range rules {
+		if ctx.Err() != nil {
+			return flagged, ctx.Err()
+		}
+		n, err := rule.run()
+		if err != nil {
+			slog.Error("Abuse scan rule failed", "rule", rule.name, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if n > 0 {
+			slog.Info("Flagged suspicious matches", "rule", rule.name, "count", n)
+		}
+		flagged += n
+	}
+	return flagged, firstErr
+}
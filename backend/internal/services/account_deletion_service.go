@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AccountDeletionGracePeriod is how long a user can cancel a requested account deletion
+const AccountDeletionGracePeriod = 14 * 24 * time.Hour
+
+// AccountDeletionService erases accounts on request (Art. 17 GDPR)
+// A request only schedules the erasure; the account deletion job erases the
+// account once the grace period has passed, unless the user cancelled meanwhile
+type AccountDeletionService struct {
+	db           *sql.DB
+	userRepo     repositories.UserStore
+	deletionRepo repositories.DeletionRequestStore
+	matchService *MatchService
+}
+
+// NewAccountDeletionService creates an account deletion service
+func NewAccountDeletionService(
+	db *sql.DB,
+	userRepo repositories.UserStore,
+	deletionRepo repositories.DeletionRequestStore,
+	matchService *MatchService,
+) *AccountDeletionService {
+	return &AccountDeletionService{
+		db:           db,
+		userRepo:     userRepo,
+		deletionRepo: deletionRepo,
+		matchService: matchService,
+	}
+}
+
+// Request schedules the erasure of an account after the grace period
+// A pending request is returned unchanged; the bool reports whether it was created
+func (s *AccountDeletionService) Request(ctx context.Context, userID int) (*models.DeletionRequest, bool, error) {
+	ctx, span := tracer.Start(ctx, "AccountDeletionService.Request", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, false, err
+	}
+	return s.deletionRepo.Create(ctx, userID, time.Now().Add(AccountDeletionGracePeriod))
+}
+
+// Get returns the pending deletion of an account, or nil if none is scheduled
+func (s *AccountDeletionService) Get(ctx context.Context, userID int) (*models.DeletionRequest, error) {
+	ctx, span := tracer.Start(ctx, "AccountDeletionService.Get", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	return s.deletionRepo.GetForUser(ctx, userID)
+}
+
+// Cancel keeps the account; fails with "no account deletion scheduled" if there is nothing to cancel
+func (s *AccountDeletionService) Cancel(ctx context.Context, userID int) error {
+	ctx, span := tracer.Start(ctx, "AccountDeletionService.Cancel", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	cancelled, err := s.deletionRepo.Delete(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return fmt.Errorf("no account deletion scheduled")
+	}
+	return nil
+}
+
+// ProcessDue erases every account whose grace period has ended
+// Returns the number of erased accounts; one failure does not stop the others
+func (s *AccountDeletionService) ProcessDue(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "AccountDeletionService.ProcessDue")
+	defer span.End()
+
+	now := time.Now()
+	userIDs, err := s.deletionRepo.ListDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	erased := 0
+	var firstErr error
+	for _, userID := range userIDs {
+		ok, err := s.erase(ctx, userID, now)
+		if err != nil {
+			slog.Error("Failed to erase account", "user_id", userID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if ok {
+			erased++
+			slog.Info("Account deleted after grace period", "user_id", userID)
+		}
+	}
+
+	if erased > 0 {
+		s.matchService.LeaderboardChanged()
+	}
+	return erased, firstErr
+}
+
+// erase deletes an account in one transaction: comments and reactions are deleted,
+// matches are kept but moved to the anonymized user (id = -1)
+// Returns false if the deletion was cancelled before the transaction claimed it
+func (s *AccountDeletionService) erase(ctx context.Context, userID int, now time.Time) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	claimed, err := s.deletionRepo.Claim(ctx, tx, userID, now)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	anonymizedID := -1
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO users (id, login, display_name, avatar_url, campus, is_banned, ban_reason)
+		VALUES ($1, 'deleted_user', 'Deleted User', '', '42heilbronn', true, 'System account for anonymized data')
+		ON CONFLICT (id) DO NOTHING
+	`, anonymizedID)
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare anonymized user: %w", err)
+	}
+
+	// 1. Delete all comments by this user
+	if _, err := tx.ExecContext(ctx, "DELETE FROM comments WHERE user_id = $1", userID); err != nil {
+		return false, fmt.Errorf("failed to delete comments: %w", err)
+	}
+
+	// 2. Delete all reactions by this user
+	if _, err := tx.ExecContext(ctx, "DELETE FROM reactions WHERE user_id = $1", userID); err != nil {
+		return false, fmt.Errorf("failed to delete reactions: %w", err)
+	}
+
+	// 3. Anonymize matches where user is player1, player2, winner, or submitter
+	// We keep match history but remove personal data linkage
+	// Note: Must update player IDs and winner_id together to satisfy the
+	// valid_winner CHECK constraint (winner_id = player1_id OR winner_id = player2_id)
+	// Matches archived from past seasons are anonymized the same way
+	for _, table := range []string{"matches", "matches_archive"} {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE `+table+` SET
+				player1_id = CASE WHEN player1_id = $2 THEN $1 ELSE player1_id END,
+				player2_id = CASE WHEN player2_id = $2 THEN $1 ELSE player2_id END,
+				winner_id = CASE WHEN winner_id = $2 THEN $1 ELSE winner_id END,
+				submitted_by = CASE WHEN submitted_by = $2 THEN $1 ELSE submitted_by END,
+				submit_fingerprint = NULL,
+				confirm_fingerprint = NULL
+			WHERE player1_id = $2 OR player2_id = $2 OR winner_id = $2 OR submitted_by = $2
+		`, anonymizedID, userID)
+		if err != nil {
+			return false, fmt.Errorf("failed to anonymize %s: %w", table, err)
+		}
+	}
+
+	// 4. Anonymize ELO adjustments made by this user (adjusted_by foreign key)
+	if _, err := tx.ExecContext(ctx, "UPDATE elo_adjustments SET adjusted_by = $1 WHERE adjusted_by = $2", anonymizedID, userID); err != nil {
+		return false, fmt.Errorf("failed to anonymize elo adjustments: %w", err)
+	}
+
+	// 5. Clear banned_by references (users banned by this user)
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET banned_by = NULL WHERE banned_by = $1", userID); err != nil {
+		return false, fmt.Errorf("failed to clear ban references: %w", err)
+	}
+
+	// 6. Delete audit log entries where this user was the admin (admin_id foreign key)
+	if _, err := tx.ExecContext(ctx, "DELETE FROM admin_audit_log WHERE admin_id = $1", userID); err != nil {
+		return false, fmt.Errorf("failed to delete audit entries: %w", err)
+	}
+
+	// 7. Delete audit log entries related to this user (admin actions on this user)
+	if _, err := tx.ExecContext(ctx, "DELETE FROM admin_audit_log WHERE target_type = 'user' AND target_id = $1", userID); err != nil {
+		return false, fmt.Errorf("failed to delete audit entries targeting user: %w", err)
+	}
+
+	// 8. Delete reports filed against this user; their own reports cascade
+	if _, err := tx.ExecContext(ctx, "DELETE FROM reports WHERE target_type = 'user' AND target_id = $1", userID); err != nil {
+		return false, fmt.Errorf("failed to delete reports targeting user: %w", err)
+	}
+
+	// 9. Delete the user account
+	if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userID); err != nil {
+		return false, fmt.Errorf("failed to delete user account: %w", err)
+	}
+	if err := s.matchService.RefreshRankings(ctx, tx); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to complete deletion: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// activityBurstWindow is how long after a confirmation caches stay very short
+	activityBurstWindow = 2 * time.Minute
+	// activityBusyWindow is how long after any match event the app counts as busy
+	activityBusyWindow = 15 * time.Minute
+)
+
+// Activity levels, from most to least active
+const (
+	ActivityBurst = "burst" // a match was just confirmed
+	ActivityBusy  = "busy"  // matches are being submitted or answered
+	ActivityDay   = "day"   // usual playing hours without recent matches
+	ActivityNight = "night" // outside the usual playing hours
+)
+
+// AdaptiveTTL is a cache TTL for each activity level
+type AdaptiveTTL struct {
+	Burst time.Duration
+	Busy  time.Duration
+	Day   time.Duration
+	Night time.Duration
+}
+
+// Cache TTLs of data that changes with matches
+var (
+	// LeaderboardTTL applies to ranked leaderboard pages
+	LeaderboardTTL = AdaptiveTTL{Burst: 15 * time.Second, Busy: time.Minute, Day: 5 * time.Minute, Night: 30 * time.Minute}
+	// PodiumTTL applies to the serialized top-N responses polled by widgets
+	PodiumTTL = AdaptiveTTL{Burst: 2 * time.Second, Busy: 5 * time.Second, Day: 30 * time.Second, Night: 2 * time.Minute}
+)
+
+// ActivityMonitor tracks match activity from the event bus to pick cache TTLs
+// Fresh data matters while people play; overnight the database is spared
+type ActivityMonitor struct {
+	mu            sync.RWMutex
+	lastEvent     time.Time
+	lastConfirmed time.Time
+	activeFrom    int // UTC hour
+	activeUntil   int // UTC hour, exclusive; may be smaller than activeFrom
+}
+
+// NewActivityMonitor creates a monitor with the usual playing hours in UTC
+func NewActivityMonitor(activeFrom, activeUntil int) *ActivityMonitor {
+	return &ActivityMonitor{
+		activeFrom:  activeFrom,
+		activeUntil: activeUntil,
+	}
+}
+
+// Publish records match events; it is registered alongside the other event publishers
+func (m *ActivityMonitor) Publish(channel, eventType string, data interface{}) {
+	switch eventType {
+	case EventMatchPending, EventMatchDenied, EventMatchCancelled:
+		m.mu.Lock()
+		m.lastEvent = time.Now()
+		m.mu.Unlock()
+	case EventMatchConfirmed, EventMatchCorrected:
+		m.mu.Lock()
+		m.lastEvent = time.Now()
+		m.lastConfirmed = m.lastEvent
+		m.mu.Unlock()
+	}
+}
+
+// Level returns the current activity level
+func (m *ActivityMonitor) Level() string {
+	m.mu.RLock()
+	lastEvent, lastConfirmed := m.lastEvent, m.lastConfirmed
+	m.mu.RUnlock()
+
+	now := time.Now()
+	switch {
+	case now.Sub(lastConfirmed) < activityBurstWindow:
+		return ActivityBurst
+	case now.Sub(lastEvent) < activityBusyWindow:
+		return ActivityBusy
+	case m.inActiveHours(now.UTC().Hour()):
+		return ActivityDay
+	default:
+		return ActivityNight
+	}
+}
+
+// TTL returns the TTL for the current activity level
+func (m *ActivityMonitor) TTL(ttl AdaptiveTTL) time.Duration {
+	switch m.Level() {
+	case ActivityBurst:
+		return ttl.Burst
+	case ActivityBusy:
+		return ttl.Busy
+	case ActivityDay:
+		return ttl.Day
+	default:
+		return ttl.Night
+	}
+}
+
+func (m *ActivityMonitor) inActiveHours(hour int) bool {
+	if m.activeFrom < m.activeUntil {
+		return hour >= m.activeFrom && hour < m.activeUntil
+	}
+	return hour >= m.activeFrom || hour < m.activeUntil
+}
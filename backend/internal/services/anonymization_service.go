@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxNameAttempts bounds the search for a free anonymous name per user
+const maxNameAttempts = 200
+
+// nameBackfillBatch is the number of unnamed users loaded at a time by AssignMissingNames
+const nameBackfillBatch = 500
+
+// vocabulary is the adjective/animal word list for one campus
+type vocabulary struct {
+	adjectives []string
+	animals    []string
+}
+
+// AnonymizationService assigns unique, persisted anonymous names to users
+// Vocabularies are loaded from the database per campus and cached in memory
+type AnonymizationService struct {
+	repo        repositories.AnonymizationStore
+	fallback    vocabulary
+	vocab       map[string]vocabulary
+	vocabMutex  sync.RWMutex
+	vocabExpiry time.Time
+	vocabTTL    time.Duration
+}
+
+// NewAnonymizationService creates a new AnonymizationService instance
+// adjectives and animals are used when the database holds no vocabulary; empty means built-in defaults
+func NewAnonymizationService(repo repositories.AnonymizationStore, adjectives, animals []string) *AnonymizationService {
+	if len(adjectives) == 0 {
+		adjectives = utils.DefaultAnonymousAdjectives
+	}
+	if len(animals) == 0 {
+		animals = utils.DefaultAnonymousAnimals
+	}
+
+	return &AnonymizationService{
+		repo:     repo,
+		fallback: vocabulary{adjectives: adjectives, animals: animals},
+		vocabTTL: 5 * time.Minute,
+	}
+}
+
+// AnonymousNames returns the anonymous name of each user
+// Names are assigned on login and by the backfill job, so this only reads them; a user
+// without a name is an error, since any stand-in could be another player's name
+func (s *AnonymizationService) AnonymousNames(ctx context.Context, users []models.User) (map[int]string, error) {
+	ctx, span := tracer.Start(ctx, "AnonymizationService.AnonymousNames")
+	defer span.End()
+
+	ids := make([]int, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+
+	names, err := s.repo.GetNames(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if _, ok := names[u.ID]; !ok {
+			return nil, fmt.Errorf("user %d has no anonymous name yet", u.ID)
+		}
+	}
+
+	return names, nil
+}
+
+// AssignName gives the user a unique anonymous name unless they already have one
+func (s *AnonymizationService) AssignName(ctx context.Context, user models.User) (string, error) {
+	ctx, span := tracer.Start(ctx, "AnonymizationService.AssignName", trace.WithAttributes(attribute.Int("user.id", user.ID)))
+	defer span.End()
+
+	names, err := s.repo.GetNames(ctx, []int{user.ID})
+	if err != nil {
+		return "", err
+	}
+	if name, ok := names[user.ID]; ok {
+		return name, nil
+	}
+	return s.assignName(ctx, user)
+}
+
+// AssignMissingNames names every user who has none yet, e.g. accounts created before names
+// were persisted, by the seeder or for erased players; returns the number of users named
+func (s *AnonymizationService) AssignMissingNames(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "AnonymizationService.AssignMissingNames")
+	defer span.End()
+
+	assigned := 0
+	for ctx.Err() == nil {
+		users, err := s.repo.ListUnnamedUsers(ctx, nameBackfillBatch)
+		if err != nil {
+			return assigned, err
+		}
+		if len(users) == 0 {
+			return assigned, nil
+		}
+
+		for _, u := range users {
+			if _, err := s.assignName(ctx, u); err != nil {
+				return assigned, fmt.Errorf("failed to assign anonymous name to user %d: %w", u.ID, err)
+			}
+			assigned++
+		}
+	}
+	return assigned, ctx.Err()
+}
+
+// assignName walks the user's candidate names until one is free and persists it
+func (s *AnonymizationService) assignName(ctx context.Context, user models.User) (string, error) {
+	vocab := s.vocabularyFor(ctx, user.Campus)
+
+	// Concurrent assignments are settled by the unique constraint: a taken candidate moves on
+	for attempt := 0; attempt < maxNameAttempts; attempt++ {
+		candidate := utils.AnonymousNameCandidate(user.ID, attempt, vocab.adjectives, vocab.animals)
+		name, taken, err := s.repo.ClaimName(ctx, user.ID, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return name, nil
+		}
+	}
+
+	// Vocabulary is nearly exhausted - the user ID keeps the name unique
+	name, taken, err := s.repo.ClaimName(ctx, user.ID, fmt.Sprintf("Player %d", user.ID))
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", fmt.Errorf("no free anonymous name for user %d", user.ID)
+	}
+	return name, nil
+}
+
+// vocabularyFor returns the campus vocabulary, falling back to the global one
+func (s *AnonymizationService) vocabularyFor(ctx context.Context, campus string) vocabulary {
+	if err := s.ensureVocabFresh(ctx); err != nil {
+		slog.Warn("Failed to load anonymization vocabulary", "error", err)
+		return s.fallback
+	}
+
+	s.vocabMutex.RLock()
+	defer s.vocabMutex.RUnlock()
+
+	if v, ok := s.vocab[campus]; ok && len(v.adjectives) > 0 && len(v.animals) > 0 {
+		return v
+	}
+	if v, ok := s.vocab[""]; ok && len(v.adjectives) > 0 && len(v.animals) > 0 {
+		return v
+	}
+	return s.fallback
+}
+
+// ensureVocabFresh reloads the vocabulary if the cache has expired
+func (s *AnonymizationService) ensureVocabFresh(ctx context.Context) error {
+	s.vocabMutex.RLock()
+	if time.Now().Before(s.vocabExpiry) {
+		s.vocabMutex.RUnlock()
+		return nil
+	}
+	s.vocabMutex.RUnlock()
+
+	s.vocabMutex.Lock()
+	defer s.vocabMutex.Unlock()
+
+	// Double-check after acquiring write lock
+	if time.Now().Before(s.vocabExpiry) {
+		return nil
+	}
+
+	words, err := s.repo.ListWords(ctx)
+	if err != nil {
+		return err
+	}
+
+	vocab := make(map[string]vocabulary)
+	for _, w := range words {
+		v := vocab[w.Campus]
+		if w.Kind == "adjective" {
+			v.adjectives = append(v.adjectives, w.Word)
+		} else {
+			v.animals = append(v.animals, w.Word)
+		}
+		vocab[w.Campus] = v
+	}
+
+	s.vocab = vocab
+	s.vocabExpiry = time.Now().Add(s.vocabTTL)
+	return nil
+}
+
+// InvalidateCache forces a vocabulary reload on the next assignment
+func (s *AnonymizationService) InvalidateCache() {
+	s.vocabMutex.Lock()
+	defer s.vocabMutex.Unlock()
+	s.vocabExpiry = time.Time{}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Calendar feed contents
+const (
+	calendarMatchWindow       = 365 * 24 * time.Hour // older matches are left out of the feed
+	calendarMatchDuration     = 15 * time.Minute
+	calendarChallengeDuration = 30 * time.Minute
+	calendarUIDDomain         = "elo-leaderboard.42heilbronn"
+)
+
+// CalendarService builds the iCalendar feed of a player's matches and challenges
+// Calendar apps subscribe without a JWT, so feeds are authorized by a signed token in the
+// URL that never expires; changing JWT_SECRET invalidates every subscription
+type CalendarService struct {
+	matchRepo     repositories.MatchStore
+	challengeRepo repositories.ChallengeStore
+	userRepo      repositories.UserStore
+	sportService  *SportService
+	signingKey    []byte
+	baseURL       string
+}
+
+// NewCalendarService creates a calendar service
+// Tokens are signed with a key derived from secret and linked at publicAPIURL
+func NewCalendarService(
+	matchRepo repositories.MatchStore,
+	challengeRepo repositories.ChallengeStore,
+	userRepo repositories.UserStore,
+	sportService *SportService,
+	secret string,
+	publicAPIURL string,
+) *CalendarService {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("calendar-feed-tokens"))
+
+	return &CalendarService{
+		matchRepo:     matchRepo,
+		challengeRepo: challengeRepo,
+		userRepo:      userRepo,
+		sportService:  sportService,
+		signingKey:    mac.Sum(nil),
+		baseURL:       publicAPIURL,
+	}
+}
+
+// FeedLink returns the subscription link of a player's calendar feed
+func (s *CalendarService) FeedLink(userID int) *models.CalendarFeedLink {
+	payload := strconv.Itoa(userID)
+	token := payload + "." + s.sign(payload)
+
+	return &models.CalendarFeedLink{
+		Token: token,
+		URL:   s.baseURL + "/api/users/me/matches.ics?token=" + url.QueryEscape(token),
+	}
+}
+
+// Validate checks a feed token and returns the player it belongs to
+func (s *CalendarService) Validate(token string) (int, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return 0, fmt.Errorf("invalid calendar token")
+	}
+	userID, err := strconv.Atoi(payload)
+	if err != nil {
+		return 0, fmt.Errorf("invalid calendar token")
+	}
+	return userID, nil
+}
+
+func (s *CalendarService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Feed renders the confirmed matches of the last year and the pending and accepted
+// challenges of a player as an iCalendar document
+// Fails with "user not found" if the account no longer exists
+func (s *CalendarService) Feed(ctx context.Context, userID int) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "CalendarService.Feed", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	matches, err := s.matchRepo.GetUserMatches(ctx, userID, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	challenges, err := s.challengeRepo.ListForUser(ctx, userID, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	cutoff := now.Add(-calendarMatchWindow)
+
+	// Opponent names for the event titles
+	ids := []int{userID}
+	for _, m := range matches {
+		ids = append(ids, m.Player1ID, m.Player2ID)
+	}
+	for _, ch := range challenges {
+		ids = append(ids, ch.ChallengerID, ch.OpponentID)
+	}
+	users, err := s.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(users))
+	for _, u := range users {
+		names[u.ID] = u.Login
+	}
+	if _, ok := names[userID]; !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	cal := &icalWriter{}
+	cal.line("BEGIN:VCALENDAR")
+	cal.line("VERSION:2.0")
+	cal.line("PRODID:-//42 Heilbronn//ELO Leaderboard//EN")
+	cal.line("CALSCALE:GREGORIAN")
+	cal.line("METHOD:PUBLISH")
+	cal.text("X-WR-CALNAME", "ELO Leaderboard: "+names[userID])
+
+	for _, m := range matches {
+		if m.CreatedAt.Before(cutoff) {
+			continue
+		}
+		opponent, ownScore, opponentScore, delta := m.Player2ID, m.Player1Score, m.Player2Score, m.Player1ELODelta
+		if m.Player2ID == userID {
+			opponent, ownScore, opponentScore, delta = m.Player1ID, m.Player2Score, m.Player1Score, m.Player2ELODelta
+		}
+
+		outcome := "Lost"
+		if m.WinnerID == userID {
+			outcome = "Won"
+		}
+		description := fmt.Sprintf("%s %d:%d", outcome, ownScore, opponentScore)
+		if delta != nil {
+			description += fmt.Sprintf(", ELO %+d", *delta)
+		}
+
+		cal.line("BEGIN:VEVENT")
+		cal.line(fmt.Sprintf("UID:match-%d@%s", m.ID, calendarUIDDomain))
+		cal.line("DTSTAMP:" + icalTime(now))
+		cal.line("DTSTART:" + icalTime(m.CreatedAt))
+		cal.line("DTEND:" + icalTime(m.CreatedAt.Add(calendarMatchDuration)))
+		cal.text("SUMMARY", fmt.Sprintf("%s vs %s", s.sportName(m.Sport), names[opponent]))
+		cal.text("DESCRIPTION", description)
+		cal.line("STATUS:CONFIRMED")
+		cal.line("END:VEVENT")
+	}
+
+	for _, ch := range challenges {
+		status := "CONFIRMED"
+		switch ch.Status {
+		case models.ChallengeAccepted:
+		case models.ChallengePending:
+			status = "TENTATIVE"
+		default:
+			continue
+		}
+		opponent := ch.OpponentID
+		if opponent == userID {
+			opponent = ch.ChallengerID
+		}
+
+		cal.line("BEGIN:VEVENT")
+		cal.line(fmt.Sprintf("UID:challenge-%d@%s", ch.ID, calendarUIDDomain))
+		cal.line("DTSTAMP:" + icalTime(now))
+		cal.line("DTSTART:" + icalTime(ch.ScheduledAt))
+		cal.line("DTEND:" + icalTime(ch.ScheduledAt.Add(calendarChallengeDuration)))
+		cal.text("SUMMARY", fmt.Sprintf("%s challenge vs %s", s.sportName(ch.Sport), names[opponent]))
+		if ch.Message != nil {
+			cal.text("DESCRIPTION", *ch.Message)
+		}
+		cal.line("STATUS:" + status)
+		cal.line("END:VEVENT")
+	}
+
+	cal.line("END:VCALENDAR")
+	return cal.buf.Bytes(), nil
+}
+
+// sportName returns the display name of a sport, or its ID if it is unknown
+func (s *CalendarService) sportName(sportID string) string {
+	if sport, err := s.sportService.GetSport(sportID); err == nil {
+		return sport.DisplayName
+	}
+	return sportID
+}
+
+// icalTime formats a time as an iCalendar UTC date-time
+func icalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icalWriter writes content lines folded at 75 octets and ended by CRLF (RFC 5545 3.1)
+type icalWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *icalWriter) line(content string) {
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		// Never split a UTF-8 sequence
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.buf.WriteString(content[:cut] + "\r\n ")
+		content = content[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	w.buf.WriteString(content + "\r\n")
+}
+
+// text writes a property with a TEXT value, escaped as required by RFC 5545 3.3.11
+func (w *icalWriter) text(name, value string) {
+	value = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+	w.line(name + ":" + value)
+}
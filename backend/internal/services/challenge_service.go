@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ChallengeMaxAdvance is how far ahead a time slot can be challenged
+const ChallengeMaxAdvance = 30 * 24 * time.Hour
+
+// ChallengeService schedules matches between two players
+// Challenges are pending until the opponent answers; the result of an accepted
+// challenge is submitted as a regular pending match that the other player confirms
+type ChallengeService struct {
+	db            *sql.DB
+	challengeRepo repositories.ChallengeStore
+	userRepo      repositories.UserStore
+	sportService  *SportService
+	matchService  *MatchService
+}
+
+// NewChallengeService creates a new ChallengeService instance
+func NewChallengeService(
+	db *sql.DB,
+	challengeRepo repositories.ChallengeStore,
+	userRepo repositories.UserStore,
+	sportService *SportService,
+	matchService *MatchService,
+) *ChallengeService {
+	return &ChallengeService{
+		db:            db,
+		challengeRepo: challengeRepo,
+		userRepo:      userRepo,
+		sportService:  sportService,
+		matchService:  matchService,
+	}
+}
+
+// Create challenges an opponent to a match at a future time slot
+func (s *ChallengeService) Create(ctx context.Context, challengerID int, req *models.CreateChallengeRequest) (*models.Challenge, error) {
+	ctx, span := tracer.Start(ctx, "ChallengeService.Create")
+	defer span.End()
+
+	if req.OpponentID == challengerID {
+		return nil, fmt.Errorf("cannot challenge yourself")
+	}
+	if err := s.sportService.ValidateSportID(req.Sport); err != nil {
+		return nil, fmt.Errorf("invalid sport")
+	}
+
+	opponent, err := s.userRepo.GetByID(ctx, req.OpponentID)
+	if err != nil || opponent.Banned() {
+		return nil, fmt.Errorf("opponent not found")
+	}
+	if opponent.DeactivatedAt != nil {
+		return nil, ErrOpponentDeactivated
+	}
+
+	scheduledAt := req.ScheduledAt.UTC()
+	now := time.Now().UTC()
+	if !scheduledAt.After(now) {
+		return nil, fmt.Errorf("time slot is in the past")
+	}
+	if scheduledAt.After(now.Add(ChallengeMaxAdvance)) {
+		return nil, fmt.Errorf("time slot is more than %d days ahead", int(ChallengeMaxAdvance.Hours()/24))
+	}
+
+	challenge := &models.Challenge{
+		Sport:        req.Sport,
+		ChallengerID: challengerID,
+		OpponentID:   req.OpponentID,
+		ScheduledAt:  scheduledAt,
+	}
+	if message := utils.SanitizeString(strings.TrimSpace(req.Message)); message != "" {
+		challenge.Message = &message
+	}
+
+	if err := s.challengeRepo.Create(ctx, challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// Get returns a challenge the user takes part in
+// Other users' challenges are reported as not found
+func (s *ChallengeService) Get(ctx context.Context, challengeID, userID int) (*models.Challenge, error) {
+	ctx, span := tracer.Start(ctx, "ChallengeService.Get", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	challenge, err := s.challengeRepo.GetByID(ctx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.ChallengerID != userID && challenge.OpponentID != userID {
+		return nil, fmt.Errorf("challenge not found")
+	}
+	return challenge, nil
+}
+
+// List returns the user's incoming, outgoing or all challenges
+func (s *ChallengeService) List(ctx context.Context, userID int, direction string, status *string) ([]models.Challenge, error) {
+	ctx, span := tracer.Start(ctx, "ChallengeService.List", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	return s.challengeRepo.ListForUser(ctx, userID, direction, status)
+}
+
+// Accept agrees to play a pending challenge; slots that have passed cannot be accepted
+func (s *ChallengeService) Accept(ctx context.Context, challengeID, userID int) (*models.Challenge, error) {
+	ctx, span := tracer.Start(ctx, "ChallengeService.Accept", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	challenge, err := s.Get(ctx, challengeID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.OpponentID != userID {
+		return nil, fmt.Errorf("only the challenged player can accept")
+	}
+	if !challenge.ScheduledAt.After(time.Now()) {
+		return nil, fmt.Errorf("time slot has passed")
+	}
+	return s.challengeRepo.Respond(ctx, challengeID, userID, models.ChallengeAccepted)
+}
+
+// Decline refuses a pending challenge
+func (s *ChallengeService) Decline(ctx context.Context, challengeID, userID int) (*models.Challenge, error) {
+	ctx, span := tracer.Start(ctx, "ChallengeService.Decline", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	challenge, err := s.Get(ctx, challengeID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.OpponentID != userID {
+		return nil, fmt.Errorf("only the challenged player can decline")
+	}
+	return s.challengeRepo.Respond(ctx, challengeID, userID, models.ChallengeDeclined)
+}
+
+// Cancel withdraws a pending or accepted challenge
+func (s *ChallengeService) Cancel(ctx context.Context, challengeID, userID int) (*models.Challenge, error) {
+	ctx, span := tracer.Start(ctx, "ChallengeService.Cancel", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	challenge, err := s.Get(ctx, challengeID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.ChallengerID != userID {
+		return nil, fmt.Errorf("only the challenger can cancel")
+	}
+	return s.challengeRepo.Cancel(ctx, challengeID, userID)
+}
+
+// MatchRequest turns the result of a challenge into a match submission by one of its players
+func (s *ChallengeService) MatchRequest(challenge *models.Challenge, userID int, result *models.ChallengeResultRequest) *models.SubmitMatchRequest {
+	opponentID := challenge.OpponentID
+	if userID == challenge.OpponentID {
+		opponentID = challenge.ChallengerID
+	}
+
+	req := &models.SubmitMatchRequest{
+		Sport:         challenge.Sport,
+		OpponentID:    opponentID,
+		PlayerScore:   result.PlayerScore,
+		OpponentScore: result.OpponentScore,
+		BestOf:        result.BestOf,
+		Sets:          result.Sets,
+	}
+	if challenge.Message != nil {
+		req.Context = *challenge.Message
+	}
+	return req
+}
+
+// SubmitResult submits the result of an accepted challenge as a pending match
+// The challenge stays locked while the match is created, so only one result is entered
+func (s *ChallengeService) SubmitResult(ctx context.Context, challengeID, userID int, req *models.SubmitMatchRequest, fingerprint string) (*models.Match, error) {
+	ctx, span := tracer.Start(ctx, "ChallengeService.SubmitResult", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	challenge, err := s.challengeRepo.GetByIDForUpdate(ctx, tx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.ChallengerID != userID && challenge.OpponentID != userID {
+		return nil, fmt.Errorf("challenge not found")
+	}
+	if challenge.Status != models.ChallengeAccepted {
+		return nil, fmt.Errorf("challenge is not accepted")
+	}
+	if req.Sport != challenge.Sport || (req.OpponentID != challenge.ChallengerID && req.OpponentID != challenge.OpponentID) {
+		return nil, fmt.Errorf("result does not belong to this challenge")
+	}
+
+	match, err := s.matchService.SubmitMatch(ctx, req, userID, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.challengeRepo.Complete(ctx, tx, challengeID, match.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to complete challenge: %w", err)
+	}
+	return match, nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories/fakes"
+)
+
+func newChallengeFixture() (*ChallengeService, *matchFixture) {
+	f := newMatchFixture()
+	users := fakes.NewUsers(
+		models.User{ID: alice, Login: "alice"},
+		models.User{ID: bob, Login: "bob"},
+		models.User{ID: carol, Login: "carol", IsBanned: true},
+	)
+	service := NewChallengeService(fakes.NewDB(), fakes.NewChallenges(), users, f.service.sportService, f.service)
+	return service, f
+}
+
+func TestCreateChallengeValidation(t *testing.T) {
+	service, _ := newChallengeFixture()
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name string
+		req  models.CreateChallengeRequest
+		want string
+	}{
+		{"yourself", models.CreateChallengeRequest{Sport: "table_tennis", OpponentID: alice, ScheduledAt: tomorrow}, "cannot challenge yourself"},
+		{"unknown sport", models.CreateChallengeRequest{Sport: "chess", OpponentID: bob, ScheduledAt: tomorrow}, "invalid sport"},
+		{"banned opponent", models.CreateChallengeRequest{Sport: "table_tennis", OpponentID: carol, ScheduledAt: tomorrow}, "opponent not found"},
+		{"past slot", models.CreateChallengeRequest{Sport: "table_tennis", OpponentID: bob, ScheduledAt: time.Now().Add(-time.Minute)}, "in the past"},
+		{"too far ahead", models.CreateChallengeRequest{Sport: "table_tennis", OpponentID: bob, ScheduledAt: time.Now().Add(ChallengeMaxAdvance + time.Hour)}, "days ahead"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Create(context.Background(), alice, &tt.req)
+			wantError(t, err, tt.want)
+		})
+	}
+
+	req := &models.CreateChallengeRequest{Sport: "table_tennis", OpponentID: bob, ScheduledAt: tomorrow, Message: "  lunch break  "}
+	challenge, err := service.Create(context.Background(), alice, req)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if challenge.Status != models.ChallengePending || challenge.Message == nil || *challenge.Message != "lunch break" {
+		t.Errorf("challenge = %+v, want a pending challenge with a trimmed message", challenge)
+	}
+	_, err = service.Create(context.Background(), alice, req)
+	wantError(t, err, "already exists")
+}
+
+func TestChallengeOpponentAfterTemporaryBan(t *testing.T) {
+	service, _ := newChallengeFixture()
+	users := service.userRepo.(*fakes.Users)
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	ended := time.Now().Add(-time.Minute)
+	users.Add(models.User{ID: carol, Login: "carol", IsBanned: true, BannedUntil: &ended})
+	if _, err := service.Create(context.Background(), alice, &models.CreateChallengeRequest{Sport: "table_tennis", OpponentID: carol, ScheduledAt: tomorrow}); err != nil {
+		t.Errorf("Create against a player whose ban ended: %v", err)
+	}
+
+	running := time.Now().Add(time.Hour)
+	users.Add(models.User{ID: carol, Login: "carol", IsBanned: true, BannedUntil: &running})
+	_, err := service.Create(context.Background(), bob, &models.CreateChallengeRequest{Sport: "table_tennis", OpponentID: carol, ScheduledAt: tomorrow})
+	wantError(t, err, "opponent not found")
+}
+
+func TestChallengeResultBecomesPendingMatch(t *testing.T) {
+	service, f := newChallengeFixture()
+	challenge, err := service.Create(context.Background(), alice, &models.CreateChallengeRequest{Sport: "table_tennis", OpponentID: bob, ScheduledAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	result := service.MatchRequest(challenge, bob, &models.ChallengeResultRequest{PlayerScore: 11, OpponentScore: 8})
+	_, err = service.SubmitResult(context.Background(), challenge.ID, bob, result, "")
+	wantError(t, err, "not accepted")
+
+	_, err = service.Accept(context.Background(), challenge.ID, alice)
+	wantError(t, err, "only the challenged player")
+	if _, err := service.Accept(context.Background(), challenge.ID, bob); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	_, err = service.Decline(context.Background(), challenge.ID, bob)
+	wantError(t, err, "not pending")
+
+	match, err := service.SubmitResult(context.Background(), challenge.ID, bob, result, "")
+	if err != nil {
+		t.Fatalf("SubmitResult: %v", err)
+	}
+	if match.Player1ID != bob || match.Player2ID != alice || match.WinnerID != bob || match.Status != models.StatusPending {
+		t.Errorf("match = %+v, want a pending win of bob against alice", match)
+	}
+
+	completed, err := service.Get(context.Background(), challenge.ID, alice)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if completed.Status != models.ChallengeCompleted || completed.MatchID == nil || *completed.MatchID != match.ID {
+		t.Errorf("challenge = %+v, want it completed with match %d", completed, match.ID)
+	}
+	_, err = service.SubmitResult(context.Background(), challenge.ID, alice, service.MatchRequest(challenge, alice, &models.ChallengeResultRequest{PlayerScore: 11, OpponentScore: 2}), "")
+	wantError(t, err, "not accepted")
+
+	// The result is confirmed like any other match
+	if err := f.service.ConfirmMatch(context.Background(), match.ID, alice, ""); err != nil {
+		t.Fatalf("ConfirmMatch: %v", err)
+	}
+}
+
+func TestCancelChallenge(t *testing.T) {
+	service, _ := newChallengeFixture()
+	challenge, err := service.Create(context.Background(), alice, &models.CreateChallengeRequest{Sport: "table_tennis", OpponentID: bob, ScheduledAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	_, err = service.Cancel(context.Background(), challenge.ID, bob)
+	wantError(t, err, "only the challenger")
+	_, err = service.Get(context.Background(), challenge.ID, carol)
+	wantError(t, err, "challenge not found")
+
+	cancelled, err := service.Cancel(context.Background(), challenge.ID, alice)
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if cancelled.Status != models.ChallengeCancelled {
+		t.Errorf("status = %q, want cancelled", cancelled.Status)
+	}
+	_, err = service.Accept(context.Background(), challenge.ID, bob)
+	wantError(t, err, "not pending")
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ConfirmationTokenService issues and validates the signed tokens behind the
+// confirmation QR code shown after a match is submitted
+// A token is bound to the match's last update, so it works once: confirming,
+// denying, cancelling or editing the match invalidates it
+type ConfirmationTokenService struct {
+	matchRepo  repositories.MatchStore
+	signingKey []byte
+	ttl        time.Duration
+	baseURL    string
+}
+
+// NewConfirmationTokenService creates a confirmation token service
+// Tokens are signed with a key derived from secret and link to the confirmation page of the SPA at frontendURL
+func NewConfirmationTokenService(
+	matchRepo repositories.MatchStore,
+	secret string,
+	ttl time.Duration,
+	frontendURL string,
+) *ConfirmationTokenService {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("match-confirmation-tokens"))
+
+	return &ConfirmationTokenService{
+		matchRepo:  matchRepo,
+		signingKey: mac.Sum(nil),
+		ttl:        ttl,
+		baseURL:    frontendURL,
+	}
+}
+
+// Issue creates the confirmation link of a pending match for the opponent of its submitter
+func (s *ConfirmationTokenService) Issue(match *models.Match) *models.MatchConfirmationLink {
+	expiresAt := time.Now().Add(s.ttl).Truncate(time.Second)
+
+	payload := fmt.Sprintf("%d.%d.%d.%d", match.ID, confirmingPlayer(match), match.UpdatedAt.UnixMicro(), expiresAt.Unix())
+	token := payload + "." + s.sign(payload)
+
+	return &models.MatchConfirmationLink{
+		Token:     token,
+		URL:       s.baseURL + "/matches/confirm/" + url.PathEscape(token),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// Validate checks a token presented by userID and returns the match it confirms
+func (s *ConfirmationTokenService) Validate(ctx context.Context, token string, userID int) (*models.Match, error) {
+	ctx, span := tracer.Start(ctx, "ConfirmationTokenService.Validate", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid confirmation token")
+	}
+	payload := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(parts[4]), []byte(s.sign(payload))) {
+		return nil, fmt.Errorf("invalid confirmation token")
+	}
+
+	var fields [4]int64
+	for i := range fields {
+		v, err := strconv.ParseInt(parts[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid confirmation token")
+		}
+		fields[i] = v
+	}
+	matchID, opponentID, version, expires := int(fields[0]), int(fields[1]), fields[2], fields[3]
+
+	if time.Now().Unix() > expires {
+		return nil, fmt.Errorf("confirmation token expired")
+	}
+	if opponentID != userID {
+		return nil, fmt.Errorf("confirmation token belongs to another player")
+	}
+
+	match, err := s.matchRepo.GetByID(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match.Status != models.StatusPending || match.UpdatedAt.UnixMicro() != version {
+		return nil, fmt.Errorf("confirmation token already used")
+	}
+	return match, nil
+}
+
+func (s *ConfirmationTokenService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// confirmingPlayer returns the player who has to confirm a match
+func confirmingPlayer(match *models.Match) int {
+	if match.SubmittedBy == match.Player1ID {
+		return match.Player2ID
+	}
+	return match.Player1ID
+}
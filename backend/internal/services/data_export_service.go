@@ -0,0 +1,522 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DataExportLinkTTL is how long a built export can be downloaded
+	DataExportLinkTTL = 24 * time.Hour
+	// DataExportInterval is how often a user can have an export built; failed builds don't count
+	DataExportInterval = 24 * time.Hour
+	// dataExportStaleAfter is when an export stuck in processing is built again
+	dataExportStaleAfter = 30 * time.Minute
+)
+
+// Files of an export archive; the profile file also carries the export metadata
+const (
+	dataExportProfileFile   = "profile.json"
+	dataExportMatchesFile   = "matches.csv"
+	dataExportCommentsFile  = "comments.csv"
+	dataExportReactionsFile = "reactions.csv"
+)
+
+// UserDataExport represents all data associated with a user (Art. 15 GDPR)
+type UserDataExport struct {
+	ExportDate    string             `json:"export_date"`
+	ExportVersion string             `json:"export_version"`
+	Profile       UserProfileExport  `json:"profile"`
+	Matches       []MatchExport      `json:"matches"`
+	Comments      []CommentExport    `json:"comments"`
+	Reactions     []ReactionExport   `json:"reactions"`
+	DataInfo      DataProcessingInfo `json:"data_processing_info"`
+}
+
+// profileExportFile is the profile.json of an export archive
+type profileExportFile struct {
+	ExportDate    string             `json:"export_date"`
+	ExportVersion string             `json:"export_version"`
+	Profile       UserProfileExport  `json:"profile"`
+	DataInfo      DataProcessingInfo `json:"data_processing_info"`
+}
+
+// UserProfileExport contains user profile data
+type UserProfileExport struct {
+	ID          int                             `json:"id"`
+	IntraID     int                             `json:"intra_id"`
+	Login       string                          `json:"login"`
+	DisplayName string                          `json:"display_name"`
+	AvatarURL   string                          `json:"avatar_url"`
+	Campus      string                          `json:"campus"`
+	Sports      map[string]models.UserSportData `json:"sports"` // Ratings and record per sport
+	IsAdmin     bool                            `json:"is_admin"`
+	IsBanned    bool                            `json:"is_banned"`
+	CreatedAt   time.Time                       `json:"created_at"`
+	UpdatedAt   time.Time                       `json:"updated_at"`
+}
+
+// MatchExport contains match data for export
+type MatchExport struct {
+	ID            int        `json:"id"`
+	Sport         string     `json:"sport"`
+	Role          string     `json:"role"` // "player1", "player2", "submitter"
+	OpponentID    int        `json:"opponent_id,omitempty"`
+	PlayerScore   int        `json:"player_score"`
+	OpponentScore int        `json:"opponent_score"`
+	Won           bool       `json:"won"`
+	Status        string     `json:"status"`
+	ELOBefore     *int       `json:"elo_before,omitempty"`
+	ELOAfter      *int       `json:"elo_after,omitempty"`
+	ELODelta      *int       `json:"elo_delta,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ConfirmedAt   *time.Time `json:"confirmed_at,omitempty"`
+}
+
+// CommentExport contains comment data for export
+type CommentExport struct {
+	ID        int       `json:"id"`
+	MatchID   int       `json:"match_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReactionExport contains reaction data for export
+type ReactionExport struct {
+	ID        int       `json:"id"`
+	MatchID   int       `json:"match_id"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DataProcessingInfo provides information about data processing (Art. 13/14 GDPR)
+type DataProcessingInfo struct {
+	Purpose         string   `json:"purpose"`
+	LegalBasis      string   `json:"legal_basis"`
+	RetentionPeriod string   `json:"retention_period"`
+	ThirdParties    []string `json:"third_parties"`
+	YourRights      []string `json:"your_rights"`
+	ContactEmail    string   `json:"contact_email"`
+}
+
+// DataExportService builds GDPR data exports (Art. 15/20)
+// Large accounts request an export that is built in the background by the data
+// export job and downloaded through a signed, time-limited link
+type DataExportService struct {
+	db         *sql.DB
+	userRepo   repositories.UserStore
+	exportRepo repositories.DataExportStore
+	signingKey []byte
+	baseURL    string
+}
+
+// NewDataExportService creates a data export service
+// Download links are signed with a key derived from secret and point at publicAPIURL
+func NewDataExportService(
+	db *sql.DB,
+	userRepo repositories.UserStore,
+	exportRepo repositories.DataExportStore,
+	secret string,
+	publicAPIURL string,
+) *DataExportService {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("data-export-links"))
+
+	return &DataExportService{
+		db:         db,
+		userRepo:   userRepo,
+		exportRepo: exportRepo,
+		signingKey: mac.Sum(nil),
+		baseURL:    publicAPIURL,
+	}
+}
+
+// Build collects all data associated with a user
+func (s *DataExportService) Build(ctx context.Context, userID int) (*UserDataExport, error) {
+	ctx, span := tracer.Start(ctx, "DataExportService.Build", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user data: %w", err)
+	}
+
+	matches, err := s.getMatchesForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve match data: %w", err)
+	}
+
+	comments, err := s.getCommentsForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve comment data: %w", err)
+	}
+
+	reactions, err := s.getReactionsForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve reaction data: %w", err)
+	}
+
+	return &UserDataExport{
+		ExportDate:    time.Now().UTC().Format(time.RFC3339),
+		ExportVersion: "1.2",
+		Profile: UserProfileExport{
+			ID:          user.ID,
+			IntraID:     user.IntraID,
+			Login:       user.Login,
+			DisplayName: user.DisplayName,
+			AvatarURL:   user.AvatarURL,
+			Campus:      user.Campus,
+			Sports:      user.Sports,
+			IsAdmin:     user.IsAdmin,
+			IsBanned:    user.IsBanned,
+			CreatedAt:   user.CreatedAt,
+			UpdatedAt:   user.UpdatedAt,
+		},
+		Matches:   matches,
+		Comments:  comments,
+		Reactions: reactions,
+		DataInfo: DataProcessingInfo{
+			Purpose:         "ELO Leaderboard ranking system for table tennis and table football at 42 Heilbronn",
+			LegalBasis:      "Art. 6(1)(a) GDPR - Consent, Art. 6(1)(b) GDPR - Contract performance",
+			RetentionPeriod: "Data is retained until account deletion or upon request",
+			ThirdParties: []string{
+				"42 Intra API (authentication)",
+				"Hosting provider (infrastructure)",
+			},
+			YourRights: []string{
+				"Right to access (Art. 15 GDPR)",
+				"Right to rectification (Art. 16 GDPR)",
+				"Right to erasure (Art. 17 GDPR)",
+				"Right to restriction of processing (Art. 18 GDPR)",
+				"Right to data portability (Art. 20 GDPR)",
+				"Right to object (Art. 21 GDPR)",
+			},
+			ContactEmail: "privacy@example.com",
+		},
+	}, nil
+}
+
+// Request queues an export for a user
+// An export that is still queued, being built or downloadable, or that was requested
+// within DataExportInterval, is returned instead of queuing another, so repeated
+// requests don't repeat the heavy queries
+func (s *DataExportService) Request(ctx context.Context, userID int) (*models.DataExport, bool, error) {
+	ctx, span := tracer.Start(ctx, "DataExportService.Request", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	latest, err := s.exportRepo.GetLatestForUser(ctx, userID)
+	if err != nil && err.Error() != "data export not found" {
+		return nil, false, err
+	}
+	if latest != nil && latest.Status != models.DataExportFailed &&
+		(!dataExportExpired(latest) || time.Since(latest.CreatedAt) < DataExportInterval) {
+		s.sign(latest)
+		return latest, false, nil
+	}
+
+	export, err := s.exportRepo.Create(ctx, userID)
+	if err != nil {
+		if err.Error() == "data export already in progress" {
+			// A concurrent request queued one first
+			latest, err := s.exportRepo.GetLatestForUser(ctx, userID)
+			return latest, false, err
+		}
+		return nil, false, err
+	}
+	return export, true, nil
+}
+
+// GetLatest returns the most recent export of a user with its download link if ready
+func (s *DataExportService) GetLatest(ctx context.Context, userID int) (*models.DataExport, error) {
+	ctx, span := tracer.Start(ctx, "DataExportService.GetLatest", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	export, err := s.exportRepo.GetLatestForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(export)
+	return export, nil
+}
+
+// ProcessNext builds the oldest queued export and returns it, or nil if none is queued
+// A failed build is recorded on the export and returned as an error
+func (s *DataExportService) ProcessNext(ctx context.Context) (*models.DataExport, error) {
+	ctx, span := tracer.Start(ctx, "DataExportService.ProcessNext")
+	defer span.End()
+
+	export, err := s.exportRepo.ClaimNext(ctx, time.Now().Add(-dataExportStaleAfter))
+	if err != nil || export == nil {
+		return nil, err
+	}
+
+	archive, err := s.buildArchive(ctx, export.UserID)
+	if err != nil {
+		if failErr := s.exportRepo.Fail(ctx, export.ID, err.Error(), time.Now().Add(DataExportLinkTTL)); failErr != nil {
+			slog.Error("Failed to record data export failure", "export_id", export.ID, "error", failErr)
+		}
+		return nil, fmt.Errorf("failed to build data export %d: %w", export.ID, err)
+	}
+
+	if err := s.exportRepo.Complete(ctx, export, archive, time.Now().Add(DataExportLinkTTL)); err != nil {
+		return nil, err
+	}
+	s.sign(export)
+
+	slog.Info("Data export built", "export_id", export.ID, "user_id", export.UserID, "size_bytes", len(archive))
+	return export, nil
+}
+
+// PurgeExpired deletes exports whose download link has expired
+func (s *DataExportService) PurgeExpired(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "DataExportService.PurgeExpired")
+	defer span.End()
+
+	return s.exportRepo.DeleteExpired(ctx, time.Now())
+}
+
+// Download returns the archive behind a signed download link
+func (s *DataExportService) Download(ctx context.Context, id int, expires int64, signature string) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "DataExportService.Download")
+	defer span.End()
+
+	if !hmac.Equal([]byte(signature), []byte(s.signature(id, expires))) {
+		return nil, fmt.Errorf("invalid download link")
+	}
+	if time.Now().Unix() > expires {
+		return nil, fmt.Errorf("download link expired")
+	}
+	return s.exportRepo.GetArchive(ctx, id)
+}
+
+// buildArchive builds the export of a user as a zip archive
+func (s *DataExportService) buildArchive(ctx context.Context, userID int) ([]byte, error) {
+	data, err := s.Build(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDataExportArchive(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteDataExportArchive writes an export as a zip with profile.json and one CSV
+// file each for matches, comments and reactions
+func WriteDataExportArchive(w io.Writer, data *UserDataExport) error {
+	zw := zip.NewWriter(w)
+
+	f, err := zw.Create(dataExportProfileFile)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(profileExportFile{
+		ExportDate:    data.ExportDate,
+		ExportVersion: data.ExportVersion,
+		Profile:       data.Profile,
+		DataInfo:      data.DataInfo,
+	}); err != nil {
+		return err
+	}
+
+	for _, table := range []struct {
+		name string
+		rows interface{}
+	}{
+		{dataExportMatchesFile, data.Matches},
+		{dataExportCommentsFile, data.Comments},
+		{dataExportReactionsFile, data.Reactions},
+	} {
+		f, err := zw.Create(table.name)
+		if err != nil {
+			return err
+		}
+		if err := utils.EncodeCSV(f, table.rows); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", table.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// dataExportExpired reports whether a ready export is past its download window
+func dataExportExpired(export *models.DataExport) bool {
+	return export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt)
+}
+
+// sign sets the download link of a ready export
+func (s *DataExportService) sign(export *models.DataExport) {
+	if export.Status != models.DataExportReady || export.ExpiresAt == nil || dataExportExpired(export) {
+		return
+	}
+	expires := export.ExpiresAt.Unix()
+	export.DownloadURL = fmt.Sprintf("%s/api/data-exports/%d/download?expires=%d&signature=%s",
+		s.baseURL, export.ID, expires, s.signature(export.ID, expires))
+}
+
+func (s *DataExportService) signature(id int, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(strconv.Itoa(id) + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *DataExportService) getMatchesForUser(ctx context.Context, userID int) ([]MatchExport, error) {
+	query := `
+		SELECT id, sport, player1_id, player2_id, player1_score, player2_score,
+		       winner_id, status, player1_elo_before, player1_elo_after, player1_elo_delta,
+		       player2_elo_before, player2_elo_after, player2_elo_delta,
+		       submitted_by, created_at, confirmed_at
+		FROM matches_all
+		WHERE player1_id = $1 OR player2_id = $1 OR submitted_by = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []MatchExport
+	for rows.Next() {
+		var m struct {
+			ID               int
+			Sport            string
+			Player1ID        int
+			Player2ID        int
+			Player1Score     int
+			Player2Score     int
+			WinnerID         int
+			Status           string
+			Player1ELOBefore *int
+			Player1ELOAfter  *int
+			Player1ELODelta  *int
+			Player2ELOBefore *int
+			Player2ELOAfter  *int
+			Player2ELODelta  *int
+			SubmittedBy      int
+			CreatedAt        time.Time
+			ConfirmedAt      *time.Time
+		}
+
+		if err := rows.Scan(
+			&m.ID, &m.Sport, &m.Player1ID, &m.Player2ID, &m.Player1Score, &m.Player2Score,
+			&m.WinnerID, &m.Status, &m.Player1ELOBefore, &m.Player1ELOAfter, &m.Player1ELODelta,
+			&m.Player2ELOBefore, &m.Player2ELOAfter, &m.Player2ELODelta,
+			&m.SubmittedBy, &m.CreatedAt, &m.ConfirmedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		export := MatchExport{
+			ID:          m.ID,
+			Sport:       m.Sport,
+			Status:      m.Status,
+			CreatedAt:   m.CreatedAt,
+			ConfirmedAt: m.ConfirmedAt,
+		}
+
+		// Determine role and set appropriate fields
+		if m.Player1ID == userID {
+			export.Role = "player1"
+			export.OpponentID = m.Player2ID
+			export.PlayerScore = m.Player1Score
+			export.OpponentScore = m.Player2Score
+			export.Won = m.WinnerID == userID
+			export.ELOBefore = m.Player1ELOBefore
+			export.ELOAfter = m.Player1ELOAfter
+			export.ELODelta = m.Player1ELODelta
+		} else if m.Player2ID == userID {
+			export.Role = "player2"
+			export.OpponentID = m.Player1ID
+			export.PlayerScore = m.Player2Score
+			export.OpponentScore = m.Player1Score
+			export.Won = m.WinnerID == userID
+			export.ELOBefore = m.Player2ELOBefore
+			export.ELOAfter = m.Player2ELOAfter
+			export.ELODelta = m.Player2ELODelta
+		} else if m.SubmittedBy == userID {
+			export.Role = "submitter"
+			export.PlayerScore = m.Player1Score
+			export.OpponentScore = m.Player2Score
+		}
+
+		matches = append(matches, export)
+	}
+
+	return matches, rows.Err()
+}
+
+func (s *DataExportService) getCommentsForUser(ctx context.Context, userID int) ([]CommentExport, error) {
+	query := `
+		SELECT id, match_id, content, created_at, updated_at
+		FROM comments
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []CommentExport
+	for rows.Next() {
+		var c CommentExport
+		if err := rows.Scan(&c.ID, &c.MatchID, &c.Content, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
+
+func (s *DataExportService) getReactionsForUser(ctx context.Context, userID int) ([]ReactionExport, error) {
+	query := `
+		SELECT id, match_id, emoji, created_at
+		FROM reactions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []ReactionExport
+	for rows.Next() {
+		var r ReactionExport
+		if err := rows.Scan(&r.ID, &r.MatchID, &r.Emoji, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, r)
+	}
+
+	return reactions, rows.Err()
+}
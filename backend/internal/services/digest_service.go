@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// digestSize caps the entries of each digest section
+const digestSize = 5
+
+// DigestService generates the daily and weekly digest of every sport
+// A digest covers a finished UTC day or ISO week (Monday to Monday) and is generated once
+type DigestService struct {
+	digestRepo   repositories.DigestStore
+	snapshotRepo repositories.SnapshotStore
+	matchService *MatchService
+	sportService *SportService
+}
+
+// NewDigestService creates a digest service
+func NewDigestService(
+	digestRepo repositories.DigestStore,
+	snapshotRepo repositories.SnapshotStore,
+	matchService *MatchService,
+	sportService *SportService,
+) *DigestService {
+	return &DigestService{
+		digestRepo:   digestRepo,
+		snapshotRepo: snapshotRepo,
+		matchService: matchService,
+		sportService: sportService,
+	}
+}
+
+// GenerateDue generates the digests of the last finished day and week that are missing
+// Returns the new digests; a failing sport does not stop the others
+func (s *DigestService) GenerateDue(ctx context.Context, now time.Time) ([]*models.Digest, error) {
+	ctx, span := tracer.Start(ctx, "DigestService.GenerateDue")
+	defer span.End()
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	weekEnd := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)) // this week's Monday
+
+	periods := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{models.DigestDaily, today.AddDate(0, 0, -1), today},
+		{models.DigestWeekly, weekEnd.AddDate(0, 0, -7), weekEnd},
+	}
+
+	sports, err := s.sportService.GetAllActiveSports()
+	if err != nil {
+		return nil, err
+	}
+
+	var generated []*models.Digest
+	var firstErr error
+	for _, sport := range sports {
+		for _, period := range periods {
+			if ctx.Err() != nil {
+				return generated, ctx.Err()
+			}
+
+			exists, err := s.digestRepo.Exists(ctx, sport.ID, period.name, period.start)
+			if err == nil && !exists {
+				var digest *models.Digest
+				digest, err = s.generate(ctx, sport.ID, period.name, period.start, period.end)
+				if err == nil && digest != nil {
+					generated = append(generated, digest)
+				}
+			}
+			if err != nil {
+				slog.Error("Failed to generate digest", "sport", sport.ID, "period", period.name, "error", err)
+				if firstErr == nil {
+					firstErr = fmt.Errorf("digest %s/%s: %w", sport.ID, period.name, err)
+				}
+			}
+		}
+	}
+	return generated, firstErr
+}
+
+// generate computes and stores one digest; returns nil if another instance stored it first
+func (s *DigestService) generate(ctx context.Context, sport, period string, start, end time.Time) (*models.Digest, error) {
+	digest := &models.Digest{
+		Sport:       sport,
+		Period:      period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+
+	var err error
+	if digest.Matches, err = s.digestRepo.CountMatches(ctx, sport, start, end); err != nil {
+		return nil, err
+	}
+	if digest.Upsets, err = s.digestRepo.GetUpsets(ctx, sport, start, end, digestSize); err != nil {
+		return nil, err
+	}
+	if digest.MostActive, err = s.digestRepo.GetMostActive(ctx, sport, start, end, digestSize); err != nil {
+		return nil, err
+	}
+	if digest.RankMovers, err = s.rankMovers(ctx, sport, start); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.digestRepo.Save(ctx, digest)
+	if err != nil || !saved {
+		return nil, err
+	}
+	return digest, nil
+}
+
+// rankMovers compares the leaderboard snapshot taken on the first day of the period with the
+// current leaderboard; digests are generated right after the period, so that is its end
+// Without a snapshot there are no movers
+func (s *DigestService) rankMovers(ctx context.Context, sport string, start time.Time) ([]models.DigestRankMover, error) {
+	previous, err := s.snapshotRepo.GetRanks(ctx, start, sport)
+	if err != nil || len(previous) == 0 {
+		return []models.DigestRankMover{}, err
+	}
+	entries, err := s.matchService.GetLeaderboard(ctx, sport)
+	if err != nil {
+		return nil, err
+	}
+
+	movers := []models.DigestRankMover{}
+	for _, entry := range entries {
+		previousRank, ok := previous[entry.User.ID]
+		if !ok || previousRank == entry.Rank {
+			continue
+		}
+		movers = append(movers, models.DigestRankMover{
+			UserID:       entry.User.ID,
+			PreviousRank: previousRank,
+			Rank:         entry.Rank,
+			Change:       previousRank - entry.Rank,
+		})
+	}
+
+	sort.SliceStable(movers, func(i, j int) bool {
+		return abs(movers[i].Change) > abs(movers[j].Change)
+	})
+	if len(movers) > digestSize {
+		movers = movers[:digestSize]
+	}
+	return movers, nil
+}
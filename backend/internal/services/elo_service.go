@@ -1,21 +1,95 @@
 package services
 
-import "math"
+import (
+	"math"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+)
 
 type ELOService struct {
-	kFactor int
+	kFactor       int
+	forfeitFactor float64
+	sports        *SportService
+}
+
+// NewELOService creates an ELO calculator
+// Each sport's K-factor strategy picks the K-factors; kFactor is the default for sports
+// that cannot be loaded and the one matches were calculated with before K-factors were recorded
+// forfeitFactor scales rating changes of forfeits (0 = unrated, 1 = like a played match)
+func NewELOService(kFactor int, forfeitFactor float64, sports *SportService) *ELOService {
+	return &ELOService{kFactor: kFactor, forfeitFactor: forfeitFactor, sports: sports}
+}
+
+// ELOPlayer is one player's side of a rating calculation
+// Score is the player's score in the match, used by margin-of-victory weighting
+type ELOPlayer struct {
+	ELO     int
+	KFactor int
+	Score   int
 }
 
-func NewELOService(kFactor int) *ELOService {
-	return &ELOService{kFactor: kFactor}
+// KFactor returns the K-factor of a player in a sport from their rating and the number
+// of matches they played in it before
+func (s *ELOService) KFactor(sportID string, elo, matchesPlayed int) int {
+	config, err := s.sports.GetSportConfig(sportID)
+	if err != nil {
+		return s.kFactor
+	}
+	return config.Strategy().KFactor(elo, matchesPlayed)
+}
+
+// BaseKFactor returns the K-factor of a sport before any strategy tiers apply
+func (s *ELOService) BaseKFactor(sportID string) int {
+	config, err := s.sports.GetSportConfig(sportID)
+	if err != nil {
+		return s.kFactor
+	}
+	return config.KFactor
+}
+
+// Rules returns the margin-of-victory rule and rating bounds of a sport; sports that
+// cannot be loaded are not weighted and only kept from going negative
+func (s *ELOService) Rules(sportID string) ELORules {
+	config, err := s.sports.GetSportConfig(sportID)
+	if err != nil {
+		return ELORules{}
+	}
+	return config.ELORules()
+}
+
+// DefaultKFactor returns the configured K-factor, used by matches without recorded K-factors
+func (s *ELOService) DefaultKFactor() int {
+	return s.kFactor
 }
 
 // CalculateELO calculates new ELO ratings after a match
+// Each player's change uses their own K-factor, scaled by the margin rule's multiplier
+// for the players' scores; the new ratings are clamped to the rating bounds
 // Returns: player1NewELO, player2NewELO, player1Delta, player2Delta
-func (s *ELOService) CalculateELO(player1ELO, player2ELO int, player1Won bool) (int, int, int, int) {
+func (s *ELOService) CalculateELO(player1, player2 ELOPlayer, player1Won bool, rules ELORules) (int, int, int, int) {
+	player1Delta, player2Delta := s.deltas(player1, player2, player1Won, rules.Margin.Multiplier(player1.Score, player2.Score))
+
+	return s.apply(player1, player2, player1Delta, player2Delta, rules.Bounds)
+}
+
+// CalculateForfeitELO calculates new ELO ratings after a forfeit
+// The changes of a played match are scaled down by the forfeit factor, since a
+// forfeit says less about skill than a game
+// Forfeits have no scores, so the margin rule does not apply; the rating bounds do
+func (s *ELOService) CalculateForfeitELO(player1, player2 ELOPlayer, player1Won bool, rules ELORules) (int, int, int, int) {
+	player1Delta, player2Delta := s.deltas(player1, player2, player1Won, 1.0)
+
+	player1Delta = int(math.Round(float64(player1Delta) * s.forfeitFactor))
+	player2Delta = int(math.Round(float64(player2Delta) * s.forfeitFactor))
+
+	return s.apply(player1, player2, player1Delta, player2Delta, rules.Bounds)
+}
+
+// deltas returns the rating changes of a played match, scaled by multiplier
+func (s *ELOService) deltas(player1, player2 ELOPlayer, player1Won bool, multiplier float64) (int, int) {
 	// Expected scores
-	expectedPlayer1 := s.expectedScore(player1ELO, player2ELO)
-	expectedPlayer2 := s.expectedScore(player2ELO, player1ELO)
+	expectedPlayer1 := s.expectedScore(player1.ELO, player2.ELO)
+	expectedPlayer2 := s.expectedScore(player2.ELO, player1.ELO)
 
 	// Actual scores
 	var actualPlayer1, actualPlayer2 float64
@@ -27,14 +101,64 @@ func (s *ELOService) CalculateELO(player1ELO, player2ELO int, player1Won bool) (
 		actualPlayer2 = 1.0
 	}
 
-	// Calculate new ratings
-	player1Delta := int(float64(s.kFactor) * (actualPlayer1 - expectedPlayer1))
-	player2Delta := int(float64(s.kFactor) * (actualPlayer2 - expectedPlayer2))
+	player1Delta := int(float64(player1.KFactor) * multiplier * (actualPlayer1 - expectedPlayer1))
+	player2Delta := int(float64(player2.KFactor) * multiplier * (actualPlayer2 - expectedPlayer2))
+
+	return player1Delta, player2Delta
+}
+
+// apply adds the deltas to the ratings and clamps the results to the bounds; a player
+// stopped by the floor or the ceiling gets the smaller delta that got them there
+// Returns: player1NewELO, player2NewELO, player1Delta, player2Delta
+func (s *ELOService) apply(player1, player2 ELOPlayer, player1Delta, player2Delta int, bounds RatingBounds) (int, int, int, int) {
+	player1NewELO := bounds.Clamp(player1.ELO + player1Delta)
+	player2NewELO := bounds.Clamp(player2.ELO + player2Delta)
+
+	return player1NewELO, player2NewELO, player1NewELO - player1.ELO, player2NewELO - player2.ELO
+}
+
+// Explain returns the inputs and intermediate values of CalculateELO, or of
+// CalculateForfeitELO for forfeits, for the given ratings before a match
+// The breakdown's KFactor is left for the caller, as it describes the sport
+// RawChange is the change before rounding and the rating bounds
+func (s *ELOService) Explain(player1, player2 ELOPlayer, player1Won, forfeit bool, rules ELORules) models.ELOBreakdown {
+	breakdown := models.ELOBreakdown{
+		Result:           models.ResultPlayed,
+		MarginMultiplier: rules.Margin.Multiplier(player1.Score, player2.Score),
+	}
+	if forfeit {
+		factor := s.forfeitFactor
+		breakdown.Result = models.ResultForfeit
+		breakdown.ForfeitFactor = &factor
+		breakdown.MarginMultiplier = 1.0
+	}
+	multiplier := breakdown.MarginMultiplier
+
+	explain := func(player, opponent ELOPlayer, won bool) models.ELOBreakdownPlayer {
+		p := models.ELOBreakdownPlayer{
+			ELOBefore:     player.ELO,
+			KFactor:       player.KFactor,
+			ExpectedScore: s.expectedScore(player.ELO, opponent.ELO),
+		}
+		if won {
+			p.ActualScore = 1.0
+		}
+		p.RawChange = float64(player.KFactor) * multiplier * (p.ActualScore - p.ExpectedScore)
+		return p
+	}
+	breakdown.Player1 = explain(player1, player2, player1Won)
+	breakdown.Player2 = explain(player2, player1, !player1Won)
 
-	player1NewELO := player1ELO + player1Delta
-	player2NewELO := player2ELO + player2Delta
+	calculate := s.CalculateELO
+	if forfeit {
+		calculate = s.CalculateForfeitELO
+		breakdown.Player1.RawChange *= s.forfeitFactor
+		breakdown.Player2.RawChange *= s.forfeitFactor
+	}
+	breakdown.Player1.ELOAfter, breakdown.Player2.ELOAfter, breakdown.Player1.Delta, breakdown.Player2.Delta =
+		calculate(player1, player2, player1Won, rules)
 
-	return player1NewELO, player2NewELO, player1Delta, player2Delta
+	return breakdown
 }
 
 // expectedScore calculates the expected score for a player
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMarginRuleMultiplier(t *testing.T) {
+	rule := MarginRule{Enabled: true, MaxMultiplier: 2}
+
+	tests := []struct {
+		name         string
+		rule         MarginRule
+		player1Score int
+		player2Score int
+		want         float64
+	}{
+		{"disabled", MarginRule{MaxMultiplier: 2}, 11, 1, 1},
+		{"zero value", MarginRule{}, 11, 0, 1},
+		{"cap of one", MarginRule{Enabled: true, MaxMultiplier: 1}, 11, 0, 1},
+		{"shutout reaches the cap", rule, 11, 0, 2},
+		{"shutout by player 2", rule, 0, 11, 2},
+		{"narrow win", rule, 11, 9, 1 + 2.0/11},
+		{"clear win", rule, 11, 1, 1 + 10.0/11},
+		{"series", rule, 2, 1, 1.5},
+		{"forfeit without scores", rule, 0, 0, 1},
+		{"equal scores", rule, 5, 5, 1},
+		{"negative losing score stays capped", rule, 3, -2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Multiplier(tt.player1Score, tt.player2Score); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Multiplier(%d, %d) = %v, want %v", tt.player1Score, tt.player2Score, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateELOMarginOfVictory(t *testing.T) {
+	elo := NewELOService(32, 0.5, nil)
+	rule := MarginRule{Enabled: true, MaxMultiplier: 1.5}
+	players := func(loserScore int) (ELOPlayer, ELOPlayer) {
+		return ELOPlayer{ELO: 1000, KFactor: 32, Score: 11}, ELOPlayer{ELO: 1000, KFactor: 32, Score: loserScore}
+	}
+
+	// Without weighting the score makes no difference
+	p1, p2 := players(1)
+	_, _, crushing, _ := elo.CalculateELO(p1, p2, true, ELORules{})
+	if crushing != 16 {
+		t.Fatalf("unweighted delta = %d, want 16", crushing)
+	}
+
+	p1, p2 = players(9)
+	_, _, narrow, narrowLoss := elo.CalculateELO(p1, p2, true, ELORules{Margin: rule})
+	p1, p2 = players(1)
+	_, _, crushing, crushingLoss := elo.CalculateELO(p1, p2, true, ELORules{Margin: rule})
+	if crushing <= narrow {
+		t.Errorf("11-1 moved %d points, 11-9 moved %d; want the clearer win to move more", crushing, narrow)
+	}
+	if crushingLoss >= narrowLoss {
+		t.Errorf("11-1 cost %d points, 11-9 cost %d; want the clearer loss to cost more", crushingLoss, narrowLoss)
+	}
+
+	// A shutout is capped at the maximum multiplier
+	p1, p2 = players(0)
+	_, _, shutout, _ := elo.CalculateELO(p1, p2, true, ELORules{Margin: rule})
+	if shutout != 24 {
+		t.Errorf("shutout delta = %d, want 24 (16 * 1.5)", shutout)
+	}
+
+	// An upset is weighted too, and each player keeps their own K-factor
+	underdog := ELOPlayer{ELO: 800, KFactor: 48, Score: 11}
+	favourite := ELOPlayer{ELO: 1200, KFactor: 16, Score: 0}
+	_, _, plain1, plain2 := elo.CalculateELO(underdog, favourite, true, ELORules{})
+	_, _, weighted1, weighted2 := elo.CalculateELO(underdog, favourite, true, ELORules{Margin: rule})
+	if weighted1 <= plain1 || weighted2 >= plain2 {
+		t.Errorf("weighted upset = %d/%d, unweighted = %d/%d; want larger changes", weighted1, weighted2, plain1, plain2)
+	}
+}
+
+func TestCalculateForfeitELOIgnoresMargin(t *testing.T) {
+	elo := NewELOService(32, 0.5, nil)
+	player1 := ELOPlayer{ELO: 1000, KFactor: 32, Score: 11}
+	player2 := ELOPlayer{ELO: 1000, KFactor: 32}
+
+	_, _, weighted, _ := elo.CalculateForfeitELO(player1, player2, true, ELORules{Margin: MarginRule{Enabled: true, MaxMultiplier: 3}})
+	_, _, plain, _ := elo.CalculateForfeitELO(player1, player2, true, ELORules{})
+	if weighted != plain || plain != 8 {
+		t.Errorf("forfeit deltas = %d weighted, %d unweighted; want 8 for both", weighted, plain)
+	}
+}
+
+func TestExplainMarginMultiplier(t *testing.T) {
+	elo := NewELOService(32, 0.5, nil)
+	rule := MarginRule{Enabled: true, MaxMultiplier: 1.5}
+	player1 := ELOPlayer{ELO: 1000, KFactor: 32, Score: 11}
+	player2 := ELOPlayer{ELO: 1000, KFactor: 32, Score: 0}
+
+	breakdown := elo.Explain(player1, player2, true, false, ELORules{Margin: rule})
+	if breakdown.MarginMultiplier != 1.5 {
+		t.Errorf("MarginMultiplier = %v, want 1.5", breakdown.MarginMultiplier)
+	}
+	if breakdown.Player1.RawChange != 24 || breakdown.Player1.Delta != 24 {
+		t.Errorf("player1 raw change %v, delta %d; want 24 for both", breakdown.Player1.RawChange, breakdown.Player1.Delta)
+	}
+
+	forfeit := elo.Explain(player1, player2, true, true, ELORules{Margin: rule})
+	if forfeit.MarginMultiplier != 1 {
+		t.Errorf("forfeit MarginMultiplier = %v, want 1", forfeit.MarginMultiplier)
+	}
+}
+
+func TestRatingBoundsClamp(t *testing.T) {
+	bounds := RatingBounds{Floor: 100, Ceiling: 4000}
+
+	tests := []struct {
+		name   string
+		bounds RatingBounds
+		elo    int
+		want   int
+	}{
+		{"within", bounds, 1000, 1000},
+		{"at the floor", bounds, 100, 100},
+		{"below the floor", bounds, 37, 100},
+		{"negative", bounds, -250, 100},
+		{"at the ceiling", bounds, 4000, 4000},
+		{"above the ceiling", bounds, 9001, 4000},
+		{"zero value keeps ratings non-negative", RatingBounds{}, -5, 0},
+		{"zero value has no ceiling", RatingBounds{}, 9001, 9001},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.bounds.Clamp(tt.elo); got != tt.want {
+				t.Errorf("Clamp(%d) = %d, want %d", tt.elo, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateELOClampsToBounds(t *testing.T) {
+	elo := NewELOService(32, 0.5, nil)
+	rules := ELORules{Bounds: RatingBounds{Floor: 100, Ceiling: 4000}}
+
+	// The loser stops at the floor and only loses what got them there
+	winner := ELOPlayer{ELO: 110, KFactor: 32}
+	loser := ELOPlayer{ELO: 110, KFactor: 32}
+	_, loserAfter, _, loserDelta := elo.CalculateELO(winner, loser, true, rules)
+	if loserAfter != 100 || loserDelta != -10 {
+		t.Errorf("loser after %d, delta %d; want 100 and -10", loserAfter, loserDelta)
+	}
+
+	// The winner stops at the ceiling
+	top := ELOPlayer{ELO: 3995, KFactor: 32}
+	challenger := ELOPlayer{ELO: 3995, KFactor: 32}
+	topAfter, _, topDelta, _ := elo.CalculateELO(top, challenger, true, rules)
+	if topAfter != 4000 || topDelta != 5 {
+		t.Errorf("winner after %d, delta %d; want 4000 and 5", topAfter, topDelta)
+	}
+
+	// Forfeits are clamped too
+	loserAfter, _, loserDelta, _ = elo.CalculateForfeitELO(ELOPlayer{ELO: 104, KFactor: 32}, ELOPlayer{ELO: 104, KFactor: 32}, false, rules)
+	if loserAfter != 100 || loserDelta != -4 {
+		t.Errorf("forfeit loser after %d, delta %d; want 100 and -4", loserAfter, loserDelta)
+	}
+}
@@ -0,0 +1,35 @@
+package services
+
+// Realtime event types emitted by the services
+const (
+	EventMatchPending       = "match.pending"
+	EventMatchConfirmed     = "match.confirmed"
+	EventMatchDenied        = "match.denied"
+	EventMatchCancelled     = "match.cancelled"
+	EventMatchCorrected     = "match.corrected"
+	EventLeaderboardUpdated = "leaderboard.updated"
+)
+
+// EventPublisher pushes events to live subscribers (implemented by realtime.Hub)
+type EventPublisher interface {
+	Publish(channel, eventType string, data interface{})
+}
+
+// MatchEvent is the payload of match lifecycle events
+// Only IDs are sent; clients refetch what they display so privacy rules stay in one place
+type MatchEvent struct {
+	MatchID   int    `json:"match_id"`
+	Sport     string `json:"sport"`
+	Player1ID int    `json:"player1_id"`
+	Player2ID int    `json:"player2_id"`
+}
+
+// Publishers fans events out to several publishers, e.g. the realtime hub and outbound webhooks
+type Publishers []EventPublisher
+
+// Publish forwards the event to every publisher
+func (p Publishers) Publish(channel, eventType string, data interface{}) {
+	for _, publisher := range p {
+		publisher.Publish(channel, eventType, data)
+	}
+}
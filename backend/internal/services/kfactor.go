@@ -0,0 +1,72 @@
+package services
+
+// K-factor strategies of a sport
+const (
+	KFactorFixed  = "fixed"  // Every player uses the sport's K-factor
+	KFactorTiered = "tiered" // Provisional players move faster, top-rated players slower
+)
+
+// Defaults of the tiered strategy, matching the sports table
+const (
+	defaultProvisionalMatches  = 10
+	defaultProvisionalKFactor  = 48
+	defaultHighRatingThreshold = 2100
+	defaultHighRatingKFactor   = 16
+)
+
+// KFactorStrategy decides how strongly a match moves one player's rating
+// matchesPlayed counts the player's matches in the sport before this one
+type KFactorStrategy interface {
+	KFactor(elo, matchesPlayed int) int
+}
+
+// FixedKFactor uses the same K-factor for every player
+type FixedKFactor int
+
+func (k FixedKFactor) KFactor(elo, matchesPlayed int) int {
+	return int(k)
+}
+
+// TieredKFactor gives provisional players a higher K-factor so they reach their level
+// quickly, and players at or above HighRatingThreshold a lower one so top ratings settle
+// Being provisional takes precedence over a high rating
+type TieredKFactor struct {
+	Base                int
+	ProvisionalMatches  int
+	ProvisionalKFactor  int
+	HighRatingThreshold int
+	HighRatingKFactor   int
+}
+
+func (t TieredKFactor) KFactor(elo, matchesPlayed int) int {
+	switch {
+	case matchesPlayed < t.ProvisionalMatches:
+		return t.ProvisionalKFactor
+	case elo >= t.HighRatingThreshold:
+		return t.HighRatingKFactor
+	}
+	return t.Base
+}
+
+// Strategy returns the K-factor strategy configured for the sport
+func (c SportConfig) Strategy() KFactorStrategy {
+	if c.KFactorStrategy != KFactorTiered {
+		return FixedKFactor(c.KFactor)
+	}
+	return TieredKFactor{
+		Base:                c.KFactor,
+		ProvisionalMatches:  c.ProvisionalMatches,
+		ProvisionalKFactor:  c.ProvisionalKFactor,
+		HighRatingThreshold: c.HighRatingThreshold,
+		HighRatingKFactor:   c.HighRatingKFactor,
+	}
+}
+
+// SetKFactorDefaults selects the fixed strategy with the default tiers, as new sports get
+func (c *SportConfig) SetKFactorDefaults() {
+	c.KFactorStrategy = KFactorFixed
+	c.ProvisionalMatches = defaultProvisionalMatches
+	c.ProvisionalKFactor = defaultProvisionalKFactor
+	c.HighRatingThreshold = defaultHighRatingThreshold
+	c.HighRatingKFactor = defaultHighRatingKFactor
+}
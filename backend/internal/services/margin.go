@@ -0,0 +1,38 @@
+package services
+
+// defaultMaxMarginMultiplier matches the sports table
+const defaultMaxMarginMultiplier = 1.5
+
+// MarginRule scales rating changes by the margin of victory, so a clear win moves
+// more points than a narrow one; the zero value leaves them unscaled
+type MarginRule struct {
+	Enabled       bool
+	MaxMultiplier float64
+}
+
+// Multiplier maps the winner's margin, relative to the winning score, linearly onto
+// 1 for no margin up to MaxMultiplier for a shutout
+// Scores without a winner, like the 0-0 of a forfeit, give 1
+func (r MarginRule) Multiplier(player1Score, player2Score int) float64 {
+	if !r.Enabled || r.MaxMultiplier <= 1 {
+		return 1.0
+	}
+	winner, loser := player1Score, player2Score
+	if loser > winner {
+		winner, loser = loser, winner
+	}
+	if winner <= 0 || winner == loser {
+		return 1.0
+	}
+	// Capped, as a negative losing score would otherwise push the margin past a shutout
+	margin := float64(winner-loser) / float64(winner)
+	if margin > 1 {
+		margin = 1
+	}
+	return 1.0 + (r.MaxMultiplier-1.0)*margin
+}
+
+// MarginRule returns the margin-of-victory rule configured for the sport
+func (c SportConfig) MarginRule() MarginRule {
+	return MarginRule{Enabled: c.MarginOfVictory, MaxMultiplier: c.MaxMarginMultiplier}
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// MatchImportMaxRows bounds how many matches one CSV import may contain
+const MatchImportMaxRows = 10000
+
+// ErrInvalidMatchCSV is returned when an import file cannot be read as a whole
+var ErrInvalidMatchCSV = errors.New("invalid CSV")
+
+// importContext marks imported matches, which were never submitted through the app
+const importContext = "import"
+
+// importRequiredColumns are the columns of the match CSV export an import cannot do without
+// WinnerID, Status, SubmittedBy, ConfirmedAt, DeniedAt and UpdatedAt are read if present;
+// ID and the ELO figures are ignored since IDs are assigned and ELO is replayed
+var importRequiredColumns = []string{"Sport", "Player1ID", "Player2ID", "Player1Score", "Player2Score", "CreatedAt"}
+
+// importTimeLayouts are the timestamp formats accepted besides the RFC 3339 of the export
+// They are read as UTC
+var importTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// MatchImportService backfills historical matches from a CSV file
+type MatchImportService struct {
+	db             *sql.DB
+	matchRepo      repositories.MatchStore
+	userRepo       repositories.UserStore
+	userSportsRepo repositories.UserSportsStore
+	sportService   *SportService
+	matchService   *MatchService
+	recompute      *RecomputeService
+}
+
+// NewMatchImportService creates a new MatchImportService instance
+func NewMatchImportService(
+	db *sql.DB,
+	matchRepo repositories.MatchStore,
+	userRepo repositories.UserStore,
+	userSportsRepo repositories.UserSportsStore,
+	sportService *SportService,
+	matchService *MatchService,
+	recompute *RecomputeService,
+) *MatchImportService {
+	return &MatchImportService{
+		db:             db,
+		matchRepo:      matchRepo,
+		userRepo:       userRepo,
+		userSportsRepo: userSportsRepo,
+		sportService:   sportService,
+		matchService:   matchService,
+		recompute:      recompute,
+	}
+}
+
+// importRow is a parsed CSV row whose players are not resolved yet
+type importRow struct {
+	line                int
+	match               models.Match
+	player1, player2    string
+	winner, submittedBy string
+}
+
+// Import reads matches in the column layout of the admin CSV export and inserts the valid
+// ones in one transaction, after which all ratings are replayed in chronological order
+// Player columns hold a login or a user ID. Rows that fail validation or duplicate an
+// existing match are skipped and reported; with dryRun nothing is kept
+// Problems with the file as a whole (header, size, syntax) fail with ErrInvalidMatchCSV
+func (s *MatchImportService) Import(ctx context.Context, r io.Reader, dryRun bool) (*models.MatchImportReport, error) {
+	ctx, span := tracer.Start(ctx, "MatchImportService.Import")
+	defer span.End()
+
+	// An import touches thousands of rows and replays all ratings; the request query timeout
+	// would abort it halfway, so only the request itself bounds it
+	ctx = repositories.WithoutQueryTimeout(ctx)
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidMatchCSV)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMatchCSV, err)
+	}
+	// Column names are matched case-insensitively; spreadsheet apps may prepend a BOM
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("%w: missing the %s column", ErrInvalidMatchCSV, name)
+		}
+	}
+
+	report := &models.MatchImportReport{DryRun: dryRun, Errors: []models.MatchImportError{}}
+	fail := func(line int, format string, args ...interface{}) {
+		report.Errors = append(report.Errors, models.MatchImportError{Line: line, Error: fmt.Sprintf(format, args...)})
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidMatchCSV, parseErr.Line, parseErr.Err)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		report.Rows++
+		if report.Rows > MatchImportMaxRows {
+			return nil, fmt.Errorf("%w: more than %d matches", ErrInvalidMatchCSV, MatchImportMaxRows)
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[strings.ToLower(name)]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row, err := s.parseRow(field)
+		if err != nil {
+			fail(line, "%v", err)
+			continue
+		}
+		row.line = line
+		rows = append(rows, *row)
+	}
+
+	players, err := s.resolvePlayers(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate against the players and drop duplicates within the file
+	valid := rows[:0]
+	seen := make(map[string]int)
+	for _, row := range rows {
+		if err := resolveRow(&row, players); err != nil {
+			fail(row.line, "%v", err)
+			continue
+		}
+		m := &row.match
+		key := fmt.Sprintf("%s|%d|%d|%d|%d|%d", m.Sport, m.Player1ID, m.Player2ID, m.Player1Score, m.Player2Score, m.CreatedAt.UnixNano())
+		if first, ok := seen[key]; ok {
+			fail(row.line, "duplicate of line %d", first)
+			continue
+		}
+		seen[key] = row.line
+		valid = append(valid, row)
+	}
+	if len(valid) == 0 {
+		return report, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var playerIDs []int
+	for _, row := range valid {
+		m := row.match
+		existing, err := s.matchRepo.FindDuplicate(ctx, tx, &m)
+		if err != nil {
+			return nil, err
+		}
+		if existing != 0 {
+			fail(row.line, "match already exists (ID %d)", existing)
+			continue
+		}
+
+		for _, userID := range []int{m.Player1ID, m.Player2ID} {
+			if err := s.userSportsRepo.EnsureUserSportExists(ctx, tx, userID, m.Sport, s.sportService.GetDefaultELO(m.Sport)); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.matchRepo.Import(ctx, tx, &m); err != nil {
+			return nil, fmt.Errorf("failed to import line %d: %w", row.line, err)
+		}
+		playerIDs = append(playerIDs, m.Player1ID, m.Player2ID)
+		report.Imported++
+	}
+	if report.Imported == 0 {
+		return report, nil
+	}
+
+	// Ratings depend on the order of all matches, so the imported ones are replayed with the rest
+	report.Replay, err = s.recompute.replay(ctx, tx, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.userSportsRepo.ReconcileStats(ctx, tx, playerIDs); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	if err := s.matchService.RefreshRankings(ctx, tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.matchService.LeaderboardChanged()
+	slog.Info("Matches imported", "rows", report.Rows, "imported", report.Imported, "ratings_changed", len(report.Replay.Changes))
+
+	return report, nil
+}
+
+// parseRow reads the fields of one CSV row that do not need the database
+func (s *MatchImportService) parseRow(field func(string) string) (*importRow, error) {
+	row := &importRow{
+		player1:     field("Player1ID"),
+		player2:     field("Player2ID"),
+		winner:      field("WinnerID"),
+		submittedBy: field("SubmittedBy"),
+	}
+	m := &row.match
+	m.Context = importContext
+	m.Result = models.ResultPlayed
+
+	m.Sport = field("Sport")
+	sport, err := s.sportService.GetSport(m.Sport)
+	if err != nil {
+		return nil, err
+	}
+	if row.player1 == "" || row.player2 == "" {
+		return nil, fmt.Errorf("both players are required")
+	}
+
+	if m.Player1Score, err = parseImportScore(field("Player1Score"), sport); err != nil {
+		return nil, fmt.Errorf("Player1Score: %w", err)
+	}
+	if m.Player2Score, err = parseImportScore(field("Player2Score"), sport); err != nil {
+		return nil, fmt.Errorf("Player2Score: %w", err)
+	}
+	if m.Player1Score == m.Player2Score {
+		return nil, fmt.Errorf("scores cannot be equal - someone must win")
+	}
+
+	m.Status = strings.ToLower(field("Status"))
+	switch m.Status {
+	case "":
+		m.Status = models.StatusConfirmed
+	case models.StatusConfirmed, models.StatusDenied, models.StatusCancelled:
+	default:
+		return nil, fmt.Errorf("status must be %s, %s or %s", models.StatusConfirmed, models.StatusDenied, models.StatusCancelled)
+	}
+
+	createdAt, err := parseImportTime(field("CreatedAt"))
+	if err != nil || createdAt == nil {
+		return nil, fmt.Errorf("CreatedAt must be a date or timestamp")
+	}
+	if createdAt.After(time.Now()) {
+		return nil, fmt.Errorf("CreatedAt is in the future")
+	}
+	m.CreatedAt = *createdAt
+
+	if m.ConfirmedAt, err = parseImportTime(field("ConfirmedAt")); err != nil {
+		return nil, fmt.Errorf("ConfirmedAt: %w", err)
+	}
+	if m.DeniedAt, err = parseImportTime(field("DeniedAt")); err != nil {
+		return nil, fmt.Errorf("DeniedAt: %w", err)
+	}
+	switch m.Status {
+	case models.StatusConfirmed:
+		if m.ConfirmedAt == nil {
+			m.ConfirmedAt = createdAt
+		}
+		m.DeniedAt = nil
+	case models.StatusDenied:
+		if m.DeniedAt == nil {
+			m.DeniedAt = createdAt
+		}
+		m.ConfirmedAt = nil
+	default:
+		m.ConfirmedAt, m.DeniedAt = nil, nil
+	}
+
+	updatedAt, err := parseImportTime(field("UpdatedAt"))
+	if err != nil {
+		return nil, fmt.Errorf("UpdatedAt: %w", err)
+	}
+	m.UpdatedAt = m.CreatedAt
+	for _, t := range []*time.Time{m.ConfirmedAt, m.DeniedAt, updatedAt} {
+		if t != nil && t.After(m.UpdatedAt) {
+			m.UpdatedAt = *t
+		}
+	}
+
+	return row, nil
+}
+
+// resolvePlayers looks up every login and user ID referenced by the rows
+// The returned map is keyed by the reference as written in the file
+func (s *MatchImportService) resolvePlayers(ctx context.Context, rows []importRow) (map[string]int, error) {
+	var logins []string
+	var ids []int
+	for _, row := range rows {
+		for _, ref := range []string{row.player1, row.player2, row.winner, row.submittedBy} {
+			if ref == "" {
+				continue
+			}
+			if id, err := strconv.Atoi(ref); err == nil {
+				ids = append(ids, id)
+			} else {
+				logins = append(logins, ref)
+			}
+		}
+	}
+
+	players := make(map[string]int)
+	if len(logins) > 0 {
+		users, err := s.userRepo.GetByLogins(ctx, logins)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			players[u.Login] = u.ID
+		}
+	}
+	if len(ids) > 0 {
+		users, err := s.userRepo.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			players[strconv.Itoa(u.ID)] = u.ID
+		}
+	}
+	return players, nil
+}
+
+// resolveRow fills in the player IDs of a row and checks the winner against the scores
+func resolveRow(row *importRow, players map[string]int) error {
+	m := &row.match
+	for _, p := range []struct {
+		ref string
+		id  *int
+	}{
+		{row.player1, &m.Player1ID},
+		{row.player2, &m.Player2ID},
+	} {
+		id, ok := players[p.ref]
+		// Negative IDs belong to the placeholder of deleted accounts
+		if !ok || id <= 0 {
+			return fmt.Errorf("unknown player %q", p.ref)
+		}
+		*p.id = id
+	}
+	if m.Player1ID == m.Player2ID {
+		return fmt.Errorf("a player cannot play against themselves")
+	}
+
+	m.WinnerID = m.Player2ID
+	if m.Player1Score > m.Player2Score {
+		m.WinnerID = m.Player1ID
+	}
+	if row.winner != "" && players[row.winner] != m.WinnerID {
+		return fmt.Errorf("winner %q does not match the scores", row.winner)
+	}
+
+	m.SubmittedBy = m.Player1ID
+	if row.submittedBy != "" {
+		id := players[row.submittedBy]
+		if id != m.Player1ID && id != m.Player2ID {
+			return fmt.Errorf("submitter %q is not one of the players", row.submittedBy)
+		}
+		m.SubmittedBy = id
+	}
+	return nil
+}
+
+// parseImportScore reads a score and checks it against the score range of the sport
+func parseImportScore(value string, sport *Sport) (int, error) {
+	score, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("must be a whole number")
+	}
+	if score < sport.MinScore || score > sport.MaxScore {
+		return 0, fmt.Errorf("must be between %d and %d under the %s rules", sport.MinScore, sport.MaxScore, sport.DisplayName)
+	}
+	return score, nil
+}
+
+// parseImportTime parses an optional timestamp; an empty value is nil
+func parseImportTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid timestamp %q", value)
+}
@@ -3,48 +3,117 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/42heilbronn/elo-leaderboard/internal/cache"
 	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/realtime"
 	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Cache TTL for leaderboard data
-const leaderboardCacheTTL = 5 * time.Minute
+// ErrOpponentDeactivated is returned for matches and challenges against a deactivated player
+var ErrOpponentDeactivated = errors.New("opponent has deactivated their account")
 
 type MatchService struct {
 	db             *sql.DB
-	matchRepo      *repositories.MatchRepository
-	userRepo       *repositories.UserRepository
-	userSportsRepo *repositories.UserSportsRepository
+	matchRepo      repositories.MatchStore
+	userRepo       repositories.UserStore
+	userSportsRepo repositories.UserSportsStore
+	eloHistoryRepo repositories.ELOHistoryStore
+	snapshotRepo   repositories.SnapshotStore
+	rankingRepo    repositories.RankingStore
 	sportService   *SportService
 	eloService     *ELOService
-	cache          *cache.Cache
+	cache          cache.Cache
+	activity       *ActivityMonitor // picks the leaderboard cache TTL
+	events         EventPublisher
 }
 
 func NewMatchService(
 	db *sql.DB,
-	matchRepo *repositories.MatchRepository,
-	userRepo *repositories.UserRepository,
-	userSportsRepo *repositories.UserSportsRepository,
+	matchRepo repositories.MatchStore,
+	userRepo repositories.UserStore,
+	userSportsRepo repositories.UserSportsStore,
+	eloHistoryRepo repositories.ELOHistoryStore,
+	snapshotRepo repositories.SnapshotStore,
+	rankingRepo repositories.RankingStore,
 	sportService *SportService,
 	eloService *ELOService,
+	leaderboardCache cache.Cache,
+	activity *ActivityMonitor,
 ) *MatchService {
 	return &MatchService{
 		db:             db,
 		matchRepo:      matchRepo,
 		userRepo:       userRepo,
 		userSportsRepo: userSportsRepo,
+		eloHistoryRepo: eloHistoryRepo,
+		snapshotRepo:   snapshotRepo,
+		rankingRepo:    rankingRepo,
 		sportService:   sportService,
 		eloService:     eloService,
-		cache:          cache.NewCache(leaderboardCacheTTL, 1*time.Minute),
+		cache:          leaderboardCache,
+		activity:       activity,
+	}
+}
+
+// SetEventPublisher enables realtime events for match lifecycle and leaderboard changes
+func (s *MatchService) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// publishMatchEvent broadcasts a match lifecycle event if a publisher is configured
+func (s *MatchService) publishMatchEvent(eventType string, match *models.Match) {
+	if s.events == nil {
+		return
+	}
+
+	s.events.Publish(realtime.GlobalChannel, eventType, MatchEvent{
+		MatchID:   match.ID,
+		Sport:     match.Sport,
+		Player1ID: match.Player1ID,
+		Player2ID: match.Player2ID,
+	})
+
+	if eventType == EventMatchConfirmed || eventType == EventMatchCorrected {
+		s.publishLeaderboardUpdated(match.Sport)
+	}
+}
+
+// publishLeaderboardUpdated broadcasts a leaderboard update for each of the given sports, or for
+// every active sport when none are given, if a publisher is configured
+func (s *MatchService) publishLeaderboardUpdated(sports ...string) {
+	if s.events == nil {
+		return
+	}
+
+	if len(sports) == 0 {
+		active, err := s.sportService.GetAllActiveSports()
+		if err != nil {
+			slog.Warn("Failed to load sports for leaderboard update", "error", err)
+			return
+		}
+		for _, sport := range active {
+			sports = append(sports, sport.ID)
+		}
+	}
+	for _, sport := range sports {
+		s.events.Publish(realtime.GlobalChannel, EventLeaderboardUpdated, map[string]string{"sport": sport})
 	}
 }
 
 // SubmitMatch creates a new pending match
-func (s *MatchService) SubmitMatch(req *models.SubmitMatchRequest, submitterID int) (*models.Match, error) {
+// fingerprint is the hashed client fingerprint of the submitter, stored for admin anomaly review
+func (s *MatchService) SubmitMatch(ctx context.Context, req *models.SubmitMatchRequest, submitterID int, fingerprint string) (*models.Match, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.SubmitMatch")
+	defer span.End()
+
 	// Validate: cannot play against yourself
 	if req.OpponentID == submitterID {
 		return nil, fmt.Errorf("cannot submit a match against yourself")
@@ -55,28 +124,31 @@ func (s *MatchService) SubmitMatch(req *models.SubmitMatchRequest, submitterID i
 		return nil, fmt.Errorf("match cannot end in a tie")
 	}
 
+	// Check the sport exists and the scores fit its rules
+	sport, err := s.validateSport(req.Sport)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateSportScores(utils.ScoreRange{Sport: sport.DisplayName, Min: sport.MinScore, Max: sport.MaxScore}, req); err != nil {
+		return nil, err
+	}
+
 	// Check opponent exists
-	opponent, err := s.userRepo.GetByID(req.OpponentID)
+	opponent, err := s.userRepo.GetByID(ctx, req.OpponentID)
 	if err != nil {
 		return nil, fmt.Errorf("opponent not found")
 	}
+	if opponent.DeactivatedAt != nil {
+		return nil, ErrOpponentDeactivated
+	}
 
-	// Check for existing pending match
-	existingMatch, err := s.matchRepo.GetPendingMatchBetweenPlayers(submitterID, req.OpponentID, req.Sport)
-	if err != nil {
+	// Check pending match rules of the sport
+	if err := s.checkPendingRules(ctx, submitterID, req.OpponentID, req.Sport); err != nil {
 		return nil, err
 	}
-	if existingMatch != nil {
-		return nil, fmt.Errorf("a pending match already exists between these players for this sport")
-	}
 
 	// Determine winner
-	var winnerID int
-	if req.PlayerScore > req.OpponentScore {
-		winnerID = submitterID
-	} else {
-		winnerID = req.OpponentID
-	}
+	winnerID := utils.ExpectedWinner(submitterID, req.OpponentID, req.PlayerScore, req.OpponentScore)
 
 	// Create match
 	match := &models.Match{
@@ -90,20 +162,212 @@ func (s *MatchService) SubmitMatch(req *models.SubmitMatchRequest, submitterID i
 		SubmittedBy:  submitterID,
 		Context:      req.Context,
 	}
+	if fingerprint != "" {
+		match.SubmitFingerprint = &fingerprint
+	}
 
-	if err := s.matchRepo.Create(nil, match); err != nil {
+	if len(req.Sets) > 0 {
+		if err := s.createSeries(ctx, match, req.Sets); err != nil {
+			return nil, err
+		}
+	} else if err := s.matchRepo.Create(ctx, nil, match); err != nil {
 		return nil, err
 	}
 
 	_ = opponent // Suppress unused warning
 
+	s.publishMatchEvent(EventMatchPending, match)
+
 	return match, nil
 }
 
+// validateSport returns the configuration of a sport if it is configured and active
+func (s *MatchService) validateSport(sportID string) (*Sport, error) {
+	sport, err := s.sportService.GetSport(sportID)
+	if err != nil {
+		return nil, &utils.InputValidationError{Field: "sport", Message: "is not an active sport"}
+	}
+	return sport, nil
+}
+
+// scoreRange returns the score range of a sport, also of a deactivated one whose matches are
+// still being answered or corrected
+func (s *MatchService) scoreRange(sportID string) (utils.ScoreRange, error) {
+	config, err := s.sportService.GetSportConfig(sportID)
+	if err != nil {
+		return utils.ScoreRange{}, err
+	}
+	return utils.ScoreRange{Sport: config.DisplayName, Min: config.MinScore, Max: config.MaxScore}, nil
+}
+
+// createSeries stores a best-of-N series together with its sets
+// The sets are given from the submitter's perspective, who is always player 1
+func (s *MatchService) createSeries(ctx context.Context, match *models.Match, sets []models.SetScore) error {
+	match.Sets = make([]models.MatchSet, len(sets))
+	for i, set := range sets {
+		match.Sets[i] = models.MatchSet{
+			SetNumber:    i + 1,
+			Player1Score: set.PlayerScore,
+			Player2Score: set.OpponentScore,
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.matchRepo.Create(ctx, tx, match); err != nil {
+		return err
+	}
+	if err := s.matchRepo.CreateSets(ctx, tx, match.ID, match.Sets); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SubmitForfeit creates a pending forfeit the opponent has to confirm like a match
+// The submitter either concedes or reports that the opponent did not show up
+func (s *MatchService) SubmitForfeit(ctx context.Context, req *models.SubmitForfeitRequest, submitterID int, fingerprint string) (*models.Match, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.SubmitForfeit")
+	defer span.End()
+
+	if req.OpponentID == submitterID {
+		return nil, fmt.Errorf("cannot submit a match against yourself")
+	}
+
+	if _, err := s.validateSport(req.Sport); err != nil {
+		return nil, err
+	}
+
+	opponent, err := s.userRepo.GetByID(ctx, req.OpponentID)
+	if err != nil {
+		return nil, fmt.Errorf("opponent not found")
+	}
+	if opponent.DeactivatedAt != nil {
+		return nil, ErrOpponentDeactivated
+	}
+
+	if err := s.checkPendingRules(ctx, submitterID, req.OpponentID, req.Sport); err != nil {
+		return nil, err
+	}
+
+	winnerID, forfeitedBy := submitterID, req.OpponentID
+	if req.Conceded {
+		winnerID, forfeitedBy = req.OpponentID, submitterID
+	}
+
+	match := &models.Match{
+		Sport:       req.Sport,
+		Player1ID:   submitterID,
+		Player2ID:   req.OpponentID,
+		WinnerID:    winnerID,
+		Status:      models.StatusPending,
+		SubmittedBy: submitterID,
+		Context:     req.Context,
+		Result:      models.ResultForfeit,
+		ForfeitedBy: &forfeitedBy,
+	}
+	if fingerprint != "" {
+		match.SubmitFingerprint = &fingerprint
+	}
+
+	if err := s.matchRepo.Create(ctx, nil, match); err != nil {
+		return nil, err
+	}
+
+	s.publishMatchEvent(EventMatchPending, match)
+
+	return match, nil
+}
+
+// RecordForfeit records a forfeit on behalf of both players and applies it right away
+// Used by organizers (admins) who saw the no-show themselves
+func (s *MatchService) RecordForfeit(ctx context.Context, req *models.RecordForfeitRequest, organizerID int) (*models.Match, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.RecordForfeit")
+	defer span.End()
+
+	if req.WinnerID == req.ForfeitedBy {
+		return nil, fmt.Errorf("cannot submit a match against yourself")
+	}
+
+	if _, err := s.validateSport(req.Sport); err != nil {
+		return nil, err
+	}
+
+	for _, playerID := range []int{req.WinnerID, req.ForfeitedBy} {
+		if _, err := s.userRepo.GetByID(ctx, playerID); err != nil {
+			return nil, fmt.Errorf("player not found")
+		}
+	}
+
+	forfeitedBy := req.ForfeitedBy
+	match := &models.Match{
+		Sport:       req.Sport,
+		Player1ID:   req.WinnerID,
+		Player2ID:   req.ForfeitedBy,
+		WinnerID:    req.WinnerID,
+		Status:      models.StatusPending,
+		SubmittedBy: organizerID,
+		Context:     req.Context,
+		Result:      models.ResultForfeit,
+		ForfeitedBy: &forfeitedBy,
+	}
+
+	err := s.applyConfirmation(ctx, match, "", func(tx *sql.Tx) error {
+		return s.matchRepo.Create(ctx, tx, match)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.matchRepo.GetByID(ctx, match.ID)
+}
+
+// checkPendingRules enforces the sport's pending match rules for a pair of players
+// Strict sports allow one pending match per pair; multiple-mode sports allow several
+// back-to-back games as long as they are spaced out and below the per-pair cap
+func (s *MatchService) checkPendingRules(ctx context.Context, submitterID, opponentID int, sportID string) error {
+	sport, err := s.sportService.GetSport(sportID)
+	if err != nil || sport.PendingMode != PendingModeMultiple {
+		existingMatch, err := s.matchRepo.GetPendingMatchBetweenPlayers(ctx, submitterID, opponentID, sportID)
+		if err != nil {
+			return err
+		}
+		if existingMatch != nil {
+			return fmt.Errorf("a pending match already exists between these players for this sport")
+		}
+		return nil
+	}
+
+	count, latest, err := s.matchRepo.GetPendingStatsBetweenPlayers(ctx, submitterID, opponentID, sportID)
+	if err != nil {
+		return err
+	}
+	if count >= sport.MaxPendingPerPair {
+		return fmt.Errorf("too many pending matches between these players for this sport (max %d)", sport.MaxPendingPerPair)
+	}
+
+	// Distinct timestamps guard against accidental double submissions of the same game
+	minInterval := time.Duration(sport.PendingMinIntervalSeconds) * time.Second
+	if latest != nil && time.Since(*latest) < minInterval {
+		wait := (minInterval - time.Since(*latest)).Round(time.Second)
+		return fmt.Errorf("a match between these players was just submitted, please wait %s before submitting the next one", wait)
+	}
+
+	return nil
+}
+
 // ConfirmMatch confirms a pending match and updates ELO ratings
-func (s *MatchService) ConfirmMatch(matchID, userID int) error {
+// fingerprint is the hashed client fingerprint of the confirming user
+func (s *MatchService) ConfirmMatch(ctx context.Context, matchID, userID int, fingerprint string) error {
+	ctx, span := tracer.Start(ctx, "MatchService.ConfirmMatch", trace.WithAttributes(attribute.Int("match.id", matchID), attribute.Int("user.id", userID)))
+	defer span.End()
+
 	// Get the match
-	match, err := s.matchRepo.GetByID(matchID)
+	match, err := s.matchRepo.GetByID(ctx, matchID)
 	if err != nil {
 		return err
 	}
@@ -123,57 +387,76 @@ func (s *MatchService) ConfirmMatch(matchID, userID int) error {
 		return fmt.Errorf("you are not part of this match")
 	}
 
-	// Get current ELO ratings from user_sports table (generic for any sport)
-	player1ELO, err := s.userSportsRepo.GetUserELO(match.Player1ID, match.Sport)
-	if err != nil {
-		return fmt.Errorf("failed to get player1 ELO: %w", err)
-	}
+	return s.applyConfirmation(ctx, match, fingerprint, nil)
+}
 
-	player2ELO, err := s.userSportsRepo.GetUserELO(match.Player2ID, match.Sport)
-	if err != nil {
-		return fmt.Errorf("failed to get player2 ELO: %w", err)
+// applyConfirmation confirms a match and updates ELO ratings in one transaction
+// prepare runs inside the transaction before the ELO update, e.g. to correct the scores
+func (s *MatchService) applyConfirmation(ctx context.Context, match *models.Match, fingerprint string, prepare func(tx *sql.Tx) error) error {
+	// Refuse to apply ELO for a winner that contradicts the scores (e.g. after a bad admin edit)
+	// Forfeits have no scores; the database guarantees the forfeiting player didn't win
+	forfeit := match.Result == models.ResultForfeit
+	if !forfeit {
+		if err := utils.ValidateWinner(match.Player1ID, match.Player2ID, match.Player1Score, match.Player2Score, match.WinnerID); err != nil {
+			return fmt.Errorf("match data is inconsistent, contact an admin: %w", err)
+		}
+	}
+	calculate := s.eloService.CalculateELO
+	if forfeit {
+		calculate = s.eloService.CalculateForfeitELO
 	}
 
-	// Calculate new ELO ratings
-	player1Won := match.WinnerID == match.Player1ID
-	player1NewELO, player2NewELO, player1Delta, player2Delta := s.eloService.CalculateELO(
-		player1ELO,
-		player2ELO,
-		player1Won,
-	)
-
-	// Start transaction with SERIALIZABLE isolation level to prevent race conditions
-	// This ensures that concurrent ELO updates don't interfere with each other
-	ctx := context.Background()
-	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelSerializable,
-	})
+	// READ COMMITTED: concurrent confirmations are kept apart by the player locks below, the
+	// match is only confirmed if it still has the status read above, and the ranking step
+	// sees the rankings committed before it got the sport's lock. A SERIALIZABLE snapshot
+	// would be taken before that lock and fail on every concurrent confirmation in the sport
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Re-fetch ELO values within transaction to ensure consistency
-	// This is necessary because the ELO might have changed between our initial read and now
-	player1CurrentELO, err := s.userSportsRepo.GetUserELOForUpdate(tx, match.Player1ID, match.Sport)
+	if prepare != nil {
+		if err := prepare(tx); err != nil {
+			return err
+		}
+	}
+
+	// Lock both players before reading their ratings, so a concurrent confirmation
+	// involving either of them waits instead of computing from the same values
+	// The users row always exists, unlike the user_sports row of a first match
+	// Locking in ID order keeps two confirmations of the same pair from deadlocking
+	firstID, secondID := match.Player1ID, match.Player2ID
+	if secondID < firstID {
+		firstID, secondID = secondID, firstID
+	}
+	for _, id := range []int{firstID, secondID} {
+		if _, err := s.userRepo.GetByIDForUpdate(ctx, tx, id); err != nil {
+			return fmt.Errorf("failed to lock player %d: %w", id, err)
+		}
+		// A player's first match in a sport starts from the sport's default rating
+		if err := s.userSportsRepo.EnsureUserSportExists(ctx, tx, id, match.Sport, s.sportService.GetDefaultELO(match.Sport)); err != nil {
+			return err
+		}
+	}
+
+	// Get current ELO ratings from user_sports table (generic for any sport)
+	player1ELO, player1Played, err := s.userSportsRepo.GetRatingForUpdate(ctx, tx, match.Player1ID, match.Sport)
 	if err != nil {
-		return fmt.Errorf("failed to lock player1: %w", err)
+		return fmt.Errorf("failed to get player1 ELO: %w", err)
 	}
-	player2CurrentELO, err := s.userSportsRepo.GetUserELOForUpdate(tx, match.Player2ID, match.Sport)
+	player2ELO, player2Played, err := s.userSportsRepo.GetRatingForUpdate(ctx, tx, match.Player2ID, match.Sport)
 	if err != nil {
-		return fmt.Errorf("failed to lock player2: %w", err)
+		return fmt.Errorf("failed to get player2 ELO: %w", err)
 	}
 
-	// If ELO changed between reads, recalculate
-	if player1CurrentELO != player1ELO || player2CurrentELO != player2ELO {
-		player1ELO = player1CurrentELO
-		player2ELO = player2CurrentELO
-		player1NewELO, player2NewELO, player1Delta, player2Delta = s.eloService.CalculateELO(
-			player1ELO,
-			player2ELO,
-			player1Won,
-		)
-	}
+	// Calculate new ELO ratings from the locked values, with the K-factors the sport's
+	// strategy gives each player, weighted by the margin of victory if the sport uses it
+	// and clamped to the sport's rating bounds
+	player1 := ELOPlayer{ELO: player1ELO, KFactor: s.eloService.KFactor(match.Sport, player1ELO, player1Played), Score: match.Player1Score}
+	player2 := ELOPlayer{ELO: player2ELO, KFactor: s.eloService.KFactor(match.Sport, player2ELO, player2Played), Score: match.Player2Score}
+	player1Won := match.WinnerID == match.Player1ID
+	player1NewELO, player2NewELO, player1Delta, player2Delta := calculate(player1, player2, player1Won, s.eloService.Rules(match.Sport))
 
 	// Update match with ELO data
 	eloData := map[string]int{
@@ -183,45 +466,280 @@ func (s *MatchService) ConfirmMatch(matchID, userID int) error {
 		"player2_before": player2ELO,
 		"player2_after":  player2NewELO,
 		"player2_delta":  player2Delta,
+		"player1_k":      player1.KFactor,
+		"player2_k":      player2.KFactor,
+	}
+
+	var confirmFingerprint *string
+	if fingerprint != "" {
+		confirmFingerprint = &fingerprint
 	}
 
-	if err := s.matchRepo.ConfirmMatch(tx, matchID, eloData); err != nil {
+	if err := s.matchRepo.ConfirmMatch(ctx, tx, match.ID, match.Status, eloData, confirmFingerprint); err != nil {
 		return err
 	}
 
 	// Update user ELO ratings in user_sports table
-	if err := s.userSportsRepo.UpdateUserELO(tx, match.Player1ID, match.Sport, player1NewELO); err != nil {
+	if err := s.userSportsRepo.UpdateUserELO(ctx, tx, match.Player1ID, match.Sport, player1NewELO); err != nil {
 		return err
 	}
 
-	if err := s.userSportsRepo.UpdateUserELO(tx, match.Player2ID, match.Sport, player2NewELO); err != nil {
+	if err := s.userSportsRepo.UpdateUserELO(ctx, tx, match.Player2ID, match.Sport, player2NewELO); err != nil {
 		return err
 	}
 
+	// Log both rating changes for the ELO history graphs
+	matchID := match.ID
+	for _, change := range []models.ELOHistoryEntry{
+		{UserID: match.Player1ID, ELOBefore: player1ELO, ELOAfter: player1NewELO},
+		{UserID: match.Player2ID, ELOBefore: player2ELO, ELOAfter: player2NewELO},
+	} {
+		change.Sport = match.Sport
+		change.Source = models.ELOSourceMatch
+		if forfeit {
+			change.Source = models.ELOSourceForfeit
+		}
+		change.MatchID = &matchID
+		if err := s.eloHistoryRepo.Record(ctx, tx, &change); err != nil {
+			return err
+		}
+	}
+
 	// Update match statistics
-	if err := s.userSportsRepo.IncrementMatchStats(tx, match.Player1ID, match.Sport, player1Won); err != nil {
+	if err := s.userSportsRepo.IncrementMatchStats(ctx, tx, match.Player1ID, match.Sport, player1Won); err != nil {
 		return fmt.Errorf("failed to update player1 stats: %w", err)
 	}
 
-	if err := s.userSportsRepo.IncrementMatchStats(tx, match.Player2ID, match.Sport, !player1Won); err != nil {
+	if err := s.userSportsRepo.IncrementMatchStats(ctx, tx, match.Player2ID, match.Sport, !player1Won); err != nil {
 		return fmt.Errorf("failed to update player2 stats: %w", err)
 	}
 
+	if _, err := s.rankingRepo.RankPlayers(ctx, tx, []int{match.Player1ID, match.Player2ID}, match.Sport); err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		return err
 	}
 
 	// Invalidate leaderboard cache since ELO changed
-	s.InvalidateLeaderboardCache()
+	s.InvalidateSportLeaderboard(match.Sport)
+
+	s.publishMatchEvent(EventMatchConfirmed, match)
 
 	return nil
 }
 
+// CorrectMatch corrects the scores or winner of a confirmed match as admin
+// The ELO deltas are recomputed from the ratings both players had before the match and
+// the difference to the old deltas is applied to their current ratings; matches played
+// since keep their deltas. Returns the match before and after the correction
+func (s *MatchService) CorrectMatch(ctx context.Context, matchID int, req *models.EditMatchRequest) (*models.Match, *models.Match, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.CorrectMatch", trace.WithAttributes(attribute.Int("match.id", matchID)))
+	defer span.End()
+
+	before, err := s.matchRepo.GetByID(ctx, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if before.Status != models.StatusConfirmed {
+		return nil, nil, fmt.Errorf("only confirmed matches can be corrected")
+	}
+	if before.Player1ELOBefore == nil || before.Player2ELOBefore == nil ||
+		before.Player1ELODelta == nil || before.Player2ELODelta == nil {
+		return nil, nil, fmt.Errorf("match has no ELO data to recalculate")
+	}
+
+	after := *before
+	forfeit := before.Result == models.ResultForfeit
+	if forfeit {
+		if req.Player1Score != nil || req.Player2Score != nil {
+			return nil, nil, fmt.Errorf("forfeits have no scores")
+		}
+		if req.WinnerID != nil {
+			if *req.WinnerID != before.Player1ID && *req.WinnerID != before.Player2ID {
+				return nil, nil, fmt.Errorf("winner must be one of the players")
+			}
+			loser := before.Player1ID
+			if *req.WinnerID == before.Player1ID {
+				loser = before.Player2ID
+			}
+			after.WinnerID = *req.WinnerID
+			after.ForfeitedBy = &loser
+		}
+	} else {
+		rules, err := s.scoreRange(before.Sport)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := utils.ValidateEditMatchRequest(rules, req.Player1Score, req.Player2Score); err != nil {
+			return nil, nil, err
+		}
+		if req.Player1Score != nil {
+			after.Player1Score = *req.Player1Score
+		}
+		if req.Player2Score != nil {
+			after.Player2Score = *req.Player2Score
+		}
+		if after.Player1Score == after.Player2Score {
+			return nil, nil, fmt.Errorf("scores cannot be equal - someone must win")
+		}
+		after.WinnerID = after.Player1ID
+		if after.Player2Score > after.Player1Score {
+			after.WinnerID = after.Player2ID
+		}
+		if req.WinnerID != nil && *req.WinnerID != after.WinnerID {
+			return nil, nil, fmt.Errorf("winner does not match the scores")
+		}
+	}
+
+	if after.Player1Score == before.Player1Score && after.Player2Score == before.Player2Score && after.WinnerID == before.WinnerID {
+		return nil, nil, fmt.Errorf("nothing to correct")
+	}
+
+	calculate := s.eloService.CalculateELO
+	if forfeit {
+		calculate = s.eloService.CalculateForfeitELO
+	}
+
+	// The corrected result keeps the K-factors the match was confirmed with; the margin
+	// of victory comes from the corrected scores
+	player1, player2 := s.recordedKFactors(&after)
+	rules := s.eloService.Rules(after.Sport)
+	player1After, player2After, player1Delta, player2Delta := calculate(player1, player2,
+		after.WinnerID == after.Player1ID, rules)
+	after.Player1ELOAfter, after.Player1ELODelta = &player1After, &player1Delta
+	after.Player2ELOAfter, after.Player2ELODelta = &player2After, &player2Delta
+
+	// READ COMMITTED like applyConfirmation; CorrectResult only writes if the match is still
+	// the one the new deltas were computed from, and the players are locked before their
+	// ratings are shifted
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.matchRepo.CorrectResult(ctx, tx, &after); err != nil {
+		return nil, nil, err
+	}
+	// The set breakdown of a series no longer matches the corrected score
+	if after.Player1Score != before.Player1Score || after.Player2Score != before.Player2Score {
+		if err := s.matchRepo.DeleteSets(ctx, tx, matchID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Shift the current ratings by the difference between the new and the old delta,
+	// staying within the rating bounds
+	for _, player := range []struct {
+		userID   int
+		oldDelta int
+		newDelta int
+	}{
+		{before.Player1ID, *before.Player1ELODelta, player1Delta},
+		{before.Player2ID, *before.Player2ELODelta, player2Delta},
+	} {
+		if player.newDelta == player.oldDelta {
+			continue
+		}
+		current, err := s.userSportsRepo.GetUserELOForUpdate(ctx, tx, player.userID, before.Sport)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to lock player %d: %w", player.userID, err)
+		}
+		corrected := rules.Bounds.Clamp(current - player.oldDelta + player.newDelta)
+		if err := s.userSportsRepo.UpdateUserELO(ctx, tx, player.userID, before.Sport, corrected); err != nil {
+			return nil, nil, err
+		}
+		if err := s.eloHistoryRepo.Record(ctx, tx, &models.ELOHistoryEntry{
+			UserID:    player.userID,
+			Sport:     before.Sport,
+			ELOBefore: current,
+			ELOAfter:  corrected,
+			Source:    models.ELOSourceCorrection,
+			MatchID:   &before.ID,
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// A changed winner moves a win and a loss between the players and can break streaks
+	if after.WinnerID != before.WinnerID {
+		if _, err := s.userSportsRepo.ReconcileStats(ctx, tx, []int{before.Player1ID, before.Player2ID}); err != nil {
+			return nil, nil, fmt.Errorf("failed to recompute player stats: %w", err)
+		}
+	}
+
+	if _, err := s.rankingRepo.RankPlayers(ctx, tx, []int{before.Player1ID, before.Player2ID}, before.Sport); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	s.InvalidateSportLeaderboard(before.Sport)
+	s.publishMatchEvent(EventMatchCorrected, &after)
+
+	return before, &after, nil
+}
+
+// recordedKFactors returns both players' ratings before a match and their scores, with the
+// K-factors it was confirmed with; matches from before K-factors were recorded used the default one
+func (s *MatchService) recordedKFactors(match *models.Match) (ELOPlayer, ELOPlayer) {
+	player1 := ELOPlayer{ELO: *match.Player1ELOBefore, KFactor: s.eloService.DefaultKFactor(), Score: match.Player1Score}
+	player2 := ELOPlayer{ELO: *match.Player2ELOBefore, KFactor: s.eloService.DefaultKFactor(), Score: match.Player2Score}
+	if match.Player1KFactor != nil && match.Player2KFactor != nil {
+		player1.KFactor, player2.KFactor = *match.Player1KFactor, *match.Player2KFactor
+	}
+	return player1, player2
+}
+
+// ExplainMatchELO shows how the rating changes of a confirmed match came about
+// The math uses the recorded K-factors and the sport's current margin-of-victory rule and
+// rating bounds; the after and delta values are the ones applied at confirmation, so they
+// can differ if the forfeit factor or those rules changed since
+func (s *MatchService) ExplainMatchELO(ctx context.Context, matchID int) (*models.ELOBreakdown, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.ExplainMatchELO", trace.WithAttributes(attribute.Int("match.id", matchID)))
+	defer span.End()
+
+	match, err := s.matchRepo.GetByID(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match.Status != models.StatusConfirmed {
+		return nil, fmt.Errorf("match is not confirmed")
+	}
+	if match.Player1ELOBefore == nil || match.Player2ELOBefore == nil ||
+		match.Player1ELODelta == nil || match.Player2ELODelta == nil {
+		return nil, fmt.Errorf("match has no ELO data")
+	}
+
+	player1, player2 := s.recordedKFactors(match)
+	breakdown := s.eloService.Explain(player1, player2,
+		match.WinnerID == match.Player1ID, match.Result == models.ResultForfeit, s.eloService.Rules(match.Sport))
+	breakdown.KFactor = s.eloService.BaseKFactor(match.Sport)
+	breakdown.MatchID = match.ID
+	breakdown.Sport = match.Sport
+	breakdown.Player1.UserID = match.Player1ID
+	breakdown.Player2.UserID = match.Player2ID
+	breakdown.Player1.Delta = *match.Player1ELODelta
+	breakdown.Player2.Delta = *match.Player2ELODelta
+	breakdown.Player1.ELOAfter = *match.Player1ELOBefore + *match.Player1ELODelta
+	breakdown.Player2.ELOAfter = *match.Player2ELOBefore + *match.Player2ELODelta
+
+	return &breakdown, nil
+}
+
 // DenyMatch denies a pending match
-func (s *MatchService) DenyMatch(matchID, userID int) error {
+// counter optionally carries the corrected score from the denying player's perspective
+func (s *MatchService) DenyMatch(ctx context.Context, matchID, userID int, counter *models.DenyMatchRequest) error {
+	ctx, span := tracer.Start(ctx, "MatchService.DenyMatch", trace.WithAttributes(attribute.Int("match.id", matchID), attribute.Int("user.id", userID)))
+	defer span.End()
+
 	// Get the match
-	match, err := s.matchRepo.GetByID(matchID)
+	match, err := s.matchRepo.GetByID(ctx, matchID)
 	if err != nil {
 		return err
 	}
@@ -241,13 +759,166 @@ func (s *MatchService) DenyMatch(matchID, userID int) error {
 		return fmt.Errorf("you are not part of this match")
 	}
 
-	return s.matchRepo.DenyMatch(matchID)
+	hasCounter := counter != nil && (counter.PlayerScore != nil || counter.OpponentScore != nil)
+	if hasCounter && match.Result == models.ResultForfeit {
+		return &utils.InputValidationError{Field: "score", Message: "a forfeit has no score to correct, deny it without one"}
+	}
+
+	if !hasCounter {
+		if err := s.matchRepo.DenyMatch(ctx, nil, matchID); err != nil {
+			return err
+		}
+		s.publishMatchEvent(EventMatchDenied, match)
+		return nil
+	}
+
+	rules, err := s.scoreRange(match.Sport)
+	if err != nil {
+		return err
+	}
+	if err := utils.ValidateCounterProposal(rules, counter.PlayerScore, counter.OpponentScore); err != nil {
+		return err
+	}
+
+	// Map the denier's perspective back onto player1/player2
+	proposal := &models.CounterProposal{
+		MatchID:    matchID,
+		ProposedBy: userID,
+	}
+	if match.Player1ID == userID {
+		proposal.Player1Score, proposal.Player2Score = *counter.PlayerScore, *counter.OpponentScore
+	} else {
+		proposal.Player1Score, proposal.Player2Score = *counter.OpponentScore, *counter.PlayerScore
+	}
+
+	if proposal.Player1Score == match.Player1Score && proposal.Player2Score == match.Player2Score {
+		return &utils.InputValidationError{Field: "score", Message: "counter-proposal must differ from the submitted score"}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.matchRepo.DenyMatch(ctx, tx, matchID); err != nil {
+		return err
+	}
+	if err := s.matchRepo.CreateCounterProposal(ctx, tx, proposal); err != nil {
+		return fmt.Errorf("failed to save counter-proposal: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.publishMatchEvent(EventMatchDenied, match)
+
+	return nil
+}
+
+// GetCounterProposal returns the counter-proposal of a match
+// Only the players of the match may see it
+func (s *MatchService) GetCounterProposal(ctx context.Context, matchID, userID int) (*models.CounterProposal, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.GetCounterProposal", trace.WithAttributes(attribute.Int("match.id", matchID), attribute.Int("user.id", userID)))
+	defer span.End()
+
+	match, err := s.matchRepo.GetByID(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if match.Player1ID != userID && match.Player2ID != userID {
+		return nil, fmt.Errorf("you are not part of this match")
+	}
+
+	proposal, err := s.matchRepo.GetCounterProposal(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil {
+		return nil, fmt.Errorf("counter-proposal not found")
+	}
+
+	return proposal, nil
+}
+
+// AcceptCounterProposal confirms a denied match with the opponent's corrected score
+// Only the original submitter can accept; ELO is applied as on a normal confirmation
+func (s *MatchService) AcceptCounterProposal(ctx context.Context, matchID, userID int, fingerprint string) error {
+	ctx, span := tracer.Start(ctx, "MatchService.AcceptCounterProposal", trace.WithAttributes(attribute.Int("match.id", matchID), attribute.Int("user.id", userID)))
+	defer span.End()
+
+	match, proposal, err := s.pendingCounterProposal(ctx, matchID, userID)
+	if err != nil {
+		return err
+	}
+
+	match.Player1Score = proposal.Player1Score
+	match.Player2Score = proposal.Player2Score
+	match.WinnerID = utils.ExpectedWinner(match.Player1ID, match.Player2ID, proposal.Player1Score, proposal.Player2Score)
+
+	return s.applyConfirmation(ctx, match, fingerprint, func(tx *sql.Tx) error {
+		if err := s.matchRepo.ResolveCounterProposal(ctx, tx, matchID, models.CounterProposalAccepted); err != nil {
+			return err
+		}
+		// The set breakdown of a series no longer matches the proposed score
+		if err := s.matchRepo.DeleteSets(ctx, tx, matchID); err != nil {
+			return err
+		}
+		return s.matchRepo.UpdateScores(ctx, tx, matchID, match.Player1Score, match.Player2Score, match.WinnerID)
+	})
+}
+
+// RejectCounterProposal rejects the opponent's corrected score; the match stays denied
+func (s *MatchService) RejectCounterProposal(ctx context.Context, matchID, userID int) error {
+	ctx, span := tracer.Start(ctx, "MatchService.RejectCounterProposal", trace.WithAttributes(attribute.Int("match.id", matchID), attribute.Int("user.id", userID)))
+	defer span.End()
+
+	if _, _, err := s.pendingCounterProposal(ctx, matchID, userID); err != nil {
+		return err
+	}
+
+	return s.matchRepo.ResolveCounterProposal(ctx, nil, matchID, models.CounterProposalRejected)
+}
+
+// pendingCounterProposal loads a denied match and its open counter-proposal for the submitter
+func (s *MatchService) pendingCounterProposal(ctx context.Context, matchID, userID int) (*models.Match, *models.CounterProposal, error) {
+	match, err := s.matchRepo.GetByID(ctx, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validate: only the submitter can answer the counter-proposal
+	if match.SubmittedBy != userID {
+		return nil, nil, fmt.Errorf("only the submitter can answer the counter-proposal")
+	}
+
+	if match.Status != models.StatusDenied {
+		return nil, nil, fmt.Errorf("match is not denied")
+	}
+
+	proposal, err := s.matchRepo.GetCounterProposal(ctx, matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if proposal == nil {
+		return nil, nil, fmt.Errorf("counter-proposal not found")
+	}
+	if proposal.Status != models.CounterProposalPending {
+		return nil, nil, fmt.Errorf("counter-proposal is no longer pending")
+	}
+
+	return match, proposal, nil
 }
 
 // CancelMatch cancels a pending match (only the submitter can cancel)
-func (s *MatchService) CancelMatch(matchID, userID int) error {
+func (s *MatchService) CancelMatch(ctx context.Context, matchID, userID int) error {
+	ctx, span := tracer.Start(ctx, "MatchService.CancelMatch", trace.WithAttributes(attribute.Int("match.id", matchID), attribute.Int("user.id", userID)))
+	defer span.End()
+
 	// Get the match
-	match, err := s.matchRepo.GetByID(matchID)
+	match, err := s.matchRepo.GetByID(ctx, matchID)
 	if err != nil {
 		return err
 	}
@@ -262,86 +933,343 @@ func (s *MatchService) CancelMatch(matchID, userID int) error {
 		return fmt.Errorf("only the submitter can cancel this match")
 	}
 
-	return s.matchRepo.CancelMatch(matchID)
+	if err := s.matchRepo.CancelMatch(ctx, matchID); err != nil {
+		return err
+	}
+
+	s.publishMatchEvent(EventMatchCancelled, match)
+
+	return nil
+}
+
+// staffStatusChanges are the status changes staff may make to a match. Confirmed matches are
+// corrected or reverted instead, and a disputed match is confirmed with its ELO applied
+var staffStatusChanges = map[string][]string{
+	models.StatusPending:  {models.StatusDisputed, models.StatusDenied, models.StatusCancelled},
+	models.StatusDisputed: {models.StatusConfirmed, models.StatusDenied, models.StatusCancelled},
+	models.StatusDenied:   {models.StatusCancelled},
+}
+
+// ResolveMatchStatus changes the status of a match as staff, e.g. to hold a pending match for
+// review and confirm or deny it afterwards. Returns the match before the change
+func (s *MatchService) ResolveMatchStatus(ctx context.Context, matchID int, status string) (*models.Match, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.ResolveMatchStatus", trace.WithAttributes(attribute.Int("match.id", matchID)))
+	defer span.End()
+
+	match, err := s.matchRepo.GetByID(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, to := range staffStatusChanges[match.Status] {
+		if to == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("a %s match cannot be set to %s", match.Status, status)
+	}
+
+	if status == models.StatusConfirmed {
+		if err := s.applyConfirmation(ctx, match, "", nil); err != nil {
+			return nil, err
+		}
+		return match, nil
+	}
+
+	if err := s.matchRepo.SetStatus(ctx, matchID, match.Status, status); err != nil {
+		return nil, err
+	}
+	switch status {
+	case models.StatusDenied:
+		s.publishMatchEvent(EventMatchDenied, match)
+	case models.StatusCancelled:
+		s.publishMatchEvent(EventMatchCancelled, match)
+	}
+	return match, nil
 }
 
-// GetLeaderboard generates leaderboard for a sport
+// GetLeaderboard returns the full ranked leaderboard for a sport
 // Optimized with caching - regenerates every 5 minutes
-func (s *MatchService) GetLeaderboard(sport string) ([]models.LeaderboardEntry, error) {
-	cacheKey := "leaderboard:" + sport
+func (s *MatchService) GetLeaderboard(ctx context.Context, sport string) ([]models.LeaderboardEntry, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.GetLeaderboard", trace.WithAttributes(attribute.String("sport", sport)))
+	defer span.End()
+
+	entries, _, err := s.GetLeaderboardPage(ctx, sport, 0, 0)
+	return entries, err
+}
+
+// leaderboardPage is a cached leaderboard page with the total player count
+type leaderboardPage struct {
+	Entries []models.LeaderboardEntry `json:"entries"`
+	Total   int                       `json:"total"`
+}
+
+// GetLeaderboardPage returns one page of the ranked leaderboard and the total player count
+// limit <= 0 returns the whole leaderboard; pages are cached like the full list
+func (s *MatchService) GetLeaderboardPage(ctx context.Context, sport string, limit, offset int) ([]models.LeaderboardEntry, int, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.GetLeaderboardPage", trace.WithAttributes(attribute.String("sport", sport)))
+	defer span.End()
+
+	cacheKey := fmt.Sprintf("leaderboard:%s:%d:%d", sport, limit, offset)
 
 	// Try to get from cache first
-	if cached, found := s.cache.Get(cacheKey); found {
-		if entries, ok := cached.([]models.LeaderboardEntry); ok {
-			return entries, nil
+	var page leaderboardPage
+	if cache.GetJSON(s.cache, cacheKey, &page) {
+		return page.Entries, page.Total, nil
+	}
+
+	// Cache miss - ranked and paginated by the database
+	entries, total, err := s.matchRepo.GetLeaderboardEntries(ctx, sport, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Store in cache
+	cache.SetJSON(s.cache, cacheKey, leaderboardPage{Entries: entries, Total: total}, s.activity.TTL(LeaderboardTTL))
+
+	return entries, total, nil
+}
+
+// GetUnrankedPlayers returns one page of the players still in the sport's placement matches
+// and their total number; cached under the leaderboard prefix, so confirmations refresh it
+func (s *MatchService) GetUnrankedPlayers(ctx context.Context, sport string, limit, offset int) ([]models.UnrankedEntry, int, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.GetUnrankedPlayers", trace.WithAttributes(attribute.String("sport", sport)))
+	defer span.End()
+
+	cacheKey := fmt.Sprintf("leaderboard:%s:unranked:%d:%d", sport, limit, offset)
+
+	var page struct {
+		Entries []models.UnrankedEntry `json:"entries"`
+		Total   int                    `json:"total"`
+	}
+	if cache.GetJSON(s.cache, cacheKey, &page) {
+		return page.Entries, page.Total, nil
+	}
+
+	entries, total, err := s.matchRepo.GetUnrankedPlayers(ctx, sport, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	page.Entries, page.Total = entries, total
+	cache.SetJSON(s.cache, cacheKey, page, s.activity.TTL(LeaderboardTTL))
+
+	return entries, total, nil
+}
+
+// ApplyRankDeltas sets the rank change of every entry since the snapshot taken on the given day
+// Past snapshots never change, so their ranks are cached outside the leaderboard prefix
+// Entries missing from the snapshot, or all of them if there is none, keep a nil delta
+func (s *MatchService) ApplyRankDeltas(ctx context.Context, sport string, entries []models.LeaderboardEntry, day time.Time) error {
+	ctx, span := tracer.Start(ctx, "MatchService.ApplyRankDeltas", trace.WithAttributes(attribute.String("sport", sport)))
+	defer span.End()
+
+	cacheKey := fmt.Sprintf("snapshot_ranks:%s:%s", sport, day.Format("2006-01-02"))
+
+	var ranks map[int]int
+	if !cache.GetJSON(s.cache, cacheKey, &ranks) {
+		var err error
+		ranks, err = s.snapshotRepo.GetRanks(ctx, day, sport)
+		if err != nil {
+			return err
 		}
+		cache.SetJSON(s.cache, cacheKey, ranks, time.Hour)
 	}
 
-	// Cache miss - fetch from database
-	entries, err := s.matchRepo.GetLeaderboardEntries(sport)
+	for i := range entries {
+		if previous, ok := ranks[entries[i].User.ID]; ok {
+			delta := previous - entries[i].Rank
+			entries[i].RankDelta = &delta
+		}
+	}
+	return nil
+}
+
+// GetCoalitionStandings returns the coalition standings of a sport
+// Cached under the sport's leaderboard prefix so match confirmations invalidate them too
+func (s *MatchService) GetCoalitionStandings(ctx context.Context, sport string) ([]models.CoalitionStanding, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.GetCoalitionStandings", trace.WithAttributes(attribute.String("sport", sport)))
+	defer span.End()
+
+	cacheKey := fmt.Sprintf("leaderboard:%s:coalitions", sport)
+
+	var standings []models.CoalitionStanding
+	if cache.GetJSON(s.cache, cacheKey, &standings) {
+		return standings, nil
+	}
+
+	standings, err := s.matchRepo.GetCoalitionStandings(ctx, sport)
 	if err != nil {
 		return nil, err
 	}
 
-	// Sort by ELO (descending) with tiebreakers
-	sortLeaderboardByELO(entries)
+	cache.SetJSON(s.cache, cacheKey, standings, s.activity.TTL(LeaderboardTTL))
+	return standings, nil
+}
 
-	// Assign ranks - same rank for tied ELO
-	for i := range entries {
-		if i == 0 {
-			entries[i].Rank = 1
-		} else if entries[i].ELO == entries[i-1].ELO {
-			// Same ELO = same rank
-			entries[i].Rank = entries[i-1].Rank
-		} else {
-			// Different ELO = position-based rank (accounts for ties above)
-			entries[i].Rank = i + 1
+// GetELODistribution returns the rating distribution of a sport
+// Cached under the sport's leaderboard prefix so match confirmations invalidate it too
+func (s *MatchService) GetELODistribution(ctx context.Context, sport string, bucketSize int) (*models.ELODistribution, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.GetELODistribution", trace.WithAttributes(attribute.String("sport", sport)))
+	defer span.End()
+
+	cacheKey := fmt.Sprintf("leaderboard:%s:distribution:%d", sport, bucketSize)
+
+	var dist models.ELODistribution
+	if cache.GetJSON(s.cache, cacheKey, &dist) {
+		return &dist, nil
+	}
+
+	result, err := s.matchRepo.GetELODistribution(ctx, sport, bucketSize)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.SetJSON(s.cache, cacheKey, result, s.activity.TTL(LeaderboardTTL))
+	return result, nil
+}
+
+// GetActivityHeatmap returns when a sport was played over the last days, in time zone tz
+// Cached under the sport's leaderboard prefix so match confirmations invalidate it too
+func (s *MatchService) GetActivityHeatmap(ctx context.Context, sport string, days int, tz string) (*models.ActivityHeatmap, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.GetActivityHeatmap", trace.WithAttributes(attribute.String("sport", sport)))
+	defer span.End()
+
+	cacheKey := fmt.Sprintf("leaderboard:%s:activity:%d:%s", sport, days, tz)
+
+	var heatmap models.ActivityHeatmap
+	if cache.GetJSON(s.cache, cacheKey, &heatmap) {
+		return &heatmap, nil
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	counts, total, err := s.matchRepo.GetActivityHeatmap(ctx, sport, since, tz)
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap = models.ActivityHeatmap{Sport: sport, Days: days, TimeZone: tz, Matches: total, Counts: counts}
+	cache.SetJSON(s.cache, cacheKey, heatmap, s.activity.TTL(LeaderboardTTL))
+	return &heatmap, nil
+}
+
+// GetGlobalStats returns the landing page figures: players, matches, averages, leaders and
+// the most active player of the week (since Monday 00:00 UTC)
+// Cached as globalStatsKey, which every leaderboard invalidation clears
+func (s *MatchService) GetGlobalStats(ctx context.Context) (*models.GlobalStats, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.GetGlobalStats")
+	defer span.End()
+
+	cacheKey := globalStatsKey
+
+	var stats models.GlobalStats
+	if cache.GetJSON(s.cache, cacheKey, &stats) {
+		return &stats, nil
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+
+	players, err := s.userRepo.CountPlayers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.matchRepo.GetSportSummaries(ctx, weekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	stats = models.GlobalStats{TotalPlayers: players, Sports: summaries}
+	for i := range stats.Sports {
+		summary := &stats.Sports[i]
+		stats.MatchesThisWeek += summary.MatchesThisWeek
+
+		// Nobody leads a sport that has not been played yet
+		if summary.Matches == 0 {
+			continue
+		}
+		top, _, err := s.GetLeaderboardPage(ctx, summary.Sport, 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(top) > 0 {
+			summary.Leader = &top[0]
 		}
 	}
 
-	// Store in cache
-	s.cache.Set(cacheKey, entries)
+	userID, matches, ok, err := s.matchRepo.GetMostActivePlayer(ctx, weekStart)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		stats.MostActive = &models.ActivePlayer{User: *user, Matches: matches}
+	}
 
-	return entries, nil
+	cache.SetJSON(s.cache, cacheKey, stats, s.activity.TTL(LeaderboardTTL))
+	return &stats, nil
 }
 
-// InvalidateLeaderboardCache clears the leaderboard cache
-// Should be called after match confirmations that affect ELO
+// globalStatsKey caches the landing page figures, which span all sports
+const globalStatsKey = "leaderboard:stats"
+
+// InvalidateLeaderboardCache clears the cached leaderboards and statistics of all sports
+// For changes to what every leaderboard shows but not to its rankings, such as names and avatars
 func (s *MatchService) InvalidateLeaderboardCache() {
 	s.cache.DeleteByPrefix("leaderboard:")
+	s.cache.DeleteByPrefix("podium:")
 }
 
-// sortLeaderboardByELO sorts entries by ELO descending with tiebreakers
-// Tiebreaker order: ELO (desc) > Wins (desc) > MatchesPlayed (desc) > UserID (asc for consistency)
-func sortLeaderboardByELO(entries []models.LeaderboardEntry) {
-	// Use insertion sort for small slices, quicksort-like approach for larger ones
-	n := len(entries)
-	for i := 1; i < n; i++ {
-		key := entries[i]
-		j := i - 1
-		for j >= 0 && compareLeaderboardEntries(entries[j], key) < 0 {
-			entries[j+1] = entries[j]
-			j--
-		}
-		entries[j+1] = key
-	}
+// RefreshRankings re-ranks the leaderboards of the given sports, or of all sports, inside tx
+// For services that change ratings, records or players in a transaction of their own
+func (s *MatchService) RefreshRankings(ctx context.Context, tx *sql.Tx, sports ...string) error {
+	ctx, span := tracer.Start(ctx, "MatchService.RefreshRankings")
+	defer span.End()
+
+	_, err := s.rankingRepo.Refresh(ctx, tx, sports...)
+	return err
 }
 
-// compareLeaderboardEntries compares two entries for sorting
-// Returns positive if a should come before b, negative if b should come before a
-func compareLeaderboardEntries(a, b models.LeaderboardEntry) int {
-	// Primary: ELO descending
-	if a.ELO != b.ELO {
-		return a.ELO - b.ELO
+// RebuildLeaderboard re-ranks the leaderboards of the given sports, or of all sports when none
+// are given, and clears their caches; returns the number of rankings that changed
+// For changes made outside the match flow, e.g. by admins or background jobs, after they commit
+func (s *MatchService) RebuildLeaderboard(ctx context.Context, sports ...string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "MatchService.RebuildLeaderboard")
+	defer span.End()
+
+	changed, err := s.rankingRepo.Refresh(ctx, nil, sports...)
+	if err != nil {
+		return 0, err
 	}
-	// Secondary: Wins descending
-	if a.Wins != b.Wins {
-		return a.Wins - b.Wins
+
+	s.LeaderboardChanged(sports...)
+	return changed, nil
+}
+
+// LeaderboardChanged clears the caches of the given sports, or of all sports when none are
+// given, and tells subscribers such as rank notifications and podium webhooks that their
+// rankings changed; for changes whose rankings were refreshed in a transaction that committed
+func (s *MatchService) LeaderboardChanged(sports ...string) {
+	if len(sports) == 0 {
+		s.InvalidateLeaderboardCache()
 	}
-	// Tertiary: Matches played descending (more active = higher)
-	if a.MatchesPlayed != b.MatchesPlayed {
-		return a.MatchesPlayed - b.MatchesPlayed
+	for _, sport := range sports {
+		s.InvalidateSportLeaderboard(sport)
 	}
-	// Final tiebreaker: User ID ascending for consistent ordering
-	return b.User.ID - a.User.ID
+	s.publishLeaderboardUpdated(sports...)
+}
+
+// InvalidateSportLeaderboard clears the cached leaderboard, podiums and statistics of one sport
+// and the global statistics; called after every change to the sport's ratings or results
+func (s *MatchService) InvalidateSportLeaderboard(sport string) {
+	s.cache.DeleteByPrefix("leaderboard:" + sport + ":")
+	s.cache.DeleteByPrefix("podium:" + sport + ":")
+	s.cache.Delete(globalStatsKey)
 }
@@ -0,0 +1,579 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/cache"
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories/fakes"
+)
+
+// Players of the test fixtures
+const (
+	alice = 1
+	bob   = 2
+	carol = 3
+)
+
+// testSports returns a SportService serving table tennis with strict pending rules and
+// table football with several pending matches per pair, without a database
+func testSports() *SportService {
+	sports := NewSportService(nil, fakes.NewRankings(), cache.NewInMemory())
+	sports.setCache([]*Sport{
+		{
+			ID: "table_tennis", Name: "table_tennis", DisplayName: "Table Tennis",
+			DefaultELO: 1000, KFactor: 32, KFactorStrategy: KFactorFixed,
+			RatingFloor: 100, RatingCeiling: 4000, MinScore: 0, MaxScore: 99,
+			IsActive: true, PendingMode: PendingModeStrict, MaxPendingPerPair: 1,
+		},
+		{
+			ID: "table_football", Name: "table_football", DisplayName: "Table Football",
+			DefaultELO: 1000, KFactor: 32, KFactorStrategy: KFactorFixed,
+			RatingFloor: 100, RatingCeiling: 4000, MinScore: 0, MaxScore: 10,
+			IsActive: true, PendingMode: PendingModeMultiple, PendingMinIntervalSeconds: 60, MaxPendingPerPair: 2,
+		},
+		{
+			ID: "darts", Name: "darts", DisplayName: "Darts",
+			DefaultELO: 1000, KFactor: 32, MinScore: 0, MaxScore: 99,
+		},
+	})
+	return sports
+}
+
+// matchFixture is a MatchService backed by in-memory stores
+type matchFixture struct {
+	service    *MatchService
+	matches    *fakes.Matches
+	userSports *fakes.UserSports
+	history    *fakes.ELOHistory
+	rankings   *fakes.Rankings
+	cache      cache.Cache
+}
+
+func newMatchFixture() *matchFixture {
+	sports := testSports()
+	f := &matchFixture{
+		matches:  fakes.NewMatches(),
+		history:  fakes.NewELOHistory(),
+		rankings: fakes.NewRankings(),
+		cache:    cache.NewInMemory(),
+	}
+	f.userSports = fakes.NewUserSports(f.matches)
+	users := fakes.NewUsers(
+		models.User{ID: alice, Login: "alice"},
+		models.User{ID: bob, Login: "bob"},
+		models.User{ID: carol, Login: "carol"},
+	)
+	f.service = NewMatchService(fakes.NewDB(), f.matches, users, f.userSports, f.history, nil, f.rankings,
+		sports, NewELOService(32, 0.5, sports), f.cache, nil)
+	return f
+}
+
+// submit submits a table tennis match of alice against bob
+func (f *matchFixture) submit(t *testing.T, aliceScore, bobScore int) *models.Match {
+	t.Helper()
+	match, err := f.service.SubmitMatch(context.Background(), &models.SubmitMatchRequest{
+		Sport: "table_tennis", OpponentID: bob, PlayerScore: aliceScore, OpponentScore: bobScore,
+	}, alice, "")
+	if err != nil {
+		t.Fatalf("SubmitMatch: %v", err)
+	}
+	return match
+}
+
+func (f *matchFixture) elo(t *testing.T, userID int, sport string) int {
+	t.Helper()
+	row := f.userSports.Get(userID, sport)
+	if row == nil {
+		t.Fatalf("player %d has no %s rating", userID, sport)
+	}
+	return row.CurrentELO
+}
+
+// wantError fails unless err is set and mentions want
+func wantError(t *testing.T, err error, want string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("got no error, want one containing %q", want)
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("error = %q, want one containing %q", err, want)
+	}
+}
+
+func TestSubmitMatchValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.SubmitMatchRequest
+		want string
+	}{
+		{"against yourself", models.SubmitMatchRequest{Sport: "table_tennis", OpponentID: alice, PlayerScore: 11, OpponentScore: 5}, "against yourself"},
+		{"tie", models.SubmitMatchRequest{Sport: "table_tennis", OpponentID: bob, PlayerScore: 11, OpponentScore: 11}, "tie"},
+		{"unknown sport", models.SubmitMatchRequest{Sport: "chess", OpponentID: bob, PlayerScore: 11, OpponentScore: 5}, "not an active sport"},
+		{"inactive sport", models.SubmitMatchRequest{Sport: "darts", OpponentID: bob, PlayerScore: 11, OpponentScore: 5}, "not an active sport"},
+		{"score above the sport's maximum", models.SubmitMatchRequest{Sport: "table_football", OpponentID: bob, PlayerScore: 11, OpponentScore: 5}, "between 0 and 10"},
+		{"unknown opponent", models.SubmitMatchRequest{Sport: "table_tennis", OpponentID: 99, PlayerScore: 11, OpponentScore: 5}, "opponent not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newMatchFixture()
+			_, err := f.service.SubmitMatch(context.Background(), &tt.req, alice, "")
+			wantError(t, err, tt.want)
+		})
+	}
+}
+
+func TestSubmitMatchCreatesPendingMatch(t *testing.T) {
+	f := newMatchFixture()
+
+	match := f.submit(t, 5, 11)
+	if match.ID == 0 || match.Status != models.StatusPending {
+		t.Fatalf("match %d has status %q, want a stored pending match", match.ID, match.Status)
+	}
+	if match.Player1ID != alice || match.Player2ID != bob || match.SubmittedBy != alice {
+		t.Errorf("players %d vs %d submitted by %d, want the submitter as player 1", match.Player1ID, match.Player2ID, match.SubmittedBy)
+	}
+	if match.WinnerID != bob {
+		t.Errorf("winner = %d, want bob (%d)", match.WinnerID, bob)
+	}
+}
+
+func TestSubmitMatchAgainstDeactivatedPlayer(t *testing.T) {
+	f := newMatchFixture()
+	deactivated := time.Now().Add(-time.Hour)
+	f.service.userRepo.(*fakes.Users).Add(models.User{ID: bob, Login: "bob", DeactivatedAt: &deactivated})
+
+	_, err := f.service.SubmitMatch(context.Background(), &models.SubmitMatchRequest{Sport: "table_tennis", OpponentID: bob, PlayerScore: 11, OpponentScore: 7}, alice, "")
+	if !errors.Is(err, ErrOpponentDeactivated) {
+		t.Fatalf("SubmitMatch against a deactivated player: err = %v, want %v", err, ErrOpponentDeactivated)
+	}
+	_, err = f.service.SubmitForfeit(context.Background(), &models.SubmitForfeitRequest{Sport: "table_tennis", OpponentID: bob}, alice, "")
+	if !errors.Is(err, ErrOpponentDeactivated) {
+		t.Fatalf("SubmitForfeit against a deactivated player: err = %v, want %v", err, ErrOpponentDeactivated)
+	}
+
+	// Reactivated on login, the player can be played again
+	f.service.userRepo.(*fakes.Users).Add(models.User{ID: bob, Login: "bob"})
+	f.submit(t, 11, 7)
+}
+
+func TestSubmitMatchStrictPendingRule(t *testing.T) {
+	f := newMatchFixture()
+	f.submit(t, 11, 5)
+
+	_, err := f.service.SubmitMatch(context.Background(), &models.SubmitMatchRequest{Sport: "table_tennis", OpponentID: bob, PlayerScore: 11, OpponentScore: 7}, alice, "")
+	wantError(t, err, "pending match already exists")
+
+	// The rule covers the pair in both directions
+	_, err = f.service.SubmitMatch(context.Background(), &models.SubmitMatchRequest{Sport: "table_tennis", OpponentID: alice, PlayerScore: 11, OpponentScore: 7}, bob, "")
+	wantError(t, err, "pending match already exists")
+
+	// Other pairs are not affected
+	if _, err := f.service.SubmitMatch(context.Background(), &models.SubmitMatchRequest{Sport: "table_tennis", OpponentID: carol, PlayerScore: 11, OpponentScore: 7}, alice, ""); err != nil {
+		t.Errorf("match against carol: %v", err)
+	}
+}
+
+func TestSubmitMatchMultiplePendingRule(t *testing.T) {
+	f := newMatchFixture()
+	req := &models.SubmitMatchRequest{Sport: "table_football", OpponentID: bob, PlayerScore: 10, OpponentScore: 4}
+
+	if _, err := f.service.SubmitMatch(context.Background(), req, alice, ""); err != nil {
+		t.Fatalf("first match: %v", err)
+	}
+	_, err := f.service.SubmitMatch(context.Background(), req, alice, "")
+	wantError(t, err, "please wait")
+
+	// Spaced-out games are fine up to the per-pair cap
+	f.matches.Add(models.Match{
+		Sport: "table_football", Player1ID: bob, Player2ID: alice, Player1Score: 10, Player2Score: 8,
+		WinnerID: bob, Status: models.StatusPending, SubmittedBy: bob, CreatedAt: time.Now().Add(-time.Hour),
+	})
+	_, err = f.service.SubmitMatch(context.Background(), req, alice, "")
+	wantError(t, err, "too many pending matches")
+}
+
+func TestConfirmMatchUpdatesRatings(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+	f.cache.Set("leaderboard:table_tennis:0:0", []byte("[]"), time.Minute)
+
+	if err := f.service.ConfirmMatch(context.Background(), match.ID, bob, "fingerprint"); err != nil {
+		t.Fatalf("ConfirmMatch: %v", err)
+	}
+
+	confirmed, _ := f.matches.GetByID(context.Background(), match.ID)
+	if confirmed.Status != models.StatusConfirmed || confirmed.ConfirmedAt == nil {
+		t.Fatalf("status = %q, want confirmed", confirmed.Status)
+	}
+	if *confirmed.Player1ELODelta != 16 || *confirmed.Player2ELODelta != -16 {
+		t.Errorf("deltas = %d/%d, want 16/-16", *confirmed.Player1ELODelta, *confirmed.Player2ELODelta)
+	}
+	if *confirmed.Player1KFactor != 32 || *confirmed.Player2KFactor != 32 {
+		t.Errorf("K-factors = %d/%d, want 32/32", *confirmed.Player1KFactor, *confirmed.Player2KFactor)
+	}
+	if got := f.elo(t, alice, "table_tennis"); got != 1016 {
+		t.Errorf("alice's rating = %d, want 1016", got)
+	}
+	if got := f.elo(t, bob, "table_tennis"); got != 984 {
+		t.Errorf("bob's rating = %d, want 984", got)
+	}
+
+	winner, loser := f.userSports.Get(alice, "table_tennis"), f.userSports.Get(bob, "table_tennis")
+	if winner.Wins != 1 || winner.CurrentStreak != 1 || loser.Losses != 1 || loser.CurrentStreak != -1 {
+		t.Errorf("records %+v and %+v, want a win for alice and a loss for bob", winner, loser)
+	}
+
+	history := f.history.Entries(alice)
+	if len(history) != 1 || history[0].Source != models.ELOSourceMatch || history[0].Delta != 16 {
+		t.Errorf("alice's history = %+v, want one match entry of +16", history)
+	}
+
+	if _, ok := f.cache.Get("leaderboard:table_tennis:0:0"); ok {
+		t.Error("leaderboard cache was not invalidated")
+	}
+}
+
+func TestConfirmMatchInvalidatesOnlyItsSport(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+	for _, key := range []string{"leaderboard:table_tennis:10:0", "podium:table_tennis:3:false", "leaderboard:stats", "leaderboard:table_football:0:0", "podium:table_football:3:false"} {
+		f.cache.Set(key, []byte("[]"), time.Minute)
+	}
+
+	if err := f.service.ConfirmMatch(context.Background(), match.ID, bob, ""); err != nil {
+		t.Fatalf("ConfirmMatch: %v", err)
+	}
+
+	for key, want := range map[string]bool{
+		"leaderboard:table_tennis:10:0":  false,
+		"podium:table_tennis:3:false":    false,
+		"leaderboard:stats":              false,
+		"leaderboard:table_football:0:0": true,
+		"podium:table_football:3:false":  true,
+	} {
+		if _, ok := f.cache.Get(key); ok != want {
+			t.Errorf("%s cached = %t, want %t", key, ok, want)
+		}
+	}
+}
+
+func TestConfirmMatchPermissions(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+
+	wantError(t, f.service.ConfirmMatch(context.Background(), match.ID, alice, ""), "cannot confirm your own match")
+	wantError(t, f.service.ConfirmMatch(context.Background(), match.ID, carol, ""), "not part of this match")
+	wantError(t, f.service.ConfirmMatch(context.Background(), 999, bob, ""), "match not found")
+
+	if err := f.service.ConfirmMatch(context.Background(), match.ID, bob, ""); err != nil {
+		t.Fatalf("ConfirmMatch: %v", err)
+	}
+	wantError(t, f.service.ConfirmMatch(context.Background(), match.ID, bob, ""), "not pending")
+}
+
+func TestConfirmForfeitUsesForfeitFactor(t *testing.T) {
+	f := newMatchFixture()
+	match, err := f.service.SubmitForfeit(context.Background(), &models.SubmitForfeitRequest{Sport: "table_tennis", OpponentID: bob, Conceded: true}, alice, "")
+	if err != nil {
+		t.Fatalf("SubmitForfeit: %v", err)
+	}
+	if match.WinnerID != bob || match.ForfeitedBy == nil || *match.ForfeitedBy != alice {
+		t.Fatalf("conceded forfeit won by %d, want bob to win against alice", match.WinnerID)
+	}
+
+	if err := f.service.ConfirmMatch(context.Background(), match.ID, bob, ""); err != nil {
+		t.Fatalf("ConfirmMatch: %v", err)
+	}
+	if got := f.elo(t, bob, "table_tennis"); got != 1008 {
+		t.Errorf("bob's rating = %d, want 1008 (half of a played win)", got)
+	}
+	if history := f.history.Entries(bob); len(history) != 1 || history[0].Source != models.ELOSourceForfeit {
+		t.Errorf("bob's history = %+v, want one forfeit entry", history)
+	}
+}
+
+func TestDenyMatch(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+
+	wantError(t, f.service.DenyMatch(context.Background(), match.ID, alice, nil), "cannot deny your own match")
+	wantError(t, f.service.DenyMatch(context.Background(), match.ID, carol, nil), "not part of this match")
+
+	if err := f.service.DenyMatch(context.Background(), match.ID, bob, nil); err != nil {
+		t.Fatalf("DenyMatch: %v", err)
+	}
+	denied, _ := f.matches.GetByID(context.Background(), match.ID)
+	if denied.Status != models.StatusDenied || denied.DeniedAt == nil {
+		t.Errorf("status = %q, want denied", denied.Status)
+	}
+	if proposal, _ := f.matches.GetCounterProposal(context.Background(), match.ID); proposal != nil {
+		t.Errorf("denial without a score created counter-proposal %+v", proposal)
+	}
+	if row := f.userSports.Get(alice, "table_tennis"); row != nil {
+		t.Errorf("denied match changed alice's record: %+v", row)
+	}
+}
+
+func TestAcceptCounterProposal(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+
+	// Bob says he won 11-9; scores are from his perspective
+	bobScore, aliceScore := 11, 9
+	same := 5
+	wantError(t, f.service.DenyMatch(context.Background(), match.ID, bob, &models.DenyMatchRequest{PlayerScore: &same, OpponentScore: &match.Player1Score}),
+		"must differ from the submitted score")
+	if err := f.service.DenyMatch(context.Background(), match.ID, bob, &models.DenyMatchRequest{PlayerScore: &bobScore, OpponentScore: &aliceScore}); err != nil {
+		t.Fatalf("DenyMatch: %v", err)
+	}
+
+	proposal, err := f.service.GetCounterProposal(context.Background(), match.ID, alice)
+	if err != nil {
+		t.Fatalf("GetCounterProposal: %v", err)
+	}
+	if proposal.Player1Score != 9 || proposal.Player2Score != 11 || proposal.ProposedBy != bob {
+		t.Errorf("proposal = %+v, want 9-11 proposed by bob", proposal)
+	}
+
+	wantError(t, f.service.AcceptCounterProposal(context.Background(), match.ID, bob, ""), "only the submitter")
+	if err := f.service.AcceptCounterProposal(context.Background(), match.ID, alice, ""); err != nil {
+		t.Fatalf("AcceptCounterProposal: %v", err)
+	}
+
+	confirmed, _ := f.matches.GetByID(context.Background(), match.ID)
+	if confirmed.Status != models.StatusConfirmed || confirmed.WinnerID != bob || confirmed.Player1Score != 9 {
+		t.Errorf("match = %s %d-%d won by %d, want confirmed 9-11 won by bob", confirmed.Status, confirmed.Player1Score, confirmed.Player2Score, confirmed.WinnerID)
+	}
+	if got := f.elo(t, bob, "table_tennis"); got != 1016 {
+		t.Errorf("bob's rating = %d, want 1016", got)
+	}
+	wantError(t, f.service.RejectCounterProposal(context.Background(), match.ID, alice), "match is not denied")
+}
+
+func TestCounterProposalOutsideScoreRange(t *testing.T) {
+	f := newMatchFixture()
+	match, err := f.service.SubmitMatch(context.Background(), &models.SubmitMatchRequest{
+		Sport: "table_football", OpponentID: bob, PlayerScore: 10, OpponentScore: 6,
+	}, alice, "")
+	if err != nil {
+		t.Fatalf("SubmitMatch: %v", err)
+	}
+
+	// Table football ends at 10, so 11 would have been refused on submission as well
+	bobScore, aliceScore := 11, 6
+	wantError(t, f.service.DenyMatch(context.Background(), match.ID, bob, &models.DenyMatchRequest{PlayerScore: &bobScore, OpponentScore: &aliceScore}),
+		"player_score: must be between 0 and 10 under the Table Football rules")
+	pending, _ := f.matches.GetByID(context.Background(), match.ID)
+	if pending.Status != models.StatusPending {
+		t.Errorf("status = %q after a rejected counter-proposal, want pending", pending.Status)
+	}
+}
+
+func TestCancelMatch(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+
+	wantError(t, f.service.CancelMatch(context.Background(), match.ID, bob), "only the submitter")
+	if err := f.service.CancelMatch(context.Background(), match.ID, alice); err != nil {
+		t.Fatalf("CancelMatch: %v", err)
+	}
+	cancelled, _ := f.matches.GetByID(context.Background(), match.ID)
+	if cancelled.Status != models.StatusCancelled {
+		t.Errorf("status = %q, want cancelled", cancelled.Status)
+	}
+	wantError(t, f.service.CancelMatch(context.Background(), match.ID, alice), "not pending")
+
+	// A cancelled match no longer blocks the pair
+	f.submit(t, 11, 7)
+}
+
+func TestCorrectMatchSwapsWinner(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+	if err := f.service.ConfirmMatch(context.Background(), match.ID, bob, ""); err != nil {
+		t.Fatalf("ConfirmMatch: %v", err)
+	}
+
+	aliceScore, bobScore := 5, 11
+	before, after, err := f.service.CorrectMatch(context.Background(), match.ID, &models.EditMatchRequest{Player1Score: &aliceScore, Player2Score: &bobScore, Reason: "swapped"})
+	if err != nil {
+		t.Fatalf("CorrectMatch: %v", err)
+	}
+	if before.WinnerID != alice || after.WinnerID != bob {
+		t.Errorf("winner %d -> %d, want alice -> bob", before.WinnerID, after.WinnerID)
+	}
+	if *after.Player1ELODelta != -16 || *after.Player2ELODelta != 16 {
+		t.Errorf("corrected deltas = %d/%d, want -16/16", *after.Player1ELODelta, *after.Player2ELODelta)
+	}
+	if got := f.elo(t, alice, "table_tennis"); got != 984 {
+		t.Errorf("alice's rating = %d, want 984", got)
+	}
+	if got := f.elo(t, bob, "table_tennis"); got != 1016 {
+		t.Errorf("bob's rating = %d, want 1016", got)
+	}
+
+	// The win and the loss moved between the players
+	if row := f.userSports.Get(alice, "table_tennis"); row.Wins != 0 || row.Losses != 1 {
+		t.Errorf("alice's record = %d-%d, want 0-1", row.Wins, row.Losses)
+	}
+	history := f.history.Entries(bob)
+	if len(history) != 2 || history[1].Source != models.ELOSourceCorrection || history[1].Delta != 32 {
+		t.Errorf("bob's history = %+v, want a correction of +32", history)
+	}
+
+	_, _, err = f.service.CorrectMatch(context.Background(), match.ID, &models.EditMatchRequest{Player1Score: &aliceScore, Reason: "again"})
+	wantError(t, err, "nothing to correct")
+}
+
+func TestCorrectMatchClampsToFloor(t *testing.T) {
+	f := newMatchFixture()
+	elo, won, lost, k := 1000, 16, -16, 32
+	match := f.matches.Add(models.Match{
+		Sport: "table_tennis", Player1ID: alice, Player2ID: bob, Player1Score: 5, Player2Score: 11,
+		WinnerID: bob, Status: models.StatusConfirmed, SubmittedBy: alice,
+		Player1ELOBefore: &elo, Player1ELODelta: &lost, Player1KFactor: &k,
+		Player2ELOBefore: &elo, Player2ELODelta: &won, Player2KFactor: &k,
+	})
+	// Bob has lost everything since and sits at the floor
+	f.userSports.SetELO(alice, "table_tennis", 984)
+	f.userSports.SetELO(bob, "table_tennis", 100)
+
+	winner := alice
+	if _, _, err := f.service.CorrectMatch(context.Background(), match.ID, &models.EditMatchRequest{WinnerID: &winner}); err == nil {
+		t.Fatal("correcting the winner against the scores succeeded")
+	}
+
+	aliceScore := 12
+	if _, _, err := f.service.CorrectMatch(context.Background(), match.ID, &models.EditMatchRequest{Player1Score: &aliceScore, Reason: "typo"}); err != nil {
+		t.Fatalf("CorrectMatch: %v", err)
+	}
+	if got := f.elo(t, alice, "table_tennis"); got != 1016 {
+		t.Errorf("alice's rating = %d, want 1016", got)
+	}
+	if got := f.elo(t, bob, "table_tennis"); got != 100 {
+		t.Errorf("bob's rating = %d, want the floor of 100", got)
+	}
+}
+
+func TestCorrectMatchOutsideScoreRange(t *testing.T) {
+	f := newMatchFixture()
+	match, err := f.service.SubmitMatch(context.Background(), &models.SubmitMatchRequest{
+		Sport: "table_football", OpponentID: bob, PlayerScore: 10, OpponentScore: 6,
+	}, alice, "")
+	if err != nil {
+		t.Fatalf("SubmitMatch: %v", err)
+	}
+	if err := f.service.ConfirmMatch(context.Background(), match.ID, bob, ""); err != nil {
+		t.Fatalf("ConfirmMatch: %v", err)
+	}
+
+	score := 12
+	_, _, err = f.service.CorrectMatch(context.Background(), match.ID, &models.EditMatchRequest{Player2Score: &score, Reason: "typo"})
+	wantError(t, err, "player2_score: must be between 0 and 10 under the Table Football rules")
+	unchanged, _ := f.matches.GetByID(context.Background(), match.ID)
+	if unchanged.Player2Score != 6 {
+		t.Errorf("player2 score = %d after a rejected edit, want 6", unchanged.Player2Score)
+	}
+}
+
+func TestCorrectMatchRequiresConfirmedMatch(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+
+	score := 3
+	_, _, err := f.service.CorrectMatch(context.Background(), match.ID, &models.EditMatchRequest{Player2Score: &score, Reason: "typo"})
+	wantError(t, err, "only confirmed matches")
+}
+
+func TestResolveMatchStatus(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+
+	for _, status := range []string{models.StatusConfirmed, models.StatusPending} {
+		_, err := f.service.ResolveMatchStatus(context.Background(), match.ID, status)
+		wantError(t, err, "cannot be set to "+status)
+	}
+
+	// Held for review, then confirmed by staff with the ELO of a normal confirmation
+	if _, err := f.service.ResolveMatchStatus(context.Background(), match.ID, models.StatusDisputed); err != nil {
+		t.Fatalf("ResolveMatchStatus(disputed): %v", err)
+	}
+	wantError(t, f.service.ConfirmMatch(context.Background(), match.ID, bob, ""), "not pending")
+	before, err := f.service.ResolveMatchStatus(context.Background(), match.ID, models.StatusConfirmed)
+	if err != nil {
+		t.Fatalf("ResolveMatchStatus(confirmed): %v", err)
+	}
+	if before.Status != models.StatusDisputed {
+		t.Errorf("previous status = %q, want disputed", before.Status)
+	}
+	if got := f.elo(t, alice, "table_tennis"); got != 1016 {
+		t.Errorf("alice's rating = %d, want 1016", got)
+	}
+
+	// A confirmed match keeps its status, so its ELO can't be applied twice or dropped
+	for _, status := range []string{models.StatusDisputed, models.StatusDenied, models.StatusCancelled} {
+		_, err := f.service.ResolveMatchStatus(context.Background(), match.ID, status)
+		wantError(t, err, "a confirmed match cannot be set to "+status)
+	}
+	if got := f.elo(t, alice, "table_tennis"); got != 1016 {
+		t.Errorf("alice's rating = %d after rejected changes, want 1016", got)
+	}
+}
+
+func TestResolveMatchStatusCancelsWithoutELO(t *testing.T) {
+	f := newMatchFixture()
+	match := f.submit(t, 11, 5)
+
+	if _, err := f.service.ResolveMatchStatus(context.Background(), match.ID, models.StatusCancelled); err != nil {
+		t.Fatalf("ResolveMatchStatus(cancelled): %v", err)
+	}
+	cancelled, _ := f.matches.GetByID(context.Background(), match.ID)
+	if cancelled.Status != models.StatusCancelled {
+		t.Errorf("status = %q, want cancelled", cancelled.Status)
+	}
+	if row := f.userSports.Get(alice, "table_tennis"); row != nil && row.CurrentELO != 1000 {
+		t.Errorf("alice's rating = %d, want 1000", row.CurrentELO)
+	}
+	_, err := f.service.ResolveMatchStatus(context.Background(), match.ID, models.StatusDisputed)
+	wantError(t, err, "a cancelled match cannot be set to disputed")
+}
+
+// eventRecorder collects the sports of published leaderboard updates
+type eventRecorder struct {
+	leaderboards []string
+}
+
+func (r *eventRecorder) Publish(channel, eventType string, data interface{}) {
+	if eventType == EventLeaderboardUpdated {
+		r.leaderboards = append(r.leaderboards, data.(map[string]string)["sport"])
+	}
+}
+
+func TestRebuildLeaderboardPublishesUpdates(t *testing.T) {
+	f := newMatchFixture()
+	events := &eventRecorder{}
+	f.service.SetEventPublisher(events)
+
+	if _, err := f.service.RebuildLeaderboard(context.Background(), "table_football"); err != nil {
+		t.Fatalf("RebuildLeaderboard(table_football): %v", err)
+	}
+	if got := strings.Join(events.leaderboards, ","); got != "table_football" {
+		t.Errorf("updated leaderboards = %q, want table_football", got)
+	}
+
+	// Without sports every active leaderboard is announced, so podium webhooks see bans and recomputes
+	events.leaderboards = nil
+	if _, err := f.service.RebuildLeaderboard(context.Background()); err != nil {
+		t.Fatalf("RebuildLeaderboard: %v", err)
+	}
+	sort.Strings(events.leaderboards)
+	if got := strings.Join(events.leaderboards, ","); got != "table_football,table_tennis" {
+		t.Errorf("updated leaderboards = %q, want table_football,table_tennis", got)
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/intra"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+const (
+	// profileSyncBatchSize caps the users refreshed per run; the 42 client spaces requests out
+	profileSyncBatchSize = 50
+
+	// profileSyncActiveWindow limits the refresh to users with a match this recent
+	profileSyncActiveWindow = 30 * 24 * time.Hour
+
+	// profileSyncBackoffBase and profileSyncBackoffMax bound the retry delay after failures
+	profileSyncBackoffBase = time.Hour
+	profileSyncBackoffMax  = 7 * 24 * time.Hour
+)
+
+// ProfileSyncService refreshes avatars and display names of active users from the 42 API
+// Without it they only change when the user logs in again
+type ProfileSyncService struct {
+	client       *intra.Client
+	syncRepo     repositories.ProfileSyncStore
+	userRepo     repositories.UserStore
+	matchService *MatchService
+	interval     time.Duration
+}
+
+// NewProfileSyncService creates a profile sync service
+// interval is how long a successfully refreshed user is left alone
+func NewProfileSyncService(
+	client *intra.Client,
+	syncRepo repositories.ProfileSyncStore,
+	userRepo repositories.UserStore,
+	matchService *MatchService,
+	interval time.Duration,
+) *ProfileSyncService {
+	return &ProfileSyncService{
+		client:       client,
+		syncRepo:     syncRepo,
+		userRepo:     userRepo,
+		matchService: matchService,
+		interval:     interval,
+	}
+}
+
+// SyncDue refreshes the users that are due, at most one batch per call
+// Returns how many profiles changed; fails only if every attempted user failed
+func (s *ProfileSyncService) SyncDue(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "ProfileSyncService.SyncDue")
+	defer span.End()
+
+	now := time.Now()
+	candidates, err := s.syncRepo.ListDue(ctx, now, now.Add(-profileSyncActiveWindow), profileSyncBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	changed, succeeded := 0, 0
+	var lastErr error
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+
+		updated, err := s.syncUser(ctx, candidate.UserID, candidate.Login)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			failures := candidate.Failures + 1
+			retryAt := time.Now().Add(profileSyncBackoff(failures))
+			slog.Warn("Failed to sync 42 profile", "user_id", candidate.UserID, "failures", failures, "retry_at", retryAt, "error", err)
+			if err := s.syncRepo.RecordFailure(ctx, candidate.UserID, failures, retryAt, err.Error()); err != nil {
+				return changed, err
+			}
+			lastErr = err
+			continue
+		}
+
+		succeeded++
+		if updated {
+			changed++
+		}
+		syncedAt := time.Now()
+		if err := s.syncRepo.RecordSuccess(ctx, candidate.UserID, syncedAt, syncedAt.Add(s.interval)); err != nil {
+			return changed, err
+		}
+	}
+
+	// Leaderboards embed display names and avatars
+	if changed > 0 {
+		s.matchService.InvalidateLeaderboardCache()
+	}
+
+	if succeeded == 0 && lastErr != nil {
+		return changed, fmt.Errorf("all profile syncs failed: %w", lastErr)
+	}
+	return changed, nil
+}
+
+// syncUser fetches one profile and stores it; returns whether the stored data changed
+func (s *ProfileSyncService) syncUser(ctx context.Context, userID int, login string) (bool, error) {
+	profile, err := s.client.GetUser(ctx, login)
+	if errors.Is(err, intra.ErrNotFound) {
+		return false, fmt.Errorf("login %q %w", login, err)
+	}
+	if err != nil {
+		return false, err
+	}
+	return s.userRepo.UpdateDisplayData(ctx, userID, profile.DisplayName, profile.Image.Link)
+}
+
+// profileSyncBackoff doubles the retry delay with every consecutive failure
+func profileSyncBackoff(failures int) time.Duration {
+	delay := profileSyncBackoffBase
+	for i := 1; i < failures && delay < profileSyncBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > profileSyncBackoffMax {
+		delay = profileSyncBackoffMax
+	}
+	return delay
+}
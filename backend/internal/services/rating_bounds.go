@@ -0,0 +1,43 @@
+package services
+
+// Defaults of the rating bounds, matching the sports table
+const (
+	defaultRatingFloor   = 100
+	defaultRatingCeiling = 4000
+)
+
+// RatingBounds keeps ratings between a floor and a ceiling, so no calculation, revert
+// or manual edit leaves a player with a negative or absurd rating
+// A Ceiling of 0 leaves ratings unbounded above; the zero value only keeps them from
+// going negative
+type RatingBounds struct {
+	Floor   int
+	Ceiling int
+}
+
+// Clamp returns elo limited to the bounds
+func (b RatingBounds) Clamp(elo int) int {
+	if b.Ceiling > 0 && elo > b.Ceiling {
+		return b.Ceiling
+	}
+	if elo < b.Floor {
+		return b.Floor
+	}
+	return elo
+}
+
+// RatingBounds returns the rating floor and ceiling configured for the sport
+func (c SportConfig) RatingBounds() RatingBounds {
+	return RatingBounds{Floor: c.RatingFloor, Ceiling: c.RatingCeiling}
+}
+
+// ELORules are the per-sport rules a rating calculation applies on top of the K-factors
+type ELORules struct {
+	Margin MarginRule
+	Bounds RatingBounds
+}
+
+// ELORules returns the rating rules configured for the sport
+func (c SportConfig) ELORules() ELORules {
+	return ELORules{Margin: c.MarginRule(), Bounds: c.RatingBounds()}
+}
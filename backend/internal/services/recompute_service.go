@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+// Replay event kinds, in the order they apply when they share a timestamp
+const (
+	replaySeasonStart = iota
+	replayAdjustment
+	replayDecay
+	replayMatch
+)
+
+// replayEvent is one step of the replay: a season start, a manual adjustment, a decay or a match
+type replayEvent struct {
+	at    time.Time
+	kind  int
+	index int
+}
+
+type ratingKey struct {
+	userID int
+	sport  string
+}
+
+// RecomputeService rebuilds every rating from scratch by replaying history
+type RecomputeService struct {
+	db             *sql.DB
+	replayRepo     repositories.ELOReplayStore
+	userSportsRepo repositories.UserSportsStore
+	eloHistoryRepo repositories.ELOHistoryStore
+	eloService     *ELOService
+	matchService   *MatchService
+}
+
+// NewRecomputeService creates a new RecomputeService instance
+func NewRecomputeService(
+	db *sql.DB,
+	replayRepo repositories.ELOReplayStore,
+	userSportsRepo repositories.UserSportsStore,
+	eloHistoryRepo repositories.ELOHistoryStore,
+	eloService *ELOService,
+	matchService *MatchService,
+) *RecomputeService {
+	return &RecomputeService{
+		db:             db,
+		replayRepo:     replayRepo,
+		userSportsRepo: userSportsRepo,
+		eloHistoryRepo: eloHistoryRepo,
+		eloService:     eloService,
+		matchService:   matchService,
+	}
+}
+
+// RecomputeELO replays all confirmed matches in chronological order, starting every
+// player at the sport default. Season starts soft-reset the ratings with their factor,
+// manual adjustments are replayed as the change they made and decays lower the replayed
+// rating by their amount, but not below the sport default. Everything runs in one
+// transaction that blocks confirmations meanwhile; without apply it is rolled back and
+// only the report of changed ratings is returned
+func (s *RecomputeService) RecomputeELO(ctx context.Context, apply bool) (*models.ELORecomputeReport, error) {
+	ctx, span := tracer.Start(ctx, "RecomputeService.RecomputeELO")
+	defer span.End()
+
+	// The replay runs to completion even if the admin's request goes away, and its queries
+	// over the whole match history are not bounded by the request query timeout
+	ctx = repositories.WithoutQueryTimeout(context.WithoutCancel(ctx))
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report, err := s.replay(ctx, tx, apply)
+	if err != nil {
+		return nil, err
+	}
+	if !apply {
+		return report, nil
+	}
+	if err := s.matchService.RefreshRankings(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.matchService.LeaderboardChanged()
+	slog.Info("ELO recomputed", "matches", report.MatchesReplayed, "matches_changed", report.MatchesChanged, "ratings_changed", len(report.Changes))
+
+	return report, nil
+}
+
+// replay recomputes the ratings inside tx, which it locks against confirmations, and
+// writes them back if apply is set; committing is left to the caller
+func (s *RecomputeService) replay(ctx context.Context, tx *sql.Tx, apply bool) (*models.ELORecomputeReport, error) {
+	if err := s.replayRepo.Lock(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.replayRepo.LoadMatches(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	seasons, err := s.replayRepo.LoadSeasonStarts(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	adjustments, err := s.replayRepo.LoadAdjustments(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	decays, err := s.replayRepo.LoadDecays(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := s.replayRepo.LoadDefaultELOs(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.replayRepo.LoadRatings(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]replayEvent, 0, len(matches)+len(seasons)+len(adjustments)+len(decays))
+	for i, season := range seasons {
+		events = append(events, replayEvent{at: season.StartedAt, kind: replaySeasonStart, index: i})
+	}
+	for i, adjustment := range adjustments {
+		events = append(events, replayEvent{at: adjustment.CreatedAt, kind: replayAdjustment, index: i})
+	}
+	for i, decay := range decays {
+		events = append(events, replayEvent{at: decay.CreatedAt, kind: replayDecay, index: i})
+	}
+	for i, match := range matches {
+		events = append(events, replayEvent{at: match.PlayedAt, kind: replayMatch, index: i})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return events[i].kind < events[j].kind
+	})
+
+	ratings := make(map[ratingKey]int)
+	played := make(map[ratingKey]int) // Matches played so far, for the K-factor strategies
+	rating := func(userID int, sport string) int {
+		if elo, ok := ratings[ratingKey{userID, sport}]; ok {
+			return elo
+		}
+		return defaults[sport]
+	}
+
+	report := &models.ELORecomputeReport{
+		Applied:         apply,
+		MatchesReplayed: len(matches),
+		SeasonResets:    len(seasons),
+		Adjustments:     len(adjustments),
+		Decays:          len(decays),
+		Changes:         []models.ELORecomputeChange{},
+	}
+	var changedMatches []*repositories.ReplayMatch
+
+	for _, event := range events {
+		switch event.kind {
+		case replaySeasonStart:
+			factor := seasons[event.index].ResetFactor
+			for key, elo := range ratings {
+				def := defaults[key.sport]
+				ratings[key] = s.eloService.Rules(key.sport).Bounds.Clamp(def + int(math.Round(float64(elo-def)*factor)))
+			}
+
+		case replayAdjustment:
+			a := adjustments[event.index]
+			ratings[ratingKey{a.UserID, a.Sport}] = s.eloService.Rules(a.Sport).Bounds.Clamp(rating(a.UserID, a.Sport) + a.NewELO - a.OldELO)
+
+		case replayDecay:
+			d := decays[event.index]
+			if elo, def := rating(d.UserID, d.Sport), defaults[d.Sport]; elo > def {
+				ratings[ratingKey{d.UserID, d.Sport}] = max(def, elo+d.Delta)
+			}
+
+		case replayMatch:
+			m := &matches[event.index]
+			calculate := s.eloService.CalculateELO
+			if m.Result == models.ResultForfeit {
+				calculate = s.eloService.CalculateForfeitELO
+			}
+
+			key1, key2 := ratingKey{m.Player1ID, m.Sport}, ratingKey{m.Player2ID, m.Sport}
+			player1Before, player2Before := rating(m.Player1ID, m.Sport), rating(m.Player2ID, m.Sport)
+			player1K := s.eloService.KFactor(m.Sport, player1Before, played[key1])
+			player2K := s.eloService.KFactor(m.Sport, player2Before, played[key2])
+			player1After, player2After, player1Delta, player2Delta := calculate(
+				ELOPlayer{ELO: player1Before, KFactor: player1K, Score: m.Player1Score},
+				ELOPlayer{ELO: player2Before, KFactor: player2K, Score: m.Player2Score},
+				m.WinnerID == m.Player1ID, s.eloService.Rules(m.Sport))
+			ratings[key1] = player1After
+			ratings[key2] = player2After
+			played[key1]++
+			played[key2]++
+
+			if !sameInts(
+				[]*int{m.Player1Before, m.Player1After, m.Player1Delta, m.Player1KFactor, m.Player2Before, m.Player2After, m.Player2Delta, m.Player2KFactor},
+				[]int{player1Before, player1After, player1Delta, player1K, player2Before, player2After, player2Delta, player2K},
+			) {
+				m.Player1Before, m.Player1After, m.Player1Delta, m.Player1KFactor = &player1Before, &player1After, &player1Delta, &player1K
+				m.Player2Before, m.Player2After, m.Player2Delta, m.Player2KFactor = &player2Before, &player2After, &player2Delta, &player2K
+				changedMatches = append(changedMatches, m)
+			}
+		}
+	}
+	report.MatchesChanged = len(changedMatches)
+
+	// Compare the replayed ratings with the stored ones; players without a row yet get one
+	logins := make(map[int]string)
+	stored := make(map[ratingKey]int, len(current))
+	for _, r := range current {
+		stored[ratingKey{r.UserID, r.Sport}] = r.ELO
+		logins[r.UserID] = r.Login
+	}
+	for key := range ratings {
+		if _, ok := stored[key]; !ok {
+			stored[key] = defaults[key.sport]
+		}
+	}
+	for key, old := range stored {
+		replayed := rating(key.userID, key.sport)
+		if replayed == old {
+			continue
+		}
+		report.Changes = append(report.Changes, models.ELORecomputeChange{
+			UserID: key.userID,
+			Login:  logins[key.userID],
+			Sport:  key.sport,
+			OldELO: old,
+			NewELO: replayed,
+			Delta:  replayed - old,
+		})
+	}
+	sort.Slice(report.Changes, func(i, j int) bool {
+		a, b := report.Changes[i], report.Changes[j]
+		if a.Sport != b.Sport {
+			return a.Sport < b.Sport
+		}
+		if abs(a.Delta) != abs(b.Delta) {
+			return abs(a.Delta) > abs(b.Delta)
+		}
+		return a.UserID < b.UserID
+	})
+
+	if !apply {
+		return report, nil
+	}
+
+	for _, m := range changedMatches {
+		if err := s.replayRepo.UpdateMatchELO(ctx, tx, m); err != nil {
+			return nil, err
+		}
+	}
+	for _, change := range report.Changes {
+		if err := s.userSportsRepo.UpdateUserELO(ctx, tx, change.UserID, change.Sport, change.NewELO); err != nil {
+			return nil, fmt.Errorf("failed to update rating of user %d: %w", change.UserID, err)
+		}
+		if err := s.eloHistoryRepo.Record(ctx, tx, &models.ELOHistoryEntry{
+			UserID:    change.UserID,
+			Sport:     change.Sport,
+			ELOBefore: change.OldELO,
+			ELOAfter:  change.NewELO,
+			Source:    models.ELOSourceCorrection,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// sameInts reports whether the stored values equal the replayed ones; missing values differ
+func sameInts(stored []*int, replayed []int) bool {
+	for i := range stored {
+		if stored[i] == nil || *stored[i] != replayed[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
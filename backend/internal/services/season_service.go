@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultResetFactor halves every player's distance to the default ELO at season start
+const defaultResetFactor = 0.5
+
+// SeasonService opens and closes seasons and serves archived standings
+type SeasonService struct {
+	db           *sql.DB
+	seasonRepo   repositories.SeasonStore
+	matchService *MatchService
+	sportService *SportService
+}
+
+// NewSeasonService creates a new SeasonService instance
+func NewSeasonService(
+	db *sql.DB,
+	seasonRepo repositories.SeasonStore,
+	matchService *MatchService,
+	sportService *SportService,
+) *SeasonService {
+	return &SeasonService{
+		db:           db,
+		seasonRepo:   seasonRepo,
+		matchService: matchService,
+		sportService: sportService,
+	}
+}
+
+// ListSeasons returns all seasons, newest first
+func (s *SeasonService) ListSeasons(ctx context.Context) ([]models.Season, error) {
+	ctx, span := tracer.Start(ctx, "SeasonService.ListSeasons")
+	defer span.End()
+
+	return s.seasonRepo.List(ctx)
+}
+
+// OpenSeason starts a new season and soft-resets ELO for all players
+// Fails if another season is still open
+func (s *SeasonService) OpenSeason(ctx context.Context, name string, resetFactor *float64, adminID int) (*models.Season, error) {
+	ctx, span := tracer.Start(ctx, "SeasonService.OpenSeason")
+	defer span.End()
+
+	open, err := s.seasonRepo.GetOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, fmt.Errorf("season %q is still open", open.Name)
+	}
+
+	factor := defaultResetFactor
+	if resetFactor != nil {
+		factor = *resetFactor
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	season := &models.Season{
+		Name:        name,
+		ResetFactor: factor,
+		OpenedBy:    &adminID,
+	}
+	if err := s.seasonRepo.Create(ctx, tx, season); err != nil {
+		return nil, err
+	}
+
+	reset, err := s.seasonRepo.SoftResetELO(ctx, tx, factor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.matchService.RefreshRankings(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit season: %w", err)
+	}
+
+	s.matchService.LeaderboardChanged()
+	slog.Info("Season opened", "season", season.Name, "reset_factor", factor, "ratings_reset", reset)
+
+	return season, nil
+}
+
+// CloseSeason ends a season and archives the final standings of every active sport
+func (s *SeasonService) CloseSeason(ctx context.Context, seasonID, adminID int) (*models.Season, error) {
+	ctx, span := tracer.Start(ctx, "SeasonService.CloseSeason")
+	defer span.End()
+
+	// The close runs to completion even if the admin's request goes away, and reads the
+	// primary so the archived standings include every confirmation up to now
+	ctx = repositories.ReadFromPrimary(context.WithoutCancel(ctx))
+
+	season, err := s.seasonRepo.GetByID(ctx, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if season.EndedAt != nil {
+		return nil, fmt.Errorf("season is already closed")
+	}
+
+	sports, err := s.sportService.GetAllActiveSports()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fresh standings, not a cached leaderboard from before the last confirmations
+	s.matchService.InvalidateLeaderboardCache()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, sport := range sports {
+		entries, err := s.matchService.GetLeaderboard(ctx, sport.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load standings for %s: %w", sport.ID, err)
+		}
+		if err := s.seasonRepo.SaveResults(ctx, tx, season.ID, sport.ID, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.seasonRepo.Close(ctx, tx, season.ID, adminID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit season close: %w", err)
+	}
+
+	slog.Info("Season closed", "season", season.Name, "sports", len(sports))
+
+	return s.seasonRepo.GetByID(ctx, season.ID)
+}
+
+// GetSeasonLeaderboard returns the archived standings of a closed season
+func (s *SeasonService) GetSeasonLeaderboard(ctx context.Context, seasonID int, sport string) ([]models.LeaderboardEntry, error) {
+	ctx, span := tracer.Start(ctx, "SeasonService.GetSeasonLeaderboard", trace.WithAttributes(attribute.String("sport", sport)))
+	defer span.End()
+
+	season, err := s.seasonRepo.GetByID(ctx, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if season.EndedAt == nil {
+		return nil, fmt.Errorf("season is still running")
+	}
+
+	return s.seasonRepo.GetResults(ctx, season.ID, sport)
+}
@@ -0,0 +1,563 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SportConfigVersion is the format version of exported sport configurations
+const SportConfigVersion = 1
+
+// sportIDPattern limits sport IDs to what URLs and channel names can carry unescaped
+var sportIDPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// SportConfig is the portable part of a sport, copied between campuses
+// Reaction emoji are fixed app-wide and therefore not part of it
+type SportConfig struct {
+	ID                        string  `json:"id"`
+	Name                      string  `json:"name"`
+	DisplayName               string  `json:"display_name"`
+	IconURL                   *string `json:"icon_url,omitempty"`
+	DefaultELO                int     `json:"default_elo"`
+	KFactor                   int     `json:"k_factor"`
+	KFactorStrategy           string  `json:"k_factor_strategy"`
+	ProvisionalMatches        int     `json:"provisional_matches"`
+	ProvisionalKFactor        int     `json:"provisional_k_factor"`
+	HighRatingThreshold       int     `json:"high_rating_threshold"`
+	HighRatingKFactor         int     `json:"high_rating_k_factor"`
+	MarginOfVictory           bool    `json:"margin_of_victory"`
+	MaxMarginMultiplier       float64 `json:"max_margin_multiplier"`
+	PlacementMatches          int     `json:"placement_matches"`
+	RatingFloor               int     `json:"rating_floor"`
+	RatingCeiling             int     `json:"rating_ceiling"`
+	MinScore                  int     `json:"min_score"`
+	MaxScore                  int     `json:"max_score"`
+	AllowDraws                bool    `json:"allow_draws"`
+	IsActive                  bool    `json:"is_active"`
+	SortOrder                 int     `json:"sort_order"`
+	PendingMode               string  `json:"pending_mode"`
+	PendingMinIntervalSeconds int     `json:"pending_min_interval_seconds"`
+	MaxPendingPerPair         int     `json:"max_pending_per_pair"`
+}
+
+// Errors returned when managing sports
+var (
+	ErrSportNotFound      = errors.New("sport not found")
+	ErrSportExists        = errors.New("sport already exists")
+	ErrLastActiveSport    = errors.New("cannot deactivate the last active sport")
+	ErrInvalidSportConfig = errors.New("invalid sport configuration")
+)
+
+// SportConfigExport is the document written by ExportConfigs and read by ImportConfigs
+type SportConfigExport struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Sports     []SportConfig `json:"sports"`
+}
+
+// SportImportResult lists which sports an import created, changed or left alone
+type SportImportResult struct {
+	DryRun    bool     `json:"dry_run"`
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+}
+
+func configOf(sport *Sport) SportConfig {
+	return SportConfig{
+		ID:                        sport.ID,
+		Name:                      sport.Name,
+		DisplayName:               sport.DisplayName,
+		IconURL:                   sport.IconURL,
+		DefaultELO:                sport.DefaultELO,
+		KFactor:                   sport.KFactor,
+		KFactorStrategy:           sport.KFactorStrategy,
+		ProvisionalMatches:        sport.ProvisionalMatches,
+		ProvisionalKFactor:        sport.ProvisionalKFactor,
+		HighRatingThreshold:       sport.HighRatingThreshold,
+		HighRatingKFactor:         sport.HighRatingKFactor,
+		MarginOfVictory:           sport.MarginOfVictory,
+		MaxMarginMultiplier:       sport.MaxMarginMultiplier,
+		PlacementMatches:          sport.PlacementMatches,
+		RatingFloor:               sport.RatingFloor,
+		RatingCeiling:             sport.RatingCeiling,
+		MinScore:                  sport.MinScore,
+		MaxScore:                  sport.MaxScore,
+		AllowDraws:                sport.AllowDraws,
+		IsActive:                  sport.IsActive,
+		SortOrder:                 sport.SortOrder,
+		PendingMode:               sport.PendingMode,
+		PendingMinIntervalSeconds: sport.PendingMinIntervalSeconds,
+		MaxPendingPerPair:         sport.MaxPendingPerPair,
+	}
+}
+
+// ExportConfigs returns the configuration of all sports, including inactive ones
+func (s *SportService) ExportConfigs() (*SportConfigExport, error) {
+	if err := s.ensureCacheFresh(); err != nil {
+		return nil, err
+	}
+
+	s.cacheMutex.RLock()
+	defer s.cacheMutex.RUnlock()
+
+	export := &SportConfigExport{
+		Version:    SportConfigVersion,
+		ExportedAt: time.Now().UTC(),
+		Sports:     make([]SportConfig, 0, len(s.cacheList)),
+	}
+	for _, sport := range s.cacheList {
+		export.Sports = append(export.Sports, configOf(sport))
+	}
+	return export, nil
+}
+
+// ImportConfigs creates or updates sports from an export in one transaction
+// Sports missing from the import are left untouched; dryRun reports the changes without saving them
+func (s *SportService) ImportConfigs(ctx context.Context, export *SportConfigExport, dryRun bool) (*SportImportResult, error) {
+	ctx, span := tracer.Start(ctx, "SportService.ImportConfigs")
+	defer span.End()
+
+	if export.Version != SportConfigVersion {
+		return nil, fmt.Errorf("unsupported export version %d", export.Version)
+	}
+	if len(export.Sports) == 0 {
+		return nil, fmt.Errorf("export contains no sports")
+	}
+
+	seen := make(map[string]bool, len(export.Sports))
+	for i := range export.Sports {
+		// Exports from before K-factor strategies keep the fixed K-factor
+		if export.Sports[i].KFactorStrategy == "" {
+			export.Sports[i].SetKFactorDefaults()
+		}
+		// Exports from before margin-of-victory weighting leave it off, with the default cap
+		if export.Sports[i].MaxMarginMultiplier == 0 {
+			export.Sports[i].MaxMarginMultiplier = defaultMaxMarginMultiplier
+		}
+		// Exports from before rating bounds get the default floor and ceiling
+		if export.Sports[i].RatingCeiling == 0 {
+			export.Sports[i].RatingFloor, export.Sports[i].RatingCeiling = defaultRatingFloor, defaultRatingCeiling
+		}
+		config := export.Sports[i]
+		if err := validateSportConfig(config); err != nil {
+			return nil, err
+		}
+		if seen[config.ID] {
+			return nil, fmt.Errorf("sport %s appears more than once", config.ID)
+		}
+		seen[config.ID] = true
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &SportImportResult{DryRun: dryRun, Created: []string{}, Updated: []string{}, Unchanged: []string{}}
+	for _, config := range export.Sports {
+		existing, err := loadSportConfigForUpdate(ctx, tx, config.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case existing == nil:
+			if err := insertSportConfig(ctx, tx, config); err != nil {
+				return nil, err
+			}
+			result.Created = append(result.Created, config.ID)
+		case sameSportConfig(*existing, config):
+			result.Unchanged = append(result.Unchanged, config.ID)
+		default:
+			if err := updateSportConfig(ctx, tx, config); err != nil {
+				return nil, err
+			}
+			result.Updated = append(result.Updated, config.ID)
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if changed := append(append([]string{}, result.Created...), result.Updated...); len(changed) > 0 {
+		if _, err := s.rankings.Refresh(ctx, tx, changed...); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.InvalidateCache()
+	return result, nil
+}
+
+// GetSportConfig returns the configuration of a sport, including inactive ones
+func (s *SportService) GetSportConfig(sportID string) (*SportConfig, error) {
+	if err := s.ensureCacheFresh(); err != nil {
+		return nil, err
+	}
+
+	s.cacheMutex.RLock()
+	defer s.cacheMutex.RUnlock()
+
+	sport, exists := s.cache[sportID]
+	if !exists {
+		return nil, ErrSportNotFound
+	}
+	config := configOf(sport)
+	return &config, nil
+}
+
+// CreateSport adds a sport; without a sort_order it is placed after all existing sports
+func (s *SportService) CreateSport(ctx context.Context, config SportConfig) error {
+	ctx, span := tracer.Start(ctx, "SportService.CreateSport")
+	defer span.End()
+
+	if err := validateSportConfig(config); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSportConfig, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	existing, err := loadSportConfigForUpdate(ctx, tx, config.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrSportExists
+	}
+
+	if config.SortOrder == 0 {
+		if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(sort_order), 0) + 1 FROM sports`).Scan(&config.SortOrder); err != nil {
+			return fmt.Errorf("failed to place sport: %w", err)
+		}
+	}
+	if err := insertSportConfig(ctx, tx, config); err != nil {
+		return err
+	}
+	if _, err := s.rankings.Refresh(ctx, tx, config.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.InvalidateCache()
+	return nil
+}
+
+// UpdateSport replaces the configuration of a sport and returns the previous one
+func (s *SportService) UpdateSport(ctx context.Context, config SportConfig) (*SportConfig, error) {
+	ctx, span := tracer.Start(ctx, "SportService.UpdateSport")
+	defer span.End()
+
+	if err := validateSportConfig(config); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSportConfig, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	existing, err := loadSportConfigForUpdate(ctx, tx, config.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrSportNotFound
+	}
+	if existing.IsActive && !config.IsActive {
+		if err := checkOtherActiveSport(ctx, tx, config.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if !sameSportConfig(*existing, config) {
+		if err := updateSportConfig(ctx, tx, config); err != nil {
+			return nil, err
+		}
+		if _, err := s.rankings.Refresh(ctx, tx, config.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.InvalidateCache()
+	return existing, nil
+}
+
+// DeactivateSport hides a sport from submission and the leaderboards
+// Its matches and ratings are kept, so reactivating it through UpdateSport restores everything
+// Returns false if the sport was already inactive
+func (s *SportService) DeactivateSport(ctx context.Context, sportID string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "SportService.DeactivateSport", trace.WithAttributes(attribute.String("sport", sportID)))
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	existing, err := loadSportConfigForUpdate(ctx, tx, sportID)
+	if err != nil {
+		return false, err
+	}
+	if existing == nil {
+		return false, ErrSportNotFound
+	}
+	if !existing.IsActive {
+		return false, nil
+	}
+	if err := checkOtherActiveSport(ctx, tx, sportID); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE sports SET is_active = FALSE, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, sportID); err != nil {
+		return false, fmt.Errorf("failed to deactivate sport %s: %w", sportID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	s.InvalidateCache()
+	return true, nil
+}
+
+// ReorderSports moves the given sports to the front in that order; sports not listed
+// follow in their previous order. Returns the IDs of all sports in the new order
+func (s *SportService) ReorderSports(ctx context.Context, sportIDs []string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "SportService.ReorderSports")
+	defer span.End()
+
+	if len(sportIDs) == 0 {
+		return nil, fmt.Errorf("%w: sport_ids must not be empty", ErrInvalidSportConfig)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, sort_order FROM sports ORDER BY sort_order, name FOR UPDATE`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sports: %w", err)
+	}
+	var current []string
+	sortOrders := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var sortOrder int
+		if err := rows.Scan(&id, &sortOrder); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan sport: %w", err)
+		}
+		current = append(current, id)
+		sortOrders[id] = sortOrder
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sports: %w", err)
+	}
+
+	order := make([]string, 0, len(current))
+	listed := make(map[string]bool, len(sportIDs))
+	for _, id := range sportIDs {
+		if _, exists := sortOrders[id]; !exists {
+			return nil, fmt.Errorf("%w: %s", ErrSportNotFound, id)
+		}
+		if listed[id] {
+			return nil, fmt.Errorf("%w: sport %s appears more than once", ErrInvalidSportConfig, id)
+		}
+		listed[id] = true
+		order = append(order, id)
+	}
+	for _, id := range current {
+		if !listed[id] {
+			order = append(order, id)
+		}
+	}
+
+	for i, id := range order {
+		if sortOrders[id] == i+1 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE sports SET sort_order = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, id, i+1); err != nil {
+			return nil, fmt.Errorf("failed to reorder sport %s: %w", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.InvalidateCache()
+	return order, nil
+}
+
+// checkOtherActiveSport refuses to deactivate a sport if no other sport stays active
+// The active sports are locked so two deactivations cannot both pass the check
+func checkOtherActiveSport(ctx context.Context, tx *sql.Tx, sportID string) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM sports WHERE is_active AND id <> $1 FOR UPDATE`, sportID)
+	if err != nil {
+		return fmt.Errorf("failed to check active sports: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to check active sports: %w", err)
+		}
+		return ErrLastActiveSport
+	}
+	return nil
+}
+
+// validateSportConfig checks an imported sport before it reaches the database constraints
+func validateSportConfig(c SportConfig) error {
+	switch {
+	case !sportIDPattern.MatchString(c.ID):
+		return fmt.Errorf("sport id %q must be 2-50 lowercase letters, digits or underscores", c.ID)
+	case c.Name == "" || len(c.Name) > 100:
+		return fmt.Errorf("sport %s: name must be 1-100 characters", c.ID)
+	case c.DisplayName == "" || len(c.DisplayName) > 100:
+		return fmt.Errorf("sport %s: display_name must be 1-100 characters", c.ID)
+	case c.IconURL != nil && len(*c.IconURL) > 255:
+		return fmt.Errorf("sport %s: icon_url must be at most 255 characters", c.ID)
+	case c.DefaultELO < 100 || c.DefaultELO > 3000:
+		return fmt.Errorf("sport %s: default_elo must be between 100 and 3000", c.ID)
+	case c.KFactor < 1 || c.KFactor > 100:
+		return fmt.Errorf("sport %s: k_factor must be between 1 and 100", c.ID)
+	case c.KFactorStrategy != KFactorFixed && c.KFactorStrategy != KFactorTiered:
+		return fmt.Errorf("sport %s: k_factor_strategy must be %q or %q", c.ID, KFactorFixed, KFactorTiered)
+	case c.ProvisionalMatches < 0 || c.ProvisionalMatches > 100:
+		return fmt.Errorf("sport %s: provisional_matches must be between 0 and 100", c.ID)
+	case c.ProvisionalKFactor < 1 || c.ProvisionalKFactor > 100:
+		return fmt.Errorf("sport %s: provisional_k_factor must be between 1 and 100", c.ID)
+	case c.HighRatingThreshold < 100 || c.HighRatingThreshold > 5000:
+		return fmt.Errorf("sport %s: high_rating_threshold must be between 100 and 5000", c.ID)
+	case c.HighRatingKFactor < 1 || c.HighRatingKFactor > 100:
+		return fmt.Errorf("sport %s: high_rating_k_factor must be between 1 and 100", c.ID)
+	case c.MaxMarginMultiplier < 1 || c.MaxMarginMultiplier > 3:
+		return fmt.Errorf("sport %s: max_margin_multiplier must be between 1 and 3", c.ID)
+	case c.PlacementMatches < 0 || c.PlacementMatches > 50:
+		return fmt.Errorf("sport %s: placement_matches must be between 0 and 50", c.ID)
+	case c.RatingFloor < 0 || c.RatingCeiling > 10000 || c.RatingFloor >= c.RatingCeiling:
+		return fmt.Errorf("sport %s: ratings must satisfy 0 <= rating_floor < rating_ceiling <= 10000", c.ID)
+	case c.DefaultELO < c.RatingFloor || c.DefaultELO > c.RatingCeiling:
+		return fmt.Errorf("sport %s: default_elo must be between rating_floor and rating_ceiling", c.ID)
+	case c.MinScore < utils.MinScoreValue || c.MaxScore <= c.MinScore || c.MaxScore > utils.MaxScoreValue:
+		return fmt.Errorf("sport %s: scores must satisfy %d <= min_score < max_score <= %d", c.ID, utils.MinScoreValue, utils.MaxScoreValue)
+	case c.PendingMode != PendingModeStrict && c.PendingMode != PendingModeMultiple:
+		return fmt.Errorf("sport %s: pending_mode must be %q or %q", c.ID, PendingModeStrict, PendingModeMultiple)
+	case c.PendingMinIntervalSeconds < 0:
+		return fmt.Errorf("sport %s: pending_min_interval_seconds must not be negative", c.ID)
+	case c.MaxPendingPerPair < 1:
+		return fmt.Errorf("sport %s: max_pending_per_pair must be at least 1", c.ID)
+	}
+	return nil
+}
+
+func sameSportConfig(a, b SportConfig) bool {
+	iconA, iconB := "", ""
+	if a.IconURL != nil {
+		iconA = *a.IconURL
+	}
+	if b.IconURL != nil {
+		iconB = *b.IconURL
+	}
+	a.IconURL, b.IconURL = nil, nil
+	return a == b && iconA == iconB
+}
+
+func loadSportConfigForUpdate(ctx context.Context, tx *sql.Tx, id string) (*SportConfig, error) {
+	c := &SportConfig{}
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, name, display_name, icon_url, default_elo, k_factor,
+		       k_factor_strategy, provisional_matches, provisional_k_factor,
+		       high_rating_threshold, high_rating_k_factor,
+		       margin_of_victory, max_margin_multiplier, placement_matches,
+		       rating_floor, rating_ceiling,
+		       min_score, max_score, allow_draws, is_active, sort_order,
+		       pending_mode, pending_min_interval_seconds, max_pending_per_pair
+		FROM sports WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(
+		&c.ID, &c.Name, &c.DisplayName, &c.IconURL, &c.DefaultELO, &c.KFactor,
+		&c.KFactorStrategy, &c.ProvisionalMatches, &c.ProvisionalKFactor,
+		&c.HighRatingThreshold, &c.HighRatingKFactor,
+		&c.MarginOfVictory, &c.MaxMarginMultiplier, &c.PlacementMatches,
+		&c.RatingFloor, &c.RatingCeiling,
+		&c.MinScore, &c.MaxScore, &c.AllowDraws, &c.IsActive, &c.SortOrder,
+		&c.PendingMode, &c.PendingMinIntervalSeconds, &c.MaxPendingPerPair,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sport %s: %w", id, err)
+	}
+	return c, nil
+}
+
+func insertSportConfig(ctx context.Context, tx *sql.Tx, c SportConfig) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO sports (id, name, display_name, icon_url, default_elo, k_factor,
+		                    min_score, max_score, is_active, sort_order,
+		                    pending_mode, pending_min_interval_seconds, max_pending_per_pair, allow_draws,
+		                    k_factor_strategy, provisional_matches, provisional_k_factor,
+		                    high_rating_threshold, high_rating_k_factor,
+		                    margin_of_victory, max_margin_multiplier, placement_matches,
+		                    rating_floor, rating_ceiling)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+	`, c.ID, c.Name, c.DisplayName, c.IconURL, c.DefaultELO, c.KFactor,
+		c.MinScore, c.MaxScore, c.IsActive, c.SortOrder,
+		c.PendingMode, c.PendingMinIntervalSeconds, c.MaxPendingPerPair, c.AllowDraws,
+		c.KFactorStrategy, c.ProvisionalMatches, c.ProvisionalKFactor, c.HighRatingThreshold, c.HighRatingKFactor,
+		c.MarginOfVictory, c.MaxMarginMultiplier, c.PlacementMatches, c.RatingFloor, c.RatingCeiling)
+	if err != nil {
+		return fmt.Errorf("failed to create sport %s: %w", c.ID, err)
+	}
+	return nil
+}
+
+func updateSportConfig(ctx context.Context, tx *sql.Tx, c SportConfig) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE sports SET
+			name = $2, display_name = $3, icon_url = $4, default_elo = $5, k_factor = $6,
+			min_score = $7, max_score = $8, is_active = $9, sort_order = $10,
+			pending_mode = $11, pending_min_interval_seconds = $12, max_pending_per_pair = $13,
+			allow_draws = $14, k_factor_strategy = $15, provisional_matches = $16, provisional_k_factor = $17,
+			high_rating_threshold = $18, high_rating_k_factor = $19,
+			margin_of_victory = $20, max_margin_multiplier = $21, placement_matches = $22,
+			rating_floor = $23, rating_ceiling = $24, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, c.ID, c.Name, c.DisplayName, c.IconURL, c.DefaultELO, c.KFactor,
+		c.MinScore, c.MaxScore, c.IsActive, c.SortOrder,
+		c.PendingMode, c.PendingMinIntervalSeconds, c.MaxPendingPerPair, c.AllowDraws,
+		c.KFactorStrategy, c.ProvisionalMatches, c.ProvisionalKFactor, c.HighRatingThreshold, c.HighRatingKFactor,
+		c.MarginOfVictory, c.MaxMarginMultiplier, c.PlacementMatches, c.RatingFloor, c.RatingCeiling)
+	if err != nil {
+		return fmt.Errorf("failed to update sport %s: %w", c.ID, err)
+	}
+	return nil
+}
@@ -1,31 +1,72 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/cache"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+)
+
+const (
+	// sportsCacheKey holds all sports in the shared cache
+	sportsCacheKey = "sports:all"
+	// sportsSharedTTL bounds how long the shared cache serves sports without the database
+	sportsSharedTTL = 5 * time.Minute
+	// sportsLocalTTL bounds how long an instance misses changes made on another instance
+	sportsLocalTTL = 30 * time.Second
 )
 
 // Sport represents a sport configuration from the database
 type Sport struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	DisplayName string    `json:"display_name"`
-	IconURL     *string   `json:"icon_url,omitempty"`
-	DefaultELO  int       `json:"default_elo"`
-	KFactor     int       `json:"k_factor"`
-	MinScore    int       `json:"min_score"`
-	MaxScore    int       `json:"max_score"`
-	IsActive    bool      `json:"is_active"`
-	SortOrder   int       `json:"sort_order"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                        string    `json:"id"`
+	Name                      string    `json:"name"`
+	DisplayName               string    `json:"display_name"`
+	IconURL                   *string   `json:"icon_url,omitempty"`
+	DefaultELO                int       `json:"default_elo"`
+	KFactor                   int       `json:"k_factor"`
+	// K-factor strategy (see KFactorFixed / KFactorTiered)
+	KFactorStrategy           string    `json:"k_factor_strategy"`
+	ProvisionalMatches        int       `json:"provisional_matches"`
+	ProvisionalKFactor        int       `json:"provisional_k_factor"`
+	HighRatingThreshold       int       `json:"high_rating_threshold"`
+	HighRatingKFactor         int       `json:"high_rating_k_factor"`
+	// Margin-of-victory weighting (see MarginRule)
+	MarginOfVictory           bool      `json:"margin_of_victory"`
+	MaxMarginMultiplier       float64   `json:"max_margin_multiplier"`
+	// Matches a player needs before they are ranked on the leaderboard
+	PlacementMatches          int       `json:"placement_matches"`
+	// Ratings are clamped to [RatingFloor, RatingCeiling] (see RatingBounds)
+	RatingFloor               int       `json:"rating_floor"`
+	RatingCeiling             int       `json:"rating_ceiling"`
+	MinScore                  int       `json:"min_score"`
+	MaxScore                  int       `json:"max_score"`
+	AllowDraws                bool      `json:"allow_draws"`
+	IsActive                  bool      `json:"is_active"`
+	SortOrder                 int       `json:"sort_order"`
+	// Pending match rules (see PendingModeStrict / PendingModeMultiple)
+	PendingMode               string    `json:"pending_mode"`
+	PendingMinIntervalSeconds int       `json:"pending_min_interval_seconds"`
+	MaxPendingPerPair         int       `json:"max_pending_per_pair"`
+	CreatedAt                 time.Time `json:"created_at"`
+	UpdatedAt                 time.Time `json:"updated_at"`
 }
 
+// Pending match modes
+const (
+	PendingModeStrict   = "strict"   // One pending match per pair of players
+	PendingModeMultiple = "multiple" // Several pending matches per pair, spaced in time
+)
+
 // SportService manages sport configurations with in-memory caching
+// The in-memory copy is refreshed from the shared cache, and from the database on a miss
 type SportService struct {
 	db           *sql.DB
+	rankings     repositories.RankingStore // re-ranks a sport whose placement or default rating changed
+	shared       cache.Cache
 	cache        map[string]*Sport
 	cacheList    []*Sport
 	cacheMutex   sync.RWMutex
@@ -34,11 +75,13 @@ type SportService struct {
 }
 
 // NewSportService creates a new SportService instance
-func NewSportService(db *sql.DB) *SportService {
+func NewSportService(db *sql.DB, rankings repositories.RankingStore, shared cache.Cache) *SportService {
 	return &SportService{
 		db:       db,
+		rankings: rankings,
+		shared:   shared,
 		cache:    make(map[string]*Sport),
-		cacheTTL: 5 * time.Minute,
+		cacheTTL: sportsLocalTTL,
 	}
 }
 
@@ -128,20 +171,32 @@ func (s *SportService) refreshCache() error {
 		return nil
 	}
 
+	var shared []*Sport
+	if cache.GetJSON(s.shared, sportsCacheKey, &shared) && len(shared) > 0 {
+		s.setCache(shared)
+		return nil
+	}
+
 	query := `
 		SELECT id, name, display_name, icon_url, default_elo, k_factor,
-		       min_score, max_score, is_active, sort_order, created_at, updated_at
+		       k_factor_strategy, provisional_matches, provisional_k_factor,
+		       high_rating_threshold, high_rating_k_factor,
+		       margin_of_victory, max_margin_multiplier, placement_matches,
+		       rating_floor, rating_ceiling,
+		       min_score, max_score, allow_draws, is_active, sort_order,
+		       pending_mode, pending_min_interval_seconds, max_pending_per_pair,
+		       created_at, updated_at
 		FROM sports
 		ORDER BY sort_order, name
 	`
 
-	rows, err := s.db.Query(query)
+	// The cache is shared by all callers, so the load does not end with the request that started it
+	rows, err := s.db.QueryContext(context.Background(), query)
 	if err != nil {
 		return fmt.Errorf("failed to load sports: %w", err)
 	}
 	defer rows.Close()
 
-	newCache := make(map[string]*Sport)
 	var newCacheList []*Sport
 
 	for rows.Next() {
@@ -153,17 +208,30 @@ func (s *SportService) refreshCache() error {
 			&sport.IconURL,
 			&sport.DefaultELO,
 			&sport.KFactor,
+			&sport.KFactorStrategy,
+			&sport.ProvisionalMatches,
+			&sport.ProvisionalKFactor,
+			&sport.HighRatingThreshold,
+			&sport.HighRatingKFactor,
+			&sport.MarginOfVictory,
+			&sport.MaxMarginMultiplier,
+			&sport.PlacementMatches,
+			&sport.RatingFloor,
+			&sport.RatingCeiling,
 			&sport.MinScore,
 			&sport.MaxScore,
+			&sport.AllowDraws,
 			&sport.IsActive,
 			&sport.SortOrder,
+			&sport.PendingMode,
+			&sport.PendingMinIntervalSeconds,
+			&sport.MaxPendingPerPair,
 			&sport.CreatedAt,
 			&sport.UpdatedAt,
 		); err != nil {
 			return fmt.Errorf("failed to scan sport: %w", err)
 		}
 
-		newCache[sport.ID] = sport
 		newCacheList = append(newCacheList, sport)
 	}
 
@@ -171,15 +239,28 @@ func (s *SportService) refreshCache() error {
 		return fmt.Errorf("error iterating sports: %w", err)
 	}
 
-	s.cache = newCache
-	s.cacheList = newCacheList
-	s.cacheExpiry = time.Now().Add(s.cacheTTL)
+	cache.SetJSON(s.shared, sportsCacheKey, newCacheList, sportsSharedTTL)
+	s.setCache(newCacheList)
 
 	return nil
 }
 
+// setCache replaces the in-memory copy; must be called with the write lock held
+func (s *SportService) setCache(sports []*Sport) {
+	newCache := make(map[string]*Sport, len(sports))
+	for _, sport := range sports {
+		newCache[sport.ID] = sport
+	}
+	s.cache = newCache
+	s.cacheList = sports
+	s.cacheExpiry = time.Now().Add(s.cacheTTL)
+}
+
 // InvalidateCache forces a cache refresh on the next request
+// Other instances pick up the change within sportsLocalTTL
 func (s *SportService) InvalidateCache() {
+	s.shared.Delete(sportsCacheKey)
+
 	s.cacheMutex.Lock()
 	defer s.cacheMutex.Unlock()
 	s.cacheExpiry = time.Time{} // Set to zero time to force refresh
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
+	"github.com/42heilbronn/elo-leaderboard/internal/repositories"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StatsService assembles player profiles from the user_sports aggregates and match history
+type StatsService struct {
+	matchRepo      repositories.MatchStore
+	userRepo       repositories.UserStore
+	userSportsRepo repositories.UserSportsStore
+	sportService   *SportService
+}
+
+// NewStatsService creates a new StatsService instance
+func NewStatsService(
+	matchRepo repositories.MatchStore,
+	userRepo repositories.UserStore,
+	userSportsRepo repositories.UserSportsStore,
+	sportService *SportService,
+) *StatsService {
+	return &StatsService{
+		matchRepo:      matchRepo,
+		userRepo:       userRepo,
+		userSportsRepo: userSportsRepo,
+		sportService:   sportService,
+	}
+}
+
+// GetPlayerProfile returns a player's stats for every active sport, or only the given one
+// Sports the player hasn't played yet are reported with the sport's default ELO
+func (s *StatsService) GetPlayerProfile(ctx context.Context, userID int, sport *string) ([]models.PlayerStats, error) {
+	ctx, span := tracer.Start(ctx, "StatsService.GetPlayerProfile", trace.WithAttributes(attribute.Int("user.id", userID)))
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	sports, err := s.sportService.GetAllActiveSports()
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := s.userSportsRepo.GetAllUserSports(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rivals, err := s.matchRepo.GetMostPlayedRivals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	forfeits, err := s.matchRepo.GetForfeitCounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := []models.PlayerStats{}
+	for _, sp := range sports {
+		if sport != nil && sp.ID != *sport {
+			continue
+		}
+
+		stats := models.PlayerStats{
+			User:       *user,
+			Sport:      sp.ID,
+			CurrentELO: sp.DefaultELO,
+			HighestELO: sp.DefaultELO,
+		}
+
+		if data, ok := aggregates[sp.ID]; ok {
+			stats.CurrentELO = data.CurrentELO
+			stats.HighestELO = data.HighestELO
+			stats.TotalMatches = data.MatchesPlayed
+			stats.Wins = data.Wins
+			stats.Losses = data.Losses
+			stats.LongestWinStreak = data.LongestWinStreak
+			if data.CurrentStreak > 0 {
+				stats.CurrentWinStreak = data.CurrentStreak
+			}
+		}
+		if stats.TotalMatches > 0 {
+			stats.WinRate = float64(stats.Wins) / float64(stats.TotalMatches) * 100
+		}
+
+		if rival, ok := rivals[sp.ID]; ok {
+			rivalUser := rival.User
+			stats.MostPlayedRival = &rivalUser
+			stats.RivalMatchCount = rival.Matches
+		}
+
+		if count, ok := forfeits[sp.ID]; ok {
+			stats.ForfeitWins = count.Wins
+			stats.ForfeitLosses = count.Losses
+		}
+
+		profile = append(profile, stats)
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,7 @@
+package services
+
+import "go.opentelemetry.io/otel"
+
+// tracer starts the spans of service calls; they nest under the span of the request or job
+// and contain the spans of the calls' SQL queries
+var tracer = otel.Tracer("github.com/42heilbronn/elo-leaderboard/internal/services")
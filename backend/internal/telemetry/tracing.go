@@ -0,0 +1,70 @@
+// Package telemetry exports OpenTelemetry traces of requests, service calls, SQL queries
+// and calls to the 42 API to an OTLP collector.
+//
+// Tracing is off unless OTEL_EXPORTER_OTLP_ENDPOINT is set. The instrumentation stays in
+// place either way; without a tracer provider its spans are dropped right away.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Config selects where traces go and how many are kept
+type Config struct {
+	Endpoint    string  // OTLP/HTTP collector URL; empty disables tracing
+	ServiceName string  // service.name of the traces
+	SampleRate  float64 // fraction of new traces recorded
+}
+
+// Setup installs the global tracer provider and W3C trace context propagation
+// The returned function flushes pending spans and stops the exporter; it is a no-op when
+// tracing is disabled. The exporter also reads the other OTEL_EXPORTER_OTLP_* variables,
+// e.g. OTEL_EXPORTER_OTLP_HEADERS for the collector's credentials
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// Without options the exporter takes OTEL_EXPORTER_OTLP_ENDPOINT and appends /v1/traces
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe service: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRate))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Transport wraps base, or http.DefaultTransport if nil, so outgoing requests are traced
+// and carry the trace context
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MIMECSV is the media type list endpoints serve when CSV is requested
+const MIMECSV = "text/csv"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// csvColumn maps a CSV header to a (possibly nested) struct field
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// WantsCSV reports whether the client asked for CSV via ?format=csv or Accept: text/csv
+// The query parameter wins so plain download links work without custom headers
+func WantsCSV(c *gin.Context) bool {
+	if format := c.Query("format"); format != "" {
+		return format == "csv"
+	}
+	return c.NegotiateFormat(gin.MIMEJSON, MIMECSV) == MIMECSV
+}
+
+// RespondWithList sends a slice of structs as CSV or JSON depending on the request
+// filename is used for the CSV download, without date or extension
+func RespondWithList(c *gin.Context, code int, filename string, rows interface{}) {
+	c.Header("Vary", "Accept")
+	if !WantsCSV(c) {
+		RespondWithJSON(c, code, rows)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeCSV(&buf, rows); err != nil {
+		RespondWithError(c, http.StatusInternalServerError, "failed to encode csv", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s_%s.csv", filename, time.Now().Format("2006-01-02")))
+	c.Data(code, MIMECSV+"; charset=utf-8", buf.Bytes())
+}
+
+// EncodeCSV writes a slice of structs as CSV with one column per JSON field
+// Nested structs are flattened with their JSON name as prefix (user_login);
+// maps, slices and fields tagged json:"-" are left out
+func EncodeCSV(w io.Writer, rows interface{}) error {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("csv encoding requires a slice, got %s", v.Kind())
+	}
+
+	elemType := v.Type().Elem()
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("csv encoding requires a slice of structs, got %s", elemType)
+	}
+
+	columns := csvColumns(elemType, "", nil)
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.name
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		for j, col := range columns {
+			record[j] = csvValue(row.FieldByIndex(col.index))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvColumns lists the exportable fields of a struct type in declaration order
+func csvColumns(t reflect.Type, prefix string, parent []int) []csvColumn {
+	var columns []csvColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		index := append(append([]int{}, parent...), i)
+		fieldType := field.Type
+
+		// Embedded structs contribute their fields without a prefix
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			columns = append(columns, csvColumns(fieldType, prefix, index)...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		switch {
+		case fieldType == timeType:
+		case fieldType.Kind() == reflect.Struct:
+			columns = append(columns, csvColumns(fieldType, prefix+name+"_", index)...)
+			continue
+		case fieldType.Kind() == reflect.Map, fieldType.Kind() == reflect.Slice, fieldType.Kind() == reflect.Interface:
+			continue
+		case fieldType.Kind() == reflect.Ptr && fieldType.Elem().Kind() == reflect.Struct && fieldType.Elem() != timeType:
+			continue
+		}
+
+		columns = append(columns, csvColumn{name: prefix + name, index: index})
+	}
+	return columns
+}
+
+// csvValue formats a scalar field; nil pointers and zero times become empty cells
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return escapeCSVFormula(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		return escapeCSVFormula(fmt.Sprint(v.Interface()))
+	}
+}
+
+// escapeCSVFormula stops spreadsheets from evaluating user-controlled text as a formula
+func escapeCSVFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
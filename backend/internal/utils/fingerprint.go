@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+)
+
+// ClientFingerprint returns a privacy-conscious, non-reversible fingerprint of a client.
+// Only the network prefix of the IP (/24 for IPv4, /48 for IPv6) and a hash of the
+// user agent are used, keyed with a server secret so the raw values cannot be
+// recovered or correlated across deployments.
+func ClientFingerprint(clientIP, userAgent, secret string) string {
+	uaHash := sha256.Sum256([]byte(userAgent))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ipPrefix(clientIP)))
+	mac.Write([]byte{'|'})
+	mac.Write(uaHash[:])
+
+	// 16 bytes are plenty to distinguish devices while keeping the column small
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// ipPrefix truncates an IP address to its network prefix
+func ipPrefix(clientIP string) string {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return clientIP
+	}
+
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
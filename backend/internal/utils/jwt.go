@@ -1,31 +1,83 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// JWTLifetime is how long issued tokens stay valid - GDPR compliant session duration
+const JWTLifetime = 24 * time.Hour
+
+// KioskScope marks tokens a kiosk holds for one student; they only submit matches
+const KioskScope = "kiosk"
+
+// KioskJWTLifetime is short so a session left open on a shared tablet expires before the next players step up
+const KioskJWTLifetime = 5 * time.Minute
+
 type Claims struct {
-	UserID int `json:"user_id"`
+	UserID int    `json:"user_id"`
+	Scope  string `json:"scope,omitempty"` // Empty for full sessions, KioskScope for kiosk sessions
+	Kiosk  string `json:"kiosk,omitempty"` // Name of the kiosk a kiosk session was issued to
 	jwt.RegisteredClaims
 }
 
 func GenerateJWT(userID int, secret string) (string, error) {
-	claims := &Claims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hours - GDPR compliant session duration
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	return signJWT(&Claims{UserID: userID}, JWTLifetime, secret)
+}
+
+// GenerateKioskJWT issues a short-lived token with which the named kiosk acts for the user
+func GenerateKioskJWT(userID int, kiosk, secret string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(KioskJWTLifetime)
+	token, err := signJWT(&Claims{UserID: userID, Scope: KioskScope, Kiosk: kiosk}, KioskJWTLifetime, secret)
+	return token, expiresAt, err
+}
+
+func signJWT(claims *Claims, lifetime time.Duration, secret string) (string, error) {
+	// Unique token ID (jti) so individual tokens can be revoked
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        hex.EncodeToString(jti),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(lifetime)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
 
+// ValidateJWT accepts full sessions only; kiosk tokens are rejected so they cannot reach other routes
 func ValidateJWT(tokenString, secret string) (*Claims, error) {
+	claims, err := parseJWT(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Scope != "" {
+		return nil, fmt.Errorf("token scope %q not allowed", claims.Scope)
+	}
+	return claims, nil
+}
+
+// ValidateKioskJWT accepts kiosk sessions issued to the named kiosk only
+func ValidateKioskJWT(tokenString, kiosk, secret string) (*Claims, error) {
+	claims, err := parseJWT(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Scope != KioskScope || claims.Kiosk != kiosk {
+		return nil, fmt.Errorf("not a session of this kiosk")
+	}
+	return claims, nil
+}
+
+func parseJWT(tokenString, secret string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
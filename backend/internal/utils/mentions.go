@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxMentionsPerComment caps how many users a single comment can notify
+const MaxMentionsPerComment = 10
+
+// mentionPattern matches @login where the @ does not follow a word character (e-mail addresses)
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9][A-Za-z0-9_-]{0,31})`)
+
+// ParseMentions returns the distinct lowercased logins mentioned in a comment, in order of appearance
+// At most MaxMentionsPerComment logins are returned
+func ParseMentions(content string) []string {
+	seen := make(map[string]bool)
+	var logins []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		login := strings.ToLower(strings.TrimRight(match[1], "-_"))
+		if login == "" || seen[login] {
+			continue
+		}
+		seen[login] = true
+		logins = append(logins, login)
+		if len(logins) == MaxMentionsPerComment {
+			break
+		}
+	}
+	return logins
+}
@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"encoding/base64"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Pagination limits
@@ -99,3 +103,34 @@ func ValidatePaginationParams(limit, offset int) string {
 	}
 	return ""
 }
+
+// EncodeCursor returns an opaque keyset cursor for a feed ordered by created_at DESC, id DESC
+func EncodeCursor(createdAt time.Time, id int) string {
+	raw := fmt.Sprintf("%d:%d", createdAt.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(cursor string) (time.Time, int, error) {
+	errInvalid := fmt.Errorf("invalid cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, errInvalid
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 {
+		return time.Time{}, 0, errInvalid
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, errInvalid
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil || id < 1 {
+		return time.Time{}, 0, errInvalid
+	}
+
+	return time.Unix(0, nanos).UTC(), id, nil
+}
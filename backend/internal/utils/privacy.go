@@ -3,10 +3,11 @@ package utils
 import (
 	"crypto/md5"
 	"fmt"
+	"hash/fnv"
 )
 
-// Adjectives for generating anonymous names
-var adjectives = []string{
+// DefaultAnonymousAdjectives is the fallback vocabulary when no adjectives are configured
+var DefaultAnonymousAdjectives = []string{
 	"Swift", "Silent", "Mighty", "Clever", "Bold",
 	"Fierce", "Mystic", "Noble", "Brave", "Quick",
 	"Stealthy", "Cosmic", "Thunder", "Shadow", "Crystal",
@@ -15,8 +16,8 @@ var adjectives = []string{
 	"Crimson", "Azure", "Emerald", "Obsidian", "Jade",
 }
 
-// Animals for generating anonymous names
-var animals = []string{
+// DefaultAnonymousAnimals is the fallback vocabulary when no animals are configured
+var DefaultAnonymousAnimals = []string{
 	"Penguin", "Fox", "Wolf", "Eagle", "Tiger",
 	"Dragon", "Phoenix", "Falcon", "Panther", "Bear",
 	"Hawk", "Lion", "Shark", "Cobra", "Raven",
@@ -34,13 +35,32 @@ func DefaultAvatarURL(userID int) string {
 }
 
 // GenerateAnonymousName generates a consistent anonymous name based on user ID
-// The same user ID will always get the same anonymous name
+// The same user ID will always get the same anonymous name. Names are not guaranteed to be
+// unique; use the persisted mapping (AnonymizationService) where uniqueness matters.
 func GenerateAnonymousName(userID int) string {
-	// Use modulo to pick deterministic adjective and animal based on user ID
-	adjIdx := userID % len(adjectives)
-	animalIdx := (userID * 7) % len(animals) // Multiply by prime to spread distribution
+	return AnonymousNameCandidate(userID, 0, DefaultAnonymousAdjectives, DefaultAnonymousAnimals)
+}
+
+// AnonymousNameCandidate returns the attempt-th name candidate for a user
+// Candidates start at a position derived from the user ID and walk through every
+// adjective/animal combination; once all are used a numeric suffix is appended.
+func AnonymousNameCandidate(userID, attempt int, adjectives, animals []string) string {
+	if len(adjectives) == 0 || len(animals) == 0 {
+		return fmt.Sprintf("Player %d", userID)
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(fmt.Sprintf("anon-%d", userID)))
+
+	combinations := len(adjectives) * len(animals)
+	idx := (int(h.Sum32()%uint32(combinations)) + attempt) % combinations
+	name := fmt.Sprintf("%s %s", adjectives[idx%len(adjectives)], animals[idx/len(adjectives)])
+
+	if round := attempt / combinations; round > 0 {
+		name = fmt.Sprintf("%s %d", name, round+1)
+	}
 
-	return fmt.Sprintf("%s %s", adjectives[adjIdx], animals[animalIdx])
+	return name
 }
 
 // GenerateAnonymousLogin generates a consistent anonymous login based on user ID
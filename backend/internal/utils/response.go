@@ -3,24 +3,28 @@ package utils
 import (
 	"log/slog"
 
+	"github.com/42heilbronn/elo-leaderboard/internal/logging"
 	"github.com/gin-gonic/gin"
 )
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // RespondWithError sends a JSON error response and logs the error if provided
+// The response carries the request ID so reports can be matched with the logs
 func RespondWithError(c *gin.Context, code int, message string, err error) {
+	ctx := c.Request.Context()
 	if err != nil {
-		slog.Error("Request failed",
+		slog.ErrorContext(ctx, "Request failed",
 			"method", c.Request.Method,
 			"path", c.Request.URL.Path,
 			"status", code,
 			"error", err.Error(),
 		)
 	}
-	c.JSON(code, ErrorResponse{Error: message})
+	c.JSON(code, ErrorResponse{Error: message, RequestID: logging.RequestID(ctx)})
 }
 
 // RespondWithJSON sends a JSON response
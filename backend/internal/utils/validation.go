@@ -5,12 +5,14 @@ import (
 	"regexp"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/42heilbronn/elo-leaderboard/internal/models"
 )
 
 // Validation limits
 const (
 	MinScoreValue    = 0
-	MaxScoreValue    = 999 // Reasonable max score for table tennis/football
+	MaxScoreValue    = 999 // Upper bound for any sport; each sport narrows it with its own score range
 	MinUserIDValue   = 1
 	MaxReasonLength  = 500
 	MinReasonLength  = 5
@@ -27,10 +29,12 @@ func (e *InputValidationError) Error() string {
 }
 
 // ValidateMatchSubmission validates match submission input beyond struct tags
+// Scores are held to the bounds shared by all sports; see ValidateSportScores for a sport's own range
 func ValidateMatchSubmission(sport string, opponentID, playerScore, opponentScore, submitterID int) error {
 	// Validate sport
-	if sport != "table_tennis" && sport != "table_football" {
-		return &InputValidationError{Field: "sport", Message: "must be 'table_tennis' or 'table_football'"}
+	// Whether the sport exists is checked against the sports table by the caller
+	if strings.TrimSpace(sport) == "" {
+		return &InputValidationError{Field: "sport", Message: "is required"}
 	}
 
 	// Validate opponent ID
@@ -60,6 +64,102 @@ func ValidateMatchSubmission(sport string, opponentID, playerScore, opponentScor
 	return nil
 }
 
+// ScoreRange holds the points a sport allows per game, or per set in a series
+type ScoreRange struct {
+	Sport string // Display name for error messages
+	Min   int
+	Max   int
+}
+
+// Check returns a validation error for field if score is outside the range
+func (r ScoreRange) Check(field string, score int) error {
+	if score < r.Min || score > r.Max {
+		return &InputValidationError{Field: field, Message: fmt.Sprintf("must be between %d and %d under the %s rules", r.Min, r.Max, r.Sport)}
+	}
+	return nil
+}
+
+// ValidateSportScores checks submitted scores against the score range of the sport
+// A series is checked set by set, as its scores are the number of sets won
+func ValidateSportScores(rules ScoreRange, req *models.SubmitMatchRequest) error {
+	if len(req.Sets) > 0 {
+		for i, set := range req.Sets {
+			if err := rules.Check(fmt.Sprintf("sets[%d].player_score", i), set.PlayerScore); err != nil {
+				return err
+			}
+			if err := rules.Check(fmt.Sprintf("sets[%d].opponent_score", i), set.OpponentScore); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := rules.Check("player_score", req.PlayerScore); err != nil {
+		return err
+	}
+	return rules.Check("opponent_score", req.OpponentScore)
+}
+
+// ValidateSeries checks that the sets form a complete best-of-N series that ends
+// with its deciding set, and returns the number of sets won by each side
+func ValidateSeries(bestOf int, sets []models.SetScore) (int, int, error) {
+	if bestOf != 3 && bestOf != 5 && bestOf != 7 {
+		return 0, 0, &InputValidationError{Field: "best_of", Message: "must be 3, 5 or 7"}
+	}
+	if len(sets) == 0 {
+		return 0, 0, &InputValidationError{Field: "sets", Message: "a series needs its set scores"}
+	}
+
+	needed := bestOf/2 + 1
+	playerSets, opponentSets := 0, 0
+	for i, set := range sets {
+		field := fmt.Sprintf("sets[%d]", i)
+		if playerSets == needed || opponentSets == needed {
+			return 0, 0, &InputValidationError{Field: field, Message: "was played after the series was decided"}
+		}
+		if set.PlayerScore < MinScoreValue || set.PlayerScore > MaxScoreValue ||
+			set.OpponentScore < MinScoreValue || set.OpponentScore > MaxScoreValue {
+			return 0, 0, &InputValidationError{Field: field, Message: fmt.Sprintf("scores must be between %d and %d", MinScoreValue, MaxScoreValue)}
+		}
+		if set.PlayerScore == set.OpponentScore {
+			return 0, 0, &InputValidationError{Field: field, Message: "a set cannot end in a tie"}
+		}
+
+		if set.PlayerScore > set.OpponentScore {
+			playerSets++
+		} else {
+			opponentSets++
+		}
+	}
+
+	if playerSets != needed && opponentSets != needed {
+		return 0, 0, &InputValidationError{Field: "sets", Message: fmt.Sprintf("series is not decided - a player needs %d sets to win", needed)}
+	}
+
+	return playerSets, opponentSets, nil
+}
+
+// ScoreSeries sets the scores of a series submission to the number of sets won
+// Scores given along with the sets must already be the set counts
+// Submissions without sets are left unchanged
+func ScoreSeries(req *models.SubmitMatchRequest) error {
+	if req.BestOf == 0 && len(req.Sets) == 0 {
+		return nil
+	}
+
+	playerSets, opponentSets, err := ValidateSeries(req.BestOf, req.Sets)
+	if err != nil {
+		return err
+	}
+	if (req.PlayerScore != 0 || req.OpponentScore != 0) &&
+		(req.PlayerScore != playerSets || req.OpponentScore != opponentSets) {
+		return &InputValidationError{Field: "score", Message: "must be the number of sets won"}
+	}
+
+	req.PlayerScore, req.OpponentScore = playerSets, opponentSets
+	return nil
+}
+
 // ValidateComment validates comment content beyond basic length checks
 func ValidateComment(content string) (string, error) {
 	// Check for empty after trimming
@@ -128,8 +228,9 @@ func ValidateELOAdjustment(userID int, sport string, newELO int, reason string,
 	}
 
 	// Validate sport
-	if sport != "table_tennis" && sport != "table_football" {
-		return &InputValidationError{Field: "sport", Message: "must be 'table_tennis' or 'table_football'"}
+	// Whether the sport exists is checked against the sports table by the caller
+	if strings.TrimSpace(sport) == "" {
+		return &InputValidationError{Field: "sport", Message: "is required"}
 	}
 
 	// Validate ELO range
@@ -173,6 +274,19 @@ func ValidateStatus(status string) error {
 	return nil
 }
 
+// allowedReactions is the fixed set of emoji that can be used as match reactions
+var allowedReactions = map[string]bool{
+	"👍": true, "👏": true, "🔥": true, "😮": true, "😂": true, "🏆": true, "💪": true, "😢": true,
+}
+
+// ValidateReactionEmoji validates that an emoji is an allowed match reaction
+func ValidateReactionEmoji(emoji string) error {
+	if !allowedReactions[emoji] {
+		return &InputValidationError{Field: "emoji", Message: "is not an allowed reaction"}
+	}
+	return nil
+}
+
 // ValidateLogin validates a login/username string
 func ValidateLogin(login string) error {
 	login = strings.TrimSpace(login)
@@ -194,17 +308,57 @@ func ValidateLogin(login string) error {
 	return nil
 }
 
-// ValidateEditMatchRequest validates edit match request
-func ValidateEditMatchRequest(player1Score, player2Score *int, status *string) error {
+// ExpectedWinner returns the player who wins according to the scores
+func ExpectedWinner(player1ID, player2ID, player1Score, player2Score int) int {
+	if player1Score > player2Score {
+		return player1ID
+	}
+	return player2ID
+}
+
+// ValidateWinner checks that winner_id is consistent with the scores
+func ValidateWinner(player1ID, player2ID, player1Score, player2Score, winnerID int) error {
+	if player1Score == player2Score {
+		return &InputValidationError{Field: "score", Message: "scores cannot be equal - someone must win"}
+	}
+
+	if winnerID != ExpectedWinner(player1ID, player2ID, player1Score, player2Score) {
+		return &InputValidationError{Field: "winner_id", Message: "winner does not match the scores"}
+	}
+
+	return nil
+}
+
+// ValidateCounterProposal validates the corrected score attached when denying a match
+// The scores must fit the score range of the match's sport, as on submission
+func ValidateCounterProposal(rules ScoreRange, playerScore, opponentScore *int) error {
+	if playerScore == nil || opponentScore == nil {
+		return &InputValidationError{Field: "score", Message: "both player_score and opponent_score are required for a counter-proposal"}
+	}
+
+	if err := ValidateSportScores(rules, &models.SubmitMatchRequest{PlayerScore: *playerScore, OpponentScore: *opponentScore}); err != nil {
+		return err
+	}
+
+	if *playerScore == *opponentScore {
+		return &InputValidationError{Field: "score", Message: "scores cannot be equal - someone must win"}
+	}
+
+	return nil
+}
+
+// ValidateEditMatchRequest validates the scores of an admin edit against the score range of
+// the match's sport, as on submission
+func ValidateEditMatchRequest(rules ScoreRange, player1Score, player2Score *int) error {
 	if player1Score != nil {
-		if *player1Score < MinScoreValue || *player1Score > MaxScoreValue {
-			return &InputValidationError{Field: "player1_score", Message: fmt.Sprintf("must be between %d and %d", MinScoreValue, MaxScoreValue)}
+		if err := rules.Check("player1_score", *player1Score); err != nil {
+			return err
 		}
 	}
 
 	if player2Score != nil {
-		if *player2Score < MinScoreValue || *player2Score > MaxScoreValue {
-			return &InputValidationError{Field: "player2_score", Message: fmt.Sprintf("must be between %d and %d", MinScoreValue, MaxScoreValue)}
+		if err := rules.Check("player2_score", *player2Score); err != nil {
+			return err
 		}
 	}
 
@@ -213,9 +367,5 @@ func ValidateEditMatchRequest(player1Score, player2Score *int, status *string) e
 		return &InputValidationError{Field: "score", Message: "scores cannot be equal - someone must win"}
 	}
 
-	if status != nil {
-		return ValidateStatus(*status)
-	}
-
 	return nil
 }
@@ -12,6 +12,7 @@ import { getDefaultSportId, preloadSports } from './config/sports';
 const Login = lazy(() => import('./pages/Login'));
 const Arena = lazy(() => import('./pages/Arena'));
 const Activity = lazy(() => import('./pages/Activity'));
+const ConfirmMatch = lazy(() => import('./pages/ConfirmMatch'));
 const Settings = lazy(() => import('./pages/Settings'));
 const Admin = lazy(() => import('./pages/Admin').then(m => ({ default: m.Admin })));
 
@@ -102,6 +103,7 @@ function App() {
 
               {/* Activity (Personal) */}
               <Route path="/matches" element={user ? <Activity /> : <Navigate to="/login" replace />} />
+              <Route path="/matches/confirm/:token" element={user ? <ConfirmMatch /> : <Navigate to="/login" replace />} />
 
               {/* Settings */}
               <Route path="/settings" element={user ? <Settings user={user} onLogout={handleLogout} /> : <Navigate to="/login" replace />} />
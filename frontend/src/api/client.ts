@@ -1,8 +1,11 @@
 import axios, { AxiosError } from 'axios';
 import type {
-  User, Match, LeaderboardEntry, Comment, SubmitMatchRequest,
-  SystemHealth, ELOAdjustment, AdminAuditLog, AdjustELORequest, BanUserRequest
+  User, Match, MatchPage, MatchWithPlayers, LeaderboardEntry, UnrankedEntry, CoalitionStanding, Comment, SubmitMatchRequest,
+  SystemHealth, AdminAnalytics, ELOAdjustment, AdminAuditLog, AdjustELORequest, BanUserRequest, UserRole, MutedUser,
+  Report, ReportTarget, ReportReason, ReportStatus, MatchFlag, MatchFlagStatus,
+  Digest, DigestPeriod, RankSnapshot, ELODistribution, GlobalStats, ActivityHeatmap, DeletedMatch, MatchImportReport, DataExport, MatchConfirmationPreview
 } from '../types';
+import type { SportConfig } from '../config/sports';
 
 const API_URL = import.meta.env.VITE_API_URL || 'http://localhost:8080';
 
@@ -114,32 +117,32 @@ export const authAPI = {
 
 // GDPR API (Art. 15 & 17)
 export const gdprAPI = {
-  // Export all user data (Art. 15 - Right to Access)
-  exportData: async (): Promise<Blob> => {
-    const response = await client.get('/users/me/data-export', {
-      responseType: 'blob',
-    });
-    return response.data;
+  // Request an export of all user data (Art. 15 - Right to Access)
+  // The zip (profile.json plus matches, comments and reactions as CSV) is built in the
+  // background and its download link arrives as a notification; one export per day
+  requestExport: async (): Promise<DataExport> => {
+    const { data } = await client.post('/users/me/data-exports');
+    return data;
   },
 
-  // Download user data as JSON file
-  downloadData: async (): Promise<void> => {
-    const blob = await gdprAPI.exportData();
-    const url = window.URL.createObjectURL(blob);
-    const a = document.createElement('a');
-    a.href = url;
-    a.download = `my-data-export-${new Date().toISOString().split('T')[0]}.json`;
-    document.body.appendChild(a);
-    a.click();
-    document.body.removeChild(a);
-    window.URL.revokeObjectURL(url);
+  // State of the latest export, with its download link once ready
+  latestExport: async (): Promise<DataExport> => {
+    const { data } = await client.get('/users/me/data-exports/latest');
+    return data;
   },
 
   // Delete account (Art. 17 - Right to Erasure)
-  deleteAccount: async (): Promise<{ message: string }> => {
+  // The account is erased after a 14-day grace period
+  deleteAccount: async (): Promise<{ message: string; erase_after: string }> => {
     const { data } = await client.delete('/users/me/delete');
     return data;
   },
+
+  // Keep the account while its deletion is still pending
+  cancelDeletion: async (): Promise<{ message: string }> => {
+    const { data } = await client.post('/users/me/delete/cancel');
+    return data;
+  },
 };
 
 // Users API
@@ -148,6 +151,17 @@ export const usersAPI = {
     const { data } = await client.get('/users');
     return data;
   },
+
+  getRankHistory: async (userId: number, sport: string): Promise<RankSnapshot[]> => {
+    const { data } = await client.get(`/users/${userId}/rank-history`, { params: { sport } });
+    return data;
+  },
+
+  // Subscription URL for calendar apps; it carries a signed token, so keep it private
+  getCalendarLink: async (): Promise<{ token: string; url: string }> => {
+    const { data } = await client.get('/users/me/calendar');
+    return data;
+  },
 };
 
 // Match API
@@ -161,6 +175,16 @@ export const matchAPI = {
     await client.post(`/matches/${matchId}/confirm`);
   },
 
+  // QR code / deep link: the GET only shows the match, the POST confirms it
+  getByConfirmToken: async (token: string): Promise<MatchConfirmationPreview> => {
+    const { data } = await client.get('/matches/confirm', { params: { token } });
+    return data;
+  },
+
+  confirmByToken: async (token: string): Promise<void> => {
+    await client.post('/matches/confirm', { token });
+  },
+
   deny: async (matchId: number): Promise<void> => {
     await client.post(`/matches/${matchId}/deny`);
   },
@@ -174,9 +198,19 @@ export const matchAPI = {
     sport?: string;
     status?: string;
     limit?: number;
-    offset?: number;
-  }): Promise<Match[]> => {
-    const { data } = await client.get('/matches', { params });
+  }): Promise<MatchWithPlayers[]> => {
+    const { data } = await client.get<MatchPage>('/matches', { params });
+    return data.matches;
+  },
+
+  listPage: async (params?: {
+    user_id?: number;
+    sport?: string;
+    status?: string;
+    limit?: number;
+    cursor?: string;
+  }): Promise<MatchPage> => {
+    const { data } = await client.get<MatchPage>('/matches', { params });
     return data;
   },
 
@@ -188,16 +222,55 @@ export const matchAPI = {
 
 // Leaderboard API
 export const leaderboardAPI = {
-  get: async (sport: string): Promise<LeaderboardEntry[]> => {
-    const { data } = await client.get(`/leaderboard/${sport}`);
+  // rank_delta compares with yesterday's snapshot, or last week's with compare = 'week'
+  get: async (sport: string, compare: 'day' | 'week' = 'day'): Promise<LeaderboardEntry[]> => {
+    const { data } = await client.get(`/leaderboard/${sport}`, { params: { compare } });
+    return data;
+  },
+
+  // Players still in their placement matches
+  getUnranked: async (sport: string): Promise<UnrankedEntry[]> => {
+    const { data } = await client.get(`/leaderboard/${sport}/unranked`);
+    return data;
+  },
+
+  getCoalitions: async (sport: string): Promise<CoalitionStanding[]> => {
+    const { data } = await client.get(`/leaderboard/${sport}`, { params: { group_by: 'coalition' } });
+    return data;
+  },
+
+  getLatestDigest: async (sport: string, period: DigestPeriod = 'daily'): Promise<Digest> => {
+    const { data } = await client.get(`/digest/${sport}/latest`, { params: { period } });
+    return data;
+  },
+};
+
+// Stats API
+export const statsAPI = {
+  get: async (): Promise<GlobalStats> => {
+    const { data } = await client.get('/stats');
+    return data;
+  },
+
+  getDistribution: async (sport: string, bucket?: number): Promise<ELODistribution> => {
+    const { data } = await client.get(`/stats/${sport}/distribution`, { params: { bucket } });
+    return data;
+  },
+
+  getActivity: async (sport: string, days?: number, tz?: string): Promise<ActivityHeatmap> => {
+    const { data } = await client.get(`/stats/${sport}/activity`, { params: { days, tz } });
     return data;
   },
 };
 
 // Comment API
 export const commentAPI = {
-  add: async (matchId: number, content: string): Promise<Comment> => {
-    const { data } = await client.post(`/matches/${matchId}/comments`, { content });
+  // Pass parentCommentId to reply to a top-level comment; @login mentions notify that user
+  add: async (matchId: number, content: string, parentCommentId?: number): Promise<Comment> => {
+    const { data } = await client.post(`/matches/${matchId}/comments`, {
+      content,
+      parent_comment_id: parentCommentId,
+    });
     return data;
   },
 
@@ -223,6 +296,19 @@ export const commentAPI = {
   },
 };
 
+// Report API - abuse reports on matches, comments and players
+export const reportAPI = {
+  create: async (targetType: ReportTarget, targetId: number, reason: ReportReason, details?: string): Promise<Report> => {
+    const { data } = await client.post('/reports', {
+      target_type: targetType,
+      target_id: targetId,
+      reason,
+      details,
+    });
+    return data;
+  },
+};
+
 // Admin API
 export const adminAPI = {
   // System Health
@@ -231,6 +317,11 @@ export const adminAPI = {
     return data;
   },
 
+  getAnalytics: async (days?: number): Promise<AdminAnalytics> => {
+    const { data } = await client.get('/admin/analytics', { params: { days } });
+    return data;
+  },
+
   // User Management
   getBannedUsers: async (): Promise<User[]> => {
     const { data } = await client.get('/admin/users/banned');
@@ -245,6 +336,66 @@ export const adminAPI = {
     await client.post(`/admin/users/${userId}/unban`);
   },
 
+  // Staff roles (superadmins only)
+  setRole: async (userId: number, role: UserRole): Promise<void> => {
+    await client.post(`/admin/users/${userId}/role`, { role });
+  },
+
+  // Comment moderation (moderators and above)
+  deleteComment: async (commentId: number): Promise<void> => {
+    await client.delete(`/admin/comments/${commentId}`);
+  },
+
+  deleteUserComments: async (userId: number): Promise<{ deleted: number }> => {
+    const { data } = await client.delete(`/admin/users/${userId}/comments`);
+    return data;
+  },
+
+  removeReactions: async (matchId: number, userId: number, emoji?: string): Promise<{ removed: number }> => {
+    const { data } = await client.delete(`/admin/matches/${matchId}/reactions`, { params: { user_id: userId, emoji } });
+    return data;
+  },
+
+  getMutedUsers: async (): Promise<MutedUser[]> => {
+    const { data } = await client.get('/admin/users/muted');
+    return data;
+  },
+
+  muteUser: async (userId: number): Promise<void> => {
+    await client.post(`/admin/users/${userId}/mute`);
+  },
+
+  unmuteUser: async (userId: number): Promise<void> => {
+    await client.delete(`/admin/users/${userId}/mute`);
+  },
+
+  // Abuse scan review queue
+  getFlaggedMatches: async (status: MatchFlagStatus = 'open', limit?: number, offset?: number): Promise<MatchFlag[]> => {
+    const { data } = await client.get('/admin/matches/flagged', { params: { status, limit, offset } });
+    return data;
+  },
+
+  reviewMatchFlag: async (flagId: number, status: Exclude<MatchFlagStatus, 'open'>, note?: string): Promise<MatchFlag> => {
+    const { data } = await client.post(`/admin/matches/flags/${flagId}/review`, { status, note });
+    return data;
+  },
+
+  // Report queue (moderators and above); the note is sent to the reporter
+  getReports: async (status: ReportStatus = 'open', limit?: number, offset?: number): Promise<Report[]> => {
+    const { data } = await client.get('/admin/reports', { params: { status, limit, offset } });
+    return data;
+  },
+
+  resolveReport: async (reportId: number, note?: string): Promise<Report> => {
+    const { data } = await client.post(`/admin/reports/${reportId}/resolve`, { note });
+    return data;
+  },
+
+  dismissReport: async (reportId: number, note?: string): Promise<Report> => {
+    const { data } = await client.post(`/admin/reports/${reportId}/dismiss`, { note });
+    return data;
+  },
+
   // ELO Management
   adjustELO: async (request: AdjustELORequest): Promise<ELOAdjustment> => {
     const { data } = await client.post('/admin/elo/adjust', request);
@@ -279,6 +430,16 @@ export const adminAPI = {
     await client.delete(`/admin/matches/${matchId}`);
   },
 
+  getDeletedMatches: async (limit?: number): Promise<DeletedMatch[]> => {
+    const { data } = await client.get('/admin/matches/deleted', { params: { limit } });
+    return data;
+  },
+
+  restoreMatch: async (matchId: number): Promise<Match> => {
+    const { data } = await client.post(`/admin/matches/${matchId}/restore`);
+    return data;
+  },
+
   // Audit Log
   getAuditLog: async (limit?: number): Promise<AdminAuditLog[]> => {
     const { data } = await client.get('/admin/audit-log', { params: { limit } });
@@ -295,6 +456,35 @@ export const adminAPI = {
     const token = localStorage.getItem('token');
     return `${API_URL}/api/admin/export/users${token ? `?token=${token}` : ''}`;
   },
+
+  // CSV Import, in the layout of the match export
+  importMatchesCSV: async (file: Blob, dryRun = false): Promise<MatchImportReport> => {
+    const { data } = await client.post('/admin/matches/import', file, {
+      params: { dry_run: dryRun || undefined },
+      headers: { 'Content-Type': 'text/csv' },
+    });
+    return data;
+  },
+
+  // Sport Management; deactivated sports keep their matches and ratings
+  createSport: async (sport: Partial<SportConfig> & { id: string; name: string; display_name: string }): Promise<SportConfig> => {
+    const { data } = await client.post('/admin/sports', sport);
+    return data;
+  },
+
+  updateSport: async (sportId: string, changes: Partial<SportConfig>): Promise<SportConfig> => {
+    const { data } = await client.put(`/admin/sports/${sportId}`, changes);
+    return data;
+  },
+
+  deactivateSport: async (sportId: string): Promise<void> => {
+    await client.delete(`/admin/sports/${sportId}`);
+  },
+
+  reorderSports: async (sportIds: string[]): Promise<string[]> => {
+    const { data } = await client.post('/admin/sports/reorder', { sport_ids: sportIds });
+    return data.sport_ids;
+  },
 };
 
 export default client;
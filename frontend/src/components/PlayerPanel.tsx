@@ -221,7 +221,7 @@ export function PlayerPanel({
                             {won ? "W" : "L"}
                           </span>
                           <span className="panel__match-score data">
-                            {myScore}–{theirScore}
+                            {match.result === "forfeit" ? "FF" : `${myScore}–${theirScore}`}
                           </span>
                           {eloDelta !== undefined && (
                             <span
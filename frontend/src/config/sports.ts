@@ -3,6 +3,8 @@
  * Fetches sport configurations from the API and provides caching
  */
 
+import type { User } from '../types';
+
 const API_URL = import.meta.env.VITE_API_URL || 'http://localhost:8080';
 
 export interface SportConfig {
@@ -12,8 +14,19 @@ export interface SportConfig {
   icon_url?: string;
   default_elo: number;
   k_factor: number;
+  k_factor_strategy?: 'fixed' | 'tiered';
+  provisional_matches?: number;
+  provisional_k_factor?: number;
+  high_rating_threshold?: number;
+  high_rating_k_factor?: number;
+  margin_of_victory?: boolean;
+  max_margin_multiplier?: number;
+  placement_matches?: number;
+  rating_floor?: number;
+  rating_ceiling?: number;
   min_score: number;
   max_score: number;
+  allow_draws: boolean;
   is_active: boolean;
   sort_order: number;
 }
@@ -95,6 +108,23 @@ export function getSportLabel(sportId: string): string {
   return fallbackLabels[sportId] || sportId;
 }
 
+/**
+ * Gets a short label such as "TT" for compact displays
+ */
+export function getSportAbbreviation(sportId: string): string {
+  return getSportLabel(sportId).split(' ').map(w => w[0]).join('').toUpperCase();
+}
+
+/**
+ * Gets a user's current ELO in a sport from their per-sport data
+ * Falls back to the sport's default ELO if they have not played it yet
+ */
+export function getUserELO(user: User, sportId: string): number {
+  const elo = user.sports?.[sportId]?.current_elo;
+  if (elo !== undefined) return elo;
+  return sportsCache?.find(s => s.id === sportId)?.default_elo ?? 1000;
+}
+
 /**
  * Gets the default sport ID (first active sport by sort order)
  */
@@ -126,6 +156,7 @@ function getDefaultSports(): SportConfig[] {
       k_factor: 32,
       min_score: 0,
       max_score: 999,
+      allow_draws: false,
       is_active: true,
       sort_order: 1,
     },
@@ -137,6 +168,7 @@ function getDefaultSports(): SportConfig[] {
       k_factor: 32,
       min_score: 0,
       max_score: 999,
+      allow_draws: false,
       is_active: true,
       sort_order: 2,
     },
@@ -1,6 +1,7 @@
 import { NavLink, Outlet, useLocation } from "react-router-dom";
 import { useMemo } from "react";
 import type { User } from "../types";
+import { getSportAbbreviation } from "../config/sports";
 import "./app-shell.css";
 import { Button } from "../ui/Button";
 import { ThemeToggle } from "../components/ThemeToggle";
@@ -83,7 +84,11 @@ export function AppShell({
                   <img className="userchip__avatar" src={user.avatar_url} alt={user.display_name} />
                   <div className="userchip__meta">
                     <div className="userchip__name">{user.display_name}</div>
-                    <div className="userchip__sub">TT {user.table_tennis_elo} · TF {user.table_football_elo}</div>
+                    <div className="userchip__sub">
+                      {Object.entries(user.sports ?? {})
+                        .map(([sportId, data]) => `${getSportAbbreviation(sportId)} ${data.current_elo}`)
+                        .join(" · ")}
+                    </div>
                   </div>
                 </NavLink>
                 <Button variant="ghost" size="sm" onClick={onLogout}>
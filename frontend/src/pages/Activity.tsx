@@ -1,8 +1,8 @@
-import { useState, useEffect, useMemo } from "react";
+import { Fragment, useState, useEffect, useMemo } from "react";
 import { NavLink, useOutletContext, useNavigate } from "react-router-dom";
 import { matchAPI, usersAPI } from "../api/client";
 import type { Match, User } from "../types";
-import { getSports, getSportLabel, type SportConfig } from "../config/sports";
+import { getSports, getSportAbbreviation, getSportLabel, type SportConfig } from "../config/sports";
 import "./activity.css";
 
 interface OutletContext {
@@ -178,15 +178,15 @@ export default function Activity() {
             <div className="activity__user-info">
               <h1 className="activity__name">{user.display_name}</h1>
               <div className="activity__elo">
-                <span className="activity__elo-item" data-sport="tt">
-                  <span className="label">TT</span>
-                  <span className="data">{user.table_tennis_elo}</span>
-                </span>
-                <span className="activity__elo-sep">·</span>
-                <span className="activity__elo-item" data-sport="tf">
-                  <span className="label">TF</span>
-                  <span className="data">{user.table_football_elo}</span>
-                </span>
+                {Object.entries(user.sports ?? {}).map(([sportId, data], i) => (
+                  <Fragment key={sportId}>
+                    {i > 0 ? <span className="activity__elo-sep">·</span> : null}
+                    <span className="activity__elo-item" data-sport={sportId}>
+                      <span className="label">{getSportAbbreviation(sportId)}</span>
+                      <span className="data">{data.current_elo}</span>
+                    </span>
+                  </Fragment>
+                ))}
               </div>
             </div>
           </div>
@@ -225,7 +225,7 @@ export default function Activity() {
                         className="activity__pending-sport"
                         data-sport={match.sport.substring(0, 2)}
                       >
-                        {getSportLabel(match.sport).split(' ').map(w => w[0]).join('').toUpperCase()}
+                        {getSportAbbreviation(match.sport)}
                       </span>
                       <span
                         className="activity__pending-opponent"
@@ -234,7 +234,7 @@ export default function Activity() {
                         {opponent?.display_name || "Unknown"}
                       </span>
                       <span className="activity__pending-score data">
-                        {theirScore}–{myScore}
+                        {match.result === "forfeit" ? "Forfeit" : `${theirScore}–${myScore}`}
                       </span>
                     </div>
                     <div className="activity__pending-actions">
@@ -324,7 +324,7 @@ export default function Activity() {
                       className="activity__match-sport"
                       data-sport={match.sport.substring(0, 2)}
                     >
-                      {getSportLabel(match.sport).split(' ').map(w => w[0]).join('').toUpperCase()}
+                      {getSportAbbreviation(match.sport)}
                     </span>
                     <span
                       className="activity__match-opponent"
@@ -336,7 +336,7 @@ export default function Activity() {
 
                   <div className="activity__match-center">
                     <span className="activity__match-score data">
-                      {myScore}–{theirScore}
+                      {match.result === "forfeit" ? "Forfeit" : `${myScore}–${theirScore}`}
                     </span>
                     {match.status === "confirmed" && eloDelta !== undefined && (
                       <span
@@ -5,6 +5,7 @@ import { Spinner } from '../ui/Spinner';
 import { adminAPI, usersAPI } from '../api/client';
 import { useToast } from '../state/useToast';
 import { formatRelativeTime } from '../utils/dateUtils';
+import { getSportAbbreviation } from '../config/sports';
 import type { User, Match, SystemHealth, ELOAdjustment, AdminAuditLog } from '../types';
 import './Admin.css';
 
@@ -333,7 +334,7 @@ export function Admin({ user }: AdminProps) {
                       <option value="">Select a user...</option>
                       {allUsers.filter(u => !u.is_banned).map(u => (
                         <option key={u.id} value={u.id}>
-                          {u.login} (TT: {u.table_tennis_elo}, TF: {u.table_football_elo})
+                          {u.login} ({Object.entries(u.sports ?? {}).map(([sportId, data]) => `${getSportAbbreviation(sportId)}: ${data.current_elo}`).join(', ')})
                         </option>
                       ))}
                     </select>
@@ -3,6 +3,7 @@ import { useParams, useOutletContext } from "react-router-dom";
 import { leaderboardAPI, matchAPI, usersAPI } from "../api/client";
 import type { LeaderboardEntry, User } from "../types";
 import { useDebounce } from "../hooks";
+import { getUserELO } from "../config/sports";
 import { calculateELOChange, formatEloDelta } from "../utils/eloUtils";
 import { PlayerPanel } from "../components/PlayerPanel";
 import "./arena.css";
@@ -151,9 +152,7 @@ export default function Arena() {
   }, [leaderboard, user]);
 
   const userElo = useMemo(() => {
-    return sport === "table_tennis"
-      ? user?.table_tennis_elo
-      : user?.table_football_elo;
+    return user ? getUserELO(user, sport) : undefined;
   }, [user, sport]);
 
   // ELO prediction for quick log
@@ -165,10 +164,7 @@ export default function Arena() {
     if (isNaN(pScore) || isNaN(oScore) || pScore === oScore) return null;
 
     const playerELO = userElo || 1000;
-    const opponentELO =
-      sport === "table_tennis"
-        ? opponent.table_tennis_elo
-        : opponent.table_football_elo;
+    const opponentELO = getUserELO(opponent, sport);
 
     const playerWins = pScore > oScore;
     return calculateELOChange(playerELO, opponentELO, playerWins);
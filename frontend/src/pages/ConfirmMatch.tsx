@@ -0,0 +1,86 @@
+import { useState, useEffect } from "react";
+import { useParams, useNavigate } from "react-router-dom";
+import { matchAPI } from "../api/client";
+import type { MatchConfirmationPreview } from "../types";
+import { getSportLabel } from "../config/sports";
+import { getErrorMessage } from "../utils/errorUtils";
+import { Page } from "../layout/Page";
+import { Button, Card, CardContent, CardDescription, CardFooter, CardHeader, CardTitle, Spinner } from "../ui";
+
+// Opened from the QR code / deep link shown to the submitter of a match
+// The match is only confirmed once the opponent has seen the score and pressed confirm
+export default function ConfirmMatch() {
+  const { token = "" } = useParams();
+  const navigate = useNavigate();
+
+  const [preview, setPreview] = useState<MatchConfirmationPreview | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [confirming, setConfirming] = useState(false);
+  const [confirmed, setConfirmed] = useState(false);
+
+  useEffect(() => {
+    matchAPI
+      .getByConfirmToken(token)
+      .then(setPreview)
+      .catch((err) => setError(getErrorMessage(err)));
+  }, [token]);
+
+  const handleConfirm = async () => {
+    setConfirming(true);
+    try {
+      await matchAPI.confirmByToken(token);
+      setConfirmed(true);
+    } catch (err) {
+      setError(getErrorMessage(err));
+    } finally {
+      setConfirming(false);
+    }
+  };
+
+  if (!preview && !error) return <Spinner />;
+
+  const match = preview?.match;
+  const submitter = preview?.submitter;
+  // Scores from the point of view of the submitter
+  const submitterIsPlayer1 = match ? match.submitted_by === match.player1_id : true;
+  const submitterScore = match ? (submitterIsPlayer1 ? match.player1_score : match.player2_score) : 0;
+  const yourScore = match ? (submitterIsPlayer1 ? match.player2_score : match.player1_score) : 0;
+
+  return (
+    <Page title="Confirm match" subtitle="Check the result before it counts">
+      <Card>
+        <CardHeader>
+          <CardTitle>{match ? getSportLabel(match.sport) : "Match confirmation"}</CardTitle>
+          {submitter ? (
+            <CardDescription>
+              Submitted by {submitter.display_name || submitter.login} ({submitter.login})
+            </CardDescription>
+          ) : null}
+        </CardHeader>
+        <CardContent>
+          {error ? (
+            <p>{error}</p>
+          ) : confirmed ? (
+            <p>Match confirmed. Your ratings have been updated.</p>
+          ) : match ? (
+            <p>
+              {match.result === "forfeit"
+                ? "Forfeit"
+                : `${submitter?.login}: ${submitterScore} – You: ${yourScore}`}
+            </p>
+          ) : null}
+        </CardContent>
+        <CardFooter>
+          {match && !confirmed && !error ? (
+            <Button onClick={handleConfirm} isLoading={confirming}>
+              Confirm result
+            </Button>
+          ) : null}
+          <Button variant="ghost" onClick={() => navigate("/matches")}>
+            {confirmed || error ? "Go to my matches" : "Wrong score? Deny it in my matches"}
+          </Button>
+        </CardFooter>
+      </Card>
+    </Page>
+  );
+}
@@ -3,6 +3,7 @@ import { useParams, Link } from 'react-router-dom';
 import { usersAPI, matchAPI } from '../api/client';
 import type { User, Match } from '../types';
 import { SPORT_LABELS } from '../types';
+import { getSportLabel } from '../config/sports';
 import { Page } from '../layout/Page';
 import { Card, CardContent } from '../ui/Card';
 import { Button } from '../ui/Button';
@@ -178,14 +179,12 @@ function PlayerProfile({ user: currentUser }: PlayerProfileProps) {
           </div>
 
           <div className="profile__elo-grid">
-            <div className="profile__elo-item">
-              <span className="profile__elo-label">Table Tennis</span>
-              <span className="profile__elo-value">{player.table_tennis_elo}</span>
-            </div>
-            <div className="profile__elo-item">
-              <span className="profile__elo-label">Table Football</span>
-              <span className="profile__elo-value">{player.table_football_elo}</span>
-            </div>
+            {Object.entries(player.sports ?? {}).map(([sportId, data]) => (
+              <div key={sportId} className="profile__elo-item">
+                <span className="profile__elo-label">{getSportLabel(sportId)}</span>
+                <span className="profile__elo-value">{data.current_elo}</span>
+              </div>
+            ))}
           </div>
         </CardContent>
       </Card>
@@ -6,6 +6,7 @@ import { Button } from '../ui/Button';
 import { Toast } from '../ui/Toast';
 import { gdprAPI } from '../api/client';
 import type { User } from '../types';
+import { getSportLabel } from '../config/sports';
 import './Settings.css';
 
 interface SettingsProps {
@@ -19,6 +20,7 @@ export function Settings({ user, onLogout }: SettingsProps) {
   const [isDeleting, setIsDeleting] = useState(false);
   const [showDeleteConfirm, setShowDeleteConfirm] = useState(false);
   const [deleteConfirmText, setDeleteConfirmText] = useState('');
+  const [deletionScheduledFor, setDeletionScheduledFor] = useState(user.deletion_scheduled_for);
   const [toastOpen, setToastOpen] = useState(false);
   const [toastMessage, setToastMessage] = useState('');
   const [toastType, setToastType] = useState<'success' | 'error'>('success');
@@ -32,11 +34,16 @@ export function Settings({ user, onLogout }: SettingsProps) {
   const handleExportData = async () => {
     setIsExporting(true);
     try {
-      await gdprAPI.downloadData();
-      showToast('Your data has been downloaded', 'success');
+      const dataExport = await gdprAPI.requestExport();
+      if (dataExport.status === 'ready' && dataExport.download_url) {
+        window.location.assign(dataExport.download_url);
+        showToast('Your data is being downloaded', 'success');
+      } else {
+        showToast('Your export is being prepared. You will get a notification with the download link.', 'success');
+      }
     } catch (error) {
       console.error('Failed to export data:', error);
-      showToast('Error downloading data', 'error');
+      showToast('Error requesting your data', 'error');
     } finally {
       setIsExporting(false);
     }
@@ -51,7 +58,7 @@ export function Settings({ user, onLogout }: SettingsProps) {
     setIsDeleting(true);
     try {
       await gdprAPI.deleteAccount();
-      showToast('Your account has been deleted', 'success');
+      showToast('Your account will be deleted in 14 days. Log in again to cancel.', 'success');
       // Clear local storage and redirect
       localStorage.removeItem('token');
       onLogout();
@@ -63,6 +70,17 @@ export function Settings({ user, onLogout }: SettingsProps) {
     }
   };
 
+  const handleCancelDeletion = async () => {
+    try {
+      await gdprAPI.cancelDeletion();
+      setDeletionScheduledFor(undefined);
+      showToast('Your account deletion has been cancelled', 'success');
+    } catch (error) {
+      console.error('Failed to cancel deletion:', error);
+      showToast('Error cancelling deletion', 'error');
+    }
+  };
+
   return (
     <Page title="Settings" subtitle="Manage your account and data">
       <div className="settings-grid">
@@ -79,14 +97,15 @@ export function Settings({ user, onLogout }: SettingsProps) {
               <h3>Right of Access (Art. 15 GDPR)</h3>
               <p>
                 You have the right to know what personal data we store about you.
-                Download all your data as a JSON file.
+                Request all your data as a ZIP archive; it is prepared in the background
+                and you can download it for 24 hours. One export can be requested per day.
               </p>
               <Button
                 variant="secondary"
                 onClick={handleExportData}
                 disabled={isExporting}
               >
-                {isExporting ? 'Downloading...' : '📥 Download my data'}
+                {isExporting ? 'Requesting...' : '📥 Download my data'}
               </Button>
             </div>
 
@@ -94,9 +113,20 @@ export function Settings({ user, onLogout }: SettingsProps) {
               <h3>Right to Erasure (Art. 17 GDPR)</h3>
               <p>
                 You have the right to request the deletion of your data.
-                This action is irreversible.
+                Your account is deleted 14 days after the request; until then
+                you can cancel it here.
               </p>
-              {!showDeleteConfirm ? (
+              {deletionScheduledFor ? (
+                <div className="delete-confirm">
+                  <div className="delete-warning">
+                    <strong>⚠️ Your account is scheduled for deletion</strong> on{' '}
+                    {new Date(deletionScheduledFor).toLocaleDateString()}.
+                  </div>
+                  <Button variant="ghost" onClick={handleCancelDeletion}>
+                    Keep my account
+                  </Button>
+                </div>
+              ) : !showDeleteConfirm ? (
                 <Button
                   variant="danger"
                   onClick={() => setShowDeleteConfirm(true)}
@@ -106,7 +136,7 @@ export function Settings({ user, onLogout }: SettingsProps) {
               ) : (
                 <div className="delete-confirm">
                   <div className="delete-warning">
-                    <strong>⚠️ Warning:</strong> This action cannot be undone.
+                    <strong>⚠️ Warning:</strong> After 14 days this cannot be undone.
                     All your data will be irrevocably deleted:
                     <ul>
                       <li>Your user profile</li>
@@ -184,14 +214,12 @@ export function Settings({ user, onLogout }: SettingsProps) {
                 <dt>Intra ID</dt>
                 <dd>{user.intra_id}</dd>
               </div>
-              <div className="account-info-row">
-                <dt>Table Tennis ELO</dt>
-                <dd>{user.table_tennis_elo}</dd>
-              </div>
-              <div className="account-info-row">
-                <dt>Table Football ELO</dt>
-                <dd>{user.table_football_elo}</dd>
-              </div>
+              {Object.entries(user.sports ?? {}).map(([sportId, data]) => (
+                <div key={sportId} className="account-info-row">
+                  <dt>{getSportLabel(sportId)} ELO</dt>
+                  <dd>{data.current_elo}</dd>
+                </div>
+              ))}
               <div className="account-info-row">
                 <dt>Registered since</dt>
                 <dd>{new Date(user.created_at).toLocaleDateString('en-GB')}</dd>
@@ -9,7 +9,7 @@ import { Button } from '../ui/Button';
 import { getErrorMessage } from '../utils/errorUtils';
 import { calculateELOChange, formatEloDelta } from '../utils/eloUtils';
 import { SCORE_MIN } from '../constants';
-import { getSports, type SportConfig } from '../config/sports';
+import { getSports, getUserELO, type SportConfig } from '../config/sports';
 import './SubmitMatch.css';
 
 interface SubmitMatchProps {
@@ -48,20 +48,9 @@ function SubmitMatch({ user }: SubmitMatchProps) {
     return players.find(p => p.id === opponentId) || null;
   }, [players, opponentId]);
 
-  // Get current ELO ratings based on selected sport
-  // Uses new sports map if available, falls back to legacy fields
-  const getELO = (u: User, sportId: string): number => {
-    if (u.sports?.[sportId]) {
-      return u.sports[sportId].current_elo;
-    }
-    // Fallback to legacy fields
-    if (sportId === 'table_tennis') return u.table_tennis_elo;
-    if (sportId === 'table_football') return u.table_football_elo;
-    return 1000; // Default for unknown sports
-  };
 
-  const playerELO = getELO(user, sport);
-  const opponentELO = selectedOpponent ? getELO(selectedOpponent, sport) : null;
+  const playerELO = getUserELO(user, sport);
+  const opponentELO = selectedOpponent ? getUserELO(selectedOpponent, sport) : null;
 
   // Calculate predicted ELO changes
   const eloPrediction = useMemo(() => {
@@ -7,6 +7,8 @@ export interface UserSportData {
   losses: number;
 }
 
+export type UserRole = 'user' | 'moderator' | 'admin' | 'superadmin';
+
 export interface User {
   id: number;
   intra_id: number;
@@ -14,18 +16,35 @@ export interface User {
   display_name: string;
   avatar_url: string;
   campus: string;
-  // Legacy ELO fields (kept for backward compatibility during migration)
-  table_tennis_elo: number;
-  table_football_elo: number;
-  is_admin?: boolean;
+  is_admin?: boolean; // any staff role
+  role?: UserRole; // only on /auth/me
   is_banned?: boolean;
   ban_reason?: string;
   banned_at?: string;
   banned_by?: number;
   created_at: string;
   updated_at: string;
-  // New: Per-sport statistics (will be populated after migration)
+  // Per-sport ELO and record; not included in leaderboard, season or match feed entries
   sports?: Record<string, UserSportData>;
+  // Set while an account deletion is pending (only on /auth/me)
+  deletion_scheduled_for?: string;
+  // From the 42 API on login
+  coalition_id?: number;
+  pool_year?: string;
+}
+
+// GDPR data export, built in the background (Art. 15)
+export type DataExportStatus = 'pending' | 'processing' | 'ready' | 'failed';
+
+export interface DataExport {
+  id: number;
+  user_id: number;
+  status: DataExportStatus;
+  size_bytes?: number;
+  created_at: string;
+  completed_at?: string;
+  expires_at?: string;
+  download_url?: string; // signed link, only while ready
 }
 
 export interface Match {
@@ -44,11 +63,68 @@ export interface Match {
   player2_elo_before?: number;
   player2_elo_after?: number;
   player2_elo_delta?: number;
+  // K-factors applied at confirmation
+  player1_k_factor?: number;
+  player2_k_factor?: number;
   submitted_by: number;
   confirmed_at?: string;
   denied_at?: string;
   created_at: string;
   updated_at: string;
+  // Forfeits are won without playing; scores are 0–0
+  result?: 'played' | 'forfeit';
+  forfeited_by?: number;
+  // Set breakdown of best-of-N series; only on the match detail
+  sets?: MatchSet[];
+  // Reaction counts per emoji, embedded in the match feed
+  reactions?: ReactionSummary[];
+}
+
+// Player as shown next to a match, e.g. the submitter on the confirmation page
+export interface PlayerSummary {
+  id: number;
+  login: string;
+  display_name: string;
+  avatar_url: string;
+}
+
+// Match behind a QR code / deep link, shown before the opponent confirms it
+export interface MatchConfirmationPreview {
+  match: Match;
+  submitter: PlayerSummary;
+}
+
+// Soft-deleted match in the admin restore list
+export interface DeletedMatch extends Match {
+  deleted_at: string;
+  deleted_by?: number;
+  // Reverted matches had their ratings rolled back; restoring reapplies them
+  reverted: boolean;
+}
+
+export interface ReactionSummary {
+  emoji: string;
+  count: number;
+  reacted: boolean;
+}
+
+export interface MatchSet {
+  set_number: number;
+  player1_score: number;
+  player2_score: number;
+}
+
+// Feed entries come with both players, the winner and the submitter
+export interface MatchWithPlayers extends Match {
+  player1: User;
+  player2: User;
+  winner: User;
+  submitted_by_user: User;
+}
+
+export interface MatchPage {
+  matches: MatchWithPlayers[];
+  next_cursor?: string;
 }
 
 export interface LeaderboardEntry {
@@ -59,23 +135,199 @@ export interface LeaderboardEntry {
   wins: number;
   losses: number;
   win_rate: number;
+  current_streak: number; // positive for wins in a row, negative for losses
+  longest_win_streak: number;
+  rank_delta?: number; // places gained since the compared snapshot, missing if not ranked then
+  is_provisional: boolean; // still within the provisional matches of a tiered K-factor
+}
+
+// A player still playing their placement matches, not yet on the leaderboard
+export interface UnrankedEntry {
+  user: User;
+  matches_played: number;
+  placement_matches_remaining: number;
+  last_match_at?: string;
+}
+
+export interface RankSnapshot {
+  date: string; // YYYY-MM-DD
+  rank: number;
+  elo: number;
+  matches_played: number;
+}
+
+export interface MutedUser {
+  user_id: number;
+  login: string;
+  display_name: string;
+  muted_at: string;
+  muted_by?: number;
+}
+
+export type DigestPeriod = 'daily' | 'weekly';
+
+// Summary of a finished day or week; players are referenced by ID and listed in `players`
+export interface Digest {
+  id: number;
+  sport: string;
+  period: DigestPeriod;
+  period_start: string;
+  period_end: string;
+  matches: number;
+  upsets: {
+    match_id: number;
+    winner_id: number;
+    loser_id: number;
+    winner_score: number;
+    loser_score: number;
+    winner_elo_before: number;
+    loser_elo_before: number;
+    elo_gained: number;
+  }[];
+  most_active: { user_id: number; matches: number; wins: number }[];
+  rank_movers: { user_id: number; previous_rank: number; rank: number; change: number }[];
+  created_at: string;
+  players?: Record<number, User>;
+}
+
+export type MatchFlagRule = 'pair_frequency' | 'alternating_wins' | 'elo_farming';
+export type MatchFlagStatus = 'open' | 'dismissed' | 'confirmed';
+
+// A confirmed match flagged by the abuse scan
+export interface MatchFlag {
+  id: number;
+  match_id: number;
+  rule: MatchFlagRule;
+  details?: Record<string, number>;
+  status: MatchFlagStatus;
+  reviewed_by?: number;
+  reviewed_at?: string;
+  created_at: string;
+  sport: string;
+  player1_id: number;
+  player2_id: number;
+  winner_id: number;
+  confirmed_at?: string;
+}
+
+export type ReportTarget = 'match' | 'comment' | 'user';
+export type ReportReason = 'spam' | 'harassment' | 'cheating' | 'inappropriate' | 'other';
+export type ReportStatus = 'open' | 'resolved' | 'dismissed';
+
+export interface Report {
+  id: number;
+  reporter_id: number;
+  target_type: ReportTarget;
+  target_id: number;
+  reason: ReportReason;
+  details?: string;
+  status: ReportStatus;
+  resolution_note?: string; // shown to the reporter
+  handled_by?: number;
+  handled_at?: string;
+  created_at: string;
+}
+
+export interface Coalition {
+  id: number;
+  name: string;
+  slug: string;
+  color: string;
+  image_url: string;
+}
+
+// ELO figures only count players with at least one match
+export interface SportSummary {
+  sport: string;
+  matches: number;
+  matches_this_week: number;
+  average_elo: number;
+  leader?: LeaderboardEntry;
+}
+
+export interface GlobalStats {
+  total_players: number;
+  matches_this_week: number;
+  sports: SportSummary[];
+  most_active?: { user: User; matches: number }; // this week, across sports
+}
+
+export interface ActivityHeatmap {
+  sport: string;
+  days: number;
+  time_zone: string;
+  matches: number;
+  counts: number[][]; // [weekday, Monday first][hour]
+}
+
+export interface ELOBucket {
+  min: number; // inclusive
+  max: number; // exclusive
+  players: number;
+}
+
+export interface ELODistribution {
+  sport: string;
+  players: number;
+  bucket_size: number;
+  buckets: ELOBucket[];
+  average: number;
+  median: number;
+  p10: number;
+  p25: number;
+  p75: number;
+  p90: number;
+  user_elo?: number; // only for logged-in players who have played
+  user_percentile?: number; // share of the other players rated lower, 0-100
+}
+
+export interface CoalitionStanding {
+  rank: number;
+  coalition: Coalition;
+  members: number;
+  active_players: number;
+  average_elo: number;
+  top_elo: number;
+  matches_played: number;
+  wins: number;
 }
 
 export interface Comment {
   id: number;
   match_id: number;
   user_id: number;
+  // Set on replies; only top-level comments can be answered
+  parent_comment_id?: number;
   content: string;
   created_at: string;
   updated_at: string;
 }
 
+export type ChallengeStatus = 'pending' | 'accepted' | 'declined' | 'cancelled' | 'completed';
+
+export interface Challenge {
+  id: number;
+  sport: string;
+  challenger_id: number;
+  opponent_id: number;
+  scheduled_at: string;
+  message?: string;
+  status: ChallengeStatus;
+  match_id?: number; // pending match created from the result
+  created_at: string;
+  responded_at?: string;
+  updated_at: string;
+}
+
 export interface SubmitMatchRequest {
   sport: string; // Dynamic - validated by backend against sports table
   opponent_id: number;
   player_score: number;
   opponent_score: number;
   context?: string;
+  // Series: scores are the sets won and may be omitted
+  best_of?: 3 | 5 | 7;
+  sets?: { player_score: number; opponent_score: number }[];
 }
 
 // Legacy constants - kept for backward compatibility
@@ -103,6 +355,32 @@ export interface SystemHealth {
   users_today: number;
 }
 
+// Engagement figures of matches by submission time; rates and latencies are
+// null when there are no matches to base them on
+export interface AnalyticsPeriod {
+  matches: number;
+  confirmed: number;
+  denied: number;
+  disputed: number;
+  new_users: number;
+  avg_confirmation_seconds: number | null;
+  median_confirmation_seconds: number | null;
+  denial_rate: number | null;
+  dispute_rate: number | null;
+}
+
+export interface AnalyticsDay extends AnalyticsPeriod {
+  date: string;
+}
+
+export interface AdminAnalytics {
+  health: SystemHealth;
+  days: number;
+  totals: AnalyticsPeriod;
+  // One entry per day (UTC), oldest first
+  series: AnalyticsDay[];
+}
+
 export interface ELOAdjustment {
   id: number;
   user_id: number;
@@ -127,9 +405,19 @@ export interface AdminAuditLog {
   admin_login?: string;
 }
 
+// Outcome of a CSV match import; rows with errors are skipped
+export interface MatchImportReport {
+  dry_run: boolean;
+  rows: number;
+  imported: number;
+  errors: { line: number; error: string }[];
+  // ELO replay after the import; absent if nothing was imported
+  replay?: Record<string, unknown>;
+}
+
 export interface AdjustELORequest {
   user_id: number;
-  sport: 'table_tennis' | 'table_football';
+  sport: string;
   new_elo: number;
   reason: string;
 }